@@ -1,7 +1,34 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/hooks"
+	"github.com/fixpanic/fixpanic-cli/internal/lock"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/trace"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	skipHooks   bool
+	lockTimeout time.Duration
+
+	// heldLock is the installation lock held by this process, if any
+	heldLock *lock.Lock
+)
+
+// Remediation hints shared by agent commands
+const (
+	hintInstallAgent   = "Install the agent with 'fixpanic agent install --agent-id=<id> --api-key=<key>'"
+	hintReinstallAgent = "Regenerate the configuration with 'fixpanic agent install --force'"
+	hintLockWait       = "Wait for the other operation to finish, or retry with --lock-timeout=5m to wait for it"
 )
 
 // agentCmd represents the agent command
@@ -11,9 +38,122 @@ var agentCmd = &cobra.Command{
 	Long: `Manage Fixpanic agents on your server.
 	
 This command group provides functionality to install, start, stop, and manage
-Fixpanic agents that connect to the Fixpanic infrastructure.`,
+Fixpanic agents that connect to the Fixpanic infrastructure.
+
+Lifecycle hooks:
+  Executables in <config dir>/hooks.d/<event>/ (or listed under "hooks.<event>"
+  in the CLI config file) run around install, upgrade, start and stop, where
+  <event> is one of pre-install, post-install, pre-upgrade, post-upgrade,
+  pre-start, post-start, pre-stop, post-stop. Hooks receive context through
+  FIXPANIC_* environment variables. A failing pre-hook aborts the operation.
+
+Locking:
+  install, upgrade, uninstall, start and restart hold an exclusive lock so
+  concurrent invocations (e.g. a cron auto-upgrade racing an operator) can't
+  corrupt the installation. A second invocation fails immediately unless
+  --lock-timeout allows it to wait.`,
 }
 
 func init() {
 	rootCmd.AddCommand(agentCmd)
+
+	agentCmd.PersistentFlags().BoolVar(&skipHooks, "no-hooks", false, "Do not run lifecycle hooks")
+	agentCmd.PersistentFlags().DurationVar(&lockTimeout, "lock-timeout", 0, "How long to wait for another fixpanic operation to finish (0 fails immediately)")
+}
+
+// withLock runs fn while holding the installation lock. Nested calls (e.g.
+// upgrade restarting the agent) reuse the lock already held by this process.
+func withLock(cmd *cobra.Command, fn func() error) error {
+	if heldLock != nil {
+		return fn()
+	}
+	if err := acquireLock(cmd); err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	return fn()
+}
+
+// acquireLock takes the installation lock for cmd, waiting up to
+// --lock-timeout for another operation to release it
+func acquireLock(cmd *cobra.Command) error {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	lockPath := platformInfo.GetLockPath()
+	owner := fmt.Sprintf("PID %d: %s", os.Getpid(), cmd.CommandPath())
+	span := trace.Start("wait for the installation lock", trace.KindLock, nil)
+	acquired, err := lock.Acquire(cmd.Context(), lockPath, lockTimeout, owner)
+	span.End(err)
+	if errors.Is(err, lock.ErrLocked) {
+		if holder := lock.Owner(lockPath); holder != "" {
+			return clierror.New(clierror.Busy, "another fixpanic operation is in progress (%s)", holder).WithHint(hintLockWait)
+		}
+		return clierror.New(clierror.Busy, "another fixpanic operation is in progress").WithHint(hintLockWait)
+	}
+	if err != nil {
+		return err
+	}
+	heldLock = acquired
+	return nil
+}
+
+// releaseLock releases the installation lock if this process holds it
+func releaseLock() {
+	if heldLock != nil {
+		heldLock.Release()
+		heldLock = nil
+	}
+}
+
+// runWithHooks runs fn between the pre- and post-hooks of a lifecycle operation
+func runWithHooks(ctx context.Context, operation string, fn func() error) error {
+	if skipHooks {
+		return fn()
+	}
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return fn()
+	}
+
+	runner := hooks.NewRunner(platformInfo.GetHooksDir())
+	for _, phase := range []string{hooks.PhasePre, hooks.PhasePost} {
+		event := hooks.Event(phase, operation)
+		runner.Configured[event] = viper.GetStringSlice("hooks." + event)
+	}
+	runner.Env = map[string]string{
+		"FIXPANIC_CLI_VERSION":  getCurrentVersion(),
+		"FIXPANIC_AGENT_BINARY": platformInfo.GetFixPanicAgentBinaryPath(),
+		"FIXPANIC_AGENT_CONFIG": platformInfo.GetConfigPath(),
+		"FIXPANIC_LOG_DIR":      platformInfo.LogDir,
+	}
+
+	span := trace.Start(hooks.Event(hooks.PhasePre, operation)+" hooks", trace.KindHook, nil)
+	err = runner.Run(ctx, hooks.PhasePre, operation, nil)
+	span.End(err)
+	if err != nil {
+		return err
+	}
+
+	opErr := fn()
+
+	result := map[string]string{"FIXPANIC_RESULT": "success"}
+	if opErr != nil {
+		result["FIXPANIC_RESULT"] = "failure"
+		result["FIXPANIC_ERROR"] = opErr.Error()
+	}
+	// Post-hooks also run for cancelled operations so they can report the
+	// failure; each script is still bounded by the hook timeout
+	span = trace.Start(hooks.Event(hooks.PhasePost, operation)+" hooks", trace.KindHook, nil)
+	err = runner.Run(context.WithoutCancel(ctx), hooks.PhasePost, operation, result)
+	span.End(err)
+	if err != nil {
+		logger.Warning("%v", err)
+	}
+
+	return opErr
 }
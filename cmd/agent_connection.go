@@ -1,123 +1,405 @@
 package cmd
 
 import (
+	"context"
+	"crypto/x509"
 	"fmt"
 	"net"
+	"os"
+	"strings"
 	"time"
 
-	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/netprobe"
 	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/workpool"
 	"github.com/spf13/cobra"
 )
 
 // agentConnectionCmd represents the agent test-connection command
 var agentConnectionCmd = &cobra.Command{
-	Use:   "test-connection",
+	Use:   "test-connection [endpoint...]",
 	Short: "Test connection to Fixpanic infrastructure",
 	Long: `Test the connection to the Fixpanic socket server.
 	
 This command verifies that your agent can connect to the Fixpanic infrastructure
-and that the network connectivity is working properly.`,
+and that the network connectivity is working properly.
+
+Endpoints given as arguments are tested instead of the configured socket
+server. They are tested concurrently, --parallel at a time, and reported in
+the order given.
+
+DNS lookup and TCP connect latency are reported separately. --resolver sends
+the lookups to a specific DNS server instead of the system resolver; use
+'fixpanic network dns' to compare resolvers.
+
+Whether the host answers at all is checked with an ICMP echo when this user
+may send one, and with TCP and UDP probes otherwise; the method that got an
+answer is reported. When the connection fails this tells a host that is down
+from a port that is blocked.
+
+The system clock is compared with a reference server first, and when the agent
+uses TLS the validity period of each endpoint's certificate is checked: a
+clock that is off by more than a few minutes makes TLS handshakes fail.
+
+The public key pin of each certificate is shown; when app.tls_pinned_spki is
+set, a chain matching none of the pins fails the test, as it would fail the
+agent's connection.`,
 	Example: `  # Test connection
-  fixpanic agent test-connection`,
+  fixpanic agent test-connection
+
+  # Test an IPv6 endpoint
+  fixpanic agent test-connection --socket-server="[2001:db8::10]:9000"
+
+  # Test several socket servers at once
+  fixpanic agent test-connection socket-eu.example.com:9000 socket-us.example.com:9000
+
+  # Resolve through a public resolver instead of the system one
+  fixpanic agent test-connection --resolver=1.1.1.1`,
 	RunE: runAgentConnection,
 }
 
+// connectionResolver is the DNS server test-connection queries, empty for
+// the system resolver
+var connectionResolver string
+
 func init() {
 	agentCmd.AddCommand(agentConnectionCmd)
+
+	// Add flags
+	agentConnectionCmd.Flags().IntVar(&probeParallel, "parallel", workpool.DefaultLimit, "Number of endpoints tested at once")
+	agentConnectionCmd.Flags().StringVar(&connectionResolver, "resolver", "", "DNS server to resolve endpoints with (e.g. 1.1.1.1), instead of the system resolver")
+}
+
+// connectionResult is the outcome of testing one endpoint
+type connectionResult struct {
+	Endpoint    string
+	Host        string
+	Resolver    string
+	V4, V6      []net.IP
+	ResolveErr  error
+	Dial        *netprobe.Result
+	DialErr     error
+	Families    []netprobe.FamilyResult
+	FamiliesErr error
+	// Reach is whether the host answers at all; loopback hosts are skipped
+	Reach *netprobe.ReachResult
+	// Certs is the chain presented in a TLS handshake, when the agent uses TLS
+	Certs        []*x509.Certificate
+	CertErr      error
+	CertProblems []string
+	// Pins are the configured public key pins and PinMatch the one the
+	// chain matched, if any
+	Pins     []string
+	PinMatch string
+}
+
+// PinMismatch reports whether keys are pinned and the presented chain
+// matched none of them
+func (r connectionResult) PinMismatch() bool {
+	return len(r.Pins) > 0 && len(r.Certs) > 0 && r.PinMatch == ""
 }
 
 func runAgentConnection(cmd *cobra.Command, args []string) error {
-	fmt.Println("Testing connection to Fixpanic infrastructure...")
+	fmt.Fprintln(os.Stderr, "Testing connection to Fixpanic infrastructure...")
 
-	// Get platform information
-	platformInfo, err := platform.GetPlatformInfo()
+	// The middleware checked that the agent is installed
+	platformInfo, err := commandPlatform()
 	if err != nil {
-		return fmt.Errorf("failed to get platform info: %w", err)
+		return err
 	}
 
-	// Check if connectivity layer is installed
-	connectivityManager := connectivity.NewManager(platformInfo)
-	if !connectivityManager.IsFixPanicAgentInstalled() {
-		return fmt.Errorf("agent is not installed. Run 'fixpanic agent install' first")
+	// Test the given endpoints, or resolve the socket server: explicit flag,
+	// then agent config, then default
+	var endpoints []string
+	for _, arg := range args {
+		endpoint, err := netprobe.NormalizeEndpoint(arg, config.DefaultSocketPort)
+		if err != nil {
+			return clierror.New(clierror.Usage, "invalid endpoint %q: %w", arg, err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	if len(endpoints) == 0 {
+		socketServer, err := resolveSocketServer(cmd, platformInfo)
+		if err != nil {
+			return err
+		}
+		endpoints = append(endpoints, socketServer)
 	}
 
-	// Test socket server connection (same as agent uses)
-	socketServer := "socket.fixpanic.com:9000"
+	resolver, err := netprobe.NewResolver(connectionResolver)
+	if err != nil {
+		return clierror.Wrap(clierror.Usage, err)
+	}
 
-	fmt.Printf("Testing connection to: %s\n", socketServer)
+	// The agent only speaks TLS when its configuration says so (the default),
+	// and then checks the configured pins
+	app := config.DefaultConfig().App
+	if agentConfig, err := config.LoadConfig(platformInfo.GetConfigPath()); err == nil {
+		app = agentConfig.App
+	}
 
-	// Parse the address
-	host, port, err := net.SplitHostPort(socketServer)
-	if err != nil {
-		return fmt.Errorf("invalid socket server address: %w", err)
+	ctx := cmd.Context()
+	offset, offsetErr := checkClock(ctx)
+	results := workpool.Map(ctx, endpoints, probeParallel, func(ctx context.Context, endpoint string) connectionResult {
+		return testConnection(ctx, endpoint, resolver, app)
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
 
-	// Test TCP connection
-	fmt.Printf("Connecting to %s:%s...\n", host, port)
+	var failed, certFailed, pinFailed []string
+	for _, result := range results {
+		printConnectionResult(result)
+		if result.DialErr != nil {
+			failed = append(failed, result.Endpoint)
+		}
+		if len(result.CertProblems) > 0 {
+			certFailed = append(certFailed, result.Endpoint)
+		}
+		if result.PinMismatch() {
+			pinFailed = append(pinFailed, result.Endpoint)
+		}
+	}
 
-	address := net.JoinHostPort(host, port)
-	conn, err := net.DialTimeout("tcp", address, 10*time.Second)
-	if err != nil {
-		fmt.Printf("❌ Connection failed: %v\n", err)
-		fmt.Println("\nTroubleshooting tips:")
-		fmt.Println("1. Check your internet connection")
-		fmt.Println("2. Verify the socket server address is correct")
-		fmt.Println("3. Check if your firewall is blocking the connection")
-		fmt.Println("4. Ensure the socket server is accessible from your network")
-		return fmt.Errorf("connection test failed")
-	}
-	defer conn.Close()
-
-	fmt.Println("✅ TCP connection successful!")
-
-	// Test if we can resolve the hostname
-	if host != "localhost" && host != "127.0.0.1" {
-		fmt.Printf("Resolving hostname: %s\n", host)
-		ips, err := net.LookupIP(host)
-		if err != nil {
-			fmt.Printf("⚠️  DNS resolution failed: %v\n", err)
-		} else {
-			fmt.Printf("✅ DNS resolution successful. IP addresses: %v\n", ips)
+	if len(pinFailed) > 0 && len(failed) == 0 {
+		return clierror.New(clierror.Network, "%s presented a certificate matching none of the pinned keys", strings.Join(pinFailed, ", ")).
+			WithHint(
+				"A proxy may be intercepting the connection; the agent will refuse it",
+				"If the server's key was rotated, update app.tls_pinned_spki with the pin shown above",
+			)
+	}
+
+	if len(certFailed) > 0 && len(failed) == 0 {
+		if offsetErr == nil && absDuration(offset) > netprobe.ClockSkewWarn {
+			return clierror.New(clierror.Network, "certificate validation would fail: %s", netprobe.DescribeClockOffset(offset)).
+				WithHint("Enable time synchronization with 'sudo timedatectl set-ntp true', or install chrony or ntpd")
+		}
+		return clierror.New(clierror.Network, "the certificate of %s is not valid now", strings.Join(certFailed, ", ")).
+			WithHint("Run 'fixpanic agent doctor' to check the system clock and the certificate")
+	}
+
+	if len(failed) > 0 {
+		fmt.Fprintln(os.Stderr, "\nTroubleshooting tips:")
+		fmt.Fprintln(os.Stderr, "1. Check your internet connection")
+		fmt.Fprintln(os.Stderr, "2. Verify the socket server address is correct")
+		fmt.Fprintln(os.Stderr, "3. Check if your firewall is blocking the connection")
+		fmt.Fprintln(os.Stderr, "4. Ensure the socket server is accessible from your network")
+		if len(endpoints) == 1 {
+			return clierror.New(clierror.Network, "connection test failed").
+				WithHint(
+					i18n.Sprintf("Check that your firewall allows outbound TCP connections to %s", failed[0]),
+					"Verify the socket server address with --socket-server",
+				)
 		}
+		return clierror.New(clierror.Network, "connection test failed for %d of %d endpoints: %s", len(failed), len(endpoints), strings.Join(failed, ", ")).
+			WithHint("Check that your firewall allows outbound TCP connections to these endpoints")
 	}
 
-	// Test connection timeout
-	fmt.Println("Testing connection timeout...")
+	fmt.Fprintln(os.Stderr, "\n✅ Connection test completed successfully!")
+	fmt.Fprintln(os.Stderr, "Your agent should be able to connect to the Fixpanic infrastructure.")
 
-	testConn, err := net.DialTimeout("tcp", address, 5*time.Second)
+	return nil
+}
+
+// checkClock compares the system clock with the reference server and reports
+// the offset
+func checkClock(ctx context.Context) (time.Duration, error) {
+	fmt.Fprintln(os.Stderr, "\nChecking the system clock...")
+	offset, err := netprobe.ClockOffset(ctx, netprobe.ClockReferenceURL, 10*time.Second)
 	if err != nil {
-		fmt.Printf("⚠️  Connection timeout test failed: %v\n", err)
+		fmt.Printf("⚠️  Could not compare the clock with %s: %v\n", netprobe.ClockReferenceURL, err)
+	} else if skew := absDuration(offset); skew > netprobe.ClockSkewWarn {
+		fmt.Printf("❌ Compared to %s, %s; TLS handshakes may fail\n", netprobe.ClockReferenceURL, netprobe.DescribeClockOffset(offset))
 	} else {
-		testConn.Close()
-		fmt.Println("✅ Connection timeout test passed")
+		fmt.Printf("✅ Clock is within %v of %s\n", skew.Round(time.Second), netprobe.ClockReferenceURL)
 	}
+	if synchronized, known := platform.TimeSynchronized(ctx); known && !synchronized {
+		fmt.Printf("⚠️  The clock is not synchronized with NTP; enable it with 'sudo timedatectl set-ntp true'\n")
+	}
+	return offset, err
+}
 
-	fmt.Println("\n✅ Connection test completed successfully!")
-	fmt.Println("Your agent should be able to connect to the Fixpanic infrastructure.")
+// absDuration returns the magnitude of d
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
 
-	// Additional checks
-	fmt.Println("\nAdditional checks:")
+// testConnection runs every connection test against one endpoint, including
+// the TLS certificate's validity and pins when the agent uses TLS
+func testConnection(ctx context.Context, endpoint string, resolver *net.Resolver, app config.AppSection) connectionResult {
+	result := connectionResult{Endpoint: endpoint, Resolver: connectionResolver}
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		result.DialErr = err
+		return result
+	}
+	result.Host = host
 
-	// Check if we can ping the host
-	if host != "localhost" && host != "127.0.0.1" {
-		fmt.Printf("Testing ping to %s...\n", host)
-		if err := pingHost(host); err != nil {
-			fmt.Printf("⚠️  Ping failed: %v (this is not critical)\n", err)
+	// Resolve the hostname and show addresses per family
+	result.V4, result.V6, result.ResolveErr = netprobe.ResolveVia(ctx, resolver, host)
+
+	// Test TCP connection (dual-stack, Happy Eyeballs)
+	result.Dial, result.DialErr = netprobe.DialVia(ctx, endpoint, 10*time.Second, resolver)
+	if result.DialErr != nil {
+		// Tell a host that is down from a port that is blocked
+		if result.ResolveErr == nil {
+			result.Reach = netprobe.Reach(ctx, host, reachPorts(port), 3*time.Second, resolver)
+		}
+		return result
+	}
+
+	// Certificates outside their validity period or matching no pin fail the
+	// agent's handshake
+	if app.TLSEnabled {
+		result.Pins = app.TLSPinnedSPKI
+		result.Certs, result.CertErr = netprobe.PeerCertificates(ctx, endpoint, 10*time.Second)
+		if result.CertErr == nil {
+			result.CertProblems = netprobe.CertificateTimeProblems(result.Certs, time.Now())
+			result.PinMatch = netprobe.MatchSPKIPins(result.Certs, result.Pins)
+		}
+	}
+
+	// Probe each address family separately to surface partial dual-stack breakage
+	result.Families, result.FamiliesErr = netprobe.ProbeFamilies(ctx, endpoint, 5*time.Second, resolver)
+
+	// Check whether the host answers ICMP, or the endpoint port otherwise
+	if host != "localhost" && host != "127.0.0.1" && host != "::1" {
+		result.Reach = netprobe.Reach(ctx, host, []string{port}, 3*time.Second, resolver)
+	}
+
+	return result
+}
+
+// printConnectionResult reports the tests of one endpoint
+func printConnectionResult(result connectionResult) {
+	fmt.Fprintf(os.Stderr, "\nTesting connection to: %s\n", result.Endpoint)
+	if result.Host == "" {
+		fmt.Printf("❌ Invalid address: %v\n", result.DialErr)
+		return
+	}
+
+	if net.ParseIP(result.Host) == nil {
+		if result.Resolver != "" {
+			fmt.Fprintf(os.Stderr, "Resolving hostname: %s (via %s)\n", result.Host, result.Resolver)
 		} else {
-			fmt.Printf("✅ Ping successful\n")
+			fmt.Fprintf(os.Stderr, "Resolving hostname: %s\n", result.Host)
 		}
 	}
+	if result.ResolveErr != nil {
+		fmt.Printf("⚠️  DNS resolution failed: %v\n", result.ResolveErr)
+	} else {
+		fmt.Printf("✅ IPv4 addresses: %s\n", formatIPs(result.V4))
+		fmt.Printf("✅ IPv6 addresses: %s\n", formatIPs(result.V6))
+	}
 
-	return nil
+	fmt.Fprintf(os.Stderr, "Connecting to %s...\n", result.Endpoint)
+	if result.DialErr != nil {
+		fmt.Printf("❌ Connection failed: %v\n", result.DialErr)
+		printReachResult(result.Reach)
+		if result.Reach != nil && result.Reach.Err == nil {
+			fmt.Printf("💡 The host is up, so the port is probably blocked by a firewall or nothing is listening on it\n")
+		}
+		return
+	}
+	fmt.Printf("✅ TCP connection successful via %s (%s, %v)\n", result.Dial.Family, result.Dial.RemoteAddr, result.Dial.Latency.Round(time.Millisecond))
+	if net.ParseIP(result.Host) == nil {
+		fmt.Printf("   DNS lookup: %v, TCP connect: %v\n", result.Dial.DNSLatency.Round(time.Microsecond), result.Dial.Latency.Round(time.Microsecond))
+	}
+
+	if result.CertErr != nil {
+		fmt.Printf("⚠️  Could not inspect the TLS certificate: %v\n", result.CertErr)
+	} else if len(result.CertProblems) > 0 {
+		for _, problem := range result.CertProblems {
+			fmt.Printf("❌ TLS %s\n", problem)
+		}
+	} else if len(result.Certs) > 0 {
+		fmt.Printf("✅ TLS certificate valid until %s\n", result.Certs[0].NotAfter.Local().Format("2006-01-02"))
+	}
+	if len(result.Certs) > 0 {
+		fmt.Printf("   Public key pin: %s\n", netprobe.SPKIPin(result.Certs[0]))
+		if result.PinMatch != "" {
+			fmt.Printf("✅ Certificate matches pinned key %s\n", result.PinMatch)
+		} else if result.PinMismatch() {
+			fmt.Printf("❌ Certificate matches none of the %d pinned key(s); the connection may be intercepted\n", len(result.Pins))
+			fmt.Printf("   Issued by: %s\n", result.Certs[0].Issuer.String())
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Testing address families...")
+	if result.FamiliesErr != nil {
+		fmt.Printf("⚠️  Address family test failed: %v\n", result.FamiliesErr)
+	} else {
+		for _, family := range result.Families {
+			if family.Error != nil {
+				fmt.Printf("⚠️  %s: unreachable (%v)\n", family.Family, family.Error)
+			} else {
+				fmt.Printf("✅ %s: reachable (%v)\n", family.Family, family.Latency.Round(time.Millisecond))
+			}
+		}
+	}
+
+	printReachResult(result.Reach)
+}
+
+// printReachResult reports whether the host answered and which probe it
+// answered to
+func printReachResult(reach *netprobe.ReachResult) {
+	if reach == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Checking whether %s is reachable...\n", reach.Host)
+	if reach.Err != nil {
+		fmt.Printf("⚠️  Host did not answer: %v (this is not critical, many hosts drop probes)\n", reach.Err)
+	} else {
+		fmt.Printf("✅ Host reachable via %s (%s, %v)\n", reach.Method, reach.Detail, reach.Latency.Round(time.Millisecond))
+	}
+	if reach.ICMPSkipped != nil {
+		fmt.Printf("   ICMP not used: %v\n", reach.ICMPSkipped)
+	}
 }
 
-func pingHost(host string) error {
-	// Simple ping test using net.Dial
-	conn, err := net.DialTimeout("ip4:icmp", host, 3*time.Second)
+// reachPorts returns the TCP ports probed to find out whether the host of a
+// failed endpoint is up, leaving out the endpoint's own port
+func reachPorts(port string) []string {
+	var ports []string
+	for _, p := range []string{"443", "80"} {
+		if p != port {
+			ports = append(ports, p)
+		}
+	}
+	return ports
+}
+
+// resolveSocketServer returns the normalized socket server address to test
+func resolveSocketServer(cmd *cobra.Command, platformInfo *platform.PlatformInfo) (string, error) {
+	socketServer := config.DefaultSocketServer
+	if cmd.Flags().Changed("socket-server") {
+		socketServer, _ = cmd.Flags().GetString("socket-server")
+	} else if agentConfig, err := config.LoadConfig(platformInfo.GetConfigPath()); err == nil {
+		socketServer = agentConfig.GetSocketServer()
+	}
+
+	normalized, err := netprobe.NormalizeEndpoint(socketServer, config.DefaultSocketPort)
 	if err != nil {
-		return err
+		return "", clierror.New(clierror.Config, "invalid socket server address: %w", err)
 	}
-	conn.Close()
-	return nil
+	return normalized, nil
+}
+
+// formatIPs renders a list of addresses for display
+func formatIPs(ips []net.IP) string {
+	if len(ips) == 0 {
+		return "none"
+	}
+	parts := make([]string, len(ips))
+	for i, ip := range ips {
+		parts[i] = ip.String()
+	}
+	return strings.Join(parts, ", ")
 }
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fixpanic/fixpanic-cli/internal/audit"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/service"
+	"github.com/fixpanic/fixpanic-cli/internal/textdiff"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change
+const diffContextLines = 2
+
+var acceptDiff bool
+
+// agentDiffCmd represents the agent diff command
+var agentDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show manual changes to the agent configuration and service unit",
+	Long: `Compare the on-disk configuration and service unit against what this CLI
+version would generate from the same inputs (agent ID, API key, socket server
+and profile). Values changed with 'fixpanic config set' show up as drift too.
+
+Differences are manual edits or leftovers from older CLI versions. Spotting
+them before an upgrade avoids surprises on hand-tuned hosts. Lines prefixed
+with "-" are on disk, lines prefixed with "+" would be generated.
+
+Exits with code 1 when drift is found. --accept re-renders both files, keeping
+the edited versions as .bak files.`,
+	Example: `  # Show drift
+  fixpanic agent diff
+
+  # Discard manual edits and re-render the files
+  sudo fixpanic agent diff --accept`,
+	Annotations: map[string]string{annotationMutating: "accept", annotationRequires: requireInstalled + "," + requireConfig + "," + requireLock},
+	RunE:        runAgentDiff,
+}
+
+// driftTarget is a file the CLI generates, compared against its on-disk content
+type driftTarget struct {
+	Name    string
+	Path    string
+	Actual  string
+	Desired string
+	Missing bool
+	Drifted bool
+}
+
+func init() {
+	agentCmd.AddCommand(agentDiffCmd)
+
+	// Add flags
+	agentDiffCmd.Flags().BoolVar(&acceptDiff, "accept", false, "Re-render the configuration and service unit, discarding manual edits")
+}
+
+func runAgentDiff(cmd *cobra.Command, args []string) error {
+	logger.Header("Agent Configuration Drift")
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	configPath := platformInfo.GetConfigPath()
+	agentConfig := commandConfig()
+
+	desiredConfig, err := renderDesiredConfig(agentConfig, platformInfo)
+	if err != nil {
+		return clierror.New(clierror.Config, "invalid configuration: %w", err).
+			WithHint(i18n.Sprintf("Fix the reported value in %s", configPath), hintReinstallAgent)
+	}
+	if err := desiredConfig.Validate(); err != nil {
+		return clierror.New(clierror.Config, "invalid configuration: %w", err).
+			WithHint(i18n.Sprintf("Fix the reported value in %s", configPath), hintReinstallAgent)
+	}
+
+	targets, err := collectDriftTargets(platformInfo, desiredConfig)
+	if err != nil {
+		return err
+	}
+
+	drifted := false
+	for _, target := range targets {
+		logger.Separator()
+		logger.KeyValue(target.Name, target.Path)
+		if !target.Drifted {
+			logger.Success("No drift")
+			continue
+		}
+
+		drifted = true
+		if target.Missing {
+			logger.Warning("File is missing")
+			continue
+		}
+		printDrift(target, agentConfig.App.APIKey)
+	}
+
+	logger.Separator()
+	if !drifted {
+		logger.Success("On-disk files match what this CLI version generates")
+		return nil
+	}
+
+	if !acceptDiff {
+		return clierror.New(clierror.General, "configuration drift detected").
+			WithHint("Run 'fixpanic agent diff --accept' to re-render the files (edited versions are kept as .bak)")
+	}
+
+	return acceptDrift(cmd.Context(), platformInfo, desiredConfig, targets)
+}
+
+// renderDesiredConfig returns the configuration agent install would write for
+// the inputs of the current configuration, including its profile. Like
+// config.InstallConfig it keeps what the host owner set up (see
+// config.PreserveUserFields), so accepting the drift doesn't drop the command
+// policy or TLS material.
+func renderDesiredConfig(current *config.AgentConfig, platformInfo *platform.PlatformInfo) (*config.AgentConfig, error) {
+	desired, err := config.ProfileConfig(current.Profile)
+	if err != nil {
+		return nil, err
+	}
+	desired.Logging.File = platformInfo.GetLogPath()
+	desired.App.AgentID = current.App.AgentID
+	desired.App.APIKey = current.App.APIKey
+	desired.App.SocketServer = current.GetSocketServer()
+	config.PreserveUserFields(desired, current)
+	return desired, nil
+}
+
+// collectDriftTargets reads the generated files and renders their desired content
+func collectDriftTargets(platformInfo *platform.PlatformInfo, desiredConfig *config.AgentConfig) ([]driftTarget, error) {
+	desiredData, err := yaml.Marshal(desiredConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render configuration: %w", err)
+	}
+
+	targets := []driftTarget{{
+		Name:    "Configuration",
+		Path:    platformInfo.GetConfigPath(),
+		Desired: string(desiredData),
+	}}
+
+	if platform.IsSystemdAvailable() {
+		unit, err := service.NewManager(platformInfo).Render()
+		if err != nil {
+			return nil, fmt.Errorf("failed to render service unit: %w", err)
+		}
+		targets = append(targets, driftTarget{
+			Name:    "Service unit",
+			Path:    platformInfo.GetServiceFilePath(),
+			Desired: unit,
+		})
+	}
+
+	for i := range targets {
+		target := &targets[i]
+		data, err := os.ReadFile(target.Path)
+		switch {
+		case os.IsNotExist(err):
+			target.Missing = true
+		case err != nil:
+			return nil, fmt.Errorf("failed to read %s: %w", target.Path, err)
+		default:
+			target.Actual = string(data)
+		}
+		target.Drifted = target.Missing || textdiff.Changed(textdiff.Lines(target.Actual, target.Desired))
+	}
+
+	return targets, nil
+}
+
+// printDrift prints the changed lines of a target with the API key redacted
+func printDrift(target driftTarget, apiKey string) {
+	actual, desired := target.Actual, target.Desired
+	if apiKey != "" {
+		actual = strings.ReplaceAll(actual, apiKey, audit.Redacted)
+		desired = strings.ReplaceAll(desired, apiKey, audit.Redacted)
+	}
+
+	for i, hunk := range textdiff.Hunks(textdiff.Lines(actual, desired), diffContextLines) {
+		if i > 0 {
+			logger.DiffLine(" ", "...")
+		}
+		for _, line := range hunk {
+			switch line.Kind {
+			case textdiff.Delete:
+				logger.DiffLine("-", line.Text)
+			case textdiff.Insert:
+				logger.DiffLine("+", line.Text)
+			default:
+				logger.DiffLine(" ", line.Text)
+			}
+		}
+	}
+}
+
+// acceptDrift re-renders the drifted files, backing up their edited versions
+func acceptDrift(ctx context.Context, platformInfo *platform.PlatformInfo, desiredConfig *config.AgentConfig, targets []driftTarget) error {
+	for _, target := range targets {
+		if !target.Drifted {
+			continue
+		}
+
+		if !target.Missing {
+			backupPath := target.Path + ".bak"
+			if err := os.WriteFile(backupPath, []byte(target.Actual), 0600); err != nil {
+				return fmt.Errorf("failed to back up %s: %w", target.Path, err)
+			}
+			logger.KeyValue("Backup", backupPath)
+		}
+
+		if target.Path == platformInfo.GetConfigPath() {
+			if err := config.SaveConfig(desiredConfig, target.Path); err != nil {
+				return fmt.Errorf("failed to save configuration: %w", err)
+			}
+		} else if err := service.NewManager(platformInfo).Install(ctx); err != nil {
+			return fmt.Errorf("failed to install service unit: %w", err)
+		}
+		logger.Success("Re-rendered %s", target.Path)
+	}
+
+	logger.Info("Run 'fixpanic agent restart' for the agent to pick up the changes")
+	return nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fixpanic/fixpanic-cli/internal/cloudmeta"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+)
+
+func TestRenderDesiredConfigKeepsInstallInputs(t *testing.T) {
+	platformInfo := &platform.PlatformInfo{LogDir: "/var/log/fixpanic"}
+	current := config.DefaultConfig()
+	current.App.AgentID = "agent_123"
+	current.App.APIKey = "fp_test"
+	current.App.TLSCAFile = "/etc/pki/corp-ca.pem"
+	current.App.TLSCertFile = "/etc/fixpanic/client.pem"
+	current.App.TLSKeyFile = "/etc/fixpanic/client.key"
+	current.App.TLSPinnedSPKI = []string{"sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="}
+	current.App.Cloud = &cloudmeta.Instance{Provider: "aws", InstanceID: "i-0123456789abcdef0", Region: "eu-central-1"}
+	current.Service.Confine = true
+	current.Policy = config.PolicySection{
+		DenyCommands: []string{"rm -rf *"},
+		BlockedPaths: []string{"/etc/shadow"},
+		TimeWindows:  []string{"Mon-Fri 09:00-17:00"},
+	}
+
+	desired, err := renderDesiredConfig(current, platformInfo)
+	if err != nil {
+		t.Fatalf("renderDesiredConfig() error = %v", err)
+	}
+
+	checks := []struct {
+		field     string
+		got, want any
+	}{
+		{"app.agent_id", desired.App.AgentID, current.App.AgentID},
+		{"app.api_key", desired.App.APIKey, current.App.APIKey},
+		{"app.tls_ca_file", desired.App.TLSCAFile, current.App.TLSCAFile},
+		{"app.tls_cert_file", desired.App.TLSCertFile, current.App.TLSCertFile},
+		{"app.tls_key_file", desired.App.TLSKeyFile, current.App.TLSKeyFile},
+		{"service.confine", desired.Service.Confine, true},
+		{"logging.file", desired.Logging.File, platformInfo.GetLogPath()},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("%s = %v, want %v", check.field, check.got, check.want)
+		}
+	}
+	if !reflect.DeepEqual(desired.App.TLSPinnedSPKI, current.App.TLSPinnedSPKI) {
+		t.Errorf("app.tls_pinned_spki = %q, want %q", desired.App.TLSPinnedSPKI, current.App.TLSPinnedSPKI)
+	}
+	if !reflect.DeepEqual(desired.App.Cloud, current.App.Cloud) {
+		t.Errorf("app.cloud = %+v, want %+v", desired.App.Cloud, current.App.Cloud)
+	}
+	if !reflect.DeepEqual(desired.Policy, current.Policy) {
+		t.Errorf("policy = %+v, want %+v", desired.Policy, current.Policy)
+	}
+}
@@ -0,0 +1,614 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/cleanup"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/diskspace"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/netprobe"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// agentDoctorCmd represents the agent doctor command
+var agentDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose common agent problems",
+	Long: `Run a series of health checks against the local agent installation.
+
+Each check reports OK, WARN or FAIL together with details and a suggested fix.
+The command exits with an error if any check fails.`,
+	Example: `  # Diagnose the agent installation
+  fixpanic agent doctor`,
+	RunE: runAgentDoctor,
+}
+
+func init() {
+	agentCmd.AddCommand(agentDoctorCmd)
+}
+
+// doctorStatus is the outcome of a single doctor check
+type doctorStatus int
+
+const (
+	doctorOK doctorStatus = iota
+	doctorWarn
+	doctorFail
+	doctorSkip
+)
+
+// doctorResult is what a doctor check reports
+type doctorResult struct {
+	Status  doctorStatus
+	Summary string
+	Details []string
+	Hint    string
+}
+
+// doctorEnv is the shared state handed to every doctor check
+type doctorEnv struct {
+	Platform     *platform.PlatformInfo
+	Connectivity *connectivity.Manager
+	Config       *config.AgentConfig
+	ConfigErr    error
+
+	// clock caches the clock offset, which several checks need
+	clockChecked bool
+	clockOffset  time.Duration
+	clockErr     error
+}
+
+// ClockOffset returns how far the local clock is off, asking the reference
+// server only once per doctor run
+func (env *doctorEnv) ClockOffset(ctx context.Context) (time.Duration, error) {
+	if !env.clockChecked {
+		env.clockChecked = true
+		env.clockOffset, env.clockErr = netprobe.ClockOffset(ctx, netprobe.ClockReferenceURL, 10*time.Second)
+	}
+	return env.clockOffset, env.clockErr
+}
+
+// doctorCheck is a named diagnostic
+type doctorCheck struct {
+	Name string
+	Run  func(ctx context.Context, env *doctorEnv) doctorResult
+}
+
+// doctorChecks lists all checks in the order they are run
+var doctorChecks = []doctorCheck{
+	{Name: "Environment", Run: checkDoctorEnvironment},
+	{Name: "Agent binary", Run: checkDoctorBinary},
+	{Name: "Configuration", Run: checkDoctorConfig},
+	{Name: "Service health", Run: checkDoctorServiceHealth},
+	{Name: "Journal", Run: checkDoctorJournal},
+	{Name: "Confinement", Run: checkDoctorConfinement},
+	{Name: "Clock", Run: checkDoctorClock},
+	{Name: "Socket server", Run: checkDoctorSocketServer},
+	{Name: "TLS settings", Run: checkDoctorTLSSettings},
+	{Name: "TLS certificate", Run: checkDoctorCertificate},
+	{Name: "Temporary files", Run: checkDoctorTempFiles},
+}
+
+func runAgentDoctor(cmd *cobra.Command, args []string) error {
+	logger.Header("FixPanic Agent Doctor")
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	env := &doctorEnv{
+		Platform:     platformInfo,
+		Connectivity: connectivity.NewManager(platformInfo),
+	}
+	env.Config, env.ConfigErr = config.LoadConfig(platformInfo.GetConfigPath())
+
+	var warnings, failures int
+	for _, check := range doctorChecks {
+		result := check.Run(cmd.Context(), env)
+
+		switch result.Status {
+		case doctorOK:
+			fmt.Printf("✅ %s: %s\n", check.Name, result.Summary)
+		case doctorWarn:
+			warnings++
+			fmt.Printf("⚠️  %s: %s\n", check.Name, result.Summary)
+		case doctorFail:
+			failures++
+			fmt.Printf("❌ %s: %s\n", check.Name, result.Summary)
+		case doctorSkip:
+			fmt.Printf("⏭️  %s: %s\n", check.Name, result.Summary)
+		}
+
+		for _, detail := range result.Details {
+			fmt.Printf("     %s\n", detail)
+		}
+		if result.Hint != "" {
+			fmt.Printf("   💡 %s\n", result.Hint)
+		}
+	}
+
+	logger.Separator()
+	if failures > 0 {
+		return fmt.Errorf("doctor found %d problem(s) and %d warning(s)", failures, warnings)
+	}
+	if warnings > 0 {
+		logger.Warning("Doctor finished with %d warning(s)", warnings)
+	} else {
+		logger.Success("No problems found")
+	}
+
+	return nil
+}
+
+func checkDoctorEnvironment(ctx context.Context, env *doctorEnv) doctorResult {
+	environment := platform.DetectEnvironment()
+
+	summary := environment.String()
+	if probe := platform.ProbeSystemd(); probe.Available() {
+		summary += fmt.Sprintf(", systemd %s", probe.State)
+	} else {
+		summary += fmt.Sprintf(", no systemd (%s)", probe.Reason())
+	}
+
+	if advice := adviseEnvironment(environment); advice != nil {
+		return doctorResult{
+			Status:  doctorWarn,
+			Summary: summary,
+			Details: []string{advice.Problem},
+			Hint:    advice.Hint,
+		}
+	}
+
+	return doctorResult{Status: doctorOK, Summary: summary}
+}
+
+func checkDoctorBinary(ctx context.Context, env *doctorEnv) doctorResult {
+	if !env.Connectivity.IsFixPanicAgentInstalled() {
+		return doctorResult{
+			Status:  doctorFail,
+			Summary: "agent binary not found",
+			Details: []string{env.Platform.GetFixPanicAgentBinaryPath()},
+			Hint:    "Run 'fixpanic agent install' to install the agent",
+		}
+	}
+
+	version, err := env.Connectivity.GetFixPanicAgentVersion(ctx)
+	if err != nil {
+		return doctorResult{
+			Status:  doctorFail,
+			Summary: "agent binary does not run",
+			Details: []string{err.Error()},
+			Hint:    "Reinstall the agent with 'fixpanic agent install --force'",
+		}
+	}
+
+	return doctorResult{Status: doctorOK, Summary: connectivity.ParseAgentVersion(version)}
+}
+
+func checkDoctorConfig(ctx context.Context, env *doctorEnv) doctorResult {
+	if env.ConfigErr != nil {
+		return doctorResult{
+			Status:  doctorFail,
+			Summary: "configuration could not be loaded",
+			Details: []string{env.ConfigErr.Error()},
+			Hint:    "Reinstall the agent with 'fixpanic agent install --force'",
+		}
+	}
+
+	if err := env.Config.Validate(); err != nil {
+		return doctorResult{
+			Status:  doctorFail,
+			Summary: "configuration is invalid",
+			Details: []string{err.Error()},
+		}
+	}
+
+	return doctorResult{Status: doctorOK, Summary: env.Platform.GetConfigPath()}
+}
+
+func checkDoctorServiceHealth(ctx context.Context, env *doctorEnv) doctorResult {
+	report := detectCrashLoop(ctx, env.Platform)
+	if report == nil {
+		return doctorResult{Status: doctorSkip, Summary: "no restart information available"}
+	}
+
+	if report.Looping {
+		return doctorResult{
+			Status:  doctorFail,
+			Summary: report.Summary(),
+			Details: report.Excerpt,
+			Hint:    "Inspect the full logs with 'fixpanic agent logs --lines=200'",
+		}
+	}
+
+	return doctorResult{Status: doctorOK, Summary: fmt.Sprintf("%s (%d restart(s))", report.State, report.Restarts)}
+}
+
+func checkDoctorJournal(ctx context.Context, env *doctorEnv) doctorResult {
+	if !platform.IsSystemdAvailable() {
+		return doctorResult{Status: doctorSkip, Summary: "systemd journal not in use"}
+	}
+
+	suppressed, err := service.NewManager(env.Platform).SuppressedMessages(ctx, journalSuppressedPeriod)
+	if err != nil {
+		return doctorResult{Status: doctorSkip, Summary: err.Error()}
+	}
+	if suppressed > 0 {
+		return doctorResult{
+			Status:  doctorWarn,
+			Summary: fmt.Sprintf("journald dropped %d agent message(s) in the last 24 hours (rate limit)", suppressed),
+			Details: strings.Split(service.RateLimitOverride, "\n"),
+			Hint:    fmt.Sprintf("Add the settings above with 'sudo systemctl edit %s'", platform.GetSystemdServiceName()),
+		}
+	}
+
+	usage, err := service.JournalDiskUsage(ctx)
+	if err != nil {
+		return doctorResult{Status: doctorSkip, Summary: err.Error()}
+	}
+	if usage > service.JournalUsageWarnBytes {
+		return doctorResult{
+			Status:  doctorWarn,
+			Summary: fmt.Sprintf("journal takes %s on disk", diskspace.FormatBytes(usage)),
+			Hint:    "Limit it with SystemMaxUse= in /etc/systemd/journald.conf, or run 'sudo journalctl --vacuum-size=1G'",
+		}
+	}
+
+	return doctorResult{Status: doctorOK, Summary: fmt.Sprintf("no dropped messages, journal takes %s", diskspace.FormatBytes(usage))}
+}
+
+// confinementDenialPeriod is how far back doctor looks for operations the
+// confinement blocked
+const confinementDenialPeriod = 24 * time.Hour
+
+func checkDoctorConfinement(ctx context.Context, env *doctorEnv) doctorResult {
+	if env.Config == nil || !env.Config.Service.Confine {
+		return doctorResult{Status: doctorSkip, Summary: "not enabled (see 'fixpanic agent install --confine')"}
+	}
+	if !platform.IsSystemdAvailable() {
+		return doctorResult{
+			Status:  doctorWarn,
+			Summary: "service.confine is set, but confinement needs systemd; the agent runs unconfined",
+		}
+	}
+
+	hintRegenerate := "Regenerate the service unit with 'sudo fixpanic agent diff --accept' and restart the agent"
+	unit, err := os.ReadFile(env.Platform.GetServiceFilePath())
+	if err != nil || !strings.Contains(string(unit), "SystemCallFilter=") {
+		return doctorResult{
+			Status:  doctorFail,
+			Summary: "service unit has no confinement settings",
+			Hint:    hintRegenerate,
+		}
+	}
+	appArmor := strings.Contains(string(unit), "AppArmorProfile=")
+	if appArmor {
+		if mode := service.AppArmorProfileMode(); mode == "" {
+			return doctorResult{
+				Status:  doctorFail,
+				Summary: fmt.Sprintf("AppArmor profile %s is not loaded; the agent can't start", service.AppArmorProfileName),
+				Hint:    hintRegenerate,
+			}
+		} else if mode != "enforce" {
+			return doctorResult{
+				Status:  doctorWarn,
+				Summary: fmt.Sprintf("AppArmor profile %s is in %s mode", service.AppArmorProfileName, mode),
+				Hint:    fmt.Sprintf("Enforce it with 'sudo aa-enforce %s'", service.AppArmorProfilePath),
+			}
+		}
+	}
+
+	running, pid := detectAgentRunning(ctx, env.Platform)
+	if !running {
+		return doctorResult{Status: doctorSkip, Summary: "agent is not running"}
+	}
+	seccomp, label := service.ProcessConfinement(pid)
+	var missing []string
+	if !seccomp {
+		missing = append(missing, "no system call filter")
+	}
+	if appArmor && !strings.HasPrefix(label, service.AppArmorProfileName) {
+		missing = append(missing, "no AppArmor profile")
+	}
+	if len(missing) > 0 {
+		return doctorResult{
+			Status:  doctorFail,
+			Summary: fmt.Sprintf("agent (PID %d) runs with %s", pid, strings.Join(missing, " and ")),
+			Hint:    "Restart the agent with 'sudo fixpanic agent restart' to apply the confinement",
+		}
+	}
+
+	summary := fmt.Sprintf("system call filter active (PID %d)", pid)
+	if label != "" {
+		summary += fmt.Sprintf(", AppArmor %s", label)
+	}
+	denials, err := service.NewManager(env.Platform).ConfinementDenials(ctx, confinementDenialPeriod)
+	if err != nil {
+		return doctorResult{Status: doctorOK, Summary: summary, Details: []string{err.Error()}}
+	}
+	if len(denials) > 0 {
+		if len(denials) > crashLoopExcerptLines {
+			denials = denials[len(denials)-crashLoopExcerptLines:]
+		}
+		return doctorResult{
+			Status:  doctorWarn,
+			Summary: summary + fmt.Sprintf("; blocked operations in the last %s", confinementDenialPeriod),
+			Details: denials,
+			Hint:    "If the agent needs these operations, report them to Fixpanic support; 'sudo fixpanic config set service.confine false' and 'sudo fixpanic agent diff --accept' lift the confinement",
+		}
+	}
+	return doctorResult{Status: doctorOK, Summary: summary + ", nothing blocked"}
+}
+
+func checkDoctorSocketServer(ctx context.Context, env *doctorEnv) doctorResult {
+	socketServer := config.DefaultSocketServer
+	if env.Config != nil {
+		socketServer = env.Config.GetSocketServer()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result, err := netprobe.Dial(ctx, socketServer, 10*time.Second)
+	if err != nil {
+		return doctorResult{
+			Status:  doctorFail,
+			Summary: fmt.Sprintf("cannot connect to %s", socketServer),
+			Details: []string{err.Error()},
+			Hint:    "Run 'fixpanic agent test-connection' for detailed network diagnostics",
+		}
+	}
+
+	return doctorResult{
+		Status:  doctorOK,
+		Summary: fmt.Sprintf("%s reachable via %s (%v)", socketServer, result.Family, result.Latency.Round(time.Millisecond)),
+	}
+}
+
+func checkDoctorClock(ctx context.Context, env *doctorEnv) doctorResult {
+	var details []string
+	synchronized, known := platform.TimeSynchronized(ctx)
+	if known {
+		details = append(details, fmt.Sprintf("NTP synchronized: %t", synchronized))
+	}
+	hint := "Enable time synchronization with 'sudo timedatectl set-ntp true', or install chrony or ntpd"
+
+	offset, err := env.ClockOffset(ctx)
+	if err != nil {
+		if known && !synchronized {
+			return doctorResult{
+				Status:  doctorWarn,
+				Summary: "clock is not synchronized with NTP",
+				Details: append(details, fmt.Sprintf("could not compare with %s: %v", netprobe.ClockReferenceURL, err)),
+				Hint:    hint,
+			}
+		}
+		return doctorResult{Status: doctorSkip, Summary: fmt.Sprintf("could not compare with %s: %v", netprobe.ClockReferenceURL, err), Details: details}
+	}
+
+	details = append(details, fmt.Sprintf("offset from %s: %v", netprobe.ClockReferenceURL, offset.Round(time.Second)))
+	skew := offset
+	if skew < 0 {
+		skew = -skew
+	}
+	switch {
+	case skew > netprobe.ClockSkewFail:
+		return doctorResult{
+			Status:  doctorFail,
+			Summary: netprobe.DescribeClockOffset(offset) + "; TLS handshakes will fail",
+			Details: details,
+			Hint:    hint,
+		}
+	case skew > netprobe.ClockSkewWarn:
+		return doctorResult{
+			Status:  doctorWarn,
+			Summary: netprobe.DescribeClockOffset(offset),
+			Details: details,
+			Hint:    hint,
+		}
+	case known && !synchronized:
+		return doctorResult{
+			Status:  doctorWarn,
+			Summary: "clock is correct but not synchronized with NTP, so it will drift",
+			Details: details,
+			Hint:    hint,
+		}
+	}
+
+	return doctorResult{Status: doctorOK, Summary: fmt.Sprintf("within %v of %s", skew.Round(time.Second), netprobe.ClockReferenceURL), Details: details}
+}
+
+func checkDoctorTLSSettings(ctx context.Context, env *doctorEnv) doctorResult {
+	if env.Config == nil {
+		return doctorResult{Status: doctorSkip, Summary: "configuration not loaded"}
+	}
+	if keys := insecureTLSKeys(env.Config); len(keys) > 0 {
+		details := make([]string, len(keys))
+		for i, key := range keys {
+			details[i] = key + ": true"
+		}
+		return doctorResult{
+			Status:  doctorWarn,
+			Summary: "CERTIFICATE VERIFICATION IS DISABLED; anyone on the network path can intercept the agent's traffic",
+			Details: details,
+			Hint:    "Set these keys to false with 'sudo fixpanic config set', and trust a private CA with app.tls_ca_file instead",
+		}
+	}
+	if !env.Config.App.TLSEnabled || !env.Config.ReqHandler.TLSEnabled {
+		return doctorResult{
+			Status:  doctorWarn,
+			Summary: "TLS is disabled; the agent's traffic is not encrypted",
+			Hint:    "Enable it with 'sudo fixpanic config set app.tls_enabled true' (and req_handler.tls_enabled)",
+		}
+	}
+	if _, err := env.Config.App.LoadTLSMaterial(); err != nil {
+		return doctorResult{
+			Status:  doctorFail,
+			Summary: "CA bundle or client certificate can't be used",
+			Details: []string{err.Error()},
+			Hint:    "Run 'fixpanic config test' for details",
+		}
+	}
+
+	summary := "TLS enabled, certificates verified against the system roots"
+	if env.Config.App.TLSCAFile != "" {
+		summary = fmt.Sprintf("TLS enabled, certificates verified against %s", env.Config.App.TLSCAFile)
+	}
+	return doctorResult{Status: doctorOK, Summary: summary}
+}
+
+func checkDoctorCertificate(ctx context.Context, env *doctorEnv) doctorResult {
+	if env.Config == nil {
+		return doctorResult{Status: doctorSkip, Summary: "configuration not loaded"}
+	}
+	if !env.Config.App.TLSEnabled {
+		return doctorResult{Status: doctorSkip, Summary: "TLS is disabled in the agent configuration"}
+	}
+	socketServer := env.Config.GetSocketServer()
+
+	chain, err := netprobe.PeerCertificates(ctx, socketServer, 10*time.Second)
+	if err != nil {
+		return doctorResult{Status: doctorSkip, Summary: err.Error()}
+	}
+
+	now := time.Now()
+	problems := netprobe.CertificateTimeProblems(chain, now)
+	if len(problems) == 0 {
+		return doctorResult{
+			Status:  doctorOK,
+			Summary: fmt.Sprintf("%s valid until %s", socketServer, chain[0].NotAfter.Local().Format("2006-01-02")),
+		}
+	}
+
+	// Tell a wrong local clock from a certificate that really is out of date
+	hint := "The server certificate is out of date; contact Fixpanic support"
+	if offset, err := env.ClockOffset(ctx); err == nil && len(netprobe.CertificateTimeProblems(chain, now.Add(-offset))) == 0 {
+		hint = fmt.Sprintf("The certificate is fine but %s; fix the system time", netprobe.DescribeClockOffset(offset))
+	}
+	return doctorResult{
+		Status:  doctorFail,
+		Summary: fmt.Sprintf("%s presents a certificate that is not valid now", socketServer),
+		Details: problems,
+		Hint:    hint,
+	}
+}
+
+func checkDoctorTempFiles(ctx context.Context, env *doctorEnv) doctorResult {
+	leftovers, err := findStaleTempFiles(env.Platform, cleanup.DefaultMaxAge)
+	if err != nil {
+		return doctorResult{Status: doctorSkip, Summary: err.Error()}
+	}
+	if len(leftovers) == 0 {
+		return doctorResult{Status: doctorOK, Summary: "no stale temporary files"}
+	}
+
+	var size int64
+	details := make([]string, 0, len(leftovers))
+	for _, leftover := range leftovers {
+		size += leftover.Size
+		details = append(details, leftover.Path)
+	}
+
+	return doctorResult{
+		Status:  doctorWarn,
+		Summary: fmt.Sprintf("%d stale item(s) left by interrupted runs (%s)", len(leftovers), formatSize(size)),
+		Details: details,
+		Hint:    "Run 'fixpanic cleanup-temp' to remove them",
+	}
+}
+
+// crashLoopExcerptLines is how many log lines are shown for a crash loop
+const crashLoopExcerptLines = 10
+
+// crashLoopReport describes the restart behaviour of the agent service
+type crashLoopReport struct {
+	Looping      bool
+	State        string
+	Restarts     int
+	LastExitCode int
+	Excerpt      []string
+}
+
+// Summary renders a one-line description of the crash loop
+func (r *crashLoopReport) Summary() string {
+	return fmt.Sprintf("agent is crash looping (%d restarts, last exit code %d)", r.Restarts, r.LastExitCode)
+}
+
+// detectCrashLoop inspects the watchdog's restart counter when the agent is
+// supervised by it, and systemd restart counters otherwise.
+// It returns nil when no restart information is available.
+func detectCrashLoop(ctx context.Context, platformInfo *platform.PlatformInfo) *crashLoopReport {
+	if report := detectWatchdogCrashLoop(platformInfo); report != nil {
+		return report
+	}
+	if !platform.IsSystemdAvailable() {
+		return nil
+	}
+
+	serviceManager := service.NewManager(platformInfo)
+	health, err := serviceManager.Health(ctx)
+	if err != nil {
+		return nil
+	}
+
+	report := &crashLoopReport{
+		Looping:      health.IsCrashLooping(),
+		State:        health.ActiveState,
+		Restarts:     health.Restarts,
+		LastExitCode: health.LastExitCode,
+	}
+	if health.SubState != "" {
+		report.State = fmt.Sprintf("%s (%s)", health.ActiveState, health.SubState)
+	}
+
+	if report.Looping {
+		if logs, err := serviceManager.GetServiceLogs(ctx, crashLoopExcerptLines); err == nil {
+			for _, line := range strings.Split(strings.TrimSpace(logs), "\n") {
+				if line != "" {
+					report.Excerpt = append(report.Excerpt, line)
+				}
+			}
+		}
+	}
+
+	return report
+}
+
+// detectWatchdogCrashLoop builds a crash loop report from the watchdog status file
+func detectWatchdogCrashLoop(platformInfo *platform.PlatformInfo) *crashLoopReport {
+	status := readWatchdogStatus(platformInfo)
+	if status == nil {
+		return nil
+	}
+
+	report := &crashLoopReport{
+		Looping:  status.IsCrashLooping(service.CrashLoopRestartThreshold, service.CrashLoopWindow),
+		State:    fmt.Sprintf("watchdog %s", status.State),
+		Restarts: status.Restarts,
+	}
+	if code, err := parseExitCode(status.LastExit); err == nil {
+		report.LastExitCode = code
+	}
+
+	if report.Looping {
+		report.Excerpt, _ = readLastLines(platformInfo.GetLogPath(), crashLoopExcerptLines)
+	}
+
+	return report
+}
+
+// parseExitCode extracts the numeric code from an "exit status N" message
+func parseExitCode(message string) (int, error) {
+	var code int
+	_, err := fmt.Sscanf(message, "exit status %d", &code)
+	return code, err
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/agentctl"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/summary"
+	"github.com/spf13/cobra"
+)
+
+// drainPollInterval is how often the active sessions are checked while
+// draining
+const drainPollInterval = 2 * time.Second
+
+var (
+	drainAgentFirst bool
+	drainTimeout    time.Duration
+	// drainedClient is the client of the agent drainAgent drained, nil if
+	// none, so it can be resumed if it ends up not being stopped
+	drainedClient *agentctl.Client
+)
+
+// addDrainFlags adds --drain to a command that stops the agent
+func addDrainFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&drainAgentFirst, "drain", false, "Stop accepting new remote-debug sessions and wait for the active ones to end before stopping the agent")
+	cmd.Flags().DurationVar(&drainTimeout, "drain-timeout", 10*time.Minute, "How long --drain waits for the active sessions to end")
+}
+
+// drainAgent asks the running agent, for --drain, to refuse new sessions and
+// waits up to --drain-timeout for the active ones to end. If they don't, the
+// agent accepts sessions again and the command fails without stopping it.
+func drainAgent(ctx context.Context) error {
+	if !drainAgentFirst {
+		return nil
+	}
+	client, err := sessionsClient()
+	if err != nil {
+		return err
+	}
+
+	err = client.Drain(ctx)
+	switch {
+	case errors.Is(err, agentctl.ErrNotListening):
+		// Nothing serves sessions, so there is nothing to wait for
+		return nil
+	case errors.Is(err, agentctl.ErrUnsupported):
+		logger.Warning("The agent can't refuse new sessions; only waiting for the active ones to end")
+	case err != nil:
+		return sessionsError(err)
+	default:
+		drainedClient = client
+		logger.Info("The agent no longer accepts new sessions")
+	}
+
+	deadline := time.Now().Add(drainTimeout)
+	waiting := -1
+	for {
+		sessions, err := client.Sessions(ctx)
+		if errors.Is(err, agentctl.ErrNotListening) {
+			return nil
+		}
+		if err != nil {
+			resumeAgent()
+			return sessionsError(err)
+		}
+		if len(sessions) == 0 {
+			if waiting > 0 {
+				logger.Success("All sessions ended")
+			}
+			summary.Record("Drain sessions", summary.OK, "")
+			return nil
+		}
+		if time.Now().After(deadline) {
+			resumeAgent()
+			summary.Record("Drain sessions", summary.Failed, fmt.Sprintf("%d session(s) still active", len(sessions)))
+			return clierror.New(clierror.Busy, "%d remote-debug session(s) still active after %s; the agent was not stopped", len(sessions), drainTimeout).
+				WithHint("Run 'fixpanic agent sessions list' to see who is connected").
+				WithHint("Wait longer with --drain-timeout, or end the sessions with 'fixpanic agent sessions kill --all'")
+		}
+		if len(sessions) != waiting {
+			waiting = len(sessions)
+			logger.Progress("Waiting for %d active session(s) to end (up to %s)...", waiting, time.Until(deadline).Round(time.Second))
+		}
+
+		select {
+		case <-time.After(drainPollInterval):
+		case <-ctx.Done():
+			resumeAgent()
+			return ctx.Err()
+		}
+	}
+}
+
+// resumeAgent makes the agent drained by drainAgent accept sessions again,
+// for when it isn't stopped after all
+func resumeAgent() {
+	if drainedClient == nil {
+		return
+	}
+	// Resume even when the command was cancelled
+	if err := drainedClient.Resume(context.Background()); err != nil && !errors.Is(err, agentctl.ErrNotListening) {
+		logger.Warning("%v", err)
+		logger.Info("Restart the agent with 'fixpanic agent restart' to accept new sessions again")
+	}
+	drainedClient = nil
+}
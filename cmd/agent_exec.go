@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var execNoConfig bool
+
+// agentExecCmd represents the agent exec command
+var agentExecCmd = &cobra.Command{
+	Use:   "exec -- <args...>",
+	Short: "Run the installed agent binary with arbitrary arguments",
+	Long: `Run the installed connectivity-layer binary with the given arguments, for
+agent-native flags and debug subcommands the CLI doesn't wrap.
+
+The binary runs in the foreground with this terminal's input and output, and
+its exit status becomes the exit status of this command. --config with the
+agent configuration is appended unless the arguments already contain it or
+--no-config is given. The variables of the service section of the
+configuration (service.environment and service.environment_file) are added
+to the environment, so the binary sees what the service would.
+
+Put the agent's arguments after --, so they aren't parsed as CLI flags.`,
+	Example: `  # Show the agent's own help
+  fixpanic agent exec -- --help
+
+  # Run an agent debug subcommand against the installed configuration
+  sudo fixpanic agent exec -- debug dump
+
+  # Run without appending --config
+  fixpanic agent exec --no-config -- version`,
+	Args:        cobra.MinimumNArgs(1),
+	Annotations: map[string]string{annotationRequires: requireInstalled},
+	RunE:        runAgentExec,
+}
+
+func init() {
+	agentCmd.AddCommand(agentExecCmd)
+
+	// Add flags
+	agentExecCmd.Flags().BoolVar(&execNoConfig, "no-config", false, "Don't append --config with the agent configuration path")
+	agentExecCmd.Flags().SetInterspersed(false)
+}
+
+func runAgentExec(cmd *cobra.Command, args []string) error {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	env := os.Environ()
+	configPath := platformInfo.GetConfigPath()
+	if agentConfig, err := config.LoadConfig(configPath); err == nil {
+		serviceEnv, err := agentConfig.Service.Environ()
+		if err != nil {
+			return clierror.New(clierror.Config, "failed to read the service environment: %w", err)
+		}
+		env = append(env, serviceEnv...)
+	} else if !execNoConfig {
+		return clierror.New(clierror.Config, "failed to load configuration: %w", err).
+			WithHint("Pass --no-config to run the agent without its configuration")
+	}
+
+	if !execNoConfig && !hasConfigArg(args) {
+		args = append(args, "--config", configPath)
+	}
+
+	// Not bound to the command context: Ctrl+C reaches the agent through the
+	// terminal, and it decides itself how to stop
+	agent := exec.Command(platformInfo.GetFixPanicAgentBinaryPath(), args...)
+	agent.Stdin = os.Stdin
+	agent.Stdout = os.Stdout
+	agent.Stderr = os.Stderr
+	agent.Env = env
+
+	err = agent.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		// The status is the agent's own, so the default hints of the CLI exit
+		// code it happens to match don't apply
+		return clierror.New(clierror.Code(exitErr.ExitCode()), "agent exited with status %d", exitErr.ExitCode()).
+			WithHint("See the agent's output above; the status is passed through unchanged")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run agent: %w", err)
+	}
+	return nil
+}
+
+// hasConfigArg reports whether args already pass a configuration file
+func hasConfigArg(args []string) bool {
+	for _, arg := range args {
+		if arg == "--config" || strings.HasPrefix(arg, "--config=") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/netprobe"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/service"
+	"github.com/fixpanic/fixpanic-cli/internal/state"
+	"github.com/fixpanic/fixpanic-cli/internal/webhook"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	healthcheckInterval time.Duration
+	healthcheckWebhook  string
+	healthcheckJSON     bool
+)
+
+// minHealthcheckInterval keeps scheduled checks from hammering the socket server
+const minHealthcheckInterval = time.Minute
+
+// healthcheckDialTimeout bounds the connectivity probe of a health check
+const healthcheckDialTimeout = 10 * time.Second
+
+// agentHealthcheckCmd represents the agent healthcheck command group
+var agentHealthcheckCmd = &cobra.Command{
+	Use:   "healthcheck",
+	Short: "Check the agent's health on a schedule",
+	Long: `Check that the agent is running and can reach the socket server, on demand
+or on a schedule, so an agent that died silently is noticed between human
+checks.
+
+Results are kept in the state file; failures can also be posted as JSON to a
+webhook given with --webhook or healthcheck.webhook in ~/.fixpanic.yaml.`,
+}
+
+// agentHealthcheckRunCmd represents the agent healthcheck run command
+var agentHealthcheckRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run one health check",
+	Long: `Check that the agent is installed and running and that the socket server is
+reachable, record the result and exit non-zero if the agent is unhealthy.
+This is what the scheduled health check runs.`,
+	Example: `  # Check now and print the result as JSON
+  fixpanic agent healthcheck run --json`,
+	Annotations: map[string]string{annotationRequires: requireNoTelemetry},
+	RunE:        runAgentHealthcheck,
+}
+
+// agentHealthcheckInstallCmd represents the agent healthcheck install command
+var agentHealthcheckInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Schedule health checks with a systemd timer or cron",
+	Example: `  # Check every 5 minutes and post failures to a webhook
+  sudo fixpanic agent healthcheck install --interval 5m --webhook https://hooks.example.com/fixpanic`,
+	Annotations: map[string]string{annotationMutating: "true", annotationRequires: requireRoot},
+	RunE:        runAgentHealthcheckInstall,
+}
+
+// agentHealthcheckUninstallCmd represents the agent healthcheck uninstall command
+var agentHealthcheckUninstallCmd = &cobra.Command{
+	Use:         "uninstall",
+	Short:       "Remove the scheduled health check",
+	Annotations: map[string]string{annotationMutating: "true"},
+	RunE:        runAgentHealthcheckUninstall,
+}
+
+// agentHealthcheckStatusCmd represents the agent healthcheck status command
+var agentHealthcheckStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the results of recent health checks",
+	RunE:  runAgentHealthcheckStatus,
+}
+
+func init() {
+	agentCmd.AddCommand(agentHealthcheckCmd)
+	agentHealthcheckCmd.AddCommand(agentHealthcheckRunCmd)
+	agentHealthcheckCmd.AddCommand(agentHealthcheckInstallCmd)
+	agentHealthcheckCmd.AddCommand(agentHealthcheckUninstallCmd)
+	agentHealthcheckCmd.AddCommand(agentHealthcheckStatusCmd)
+
+	// Add flags
+	agentHealthcheckRunCmd.Flags().StringVar(&healthcheckWebhook, "webhook", "", "URL to POST failed checks to as JSON (default from healthcheck.webhook)")
+	agentHealthcheckRunCmd.Flags().BoolVar(&healthcheckJSON, "json", false, "Output the result as JSON")
+	agentHealthcheckInstallCmd.Flags().DurationVar(&healthcheckInterval, "interval", 5*time.Minute, "Time between checks")
+	agentHealthcheckInstallCmd.Flags().StringVar(&healthcheckWebhook, "webhook", "", "URL to POST failed checks to as JSON")
+	agentHealthcheckStatusCmd.Flags().BoolVar(&healthcheckJSON, "json", false, "Output results as JSON")
+}
+
+func runAgentHealthcheck(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	result := checkAgentHealth(ctx, platformInfo)
+	err = stateStore(platformInfo).Update(ctx, func(s *state.State) error {
+		s.AddHealthCheck(result)
+		return nil
+	})
+	if err != nil {
+		logger.Warning("Failed to record the health check: %v", err)
+	}
+
+	if healthcheckJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	} else {
+		printHealthCheck(result)
+	}
+
+	if result.Healthy {
+		resetSelfHeal(ctx, platformInfo)
+		return nil
+	}
+
+	notifyHealthWebhook(ctx, healthEvent{Event: "healthcheck.failed", Result: result})
+	if result.Installed {
+		selfHeal(cmd, platformInfo, result)
+	}
+
+	return clierror.New(clierror.General, "agent is unhealthy: %s", strings.Join(result.Problems, "; ")).
+		WithHint("Run 'fixpanic agent doctor' to diagnose the agent")
+}
+
+// healthEvent is the JSON posted to the health check webhook
+type healthEvent struct {
+	Event    string            `json:"event"`
+	Hostname string            `json:"hostname"`
+	Result   state.HealthCheck `json:"result"`
+	// Attempt is the self-healing restart the event is about, if any
+	Attempt int    `json:"attempt,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// notifyHealthWebhook posts event to the webhook of --webhook or
+// healthcheck.webhook, if one is set
+func notifyHealthWebhook(ctx context.Context, event healthEvent) {
+	url := healthcheckWebhook
+	if url == "" {
+		url = viper.GetString("healthcheck.webhook")
+	}
+	if url == "" {
+		return
+	}
+	event.Hostname, _ = os.Hostname()
+	if err := webhook.Post(ctx, url, event); err != nil {
+		logger.Warning("Failed to notify the webhook: %v", err)
+	}
+}
+
+// checkAgentHealth checks that the agent is installed and running and that
+// the socket server is reachable
+func checkAgentHealth(ctx context.Context, platformInfo *platform.PlatformInfo) state.HealthCheck {
+	result := state.HealthCheck{Time: time.Now().UTC()}
+
+	connectivityManager := connectivity.NewManager(platformInfo)
+	result.Installed = connectivityManager.IsFixPanicAgentInstalled()
+	if !result.Installed {
+		result.Problems = append(result.Problems, "agent is not installed")
+		return result
+	}
+	if output, err := connectivityManager.GetFixPanicAgentVersion(ctx); err == nil {
+		result.Version = connectivity.ParseAgentVersion(output)
+	}
+
+	result.Running, result.PID = detectAgentRunning(ctx, platformInfo)
+	if !result.Running {
+		result.Problems = append(result.Problems, "agent is not running")
+	}
+
+	agentConfig, err := config.LoadConfig(platformInfo.GetConfigPath())
+	if err != nil {
+		result.Problems = append(result.Problems, fmt.Sprintf("failed to load configuration: %v", err))
+		return result
+	}
+	result.SocketServer = agentConfig.GetSocketServer()
+	if _, err := netprobe.DialVia(ctx, result.SocketServer, healthcheckDialTimeout, nil); err != nil {
+		result.Problems = append(result.Problems, fmt.Sprintf("socket server %s is unreachable: %v", result.SocketServer, err))
+	} else {
+		result.Connected = true
+	}
+
+	result.Healthy = len(result.Problems) == 0
+	return result
+}
+
+// printHealthCheck reports a health check result on the terminal
+func printHealthCheck(result state.HealthCheck) {
+	if result.Healthy {
+		logger.Success("Agent is healthy")
+	} else {
+		logger.Error("Agent is unhealthy")
+	}
+	logger.KeyValue("Installed", fmt.Sprintf("%t", result.Installed))
+	if result.Installed {
+		logger.KeyValue("Version", result.Version)
+		logger.KeyValue("Running", fmt.Sprintf("%t", result.Running))
+		if result.PID > 0 {
+			logger.KeyValue("PID", fmt.Sprintf("%d", result.PID))
+		}
+	}
+	if result.SocketServer != "" {
+		logger.KeyValue(result.SocketServer, fmt.Sprintf("connected: %t", result.Connected))
+	}
+	for _, problem := range result.Problems {
+		logger.Warning("%s", problem)
+	}
+}
+
+func runAgentHealthcheckInstall(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if healthcheckInterval < minHealthcheckInterval {
+		return clierror.New(clierror.Usage, "--interval must be at least %s", minHealthcheckInterval)
+	}
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	binaryPath, err := getCurrentBinaryPath()
+	if err != nil {
+		return fmt.Errorf("failed to get current binary path: %w", err)
+	}
+	command := []string{binaryPath}
+	if platformInfo.Prefix != "" {
+		command = append(command, "--prefix", platformInfo.Prefix)
+	}
+	command = append(command, "agent", "healthcheck", "run", "--json")
+	if healthcheckWebhook != "" {
+		command = append(command, "--webhook", healthcheckWebhook)
+	}
+
+	if err := service.NewManager(platformInfo).InstallHealthCheck(ctx, command, healthcheckInterval); err != nil {
+		return err
+	}
+
+	if platform.IsSystemdAvailable() {
+		logger.Success("Health check scheduled every %s with %s.timer", healthcheckInterval, service.HealthCheckUnit)
+		logger.Info("See the results with 'fixpanic agent healthcheck status' or 'journalctl -u %s'", service.HealthCheckUnit)
+	} else {
+		logger.Success("Health check scheduled with cron in %s", service.HealthCheckCronPath)
+		if healthcheckInterval%time.Minute != 0 {
+			logger.Info("cron runs at most once a minute; the interval was rounded up to whole minutes")
+		}
+		logger.Info("See the results with 'fixpanic agent healthcheck status'")
+	}
+	return nil
+}
+
+func runAgentHealthcheckUninstall(cmd *cobra.Command, args []string) error {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	removed, err := service.NewManager(platformInfo).UninstallHealthCheck(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if !removed {
+		logger.Info("No scheduled health check is installed")
+		return nil
+	}
+	logger.Success("Scheduled health check removed")
+	return nil
+}
+
+func runAgentHealthcheckStatus(cmd *cobra.Command, args []string) error {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	saved, err := stateStore(platformInfo).Load()
+	if err != nil {
+		return err
+	}
+	results := saved.HealthChecks
+	if healthcheckJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if results == nil {
+			results = []state.HealthCheck{}
+		}
+		return encoder.Encode(results)
+	}
+
+	if len(results) == 0 {
+		logger.Info("No health checks recorded")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tRESULT\tPROBLEMS")
+	for _, result := range results {
+		status := "healthy"
+		if !result.Healthy {
+			status = "unhealthy"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Time.Local().Format("2006-01-02 15:04:05"), status, strings.Join(result.Problems, "; "))
+	}
+	return w.Flush()
+}
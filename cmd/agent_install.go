@@ -1,20 +1,55 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/cloudmeta"
 	"github.com/fixpanic/fixpanic-cli/internal/config"
 	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/diskspace"
+	"github.com/fixpanic/fixpanic-cli/internal/hooks"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/interrupt"
 	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/plan"
 	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/releases"
+	"github.com/fixpanic/fixpanic-cli/internal/rollback"
 	"github.com/fixpanic/fixpanic-cli/internal/service"
+	"github.com/fixpanic/fixpanic-cli/internal/summary"
 	"github.com/spf13/cobra"
 )
 
 var (
-	agentID      string
-	agentAPIKey  string
-	forceInstall bool
+	agentID         string
+	agentAPIKey     string
+	forceInstall    bool
+	agentProfile    string
+	installPlanOnly bool
+	installApply    string
+	installArtifact string
+	// installAgentVersion pins the agent release instead of the latest
+	installAgentVersion string
+
+	installTLSCAFile   string
+	installTLSCertFile string
+	installTLSKeyFile  string
+	installConfine     bool
+	// installCloudMetadata attaches the cloud instance to the registration
+	installCloudMetadata bool
+
+	installNoVerify      bool
+	installVerifyTimeout time.Duration
+	// installKeepPartial keeps the files of an install whose service setup failed
+	installKeepPartial bool
 )
 
 // agentInstallCmd represents the agent install command
@@ -25,13 +60,104 @@ var agentInstallCmd = &cobra.Command{
 
 This command downloads and installs the connectivity layer binary, creates the
 necessary configuration files, and sets up the systemd service for automatic
-startup.`,
+startup.
+
+--profile selects request handler limits, timeouts and logging defaults
+shipped with the CLI (see 'fixpanic config profiles'). Individual values can
+be changed afterwards with 'fixpanic config set'.
+
+--plan prints a JSON plan of the files, services and downloads (with pinned
+versions and SHA-256 checksums) the installation would create, without
+changing anything. After review, '--apply plan.json' performs exactly that
+installation and fails if anything differs from the plan. The API key is
+never stored in the plan and must be passed again when applying.
+
+--artifact-dir installs the agent binary from a bundle created with
+'fixpanic artifacts pull' instead of downloading it, for hosts without
+internet access. The binary is verified against the bundle's manifest.
+
+--agent-version installs the given agent release instead of the latest, so
+installs across a fleet get the exact same build. Downloaded binaries, the
+latest as well as pinned ones, are verified against the release's checksum
+manifest (SHA256SUMS), whose signature must match a release signing key
+built into the CLI: a compromised mirror or CDN can't serve a tampered agent
+even over a valid TLS connection. A release without a signed manifest is
+not installed.
+
+--tls-ca-file makes the agent trust a private CA, e.g. of a proxy terminating
+TLS, instead of the system roots. --tls-cert-file and --tls-key-file set the
+client certificate the agent presents for mutual TLS. The files are checked
+before the configuration is written and stay in place; reinstalling keeps
+them unless new ones are given.
+
+--confine runs the agent service under a system call filter and kernel
+protections, and where AppArmor is enabled under a profile tailored to the
+agent: it may run diagnostic tools and read the host's configuration and
+logs, but not password hashes or SSH keys. The setting is stored as
+service.confine and needs systemd. 'fixpanic agent doctor' checks that the
+confinement is active and isn't blocking the agent.
+
+--cloud-metadata queries the AWS, Google Cloud or Azure instance metadata
+service and stores the provider, account, region, zone, instance ID and
+instance tags as app.cloud. The agent sends them with its registration, so
+the dashboard groups agents by cloud account. The installation fails if the
+host isn't an instance of one of these clouds or the service can't be
+reached; reinstalling without the flag keeps the stored metadata.
+
+Once installed, the agent is started and the installation waits up to
+--verify-timeout for it to connect to the control plane and keep the
+connection, which it only does once its credentials are accepted. If it
+doesn't, the end of the agent log is shown and the installation fails; the
+installed files stay in place. Without systemd the agent is started in the
+background for the check, except in containers, where it is left to the
+container's supervisor. --no-verify skips the check.
+
+If the agent service can't be installed or started, the binary,
+configuration and directories the installation created are removed again,
+and files it replaced are restored, so a failed install leaves the host as
+it was. On a terminal you are asked first. --keep-partial keeps them
+instead, to debug the service or start the agent by hand. An installation
+interrupted with Ctrl+C or --timeout is rolled back the same way.`,
 	Example: `  # Install with agent credentials
 	 fixpanic agent install --agent-id="agent_123" --api-key="fp_abc123xyz"
 
 	 # Force reinstall
-	 fixpanic agent install --agent-id="agent_123" --api-key="fp_abc123xyz" --force`,
-	RunE: runAgentInstall,
+	 fixpanic agent install --agent-id="agent_123" --api-key="fp_abc123xyz" --force
+
+	 # Install with the limits of a shipped profile
+	 fixpanic agent install --agent-id="agent_123" --api-key="fp_abc123xyz" --profile=high-throughput
+
+	 # Review a plan, then apply it
+	 fixpanic agent install --agent-id="agent_123" --plan > plan.json
+	 fixpanic agent install --apply plan.json --api-key="fp_abc123xyz"
+
+	 # Install on an air-gapped host from a copied bundle
+	 fixpanic agent install --agent-id="agent_123" --api-key="fp_abc123xyz" --artifact-dir=./bundle
+
+	 # Install a specific agent release, verified against its checksums
+	 fixpanic agent install --agent-id="agent_123" --api-key="fp_abc123xyz" --agent-version=v1.4.0
+
+	 # Trust a private CA and authenticate with a client certificate
+	 fixpanic agent install --agent-id="agent_123" --api-key="fp_abc123xyz" \
+	   --tls-ca-file=/etc/pki/corp-ca.pem --tls-cert-file=/etc/fixpanic/client.pem --tls-key-file=/etc/fixpanic/client.key
+
+	 # Confine the agent with seccomp and AppArmor
+	 sudo fixpanic agent install --agent-id="agent_123" --api-key="fp_abc123xyz" --confine
+
+	 # Register the instance's cloud account, region and tags with the agent
+	 fixpanic agent install --agent-id="agent_123" --api-key="fp_abc123xyz" --cloud-metadata`,
+	Annotations: map[string]string{annotationMutating: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateInstallFlags(cmd); err != nil {
+			return err
+		}
+		if installPlanOnly {
+			return runAgentInstallPlan(cmd)
+		}
+		return withLock(cmd, func() error {
+			return runWithHooks(cmd.Context(), hooks.OperationInstall, func() error { return runAgentInstall(cmd, args) })
+		})
+	},
 }
 
 func init() {
@@ -41,20 +167,136 @@ func init() {
 	agentInstallCmd.Flags().StringVar(&agentID, "agent-id", "", "Agent ID from Fixpanic dashboard (required)")
 	agentInstallCmd.Flags().StringVar(&agentAPIKey, "api-key", "", "Agent API key from Fixpanic dashboard (required)")
 	agentInstallCmd.Flags().BoolVar(&forceInstall, "force", false, "Force reinstall even if agent is already installed")
+	agentInstallCmd.Flags().StringVar(&agentProfile, "profile", config.DefaultProfile, "Configuration profile ("+strings.Join(config.ProfileNames(), ", ")+")")
+	agentInstallCmd.Flags().BoolVar(&installPlanOnly, "plan", false, "Print a JSON plan of the installation instead of performing it")
+	agentInstallCmd.Flags().StringVar(&installApply, "apply", "", "Perform the installation described by a plan file created with --plan")
+	agentInstallCmd.Flags().StringVar(&installArtifact, "artifact-dir", "", "Install the agent binary from a bundle created with 'fixpanic artifacts pull'")
+	agentInstallCmd.Flags().StringVar(&installAgentVersion, "agent-version", "", "Install this agent release (e.g. v1.4.0), verified against its checksum manifest")
+	agentInstallCmd.Flags().StringVar(&installTLSCAFile, "tls-ca-file", "", "PEM bundle of CAs the agent trusts instead of the system roots")
+	agentInstallCmd.Flags().StringVar(&installTLSCertFile, "tls-cert-file", "", "Client certificate (PEM) the agent presents for mutual TLS")
+	agentInstallCmd.Flags().StringVar(&installTLSKeyFile, "tls-key-file", "", "Private key (PEM) of --tls-cert-file")
+	agentInstallCmd.Flags().BoolVar(&installConfine, "confine", false, "Confine the agent service with a system call filter and an AppArmor profile")
+	agentInstallCmd.Flags().BoolVar(&installNoVerify, "no-verify", false, "Don't wait for the agent to connect to the control plane after installing")
+	agentInstallCmd.Flags().DurationVar(&installVerifyTimeout, "verify-timeout", defaultInstallVerifyTimeout, "How long to wait for the agent to connect to the control plane")
+	agentInstallCmd.Flags().BoolVar(&installKeepPartial, "keep-partial", false, "Keep the installed files when the agent service can't be set up or the installation is interrupted instead of rolling back")
+	agentInstallCmd.Flags().BoolVar(&installCloudMetadata, "cloud-metadata", false, "Attach the cloud instance's account, region, instance ID and tags to the agent registration")
+	addSummaryFlag(agentInstallCmd)
+}
+
+// validateInstallFlags checks the flag combinations of the install, plan and
+// apply modes. --agent-id and --api-key are required unless a plan provides
+// them; the API key is never part of a plan.
+func validateInstallFlags(cmd *cobra.Command) error {
+	if installPlanOnly && installApply != "" {
+		return clierror.New(clierror.Usage, "--plan and --apply are mutually exclusive")
+	}
+
+	if installApply != "" {
+		for _, name := range []string{"agent-id", "profile", "socket-server", "force", "agent-version", "tls-ca-file", "tls-cert-file", "tls-key-file", "confine", "cloud-metadata"} {
+			if cmd.Flags().Changed(name) {
+				return clierror.New(clierror.Usage, "--%s can't be combined with --apply; it is fixed by the plan", name).
+					WithHint("Create a new plan with the changed options")
+			}
+		}
+		if agentAPIKey == "" {
+			return clierror.New(clierror.Usage, "--api-key is required with --apply")
+		}
+		return nil
+	}
 
-	// Mark required flags
-	agentInstallCmd.MarkFlagRequired("agent-id")
-	agentInstallCmd.MarkFlagRequired("api-key")
+	if agentID == "" {
+		return clierror.New(clierror.Usage, "required flag \"agent-id\" not set")
+	}
+	if agentAPIKey == "" && !installPlanOnly {
+		return clierror.New(clierror.Usage, "required flag \"api-key\" not set")
+	}
+	if installAgentVersion != "" {
+		if installArtifact != "" {
+			return clierror.New(clierror.Usage, "--agent-version can't be combined with --artifact-dir; the bundle fixes the version")
+		}
+		tag, err := releases.ParseTag(installAgentVersion)
+		if err != nil {
+			return clierror.WithHint(clierror.Wrap(clierror.Usage, err), "Run 'fixpanic agent versions' to list the agent releases")
+		}
+		installAgentVersion = tag
+	}
+	if (installTLSCertFile == "") != (installTLSKeyFile == "") {
+		return clierror.New(clierror.Usage, "--tls-cert-file and --tls-key-file must be given together")
+	}
+	// The agent doesn't run in the directory the CLI was started from
+	for _, path := range []*string{&installTLSCAFile, &installTLSCertFile, &installTLSKeyFile} {
+		if *path == "" {
+			continue
+		}
+		absolute, err := filepath.Abs(*path)
+		if err != nil {
+			return clierror.Wrap(clierror.Usage, err)
+		}
+		*path = absolute
+	}
+	return nil
 }
 
-func runAgentInstall(cmd *cobra.Command, args []string) error {
+func runAgentInstall(cmd *cobra.Command, args []string) (err error) {
 	logger.Header("Installing Fixpanic Agent")
+	ctx := cmd.Context()
+
+	// A plan fixes all inputs except the API key
+	var appliedPlan *plan.Plan
+	if installApply != "" {
+		loaded, err := plan.Load(installApply)
+		if err != nil {
+			return clierror.Wrap(clierror.Usage, err)
+		}
+		appliedPlan = loaded
+		agentID = appliedPlan.Inputs.AgentID
+		agentProfile = appliedPlan.Inputs.Profile
+		forceInstall = appliedPlan.Inputs.Force
+		logger.KeyValue("Plan", installApply)
+	}
+
+	// Reject unknown profiles before downloading anything
+	if _, err := config.GetProfile(agentProfile); err != nil {
+		return clierror.WithHint(clierror.Wrap(clierror.Usage, err), "Run 'fixpanic config profiles' to list the available profiles")
+	}
+	if _, err := permissionPolicy(); err != nil {
+		return err
+	}
 
 	// Get platform information
 	logger.Step(1, "Detecting platform and configuration")
-	platformInfo, err := platform.GetPlatformInfo()
+	platformInfo, err := commandPlatform()
 	if err != nil {
-		return fmt.Errorf("failed to get platform info: %w", err)
+		return err
+	}
+	// Only root can install the systemd service; offer sudo before
+	// downloading anything rather than failing on the unit file afterwards
+	if !platformInfo.IsRoot && platform.IsSystemdAvailable() {
+		if err := offerSudo(cmd, "Without root the agent is installed in your user directories and not as a systemd service"); err != nil {
+			return err
+		}
+	}
+	// A reinstall must not bring back an agent stopped in an emergency
+	if err := agentops.CheckPanicStop(platformInfo); err != nil {
+		return err
+	}
+	// Refuse a plan that no longer matches what this host would get before
+	// changing anything
+	if appliedPlan != nil {
+		if err := checkPlan(appliedPlan, platformInfo); err != nil {
+			return err
+		}
+	}
+
+	var cloud *cloudmeta.Instance
+	if installCloudMetadata {
+		logger.Progress("Querying the cloud instance metadata service")
+		if cloud, err = detectInstallCloud(ctx); err != nil {
+			return err
+		}
+		logger.KeyValue("Cloud", fmt.Sprintf("%s %s", cloud.Provider, cloud.InstanceID))
+		logger.KeyValue("Account", cloud.AccountID)
+		logger.KeyValue("Region", cloud.Region)
 	}
 
 	// Check if running as root for system-wide installation
@@ -64,76 +306,262 @@ func runAgentInstall(cmd *cobra.Command, args []string) error {
 		logger.KeyValue("Config location", platformInfo.ConfigDir)
 	}
 
+	environment := platform.DetectEnvironment()
+	if environment != platform.EnvironmentHost {
+		logger.KeyValue("Environment", environment.String())
+	}
+	advice := adviseEnvironment(environment)
+	if advice != nil {
+		logger.Warning(advice.Problem)
+	}
+
+	// Record what the installation changes, to roll it back if the service
+	// can't be set up
+	var journal rollback.Journal
+	defer journal.Commit()
+	// An installation interrupted by Ctrl+C or --timeout is rolled back
+	// rather than left half done
+	defer func() {
+		if err == nil || ctx.Err() == nil || installKeepPartial {
+			return
+		}
+		logger.Progress("Rolling back the interrupted installation")
+		if rollbackErr := interrupt.Critical(journal.Rollback); rollbackErr != nil {
+			logger.Warning("Rolling back the installation failed: %v", rollbackErr)
+			logger.Info("Run 'fixpanic agent uninstall' to remove what is left of the installation")
+			summary.Record("Roll back the installation", summary.Failed, rollbackErr.Error())
+		} else {
+			summary.Record("Roll back the installation", summary.OK, "")
+		}
+	}()
+	if platform.IsSystemdAvailable() {
+		if err := agentops.TrackService(ctx, &journal, platformInfo); err != nil {
+			return err
+		}
+	}
+
 	// Create necessary directories
 	logger.Progress("Creating necessary directories")
-	if err := platformInfo.CreateDirectories(); err != nil {
-		return fmt.Errorf("failed to create directories: %w", err)
+	if err := agentops.CreateDirectories(&journal, platformInfo); err != nil {
+		return err
 	}
 
 	// Check if FixPanic Agent is already installed
 	logger.Step(2, "Checking for existing installation")
 	connectivityManager := connectivity.NewManager(platformInfo)
 	if connectivityManager.IsFixPanicAgentInstalled() && !forceInstall {
-		return fmt.Errorf("FixPanic Agent is already installed. Use --force to reinstall")
+		return clierror.New(clierror.AlreadyInstalled, "FixPanic Agent is already installed").
+			WithHint("Use --force to reinstall", "Run 'fixpanic agent upgrade' to update the agent binary")
+	}
+
+	if err := journal.TrackFile(platformInfo.GetFixPanicAgentBinaryPath()); err != nil {
+		return err
 	}
 
 	// Ensure latest agent binary (auto-update)
-	logger.Step(3, "Ensuring latest agent binary")
-	if err := connectivityManager.EnsureLatestAgent(); err != nil {
-		return fmt.Errorf("failed to ensure latest agent binary: %w", err)
+	if installArtifact != "" {
+		logger.Step(3, "Installing agent binary from artifact bundle")
+		manifest, artifact, err := bundleArtifact(installArtifact)
+		if err != nil {
+			return err
+		}
+		logger.KeyValue("Version", manifest.Version)
+		logger.KeyValue("SHA-256", artifact.SHA256)
+		if appliedPlan != nil {
+			for _, download := range appliedPlan.Downloads {
+				if !strings.EqualFold(download.SHA256, artifact.SHA256) {
+					return clierror.New(clierror.Config, "bundle %s has a different agent build than the plan", installArtifact).
+						WithHint("Use the bundle the plan was created from", hintReplan)
+				}
+			}
+		}
+		if err := connectivityManager.InstallFixPanicAgentFile(ctx, filepath.Join(installArtifact, artifact.Name), artifact.SHA256); err != nil {
+			return withDiskSpaceHint(fmt.Errorf("failed to install agent binary from bundle: %w", err))
+		}
+	} else if appliedPlan != nil {
+		logger.Step(3, "Downloading planned agent binary")
+		for _, download := range appliedPlan.Downloads {
+			logger.KeyValue("Version", download.Version)
+			logger.KeyValue("SHA-256", download.SHA256)
+			if err := connectivityManager.DownloadFixPanicAgentVerified(ctx, download.URL, download.SHA256); err != nil {
+				return withDiskSpaceHint(fmt.Errorf("failed to download planned agent binary: %w", err))
+			}
+		}
+	} else if installAgentVersion != "" {
+		logger.Step(3, "Downloading agent %s", installAgentVersion)
+		checksum, err := connectivityManager.InstallFixPanicAgentVersion(ctx, installAgentVersion)
+		if err != nil {
+			var insufficient *diskspace.InsufficientError
+			if errors.As(err, &insufficient) {
+				return withDiskSpaceHint(fmt.Errorf("failed to install agent %s: %w", installAgentVersion, err))
+			}
+			return clierror.WithHint(clierror.Classify(clierror.Network, err),
+				"Run 'fixpanic agent versions' to check that the release exists",
+				"Releases published before signed checksum manifests can't be installed with --agent-version")
+		}
+		logger.Success("Checksum manifest signature verified")
+		logger.KeyValue("Version", installAgentVersion)
+		logger.KeyValue("SHA-256", checksum)
+	} else {
+		logger.Step(3, "Ensuring latest agent binary")
+		if err := connectivityManager.EnsureLatestAgent(ctx); err != nil {
+			return withDiskSpaceHint(fmt.Errorf("failed to ensure latest agent binary: %w", err))
+		}
 	}
 
+	summary.Touch(platformInfo.GetFixPanicAgentBinaryPath())
+
 	// Create configuration
 	logger.Step(4, "Creating agent configuration")
-	agentConfig := config.DefaultConfig()
-	agentConfig.App.AgentID = agentID
-	agentConfig.App.APIKey = agentAPIKey
+	socketServer, _ := cmd.Flags().GetString("socket-server")
+	inputs := plan.Inputs{
+		AgentID:      agentID,
+		Profile:      agentProfile,
+		SocketServer: socketServer,
+		TLSCAFile:    installTLSCAFile,
+		TLSCertFile:  installTLSCertFile,
+		TLSKeyFile:   installTLSKeyFile,
+		Confine:      installConfine,
+		Cloud:        cloud,
+	}
+	if appliedPlan != nil {
+		inputs = appliedPlan.Inputs
+	}
+	agentConfig, err := agentops.RenderConfig(platformInfo, inputs, agentAPIKey)
+	if err != nil {
+		return err
+	}
 
 	// Validate configuration
 	logger.Progress("Validating configuration")
 	if err := agentConfig.Validate(); err != nil {
-		return fmt.Errorf("invalid configuration: %w", err)
+		return clierror.New(clierror.Config, "invalid configuration: %w", err).
+			WithHint("Check the values passed to --agent-id, --api-key and --socket-server")
+	}
+	if _, err := agentConfig.App.LoadTLSMaterial(); err != nil {
+		return clierror.New(clierror.Config, "invalid TLS configuration: %w", err).
+			WithHint("Check the files passed to --tls-ca-file, --tls-cert-file and --tls-key-file")
 	}
 
 	// Save configuration
 	configPath := platformInfo.GetConfigPath()
+	if err := journal.TrackFile(configPath); err != nil {
+		return err
+	}
+	if err := persistConfigMigration(configPath); err != nil {
+		return err
+	}
 	if err := config.SaveConfig(agentConfig, configPath); err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
+	summary.Touch(configPath)
 
 	logger.Success("Configuration saved to: %s", configPath)
 
+	logger.Progress("Applying file permissions")
+	if err := applyPermissions(platformInfo); err != nil {
+		return err
+	}
+
 	// Install systemd service if available
 	logger.Step(5, "Setting up system service")
+	agentStarted := false
+	var serviceErr error
 	if platform.IsSystemdAvailable() {
 		serviceManager := service.NewManager(platformInfo)
+		journal.Undo(func() error {
+			return serviceManager.Uninstall(context.WithoutCancel(ctx))
+		})
 
 		// Remove old service if it exists
 		logger.Progress("Removing old service if it exists")
-		if err := serviceManager.Uninstall(); err != nil {
+		if err := serviceManager.Uninstall(ctx); err != nil {
 			logger.Warning("Failed to remove old service: %v", err)
 		}
 
 		// Install new service
 		logger.Progress("Installing systemd service")
-		if err := serviceManager.Install(); err != nil {
-			logger.Warning("Failed to install systemd service: %v", err)
-			logger.Info("You can start the agent manually with: fixpanic agent start")
+		if err := serviceManager.Install(ctx); err != nil {
+			serviceErr = fmt.Errorf("failed to install systemd service: %w", err)
+			summary.Record("Install service", summary.Failed, err.Error())
 		} else {
+			summary.Record("Install service", summary.OK, "", platformInfo.GetServiceFilePath())
+
 			// Enable and start the service
-			if err := serviceManager.Enable(); err != nil {
+			if err := serviceManager.Enable(ctx); err != nil {
 				logger.Warning("Failed to enable service: %v", err)
+				summary.Record("Enable service", summary.Failed, err.Error())
+			} else {
+				summary.Record("Enable service", summary.OK, "")
 			}
 
-			if err := serviceManager.Start(); err != nil {
-				logger.Warning("Failed to start service: %v", err)
-				logger.Info("You can start the agent manually with: fixpanic agent start")
+			if err := serviceManager.Start(ctx); err != nil {
+				serviceErr = fmt.Errorf("failed to start service: %w", err)
+				summary.Record("Start service", summary.Failed, err.Error())
 			} else {
 				logger.Success("Agent service installed and started successfully")
+				summary.Record("Start service", summary.OK, "")
+				agentStarted = true
 			}
+			if agentConfig.Service.Confine {
+				if service.AppArmorEnabled() {
+					logger.Info("The agent is confined by a system call filter and the AppArmor profile %s", service.AppArmorProfileName)
+				} else {
+					logger.Info("The agent is confined by a system call filter; AppArmor is not enabled on this host")
+				}
+				logger.Info("Run 'fixpanic agent doctor' to check that the confinement isn't blocking the agent")
+			}
+		}
+	} else if advice != nil {
+		logger.Info(advice.Hint)
+		summary.Skip("no service is set up in this environment")
+		if agentConfig.Service.Confine {
+			logger.Warning("--confine needs systemd; the agent runs unconfined")
+		}
+	} else {
+		if agentConfig.Service.Confine {
+			logger.Warning("--confine needs systemd; the agent runs unconfined")
+		}
+		if installNoVerify {
+			logger.Info("Systemd not available. You can start the agent manually with: fixpanic agent start")
+			summary.Record("Start agent", summary.Skipped, "systemd not available and --no-verify given")
+		} else if err := cleanUpOldAgents(); err != nil {
+			serviceErr = fmt.Errorf("failed to stop the running agent: %w", err)
+			summary.Record("Start agent", summary.Failed, serviceErr.Error())
+		} else if pid, err := startAgentProcess(platformInfo); err != nil {
+			serviceErr = fmt.Errorf("failed to start the agent: %w", err)
+			summary.Record("Start agent", summary.Failed, err.Error())
+		} else {
+			logger.Success("Agent started in the background (PID %d)", pid)
+			summary.Record("Start agent", summary.OK, fmt.Sprintf("PID %d", pid))
+			agentStarted = true
+		}
+	}
+	if serviceErr != nil {
+		if err := handleServiceFailure(ctx, &journal, serviceErr); err != nil {
+			return err
 		}
+	}
+
+	recordInstall(ctx, platformInfo, agentProfile)
+
+	if installNoVerify {
+		summary.Record("Verify the agent connects", summary.Skipped, "--no-verify given")
 	} else {
-		logger.Info("Systemd not available. You can start the agent manually with: fixpanic agent start")
+		logger.Step(6, "Verifying the agent connects")
+		switch {
+		case agentStarted:
+			if err := verifyInstall(ctx, platformInfo, agentConfig.GetSocketServer(), installVerifyTimeout); err != nil {
+				return err
+			}
+		case advice != nil:
+			logger.Info("The agent isn't started in this environment, so its connection isn't verified")
+			summary.Skip("the agent isn't started in this environment")
+		default:
+			return clierror.New(clierror.General, "the agent was not started, so the installation can't be verified").
+				WithHint("Run 'fixpanic agent start' and 'fixpanic agent logs' to see why it doesn't start",
+					"Use --no-verify to install without waiting for the agent")
+		}
 	}
 
 	logger.Separator()
@@ -143,6 +571,8 @@ func runAgentInstall(cmd *cobra.Command, args []string) error {
 	logger.KeyValue("Agent ID", agentID)
 	logger.KeyValue("Binary location", platformInfo.GetFixPanicAgentBinaryPath())
 	logger.KeyValue("Config location", configPath)
+	logger.KeyValue("Socket server", agentConfig.GetSocketServer())
+	logger.KeyValue("Profile", agentProfile)
 
 	if platform.IsSystemdAvailable() {
 		logger.Separator()
@@ -155,3 +585,52 @@ func runAgentInstall(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// handleServiceFailure rolls back an installation whose service couldn't be
+// set up, asking first on a terminal. It returns nil if the installation is
+// kept, with --keep-partial or because the user declined.
+func handleServiceFailure(ctx context.Context, journal *rollback.Journal, serviceErr error) error {
+	keep := installKeepPartial
+	if keep || isInteractive() {
+		logger.Warning("%v", serviceErr)
+	}
+	if !keep && isInteractive() {
+		fmt.Fprintf(os.Stderr, "%s ", i18n.T("Roll back the installation? [Y/n]:"))
+		response, err := readLine(ctx)
+		if err != nil {
+			return err
+		}
+		keep = response == "n" || response == "N"
+	}
+	if keep {
+		logger.Info("Keeping the partial installation. You can start the agent manually with: fixpanic agent start")
+		return nil
+	}
+
+	logger.Progress("Rolling back the installation")
+	if err := interrupt.Critical(journal.Rollback); err != nil {
+		summary.Record("Roll back the installation", summary.Failed, err.Error())
+		return clierror.New(clierror.General, "%w; rolling back the installation failed too: %v", serviceErr, err).
+			WithHint("Run 'fixpanic agent uninstall' to remove what is left of the installation")
+	}
+	summary.Record("Roll back the installation", summary.OK, "")
+	logger.Info("Rolled back the installation")
+	return clierror.WithHint(clierror.Wrap(clierror.General, serviceErr),
+		"Use --keep-partial to keep the installation and find out why with 'fixpanic agent logs'")
+}
+
+// detectInstallCloud identifies the cloud instance for --cloud-metadata. The
+// flag asks for the metadata, so not getting it fails the installation
+// rather than registering an agent the dashboard can't group.
+func detectInstallCloud(ctx context.Context) (*cloudmeta.Instance, error) {
+	cloud, err := cloudmeta.Detect(ctx)
+	if err != nil {
+		return nil, clierror.New(clierror.Network, "failed to read the cloud instance metadata: %w", err).
+			WithHint("Check that the instance metadata service is enabled and not blocked by a firewall", "Install without --cloud-metadata")
+	}
+	if cloud == nil {
+		return nil, clierror.New(clierror.Usage, "--cloud-metadata: this host is not an AWS, Google Cloud or Azure instance").
+			WithHint("Install without --cloud-metadata")
+	}
+	return cloud, nil
+}
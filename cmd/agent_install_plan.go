@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/plan"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/service"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// hintReplan is shown when a plan can't be applied as reviewed
+const hintReplan = "Create a new plan on this host with 'fixpanic agent install --plan' and review it again"
+
+// runAgentInstallPlan prints the plan of an installation without changing
+// anything. Only the JSON plan is written to stdout so it can be redirected.
+func runAgentInstallPlan(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	if _, err := config.GetProfile(agentProfile); err != nil {
+		return clierror.WithHint(clierror.Wrap(clierror.Usage, err), "Run 'fixpanic config profiles' to list the available profiles")
+	}
+	if _, err := permissionPolicy(); err != nil {
+		return err
+	}
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	connectivityManager := connectivity.NewManager(platformInfo)
+	if connectivityManager.IsFixPanicAgentInstalled() && !forceInstall {
+		return clierror.New(clierror.AlreadyInstalled, "FixPanic Agent is already installed").
+			WithHint("Use --force to plan a reinstall", "Run 'fixpanic agent upgrade' to update the agent binary")
+	}
+
+	socketServer, _ := cmd.Flags().GetString("socket-server")
+	installPlan := &plan.Plan{
+		FormatVersion: plan.FormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		CLIVersion:    getCurrentVersion(),
+		Platform:      runtime.GOOS + "/" + runtime.GOARCH,
+		Inputs: plan.Inputs{
+			AgentID:      agentID,
+			Profile:      agentProfile,
+			SocketServer: socketServer,
+			Force:        forceInstall,
+			TLSCAFile:    installTLSCAFile,
+			TLSCertFile:  installTLSCertFile,
+			TLSKeyFile:   installTLSKeyFile,
+			Confine:      installConfine,
+		},
+	}
+	if installCloudMetadata {
+		if installPlan.Inputs.Cloud, err = detectInstallCloud(ctx); err != nil {
+			return err
+		}
+	}
+	installPlan.Directories, installPlan.Files, installPlan.Services, err = plannedLayout(platformInfo, installPlan.Inputs)
+	if err != nil {
+		return err
+	}
+
+	// Pin the bundled build, or the requested or latest release and the
+	// checksum of the exact bytes reviewed
+	if installArtifact != "" {
+		manifest, artifact, err := bundleArtifact(installArtifact)
+		if err != nil {
+			return err
+		}
+		installPlan.Downloads = []plan.Download{{
+			Name:        artifact.Name,
+			Version:     manifest.Version,
+			URL:         artifact.URL,
+			SHA256:      artifact.SHA256,
+			Size:        artifact.Size,
+			Destination: platformInfo.GetFixPanicAgentBinaryPath(),
+		}}
+		return installPlan.Write(os.Stdout)
+	}
+
+	assetName, err := platform.GetFixPanicAgentAssetName()
+	if err != nil {
+		return err
+	}
+	version := installAgentVersion
+	if version == "" {
+		if version, err = connectivityManager.GetLatestAgentVersion(ctx); err != nil {
+			return clierror.Wrap(clierror.Network, err)
+		}
+	}
+	downloadURL, expected, err := connectivityManager.VerifiedAgentDownload(ctx, version)
+	if err != nil {
+		return clierror.Classify(clierror.Network, err)
+	}
+	checksum, size, err := connectivityManager.FetchChecksum(ctx, downloadURL)
+	if err != nil {
+		return clierror.Classify(clierror.Network, err)
+	}
+	if !strings.EqualFold(checksum, expected) {
+		return clierror.New(clierror.Integrity, "checksum mismatch for %s: the manifest has %s, the download %s", downloadURL, expected, checksum)
+	}
+	installPlan.Downloads = []plan.Download{{
+		Name:        assetName,
+		Version:     version,
+		URL:         downloadURL,
+		SHA256:      checksum,
+		Size:        size,
+		Destination: platformInfo.GetFixPanicAgentBinaryPath(),
+	}}
+
+	return installPlan.Write(os.Stdout)
+}
+
+// plannedLayout returns the directories, files and services an installation
+// with the given inputs creates on this host
+func plannedLayout(platformInfo *platform.PlatformInfo, inputs plan.Inputs) ([]string, []plan.File, []plan.Service, error) {
+	directories := []string{platformInfo.LibDir, platformInfo.ConfigDir, platformInfo.LogDir}
+
+	agentConfig, err := agentops.RenderConfig(platformInfo, inputs, plan.RedactedSecret)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	configData, err := yaml.Marshal(agentConfig)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to render configuration: %w", err)
+	}
+	files := []plan.File{{
+		Path:        platformInfo.GetConfigPath(),
+		Mode:        "0600",
+		Description: "Agent configuration (API key redacted)",
+		Content:     string(configData),
+	}}
+
+	services := []plan.Service{}
+	if platform.IsSystemdAvailable() {
+		unit, err := service.NewManager(platformInfo).Render()
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to render service unit: %w", err)
+		}
+		files = append(files, plan.File{
+			Path:        platformInfo.GetServiceFilePath(),
+			Mode:        "0644",
+			Description: "systemd service unit",
+			Content:     unit,
+		})
+		if agentConfig.Service.Confine && service.AppArmorEnabled() {
+			profile, err := service.NewManager(platformInfo).RenderAppArmorProfile()
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to render AppArmor profile: %w", err)
+			}
+			files = append(files, plan.File{
+				Path:        service.AppArmorProfilePath,
+				Mode:        "0644",
+				Description: "AppArmor profile confining the agent",
+				Content:     profile,
+			})
+		}
+		services = append(services, plan.Service{
+			Name:     platform.GetSystemdServiceName(),
+			Manager:  "systemd",
+			UnitPath: platformInfo.GetServiceFilePath(),
+			Enable:   true,
+			Start:    true,
+		})
+	}
+
+	return directories, files, services, nil
+}
+
+// checkPlan verifies that applying p on this host does exactly what was
+// reviewed: same platform and agent build, and the same directories, files
+// and services as this CLI would create now
+func checkPlan(p *plan.Plan, platformInfo *platform.PlatformInfo) error {
+	if current := runtime.GOOS + "/" + runtime.GOARCH; p.Platform != current {
+		return clierror.New(clierror.Config, "plan was created for %s, this host is %s", p.Platform, current).
+			WithHint(hintReplan)
+	}
+
+	assetName, err := platform.GetFixPanicAgentAssetName()
+	if err != nil {
+		return err
+	}
+	for _, download := range p.Downloads {
+		if download.Name != assetName {
+			return clierror.New(clierror.Config, "plan downloads %s, but this host needs %s", download.Name, assetName).
+				WithHint(hintReplan)
+		}
+		if download.Destination != platformInfo.GetFixPanicAgentBinaryPath() {
+			return clierror.New(clierror.Config, "plan installs the agent to %s, but this host uses %s", download.Destination, platformInfo.GetFixPanicAgentBinaryPath()).
+				WithHint(hintReplan, "Run as the same user and with the same --prefix as when the plan was created")
+		}
+	}
+
+	directories, files, services, err := plannedLayout(platformInfo, p.Inputs)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(directories, p.Directories) {
+		return clierror.New(clierror.Config, "plan creates different directories than this host needs").
+			WithHint(hintReplan, "Run as the same user and with the same --prefix as when the plan was created")
+	}
+	if len(files) != len(p.Files) {
+		return clierror.New(clierror.Config, "plan writes %d file(s), this host needs %d", len(p.Files), len(files)).
+			WithHint(hintReplan)
+	}
+	for _, file := range files {
+		planned := p.File(file.Path)
+		if planned == nil || *planned != file {
+			return clierror.New(clierror.Config, "%s would differ from the plan", file.Path).
+				WithHint(hintReplan)
+		}
+	}
+	if !reflect.DeepEqual(services, p.Services) {
+		return clierror.New(clierror.Config, "plan sets up different services than this host supports").
+			WithHint(hintReplan)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/netprobe"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/procstat"
+)
+
+// defaultInstallVerifyTimeout is how long an installation waits for the
+// agent to connect to the control plane
+const defaultInstallVerifyTimeout = time.Minute
+
+// installVerifyPoll is how often the agent is looked at while verifying
+const installVerifyPoll = time.Second
+
+// installVerifyStable is how long the agent must hold its connection to count
+// as registered: the control plane drops agents whose credentials it rejects
+// right after the handshake
+const installVerifyStable = 5 * time.Second
+
+// installVerifyLogLines is how much of the agent log a failed verification shows
+const installVerifyLogLines = 10
+
+// verifyInstall waits up to timeout for the started agent to connect to the
+// control plane at socketServer and keep the connection. Where the agent's
+// sockets can't be inspected (without root, or outside Linux), the agent
+// staying up with the socket server reachable is accepted instead.
+func verifyInstall(ctx context.Context, platformInfo *platform.PlatformInfo, socketServer string, timeout time.Duration) error {
+	logger.Progress("Waiting up to %s for the agent to connect to %s", timeout, socketServer)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(installVerifyPoll)
+	defer ticker.Stop()
+
+	var runningSince, connectedSince time.Time
+	started, inspectable := false, true
+	for {
+		running, pid := detectAgentRunning(ctx, platformInfo)
+		now := time.Now()
+		if !running {
+			runningSince, connectedSince = time.Time{}, time.Time{}
+		} else {
+			started = true
+			if runningSince.IsZero() {
+				runningSince = now
+			}
+			connections := -1
+			if pid > 0 {
+				if stat, err := procstat.Read(pid); err == nil {
+					connections = stat.Connections
+				}
+			}
+			switch {
+			case connections > 0:
+				if connectedSince.IsZero() {
+					connectedSince = now
+				}
+				if now.Sub(connectedSince) >= installVerifyStable {
+					logger.Success("Agent is running and connected to the control plane (PID %d)", pid)
+					return nil
+				}
+			case connections == 0:
+				connectedSince = time.Time{}
+			default:
+				inspectable = false
+				if now.Sub(runningSince) >= installVerifyStable {
+					return verifyInstallReachable(ctx, socketServer)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return installVerifyFailure(platformInfo, started, inspectable, socketServer, timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// verifyInstallReachable accepts an agent whose connections can't be seen if
+// the socket server is reachable from this host
+func verifyInstallReachable(ctx context.Context, socketServer string) error {
+	if _, err := netprobe.DialVia(ctx, socketServer, healthcheckDialTimeout, nil); err != nil {
+		return clierror.New(clierror.Network, "the agent is running but socket server %s is unreachable: %w", socketServer, err).
+			WithHint("Run 'fixpanic agent test-connection' to diagnose connectivity")
+	}
+	logger.Success("Agent is running and the socket server is reachable")
+	logger.Warning("The agent's connections can't be inspected without root; run 'fixpanic agent status' to confirm it connected")
+	return nil
+}
+
+// installVerifyFailure reports why the agent didn't connect in time, with
+// the end of its log
+func installVerifyFailure(platformInfo *platform.PlatformInfo, started, inspectable bool, socketServer string, timeout time.Duration) error {
+	logPath := agentLogFile(platformInfo)
+	if lines, err := readLastLines(logPath, installVerifyLogLines); err == nil && len(lines) > 0 {
+		logger.Info("Last lines of %s:", logPath)
+		for _, line := range lines {
+			logger.Plain("  %s", line)
+		}
+	}
+
+	hints := []string{
+		"Run 'fixpanic agent logs' to see what the agent reported",
+		"Use --no-verify to install without waiting for the agent",
+	}
+	if !started {
+		return clierror.New(clierror.General, "the agent did not start within %s", timeout).
+			WithHint(append([]string{"Run 'fixpanic agent doctor' to diagnose common problems"}, hints...)...)
+	}
+	if !inspectable {
+		return clierror.New(clierror.General, "the agent did not stay running for %s within %s", installVerifyStable, timeout).
+			WithHint(hints...)
+	}
+	return clierror.New(clierror.Network, "the agent did not connect to %s within %s", socketServer, timeout).
+		WithHint(append([]string{
+			"Check the agent ID and API key on the Fixpanic dashboard",
+			"Run 'fixpanic agent test-connection' to diagnose connectivity",
+		}, hints...)...)
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/cloudmeta"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/fleet"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/spf13/cobra"
+)
+
+var (
+	inventoryJSON    bool
+	inventoryNoCloud bool
+)
+
+// agentInventoryCmd represents the agent inventory command
+var agentInventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Export host and agent metadata for a CMDB",
+	Long: `Show the metadata of this host and its agent: hostname, operating system,
+kernel, architecture, the cloud instance (provider, instance ID, region and
+account, from the AWS, Google Cloud or Azure metadata service), the agent
+version and when it was installed.
+
+--json emits it in a stable schema for configuration management database
+importers: fields are only ever added, and "schema" changes when one changes
+meaning. 'fixpanic fleet inventory' collects it from many hosts. --porcelain
+prints the same fields as tab-separated key/value lines (see 'fixpanic
+version --porcelain').`,
+	Example: `  # Show the inventory
+  fixpanic agent inventory
+
+  # Export it for the CMDB importer
+  fixpanic agent inventory --json > inventory.json`,
+	Args: cobra.NoArgs,
+	RunE: runAgentInventory,
+}
+
+func init() {
+	agentCmd.AddCommand(agentInventoryCmd)
+
+	// Add flags
+	agentInventoryCmd.Flags().BoolVar(&inventoryJSON, "json", false, "Output the inventory as JSON")
+	agentInventoryCmd.Flags().BoolVar(&inventoryNoCloud, "no-cloud", false, "Don't query cloud instance metadata services")
+	addPorcelainFlag(agentInventoryCmd)
+}
+
+func runAgentInventory(cmd *cobra.Command, args []string) error {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	inventory, cloudErr := collectInventory(cmd.Context(), platformInfo, !inventoryNoCloud)
+	if porcelainFormat != "" {
+		return writeInventoryPorcelain(inventory)
+	}
+	if inventoryJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(inventory)
+	}
+
+	logger.Header("Host Inventory")
+	if cloudErr != nil {
+		logger.Warning("Could not identify the cloud instance: %v", cloudErr)
+	}
+	logger.KeyValue("Hostname", inventory.Hostname)
+	logger.KeyValue("OS", inventory.OS.Name)
+	logger.KeyValue("Kernel", inventory.Kernel)
+	logger.KeyValue("Architecture", inventory.Arch)
+	logger.KeyValue("Environment", inventory.Environment)
+	if cloud := inventory.Cloud; cloud != nil {
+		logger.KeyValue("Cloud", fmt.Sprintf("%s %s", cloud.Provider, cloud.InstanceID))
+		logger.KeyValue("Region", cloud.Region)
+		logger.KeyValue("Account", cloud.AccountID)
+	}
+	logger.KeyValue("CLI version", inventory.CLIVersion)
+	logger.Separator()
+	agent := inventory.Agent
+	if !agent.Installed {
+		logger.Info("Agent is not installed")
+		return nil
+	}
+	logger.KeyValue("Agent ID", agent.AgentID)
+	logger.KeyValue("Agent version", agent.Version)
+	if agent.InstalledAt != nil {
+		logger.KeyValue("Installed", agent.InstalledAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
+
+// writeInventoryPorcelain prints the inventory as --porcelain fields
+func writeInventoryPorcelain(inventory *fleet.Inventory) error {
+	p, err := newPorcelainWriter(os.Stdout)
+	if err != nil {
+		return err
+	}
+	cloud := inventory.Cloud
+	if cloud == nil {
+		cloud = &cloudmeta.Instance{}
+	}
+	p.field("hostname", inventory.Hostname)
+	p.field("os.id", inventory.OS.ID)
+	p.field("os.name", inventory.OS.Name)
+	p.field("os.version", inventory.OS.Version)
+	p.field("kernel", inventory.Kernel)
+	p.field("arch", inventory.Arch)
+	p.field("environment", inventory.Environment)
+	p.field("cloud.provider", cloud.Provider)
+	p.field("cloud.instance_id", cloud.InstanceID)
+	p.field("cloud.region", cloud.Region)
+	p.field("cloud.account_id", cloud.AccountID)
+	p.field("cli_version", inventory.CLIVersion)
+	p.field("agent.installed", inventory.Agent.Installed)
+	p.field("agent.id", inventory.Agent.AgentID)
+	p.field("agent.version", inventory.Agent.Version)
+	p.field("agent.installed_at", inventory.Agent.InstalledAt)
+	return p.close()
+}
+
+// collectInventory gathers the inventory of this host, querying the cloud
+// metadata services if cloud is set. A failed metadata query leaves the cloud
+// instance out and is returned as the error.
+func collectInventory(ctx context.Context, platformInfo *platform.PlatformInfo, cloud bool) (*fleet.Inventory, error) {
+	inventory := &fleet.Inventory{
+		Schema:      fleet.InventorySchema,
+		CollectedAt: time.Now().UTC(),
+		OS:          platform.GetOSRelease(),
+		Kernel:      platform.KernelRelease(),
+		Arch:        runtime.GOARCH,
+		Environment: string(platform.DetectEnvironment()),
+		CLIVersion:  getCurrentVersion(),
+	}
+	inventory.Hostname, _ = os.Hostname()
+	var cloudErr error
+	if cloud {
+		inventory.Cloud, cloudErr = cloudmeta.Detect(ctx)
+	}
+
+	connectivityManager := connectivity.NewManager(platformInfo)
+	agent := &inventory.Agent
+	agent.Installed = connectivityManager.IsFixPanicAgentInstalled()
+	if !agent.Installed {
+		return inventory, cloudErr
+	}
+	if output, err := connectivityManager.GetFixPanicAgentVersion(ctx); err == nil {
+		agent.Version = connectivity.ParseAgentVersion(output)
+	}
+	if agentConfig, err := config.LoadConfig(platformInfo.GetConfigPath()); err == nil {
+		agent.AgentID = agentConfig.App.AgentID
+	}
+
+	var installedAt time.Time
+	if saved, err := stateStore(platformInfo).Load(); err == nil {
+		if install := saved.LastInstall(); install != nil {
+			installedAt = install.Time
+		}
+	}
+	if installedAt.IsZero() {
+		installedAt = lastAgentChange(platformInfo)
+	}
+	if installedAt.IsZero() {
+		if info, err := os.Stat(platformInfo.GetFixPanicAgentBinaryPath()); err == nil {
+			installedAt = info.ModTime()
+		}
+	}
+	if !installedAt.IsZero() {
+		installedAt = installedAt.UTC()
+		agent.InstalledAt = &installedAt
+	}
+	return inventory, cloudErr
+}
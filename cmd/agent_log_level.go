@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/hooks"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/process"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logLevelDuration time.Duration
+	logLevelRevert   bool
+)
+
+// logLevels are the levels the agent accepts
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// logLevelRevertUnit is the transient systemd unit that reverts the level
+const logLevelRevertUnit = "fixpanic-log-level-revert"
+
+// agentSetLogLevelCmd represents the agent set-log-level command
+var agentSetLogLevelCmd = &cobra.Command{
+	Use:   "set-log-level <level>",
+	Short: "Change the agent log level, optionally for a limited time",
+	Long: `Change logging.level in the agent configuration and restart the agent so it
+takes effect. Levels are debug, info, warn and error.
+
+With --duration the previous level is restored automatically once the
+duration has passed, so debug logging isn't left on by accident. The revert
+is scheduled as a transient systemd timer where systemd is available, and by
+a background fixpanic process otherwise (which doesn't survive a reboot).
+Setting the level again replaces a pending revert, keeping the level it
+restores; setting it without --duration cancels the revert.`,
+	Example: `  # Debug logging for the next 30 minutes
+  sudo fixpanic agent set-log-level debug --duration 30m
+
+  # Go back to info logging now
+  sudo fixpanic agent set-log-level info`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if logLevelRevert {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	ValidArgs:   logLevels,
+	Annotations: map[string]string{annotationMutating: "true", annotationRequires: requireInstalled},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if logLevelRevert {
+			return runLogLevelRevert(cmd)
+		}
+		return withLock(cmd, func() error { return runAgentSetLogLevel(cmd, args) })
+	},
+}
+
+func init() {
+	agentCmd.AddCommand(agentSetLogLevelCmd)
+
+	// Add flags
+	agentSetLogLevelCmd.Flags().DurationVar(&logLevelDuration, "duration", 0, "Restore the previous level after this long (e.g. 30m)")
+	agentSetLogLevelCmd.Flags().BoolVar(&logLevelRevert, "revert", false, "Restore the level saved by an earlier --duration once it is due")
+	agentSetLogLevelCmd.Flags().MarkHidden("revert")
+}
+
+// logLevelRevertState is the pending revert written by --duration
+type logLevelRevertState struct {
+	// Level is the temporary level; the revert is skipped if it was changed
+	Level    string    `json:"level"`
+	Previous string    `json:"previous"`
+	RevertAt time.Time `json:"revert_at"`
+}
+
+func runAgentSetLogLevel(cmd *cobra.Command, args []string) error {
+	level := strings.ToLower(args[0])
+	if !isLogLevel(level) {
+		return clierror.New(clierror.Usage, "invalid log level %q", args[0]).
+			WithHint(i18n.Sprintf("Use one of: %s", strings.Join(logLevels, ", ")))
+	}
+	if logLevelDuration < 0 {
+		return clierror.New(clierror.Usage, "--duration must be positive")
+	}
+
+	logger.Header("Setting Agent Log Level")
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	// Loaded under the lock rather than by the middleware, so a revert that
+	// was waiting for it sees the level this command sets
+	configPath := platformInfo.GetConfigPath()
+	agentConfig, err := config.LoadConfig(configPath)
+	if err != nil {
+		return clierror.New(clierror.Config, "failed to load configuration: %w", err).
+			WithHint(i18n.Sprintf("Check that %s exists and is valid YAML", configPath), hintReinstallAgent)
+	}
+
+	// A pending revert keeps restoring the level from before the first change
+	previous := agentConfig.Logging.Level
+	revertPath := platformInfo.GetLogLevelRevertPath()
+	if pending, err := readLogLevelRevert(revertPath); err == nil && pending.Level == previous {
+		previous = pending.Previous
+	}
+	cancelLogLevelRevert(cmd.Context(), revertPath)
+
+	ctx := cmd.Context()
+	if err := applyLogLevel(ctx, agentConfig, configPath, level); err != nil {
+		return err
+	}
+	logger.KeyValue("Old level", agentConfig.Logging.Level)
+	logger.KeyValue("New level", level)
+
+	if logLevelDuration == 0 || level == previous {
+		logger.Success("Log level set to %s", level)
+		return nil
+	}
+
+	state := logLevelRevertState{Level: level, Previous: previous, RevertAt: time.Now().Add(logLevelDuration).Truncate(time.Second)}
+	if err := scheduleLogLevelRevert(ctx, revertPath, state); err != nil {
+		os.Remove(revertPath)
+		return clierror.WithHint(fmt.Errorf("log level set to %s, but the revert could not be scheduled: %w", level, err),
+			i18n.Sprintf("Run 'fixpanic agent set-log-level %s' when you are done", previous))
+	}
+	logger.Success("Log level set to %s until %s, then back to %s", level, state.RevertAt.Format("15:04:05"), previous)
+	return nil
+}
+
+// runLogLevelRevert waits until the pending revert is due and restores the
+// previous level, unless the level was changed again in the meantime
+func runLogLevelRevert(cmd *cobra.Command) error {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+	revertPath := platformInfo.GetLogLevelRevertPath()
+
+	state, err := readLogLevelRevert(revertPath)
+	if err != nil {
+		return nil
+	}
+	select {
+	case <-time.After(time.Until(state.RevertAt)):
+	case <-cmd.Context().Done():
+		return cmd.Context().Err()
+	}
+
+	// The background revert waits for other operations instead of failing
+	if !cmd.Flags().Changed("lock-timeout") {
+		lockTimeout = 5 * time.Minute
+	}
+	return withLock(cmd, func() error {
+		// A newer set-log-level replaced or cancelled this revert
+		current, err := readLogLevelRevert(revertPath)
+		if err != nil || !current.RevertAt.Equal(state.RevertAt) {
+			return nil
+		}
+		defer os.Remove(revertPath)
+
+		configPath := platformInfo.GetConfigPath()
+		agentConfig, err := config.LoadConfig(configPath)
+		if err != nil {
+			return clierror.New(clierror.Config, "failed to load configuration: %w", err)
+		}
+		if agentConfig.Logging.Level != state.Level {
+			logger.Info("Log level was changed to %s in the meantime; not reverting", agentConfig.Logging.Level)
+			return nil
+		}
+
+		logger.Header("Reverting Agent Log Level")
+		if err := applyLogLevel(cmd.Context(), agentConfig, configPath, state.Previous); err != nil {
+			return err
+		}
+		logger.Success("Log level reverted to %s", state.Previous)
+		return nil
+	})
+}
+
+// applyLogLevel saves level in the configuration and restarts the agent if
+// it is running, so it picks the level up
+func applyLogLevel(ctx context.Context, agentConfig *config.AgentConfig, configPath, level string) error {
+	if agentConfig.Logging.Level == level {
+		return nil
+	}
+	updated := *agentConfig
+	updated.Logging.Level = level
+	if err := config.SaveConfig(&updated, configPath); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	pids, err := getAllAgentProcessPIDs()
+	if err != nil || len(pids) == 0 {
+		logger.Info("Agent is not running; the level applies the next time it starts")
+		return nil
+	}
+
+	logger.Progress("Restarting the agent to apply the log level")
+	if err := runWithHooks(ctx, hooks.OperationStop, func() error { return stopAgent(ctx) }); err != nil {
+		logger.Warning("Stop failed: %v", err)
+	}
+	if err := runWithHooks(ctx, hooks.OperationStart, func() error { return startAgent(ctx) }); err != nil {
+		return fmt.Errorf("failed to start agent: %w", err)
+	}
+	return nil
+}
+
+// scheduleLogLevelRevert records state and arranges for 'set-log-level
+// --revert' to run when it is due
+func scheduleLogLevelRevert(ctx context.Context, path string, state logLevelRevertState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the fixpanic binary: %w", err)
+	}
+
+	if platform.IsSystemdAvailable() {
+		seconds := int(time.Until(state.RevertAt).Seconds()) + 1
+		output, err := exec.CommandContext(ctx, "systemd-run", "--unit="+logLevelRevertUnit,
+			fmt.Sprintf("--on-active=%ds", seconds), "--timer-property=AccuracySec=1s",
+			"--description=Revert the FixPanic agent log level",
+			execPath, "agent", "set-log-level", "--revert").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("systemd-run failed: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		logger.KeyValue("Revert timer", logLevelRevertUnit+".timer")
+		return nil
+	}
+
+	procInfo, err := process.NewProcessManager().StartProcess(process.ProcessConfig{
+		BinaryPath: execPath,
+		Args:       []string{"agent", "set-log-level", "--revert"},
+		Detach:     true,
+	})
+	if err != nil {
+		return err
+	}
+	logger.KeyValue("Revert process", fmt.Sprintf("PID %d", procInfo.PID))
+	return nil
+}
+
+// cancelLogLevelRevert removes a pending revert. A background revert process
+// notices on its own; a systemd timer is stopped.
+func cancelLogLevelRevert(ctx context.Context, path string) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Warning("Failed to remove pending log level revert: %v", err)
+	}
+	if platform.IsSystemdAvailable() {
+		exec.CommandContext(ctx, "systemctl", "stop", logLevelRevertUnit+".timer").Run()
+		exec.CommandContext(ctx, "systemctl", "reset-failed", logLevelRevertUnit+".service").Run()
+	}
+}
+
+// readLogLevelRevert reads the pending revert, if any
+func readLogLevelRevert(path string) (*logLevelRevertState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var state logLevelRevertState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// isLogLevel reports whether level is one the agent accepts
+func isLogLevel(level string) bool {
+	for _, known := range logLevels {
+		if level == known {
+			return true
+		}
+	}
+	return false
+}
@@ -1,17 +1,38 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/fixpanic/fixpanic-cli/internal/audit"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/diskspace"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
 	"github.com/fixpanic/fixpanic-cli/internal/platform"
 	"github.com/fixpanic/fixpanic-cli/internal/service"
+	"github.com/fixpanic/fixpanic-cli/internal/term"
 	"github.com/spf13/cobra"
 )
 
 var logLines int
 var followLogs bool
+var logDiskUsage bool
+var logExport string
+var logRedactIPs bool
+var logNoPager bool
+var logSince string
+
+// journalSuppressedPeriod is how far back dropped journal messages are counted
+const journalSuppressedPeriod = 24 * time.Hour
 
 // agentLogsCmd represents the agent logs command
 var agentLogsCmd = &cobra.Command{
@@ -20,7 +41,12 @@ var agentLogsCmd = &cobra.Command{
 	Long: `View the logs of the Fixpanic agent.
 	
 This command shows the agent logs from systemd journal or from the log file
-if systemd is not available.`,
+if systemd is not available.
+
+On a terminal, errors are shown in red, warnings in yellow and debug output
+in gray, and the logs are paged through $PAGER (less by default) unless they
+fit on one screen. Set NO_COLOR to turn the colors off, and use --no-pager
+to print the logs directly.`,
 	Example: `  # View last 50 lines of logs
   fixpanic agent logs
   
@@ -28,7 +54,19 @@ if systemd is not available.`,
   fixpanic agent logs --lines=100
   
   # Follow logs in real-time
-  fixpanic agent logs --follow`,
+  fixpanic agent logs --follow
+
+  # Print the last 500 lines without the pager
+  fixpanic agent logs --lines=500 --no-pager
+
+  # Show how much disk the logs take and whether journald drops messages
+  fixpanic agent logs --disk-usage
+
+  # Export the last 1000 lines with secrets and IP addresses removed
+  fixpanic agent logs --lines=1000 --export agent-logs.txt --redact-ips
+
+  # Show everything logged in the last hour
+  fixpanic agent logs --since 1h`,
 	RunE: runAgentLogs,
 }
 
@@ -38,15 +76,30 @@ func init() {
 	// Add flags
 	agentLogsCmd.Flags().IntVarP(&logLines, "lines", "n", 50, "Number of log lines to show")
 	agentLogsCmd.Flags().BoolVarP(&followLogs, "follow", "f", false, "Follow log output in real-time")
+	agentLogsCmd.Flags().BoolVar(&logDiskUsage, "disk-usage", false, "Show the disk usage of the journal and log files instead of logs")
+	agentLogsCmd.Flags().StringVar(&logExport, "export", "", "Write the logs to a file with API keys, tokens and passwords redacted")
+	agentLogsCmd.Flags().BoolVar(&logRedactIPs, "redact-ips", false, "Also redact IP addresses in exported logs")
+	agentLogsCmd.Flags().BoolVar(&logNoPager, "no-pager", false, "Print the logs directly instead of through $PAGER")
+	agentLogsCmd.Flags().StringVar(&logSince, "since", "", "Show all logs newer than this age or time (e.g. 30m, 24h, 7d or 2025-01-01T12:00:00Z), the last --lines of them if given")
 }
 
 func runAgentLogs(cmd *cobra.Command, args []string) error {
-	fmt.Println("Fetching Fixpanic agent logs...")
+	if logDiskUsage {
+		return runLogDiskUsage(cmd)
+	}
+	if logExport != "" {
+		return runLogExport(cmd)
+	}
+	if logSince != "" {
+		return runLogsSince(cmd)
+	}
+
+	fmt.Fprintln(os.Stderr, "Fetching Fixpanic agent logs...")
 
 	// Get platform information
-	platformInfo, err := platform.GetPlatformInfo()
+	platformInfo, err := commandPlatform()
 	if err != nil {
-		return fmt.Errorf("failed to get platform info: %w", err)
+		return err
 	}
 
 	// Try to get logs from systemd service if available
@@ -55,78 +108,385 @@ func runAgentLogs(cmd *cobra.Command, args []string) error {
 
 		if followLogs {
 			// Follow logs in real-time
-			fmt.Println("Following agent logs (press Ctrl+C to stop)...")
-			return followSystemdLogs(platform.GetSystemdServiceName())
+			fmt.Fprintln(os.Stderr, "Following agent logs (press Ctrl+C to stop)...")
+			return followSystemdLogs(cmd.Context(), platform.GetSystemdServiceName())
 		} else {
 			// Get static logs
-			logs, err := serviceManager.GetServiceLogs(logLines)
+			logs, err := serviceManager.GetServiceLogs(cmd.Context(), logLines)
 			if err != nil {
-				fmt.Printf("Warning: could not get systemd logs: %v\n", err)
-				fmt.Println("Trying to read log file directly...")
-				return readLogFile(platformInfo, logLines)
+				fmt.Fprintf(os.Stderr, "Warning: could not get systemd logs: %v\n", err)
+				fmt.Fprintln(os.Stderr, "Trying to read log file directly...")
+				return pageLogs(func(w io.Writer) error { return readLogFile(w, platformInfo, logLines) })
 			}
 
 			if logs == "" {
-				fmt.Println("No logs found for the agent service.")
-			} else {
-				fmt.Println(logs)
+				fmt.Fprintln(os.Stderr, "No logs found for the agent service.")
+				return nil
 			}
-			return nil
+			return pageLogs(func(w io.Writer) error { return writeLogLines(w, strings.NewReader(logs)) })
 		}
 	}
 
 	// Fallback: read log file directly
-	fmt.Println("Systemd not available. Reading log file directly...")
-	return readLogFile(platformInfo, logLines)
+	fmt.Fprintln(os.Stderr, "Systemd not available. Reading log file directly...")
+	return pageLogs(func(w io.Writer) error { return readLogFile(w, platformInfo, logLines) })
+}
+
+// pageLogs calls fn with the pager's input, or with stdout for --no-pager
+func pageLogs(fn func(w io.Writer) error) error {
+	if logNoPager {
+		return fn(os.Stdout)
+	}
+	return withPager(fn)
 }
 
-func followSystemdLogs(serviceName string) error {
+// writeLogLines copies the log lines read from r to w, colored by severity
+// when stdout is a terminal
+func writeLogLines(w io.Writer, r io.Reader) error {
+	color := term.IsTerminal(os.Stdout)
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if color {
+				line = logger.LogLine(strings.TrimSuffix(line, "\n")) + "\n"
+			}
+			if _, err := io.WriteString(w, line); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func followSystemdLogs(ctx context.Context, serviceName string) error {
 	// Use journalctl to follow logs
 	args := []string{"journalctl", "-u", serviceName, "-f", "--no-pager"}
-	cmd := exec.Command(args[0], args[1:]...)
-	cmd.Stdout = os.Stdout
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
 	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to follow logs: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to follow logs: %w", err)
+	}
+	writeLogLines(os.Stdout, stdout)
 
-	if err := cmd.Run(); err != nil {
+	if err := cmd.Wait(); err != nil {
+		// Following ends with Ctrl+C or --timeout; that's not a failure
+		if ctx.Err() != nil {
+			return nil
+		}
 		return fmt.Errorf("failed to follow logs: %w", err)
 	}
 
 	return nil
 }
 
-func readLogFile(platformInfo *platform.PlatformInfo, lines int) error {
+// readLogFile writes the last lines of the agent's log file, or all of it
+// for lines <= 0, to w
+func readLogFile(w io.Writer, platformInfo *platform.PlatformInfo, lines int) error {
 	logPath := fmt.Sprintf("%s/agent.log", platformInfo.LogDir)
 
 	// Check if log file exists
 	if _, err := os.Stat(logPath); os.IsNotExist(err) {
-		fmt.Printf("No log file found at: %s\n", logPath)
-		fmt.Println("The agent might not have been started yet, or logging might be disabled.")
+		fmt.Fprintf(w, "No log file found at: %s\n", logPath)
+		fmt.Fprintln(w, "The agent might not have been started yet, or logging might be disabled.")
 		return nil
 	}
 
-	// Read the log file
+	// Read only the last N lines; the log can be gigabytes
 	if lines > 0 {
-		// Use tail to get the last N lines
-		cmd := exec.Command("tail", "-n", fmt.Sprintf("%d", lines), logPath)
-		output, err := cmd.Output()
+		lastLines, err := readLastLines(logPath, lines)
 		if err != nil {
-			// Fallback: read entire file
-			content, err := os.ReadFile(logPath)
-			if err != nil {
-				return fmt.Errorf("failed to read log file: %w", err)
+			return fmt.Errorf("failed to read log file: %w", err)
+		}
+		if len(lastLines) == 0 {
+			return nil
+		}
+		return writeLogLines(w, strings.NewReader(strings.Join(lastLines, "\n")+"\n"))
+	}
+
+	// Stream the entire file
+	file, err := os.Open(logPath)
+	if err != nil {
+		return fmt.Errorf("failed to read log file: %w", err)
+	}
+	defer file.Close()
+	return writeLogLines(w, file)
+}
+
+// runLogsSince shows the agent logs newer than --since: all of them, or the
+// last --lines if given
+func runLogsSince(cmd *cobra.Command) error {
+	if followLogs {
+		return clierror.New(clierror.Usage, "--since can't be combined with --follow")
+	}
+	since, err := parseLogSince(logSince, time.Now())
+	if err != nil {
+		return clierror.Wrap(clierror.Usage, err)
+	}
+	lines := 0
+	if cmd.Flags().Changed("lines") {
+		lines = logLines
+	}
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	if platform.IsSystemdAvailable() {
+		reader, writer := io.Pipe()
+		go func() {
+			writer.CloseWithError(service.NewManager(platformInfo).WriteServiceLogsSince(cmd.Context(), writer, since, lines))
+		}()
+		err := pageLogs(func(w io.Writer) error { return writeLogLines(w, reader) })
+		reader.Close()
+		if err == nil {
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "Warning: could not get systemd logs: %v\n", err)
+		fmt.Fprintln(os.Stderr, "Trying to read log file directly...")
+	}
+	return pageLogs(func(w io.Writer) error { return readLogFileSince(w, platformInfo, since, lines) })
+}
+
+// parseLogSince parses --since: an age as audit.ParseSince takes it, or an
+// RFC 3339 time
+func parseLogSince(value string, now time.Time) (time.Time, error) {
+	if since, err := time.Parse(time.RFC3339, value); err == nil {
+		return since, nil
+	}
+	return audit.ParseSince(value, now)
+}
+
+// logTimeLayouts are the timestamps log lines are recognised to start with
+var logTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006/01/02 15:04:05"}
+
+// logLineTime returns the time a log line starts with, if it does
+func logLineTime(line string) (time.Time, bool) {
+	for _, layout := range logTimeLayouts {
+		prefix, _, _ := strings.Cut(line, " ")
+		if strings.Contains(layout, " ") {
+			if len(line) < len(layout) {
+				continue
 			}
-			fmt.Print(string(content))
+			prefix = line[:len(layout)]
+		}
+		if t, err := time.ParseInLocation(layout, prefix, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// readLogFileSince writes the lines of the agent's log file newer than since
+// to w, only the last lines of them if lines is positive. Lines without a
+// timestamp, like the rest of a stack trace, go with the line before them.
+func readLogFileSince(w io.Writer, platformInfo *platform.PlatformInfo, since time.Time, lines int) error {
+	file, err := os.Open(filepath.Join(platformInfo.LogDir, "agent.log"))
+	if err != nil {
+		return fmt.Errorf("failed to read log file: %w", err)
+	}
+	defer file.Close()
+
+	reader, writer := io.Pipe()
+	go func() {
+		var kept []string
+		include := false
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if t, ok := logLineTime(line); ok {
+				include = !t.Before(since)
+			}
+			if !include {
+				continue
+			}
+			if lines <= 0 {
+				if _, err := io.WriteString(writer, line+"\n"); err != nil {
+					return
+				}
+				continue
+			}
+			kept = append(kept, line)
+			if len(kept) > lines {
+				kept = kept[1:]
+			}
+		}
+		for _, line := range kept {
+			io.WriteString(writer, line+"\n")
+		}
+		writer.CloseWithError(scanner.Err())
+	}()
+	err = writeLogLines(w, reader)
+	reader.Close()
+	return err
+}
+
+// tailChunkSize is how much of a log file is read at a time, backwards from
+// its end, to find the last lines
+const tailChunkSize = 64 * 1024
+
+// readLastLines returns up to n lines from the end of a file. The file is
+// read backwards a chunk at a time until it holds n lines, so only about as
+// much as the lines themselves is read, however large the file is.
+func readLastLines(path string, n int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// n lines are complete once n+1 newlines were seen: one ending each
+	// line and the one before the first
+	var chunks [][]byte
+	var size int
+	newlines := 0
+	for offset := info.Size(); offset > 0 && newlines <= n; {
+		length := int64(tailChunkSize)
+		if offset < length {
+			length = offset
+		}
+		offset -= length
+
+		chunk := make([]byte, length)
+		if _, err := file.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		newlines += bytes.Count(chunk, []byte("\n"))
+		chunks = append(chunks, chunk)
+		size += len(chunk)
+	}
+	if size == 0 {
+		return nil, nil
+	}
+
+	buf := make([]byte, 0, size)
+	for i := len(chunks) - 1; i >= 0; i-- {
+		buf = append(buf, chunks[i]...)
+	}
+	lines := strings.Split(strings.TrimRight(string(buf), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	return lines, nil
+}
+
+// runLogDiskUsage reports the disk usage of the journal and the agent's log
+// files, and whether journald dropped agent messages
+func runLogDiskUsage(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	logger.Header("Agent Log Disk Usage")
+
+	if platform.IsSystemdAvailable() {
+		if usage, err := service.JournalDiskUsage(ctx); err != nil {
+			logger.Warning("Could not read the journal disk usage: %v", err)
 		} else {
-			fmt.Print(string(output))
+			logger.KeyValue("Journal (all units)", diskspace.FormatBytes(usage))
+			if usage > service.JournalUsageWarnBytes {
+				logger.Warning("The journal is larger than %s; limit it with SystemMaxUse= in /etc/systemd/journald.conf", diskspace.FormatBytes(service.JournalUsageWarnBytes))
+			}
 		}
-	} else {
-		// Read entire file
-		content, err := os.ReadFile(logPath)
+		if free, err := diskspace.Free(service.JournalDir); err == nil {
+			logger.KeyValue(i18n.Sprintf("Free in %s", service.JournalDir), diskspace.FormatBytes(free))
+		}
+
+		suppressed, err := service.NewManager(platformInfo).SuppressedMessages(ctx, journalSuppressedPeriod)
 		if err != nil {
-			return fmt.Errorf("failed to read log file: %w", err)
+			logger.Warning("Could not check for dropped messages: %v", err)
+		} else if suppressed > 0 {
+			logger.Warning("journald dropped %d agent message(s) in the last 24 hours because of its rate limit", suppressed)
+			logger.Info("Raise the limit with 'sudo systemctl edit %s' and:", platform.GetSystemdServiceName())
+			logger.Plain("%s", service.RateLimitOverride)
+		} else {
+			logger.KeyValue("Dropped messages (24h)", "0")
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(platformInfo.LogDir, "agent.log*"))
+	if len(files) == 0 {
+		logger.KeyValue("Log files", i18n.Sprintf("none in %s", platformInfo.LogDir))
+		return nil
+	}
+	var total int64
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		total += info.Size()
+		logger.KeyValue(file, formatSize(info.Size()))
+	}
+	logger.KeyValue("Log files total", formatSize(total))
+	if free, err := diskspace.Free(platformInfo.LogDir); err == nil {
+		logger.KeyValue(i18n.Sprintf("Free in %s", platformInfo.LogDir), diskspace.FormatBytes(free))
+	}
+	return nil
+}
+
+// runLogExport writes the last lines of the agent logs, redacted, to the
+// file given with --export so they can be shared outside the organization
+func runLogExport(cmd *cobra.Command) error {
+	if followLogs {
+		return clierror.New(clierror.Usage, "--export can't be combined with --follow")
+	}
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+	redactor, err := newRedactor(platformInfo, logRedactIPs)
+	if err != nil {
+		return err
+	}
+
+	var logs string
+	if platform.IsSystemdAvailable() {
+		logs, err = service.NewManager(platformInfo).GetServiceLogs(cmd.Context(), logLines)
+	}
+	if !platform.IsSystemdAvailable() || err != nil {
+		lines, fileErr := readLastLines(filepath.Join(platformInfo.LogDir, "agent.log"), logLines)
+		if fileErr != nil {
+			if err != nil {
+				return fmt.Errorf("failed to read logs: %w", err)
+			}
+			return fmt.Errorf("failed to read log file: %w", fileErr)
 		}
-		fmt.Print(string(content))
+		logs = strings.Join(lines, "\n")
+	}
+
+	// The export may still hold host details, so only the owner can read it
+	file, err := os.OpenFile(logExport, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", logExport, err)
+	}
+	written, err := redactor.Copy(file, strings.NewReader(logs))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", logExport, err)
 	}
 
+	logger.Success("Exported %d redacted log line(s) to %s", written, logExport)
+	logger.Info("Review the file before sharing it; only known secret formats are redacted")
 	return nil
 }
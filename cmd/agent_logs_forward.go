@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/logforward"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/spf13/cobra"
+)
+
+var (
+	forwardTarget string
+	forwardPrint  bool
+	forwardRemove bool
+)
+
+// agentLogsForwardCmd represents the agent logs forward command
+var agentLogsForwardCmd = &cobra.Command{
+	Use:   "forward",
+	Short: "Ship the agent logs to a central logging system",
+	Long: `Configure the host's log shipper to forward the agent's log file to a
+central logging system:
+
+  syslog://host[:port]       syslog over UDP (default port 514), via rsyslog
+  syslog+tcp://host[:port]   syslog over TCP, via rsyslog
+  loki://host[:port]         Grafana Loki (default port 3100), via Fluent Bit
+  lokis://host[:port]        Grafana Loki over HTTPS, via Fluent Bit
+  s3://bucket[/prefix]?region=<region>   an S3 bucket, via Fluent Bit
+
+The shipper must be installed. Its configuration for the agent is written to
+/etc/rsyslog.d/60-fixpanic-agent.conf or /etc/fluent-bit/fixpanic-agent.conf
+and the shipper is restarted. Forwarding to another target replaces the
+previous one; --remove stops forwarding.
+
+Credentials are never part of the target: Fluent Bit takes the S3 credentials
+from its environment or the instance role. Use --print to review the
+configuration, or to deploy it with your configuration management instead.`,
+	Example: `  # Forward to a syslog server over TCP
+  sudo fixpanic agent logs forward --target syslog+tcp://logs.example.com:6514
+
+  # Forward to Loki
+  sudo fixpanic agent logs forward --target loki://loki.example.com
+
+  # Show the Fluent Bit configuration for an S3 bucket without installing it
+  fixpanic agent logs forward --target "s3://acme-logs/fixpanic?region=eu-west-1" --print
+
+  # Stop forwarding
+  sudo fixpanic agent logs forward --remove`,
+	Annotations: map[string]string{annotationMutating: "true"},
+	RunE:        runAgentLogsForward,
+}
+
+func init() {
+	agentLogsCmd.AddCommand(agentLogsForwardCmd)
+
+	// Add flags
+	agentLogsForwardCmd.Flags().StringVar(&forwardTarget, "target", "", "Where to forward the logs: syslog://, syslog+tcp://, loki://, lokis:// or s3:// URL")
+	agentLogsForwardCmd.Flags().BoolVar(&forwardPrint, "print", false, "Print the shipper configuration instead of installing it")
+	agentLogsForwardCmd.Flags().BoolVar(&forwardRemove, "remove", false, "Stop forwarding the agent logs")
+}
+
+func runAgentLogsForward(cmd *cobra.Command, args []string) error {
+	if forwardRemove && (forwardTarget != "" || forwardPrint) {
+		return clierror.New(clierror.Usage, "--remove can't be combined with --target or --print")
+	}
+	if !forwardRemove && forwardTarget == "" {
+		return clierror.New(clierror.Usage, "--target is required").
+			WithHint("Pass e.g. --target syslog://logs.example.com, or --remove to stop forwarding")
+	}
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+	if !platformInfo.IsRoot && !forwardPrint {
+		if err := offerSudo(cmd, "forwarding the agent logs requires root"); err != nil {
+			return err
+		}
+		return clierror.New(clierror.Permission, "forwarding the agent logs requires root").
+			WithHint("Run the command with sudo, or use --print to only show the configuration")
+	}
+
+	if forwardRemove {
+		return removeLogForwarding(cmd.Context())
+	}
+
+	target, err := logforward.ParseTarget(forwardTarget)
+	if err != nil {
+		return clierror.New(clierror.Usage, "%w", err)
+	}
+	snippet, err := target.Render(agentLogFile(platformInfo))
+	if err != nil {
+		return fmt.Errorf("failed to render the %s configuration: %w", target.Shipper(), err)
+	}
+	if forwardPrint {
+		fmt.Printf("# %s\n%s", target.ConfigPath(), snippet)
+		return nil
+	}
+
+	logger.Header("Forward Agent Logs")
+	shipperBinary := "rsyslogd"
+	if target.Shipper() == logforward.ShipperFluentBit {
+		shipperBinary = "fluent-bit"
+	}
+	if !platform.IsCommandAvailable(shipperBinary) {
+		return clierror.New(clierror.NotInstalled, "%s is not installed; it ships the logs to %s", target.Shipper(), target.Scheme).
+			WithHint(fmt.Sprintf("Install it with your package manager (e.g. 'apt install %s'), or use --print and deploy the configuration yourself", target.Shipper()))
+	}
+
+	// Forwarding to another target replaces the previous configuration,
+	// which may belong to the other shipper
+	ctx := cmd.Context()
+	for _, path := range logforward.Configured() {
+		if path != target.ConfigPath() {
+			if err := removeForwardConfig(ctx, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target.ConfigPath()), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(target.ConfigPath()), err)
+	}
+	if err := os.WriteFile(target.ConfigPath(), []byte(snippet), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", target.ConfigPath(), err)
+	}
+	logger.Success("Wrote %s", target.ConfigPath())
+	if target.Shipper() == logforward.ShipperFluentBit {
+		added, err := logforward.AddInclude()
+		if err != nil {
+			return clierror.WithHint(err, fmt.Sprintf("Add '%s' to Fluent Bit's main configuration", logforward.Include))
+		}
+		if added {
+			logger.Success("Included it in %s", logforward.FluentBitMainPath)
+		}
+	}
+
+	if err := restartShipper(ctx, target.Shipper()); err != nil {
+		return err
+	}
+	logger.Success("The agent logs are forwarded to %s", target)
+	if target.Scheme == "s3" {
+		logger.Info("Fluent Bit uploads with the credentials of its environment or the instance role")
+	}
+	return nil
+}
+
+// agentLogFile returns the log file the agent writes to
+func agentLogFile(platformInfo *platform.PlatformInfo) string {
+	if agentConfig, err := config.LoadConfig(platformInfo.GetConfigPath()); err == nil && agentConfig.Logging.File != "" {
+		return agentConfig.Logging.File
+	}
+	return platformInfo.GetLogPath()
+}
+
+// removeLogForwarding removes the forwarding configuration of either shipper
+func removeLogForwarding(ctx context.Context) error {
+	configured := logforward.Configured()
+	if len(configured) == 0 {
+		logger.Info("The agent logs are not forwarded")
+		return nil
+	}
+	for _, path := range configured {
+		if err := removeForwardConfig(ctx, path); err != nil {
+			return err
+		}
+	}
+	logger.Success("The agent logs are no longer forwarded")
+	return nil
+}
+
+// removeForwardConfig removes the forwarding configuration at path and
+// restarts its shipper
+func removeForwardConfig(ctx context.Context, path string) error {
+	shipper := logforward.ShipperRsyslog
+	if path == logforward.FluentBitPath {
+		shipper = logforward.ShipperFluentBit
+		if err := logforward.RemoveInclude(); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	logger.Success("Removed %s", path)
+	return restartShipper(ctx, shipper)
+}
+
+// restartShipper restarts the log shipper so it picks up the configuration
+func restartShipper(ctx context.Context, shipper string) error {
+	if !platform.IsSystemdAvailable() {
+		logger.Warning("Restart %s to apply the configuration", shipper)
+		return nil
+	}
+	output, err := exec.CommandContext(ctx, "systemctl", "restart", shipper).CombinedOutput()
+	if err != nil {
+		return clierror.WithHint(fmt.Errorf("failed to restart %s: %w: %s", shipper, err, strings.TrimSpace(string(output))),
+			fmt.Sprintf("Check the configuration with 'journalctl -u %s'", shipper))
+	}
+	logger.Success("Restarted %s", shipper)
+	return nil
+}
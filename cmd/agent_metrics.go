@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/metrics"
+	"github.com/fixpanic/fixpanic-cli/internal/netprobe"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var (
+	metricsListenAddr string
+	textfilePath      string
+	textfileInterval  time.Duration
+)
+
+// defaultTextfilePath is where the textfile refresh writes the metrics unless
+// told otherwise
+const defaultTextfilePath = "/var/lib/node_exporter/textfile/fixpanic.prom"
+
+// minTextfileInterval keeps the refresh from probing the socket server too often
+const minTextfileInterval = time.Minute
+
+// agentMetricsCmd represents the agent metrics command group
+var agentMetricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Expose agent health metrics",
+	Long: `Expose Fixpanic agent health metrics for monitoring systems.
+
+Metrics are rendered in the Prometheus text exposition format so existing
+Prometheus servers can scrape them without custom scripts, either from
+'fixpanic agent metrics serve' or, without another listener, through
+node_exporter's textfile collector.`,
+}
+
+// agentMetricsServeCmd represents the agent metrics serve command
+var agentMetricsServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve Prometheus metrics over HTTP",
+	Long: `Start an HTTP server exposing agent health metrics on /metrics.
+
+Metrics are collected fresh on every scrape:
+  fixpanic_agent_installed                       - 1 if the agent binary is installed
+  fixpanic_agent_up                              - 1 if the agent is running
+  fixpanic_agent_info{version}                   - installed agent version
+  fixpanic_agent_restarts_total                  - automatic service restarts (systemd)
+  fixpanic_agent_last_upgrade_timestamp_seconds  - when the agent binary was last replaced
+  fixpanic_agent_socket_server_reachable         - 1 if the socket server accepts connections
+  fixpanic_agent_socket_server_connect_seconds   - TCP connect latency to the socket server
+  fixpanic_agent_socket_server_dns_seconds       - DNS lookup latency of the socket server`,
+	Example: `  # Serve metrics on the default port
+  fixpanic agent metrics serve
+
+  # Serve metrics on localhost only
+  fixpanic agent metrics serve --listen 127.0.0.1:9402`,
+	RunE: runAgentMetricsServe,
+}
+
+// agentMetricsTextfileCmd represents the agent metrics textfile command group
+var agentMetricsTextfileCmd = &cobra.Command{
+	Use:   "textfile",
+	Short: "Refresh a node_exporter textfile with the agent metrics",
+	Long: `Keep a .prom file for node_exporter's textfile collector up to date, for hosts
+whose node_exporter is already scraped and that shouldn't open another port.
+
+A systemd timer (or a cron entry without systemd) runs
+'fixpanic agent status --textfile <path>', which writes the same metrics as
+'fixpanic agent metrics serve'. Point --path into the directory node_exporter
+reads with --collector.textfile.directory.`,
+}
+
+// agentMetricsTextfileInstallCmd represents the agent metrics textfile install command
+var agentMetricsTextfileInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Refresh the textfile with a systemd timer or cron",
+	Example: `  # Refresh the default textfile every minute
+  sudo fixpanic agent metrics textfile install
+
+  # Refresh the textfile read by Debian's prometheus-node-exporter every 5 minutes
+  sudo fixpanic agent metrics textfile install --path /var/lib/prometheus/node-exporter/fixpanic.prom --interval 5m`,
+	Annotations: map[string]string{annotationMutating: "true", annotationRequires: requireRoot},
+	RunE:        runAgentMetricsTextfileInstall,
+}
+
+// agentMetricsTextfileUninstallCmd represents the agent metrics textfile uninstall command
+var agentMetricsTextfileUninstallCmd = &cobra.Command{
+	Use:         "uninstall",
+	Short:       "Stop refreshing the textfile and remove it",
+	Annotations: map[string]string{annotationMutating: "true"},
+	RunE:        runAgentMetricsTextfileUninstall,
+}
+
+func init() {
+	agentCmd.AddCommand(agentMetricsCmd)
+	agentMetricsCmd.AddCommand(agentMetricsServeCmd)
+	agentMetricsCmd.AddCommand(agentMetricsTextfileCmd)
+	agentMetricsTextfileCmd.AddCommand(agentMetricsTextfileInstallCmd)
+	agentMetricsTextfileCmd.AddCommand(agentMetricsTextfileUninstallCmd)
+
+	// Add flags
+	agentMetricsServeCmd.Flags().StringVar(&metricsListenAddr, "listen", ":9402", "Address to listen on")
+	agentMetricsTextfileInstallCmd.Flags().StringVar(&textfilePath, "path", defaultTextfilePath, "The .prom file to write")
+	agentMetricsTextfileInstallCmd.Flags().DurationVar(&textfileInterval, "interval", time.Minute, "Time between refreshes")
+	agentMetricsTextfileUninstallCmd.Flags().StringVar(&textfilePath, "path", defaultTextfilePath, "The .prom file to remove")
+}
+
+func runAgentMetricsServe(cmd *cobra.Command, args []string) error {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		if err := metrics.Write(&buf, collectAgentMetrics(r.Context(), platformInfo)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", metrics.ContentType)
+		w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, `<html><body><h1>Fixpanic agent exporter</h1><a href="/metrics">Metrics</a></body></html>`)
+	})
+
+	server := &http.Server{
+		Addr:              metricsListenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Stop serving on Ctrl+C or --timeout
+	go func() {
+		<-cmd.Context().Done()
+		server.Shutdown(context.Background())
+	}()
+
+	logger.Info("Serving agent metrics on http://%s/metrics", metricsListenAddr)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("metrics server failed: %w", err)
+	}
+
+	return nil
+}
+
+func runAgentMetricsTextfileInstall(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if textfileInterval < minTextfileInterval {
+		return clierror.New(clierror.Usage, "--interval must be at least %s", minTextfileInterval)
+	}
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+	path, err := filepath.Abs(textfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", textfilePath, err)
+	}
+	if info, err := os.Stat(filepath.Dir(path)); err != nil || !info.IsDir() {
+		return clierror.New(clierror.Usage, "%s does not exist", filepath.Dir(path)).
+			WithHint("Pass the directory of node_exporter's --collector.textfile.directory with --path")
+	}
+
+	// Write the file right away so node_exporter has it before the first run
+	if err := writeStatusTextfile(ctx, path); err != nil {
+		return err
+	}
+	logger.Success("Wrote %s", path)
+
+	binaryPath, err := getCurrentBinaryPath()
+	if err != nil {
+		return fmt.Errorf("failed to get current binary path: %w", err)
+	}
+	command := []string{binaryPath}
+	if platformInfo.Prefix != "" {
+		command = append(command, "--prefix", platformInfo.Prefix)
+	}
+	command = append(command, "agent", "status", "--textfile", path)
+
+	if err := service.NewManager(platformInfo).InstallTextfileRefresh(ctx, command, textfileInterval); err != nil {
+		return err
+	}
+	if platform.IsSystemdAvailable() {
+		logger.Success("Textfile refreshed every %s by %s.timer", textfileInterval, service.TextfileUnit)
+	} else {
+		logger.Success("Textfile refreshed with cron in %s", service.TextfileCronPath)
+		if textfileInterval%time.Minute != 0 {
+			logger.Info("cron runs at most once a minute; the interval was rounded up to whole minutes")
+		}
+	}
+	return nil
+}
+
+func runAgentMetricsTextfileUninstall(cmd *cobra.Command, args []string) error {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	removed, err := service.NewManager(platformInfo).UninstallTextfileRefresh(cmd.Context())
+	if err != nil {
+		return err
+	}
+	// A stale file would report the last status forever
+	if err := os.Remove(textfilePath); err == nil {
+		logger.Success("Removed %s", textfilePath)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", textfilePath, err)
+	}
+	if !removed {
+		logger.Info("No textfile refresh is installed")
+		return nil
+	}
+	logger.Success("Textfile refresh removed")
+	return nil
+}
+
+// collectAgentMetrics gathers a fresh set of agent health samples
+func collectAgentMetrics(ctx context.Context, platformInfo *platform.PlatformInfo) []metrics.Sample {
+	start := time.Now()
+	var samples []metrics.Sample
+
+	connectivityManager := connectivity.NewManager(platformInfo)
+	installed := connectivityManager.IsFixPanicAgentInstalled()
+	samples = append(samples, metrics.Sample{
+		Name:  "fixpanic_agent_installed",
+		Help:  "Whether the agent binary is installed.",
+		Type:  metrics.Gauge,
+		Value: metrics.Bool(installed),
+	})
+
+	running, _ := detectAgentRunning(ctx, platformInfo)
+	samples = append(samples, metrics.Sample{
+		Name:  "fixpanic_agent_up",
+		Help:  "Whether the agent is running.",
+		Type:  metrics.Gauge,
+		Value: metrics.Bool(running),
+	})
+
+	if installed {
+		version := "unknown"
+		if output, err := connectivityManager.GetFixPanicAgentVersion(ctx); err == nil {
+			version = connectivity.ParseAgentVersion(output)
+		}
+		samples = append(samples, metrics.Sample{
+			Name:   "fixpanic_agent_info",
+			Help:   "Installed agent version.",
+			Type:   metrics.Gauge,
+			Labels: map[string]string{"version": version},
+			Value:  1,
+		})
+
+		// Installs and upgrades are recorded in the state; the binary's
+		// modification time covers agents installed by older CLIs
+		changed := lastAgentChange(platformInfo)
+		if changed.IsZero() {
+			if info, err := os.Stat(platformInfo.GetFixPanicAgentBinaryPath()); err == nil {
+				changed = info.ModTime()
+			}
+		}
+		if !changed.IsZero() {
+			samples = append(samples, metrics.Sample{
+				Name:  "fixpanic_agent_last_upgrade_timestamp_seconds",
+				Help:  "Unix time the agent binary was last installed or upgraded.",
+				Type:  metrics.Gauge,
+				Value: float64(changed.Unix()),
+			})
+		}
+	}
+
+	if platform.IsSystemdAvailable() {
+		if restarts, err := service.NewManager(platformInfo).RestartCount(ctx); err == nil {
+			samples = append(samples, metrics.Sample{
+				Name:  "fixpanic_agent_restarts_total",
+				Help:  "Number of automatic service restarts since the unit was last started.",
+				Type:  metrics.Counter,
+				Value: float64(restarts),
+			})
+		}
+	}
+
+	socketServer := config.DefaultSocketServer
+	if agentConfig, err := config.LoadConfig(platformInfo.GetConfigPath()); err == nil {
+		socketServer = agentConfig.GetSocketServer()
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	result, err := netprobe.Dial(probeCtx, socketServer, 5*time.Second)
+	samples = append(samples, metrics.Sample{
+		Name:   "fixpanic_agent_socket_server_reachable",
+		Help:   "Whether the socket server accepts TCP connections from this host.",
+		Type:   metrics.Gauge,
+		Labels: map[string]string{"server": socketServer},
+		Value:  metrics.Bool(err == nil),
+	})
+	if err == nil {
+		samples = append(samples, metrics.Sample{
+			Name:   "fixpanic_agent_socket_server_connect_seconds",
+			Help:   "TCP connect latency to the socket server.",
+			Type:   metrics.Gauge,
+			Labels: map[string]string{"server": socketServer, "family": result.Family},
+			Value:  result.Latency.Seconds(),
+		})
+		samples = append(samples, metrics.Sample{
+			Name:   "fixpanic_agent_socket_server_dns_seconds",
+			Help:   "DNS lookup latency of the socket server host.",
+			Type:   metrics.Gauge,
+			Labels: map[string]string{"server": socketServer},
+			Value:  result.DNSLatency.Seconds(),
+		})
+	}
+
+	samples = append(samples, metrics.Sample{
+		Name:  "fixpanic_exporter_scrape_duration_seconds",
+		Help:  "Time spent collecting agent metrics.",
+		Type:  metrics.Gauge,
+		Value: time.Since(start).Seconds(),
+	})
+
+	return samples
+}
+
+// detectAgentRunning reports whether the agent is running, using systemd when
+// available and falling back to process discovery otherwise
+func detectAgentRunning(ctx context.Context, platformInfo *platform.PlatformInfo) (bool, int) {
+	if platform.IsSystemdAvailable() {
+		status, err := service.NewManager(platformInfo).Status(ctx)
+		if err == nil && status == "active" {
+			return true, getServicePID(ctx)
+		}
+	}
+
+	running, pid, err := getAgentProcessInfo()
+	if err != nil {
+		return false, 0
+	}
+	return running, pid
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/legacy"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var agentMigrateDryRun bool
+
+// agentMigrateCmd represents the agent migrate command
+var agentMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move an installation made by an older CLI to the current layout",
+	Long: `Detect an agent installed by an older version of the CLI and migrate it in
+place:
+
+  - a configuration at ~/.fixpanic/agent.yaml is moved to the configuration
+    directory
+  - a non-root install in the default directories is moved to the XDG base
+    directories once XDG_DATA_HOME, XDG_CONFIG_HOME or XDG_STATE_HOME are set,
+    and a log file setting pointing at the old log is updated
+  - the configuration is upgraded to the current schema
+  - a systemd unit starting an old binary or configuration path is re-rendered
+
+Files that exist at both the old and the new location are left alone and
+reported. Restart the agent afterwards so it uses the new locations.`,
+	Example: `  # Show what would be migrated
+  fixpanic agent migrate --dry-run
+
+  # Migrate the installation
+  fixpanic agent migrate`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{annotationMutating: "!dry-run", annotationRequires: requireLock},
+	RunE:        runAgentMigrate,
+}
+
+func init() {
+	agentCmd.AddCommand(agentMigrateCmd)
+
+	// Add flags
+	agentMigrateCmd.Flags().BoolVar(&agentMigrateDryRun, "dry-run", false, "Show what would be migrated without changing anything")
+}
+
+func runAgentMigrate(cmd *cobra.Command, args []string) error {
+	logger.Header("Migrating Legacy Installation")
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+	currentUser, err := user.Current()
+	if err != nil {
+		return fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	migrated, conflicts := 0, 0
+	configPath, oldLogPath := platformInfo.GetConfigPath(), ""
+	for _, move := range legacy.Find(platformInfo, currentUser.HomeDir) {
+		if move.Conflict {
+			conflicts++
+			logger.Warning("The %s exists at both %s and %s, leaving both", move.What, move.From, move.To)
+			continue
+		}
+		if move.To == platformInfo.GetLogPath() {
+			oldLogPath = move.From
+		}
+		if agentMigrateDryRun {
+			// The following steps read the files where they are now
+			if move.To == configPath {
+				configPath = move.From
+			}
+			logger.List("Would move the %s from %s to %s", move.What, move.From, move.To)
+			migrated++
+			continue
+		}
+		if err := move.Apply(); err != nil {
+			return clierror.Wrap(clierror.General, err)
+		}
+		logger.List("Moved the %s from %s to %s", move.What, move.From, move.To)
+		migrated++
+	}
+
+	if _, err := os.Stat(configPath); err == nil {
+		changes, err := migrateLegacyConfig(configPath, oldLogPath, platformInfo.GetLogPath())
+		if err != nil {
+			return err
+		}
+		migrated += changes
+	}
+
+	changes, err := migrateServiceUnit(cmd.Context(), platformInfo)
+	if err != nil {
+		return err
+	}
+	migrated += changes
+
+	switch {
+	case migrated == 0 && conflicts == 0:
+		logger.Success("The installation already uses the current layout")
+	case agentMigrateDryRun:
+		logger.Info("Dry run: nothing was changed. Run without --dry-run to apply.")
+	case migrated > 0:
+		logger.Success("Migrated %d item(s) to the current layout", migrated)
+		logger.Info("Run 'fixpanic agent restart' so the agent uses the new locations")
+	}
+	if conflicts > 0 {
+		return clierror.New(clierror.Config, "%d file(s) exist at both the old and the new location", conflicts).
+			WithHint("Remove the copy you don't need and run 'fixpanic agent migrate' again")
+	}
+	return nil
+}
+
+// migrateLegacyConfig upgrades the configuration at configPath to the current
+// schema and points a log file setting of oldLogPath at logPath. It returns
+// the number of changes made.
+func migrateLegacyConfig(configPath, oldLogPath, logPath string) (int, error) {
+	result, err := config.Migrate(configPath, agentMigrateDryRun)
+	if err != nil {
+		return 0, clierror.New(clierror.Config, "failed to migrate configuration: %w", err)
+	}
+	changes := len(result.Applied)
+	for _, description := range result.Applied {
+		if agentMigrateDryRun {
+			logger.List("Would migrate the configuration: %s", description)
+		} else {
+			logger.List("Migrated the configuration: %s", description)
+		}
+	}
+
+	agentConfig := result.Config
+	if oldLogPath == "" || agentConfig.Logging.File != oldLogPath {
+		return changes, nil
+	}
+	if agentMigrateDryRun {
+		logger.List("Would set the log file in %s to %s", configPath, logPath)
+		return changes + 1, nil
+	}
+	agentConfig.Logging.File = logPath
+	if err := config.SaveConfig(agentConfig, configPath); err != nil {
+		return changes, clierror.Wrap(clierror.General, err)
+	}
+	logger.List("Set the log file in %s to %s", configPath, logPath)
+	return changes + 1, nil
+}
+
+// migrateServiceUnit re-renders a systemd unit that starts an old binary or
+// configuration path. It returns the number of changes made.
+func migrateServiceUnit(ctx context.Context, platformInfo *platform.PlatformInfo) (int, error) {
+	if !platform.IsSystemdAvailable() {
+		return 0, nil
+	}
+	unitPath := platformInfo.GetServiceFilePath()
+	data, err := os.ReadFile(unitPath)
+	if err != nil {
+		return 0, nil
+	}
+	serviceManager := service.NewManager(platformInfo)
+	desired, err := serviceManager.Render()
+	if err != nil {
+		return 0, fmt.Errorf("failed to render service unit: %w", err)
+	}
+	actualExec, desiredExec := unitExecStart(string(data)), unitExecStart(desired)
+	if actualExec == desiredExec {
+		return 0, nil
+	}
+	if agentMigrateDryRun {
+		logger.List("Would re-render %s to start '%s' instead of '%s'", unitPath, desiredExec, actualExec)
+		return 1, nil
+	}
+	if err := serviceManager.Install(ctx); err != nil {
+		return 0, fmt.Errorf("failed to install service unit: %w", err)
+	}
+	logger.List("Re-rendered %s to start '%s' instead of '%s'", unitPath, desiredExec, actualExec)
+	return 1, nil
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/panicstop"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var (
+	panicStopReason string
+	panicResetForce bool
+)
+
+// agentPanicStopCmd represents the agent panic-stop command
+var agentPanicStopCmd = &cobra.Command{
+	Use:   "panic-stop",
+	Short: "Sever all remote access through the agent immediately",
+	Long: `Emergency kill switch for security incidents. Cuts every connection the
+platform has to this host at once:
+
+  - an emergency stop marker is written next to the agent configuration
+  - every agent process is killed without a grace period
+  - the systemd service is stopped and disabled
+
+While the marker exists nothing starts the agent again: 'fixpanic agent start',
+restarts, self-healing and installs refuse to, the watchdog holds the agent
+down, and the systemd unit's start condition fails. Only
+'fixpanic agent panic-reset' lifts the stop.
+
+Doesn't wait for the installation lock, so it works while another fixpanic
+operation hangs, and runs in read-only mode too.`,
+	Example:     `  sudo fixpanic agent panic-stop --reason "suspicious sessions, INC-1234"`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{annotationMutating: "true", annotationEmergency: "true"},
+	RunE:        runAgentPanicStop,
+}
+
+// agentPanicResetCmd represents the agent panic-reset command
+var agentPanicResetCmd = &cobra.Command{
+	Use:   "panic-reset",
+	Short: "Lift an emergency stop so the agent may run again",
+	Long: `Remove the emergency stop marker written by 'fixpanic agent panic-stop' and
+enable the systemd service again. The agent isn't started: run
+'fixpanic agent start' afterwards. A running watchdog starts it by itself.`,
+	Example: `  sudo fixpanic agent panic-reset
+  sudo fixpanic agent start`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{annotationMutating: "true", annotationRequires: requireLock},
+	RunE:        runAgentPanicReset,
+}
+
+func init() {
+	agentCmd.AddCommand(agentPanicStopCmd)
+	agentCmd.AddCommand(agentPanicResetCmd)
+
+	// Add flags
+	agentPanicStopCmd.Flags().StringVar(&panicStopReason, "reason", "", "Why the agent is stopped, shown until the stop is lifted")
+	agentPanicResetCmd.Flags().BoolVar(&panicResetForce, "force", false, "Lift the stop without confirmation")
+}
+
+func runAgentPanicStop(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	// The marker comes first so nothing restarts the agent while it's stopped
+	markerPath := platformInfo.GetPanicStopPath()
+	marker := panicstop.Marker{StoppedAt: time.Now().UTC(), User: invokingUserName(), Reason: panicStopReason}
+	if err := panicstop.Engage(markerPath, marker); err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			if err := offerSudo(cmd, "engaging the emergency stop requires root"); err != nil {
+				return err
+			}
+			return clierror.Wrap(clierror.Permission, err)
+		}
+		return clierror.Wrap(clierror.General, err)
+	}
+	logger.Success("Emergency stop engaged: %s", markerPath)
+
+	// No grace period: the agent must lose its connections now
+	pids, err := getAllAgentProcessPIDs()
+	if err != nil {
+		logger.Warning("Failed to find agent processes: %v", err)
+	}
+	for _, pid := range pids {
+		if process, err := os.FindProcess(pid); err != nil || process.Kill() != nil {
+			logger.Warning("Failed to kill agent process %d", pid)
+			continue
+		}
+		logger.Success("Killed agent process %d", pid)
+	}
+
+	if platform.IsSystemdAvailable() {
+		serviceManager := service.NewManager(platformInfo)
+		if err := serviceManager.Stop(ctx); err != nil {
+			logger.Warning("Failed to stop the service: %v", err)
+		}
+		if err := serviceManager.Disable(ctx); err != nil {
+			logger.Warning("Failed to disable the service: %v", err)
+		}
+	} else if controller := nativeService(ctx); controller != nil {
+		if err := controller.StopService(ctx); err != nil {
+			logger.Warning("Failed to stop the service: %v", err)
+		}
+	}
+
+	// Processes may take a moment to be reaped
+	var remaining []int
+	for i := 0; i < 10; i++ {
+		if remaining, err = getAllAgentProcessPIDs(); err != nil || len(remaining) == 0 {
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	if len(remaining) > 0 {
+		return clierror.New(clierror.General, "agent processes are still running: %v", remaining).
+			WithHint("Kill them by hand, e.g. 'sudo kill -9 <pid>'; the emergency stop keeps them from being restarted")
+	}
+
+	logger.Success("The agent is stopped; nothing will start it until the emergency stop is lifted")
+	logger.Info(agentops.HintPanicReset)
+	return nil
+}
+
+func runAgentPanicReset(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	markerPath := platformInfo.GetPanicStopPath()
+	marker, err := panicstop.Read(markerPath)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Info("The emergency stop is not engaged")
+		return nil
+	}
+	logger.Warning("The emergency stop is engaged%s", agentops.DescribePanicStop(marker))
+
+	if !panicResetForce {
+		if !isInteractive() {
+			return clierror.New(clierror.Usage, "lifting the emergency stop needs confirmation").
+				WithHint("Pass --force to lift it without asking")
+		}
+		fmt.Fprintf(os.Stderr, "%s ", i18n.T("Allow the platform to reach this host again? [y/N]:"))
+		response, err := readLine(ctx)
+		if err != nil {
+			return err
+		}
+		if response != "y" && response != "Y" {
+			logger.Info("The emergency stop stays engaged")
+			return nil
+		}
+	}
+
+	if err := panicstop.Release(markerPath); err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return clierror.WithHint(clierror.Wrap(clierror.Permission, err),
+				i18n.Sprintf("Run it again with sudo: sudo %s", quotedCommandLine()))
+		}
+		return clierror.Wrap(clierror.General, err)
+	}
+	logger.Success("Emergency stop lifted")
+
+	if platform.IsSystemdAvailable() {
+		if err := service.NewManager(platformInfo).Enable(ctx); err != nil {
+			logger.Warning("Failed to enable the service: %v", err)
+		}
+	}
+	if readWatchdogStatus(platformInfo) != nil {
+		logger.Info("The watchdog starts the agent again within a few seconds")
+	} else {
+		logger.Info("Run 'fixpanic agent start' to start the agent")
+	}
+	return nil
+}
+
+// invokingUserName returns the user running the CLI, or the user who ran it
+// through sudo
+func invokingUserName() string {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		return sudoUser
+	}
+	if currentUser, err := user.Current(); err == nil {
+		return currentUser.Username
+	}
+	return ""
+}
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/agentctl"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	policyJSON bool
+	policyAt   string
+)
+
+// policyKinds maps the kinds of entries 'policy add' and 'policy remove'
+// take to the list of the policy section they change
+var policyKinds = map[string]func(*config.PolicySection) *[]string{
+	"allow":  func(p *config.PolicySection) *[]string { return &p.AllowCommands },
+	"deny":   func(p *config.PolicySection) *[]string { return &p.DenyCommands },
+	"path":   func(p *config.PolicySection) *[]string { return &p.BlockedPaths },
+	"window": func(p *config.PolicySection) *[]string { return &p.TimeWindows },
+}
+
+// agentPolicyCmd represents the agent policy command group
+var agentPolicyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Limit what the platform may run on this host",
+	Long: `Manage the host owner's policy for the commands the platform runs through the
+agent, stored in the policy section of the agent configuration. The agent
+refuses every command the policy doesn't allow, so the host owner has the
+final say over what is executed:
+
+  allow   patterns of command lines that may run, with * matching any text
+          and ? one character; without any, every command not denied may run
+  deny    patterns of command lines that are refused even if allowed
+  path    files and directories commands may not be pointed at
+  window  times commands are accepted, in the host's time zone, e.g.
+          "Mon-Fri 09:00-17:00" or "Sat,Sun 10:00-12:00"; without any,
+          commands are accepted at any time
+
+The agent reads the policy when it starts: restart it after a change and run
+'fixpanic agent policy verify' to check that the running agent enforces it.`,
+}
+
+// agentPolicyShowCmd represents the agent policy show command
+var agentPolicyShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the configured policy and whether the agent has loaded it",
+	Example: `  fixpanic agent policy show
+  fixpanic agent policy show --json`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{annotationRequires: requireInstalled + "," + requireConfig},
+	RunE:        runAgentPolicyShow,
+}
+
+// agentPolicyAddCmd represents the agent policy add command
+var agentPolicyAddCmd = &cobra.Command{
+	Use:   "add allow|deny|path|window <entry>...",
+	Short: "Add entries to the policy",
+	Example: `  # Only allow read-only diagnostics
+  sudo fixpanic agent policy add allow "systemctl status *" "journalctl *" "df *"
+
+  # Never touch secrets
+  sudo fixpanic agent policy add path /etc/shadow /root/.ssh
+
+  # Only during office hours
+  sudo fixpanic agent policy add window "Mon-Fri 08:00-18:00"
+  sudo fixpanic agent restart`,
+	Args:        cobra.MinimumNArgs(2),
+	ValidArgs:   []string{"allow", "deny", "path", "window"},
+	Annotations: map[string]string{annotationMutating: "true", annotationRequires: requireInstalled + "," + requireConfig + "," + requireLock},
+	RunE:        runAgentPolicyChange,
+}
+
+// agentPolicyRemoveCmd represents the agent policy remove command
+var agentPolicyRemoveCmd = &cobra.Command{
+	Use:         "remove allow|deny|path|window <entry>...",
+	Short:       "Remove entries from the policy",
+	Example:     `  sudo fixpanic agent policy remove window "Mon-Fri 08:00-18:00"`,
+	Args:        cobra.MinimumNArgs(2),
+	ValidArgs:   []string{"allow", "deny", "path", "window"},
+	Annotations: map[string]string{annotationMutating: "true", annotationRequires: requireInstalled + "," + requireConfig + "," + requireLock},
+	RunE:        runAgentPolicyChange,
+}
+
+// agentPolicyClearCmd represents the agent policy clear command
+var agentPolicyClearCmd = &cobra.Command{
+	Use:         "clear",
+	Short:       "Remove the policy, allowing every command again",
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{annotationMutating: "true", annotationRequires: requireInstalled + "," + requireConfig + "," + requireLock},
+	RunE:        runAgentPolicyClear,
+}
+
+// agentPolicyVerifyCmd represents the agent policy verify command
+var agentPolicyVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that the running agent enforces the configured policy",
+	Long: `Ask the running agent for the policy it has loaded and compare it with the
+configured one. Fails if the agent isn't running, predates policies, or still
+enforces an older policy.`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{annotationRequires: requireInstalled + "," + requireConfig},
+	RunE:        runAgentPolicyVerify,
+}
+
+// agentPolicyTestCmd represents the agent policy test command
+var agentPolicyTestCmd = &cobra.Command{
+	Use:   "test <command>...",
+	Short: "Check whether the configured policy allows a command",
+	Example: `  # Would the agent run this now?
+  fixpanic agent policy test -- cat /etc/shadow
+
+  # And on Saturday morning?
+  fixpanic agent policy test --at "2026-10-17 09:00" -- systemctl restart nginx`,
+	Args:        cobra.MinimumNArgs(1),
+	Annotations: map[string]string{annotationRequires: requireInstalled + "," + requireConfig},
+	RunE:        runAgentPolicyTest,
+}
+
+func init() {
+	agentCmd.AddCommand(agentPolicyCmd)
+	agentPolicyCmd.AddCommand(agentPolicyShowCmd)
+	agentPolicyCmd.AddCommand(agentPolicyAddCmd)
+	agentPolicyCmd.AddCommand(agentPolicyRemoveCmd)
+	agentPolicyCmd.AddCommand(agentPolicyClearCmd)
+	agentPolicyCmd.AddCommand(agentPolicyVerifyCmd)
+	agentPolicyCmd.AddCommand(agentPolicyTestCmd)
+
+	// Add flags
+	agentPolicyShowCmd.Flags().BoolVar(&policyJSON, "json", false, "Output the policy as JSON")
+	agentPolicyTestCmd.Flags().StringVar(&policyAt, "at", "", "Check at this local time (YYYY-MM-DD HH:MM) instead of now")
+}
+
+func runAgentPolicyShow(cmd *cobra.Command, args []string) error {
+	policy := commandConfig().Policy
+	loaded, loadedErr := policyLoaded(cmd.Context(), &policy)
+
+	if policyJSON {
+		output := struct {
+			Policy config.PolicySection `json:"policy"`
+			// Loaded is null when the agent can't be asked
+			Loaded *bool `json:"loaded"`
+		}{Policy: policy}
+		if loadedErr == nil {
+			output.Loaded = &loaded
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(output)
+	}
+
+	if policy.IsEmpty() {
+		logger.Info("No policy is set: the platform may run any command")
+	} else {
+		showPolicyList("Allowed commands", policy.AllowCommands, "any not denied")
+		showPolicyList("Denied commands", policy.DenyCommands, "none")
+		showPolicyList("Blocked paths", policy.BlockedPaths, "none")
+		showPolicyList("Time windows", policy.TimeWindows, "any time")
+	}
+
+	switch {
+	case loadedErr != nil:
+		logger.Warning("Can't tell whether the agent has loaded the policy: %v", loadedErr)
+	case loaded:
+		logger.Success("The running agent enforces this policy")
+	default:
+		logger.Warning("The running agent enforces a different policy")
+		logger.Info("Run 'fixpanic agent restart' for the agent to load it")
+	}
+	return nil
+}
+
+// showPolicyList prints one list of the policy, or what its absence means
+func showPolicyList(label string, entries []string, absent string) {
+	if len(entries) == 0 {
+		logger.KeyValue(label, i18n.T(absent))
+		return
+	}
+	logger.KeyValue(label, "")
+	for _, entry := range entries {
+		logger.List("%s", entry)
+	}
+}
+
+func runAgentPolicyChange(cmd *cobra.Command, args []string) error {
+	kind, entries := args[0], args[1:]
+	list, ok := policyKinds[kind]
+	if !ok {
+		return clierror.New(clierror.Usage, "unknown policy entry kind %q: use allow, deny, path or window", kind)
+	}
+
+	agentConfig := commandConfig()
+	target := list(&agentConfig.Policy)
+	adding := cmd.Name() == "add"
+	var changes []string
+	for _, entry := range entries {
+		index := slices.Index(*target, entry)
+		switch {
+		case adding && index < 0:
+			*target = append(*target, entry)
+			changes = append(changes, i18n.Sprintf("Added %s entry %q", kind, entry))
+		case adding:
+			logger.Info("The policy already has the %s entry %q", kind, entry)
+		case index >= 0:
+			*target = slices.Delete(*target, index, index+1)
+			changes = append(changes, i18n.Sprintf("Removed %s entry %q", kind, entry))
+		default:
+			logger.Warning("The policy has no %s entry %q", kind, entry)
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := agentConfig.Policy.Validate(); err != nil {
+		return clierror.New(clierror.Usage, "invalid policy: %w", err)
+	}
+	for _, change := range changes {
+		logger.Success("%s", change)
+	}
+	return savePolicy(agentConfig)
+}
+
+func runAgentPolicyClear(cmd *cobra.Command, args []string) error {
+	agentConfig := commandConfig()
+	if agentConfig.Policy.IsEmpty() {
+		logger.Info("No policy is set")
+		return nil
+	}
+	agentConfig.Policy = config.PolicySection{}
+	logger.Success("Removed the policy; the platform may run any command")
+	return savePolicy(agentConfig)
+}
+
+// savePolicy writes the configuration with its changed policy
+func savePolicy(agentConfig *config.AgentConfig) error {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+	if err := config.SaveConfig(agentConfig, platformInfo.GetConfigPath()); err != nil {
+		return clierror.Wrap(clierror.General, err)
+	}
+	logger.Info("Run 'fixpanic agent restart' for the agent to load the policy, then 'fixpanic agent policy verify'")
+	return nil
+}
+
+func runAgentPolicyVerify(cmd *cobra.Command, args []string) error {
+	policy := commandConfig().Policy
+	loaded, err := policyLoaded(cmd.Context(), &policy)
+	if err != nil {
+		switch {
+		case errors.Is(err, agentctl.ErrNotListening):
+			return clierror.New(clierror.General, "the agent is not running").
+				WithHint("Start it with 'fixpanic agent start'")
+		case errors.Is(err, agentctl.ErrUnsupported):
+			return clierror.New(clierror.General, "the agent does not support command policies").
+				WithHint("Upgrade the agent with 'fixpanic agent upgrade' to enforce the policy")
+		}
+		return sessionsError(err)
+	}
+	if !loaded {
+		return clierror.New(clierror.Config, "the running agent enforces a different policy than the configured one").
+			WithHint("Run 'fixpanic agent restart' for the agent to load it")
+	}
+	logger.Success("The running agent enforces the configured policy")
+	return nil
+}
+
+func runAgentPolicyTest(cmd *cobra.Command, args []string) error {
+	at := time.Now()
+	if policyAt != "" {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", policyAt, time.Local)
+		if err != nil {
+			return clierror.New(clierror.Usage, "invalid --at %q: use YYYY-MM-DD HH:MM", policyAt)
+		}
+		at = parsed
+	}
+
+	command := strings.Join(args, " ")
+	policy := commandConfig().Policy
+	if err := policy.Check(command, at); err != nil {
+		return clierror.New(clierror.General, "the policy refuses %q: %w", command, err)
+	}
+	logger.Success("The policy allows %q", command)
+	return nil
+}
+
+// policyLoaded reports whether the running agent has loaded policy. It fails
+// when that can't be told, e.g. because the agent isn't running.
+func policyLoaded(ctx context.Context, policy *config.PolicySection) (bool, error) {
+	client, err := sessionsClient()
+	if err != nil {
+		return false, err
+	}
+	loaded, err := client.Policy(ctx)
+	if err != nil {
+		return false, err
+	}
+	return slices.Equal(loaded.AllowCommands, policy.AllowCommands) &&
+		slices.Equal(loaded.DenyCommands, policy.DenyCommands) &&
+		slices.Equal(loaded.BlockedPaths, policy.BlockedPaths) &&
+		slices.Equal(loaded.TimeWindows, policy.TimeWindows), nil
+}
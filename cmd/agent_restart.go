@@ -1,10 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/fixpanic/fixpanic-cli/internal/hooks"
 	"github.com/fixpanic/fixpanic-cli/internal/logger"
-	"github.com/fixpanic/fixpanic-cli/internal/platform"
 	"github.com/fixpanic/fixpanic-cli/internal/process"
 	"github.com/spf13/cobra"
 )
@@ -16,23 +17,39 @@ var agentRestartCmd = &cobra.Command{
 	Long: `Restart the Fixpanic agent service.
 
 This command stops the agent if it's running and then starts it again.
-It's equivalent to running 'fixpanic agent stop' followed by 'fixpanic agent start'.`,
+It's equivalent to running 'fixpanic agent stop' followed by 'fixpanic agent start'.
+
+With --drain the agent first stops accepting new remote-debug sessions and
+waits up to --drain-timeout for the active ones to end; if they don't, it
+isn't restarted.`,
 	Example: `  # Restart the agent
-  fixpanic agent restart`,
-	RunE: runAgentRestart,
+  fixpanic agent restart
+
+  # Restart once the active debugging sessions ended
+  fixpanic agent restart --drain`,
+	Annotations: map[string]string{annotationMutating: "true", annotationRequires: requireLock},
+	RunE:        runAgentRestart,
 }
 
 func init() {
 	agentCmd.AddCommand(agentRestartCmd)
+
+	// Add flags
+	addDrainFlags(agentRestartCmd)
 }
 
 func runAgentRestart(cmd *cobra.Command, args []string) error {
 	logger.Header("Restarting FixPanic Agent")
+	ctx := cmd.Context()
 
 	// Stop the agent first
 	logger.Step(1, "Stopping agent")
-	if err := stopAgent(); err != nil {
+	if err := drainAgent(ctx); err != nil {
+		return err
+	}
+	if err := runWithHooks(ctx, hooks.OperationStop, func() error { return stopAgent(ctx) }); err != nil {
 		// If stop fails, continue with start (agent might not be running)
+		resumeAgent()
 		logger.Warning("Stop failed: %v", err)
 		logger.Info("Continuing with start...")
 	} else {
@@ -44,7 +61,7 @@ func runAgentRestart(cmd *cobra.Command, args []string) error {
 
 	// Start the agent
 	logger.Step(2, "Starting agent")
-	if err := startAgent(); err != nil {
+	if err := runWithHooks(ctx, hooks.OperationStart, func() error { return startAgent(ctx) }); err != nil {
 		return fmt.Errorf("failed to start agent: %w", err)
 	}
 
@@ -55,7 +72,12 @@ func runAgentRestart(cmd *cobra.Command, args []string) error {
 }
 
 // stopAgent stops all running agent processes
-func stopAgent() error {
+func stopAgent(ctx context.Context) error {
+	// Stop the watchdog first so it doesn't restart the agent
+	if err := stopWatchdog(ctx); err != nil {
+		logger.Warning("%v", err)
+	}
+
 	// Get all running agent processes
 	pids, err := getAllAgentProcessPIDs()
 	if err != nil {
@@ -82,6 +104,7 @@ func stopAgent() error {
 	}
 
 	if stoppedCount == 0 {
+		resumeAgent()
 		return fmt.Errorf("failed to stop any agent processes")
 	}
 
@@ -94,11 +117,11 @@ func stopAgent() error {
 }
 
 // startAgent starts the agent
-func startAgent() error {
+func startAgent(ctx context.Context) error {
 	// Get platform information
-	platformInfo, err := platform.GetPlatformInfo()
+	platformInfo, err := commandPlatform()
 	if err != nil {
-		return fmt.Errorf("failed to get platform info: %w", err)
+		return err
 	}
 
 	// Validate agent installation
@@ -113,5 +136,5 @@ func startAgent() error {
 	}
 
 	// Start the agent service
-	return startAgentService(platformInfo, connectivityManager)
+	return startAgentService(ctx, platformInfo, connectivityManager)
 }
\ No newline at end of file
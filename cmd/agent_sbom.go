@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/releases"
+	"github.com/fixpanic/fixpanic-cli/internal/sbom"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sbomFormat    string
+	sbomComponent string
+	sbomRaw       bool
+)
+
+// Components 'agent sbom' reports on
+const (
+	sbomComponentAgent = "agent"
+	sbomComponentCLI   = "cli"
+	sbomComponentAll   = "all"
+)
+
+// agentSBOMCmd represents the agent sbom command
+var agentSBOMCmd = &cobra.Command{
+	Use:   "sbom",
+	Short: "Show the software bill of materials of the agent and the CLI",
+	Long: `Show the software bill of materials (SBOM) of the installed components, for
+security reviews and vendor questionnaires:
+
+  agent  the SPDX or CycloneDX SBOM published with the installed agent
+         release, downloaded and checked against the release's signed
+         checksum manifest before it is shown
+  cli    the SBOM of this CLI, generated from the module information the Go
+         toolchain embeds in the binary
+
+Pass --raw to print a component's SBOM document as is, e.g. to hand it to a
+scanner or attach it to a questionnaire.`,
+	Example: `  # Summarize the SBOMs of the agent and the CLI
+  fixpanic agent sbom
+
+  # Save the agent's CycloneDX SBOM
+  fixpanic agent sbom --component agent --format cyclonedx --raw > agent.cdx.json`,
+	Args: cobra.NoArgs,
+	RunE: runAgentSBOM,
+}
+
+func init() {
+	agentCmd.AddCommand(agentSBOMCmd)
+
+	// Add flags
+	agentSBOMCmd.Flags().StringVar(&sbomFormat, "format", sbom.FormatSPDX, "SBOM format: spdx or cyclonedx")
+	agentSBOMCmd.Flags().StringVar(&sbomComponent, "component", sbomComponentAll, "Component to show: agent, cli or all")
+	agentSBOMCmd.Flags().BoolVar(&sbomRaw, "raw", false, "Print the SBOM document as is (requires --component agent or cli)")
+}
+
+// componentSBOM is the SBOM of one component and where it came from
+type componentSBOM struct {
+	component  string
+	source     string
+	provenance string
+	data       []byte
+}
+
+func runAgentSBOM(cmd *cobra.Command, args []string) error {
+	if _, err := sbom.AssetName(sbomFormat); err != nil {
+		return clierror.Wrap(clierror.Usage, err)
+	}
+	switch sbomComponent {
+	case sbomComponentAgent, sbomComponentCLI, sbomComponentAll:
+	default:
+		return clierror.New(clierror.Usage, "unknown component %q: use agent, cli or all", sbomComponent)
+	}
+	if sbomRaw && sbomComponent == sbomComponentAll {
+		return clierror.New(clierror.Usage, "--raw prints one document").
+			WithHint("Pass --component agent or --component cli")
+	}
+
+	var sboms []componentSBOM
+	if sbomComponent != sbomComponentCLI {
+		agentSBOM, err := fetchAgentSBOM(cmd)
+		switch {
+		case err == nil:
+			sboms = append(sboms, *agentSBOM)
+		case sbomComponent == sbomComponentAll && clierror.CodeOf(err) == clierror.NotInstalled:
+			logger.Warning("FixPanic Agent is not installed, showing the CLI only")
+		default:
+			return err
+		}
+	}
+	if sbomComponent != sbomComponentAgent {
+		cliSBOM, err := generateCLISBOM()
+		if err != nil {
+			return err
+		}
+		sboms = append(sboms, *cliSBOM)
+	}
+
+	if sbomRaw {
+		_, err := os.Stdout.Write(append(sboms[0].data, '\n'))
+		return err
+	}
+	logger.Header("Software Bill of Materials")
+	for i, component := range sboms {
+		if i > 0 {
+			logger.Plain("")
+		}
+		if err := showComponentSBOM(component); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchAgentSBOM downloads the SBOM published with the installed agent
+// release
+func fetchAgentSBOM(cmd *cobra.Command) (*componentSBOM, error) {
+	ctx := cmd.Context()
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkInstalled(platformInfo); err != nil {
+		return nil, err
+	}
+	connectivityManager := connectivity.NewManager(platformInfo)
+	output, err := connectivityManager.GetFixPanicAgentVersion(ctx)
+	if err != nil {
+		return nil, clierror.New(clierror.General, "failed to get agent version: %w", err)
+	}
+	tag, err := releases.ParseTag(connectivity.ParseAgentVersion(output))
+	if err != nil {
+		return nil, clierror.New(clierror.General, "the installed agent is not a release build: %w", err).
+			WithHint("Install a released agent with 'fixpanic agent upgrade'")
+	}
+
+	logger.Loading("Downloading the SBOM of agent %s...", tag)
+	data, err := connectivityManager.SBOM(ctx, tag, sbomFormat)
+	if err != nil {
+		logger.LoadingFailed("Could not download the SBOM of agent %s", tag)
+		if errors.Is(err, releases.ErrNotListed) {
+			return nil, clierror.New(clierror.General, "agent %s publishes no %s SBOM: %w", tag, sbomFormat, err).
+				WithHint("Try the other format with --format, or upgrade the agent with 'fixpanic agent upgrade'")
+		}
+		return nil, clierror.WithHint(clierror.Classify(clierror.Network, err),
+			"Run 'fixpanic network check' to diagnose connectivity")
+	}
+	logger.LoadingDone("Checksum verified against the signed release manifest")
+	name, _ := sbom.AssetName(sbomFormat)
+	return &componentSBOM{
+		component:  "FixPanic Agent " + tag,
+		source:     releases.DownloadURL(releases.AgentRepo, tag, name),
+		provenance: "signed release manifest",
+		data:       data,
+	}, nil
+}
+
+// generateCLISBOM generates the SBOM of this CLI from its build information
+func generateCLISBOM() (*componentSBOM, error) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil, clierror.New(clierror.General, "this binary carries no build information")
+	}
+	created := time.Now()
+	if builtAt, err := time.Parse(time.RFC3339, date); err == nil {
+		created = builtAt
+	}
+	data, err := sbom.Generate(sbomFormat, getCurrentVersion(), info, created)
+	if err != nil {
+		return nil, clierror.Wrap(clierror.General, err)
+	}
+	return &componentSBOM{
+		component:  "FixPanic CLI " + getCurrentVersion(),
+		source:     "embedded in the binary",
+		provenance: "build information of the Go toolchain",
+		data:       data,
+	}, nil
+}
+
+// showComponentSBOM prints a summary of component's SBOM and its packages
+func showComponentSBOM(component componentSBOM) error {
+	document, err := sbom.Parse(component.data)
+	if err != nil {
+		return clierror.Wrap(clierror.General, err)
+	}
+	logger.KeyValue("Component", component.component)
+	logger.KeyValue("Format", document.Format)
+	logger.KeyValue("Source", i18n.T(component.source))
+	logger.KeyValue("Provenance", i18n.T(component.provenance))
+	if !document.Created.IsZero() {
+		logger.KeyValue("Created", document.Created.Local().Format("2006-01-02 15:04:05"))
+	}
+	if len(document.Creators) > 0 {
+		logger.KeyValue("Created by", strings.Join(document.Creators, ", "))
+	}
+	logger.KeyValue("Packages", fmt.Sprint(len(document.Packages)))
+	logger.Plain("")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tLICENSE")
+	for _, pkg := range document.Packages {
+		version, license := pkg.Version, pkg.License
+		if version == "" {
+			version = "-"
+		}
+		if license == "" {
+			license = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", pkg.Name, version, license)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/agentctl"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sessionsJSON    bool
+	sessionsKillAll bool
+)
+
+// agentSessionsCmd represents the agent sessions command group
+var agentSessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "List and terminate remote-debug sessions on this host",
+	Long: `Inspect the remote-debug sessions the running agent is serving: who opened
+them on the dashboard, from where and since when.
+
+'kill' terminates a session on this host, together with any command it is
+running, without going through the dashboard: a local kill switch for an
+operator who doesn't want a session to continue.
+
+The agent is asked over its control socket in the state directory, which
+only users with access to the agent's files can use; run the commands with
+sudo for an agent installed as root.`,
+}
+
+// agentSessionsListCmd represents the agent sessions list command
+var agentSessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List active remote-debug sessions",
+	Example: `  # Show who is connected to this host
+  sudo fixpanic agent sessions list
+
+  # As JSON for scripts
+  sudo fixpanic agent sessions list --json`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{annotationRequires: requireInstalled},
+	RunE:        runAgentSessionsList,
+}
+
+// agentSessionsKillCmd represents the agent sessions kill command
+var agentSessionsKillCmd = &cobra.Command{
+	Use:   "kill <id>...",
+	Short: "Terminate remote-debug sessions",
+	Example: `  # Terminate one session
+  sudo fixpanic agent sessions kill 7f3a9c
+
+  # Terminate every session
+  sudo fixpanic agent sessions kill --all`,
+	Annotations: map[string]string{annotationMutating: "true", annotationRequires: requireInstalled},
+	RunE:        runAgentSessionsKill,
+}
+
+func init() {
+	agentCmd.AddCommand(agentSessionsCmd)
+	agentSessionsCmd.AddCommand(agentSessionsListCmd)
+	agentSessionsCmd.AddCommand(agentSessionsKillCmd)
+
+	// Add flags
+	agentSessionsListCmd.Flags().BoolVar(&sessionsJSON, "json", false, "Output sessions as JSON")
+	agentSessionsKillCmd.Flags().BoolVar(&sessionsKillAll, "all", false, "Terminate every active session")
+}
+
+func runAgentSessionsList(cmd *cobra.Command, args []string) error {
+	client, err := sessionsClient()
+	if err != nil {
+		return err
+	}
+	sessions, err := client.Sessions(cmd.Context())
+	if err != nil {
+		return sessionsError(err)
+	}
+
+	if sessionsJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if sessions == nil {
+			sessions = []agentctl.Session{}
+		}
+		return encoder.Encode(sessions)
+	}
+
+	if len(sessions) == 0 {
+		logger.Info("No active sessions")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSER\tSOURCE\tSINCE\tDURATION\tCOMMAND")
+	for _, session := range sessions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", session.ID, session.User, session.Source,
+			session.Started.Local().Format("2006-01-02 15:04:05"), time.Since(session.Started).Round(time.Second), session.Command)
+	}
+	return w.Flush()
+}
+
+func runAgentSessionsKill(cmd *cobra.Command, args []string) error {
+	switch {
+	case sessionsKillAll && len(args) > 0:
+		return clierror.New(clierror.Usage, "--all can't be combined with session IDs")
+	case !sessionsKillAll && len(args) == 0:
+		return clierror.New(clierror.Usage, "no session given").
+			WithHint("Pass the IDs shown by 'fixpanic agent sessions list', or --all")
+	}
+
+	client, err := sessionsClient()
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	ids := args
+	if sessionsKillAll {
+		sessions, err := client.Sessions(ctx)
+		if err != nil {
+			return sessionsError(err)
+		}
+		if len(sessions) == 0 {
+			logger.Info("No active sessions")
+			return nil
+		}
+		for _, session := range sessions {
+			ids = append(ids, session.ID)
+		}
+	}
+
+	// Kill every session that can be killed; the first failure is returned,
+	// later ones are only reported
+	var failed error
+	for _, id := range ids {
+		err := killSession(ctx, client, id)
+		switch {
+		case err == nil:
+		case failed == nil:
+			failed = err
+		default:
+			logger.Error("%v", err)
+		}
+	}
+	return failed
+}
+
+// killSession terminates one session and reports it
+func killSession(ctx context.Context, client *agentctl.Client, id string) error {
+	if err := client.KillSession(ctx, id); err != nil {
+		if errors.Is(err, agentctl.ErrSessionNotFound) {
+			return clierror.New(clierror.Usage, "%w", err).
+				WithHint("Run 'fixpanic agent sessions list' to see the active sessions")
+		}
+		return sessionsError(err)
+	}
+	logger.Success("Terminated session %s", id)
+	return nil
+}
+
+// sessionsClient returns a client for the running agent's control socket
+func sessionsClient() (*agentctl.Client, error) {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return nil, err
+	}
+	return agentctl.NewClient(platformInfo.GetControlSocketPath()), nil
+}
+
+// sessionsError classifies a failed request to the agent's control socket
+func sessionsError(err error) error {
+	switch {
+	case errors.Is(err, agentctl.ErrNotListening):
+		return clierror.New(clierror.General, "the agent is not running").
+			WithHint("Start it with 'fixpanic agent start'")
+	case errors.Is(err, agentctl.ErrUnsupported):
+		return clierror.New(clierror.General, "%w", err).
+			WithHint("Upgrade the agent with 'fixpanic agent upgrade' to manage sessions")
+	case errors.Is(err, os.ErrPermission):
+		return clierror.New(clierror.Permission, "%w", err).
+			WithHint(i18n.Sprintf("Run it again with sudo: sudo %s", quotedCommandLine()))
+	}
+	return clierror.Wrap(clierror.General, err)
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/controlplane"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// smokeTestPoll is how often smoke-test asks for the state of its command
+const smokeTestPoll = time.Second
+
+var smokeTestWait time.Duration
+
+// agentSmokeTestCmd represents the agent smoke-test command
+var agentSmokeTestCmd = &cobra.Command{
+	Use:   "smoke-test",
+	Short: "Run a command on this host through the control plane to test the whole pipeline",
+	Long: `Prove that the agent works end to end: a trivial 'echo' of a random token
+is requested from the control-plane API with the agent's credentials, sent to
+the agent through the socket server, run on this host, and its output is
+compared with the token on the way back.
+
+test-connection and status show that the agent can reach the socket server;
+smoke-test shows that the dashboard can actually run commands through it.
+Run it after installing or changing the network setup. The control-plane API
+is https://api.fixpanic.com unless $FIXPANIC_API_URL is set.`,
+	Example: `  # Test the round trip after installing
+  fixpanic agent smoke-test
+
+  # Give a slow network more time
+  fixpanic agent smoke-test --wait 2m`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{annotationRequires: requireInstalled + "," + requireConfig},
+	RunE:        runAgentSmokeTest,
+}
+
+func init() {
+	agentCmd.AddCommand(agentSmokeTestCmd)
+
+	// Add flags
+	agentSmokeTestCmd.Flags().DurationVar(&smokeTestWait, "wait", 30*time.Second, "How long to wait for the agent to run the command")
+}
+
+func runAgentSmokeTest(cmd *cobra.Command, args []string) error {
+	logger.Header("Agent Smoke Test")
+	ctx := cmd.Context()
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+	agentConfig := commandConfig()
+
+	logger.Step(1, "Checking the agent is running")
+	if running, _ := detectAgentRunning(ctx, platformInfo); !running {
+		return clierror.New(clierror.General, "the agent is not running").
+			WithHint("Start it with 'fixpanic agent start'")
+	}
+	logger.Success("Agent is running")
+
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return err
+	}
+	token := "fixpanic-smoke-test-" + hex.EncodeToString(random)
+
+	client := controlplane.NewClient(agentConfig.App.AgentID, agentConfig.App.APIKey)
+	logger.Step(2, "Requesting 'echo' through %s", client.Endpoint())
+	started := time.Now()
+	command, err := client.RunCommand(ctx, "echo", []string{token})
+	if err != nil {
+		return smokeTestAPIError(err, client.Endpoint())
+	}
+
+	logger.Step(3, "Waiting up to %s for the agent to run it", smokeTestWait)
+	deadline := time.NewTimer(smokeTestWait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(smokeTestPoll)
+	defer ticker.Stop()
+	for !command.Done() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			return clierror.New(clierror.Timeout, "the agent did not run the command within %s (last status: %s)", smokeTestWait, command.Status).
+				WithHint("Run 'fixpanic agent status' to check the agent is connected",
+					"Run 'fixpanic agent logs' to see whether the command reached the agent")
+		case <-ticker.C:
+		}
+		if command, err = client.Command(ctx, command.ID); err != nil {
+			return smokeTestAPIError(err, client.Endpoint())
+		}
+	}
+	roundTrip := time.Since(started)
+
+	logger.Step(4, "Verifying the output")
+	if command.Status == controlplane.StatusFailed {
+		return clierror.New(clierror.General, "the command failed: %s", command.Error).
+			WithHint("Run 'fixpanic agent logs' to see what the agent reported")
+	}
+	if command.ExitCode != 0 {
+		return clierror.New(clierror.General, "'echo' exited with status %d on this host", command.ExitCode).
+			WithHint("Run 'fixpanic agent doctor' to check the agent's confinement isn't blocking it")
+	}
+	if output := strings.TrimSpace(command.Output); output != token {
+		return clierror.New(clierror.General, "the command returned %q instead of %q", output, token).
+			WithHint("Run the smoke test again; if it keeps failing contact support@fixpanic.com")
+	}
+
+	logger.Success("Commands run through the control plane, socket server and agent (round trip %s)", roundTrip.Round(time.Millisecond))
+	return nil
+}
+
+// smokeTestAPIError classifies a failed request to the control-plane API at
+// endpoint
+func smokeTestAPIError(err error, endpoint string) error {
+	if errors.Is(err, controlplane.ErrUnauthorized) {
+		return clierror.New(clierror.Config, "%w", err).
+			WithHint("Check the agent ID and API key on the Fixpanic dashboard", hintReinstallAgent)
+	}
+	return clierror.New(clierror.Network, "%w", err).
+		WithHint(i18n.Sprintf("Check that %s is reachable from this host", endpoint),
+			"Run 'fixpanic network check' to diagnose connectivity")
+}
@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
-	"strconv"
-	"strings"
+	"os"
 
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
 	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/hooks"
 	"github.com/fixpanic/fixpanic-cli/internal/logger"
 	"github.com/fixpanic/fixpanic-cli/internal/platform"
 	"github.com/fixpanic/fixpanic-cli/internal/process"
@@ -24,7 +26,10 @@ This command starts the agent service using systemd if available, or runs the
 connectivity layer binary directly if systemd is not available.`,
 	Example: `  # Start the agent
   fixpanic agent start`,
-	RunE: runAgentStart,
+	Annotations: map[string]string{annotationMutating: "true", annotationRequires: requireLock},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWithHooks(cmd.Context(), hooks.OperationStart, func() error { return runAgentStart(cmd, args) })
+	},
 }
 
 func init() {
@@ -35,9 +40,9 @@ func runAgentStart(cmd *cobra.Command, args []string) error {
 	logger.Header("Starting FixPanic Agent")
 
 	// Get platform information
-	platformInfo, err := platform.GetPlatformInfo()
+	platformInfo, err := commandPlatform()
 	if err != nil {
-		return fmt.Errorf("failed to get platform info: %w", err)
+		return err
 	}
 
 	// Validate agent installation
@@ -53,7 +58,7 @@ func runAgentStart(cmd *cobra.Command, args []string) error {
 	}
 
 	// Start the agent service
-	return startAgentService(platformInfo, connectivityManager)
+	return startAgentService(cmd.Context(), platformInfo, connectivityManager)
 }
 
 // validateAgentInstall checks if the agent is installed
@@ -62,7 +67,8 @@ func validateAgentInstall(platformInfo *platform.PlatformInfo) (*connectivity.Ma
 	connectivityManager := connectivity.NewManager(platformInfo)
 
 	if !connectivityManager.IsFixPanicAgentInstalled() {
-		return nil, fmt.Errorf("FixPanic Agent not installed. Run 'fixpanic agent install' first")
+		return nil, clierror.New(clierror.NotInstalled, "FixPanic Agent not installed").
+			WithHint(hintInstallAgent)
 	}
 
 	logger.Success("Agent installation verified")
@@ -77,18 +83,18 @@ func cleanUpOldAgents() error {
 	}
 
 	if len(existingPIDs) > 0 {
-		fmt.Printf("⚠️  Found %d existing agent process(es) running:\n", len(existingPIDs))
+		fmt.Fprintf(os.Stderr, "⚠️  Found %d existing agent process(es) running:\n", len(existingPIDs))
 		for _, pid := range existingPIDs {
-			fmt.Printf("   - PID: %d\n", pid)
+			fmt.Fprintf(os.Stderr, "   - PID: %d\n", pid)
 		}
-		fmt.Println("🛑 Stopping existing processes before starting new agent...")
+		fmt.Fprintln(os.Stderr, "🛑 Stopping existing processes before starting new agent...")
 
 		// Stop all existing processes
 		procManager := process.NewProcessManager()
 		stoppedCount := 0
 		for _, pid := range existingPIDs {
 			if err := procManager.StopProcess(pid); err != nil {
-				fmt.Printf("⚠️  Warning: failed to stop process %d: %v\n", pid, err)
+				fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to stop process %d: %v\n", pid, err)
 			} else {
 				stoppedCount++
 			}
@@ -98,15 +104,18 @@ func cleanUpOldAgents() error {
 			return fmt.Errorf("failed to stop any existing agent processes")
 		}
 
-		fmt.Printf("✅ Stopped %d existing process(es)\n", stoppedCount)
-		fmt.Println() // Empty line for better readability
+		fmt.Fprintf(os.Stderr, "✅ Stopped %d existing process(es)\n", stoppedCount)
+		fmt.Fprintln(os.Stderr) // Empty line for better readability
 	}
 
 	return nil
 }
 
 // startAgentService starts the agent using systemd if available, or directly if not
-func startAgentService(platformInfo *platform.PlatformInfo, connectivityManager *connectivity.Manager) error {
+func startAgentService(ctx context.Context, platformInfo *platform.PlatformInfo, connectivityManager *connectivity.Manager) error {
+	if err := agentops.CheckPanicStop(platformInfo); err != nil {
+		return err
+	}
 	binaryPath := platformInfo.GetFixPanicAgentBinaryPath()
 
 	// Try to use systemd service if available
@@ -116,87 +125,102 @@ func startAgentService(platformInfo *platform.PlatformInfo, connectivityManager
 
 		// Check current status
 		logger.Progress("Checking service status")
-		status, err := serviceManager.Status()
+		status, err := serviceManager.Status(ctx)
 		if err != nil {
-			fmt.Printf("Warning: could not check service status: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Warning: could not check service status: %v\n", err)
 		} else if status == "active" {
-			fmt.Println("✅ Agent service is already running")
+			fmt.Fprintln(os.Stderr, "✅ Agent service is already running")
 			return nil
 		}
 
 		// Start the service
-		if err := serviceManager.Start(); err != nil {
-			return fmt.Errorf("failed to start service: %w", err)
+		if err := serviceManager.Start(ctx); err != nil {
+			return clierror.WithHint(fmt.Errorf("failed to start service: %w", err),
+				"Run 'fixpanic agent logs' to see why the agent failed to start",
+				"Run 'fixpanic agent doctor' to diagnose common problems")
 		}
 
-		fmt.Println("✅ Agent service started successfully")
-		fmt.Printf("Service: %s\n", platform.GetSystemdServiceName())
+		fmt.Fprintln(os.Stderr, "✅ Agent service started successfully")
+		fmt.Fprintf(os.Stderr, "Service: %s\n", platform.GetSystemdServiceName())
 
 		// Show how to check status
-		fmt.Println("\nYou can check the status with:")
-		fmt.Printf("  sudo systemctl status %s\n", platform.GetSystemdServiceName())
+		fmt.Fprintln(os.Stderr, "\nYou can check the status with:")
+		fmt.Fprintf(os.Stderr, "  sudo systemctl status %s\n", platform.GetSystemdServiceName())
+
+		return nil
+	}
+
+	// Use the Windows service if the agent is registered with the Service Control Manager
+	if controller := nativeService(ctx); controller != nil {
+		status, err := controller.GetServiceStatus(ctx)
+		if err == nil && status == "running" {
+			fmt.Fprintln(os.Stderr, "✅ Agent service is already running")
+			return nil
+		}
 
+		if err := controller.StartService(ctx); err != nil {
+			return clierror.WithHint(fmt.Errorf("failed to start service: %w", err),
+				"Run 'fixpanic agent logs' to see why the agent failed to start",
+				"Run 'fixpanic agent doctor' to diagnose common problems")
+		}
+
+		fmt.Fprintln(os.Stderr, "✅ Agent service started successfully")
+		fmt.Fprintf(os.Stderr, "Service: %s\n", platform.GetWindowsServiceName())
 		return nil
 	}
 
 	// Use cross-platform process manager for direct process execution
-	configPath := platformInfo.GetConfigPath()
+	fmt.Fprintf(os.Stderr, "Starting: %s --config %s\n", binaryPath, platformInfo.GetConfigPath())
+	pid, err := startAgentProcess(platformInfo)
+	if err != nil {
+		return err
+	}
 
-	fmt.Printf("Starting: %s --config %s\n", binaryPath, configPath)
+	fmt.Fprintln(os.Stderr, "✅ Agent started successfully in background")
+	fmt.Printf("Process PID: %d\n", pid)
 
-	// Create process manager for the current platform
-	procManager := process.NewProcessManager()
+	return nil
+}
 
-	// Start the agent process
+// startAgentProcess runs the agent binary in the background, detached from
+// the CLI, and returns its PID
+func startAgentProcess(platformInfo *platform.PlatformInfo) (int, error) {
+	procManager := process.NewProcessManager()
 	procInfo, err := procManager.StartProcess(process.ProcessConfig{
-		BinaryPath: binaryPath,
-		Args:       []string{"--config", configPath},
+		BinaryPath: platformInfo.GetFixPanicAgentBinaryPath(),
+		Args:       []string{"--config", platformInfo.GetConfigPath()},
 		Detach:     true,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to start agent: %w", err)
+		return 0, fmt.Errorf("failed to start agent: %w", err)
 	}
+	return procInfo.PID, nil
+}
 
-	fmt.Println("✅ Agent started successfully in background")
-	fmt.Printf("Process PID: %d\n", procInfo.PID)
-
-	return nil
+// nativeService returns the agent's service if it is registered with the
+// operating system's native service manager (Windows), or nil otherwise
+func nativeService(ctx context.Context) process.ServiceController {
+	controller, ok := process.NewServiceController(platform.GetWindowsServiceName())
+	if !ok {
+		return nil
+	}
+	// Querying fails when the service isn't registered
+	if _, err := controller.GetServiceStatus(ctx); err != nil {
+		return nil
+	}
+	return controller
 }
 
 // getAllAgentProcessPIDs returns all PIDs of running FixPanic Agent processes
 func getAllAgentProcessPIDs() ([]int, error) {
-	var pids []int
-
-	// Create process manager for the current platform
-	procManager := process.NewProcessManager()
-
-	// Use ps command to find all fixpanic-connectivity-layer processes
-	cmd := exec.Command("ps", "aux")
-	output, err := cmd.Output()
+	procs, err := findAgentProcesses()
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute ps command: %w", err)
+		return nil, err
 	}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		// Look for fixpanic-connectivity-layer process (exclude grep itself and this process)
-		if strings.Contains(line, "fixpanic-connectivity-layer") {
-			if strings.Contains(line, "grep") || strings.Contains(line, "ps aux") {
-				continue
-			}
-
-			// Extract PID from ps output
-			fields := strings.Fields(line)
-			if len(fields) >= 2 {
-				if pid, err := strconv.Atoi(fields[1]); err == nil {
-					// Verify the process is actually running using our process manager
-					if procManager.IsProcessRunning(pid) {
-						pids = append(pids, pid)
-					}
-				}
-			}
-		}
+	var pids []int
+	for _, proc := range procs {
+		pids = append(pids, proc.PID)
 	}
-
 	return pids, nil
 }
@@ -1,21 +1,31 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
 	"github.com/fixpanic/fixpanic-cli/internal/config"
 	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
 	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/metrics"
+	"github.com/fixpanic/fixpanic-cli/internal/panicstop"
 	"github.com/fixpanic/fixpanic-cli/internal/platform"
-	"github.com/fixpanic/fixpanic-cli/internal/process"
+	"github.com/fixpanic/fixpanic-cli/internal/procfind"
 	"github.com/fixpanic/fixpanic-cli/internal/service"
 	"github.com/spf13/cobra"
 )
 
+var statusTextfile string
+
 // agentStatusCmd represents the agent status command
 var agentStatusCmd = &cobra.Command{
 	Use:   "status",
@@ -23,68 +33,65 @@ var agentStatusCmd = &cobra.Command{
 	Long: `Check the status of the Fixpanic agent on your server.
 	
 This command shows whether the agent is installed, running, and provides
-information about the current configuration and connectivity.`,
+information about the current configuration and connectivity.
+
+With --textfile, the status is written as Prometheus gauges (the metrics of
+'fixpanic agent metrics serve') to a .prom file for node_exporter's textfile
+collector instead. 'fixpanic agent metrics textfile install' refreshes it on
+a schedule.
+
+--porcelain prints the status as stable tab-separated key/value lines for
+scripts (see 'fixpanic version --porcelain' for the format); every key is
+always present and empty when it doesn't apply.`,
 	Example: `  # Check agent status
-  fixpanic agent status`,
+  fixpanic agent status
+
+  # Check from a script whether the agent runs
+  fixpanic agent status --porcelain | awk -F'\t' '$1 == "running" { print $2 }'
+
+  # Write the status for node_exporter's textfile collector
+  sudo fixpanic agent status --textfile /var/lib/node_exporter/textfile/fixpanic.prom`,
 	RunE: runAgentStatus,
 }
 
 func init() {
 	agentCmd.AddCommand(agentStatusCmd)
-}
 
-// getAgentProcessInfo detects if the FixPanic Agent process is running using cross-platform process management
-func getAgentProcessInfo() (running bool, pid int, err error) {
-	// Create process manager for the current platform
-	procManager := process.NewProcessManager()
+	// Add flags
+	agentStatusCmd.Flags().StringVar(&statusTextfile, "textfile", "", "Write the status as Prometheus metrics to this .prom file for node_exporter")
+	addPorcelainFlag(agentStatusCmd)
+}
 
-	// Use a more targeted approach: check if the specific agent binary is running
-	// We'll use the ps command approach but make it more robust
-	cmd := exec.Command("ps", "aux")
-	output, err := cmd.Output()
+// findAgentProcesses returns the running processes of the installed agent
+// binary, matched by executable path
+func findAgentProcesses() ([]procfind.Process, error) {
+	platformInfo, err := commandPlatform()
 	if err != nil {
-		return false, 0, fmt.Errorf("failed to execute ps command: %w", err)
+		return nil, err
 	}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		// Look for fixpanic-connectivity-layer process (exclude grep itself and this process)
-		if strings.Contains(line, "fixpanic-connectivity-layer") {
-			if strings.Contains(line, "grep") || strings.Contains(line, "ps aux") {
-				continue
-			}
-
-			// Extract PID from ps output
-			// Handle both GNU ps (USER PID ...) and busybox ps (PID USER ...) formats
-			fields := strings.Fields(line)
-			if len(fields) >= 2 {
-				var pid int
-				var err error
-
-				// Try to parse first field as PID (busybox format: PID USER TIME COMMAND)
-				pid, err = strconv.Atoi(fields[0])
-				if err != nil {
-					// If first field isn't a number, try second field (GNU format: USER PID %CPU ...)
-					pid, err = strconv.Atoi(fields[1])
-					if err != nil {
-						continue
-					}
-				}
-
-				// Verify the process is actually running using our process manager
-				if procManager.IsProcessRunning(pid) {
-					return true, pid, nil
-				}
-			}
-		}
+	procs, err := procfind.FindByExecutable(platformInfo.GetFixPanicAgentBinaryPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
 	}
+	return procs, nil
+}
 
-	return false, 0, nil
+// getAgentProcessInfo detects if the FixPanic Agent process is running
+func getAgentProcessInfo() (running bool, pid int, err error) {
+	procs, err := findAgentProcesses()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(procs) == 0 {
+		return false, 0, nil
+	}
+	return true, procs[0].PID, nil
 }
 
 // getServicePID gets the PID of the systemd service
-func getServicePID() int {
-	cmd := exec.Command("systemctl", "show", "-p", "MainPID", platform.GetSystemdServiceName())
+func getServicePID(ctx context.Context) int {
+	cmd := exec.CommandContext(ctx, "systemctl", "show", "-p", "MainPID", platform.GetSystemdServiceName())
 	output, err := cmd.Output()
 	if err != nil {
 		return 0
@@ -103,17 +110,25 @@ func getServicePID() int {
 }
 
 func runAgentStatus(cmd *cobra.Command, args []string) error {
+	if statusTextfile != "" {
+		return writeStatusTextfile(cmd.Context(), statusTextfile)
+	}
+	if porcelainFormat != "" {
+		return writeStatusPorcelain(cmd.Context())
+	}
+
 	logger.Header("FixPanic Agent Status")
+	ctx := cmd.Context()
 
 	// Check if running local development version
 	if rootCmd.Version == "dev" {
-		fmt.Println("🚀 Running LOCAL DEVELOPMENT version (built from source)")
+		fmt.Fprintln(os.Stderr, "🚀 Running LOCAL DEVELOPMENT version (built from source)")
 	}
 
 	// Get platform information
-	platformInfo, err := platform.GetPlatformInfo()
+	platformInfo, err := commandPlatform()
 	if err != nil {
-		return fmt.Errorf("failed to get platform info: %w", err)
+		return err
 	}
 
 	// Check if connectivity layer is installed
@@ -129,7 +144,7 @@ func runAgentStatus(cmd *cobra.Command, args []string) error {
 	logger.Success("Agent is installed")
 
 	// Get FixPanic Agent version
-	version, err := connectivityManager.GetFixPanicAgentVersion()
+	version, err := connectivityManager.GetFixPanicAgentVersion(ctx)
 	if err != nil {
 		logger.Warning("Could not determine FixPanic Agent version: %v", err)
 	} else {
@@ -144,7 +159,15 @@ func runAgentStatus(cmd *cobra.Command, args []string) error {
 	} else {
 		logger.KeyValue("Configuration file", configPath)
 		logger.KeyValue("Agent ID", agentConfig.App.AgentID)
-		logger.KeyValue("Log level", agentConfig.Logging.Level)
+		logLevel := agentConfig.Logging.Level
+		if pending, err := readLogLevelRevert(platformInfo.GetLogLevelRevertPath()); err == nil && pending.Level == logLevel {
+			logLevel = i18n.Sprintf("%s (reverts to %s at %s)", logLevel, pending.Previous, pending.RevertAt.Format("15:04:05"))
+		}
+		logger.KeyValue("Log level", logLevel)
+		if len(insecureTLSKeys(agentConfig)) > 0 {
+			warnInsecureTLS(agentConfig)
+			logger.Info("Run 'fixpanic agent tls status' for the full TLS settings")
+		}
 	}
 
 	// Check service status or process status
@@ -152,7 +175,7 @@ func runAgentStatus(cmd *cobra.Command, args []string) error {
 		serviceManager := service.NewManager(platformInfo)
 
 		// Check if service is enabled
-		enabled, err := serviceManager.IsEnabled()
+		enabled, err := serviceManager.IsEnabled(ctx)
 		if err != nil {
 			fmt.Printf("⚠️  Could not check if service is enabled: %v\n", err)
 		} else if enabled {
@@ -162,7 +185,7 @@ func runAgentStatus(cmd *cobra.Command, args []string) error {
 		}
 
 		// Check service status
-		status, err := serviceManager.Status()
+		status, err := serviceManager.Status(ctx)
 		if err != nil {
 			fmt.Printf("⚠️  Could not get service status: %v\n", err)
 		} else {
@@ -170,7 +193,7 @@ func runAgentStatus(cmd *cobra.Command, args []string) error {
 			case "active":
 				fmt.Println("✅ Service is running")
 				// Try to get PID from systemctl
-				if pid := getServicePID(); pid > 0 {
+				if pid := getServicePID(ctx); pid > 0 {
 					fmt.Printf("🆔 Process ID: %d\n", pid)
 				}
 			case "inactive":
@@ -179,11 +202,28 @@ func runAgentStatus(cmd *cobra.Command, args []string) error {
 				fmt.Printf("⚠️  Service status: %s\n", status)
 			}
 		}
+
+		// systemd reports "active" between automatic restarts, so check for crash loops
+		if report := detectCrashLoop(ctx, platformInfo); report != nil && report.Looping {
+			fmt.Printf("🔁 %s\n", report.Summary())
+			if len(report.Excerpt) > 0 {
+				fmt.Println("   Last log lines before the crash:")
+				for _, line := range report.Excerpt {
+					fmt.Printf("     %s\n", line)
+				}
+			}
+			fmt.Println("   Run 'fixpanic agent doctor' for a full diagnosis")
+		}
 	} else {
 		// Systemd not available, check if process is running directly using cross-platform process management
-		fmt.Println("ℹ️  Systemd not available - checking process status directly")
-		// Try to find the agent process by checking if any process with "fixpanic-connectivity-layer" is running
-		// This is a more robust approach than the previous ps aux method
+		if controller := nativeService(ctx); controller != nil {
+			if status, err := controller.GetServiceStatus(ctx); err == nil {
+				fmt.Printf("ℹ️  Windows service %s is %s\n", platform.GetWindowsServiceName(), status)
+			}
+		} else {
+			fmt.Fprintln(os.Stderr, "ℹ️  Systemd not available - checking process status directly")
+		}
+		// Find the agent process by the path of the installed binary
 		running, pid, err := getAgentProcessInfo()
 		if err != nil {
 			fmt.Printf("⚠️  Could not check process status: %v\n", err)
@@ -194,6 +234,25 @@ func runAgentStatus(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Report watchdog supervision if present
+	if status := readWatchdogStatus(platformInfo); status != nil {
+		fmt.Printf("🐕 Supervised by watchdog (PID: %d, state: %s, restarts: %d)\n", status.PID, status.State, status.Restarts)
+		if status.LastExit != "" {
+			fmt.Printf("   Last exit: %s at %s\n", status.LastExit, status.LastExitAt.Format(time.RFC3339))
+		}
+		if report := detectCrashLoop(ctx, platformInfo); report != nil && report.Looping {
+			fmt.Printf("🔁 %s\n", report.Summary())
+			for _, line := range report.Excerpt {
+				fmt.Printf("     %s\n", line)
+			}
+		}
+	}
+
+	if marker, err := panicstop.Read(platformInfo.GetPanicStopPath()); !errors.Is(err, os.ErrNotExist) {
+		fmt.Printf("🛑 Emergency stop engaged%s\n", agentops.DescribePanicStop(marker))
+		fmt.Println("   Run 'fixpanic agent panic-reset' to lift it")
+	}
+
 	// Check binary location
 	binaryPath := platformInfo.GetFixPanicAgentBinaryPath()
 	if _, err := os.Stat(binaryPath); err == nil {
@@ -206,11 +265,92 @@ func runAgentStatus(cmd *cobra.Command, args []string) error {
 		fmt.Printf("📝 Log file: %s\n", logPath)
 	}
 
-	fmt.Println("\n💡 Useful commands:")
-	fmt.Println("  fixpanic agent start    - Start the agent")
-	fmt.Println("  fixpanic agent stop     - Stop the agent")
-	fmt.Println("  fixpanic agent logs     - View agent logs")
-	fmt.Println("  fixpanic agent uninstall - Remove the agent")
+	fmt.Fprintln(os.Stderr, "\n💡 Useful commands:")
+	fmt.Fprintln(os.Stderr, "  fixpanic agent start    - Start the agent")
+	fmt.Fprintln(os.Stderr, "  fixpanic agent stop     - Stop the agent")
+	fmt.Fprintln(os.Stderr, "  fixpanic agent logs     - View agent logs")
+	fmt.Fprintln(os.Stderr, "  fixpanic agent doctor   - Diagnose common problems")
+	fmt.Fprintln(os.Stderr, "  fixpanic agent uninstall - Remove the agent")
 
 	return nil
 }
+
+// writeStatusTextfile writes the agent's metrics to path for node_exporter's
+// textfile collector. It prints nothing on success, as it runs on a timer.
+func writeStatusTextfile(ctx context.Context, path string) error {
+	if !strings.HasSuffix(path, ".prom") {
+		return clierror.New(clierror.Usage, "--textfile must end in .prom; node_exporter ignores other files")
+	}
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	if err := metrics.WriteFile(path, collectAgentMetrics(ctx, platformInfo)); err != nil {
+		return clierror.WithHint(clierror.Wrap(clierror.General, fmt.Errorf("failed to write %s: %w", path, err)),
+			"Point --textfile into the directory of node_exporter's --collector.textfile.directory")
+	}
+	return nil
+}
+
+// writeStatusPorcelain prints the status as --porcelain fields
+func writeStatusPorcelain(ctx context.Context) error {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+	p, err := newPorcelainWriter(os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	connectivityManager := connectivity.NewManager(platformInfo)
+	installed := connectivityManager.IsFixPanicAgentInstalled()
+	var version, agentID, logLevel, supervisor, enabled, pid string
+	var running, looping bool
+	if installed {
+		if output, err := connectivityManager.GetFixPanicAgentVersion(ctx); err == nil {
+			version = connectivity.ParseAgentVersion(output)
+		}
+		if agentConfig, err := config.LoadConfig(platformInfo.GetConfigPath()); err == nil {
+			agentID, logLevel = agentConfig.App.AgentID, agentConfig.Logging.Level
+		}
+
+		supervisor = "none"
+		switch {
+		case platform.IsSystemdAvailable():
+			supervisor = "systemd"
+			if isEnabled, err := service.NewManager(platformInfo).IsEnabled(ctx); err == nil {
+				enabled = strconv.FormatBool(isEnabled)
+			}
+		case nativeService(ctx) != nil:
+			supervisor = "windows-service"
+		case readWatchdogStatus(platformInfo) != nil:
+			supervisor = "watchdog"
+		}
+
+		var agentPID int
+		running, agentPID = detectAgentRunning(ctx, platformInfo)
+		if agentPID > 0 {
+			pid = strconv.Itoa(agentPID)
+		}
+		if report := detectCrashLoop(ctx, platformInfo); report != nil {
+			looping = report.Looping
+		}
+	}
+
+	p.field("installed", installed)
+	p.field("version", version)
+	p.field("binary", platformInfo.GetFixPanicAgentBinaryPath())
+	p.field("config", platformInfo.GetConfigPath())
+	p.field("agent_id", agentID)
+	p.field("log_level", logLevel)
+	p.field("log_file", platformInfo.GetLogPath())
+	p.field("supervisor", supervisor)
+	p.field("enabled", enabled)
+	p.field("running", running)
+	p.field("pid", pid)
+	p.field("crash_loop", looping)
+	p.field("panic_stop", panicstop.Engaged(platformInfo.GetPanicStopPath()))
+	return p.close()
+}
@@ -2,7 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/fixpanic/fixpanic-cli/internal/hooks"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
 	"github.com/fixpanic/fixpanic-cli/internal/process"
 	"github.com/spf13/cobra"
 )
@@ -10,46 +13,82 @@ import (
 var agentStopCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "Stop the FixPanic Agent",
-	Long:  `Stop the FixPanic Agent service that is running in the background.`,
+	Long: `Stop the FixPanic Agent service that is running in the background.
+
+With --drain the agent first stops accepting new remote-debug sessions and
+the active ones may finish, for up to --drain-timeout. If sessions are still
+active then, the agent accepts sessions again and keeps running.`,
+	Example: `  # Stop the agent once nobody is debugging through it
+  sudo fixpanic agent stop --drain --drain-timeout 30m`,
+	Annotations: map[string]string{annotationMutating: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get all running agent processes
-		pids, err := getAllAgentProcessPIDs()
-		if err != nil {
-			return fmt.Errorf("failed to check agent status: %w", err)
-		}
+		return runWithHooks(cmd.Context(), hooks.OperationStop, func() error { return runAgentStop(cmd, args) })
+	},
+}
 
-		if len(pids) == 0 {
-			fmt.Println("FixPanic Agent is not running")
-			return nil
-		}
+func init() {
+	agentCmd.AddCommand(agentStopCmd)
+
+	// Add flags
+	addDrainFlags(agentStopCmd)
+}
+
+func runAgentStop(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if err := drainAgent(ctx); err != nil {
+		return err
+	}
 
-		// Create process manager for the current platform
-		procManager := process.NewProcessManager()
-
-		// Stop all agent processes
-		stoppedCount := 0
-		for _, pid := range pids {
-			fmt.Printf("Stopping FixPanic Agent (PID: %d)...\n", pid)
-			if err := procManager.StopProcess(pid); err != nil {
-				fmt.Printf("Warning: failed to stop process %d: %v\n", pid, err)
-			} else {
-				stoppedCount++
+	// Stop the watchdog first so it doesn't restart the agent
+	if err := stopWatchdog(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	// Stop the Windows service first so the Service Control Manager tracks the state
+	if controller := nativeService(ctx); controller != nil {
+		if status, err := controller.GetServiceStatus(ctx); err == nil && status == "running" {
+			fmt.Fprintf(os.Stderr, "Stopping service %s...\n", platform.GetWindowsServiceName())
+			if err := controller.StopService(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 			}
 		}
+	}
 
-		if stoppedCount == 0 {
-			return fmt.Errorf("failed to stop any agent processes")
-		}
+	// Get all running agent processes
+	pids, err := getAllAgentProcessPIDs()
+	if err != nil {
+		return fmt.Errorf("failed to check agent status: %w", err)
+	}
+
+	if len(pids) == 0 {
+		fmt.Fprintln(os.Stderr, "FixPanic Agent is not running")
+		return nil
+	}
+
+	// Create process manager for the current platform
+	procManager := process.NewProcessManager()
 
-		if stoppedCount == 1 {
-			fmt.Println("FixPanic Agent stopped successfully")
+	// Stop all agent processes
+	stoppedCount := 0
+	for _, pid := range pids {
+		fmt.Fprintf(os.Stderr, "Stopping FixPanic Agent (PID: %d)...\n", pid)
+		if err := procManager.StopProcess(pid); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to stop process %d: %v\n", pid, err)
 		} else {
-			fmt.Printf("FixPanic Agent stopped successfully (%d processes stopped)\n", stoppedCount)
+			stoppedCount++
 		}
-		return nil
-	},
-}
+	}
 
-func init() {
-	agentCmd.AddCommand(agentStopCmd)
+	if stoppedCount == 0 {
+		resumeAgent()
+		return fmt.Errorf("failed to stop any agent processes")
+	}
+
+	if stoppedCount == 1 {
+		fmt.Fprintln(os.Stderr, "FixPanic Agent stopped successfully")
+	} else {
+		fmt.Fprintf(os.Stderr, "FixPanic Agent stopped successfully (%d processes stopped)\n", stoppedCount)
+	}
+	return nil
 }
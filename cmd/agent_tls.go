@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/netprobe"
+	"github.com/spf13/cobra"
+)
+
+var tlsStatusJSON bool
+
+// agentTLSCmd represents the agent tls command group
+var agentTLSCmd = &cobra.Command{
+	Use:   "tls",
+	Short: "Inspect the agent's TLS settings",
+}
+
+// agentTLSStatusCmd represents the agent tls status command
+var agentTLSStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Summarize the agent's effective TLS settings",
+	Long: `Summarize the TLS settings of both sections of the agent configuration
+(app and req_handler): whether TLS is enabled and certificates are verified,
+which CAs are trusted, the client certificate and pinned keys, and the
+certificate the socket server presents, verified the way the agent would.
+
+Disabling certificate verification (tls_insecure_skip_verify: true) is
+reported here, in 'fixpanic agent status' and in 'fixpanic agent doctor'.`,
+	Example: `  # Review the TLS posture of this host
+  fixpanic agent tls status
+
+  # As JSON, e.g. for a compliance scan
+  fixpanic agent tls status --json`,
+	Annotations: map[string]string{annotationRequires: requireInstalled + "," + requireConfig},
+	RunE:        runAgentTLSStatus,
+}
+
+func init() {
+	agentCmd.AddCommand(agentTLSCmd)
+	agentTLSCmd.AddCommand(agentTLSStatusCmd)
+
+	// Add flags
+	agentTLSStatusCmd.Flags().BoolVar(&tlsStatusJSON, "json", false, "Output the status as JSON")
+}
+
+// tlsStatus is the effective TLS configuration of the agent
+type tlsStatus struct {
+	Sections []tlsSectionStatus `json:"sections"`
+	// CASource is "system" or the path of the CA bundle
+	CASource          string          `json:"ca_source"`
+	CACertificates    int             `json:"ca_certificates,omitempty"`
+	ClientCertificate *tlsCertificate `json:"client_certificate,omitempty"`
+	PinnedKeys        []string        `json:"pinned_keys,omitempty"`
+	Server            *tlsServer      `json:"server,omitempty"`
+	// Insecure is set when either section turns off certificate verification
+	Insecure bool     `json:"insecure"`
+	Warnings []string `json:"warnings"`
+}
+
+// tlsSectionStatus is the TLS setting of one section of the agent configuration
+type tlsSectionStatus struct {
+	Section            string `json:"section"`
+	Enabled            bool   `json:"enabled"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+}
+
+// tlsCertificate describes a certificate
+type tlsCertificate struct {
+	Subject  string    `json:"subject"`
+	Issuer   string    `json:"issuer"`
+	NotAfter time.Time `json:"not_after"`
+	// ExpiresInDays is negative for an expired certificate
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+// tlsServer is what the socket server presented
+type tlsServer struct {
+	Endpoint    string          `json:"endpoint"`
+	Certificate *tlsCertificate `json:"certificate,omitempty"`
+	// Verified is whether the chain verifies against the CA source
+	Verified    bool   `json:"verified"`
+	VerifyError string `json:"verify_error,omitempty"`
+	PinMatch    string `json:"pin_match,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// insecureTLSKeys returns the keys of the agent configuration that turn off
+// certificate verification
+func insecureTLSKeys(agentConfig *config.AgentConfig) []string {
+	var keys []string
+	if agentConfig.App.TLSInsecureSkipVerify {
+		keys = append(keys, "app.tls_insecure_skip_verify")
+	}
+	if agentConfig.ReqHandler.TLSInsecureSkipVerify {
+		keys = append(keys, "req_handler.tls_insecure_skip_verify")
+	}
+	return keys
+}
+
+// warnInsecureTLS prints the loud warning shown wherever the configuration
+// turns off certificate verification
+func warnInsecureTLS(agentConfig *config.AgentConfig) {
+	for _, key := range insecureTLSKeys(agentConfig) {
+		logger.Warning("TLS CERTIFICATE VERIFICATION IS DISABLED (%s: true): anyone on the network path can intercept the agent's traffic", key)
+	}
+}
+
+func runAgentTLSStatus(cmd *cobra.Command, args []string) error {
+	agentConfig := commandConfig()
+	status := inspectTLS(cmd.Context(), agentConfig)
+	if tlsStatusJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(status)
+	}
+
+	printTLSStatus(status, agentConfig)
+	return nil
+}
+
+// inspectTLS collects the effective TLS settings and probes the socket server
+func inspectTLS(ctx context.Context, agentConfig *config.AgentConfig) *tlsStatus {
+	app := agentConfig.App
+	status := &tlsStatus{
+		Sections: []tlsSectionStatus{
+			{Section: "app", Enabled: app.TLSEnabled, InsecureSkipVerify: app.TLSInsecureSkipVerify},
+			{Section: "req_handler", Enabled: agentConfig.ReqHandler.TLSEnabled, InsecureSkipVerify: agentConfig.ReqHandler.TLSInsecureSkipVerify},
+		},
+		CASource:   "system",
+		PinnedKeys: app.TLSPinnedSPKI,
+		Warnings:   []string{},
+	}
+
+	for _, section := range status.Sections {
+		if !section.Enabled {
+			status.Warnings = append(status.Warnings, fmt.Sprintf("TLS is disabled in %s; the traffic is not encrypted", section.Section))
+		}
+	}
+	status.Insecure = len(insecureTLSKeys(agentConfig)) > 0
+
+	material, err := app.LoadTLSMaterial()
+	if err != nil {
+		status.Warnings = append(status.Warnings, err.Error())
+		material = &config.TLSMaterial{}
+	}
+	if app.TLSCAFile != "" {
+		status.CASource = app.TLSCAFile
+		status.CACertificates = len(material.CACertificates)
+	}
+	if cert := material.ClientCertificate; cert != nil {
+		status.ClientCertificate = describeTLSCertificate(cert.Subject.CommonName, cert.Issuer.CommonName, cert.NotAfter)
+	}
+
+	if !app.TLSEnabled {
+		return status
+	}
+	endpoint := agentConfig.GetSocketServer()
+	server := &tlsServer{Endpoint: endpoint}
+	status.Server = server
+	chain, err := netprobe.PeerCertificates(ctx, endpoint, 10*time.Second)
+	if err != nil {
+		server.Error = err.Error()
+		return status
+	}
+	leaf := chain[0]
+	server.Certificate = describeTLSCertificate(leaf.Subject.CommonName, leaf.Issuer.CommonName, leaf.NotAfter)
+	host, _, _ := net.SplitHostPort(endpoint)
+	if err := netprobe.VerifyChain(chain, host, material.CertPool()); err != nil {
+		server.VerifyError = err.Error()
+	} else {
+		server.Verified = true
+	}
+	server.PinMatch = netprobe.MatchSPKIPins(chain, app.TLSPinnedSPKI)
+	if len(app.TLSPinnedSPKI) > 0 && server.PinMatch == "" {
+		status.Warnings = append(status.Warnings, fmt.Sprintf("%s matches none of the pinned keys", endpoint))
+	}
+	return status
+}
+
+// describeTLSCertificate summarizes a certificate for the status
+func describeTLSCertificate(subject, issuer string, notAfter time.Time) *tlsCertificate {
+	return &tlsCertificate{
+		Subject:       subject,
+		Issuer:        issuer,
+		NotAfter:      notAfter.UTC(),
+		ExpiresInDays: int(time.Until(notAfter).Hours() / 24),
+	}
+}
+
+// printTLSStatus reports the TLS status on the terminal
+func printTLSStatus(status *tlsStatus, agentConfig *config.AgentConfig) {
+	logger.Header("Agent TLS Status")
+	warnInsecureTLS(agentConfig)
+
+	for _, section := range status.Sections {
+		verify := "verified"
+		if section.InsecureSkipVerify {
+			verify = "NOT VERIFIED"
+		}
+		if section.Enabled {
+			logger.KeyValue(section.Section, i18n.Sprintf("TLS enabled, certificates %s", verify))
+		} else {
+			logger.KeyValue(section.Section, i18n.T("TLS disabled"))
+		}
+	}
+
+	if status.CASource == "system" {
+		logger.KeyValue("CA certificates", i18n.T("system roots"))
+	} else {
+		logger.KeyValue("CA bundle", fmt.Sprintf("%s (%d certificate(s))", status.CASource, status.CACertificates))
+	}
+	if cert := status.ClientCertificate; cert != nil {
+		logger.KeyValue("Client certificate", i18n.Sprintf("%s, expires %s (%d days)", cert.Subject, cert.NotAfter.Local().Format("2006-01-02"), cert.ExpiresInDays))
+	}
+	if len(status.PinnedKeys) > 0 {
+		logger.KeyValue("Pinned keys", fmt.Sprintf("%d", len(status.PinnedKeys)))
+	}
+
+	if server := status.Server; server != nil {
+		logger.Separator()
+		logger.KeyValue("Socket server", server.Endpoint)
+		if server.Error != "" {
+			logger.Warning("Could not inspect the server certificate: %s", server.Error)
+		} else {
+			cert := server.Certificate
+			logger.KeyValue("Certificate", fmt.Sprintf("%s (issued by %s)", cert.Subject, cert.Issuer))
+			logger.KeyValue("Expires", i18n.Sprintf("%s (%d days)", cert.NotAfter.Local().Format("2006-01-02"), cert.ExpiresInDays))
+			if server.Verified {
+				logger.Success("Certificate chain verifies against the %s CA certificates", status.CASource)
+			} else {
+				logger.Error("Certificate chain does not verify: %s", server.VerifyError)
+			}
+			if server.PinMatch != "" {
+				logger.Success("Certificate matches pinned key %s", server.PinMatch)
+			}
+		}
+	}
+
+	logger.Separator()
+	for _, warning := range status.Warnings {
+		logger.Warning("%s", warning)
+	}
+	if status.Insecure {
+		logger.Info("Turn verification back on with 'sudo fixpanic config set <key> false'; trust a private CA with app.tls_ca_file instead")
+	} else if len(status.Warnings) == 0 {
+		logger.Success("No TLS problems found")
+	}
+}
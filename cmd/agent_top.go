@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/procfind"
+	"github.com/fixpanic/fixpanic-cli/internal/procstat"
+	"github.com/fixpanic/fixpanic-cli/internal/term"
+	"github.com/spf13/cobra"
+)
+
+var (
+	topInterval time.Duration
+	topNoStream bool
+)
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// agentTopCmd represents the agent top command
+var agentTopCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Show live resource usage of the agent",
+	Long: `Display a live view of the agent process tree with the CPU and memory
+usage and the number of established TCP connections of each process, similar
+to 'docker stats'.
+
+The agent has no statistics endpoint of its own, so the figures are read from
+the operating system (procfs on Linux). Connection counts need access to the
+agent's file descriptors, which usually means running as root. On other
+platforms only the process tree is shown.
+
+Press Ctrl+C to exit.`,
+	Example: `  # Live view, refreshed every 2 seconds
+  sudo fixpanic agent top
+
+  # Print a single snapshot, e.g. for a support ticket
+  sudo fixpanic agent top --no-stream`,
+	RunE: runAgentTop,
+}
+
+func init() {
+	agentCmd.AddCommand(agentTopCmd)
+
+	// Add flags
+	agentTopCmd.Flags().DurationVar(&topInterval, "interval", 2*time.Second, "Time between refreshes")
+	agentTopCmd.Flags().BoolVar(&topNoStream, "no-stream", false, "Print a single snapshot and exit")
+}
+
+// topRow is one process in the agent top view
+type topRow struct {
+	node procstat.Node
+	stat procstat.Stat
+	// cpu is the CPU usage since the previous frame, or -1 for the first sample
+	cpu float64
+	err error
+}
+
+func runAgentTop(cmd *cobra.Command, args []string) error {
+	if topInterval < 100*time.Millisecond {
+		return clierror.New(clierror.Usage, "--interval must be at least 100ms")
+	}
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	prev := make(map[int]procstat.Stat)
+
+	if topNoStream {
+		// CPU usage needs two samples, so wait one interval before printing
+		if _, err := sampleAgentTree(ctx, platformInfo, prev); err != nil {
+			return err
+		}
+		if err := sleepContext(ctx, topInterval); err != nil {
+			return err
+		}
+		rows, err := sampleAgentTree(ctx, platformInfo, prev)
+		if err != nil {
+			return err
+		}
+		renderAgentTop(os.Stdout, rows)
+		return nil
+	}
+
+	interactive := term.IsTerminal(os.Stdout)
+	for {
+		rows, err := sampleAgentTree(ctx, platformInfo, prev)
+		if interactive {
+			fmt.Print(clearScreen)
+		}
+		fmt.Printf("FixPanic Agent  %s\n\n", time.Now().Format("15:04:05"))
+		if err != nil {
+			// The agent may be restarting; keep watching until it's back
+			fmt.Printf("❌ %v\n", err)
+		} else {
+			renderAgentTop(os.Stdout, rows)
+		}
+		if !interactive {
+			fmt.Println()
+		}
+
+		if err := sleepContext(ctx, topInterval); err != nil {
+			if ctx.Err() == context.Canceled {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// sampleAgentTree samples every process of the agent tree. prev holds the
+// samples of the previous frame and is updated in place.
+func sampleAgentTree(ctx context.Context, platformInfo *platform.PlatformInfo, prev map[int]procstat.Stat) ([]topRow, error) {
+	running, pid := detectAgentRunning(ctx, platformInfo)
+	if !running || pid == 0 {
+		return nil, clierror.New(clierror.General, "the agent is not running").
+			WithHint("Start it with 'fixpanic agent start'")
+	}
+
+	procs, err := procfind.Processes()
+	if err != nil {
+		return nil, err
+	}
+	nodes := procstat.Tree(procs, pid)
+	if len(nodes) == 0 {
+		return nil, clierror.New(clierror.General, "agent process %d exited", pid)
+	}
+
+	rows := make([]topRow, 0, len(nodes))
+	seen := make(map[int]bool, len(nodes))
+	for _, node := range nodes {
+		row := topRow{node: node, cpu: -1}
+		row.stat, row.err = procstat.Read(node.PID)
+		if row.err == nil {
+			if last, ok := prev[node.PID]; ok {
+				row.cpu = procstat.CPUPercent(last, row.stat)
+			}
+			prev[node.PID] = row.stat
+			seen[node.PID] = true
+		}
+		rows = append(rows, row)
+	}
+
+	// Forget processes that exited so a reused PID starts fresh
+	for pid := range prev {
+		if !seen[pid] {
+			delete(prev, pid)
+		}
+	}
+
+	return rows, nil
+}
+
+// renderAgentTop prints the process table and a totals line
+func renderAgentTop(out io.Writer, rows []topRow) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PID\tCPU %\tMEM\tCONNS\tCOMMAND")
+
+	var totalCPU float64
+	var totalRSS uint64
+	totalConns := 0
+	connsKnown := true
+	for _, row := range rows {
+		cpu, mem, conns := "-", "-", "-"
+		if row.err == nil {
+			if row.cpu >= 0 {
+				cpu = fmt.Sprintf("%.1f%%", row.cpu)
+				totalCPU += row.cpu
+			}
+			mem = formatSize(int64(row.stat.RSS))
+			totalRSS += row.stat.RSS
+			if row.stat.Connections >= 0 {
+				conns = fmt.Sprintf("%d", row.stat.Connections)
+				totalConns += row.stat.Connections
+			} else {
+				connsKnown = false
+			}
+		} else {
+			connsKnown = false
+		}
+
+		// comm is truncated to 15 characters on Linux; prefer the binary name
+		command := row.node.Name
+		if row.node.Executable != "" {
+			command = filepath.Base(row.node.Executable)
+		}
+		if row.node.Depth > 0 {
+			command = strings.Repeat("  ", row.node.Depth-1) + "└─ " + command
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", row.node.PID, cpu, mem, conns, command)
+	}
+
+	conns := fmt.Sprintf("%d", totalConns)
+	if !connsKnown {
+		conns = "-"
+	}
+	fmt.Fprintf(w, "TOTAL\t%.1f%%\t%s\t%s\t%d process(es)\n", totalCPU, formatSize(int64(totalRSS)), conns, len(rows))
+	w.Flush()
+
+	for _, row := range rows {
+		if row.err != nil {
+			fmt.Fprintln(out)
+			logger.Warning("Resource usage is unavailable: %v", row.err)
+			return
+		}
+	}
+	if !connsKnown {
+		fmt.Fprintln(out)
+		logger.Info("Run as root to see connection counts")
+	}
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
 	"github.com/fixpanic/fixpanic-cli/internal/platform"
 	"github.com/fixpanic/fixpanic-cli/internal/service"
+	"github.com/fixpanic/fixpanic-cli/internal/summary"
 	"github.com/spf13/cobra"
 )
 
@@ -26,7 +27,8 @@ the agent from your system.`,
   
   # Force uninstall without confirmation
   fixpanic agent uninstall --force`,
-	RunE: runAgentUninstall,
+	Annotations: map[string]string{annotationMutating: "true", annotationRequires: requireLock},
+	RunE:        runAgentUninstall,
 }
 
 func init() {
@@ -34,39 +36,43 @@ func init() {
 
 	// Add flags
 	agentUninstallCmd.Flags().BoolVar(&forceUninstall, "force", false, "Force uninstall without confirmation")
+	addSummaryFlag(agentUninstallCmd)
 }
 
 func runAgentUninstall(cmd *cobra.Command, args []string) error {
-	fmt.Println("Uninstalling Fixpanic agent...")
+	fmt.Fprintln(os.Stderr, "Uninstalling Fixpanic agent...")
+	ctx := cmd.Context()
 
 	// Get platform information
-	platformInfo, err := platform.GetPlatformInfo()
+	platformInfo, err := commandPlatform()
 	if err != nil {
-		return fmt.Errorf("failed to get platform info: %w", err)
+		return err
 	}
 
 	// Check if FixPanic Agent is installed
 	connectivityManager := connectivity.NewManager(platformInfo)
 	if !connectivityManager.IsFixPanicAgentInstalled() {
-		fmt.Println("ℹ️  FixPanic Agent is not installed")
+		fmt.Fprintln(os.Stderr, "ℹ️  FixPanic Agent is not installed")
 		return nil
 	}
 
 	// Confirm uninstallation unless --force is used
 	if !forceUninstall {
-		fmt.Println("⚠️  This will completely remove the Fixpanic agent from your system.")
-		fmt.Println("The following will be removed:")
-		fmt.Printf("  - Binary: %s\n", platformInfo.GetBinaryPath())
-		fmt.Printf("  - Configuration: %s\n", platformInfo.GetConfigPath())
-		fmt.Printf("  - Service: %s\n", platform.GetSystemdServiceName())
-		fmt.Printf("  - Directories: %s, %s, %s\n", platformInfo.LibDir, platformInfo.ConfigDir, platformInfo.LogDir)
-
-		fmt.Print("\nAre you sure you want to continue? [y/N]: ")
-
-		var response string
-		fmt.Scanln(&response)
+		fmt.Fprintln(os.Stderr, "⚠️  This will completely remove the Fixpanic agent from your system.")
+		fmt.Fprintln(os.Stderr, "The following will be removed:")
+		fmt.Fprintf(os.Stderr, "  - Binary: %s\n", platformInfo.GetBinaryPath())
+		fmt.Fprintf(os.Stderr, "  - Configuration: %s\n", platformInfo.GetConfigPath())
+		fmt.Fprintf(os.Stderr, "  - Service: %s\n", platform.GetSystemdServiceName())
+		fmt.Fprintf(os.Stderr, "  - Directories: %s, %s, %s\n", platformInfo.LibDir, platformInfo.ConfigDir, platformInfo.LogDir)
+
+		fmt.Fprint(os.Stderr, "\nAre you sure you want to continue? [y/N]: ")
+
+		response, err := readLine(ctx)
+		if err != nil {
+			return err
+		}
 		if response != "y" && response != "Y" {
-			fmt.Println("Uninstallation cancelled.")
+			fmt.Fprintln(os.Stderr, "Uninstallation cancelled.")
 			return nil
 		}
 	}
@@ -76,34 +82,63 @@ func runAgentUninstall(cmd *cobra.Command, args []string) error {
 		serviceManager := service.NewManager(platformInfo)
 
 		// Check if service is running
-		status, err := serviceManager.Status()
+		status, err := serviceManager.Status(ctx)
 		if err == nil && status == "active" {
-			fmt.Println("Stopping agent service...")
-			if err := serviceManager.Stop(); err != nil {
-				fmt.Printf("Warning: failed to stop service: %v\n", err)
+			fmt.Fprintln(os.Stderr, "Stopping agent service...")
+			if err := serviceManager.Stop(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to stop service: %v\n", err)
+				summary.Record("Stop service", summary.Failed, err.Error())
+			} else {
+				summary.Record("Stop service", summary.OK, "")
 			}
+		} else {
+			summary.Record("Stop service", summary.Skipped, "the service was not running")
 		}
 
 		// Uninstall service
-		fmt.Println("Removing systemd service...")
-		if err := serviceManager.Uninstall(); err != nil {
-			fmt.Printf("Warning: failed to uninstall service: %v\n", err)
+		fmt.Fprintln(os.Stderr, "Removing systemd service...")
+		if err := serviceManager.Uninstall(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to uninstall service: %v\n", err)
+			summary.Record("Remove service", summary.Failed, err.Error())
+		} else {
+			summary.Record("Remove service", summary.OK, "", platformInfo.GetServiceFilePath())
 		}
+	} else {
+		summary.Record("Remove service", summary.Skipped, "systemd not available")
+	}
+
+	// A scheduled health check would only report the missing agent from now on
+	if removed, err := service.NewManager(platformInfo).UninstallHealthCheck(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove the scheduled health check: %v\n", err)
+		summary.Record("Remove health check", summary.Failed, err.Error())
+	} else if removed {
+		fmt.Fprintln(os.Stderr, "Removed the scheduled health check")
+		summary.Record("Remove health check", summary.OK, "")
+	} else {
+		summary.Record("Remove health check", summary.Skipped, "none scheduled")
 	}
 
 	// Remove FixPanic Agent binary
-	fmt.Println("Removing FixPanic Agent binary...")
+	fmt.Fprintln(os.Stderr, "Removing FixPanic Agent binary...")
 	if err := connectivityManager.RemoveFixPanicAgent(); err != nil {
-		fmt.Printf("Warning: failed to remove binary: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove binary: %v\n", err)
+		summary.Record("Remove binary", summary.Failed, err.Error())
+	} else {
+		summary.Record("Remove binary", summary.OK, "", platformInfo.GetFixPanicAgentBinaryPath())
 	}
 
 	// Remove configuration file
 	configPath := platformInfo.GetConfigPath()
-	fmt.Printf("Removing configuration file: %s\n", configPath)
+	fmt.Fprintf(os.Stderr, "Removing configuration file: %s\n", configPath)
 	if err := os.Remove(configPath); err != nil {
 		if !os.IsNotExist(err) {
-			fmt.Printf("Warning: failed to remove configuration file: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove configuration file: %v\n", err)
+			summary.Record("Remove configuration", summary.Failed, err.Error())
+		} else {
+			summary.Record("Remove configuration", summary.Skipped, "no configuration file")
 		}
+	} else {
+		summary.Record("Remove configuration", summary.OK, "", configPath)
 	}
 
 	// Remove directories (only if empty)
@@ -113,16 +148,19 @@ func runAgentUninstall(cmd *cobra.Command, args []string) error {
 		platformInfo.LogDir,
 	}
 
+	var removedDirs []string
 	for _, dir := range dirs {
 		if err := os.Remove(dir); err != nil {
 			// Directory not empty or doesn't exist, which is fine
 			continue
 		}
-		fmt.Printf("Removed empty directory: %s\n", dir)
+		fmt.Fprintf(os.Stderr, "Removed empty directory: %s\n", dir)
+		removedDirs = append(removedDirs, dir)
 	}
+	summary.Record("Remove empty directories", summary.OK, "", removedDirs...)
 
-	fmt.Println("\n✅ Fixpanic agent uninstalled successfully!")
-	fmt.Println("The agent has been completely removed from your system.")
+	fmt.Fprintln(os.Stderr, "\n✅ Fixpanic agent uninstalled successfully!")
+	fmt.Fprintln(os.Stderr, "The agent has been completely removed from your system.")
 
 	return nil
 }
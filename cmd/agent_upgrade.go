@@ -1,16 +1,28 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
 	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/hooks"
 	"github.com/fixpanic/fixpanic-cli/internal/logger"
 	"github.com/fixpanic/fixpanic-cli/internal/platform"
 	"github.com/fixpanic/fixpanic-cli/internal/process"
+	"github.com/fixpanic/fixpanic-cli/internal/releases"
+	"github.com/fixpanic/fixpanic-cli/internal/state"
+	"github.com/fixpanic/fixpanic-cli/internal/summary"
 	"github.com/spf13/cobra"
 )
 
-var forceAgentUpgrade bool
+var (
+	forceAgentUpgrade    bool
+	agentUpgradeTakeover bool
+	// upgradeAgentVersion pins the target release instead of the latest
+	upgradeAgentVersion string
+)
 
 // agentUpgradeCmd represents the agent upgrade command
 var agentUpgradeCmd = &cobra.Command{
@@ -19,13 +31,50 @@ var agentUpgradeCmd = &cobra.Command{
 	Long: `Upgrade the Fixpanic agent binary to the latest version.
 
 This command downloads and installs the latest version of the connectivity
-layer binary, ensuring your agent has the latest features and security updates.`,
-	Example: `  # Upgrade agent to latest version
+layer binary, ensuring your agent has the latest features and security updates.
+
+--agent-version upgrades, or downgrades, to the given release instead of the
+latest, so hosts can be kept at the same version or rolled back.
+
+The release notes of every version since the installed one are shown before
+upgrading; use --show-notes-only to read them without upgrading. Versions that
+were yanked (marked as such in their release or on the published blocklist)
+are refused unless --allow-yanked is given.
+
+If the agent binary belongs to a distribution package (according to dpkg or
+rpm), the upgrade is refused so the CLI and the package manager don't
+overwrite each other's versions: upgrade the package instead. --takeover
+lets the CLI upgrade the binary from then on; hold the package so the
+package manager stops updating it.
+
+With --drain a running agent first stops accepting new remote-debug sessions
+and the active ones may finish, for up to --drain-timeout, so the upgrade
+doesn't cut off a live debugging session. If sessions are still active then,
+nothing is upgraded.`,
+	Example: `  # Read what changed since the installed version
+  fixpanic agent upgrade --show-notes-only
+
+  # Upgrade agent to latest version
   fixpanic agent upgrade
 
+  # Roll back to a previous release
+  sudo fixpanic agent upgrade --agent-version v1.4.0
+
   # Force upgrade even if already on latest version
-  fixpanic agent upgrade --force`,
-	RunE: runAgentUpgrade,
+  fixpanic agent upgrade --force
+
+  # Take over an agent installed from a distribution package
+  sudo fixpanic agent upgrade --takeover
+
+  # Upgrade once nobody is debugging through the agent
+  sudo fixpanic agent upgrade --drain --drain-timeout 30m`,
+	Annotations: map[string]string{annotationMutating: "!show-notes-only", annotationRequires: unless(requireInstalled, "show-notes-only") + "," + requireLock},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if showNotesOnly {
+			return runAgentUpgradeNotes(cmd)
+		}
+		return runWithHooks(cmd.Context(), hooks.OperationUpgrade, func() error { return runAgentUpgrade(cmd, args) })
+	},
 }
 
 func init() {
@@ -33,28 +82,45 @@ func init() {
 
 	// Add flags
 	agentUpgradeCmd.Flags().BoolVar(&forceAgentUpgrade, "force", false, "Force upgrade even if already on latest version")
+	agentUpgradeCmd.Flags().StringVar(&upgradeAgentVersion, "agent-version", "", "Upgrade or downgrade to this agent release (e.g. v1.4.0) instead of the latest")
+	agentUpgradeCmd.Flags().BoolVar(&allowYanked, "allow-yanked", false, "Upgrade even if the target version was yanked")
+	agentUpgradeCmd.Flags().BoolVar(&showNotesOnly, "show-notes-only", false, "Show the release notes since the installed version without upgrading")
+	agentUpgradeCmd.Flags().BoolVar(&agentUpgradeTakeover, "takeover", false, "Upgrade an agent binary installed by a distribution package and manage it with the CLI from now on")
+	addDrainFlags(agentUpgradeCmd)
+	addSummaryFlag(agentUpgradeCmd)
 }
 
 func runAgentUpgrade(cmd *cobra.Command, args []string) error {
 	logger.Header("Upgrading FixPanic Agent")
+	ctx := cmd.Context()
+	if upgradeAgentVersion != "" {
+		tag, err := releases.ParseTag(upgradeAgentVersion)
+		if err != nil {
+			return clierror.Wrap(clierror.Usage, err)
+		}
+		upgradeAgentVersion = tag
+	}
 
 	// Get platform information
 	logger.Step(1, "Detecting platform and configuration")
-	platformInfo, err := platform.GetPlatformInfo()
+	platformInfo, err := commandPlatform()
 	if err != nil {
-		return fmt.Errorf("failed to get platform info: %w", err)
+		return err
 	}
 
 	// Check if FixPanic Agent is installed
 	logger.Step(2, "Checking agent installation")
 	connectivityManager := connectivity.NewManager(platformInfo)
-	if !connectivityManager.IsFixPanicAgentInstalled() {
-		return fmt.Errorf("FixPanic Agent is not installed. Run 'fixpanic agent install' first")
+	if err := checkPackageOwner(ctx, platformInfo); err != nil {
+		return err
+	}
+	if err := persistConfigMigration(platformInfo.GetConfigPath()); err != nil {
+		return err
 	}
 
 	// Get current version
 	logger.Progress("Checking current agent version")
-	currentVersion, err := connectivityManager.GetFixPanicAgentVersion()
+	currentVersion, err := connectivityManager.GetFixPanicAgentVersion(ctx)
 	if err != nil {
 		logger.Warning("Could not determine current version: %v", err)
 		currentVersion = "unknown"
@@ -62,6 +128,26 @@ func runAgentUpgrade(cmd *cobra.Command, args []string) error {
 		logger.KeyValue("Current version", currentVersion)
 	}
 
+	installedVersion := ""
+	if currentVersion != "unknown" {
+		installedVersion = connectivity.ParseAgentVersion(currentVersion)
+	}
+	// The target release: --agent-version or the latest
+	latestVersion, err := upgradeAgentVersion, error(nil)
+	if latestVersion == "" {
+		latestVersion, err = connectivityManager.GetLatestAgentVersion(ctx)
+	}
+	if err != nil {
+		logger.Warning("Could not fetch release notes: %v", err)
+	} else if normalizeVersion(installedVersion) != normalizeVersion(latestVersion) {
+		if err := checkNotYanked(ctx, releases.AgentRepo, latestVersion); err != nil {
+			return err
+		}
+		if err := showReleaseNotes(ctx, releases.AgentRepo, installedVersion, latestVersion); err != nil {
+			logger.Warning("Could not fetch release notes: %v", err)
+		}
+	}
+
 	// Check if agent is running and stop it before upgrade
 	logger.Step(3, "Stopping agent for upgrade")
 	agentWasRunning := false
@@ -69,6 +155,9 @@ func runAgentUpgrade(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		logger.Warning("Failed to check agent status: %v", err)
 	} else if len(pids) > 0 {
+		if err := drainAgent(ctx); err != nil {
+			return err
+		}
 		// Agent is running, stop it to allow binary replacement
 		logger.Progress("Stopping running agent to allow binary replacement")
 		procManager := process.NewProcessManager()
@@ -85,21 +174,31 @@ func runAgentUpgrade(cmd *cobra.Command, args []string) error {
 			agentWasRunning = true
 			logger.Success("Agent stopped successfully (%d process(es) stopped)", stoppedCount)
 		} else {
+			resumeAgent()
 			logger.Warning("Failed to stop agent, attempting upgrade anyway...")
 		}
 	} else {
 		logger.Info("Agent is not running, proceeding with upgrade")
+		summary.Skip("the agent was not running")
 	}
 
 	// Upgrade agent binary
 	logger.Step(4, "Upgrading agent binary")
-	if err := connectivityManager.EnsureLatestAgent(); err != nil {
-		return fmt.Errorf("failed to upgrade agent binary: %w", err)
+	if err := upgradeAgentBinary(ctx, connectivityManager, installedVersion); err != nil {
+		recordVersionChange(ctx, state.ComponentAgent, installedVersion, latestVersion, err)
+		if agentWasRunning {
+			restartPreviousAgent(cmd)
+		}
+		return withDiskSpaceHint(fmt.Errorf("failed to upgrade agent binary: %w", err))
+	}
+	summary.Touch(platformInfo.GetFixPanicAgentBinaryPath())
+	if err := applyPermissions(platformInfo); err != nil {
+		return err
 	}
 
 	// Get new version
 	logger.Progress("Verifying upgrade")
-	newVersion, err := connectivityManager.GetFixPanicAgentVersion()
+	newVersion, err := connectivityManager.GetFixPanicAgentVersion(ctx)
 	if err != nil {
 		logger.Warning("Could not determine new version: %v", err)
 		newVersion = "unknown"
@@ -107,6 +206,10 @@ func runAgentUpgrade(cmd *cobra.Command, args []string) error {
 		logger.KeyValue("New version", newVersion)
 	}
 
+	if newVersion != "unknown" && connectivity.ParseAgentVersion(newVersion) != installedVersion {
+		recordVersionChange(ctx, state.ComponentAgent, installedVersion, connectivity.ParseAgentVersion(newVersion), nil)
+	}
+
 	// Check if upgrade was needed
 	if !forceAgentUpgrade && currentVersion == newVersion && currentVersion != "unknown" {
 		logger.Success("Agent was already on the latest version")
@@ -140,4 +243,72 @@ func runAgentUpgrade(cmd *cobra.Command, args []string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// upgradeAgentBinary installs the release of --agent-version, or the latest
+// release
+func upgradeAgentBinary(ctx context.Context, connectivityManager *connectivity.Manager, installedVersion string) error {
+	if upgradeAgentVersion != "" && !forceAgentUpgrade && normalizeVersion(installedVersion) == normalizeVersion(upgradeAgentVersion) {
+		logger.List("Agent binary is already at %s", upgradeAgentVersion)
+		return nil
+	}
+	checksum, err := agentops.InstallBinary(ctx, connectivityManager, upgradeAgentVersion)
+	if err != nil {
+		return err
+	}
+	logger.KeyValue("SHA-256", checksum)
+	return nil
+}
+
+// restartPreviousAgent starts the agent stopped for an upgrade that failed
+// or was interrupted, so the host keeps running the previous version
+func restartPreviousAgent(cmd *cobra.Command) {
+	logger.Progress("Starting the previous agent again")
+	// Start even though the upgrade was cancelled
+	ctx := cmd.Context()
+	cmd.SetContext(context.WithoutCancel(ctx))
+	defer cmd.SetContext(ctx)
+	if err := agentStartCmd.RunE(cmd, []string{}); err != nil {
+		logger.Warning("Failed to start the previous agent: %v", err)
+		logger.Info("You can start the agent manually with: fixpanic agent start")
+		summary.Record("Start the previous agent", summary.Failed, err.Error())
+		return
+	}
+	summary.Record("Start the previous agent", summary.OK, "")
+}
+
+// checkPackageOwner refuses to replace an agent binary that belongs to a
+// distribution package, unless --takeover is given now or was given before
+// for the same package
+func checkPackageOwner(ctx context.Context, platformInfo *platform.PlatformInfo) error {
+	return agentops.CheckPackageOwner(ctx, platformInfo, agentUpgradeTakeover)
+}
+
+// runAgentUpgradeNotes prints the release notes between the installed and
+// the latest agent version, without upgrading
+func runAgentUpgradeNotes(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+	connectivityManager := connectivity.NewManager(platformInfo)
+
+	installed := ""
+	if output, err := connectivityManager.GetFixPanicAgentVersion(ctx); err == nil {
+		installed = connectivity.ParseAgentVersion(output)
+	}
+	latestVersion, err := connectivityManager.GetLatestAgentVersion(ctx)
+	if err != nil {
+		return clierror.New(clierror.Network, "failed to fetch latest release: %w", err)
+	}
+
+	if installed != "" && normalizeVersion(installed) == normalizeVersion(latestVersion) {
+		logger.Success("Agent is on the latest version (%s)", latestVersion)
+		return nil
+	}
+	if err := showReleaseNotes(ctx, releases.AgentRepo, installed, latestVersion); err != nil {
+		return clierror.New(clierror.Network, "failed to fetch release notes: %w", err)
+	}
+	return nil
+}
@@ -1,16 +1,23 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
 	"github.com/fixpanic/fixpanic-cli/internal/config"
 	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
 	"github.com/fixpanic/fixpanic-cli/internal/logger"
 	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/service"
 	"github.com/spf13/cobra"
 )
 
+var validateFix bool
+
 // agentValidateCmd represents the agent validate command
 var agentValidateCmd = &cobra.Command{
 	Use:   "validate",
@@ -18,45 +25,80 @@ var agentValidateCmd = &cobra.Command{
 	Long: `Validate that the Fixpanic agent is properly installed and configured.
 
 This command checks if the agent binary is installed, configuration is valid,
-and the agent can be started successfully.`,
+and the agent can be started successfully.
+
+--fix repairs the common problems it finds and reports each fix: missing
+directories are created, file modes are corrected (or the permission policy
+is applied), a service unit that starts an old binary or configuration path
+is re-rendered, and a configuration file that isn't valid YAML is restored
+from the newest valid backup (agent.yaml.bak or agent.yaml.v<N>.bak), keeping
+the broken file as agent.yaml.broken.`,
 	Example: `  # Validate agent installation
-  fixpanic agent validate`,
-	RunE: runAgentValidate,
+  fixpanic agent validate
+
+  # Repair what validation finds
+  sudo fixpanic agent validate --fix`,
+	Annotations: map[string]string{annotationMutating: "fix", annotationRequires: requireInstalled + "," + requireLock},
+	RunE:        runAgentValidate,
 }
 
 func init() {
 	agentCmd.AddCommand(agentValidateCmd)
+
+	// Add flags
+	agentValidateCmd.Flags().BoolVar(&validateFix, "fix", false, "Repair missing directories, file modes, a stale service unit and a malformed configuration")
+}
+
+// validation counts the problems found and the fixes applied
+type validation struct {
+	problems int
+	fixes    int
+}
+
+// problem reports a problem that --fix can repair
+func (v *validation) problem(format string, args ...interface{}) {
+	v.problems++
+	fmt.Printf("❌ %s\n", i18n.Sprintf(format, args...))
+}
+
+// fixed reports a fix applied by --fix
+func (v *validation) fixed(format string, args ...interface{}) {
+	v.fixes++
+	fmt.Printf("🔧 %s\n", i18n.Sprintf(format, args...))
 }
 
 func runAgentValidate(cmd *cobra.Command, args []string) error {
 	logger.Header("Validating Agent Installation")
+	ctx := cmd.Context()
+	result := &validation{}
 
 	// Get platform information
 	logger.Step(1, "Detecting platform and configuration")
-	platformInfo, err := platform.GetPlatformInfo()
+	platformInfo, err := commandPlatform()
 	if err != nil {
-		return fmt.Errorf("failed to get platform info: %w", err)
+		return err
 	}
 
-	// Check if FixPanic Agent is installed
+	// The middleware checked that the agent is installed
 	logger.Step(2, "Checking agent binary installation")
 	connectivityManager := connectivity.NewManager(platformInfo)
-	if !connectivityManager.IsFixPanicAgentInstalled() {
-		return fmt.Errorf("FixPanic Agent is not installed. Run 'fixpanic agent install' first")
-	}
-
 	logger.List("FixPanic Agent binary found: %s", connectivityManager.GetBinaryPath())
 
+	if err := validateDirectories(platformInfo, result); err != nil {
+		return err
+	}
+
 	// Load configuration
 	configPath := platformInfo.GetConfigPath()
-	agentConfig, err := config.LoadConfig(configPath)
+	agentConfig, err := loadValidatedConfig(configPath, result)
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return err
 	}
 
 	// Validate configuration
 	if err := agentConfig.Validate(); err != nil {
-		return fmt.Errorf("invalid configuration: %w", err)
+		return clierror.New(clierror.Config, "invalid configuration: %w", err).
+			WithHint(i18n.Sprintf("Fix the reported value in %s", configPath), hintReinstallAgent)
 	}
 
 	fmt.Printf("✅ Configuration is valid: %s\n", configPath)
@@ -64,26 +106,192 @@ func runAgentValidate(cmd *cobra.Command, args []string) error {
 	fmt.Printf("   Log level: %s\n", agentConfig.Logging.Level)
 	fmt.Printf("   Log file: %s\n", agentConfig.Logging.File)
 
-	// Test if binary is executable
-	binaryPath := connectivityManager.GetBinaryPath()
-	if err := os.Chmod(binaryPath, 0755); err != nil {
-		fmt.Printf("⚠️  Could not verify FixPanic Agent permissions: %v\n", err)
-	} else {
-		fmt.Println("✅ FixPanic Agent binary has correct permissions")
+	if err := validatePermissions(platformInfo, connectivityManager, result); err != nil {
+		return err
+	}
+	if err := validateServiceUnit(ctx, platformInfo, result); err != nil {
+		return err
 	}
 
 	// Test version command
-	fmt.Println("\nTesting FixPanic Agent binary...")
-	version, err := connectivityManager.GetFixPanicAgentVersion()
+	fmt.Fprintln(os.Stderr, "\nTesting FixPanic Agent binary...")
+	version, err := connectivityManager.GetFixPanicAgentVersion(ctx)
 	if err != nil {
 		fmt.Printf("⚠️  Could not get FixPanic Agent version: %v\n", err)
 	} else {
 		fmt.Printf("✅ FixPanic Agent version: %s\n", version)
 	}
 
-	fmt.Println("\n✅ FixPanic Agent validation completed successfully!")
-	fmt.Println("The FixPanic Agent appears to be properly installed and configured.")
-	fmt.Println("You can start the agent with: fixpanic agent start")
+	if result.fixes > 0 {
+		fmt.Fprintln(os.Stderr)
+		logger.Success("Applied %d fix(es)", result.fixes)
+	}
+	if result.problems > 0 {
+		return clierror.New(clierror.Config, "validation found %d problem(s)", result.problems).
+			WithHint("Run 'sudo fixpanic agent validate --fix' to repair them")
+	}
+
+	fmt.Fprintln(os.Stderr, "\n✅ FixPanic Agent validation completed successfully!")
+	fmt.Fprintln(os.Stderr, "The FixPanic Agent appears to be properly installed and configured.")
+	fmt.Fprintln(os.Stderr, "You can start the agent with: fixpanic agent start")
+
+	return nil
+}
+
+// validateDirectories checks that the configuration and log directories exist
+func validateDirectories(platformInfo *platform.PlatformInfo, result *validation) error {
+	var missing []string
+	for _, dir := range managedDirs(platformInfo) {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			missing = append(missing, dir)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if !validateFix {
+		for _, dir := range missing {
+			result.problem("Directory %s is missing", dir)
+		}
+		return nil
+	}
+	if err := platformInfo.CreateDirectories(); err != nil {
+		return err
+	}
+	for _, dir := range missing {
+		result.fixed("Created directory %s", dir)
+	}
+	return nil
+}
+
+// loadValidatedConfig loads the configuration; with --fix a file that can't
+// be parsed is restored from its newest valid backup
+func loadValidatedConfig(configPath string, result *validation) (*config.AgentConfig, error) {
+	agentConfig, err := config.LoadConfig(configPath)
+	if err == nil {
+		return agentConfig, nil
+	}
+	loadErr := clierror.New(clierror.Config, "failed to load configuration: %w", err).
+		WithHint(i18n.Sprintf("Check that %s exists and is valid YAML", configPath), hintReinstallAgent)
+	if _, statErr := os.Stat(configPath); statErr != nil {
+		return nil, loadErr
+	}
+	if !validateFix {
+		if backups := config.BackupPaths(configPath); len(backups) > 0 {
+			loadErr.WithHint("Run 'sudo fixpanic agent validate --fix' to restore the newest valid backup")
+		}
+		return nil, loadErr
+	}
+
+	backupPath, restoreErr := config.RestoreBackup(configPath)
+	if restoreErr != nil {
+		return nil, loadErr.WithHint(i18n.Sprintf("No valid backup was found: %v", restoreErr))
+	}
+	result.fixed("Restored %s from %s (the broken file is kept as %s)", configPath, backupPath, configPath+config.BrokenSuffix)
+	agentConfig, err = config.LoadConfig(configPath)
+	if err != nil {
+		return nil, clierror.New(clierror.Config, "failed to load restored configuration: %w", err)
+	}
+	return agentConfig, nil
+}
+
+// validatePermissions checks file modes and ownership against the configured
+// policy; without one, that the binary is executable and the configuration,
+// which holds the API key, is only readable by its owner
+func validatePermissions(platformInfo *platform.PlatformInfo, connectivityManager *connectivity.Manager, result *validation) error {
+	policy, err := permissionPolicy()
+	if err != nil {
+		return err
+	}
+	if !policy.IsZero() {
+		violations, err := checkPermissions(platformInfo)
+		if err != nil {
+			return err
+		}
+		if len(violations) == 0 {
+			fmt.Println("✅ File modes and ownership match the permission policy")
+			return nil
+		}
+		if validateFix {
+			if err := applyPermissions(platformInfo); err != nil {
+				return err
+			}
+			for _, violation := range violations {
+				result.fixed("Applied the permission policy to %s (%s)", violation.Path, violation.Problem)
+			}
+			return nil
+		}
+		fmt.Println("❌ Files do not match the permission policy:")
+		for _, violation := range violations {
+			fmt.Printf("   %s: %s\n", violation.Path, violation.Problem)
+		}
+		return clierror.New(clierror.Config, "%d file(s) violate the permission policy", len(violations)).
+			WithHint(hintReapplyPermissions, "Run 'sudo fixpanic agent validate --fix' to apply it")
+	}
+
+	binaryPath := connectivityManager.GetBinaryPath()
+	if err := os.Chmod(binaryPath, 0755); err != nil {
+		fmt.Printf("⚠️  Could not verify FixPanic Agent permissions: %v\n", err)
+	} else {
+		fmt.Println("✅ FixPanic Agent binary has correct permissions")
+	}
 
+	configPath := platformInfo.GetConfigPath()
+	info, err := os.Stat(configPath)
+	if err != nil || info.Mode().Perm()&0077 == 0 {
+		return nil
+	}
+	if !validateFix {
+		result.problem("%s is readable by other users (mode %04o) and holds the API key", configPath, info.Mode().Perm())
+		return nil
+	}
+	if err := os.Chmod(configPath, 0600); err != nil {
+		return fmt.Errorf("failed to restrict %s: %w", configPath, err)
+	}
+	result.fixed("Restricted %s to mode 0600 (was %04o)", configPath, info.Mode().Perm())
+	return nil
+}
+
+// validateServiceUnit checks that the systemd unit starts the installed
+// binary with the installed configuration, as units written for an older
+// installation layout don't
+func validateServiceUnit(ctx context.Context, platformInfo *platform.PlatformInfo, result *validation) error {
+	if !platform.IsSystemdAvailable() {
+		return nil
+	}
+	unitPath := platformInfo.GetServiceFilePath()
+	data, err := os.ReadFile(unitPath)
+	if err != nil {
+		return nil
+	}
+	serviceManager := service.NewManager(platformInfo)
+	desired, err := serviceManager.Render()
+	if err != nil {
+		return fmt.Errorf("failed to render service unit: %w", err)
+	}
+	actualExec, desiredExec := unitExecStart(string(data)), unitExecStart(desired)
+	if actualExec == desiredExec {
+		fmt.Println("✅ Service unit starts the installed agent")
+		return nil
+	}
+	if !validateFix {
+		result.problem("%s starts '%s' instead of '%s'", unitPath, actualExec, desiredExec)
+		return nil
+	}
+	if err := serviceManager.Install(ctx); err != nil {
+		return fmt.Errorf("failed to install service unit: %w", err)
+	}
+	result.fixed("Re-rendered %s to start '%s'", unitPath, desiredExec)
+	logger.Info("Run 'fixpanic agent restart' for the service to use the new unit")
 	return nil
 }
+
+// unitExecStart returns the command of the ExecStart line of a unit
+func unitExecStart(unit string) string {
+	for _, line := range strings.Split(unit, "\n") {
+		if command, ok := strings.CutPrefix(strings.TrimSpace(line), "ExecStart="); ok {
+			return strings.TrimSpace(command)
+		}
+	}
+	return ""
+}
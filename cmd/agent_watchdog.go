@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/process"
+	"github.com/fixpanic/fixpanic-cli/internal/watchdog"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchdogMaxLogSize  int
+	watchdogMaxLogFiles int
+	watchdogMaxBackoff  time.Duration
+	watchdogHealthAddr  string
+)
+
+// agentWatchdogCmd represents the agent watchdog command
+var agentWatchdogCmd = &cobra.Command{
+	Use:   "watchdog",
+	Short: "Supervise the agent without systemd",
+	Long: `Run a lightweight supervisor that keeps the Fixpanic agent running.
+
+Intended for hosts without init system integration such as containers. The
+watchdog runs in the foreground, starts the agent, restarts it with
+exponential backoff when it exits, and rotates the agent log file.
+
+Its state is written to a status file that 'fixpanic agent status' reads.
+Load balancers and local probes can check the agent with a plain HTTP call:
+GET /healthz answers "ok" or 503 when the agent is unhealthy, and
+GET /status.json the health check and watchdog state as JSON, on
+127.0.0.1:7879 by default.
+
+Stop it with Ctrl+C, SIGTERM, or 'fixpanic agent stop'.`,
+	Example: `  # Supervise the agent (e.g. as a container entrypoint)
+  fixpanic agent watchdog
+
+  # Keep up to 10 log files of 100MB each
+  fixpanic agent watchdog --max-log-size=100 --max-log-files=10
+
+  # Serve the health endpoints to the container network, e.g. for a load balancer
+  fixpanic agent watchdog --health-listen 0.0.0.0:7879`,
+	Annotations: map[string]string{annotationMutating: "true"},
+	RunE:        runAgentWatchdog,
+}
+
+func init() {
+	agentCmd.AddCommand(agentWatchdogCmd)
+
+	// Add flags
+	agentWatchdogCmd.Flags().IntVar(&watchdogMaxLogSize, "max-log-size", 50, "Rotate the agent log after this many megabytes")
+	agentWatchdogCmd.Flags().IntVar(&watchdogMaxLogFiles, "max-log-files", 5, "Number of rotated log files to keep")
+	agentWatchdogCmd.Flags().DurationVar(&watchdogMaxBackoff, "max-backoff", 5*time.Minute, "Maximum delay between restarts")
+	agentWatchdogCmd.Flags().StringVar(&watchdogHealthAddr, "health-listen", "127.0.0.1:7879", "Address to serve /healthz and /status.json on (empty to disable)")
+}
+
+func runAgentWatchdog(cmd *cobra.Command, args []string) error {
+	logger.Header("FixPanic Agent Watchdog")
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	if _, err := validateAgentInstall(platformInfo); err != nil {
+		return err
+	}
+
+	statusPath := platformInfo.GetWatchdogStatusPath()
+	if status, err := watchdog.ReadStatus(statusPath); err == nil && status.IsAlive() && status.PID != os.Getpid() {
+		return clierror.New(clierror.General, "another watchdog is already running (PID: %d)", status.PID).
+			WithHint("Run 'fixpanic agent stop' to stop the running watchdog first")
+	}
+
+	logger.Step(2, "Checking for existing agent processes")
+	if err := cleanUpOldAgents(); err != nil {
+		return err
+	}
+
+	supervisor := watchdog.NewSupervisor(watchdog.Config{
+		BinaryPath:     platformInfo.GetFixPanicAgentBinaryPath(),
+		Args:           []string{"--config", platformInfo.GetConfigPath()},
+		LogPath:        platformInfo.GetLogPath(),
+		StatusPath:     statusPath,
+		StopMarkerPath: platformInfo.GetPanicStopPath(),
+		MaxLogSize:     int64(watchdogMaxLogSize) * 1024 * 1024,
+		MaxLogBackups:  watchdogMaxLogFiles,
+		InitialBackoff: time.Second,
+		MaxBackoff:     watchdogMaxBackoff,
+	})
+
+	logger.Step(3, "Supervising agent")
+	logger.KeyValue("Watchdog PID", fmt.Sprintf("%d", os.Getpid()))
+	logger.KeyValue("Log file", platformInfo.GetLogPath())
+	logger.KeyValue("Status file", statusPath)
+	if watchdogHealthAddr != "" {
+		serveHealthEndpoints(cmd.Context(), platformInfo, watchdogHealthAddr)
+	}
+
+	if err := supervisor.Run(cmd.Context()); err != nil {
+		return fmt.Errorf("watchdog failed: %w", err)
+	}
+
+	logger.Success("Watchdog stopped")
+	return nil
+}
+
+// readWatchdogStatus returns the status of a live watchdog, or nil if none is running
+func readWatchdogStatus(platformInfo *platform.PlatformInfo) *watchdog.Status {
+	status, err := watchdog.ReadStatus(platformInfo.GetWatchdogStatusPath())
+	if err != nil || !status.IsAlive() {
+		return nil
+	}
+	return status
+}
+
+// stopWatchdog stops a running watchdog so it doesn't restart the agent behind our back
+func stopWatchdog(ctx context.Context) error {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	status := readWatchdogStatus(platformInfo)
+	if status == nil {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Stopping agent watchdog (PID: %d)...\n", status.PID)
+	procManager := process.NewProcessManager()
+	if err := procManager.StopProcess(status.PID); err != nil {
+		return fmt.Errorf("failed to stop watchdog: %w", err)
+	}
+
+	// Give the watchdog time to stop the agent it supervises
+	for i := 0; i < 20 && procManager.IsProcessRunning(status.PID); i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+
+	return nil
+}
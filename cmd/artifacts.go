@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"runtime"
+
+	"github.com/fixpanic/fixpanic-cli/internal/artifacts"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/spf13/cobra"
+)
+
+// artifactsCmd represents the artifacts command
+var artifactsCmd = &cobra.Command{
+	Use:   "artifacts",
+	Short: "Prepare agent builds for offline installation",
+	Long: `Fetch and verify agent builds on an internet-connected machine so they can be
+copied to air-gapped hosts and installed with 'fixpanic agent install --artifact-dir'.`,
+}
+
+func init() {
+	rootCmd.AddCommand(artifactsCmd)
+}
+
+// bundleArtifact returns the manifest of the bundle in dir and the agent build
+// in it that this host needs
+func bundleArtifact(dir string) (*artifacts.Manifest, *artifacts.Artifact, error) {
+	manifest, err := artifacts.Load(dir)
+	if err != nil {
+		return nil, nil, clierror.WithHint(clierror.Wrap(clierror.Usage, err),
+			"Create the bundle with 'fixpanic artifacts pull --dest "+dir+"'")
+	}
+
+	assetName, err := platform.GetFixPanicAgentAssetName()
+	if err != nil {
+		return nil, nil, err
+	}
+	artifact := manifest.Find(assetName)
+	if artifact == nil {
+		return nil, nil, clierror.New(clierror.Config, "bundle %s has no %s build", dir, assetName).
+			WithHint("Add it with 'fixpanic artifacts pull --version " + manifest.Version + " --platform " + runtime.GOOS + "/" + runtime.GOARCH + " --dest " + dir + "'")
+	}
+	return manifest, artifact, nil
+}
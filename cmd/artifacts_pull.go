@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/artifacts"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pullVersion   string
+	pullPlatforms []string
+	pullDest      string
+)
+
+// artifactsPullCmd represents the artifacts pull command
+var artifactsPullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Download and verify agent builds into a bundle directory",
+	Long: `Download the agent builds of a release into a bundle directory that can be
+copied to hosts without internet access.
+
+Every build for the given platforms is pulled, including the musl and 32-bit
+ARM variants, since the libc and ARM version of the target hosts can't be
+detected from here. Each download is verified against the release's checksum
+manifest (SHA256SUMS), whose signature must match a release signing key built
+into the CLI, and the checksums are written to manifest.json in the bundle.
+A release without a signed manifest is not pulled.
+
+Pulling into an existing bundle of the same release adds the platforms to it.
+On the target host, install from the bundle with:
+
+  fixpanic agent install --artifact-dir ./bundle --agent-id=... --api-key=...`,
+	Example: `  # Bundle the latest release for this platform
+  fixpanic artifacts pull --dest ./bundle
+
+  # Bundle a pinned release for Linux servers on x86 and ARM
+  fixpanic artifacts pull --version v1.4.0 --platform linux/amd64,linux/arm64 --dest ./bundle`,
+	RunE: runArtifactsPull,
+}
+
+func init() {
+	artifactsCmd.AddCommand(artifactsPullCmd)
+
+	// Add flags
+	artifactsPullCmd.Flags().StringVar(&pullVersion, "version", "latest", "Agent release to pull")
+	artifactsPullCmd.Flags().StringSliceVar(&pullPlatforms, "platform", []string{runtime.GOOS + "/" + runtime.GOARCH}, "Platforms to pull as GOOS/GOARCH, comma separated or repeated")
+	artifactsPullCmd.Flags().StringVar(&pullDest, "dest", "", "Bundle directory to write (required)")
+	artifactsPullCmd.MarkFlagRequired("dest")
+}
+
+func runArtifactsPull(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	// Validate every platform before downloading anything
+	assetNames := map[string][]string{}
+	for _, target := range pullPlatforms {
+		goos, goarch, ok := strings.Cut(target, "/")
+		if !ok {
+			return clierror.New(clierror.Usage, "invalid platform %q: expected GOOS/GOARCH, e.g. linux/amd64", target)
+		}
+		names, err := platform.FixPanicAgentAssetNames(goos, goarch)
+		if err != nil {
+			return clierror.Wrap(clierror.Usage, err)
+		}
+		assetNames[target] = names
+	}
+
+	logger.Header("Pulling FixPanic Agent Artifacts")
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+	connectivityManager := connectivity.NewManager(platformInfo)
+
+	logger.Loading("Fetching release %s", pullVersion)
+	release, err := connectivityManager.GetAgentRelease(ctx, pullVersion)
+	if err != nil {
+		logger.LoadingFailed("Failed to fetch release")
+		return clierror.Wrap(clierror.Network, err)
+	}
+	logger.LoadingDone("Release %s", release.TagName)
+
+	logger.Loading("Fetching the checksums of %s", release.TagName)
+	checksums, err := connectivityManager.ChecksumManifest(ctx, release.TagName)
+	if err != nil {
+		logger.LoadingFailed("Failed to verify the checksum manifest")
+		return clierror.WithHint(clierror.Classify(clierror.Network, err),
+			"Releases published before signed checksum manifests can't be pulled")
+	}
+	logger.LoadingDone("Checksum manifest signature verified")
+
+	manifest, err := artifacts.Load(pullDest)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		manifest = &artifacts.Manifest{FormatVersion: artifacts.FormatVersion, Version: release.TagName}
+	case err != nil:
+		return clierror.Wrap(clierror.Usage, err)
+	case manifest.Version != release.TagName:
+		return clierror.New(clierror.Usage, "%s already holds a bundle of %s", pullDest, manifest.Version).
+			WithHint("Use a separate --dest directory for each release")
+	}
+	manifest.CreatedAt = time.Now().UTC()
+	manifest.CLIVersion = getCurrentVersion()
+
+	if err := os.MkdirAll(pullDest, 0755); err != nil {
+		return fmt.Errorf("failed to create bundle directory: %w", err)
+	}
+
+	for _, target := range pullPlatforms {
+		pulled := 0
+		for _, name := range assetNames[target] {
+			asset := release.Asset(name)
+			if asset == nil {
+				// Not every variant is built for every platform
+				continue
+			}
+			expected := checksums.Checksum(name)
+			if expected == "" {
+				return clierror.New(clierror.Integrity, "the checksum manifest of %s does not list %s", release.TagName, name)
+			}
+
+			logger.Loading("Downloading %s", name)
+			checksum, size, err := connectivityManager.DownloadArtifact(ctx, asset.BrowserDownloadURL, filepath.Join(pullDest, name), expected)
+			if err != nil {
+				logger.LoadingFailed("Failed to download %s", name)
+				return clierror.Classify(clierror.Network, err)
+			}
+			if asset.Size > 0 && size != asset.Size {
+				os.Remove(filepath.Join(pullDest, name))
+				logger.LoadingFailed("Failed to download %s", name)
+				return clierror.New(clierror.Network, "%s is %d bytes, the release lists %d", name, size, asset.Size)
+			}
+			logger.LoadingDone("%s verified", name)
+
+			manifest.Add(artifacts.Artifact{
+				Name:     name,
+				Platform: target,
+				URL:      asset.BrowserDownloadURL,
+				SHA256:   checksum,
+				Size:     size,
+			})
+			pulled++
+		}
+		if pulled == 0 {
+			return clierror.New(clierror.Config, "release %s has no agent build for %s", release.TagName, target)
+		}
+	}
+
+	// Check what actually landed on disk before declaring the bundle good
+	for i := range manifest.Artifacts {
+		if err := artifacts.Verify(pullDest, &manifest.Artifacts[i]); err != nil {
+			return err
+		}
+	}
+	if err := manifest.Save(pullDest); err != nil {
+		return err
+	}
+
+	logger.Separator()
+	logger.Success("Bundle of %s ready in %s", release.TagName, pullDest)
+	logger.KeyValue("Artifacts", fmt.Sprintf("%d", len(manifest.Artifacts)))
+	logger.KeyValue("Manifest", filepath.Join(pullDest, artifacts.ManifestName))
+	logger.Info("Copy the directory to the target hosts and install with:")
+	logger.Command("fixpanic agent install --artifact-dir " + pullDest + " --agent-id=<id> --api-key=<key>")
+	return nil
+}
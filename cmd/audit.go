@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/audit"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// annotationMutating marks commands that change the installation and must be
+// audited. The value is "true", or the name of a boolean flag for commands that
+// only change the installation when that flag is set, or "!" and the name of a
+// boolean flag for commands that don't change it when the flag is set.
+const annotationMutating = "fixpanic.mutating"
+
+var (
+	auditSince string
+	auditJSON  bool
+)
+
+// auditCmd represents the audit command group
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the audit log of CLI actions",
+	Long: `Inspect the audit log of mutating CLI actions.
+
+Every command that changes the agent installation (install, upgrade, start,
+stop, restart, uninstall, ...) is recorded with the invoking user, timestamp,
+arguments (secrets redacted) and result in an append-only file in the log
+directory.`,
+}
+
+// auditListCmd represents the audit list command
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded CLI actions",
+	Example: `  # Show actions from the last 7 days
+  fixpanic audit list --since 7d
+
+  # Export the full audit trail as JSON
+  fixpanic audit list --json`,
+	RunE: runAuditList,
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditListCmd)
+
+	// Add flags
+	auditListCmd.Flags().StringVar(&auditSince, "since", "", "Only show actions newer than this age (e.g. 30m, 24h, 7d)")
+	auditListCmd.Flags().BoolVar(&auditJSON, "json", false, "Output entries as JSON")
+}
+
+func runAuditList(cmd *cobra.Command, args []string) error {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	since, err := audit.ParseSince(auditSince, time.Now())
+	if err != nil {
+		return err
+	}
+
+	entries, err := audit.Read(getAuditLogPath(platformInfo), since)
+	if err != nil {
+		return err
+	}
+
+	if auditJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if entries == nil {
+			entries = []audit.Entry{}
+		}
+		return encoder.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		logger.Info("No audited actions found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tUSER\tCOMMAND\tRESULT\tDURATION")
+	for _, entry := range entries {
+		who := entry.User
+		if entry.SudoUser != "" {
+			who = fmt.Sprintf("%s (sudo: %s)", entry.User, entry.SudoUser)
+		}
+		command := strings.TrimSpace(entry.Command + " " + strings.Join(entry.Args, " "))
+		result := entry.Result
+		if entry.Error != "" {
+			result = fmt.Sprintf("%s: %s", entry.Result, entry.Error)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%dms\n", entry.Time.Local().Format("2006-01-02 15:04:05"), who, command, result, entry.DurationMS)
+	}
+	return w.Flush()
+}
+
+// getAuditLogPath returns the location of the audit log
+func getAuditLogPath(platformInfo *platform.PlatformInfo) string {
+	return filepath.Join(platformInfo.LogDir, audit.FileName)
+}
+
+// isMutating reports whether cmd changes the installation when run with its
+// current flags
+func isMutating(cmd *cobra.Command) bool {
+	value := cmd.Annotations[annotationMutating]
+	if value == "" {
+		return false
+	}
+	return flagCondition(cmd, value)
+}
+
+// flagCondition evaluates a condition of an annotation: "true", the name of
+// a boolean flag that must be set, or "!" and the name of one that must not
+func flagCondition(cmd *cobra.Command, value string) bool {
+	if value == "true" {
+		return true
+	}
+	flag, negated := strings.CutPrefix(value, "!")
+	set, err := cmd.Flags().GetBool(flag)
+	if err != nil {
+		return negated
+	}
+	return set != negated
+}
+
+// auditDisabled suppresses the audit entry of the current command, e.g. after
+// 'self uninstall' removed the audit log
+var auditDisabled bool
+
+// recordAudit appends an audit entry if the executed command is mutating
+func recordAudit(executed *cobra.Command, started time.Time, runErr error) {
+	if executed == nil || !isMutating(executed) || auditDisabled {
+		return
+	}
+	appendAuditEntry(executed.CommandPath(), auditArgs(executed), started, runErr)
+}
+
+// appendAuditEntry records an action run by the CLI, on the command line or
+// on behalf of an API client
+func appendAuditEntry(command string, args []string, started time.Time, runErr error) {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return
+	}
+
+	entry := audit.Entry{
+		Time:       started.UTC(),
+		PID:        os.Getpid(),
+		Command:    command,
+		Args:       args,
+		Result:     audit.ResultSuccess,
+		DurationMS: time.Since(started).Milliseconds(),
+		CLIVersion: getCurrentVersion(),
+		SudoUser:   os.Getenv("SUDO_USER"),
+	}
+	if currentUser, err := user.Current(); err == nil {
+		entry.User = currentUser.Username
+		entry.UID = currentUser.Uid
+	}
+	entry.Hostname, _ = os.Hostname()
+	if runErr != nil {
+		entry.Result = audit.ResultFailure
+		entry.Error = runErr.Error()
+	}
+
+	if err := audit.Append(getAuditLogPath(platformInfo), entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record audit entry: %v\n", err)
+	}
+}
+
+// auditArgs returns the changed flags and positional arguments with secrets redacted
+func auditArgs(executed *cobra.Command) []string {
+	var args []string
+	executed.Flags().Visit(func(flag *pflag.Flag) {
+		value := flag.Value.String()
+		if audit.IsSecretName(flag.Name) {
+			value = audit.Redacted
+		}
+		args = append(args, fmt.Sprintf("--%s=%s", flag.Name, value))
+	})
+
+	// Positional key/value pairs such as "config set app.api_key <key>" carry
+	// the secret in the argument following its name
+	positional := executed.Flags().Args()
+	for i, arg := range positional {
+		if i > 0 && audit.IsSecretName(positional[i-1]) {
+			arg = audit.Redacted
+		}
+		args = append(args, arg)
+	}
+	return args
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/cleanup"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/diskspace"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanupDryRun bool
+	cleanupMaxAge time.Duration
+)
+
+// cleanupTempCmd represents the cleanup-temp command
+var cleanupTempCmd = &cobra.Command{
+	Use:   "cleanup-temp",
+	Short: "Remove temporary files left behind by interrupted runs",
+	Long: `Find and remove stale temporary files left behind by interrupted upgrades
+and downloads:
+
+  - fixpanic-upgrade-* directories in the system temp directory
+  - partial *.tmp agent downloads next to the agent binary
+
+Only files older than --older-than are touched, so operations that are still
+running are left alone.`,
+	Example: `  # Show what would be removed
+  fixpanic cleanup-temp --dry-run
+
+  # Remove leftovers older than a week
+  sudo fixpanic cleanup-temp --older-than=168h`,
+	Annotations: map[string]string{annotationMutating: "!dry-run", annotationRequires: requireLock},
+	RunE:        runCleanupTemp,
+}
+
+func init() {
+	rootCmd.AddCommand(cleanupTempCmd)
+
+	// Add flags
+	cleanupTempCmd.Flags().BoolVar(&cleanupDryRun, "dry-run", false, "List stale files without removing them")
+	cleanupTempCmd.Flags().DurationVar(&cleanupMaxAge, "older-than", cleanup.DefaultMaxAge, "Only remove files last modified longer ago than this")
+	cleanupTempCmd.Flags().DurationVar(&lockTimeout, "lock-timeout", 0, "How long to wait for another fixpanic operation to finish (0 fails immediately)")
+}
+
+// tempFilePatterns returns the kinds of leftovers interrupted runs produce
+func tempFilePatterns(platformInfo *platform.PlatformInfo) []cleanup.Pattern {
+	return []cleanup.Pattern{
+		{Glob: filepath.Join(os.TempDir(), "fixpanic-upgrade-*"), Dir: true},
+		{Glob: filepath.Join(platformInfo.LibDir, "*.tmp")},
+	}
+}
+
+// findStaleTempFiles returns leftovers older than maxAge
+func findStaleTempFiles(platformInfo *platform.PlatformInfo, maxAge time.Duration) ([]cleanup.Leftover, error) {
+	return cleanup.Find(tempFilePatterns(platformInfo), maxAge, time.Now())
+}
+
+func runCleanupTemp(cmd *cobra.Command, args []string) error {
+	logger.Header("Cleaning Up Temporary Files")
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	leftovers, err := findStaleTempFiles(platformInfo, cleanupMaxAge)
+	if err != nil {
+		return fmt.Errorf("failed to scan for temporary files: %w", err)
+	}
+
+	if len(leftovers) == 0 {
+		logger.Success("No stale temporary files found")
+		return nil
+	}
+
+	var removed int
+	var freed int64
+	for _, leftover := range leftovers {
+		age := time.Since(leftover.ModTime).Round(time.Hour)
+		logger.KeyValue(leftover.Path, fmt.Sprintf("%s, %s old", formatSize(leftover.Size), age))
+		if cleanupDryRun {
+			continue
+		}
+
+		if err := cleanup.Remove(leftover); err != nil {
+			logger.Warning("Failed to remove %s: %v", leftover.Path, err)
+			continue
+		}
+		removed++
+		freed += leftover.Size
+	}
+
+	logger.Separator()
+	if cleanupDryRun {
+		logger.Info("Dry run: %d item(s) would be removed. Run without --dry-run to remove them.", len(leftovers))
+		return nil
+	}
+
+	logger.Success("Removed %d item(s), freed %s", removed, formatSize(freed))
+	if removed < len(leftovers) {
+		return fmt.Errorf("failed to remove %d item(s)", len(leftovers)-removed)
+	}
+	return nil
+}
+
+// formatSize renders a byte count for humans
+func formatSize(bytes int64) string {
+	return diskspace.FormatBytes(uint64(bytes))
+}
+
+// withDiskSpaceHint tells the user how to make room when err reports that a
+// download doesn't fit on disk. Other errors are returned unchanged.
+func withDiskSpaceHint(err error) error {
+	var insufficient *diskspace.InsufficientError
+	if !errors.As(err, &insufficient) {
+		return err
+	}
+	return clierror.WithHint(err,
+		i18n.Sprintf("Free up space in %s", insufficient.Path),
+		"Run 'fixpanic cleanup-temp' to remove files left behind by interrupted runs")
+}
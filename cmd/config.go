@@ -0,0 +1,356 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/fixpanic/fixpanic-cli/internal/audit"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var migrateDryRun bool
+
+// configCmd represents the config command group
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the agent and CLI configuration",
+	Long: `Manage the agent configuration file.
+
+Values can be changed with 'fixpanic config set' and checked with 'fixpanic
+config test'; 'fixpanic config profiles' lists the profiles 'fixpanic agent
+install --profile' accepts.
+
+The configuration carries a config_version. Older versions are upgraded
+automatically whenever the CLI loads them, keeping a backup of the original
+file next to it (agent.yaml.v<N>.bak).
+
+With --cli, 'fixpanic config' lists and 'fixpanic config set' changes the
+preferences of the CLI itself instead, kept per user apart from the agent
+configuration in cli.yaml of the user's configuration directory, e.g.
+~/.config/fixpanic/cli.yaml, or the file $FIXPANIC_CLI_CONFIG names:
+
+  update_channel   release channel 'fixpanic upgrade' follows: stable or beta
+  color            auto (follow NO_COLOR and CLICOLOR), always or never
+  output           format of commands that can write JSON: text or json
+  proxy            HTTP proxy for the CLI's own downloads, unless HTTPS_PROXY
+                   or HTTP_PROXY are set
+  update_notifier  notices about newer agent releases: true or false
+  telemetry        anonymous usage telemetry: true or false
+
+An empty value resets a setting to its default.`,
+	Example: `  # List the CLI settings
+  fixpanic config --cli
+
+  # Follow beta releases and write JSON by default
+  fixpanic config set --cli update_channel beta
+  fixpanic config set --cli output json`,
+	Args: subcommandArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !configCLI {
+			return cmd.Help()
+		}
+		return runConfigCLIShow(cmd)
+	},
+}
+
+// configMigrateCmd represents the config migrate command
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the agent configuration to the current schema",
+	Example: `  # Show which migrations would run
+  fixpanic config migrate --dry-run
+
+  # Upgrade the configuration (a backup is kept)
+  sudo fixpanic config migrate`,
+	Annotations: map[string]string{annotationMutating: "!dry-run", annotationRequires: requireInstalled + "," + requireLock},
+	RunE:        runConfigMigrate,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configMigrateCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configProfilesCmd)
+	configCmd.AddCommand(configTestCmd)
+
+	// Add flags
+	configCmd.PersistentFlags().DurationVar(&lockTimeout, "lock-timeout", 0, "How long to wait for another fixpanic operation to finish (0 fails immediately)")
+	configMigrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Show the migrations and resulting configuration without writing")
+	configCmd.Flags().BoolVar(&configCLI, "cli", false, "List the settings of the CLI itself instead of managing the agent configuration")
+	configSetCmd.Flags().BoolVar(&configCLI, "cli", false, "Change a setting of the CLI itself instead of the agent configuration")
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	logger.Header("Migrating Agent Configuration")
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	configPath := platformInfo.GetConfigPath()
+	logger.KeyValue("Configuration file", configPath)
+
+	result, err := config.Migrate(configPath, migrateDryRun)
+	if err != nil {
+		return clierror.New(clierror.Config, "failed to migrate configuration: %w", err)
+	}
+
+	if !result.NeedsMigration() {
+		logger.Success("Configuration is already at version %d", result.ToVersion)
+		return nil
+	}
+
+	logger.KeyValue("Version", fmt.Sprintf("%d → %d", result.FromVersion, result.ToVersion))
+	for _, description := range result.Applied {
+		logger.List("%s", description)
+	}
+
+	if migrateDryRun {
+		preview := *result.Config
+		if preview.App.APIKey != "" {
+			preview.App.APIKey = audit.Redacted
+		}
+		data, err := yaml.Marshal(&preview)
+		if err != nil {
+			return fmt.Errorf("failed to render migrated configuration: %w", err)
+		}
+
+		logger.Separator()
+		logger.Info("Resulting configuration:")
+		fmt.Print(string(data))
+		logger.Separator()
+		logger.Info("Dry run: nothing was written. Run without --dry-run to apply.")
+		return nil
+	}
+
+	logger.Success("Configuration migrated to version %d", result.ToVersion)
+	logger.KeyValue("Backup", result.BackupPath)
+	return nil
+}
+
+// persistConfigMigration upgrades the configuration file at configPath to the
+// current version, keeping a backup, for install and upgrade to run while
+// they hold the installation lock
+func persistConfigMigration(configPath string) error {
+	result, err := agentops.PersistMigration(configPath)
+	if err != nil {
+		return clierror.New(clierror.Config, "failed to migrate configuration: %w", err).
+			WithHint("Preview the migration with 'fixpanic config migrate --dry-run'")
+	}
+	if result != nil && result.NeedsMigration() {
+		logger.Info("Configuration migrated from version %d to %d (backup: %s)", result.FromVersion, result.ToVersion, result.BackupPath)
+	}
+	return nil
+}
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a value in the agent configuration",
+	Long:  configSetHelp(),
+	Example: `  # Allow more concurrent connections
+  sudo fixpanic config set req_handler.max_concurrent_connections 25
+
+  # Turn on debug logging
+  sudo fixpanic config set logging.level debug
+
+  # Start the agent only once the WireGuard tunnel is up
+  sudo fixpanic config set service.requires wg-quick@wg0.service
+
+  # Send the agent's traffic through a proxy
+  sudo fixpanic config set service.environment HTTPS_PROXY=http://proxy.internal:3128
+
+  # Send the CLI's own downloads through a proxy
+  fixpanic config set --cli proxy http://proxy.internal:3128`,
+	Args:        cobra.ExactArgs(2),
+	Annotations: map[string]string{annotationMutating: "!cli", annotationRequires: unless(requireInstalled, "cli") + "," + unless(requireConfig, "cli") + "," + requireLock},
+	RunE:        runConfigSet,
+}
+
+// configProfilesCmd represents the config profiles command
+var configProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List the configuration profiles shipped with the CLI",
+	Long: `List the configuration profiles that can be selected with
+'fixpanic agent install --profile'.`,
+	RunE: runConfigProfiles,
+}
+
+// configTestCmd represents the config test command
+var configTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Check the agent configuration without starting the agent",
+	Long: `Validate the agent configuration and read the files it refers to: the CA
+bundle (app.tls_ca_file) must contain PEM certificates, and the client
+certificate and key (app.tls_cert_file, app.tls_key_file) must be set
+together, match and not be expired. Public key pins (app.tls_pinned_spki)
+must have the sha256/<base64> form 'fixpanic agent test-connection' shows.
+
+Run it after changing TLS settings with 'fixpanic config set', before
+restarting the agent.`,
+	Example: `  # Trust a private CA and check the result
+  sudo fixpanic config set app.tls_ca_file /etc/pki/corp-ca.pem
+  sudo fixpanic config test`,
+	Annotations: map[string]string{annotationRequires: requireInstalled + "," + requireConfig},
+	RunE:        runConfigTest,
+}
+
+// certExpiryWarning is how long before expiry a client certificate is reported
+const certExpiryWarning = 30 * 24 * time.Hour
+
+func runConfigTest(cmd *cobra.Command, args []string) error {
+	logger.Header("Testing Agent Configuration")
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	configPath := platformInfo.GetConfigPath()
+	logger.KeyValue("Configuration file", configPath)
+
+	agentConfig := commandConfig()
+	if err := agentConfig.Validate(); err != nil {
+		return clierror.New(clierror.Config, "invalid configuration: %w", err)
+	}
+
+	app := agentConfig.App
+	material, err := app.LoadTLSMaterial()
+	if err != nil {
+		return clierror.New(clierror.Config, "invalid TLS configuration: %w", err).
+			WithHint("Fix the paths with 'fixpanic config set app.tls_ca_file|app.tls_cert_file|app.tls_key_file <path>'")
+	}
+
+	now := time.Now()
+	var problems int
+	if !app.TLSEnabled {
+		logger.Warning("TLS is disabled; the agent's traffic is not encrypted")
+		if app.TLSCAFile != "" || app.TLSCertFile != "" || len(app.TLSPinnedSPKI) > 0 {
+			logger.Warning("The CA bundle, client certificate and pins are ignored while TLS is disabled")
+		}
+	}
+	if len(app.TLSPinnedSPKI) > 0 {
+		logger.KeyValue("Pinned keys", strings.Join(app.TLSPinnedSPKI, ", "))
+	}
+
+	if app.TLSCAFile == "" {
+		logger.KeyValue("CA certificates", "system roots")
+	} else {
+		logger.KeyValue("CA bundle", fmt.Sprintf("%s (%d certificate(s))", app.TLSCAFile, len(material.CACertificates)))
+		for _, cert := range material.CACertificates {
+			if now.After(cert.NotAfter) {
+				problems++
+				logger.Error("CA certificate %q expired on %s", cert.Subject.CommonName, cert.NotAfter.Local().Format("2006-01-02"))
+			}
+		}
+	}
+
+	if cert := material.ClientCertificate; cert != nil {
+		logger.KeyValue("Client certificate", fmt.Sprintf("%s (%s)", cert.Subject.CommonName, app.TLSCertFile))
+		logger.KeyValue("Expires", cert.NotAfter.Local().Format("2006-01-02"))
+		switch {
+		case now.After(cert.NotAfter):
+			problems++
+			logger.Error("Client certificate expired on %s", cert.NotAfter.Local().Format("2006-01-02"))
+		case now.Before(cert.NotBefore):
+			problems++
+			logger.Error("Client certificate is not valid until %s", cert.NotBefore.Local().Format("2006-01-02 15:04"))
+		case cert.NotAfter.Sub(now) < certExpiryWarning:
+			logger.Warning("Client certificate expires in %d day(s)", int(cert.NotAfter.Sub(now).Hours()/24))
+		}
+		if material.KeyMode&0077 != 0 {
+			logger.Warning("Client key %s is readable by other users (mode %04o); run 'chmod 600 %s'", app.TLSKeyFile, material.KeyMode, app.TLSKeyFile)
+		}
+	}
+
+	if problems > 0 {
+		return clierror.New(clierror.Config, "configuration has %d TLS problem(s)", problems).
+			WithHint("Replace the certificates and run 'fixpanic config test' again")
+	}
+	logger.Success("Configuration is valid")
+	return nil
+}
+
+// configSetHelp renders the long help of config set, listing the known keys
+func configSetHelp() string {
+	var b strings.Builder
+	b.WriteString("Change a single value in the agent configuration, e.g. to tune the limits\n")
+	b.WriteString("of a profile selected at install time. The value is checked against the\n")
+	b.WriteString("type of the key and the configuration is validated before it is saved.\n")
+	b.WriteString("Restart the agent for the change to take effect.\n\n")
+	b.WriteString("The service.* keys configure the generated service unit: after, wants and\n")
+	b.WriteString("requires take comma-separated systemd units the agent starts after (and\n")
+	b.WriteString("depends on), environment takes comma-separated NAME=value pairs and\n")
+	b.WriteString("environment_file an absolute path, and confine turns the system call filter\n")
+	b.WriteString("and AppArmor profile on or off. They are applied by regenerating the unit\n")
+	b.WriteString("with 'fixpanic agent diff --accept'.\n\n")
+	b.WriteString("With --cli the key is a setting of the CLI itself instead; see 'fixpanic\n")
+	b.WriteString("config --help'.\n\nKeys:\n")
+	for _, key := range config.Keys() {
+		fmt.Fprintf(&b, "  %s\n", key)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	key, value := args[0], args[1]
+	if configCLI {
+		return runConfigSetCLI(cmd, key, value)
+	}
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	configPath := platformInfo.GetConfigPath()
+	agentConfig := commandConfig()
+
+	previous, err := agentConfig.Get(key)
+	if err != nil {
+		return clierror.Wrap(clierror.Usage, err)
+	}
+	if err := agentConfig.Set(key, value); err != nil {
+		return clierror.Wrap(clierror.Usage, err)
+	}
+	if err := agentConfig.Validate(); err != nil {
+		return clierror.New(clierror.Config, "invalid configuration: %w", err)
+	}
+
+	if err := config.SaveConfig(agentConfig, configPath); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	current, _ := agentConfig.Get(key)
+	if key == "app.api_key" {
+		previous, current = audit.Redacted, audit.Redacted
+	}
+
+	logger.Success("Updated %s", key)
+	logger.KeyValue("Old value", previous)
+	logger.KeyValue("New value", current)
+	if strings.HasPrefix(key, "service.") {
+		logger.Info("Run 'fixpanic agent diff --accept' to regenerate the service unit")
+		return nil
+	}
+	if strings.HasPrefix(key, "app.tls_") {
+		logger.Info("Run 'fixpanic config test' to check the TLS settings")
+	}
+	logger.Info("Run 'fixpanic agent restart' for the agent to pick up the changes")
+	return nil
+}
+
+func runConfigProfiles(cmd *cobra.Command, args []string) error {
+	for _, profile := range config.Profiles() {
+		fmt.Printf("%-16s %s\n", profile.Name, i18n.T(profile.Description))
+	}
+	return nil
+}
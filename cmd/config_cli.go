@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/fixpanic/fixpanic-cli/internal/cliconfig"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/lock"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/netprobe"
+	"github.com/spf13/cobra"
+)
+
+// cliTelemetryKey is the CLI setting for usage telemetry. It is changed
+// through the telemetry store, which also keeps the install ID and drops the
+// queued events when telemetry is turned off.
+const cliTelemetryKey = "telemetry"
+
+// configCLI selects the CLI configuration instead of the agent's
+var configCLI bool
+
+// cliConfigLoaded is the CLI configuration of the running command
+var cliConfigLoaded *cliconfig.Config
+
+// cliConfigStore returns the store of the user's CLI configuration file
+func cliConfigStore() (*cliconfig.Store, error) {
+	path, err := cliconfig.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	store := cliconfig.NewStore(path)
+	if lockTimeout > store.LockWait {
+		store.LockWait = lockTimeout
+	}
+	return store, nil
+}
+
+// cliConfig returns the CLI configuration, loaded once per process. A file
+// that can't be read is reported and the defaults are used, so a broken
+// file doesn't keep the CLI from running.
+func cliConfig() *cliconfig.Config {
+	if cliConfigLoaded != nil {
+		return cliConfigLoaded
+	}
+	cliConfigLoaded = &cliconfig.Config{}
+	store, err := cliConfigStore()
+	if err == nil {
+		var loaded *cliconfig.Config
+		if loaded, err = store.Load(); err == nil {
+			cliConfigLoaded = loaded
+		}
+	}
+	if err != nil {
+		logger.Warning("Ignoring the CLI configuration: %v", err)
+	}
+	return cliConfigLoaded
+}
+
+// applyCLIConfig applies the colors and the proxy for the CLI's own downloads
+// of the CLI configuration. Its default output format is one of the settings
+// applySettings resolves.
+//
+// The proxy is set on the HTTP transports rather than in the environment, so
+// systemctl, hooks and the ssh of fleet commands don't inherit it.
+func applyCLIConfig() {
+	preferences := cliConfig()
+	switch preferences.ColorMode() {
+	case cliconfig.ColorAlways:
+		logger.SetColors(true)
+	case cliconfig.ColorNever:
+		logger.SetColors(false)
+	}
+
+	if preferences.Proxy != "" {
+		proxy := preferences.ProxyFunc()
+		if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+			transport.Proxy = proxy
+		}
+		netprobe.Proxy = proxy
+	}
+}
+
+// runConfigCLIShow lists the CLI settings
+func runConfigCLIShow(cmd *cobra.Command) error {
+	store, err := cliConfigStore()
+	if err != nil {
+		return clierror.Wrap(clierror.Config, err)
+	}
+	preferences, err := store.Load()
+	if err != nil {
+		return clierror.WithHint(clierror.Wrap(clierror.Config, err),
+			i18n.Sprintf("Fix or remove %s", store.Path))
+	}
+
+	logger.Header("CLI Configuration")
+	logger.KeyValue("Configuration file", store.Path)
+	for _, key := range cliconfig.Keys {
+		value, _ := preferences.Get(key)
+		if value == "" {
+			value = i18n.T("none")
+		}
+		if !preferences.IsSet(key) {
+			value += " " + i18n.T("(default)")
+		}
+		logger.KeyValue(key, value)
+	}
+	return nil
+}
+
+// runConfigSetCLI changes a setting in the CLI configuration
+func runConfigSetCLI(cmd *cobra.Command, key, value string) error {
+	if key == cliTelemetryKey {
+		enabled := false
+		if value != "" {
+			var err error
+			if enabled, err = strconv.ParseBool(value); err != nil {
+				return clierror.New(clierror.Usage, "telemetry must be true or false, got %q", value)
+			}
+		}
+		return setTelemetry(enabled)
+	}
+	if !slices.Contains(cliconfig.Keys, key) {
+		return clierror.New(clierror.Usage, "unknown CLI setting %q", key).
+			WithHint("Run 'fixpanic config --cli' to list the settings")
+	}
+
+	store, err := cliConfigStore()
+	if err != nil {
+		return clierror.Wrap(clierror.Config, err)
+	}
+	var previous, current string
+	err = store.Update(cmd.Context(), func(preferences *cliconfig.Config) error {
+		previous, _ = preferences.Get(key)
+		if err := preferences.Set(key, value); err != nil {
+			return clierror.Wrap(clierror.Usage, err)
+		}
+		current, _ = preferences.Get(key)
+		return nil
+	})
+	if errors.Is(err, lock.ErrLocked) {
+		return clierror.New(clierror.Busy, "another fixpanic process is changing the CLI configuration").WithHint(hintLockWait)
+	}
+	var classified *clierror.Error
+	if err != nil && !errors.As(err, &classified) {
+		return clierror.Wrap(clierror.Config, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Success("Updated %s", key)
+	logger.KeyValue("Old value", previous)
+	logger.KeyValue("New value", current)
+	return nil
+}
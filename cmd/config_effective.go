@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fixpanic/fixpanic-cli/internal/cliconfig"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// envPrefix prefixes the environment variables that set config file keys,
+// e.g. FIXPANIC_SOCKET_SERVER for socket_server
+const envPrefix = "FIXPANIC"
+
+// setting is a value that can be given as a flag, an environment variable or
+// a key of the config file, in that order of precedence
+type setting struct {
+	// Key is the key in the config file
+	Key string
+	// Flag is the flag the value is applied to
+	Flag string
+}
+
+// settings are the values resolved from flags, the environment and the
+// config file before a command runs
+var settings = []setting{
+	{Key: "socket_server", Flag: "socket-server"},
+	{Key: "timeout", Flag: "timeout"},
+	{Key: "lock_timeout", Flag: "lock-timeout"},
+	{Key: "lang", Flag: "lang"},
+	{Key: "output", Flag: "json"},
+	{Key: "otel_endpoint", Flag: "otel-endpoint"},
+}
+
+// Sources of a setting's value
+const (
+	sourceFlag      = "flag"
+	sourceEnv       = "env"
+	sourceFile      = "config file"
+	sourceCLIConfig = "cli config"
+	sourceDefault   = "default"
+)
+
+// effectiveSetting is the value a setting resolved to and where it came from
+type effectiveSetting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	// Origin names the flag, environment variable or file of the value
+	Origin string `json:"origin,omitempty"`
+}
+
+// envName returns the environment variable of a config file key
+func envName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(key)
+}
+
+// resolveSetting returns the value of s for cmd. Commands without the
+// setting's flag don't use it and report ok false.
+func resolveSetting(cmd *cobra.Command, s setting) (effective effectiveSetting, ok bool) {
+	flag := cmd.Flags().Lookup(s.Flag)
+	if flag == nil {
+		return effectiveSetting{}, false
+	}
+	effective = effectiveSetting{Key: s.Key}
+
+	switch {
+	case flag.Changed:
+		effective.Value, effective.Source, effective.Origin = flag.Value.String(), sourceFlag, "--"+s.Flag
+		if s.Key == "output" {
+			effective.Value = cliconfig.OutputText
+			if flag.Value.String() == "true" {
+				effective.Value = cliconfig.OutputJSON
+			}
+		}
+	case os.Getenv(envName(s.Key)) != "":
+		effective.Value, effective.Source, effective.Origin = os.Getenv(envName(s.Key)), sourceEnv, "$"+envName(s.Key)
+	case viper.InConfig(s.Key):
+		effective.Value, effective.Source, effective.Origin = viper.GetString(s.Key), sourceFile, viper.ConfigFileUsed()
+	case s.Key == "output" && cliConfig().IsSet("output"):
+		effective.Value, effective.Source = cliConfig().OutputFormat(), sourceCLIConfig
+		if path, err := cliconfig.DefaultPath(); err == nil {
+			effective.Origin = path
+		}
+	default:
+		effective.Value, effective.Source = flag.DefValue, sourceDefault
+		if s.Key == "output" {
+			effective.Value = cliconfig.OutputText
+		}
+	}
+	return effective, true
+}
+
+// resolvedSettings are the settings of the running command as applySettings
+// resolved them
+var resolvedSettings []effectiveSetting
+
+// applySettings sets the flags of cmd that weren't given on the command line
+// from the environment and the config files, so commands only read flags
+func applySettings(cmd *cobra.Command) error {
+	resolvedSettings = make([]effectiveSetting, 0, len(settings))
+	for _, s := range settings {
+		effective, ok := resolveSetting(cmd, s)
+		if !ok {
+			continue
+		}
+		resolvedSettings = append(resolvedSettings, effective)
+		if effective.Source == sourceFlag || effective.Source == sourceDefault {
+			continue
+		}
+
+		value := effective.Value
+		if s.Key == "output" {
+			if value != cliconfig.OutputText && value != cliconfig.OutputJSON {
+				return clierror.New(clierror.Usage, "invalid output %q from %s: must be text or json", value, effective.Origin)
+			}
+			// --porcelain asks for its own format
+			if porcelain := cmd.Flags().Lookup("porcelain"); value == cliconfig.OutputText || (porcelain != nil && porcelain.Changed) {
+				continue
+			}
+			value = "true"
+		}
+		if err := cmd.Flags().Set(s.Flag, value); err != nil {
+			return clierror.New(clierror.Usage, "invalid %s %q from %s: %w", s.Key, effective.Value, effective.Origin, err)
+		}
+	}
+	return nil
+}
+
+var configEffectiveJSON bool
+
+// configEffectiveCmd represents the config effective command
+var configEffectiveCmd = &cobra.Command{
+	Use:   "effective",
+	Short: "Show the settings in effect and where they come from",
+	Long: `Show the global settings in effect and where each value comes from.
+
+A setting is taken from the first of:
+
+  flag         the command line, e.g. --socket-server
+  env          the environment variable FIXPANIC_<KEY>, e.g. FIXPANIC_TIMEOUT
+  config file  the key in the config file --config names ($HOME/.fixpanic.yaml
+               by default)
+  cli config   for output, the CLI configuration ('fixpanic config --cli')
+  default      the built-in default
+
+The settings are:
+
+  socket_server  socket server the agent and the network checks connect to
+  timeout        abort commands after this long, e.g. 5m
+  lock_timeout   how long to wait for another fixpanic operation to finish
+  lang           output language: en, de or ja
+  output         format of commands that can write JSON: text or json`,
+	Example: `  # Show where the settings come from
+  fixpanic config effective
+
+  # Check what a variable changes
+  FIXPANIC_SOCKET_SERVER=socket.example.com:9000 fixpanic config effective`,
+	Args: cobra.NoArgs,
+	RunE: runConfigEffective,
+}
+
+func init() {
+	configCmd.AddCommand(configEffectiveCmd)
+
+	// Add flags
+	configEffectiveCmd.Flags().BoolVar(&configEffectiveJSON, "json", false, "Output the settings as JSON")
+}
+
+func runConfigEffective(cmd *cobra.Command, args []string) error {
+	if configEffectiveJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(resolvedSettings)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SETTING\tVALUE\tSOURCE")
+	for _, effective := range resolvedSettings {
+		source := effective.Source
+		if effective.Origin != "" {
+			source += " (" + effective.Origin + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", effective.Key, effective.Value, source)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/crash"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/redact"
+)
+
+// handleCrash turns a recovered panic into a crash report on disk and a
+// regular error, instead of a raw Go stack trace on the user's terminal. On an
+// interactive terminal it offers to submit the report.
+func handleCrash(recovered interface{}, stack []byte) error {
+	report := &crash.Report{
+		Time:       time.Now(),
+		Panic:      fmt.Sprint(recovered),
+		Stack:      string(stack),
+		Command:    strings.Join(append([]string{"fixpanic"}, redact.Args(os.Args[1:])...), " "),
+		CLIVersion: getCurrentVersion(),
+		Commit:     commit,
+		GoVersion:  runtime.Version(),
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+	}
+
+	crashErr := clierror.New(clierror.General, "fixpanic crashed unexpectedly: %v", recovered)
+
+	dir := os.TempDir()
+	platformInfo, err := commandPlatform()
+	if err == nil {
+		dir = platformInfo.LogDir
+	} else {
+		platformInfo = nil
+	}
+	// Panic messages may quote configuration values; reports leave the host
+	if redactor, err := newRedactor(platformInfo, false); err == nil {
+		report.Panic = redactor.String(report.Panic)
+		report.Command = redactor.String(report.Command)
+	}
+	path, err := crash.Write(dir, report)
+	if err != nil {
+		// Last resort: the stack is all the user can send us
+		fmt.Fprintln(os.Stderr, report.String())
+		return crashErr.WithHint("Please report this at support@fixpanic.com with the output above")
+	}
+
+	if isInteractive() {
+		fmt.Fprintf(os.Stderr, "%s ", i18n.Sprintf("fixpanic crashed. Send the crash report (%s) to FixPanic? [y/N]:", path))
+		response, err := readLine(context.Background())
+		if err == nil && (response == "y" || response == "Y") {
+			if err := crash.Submit(context.Background(), report); err != nil {
+				fmt.Fprintln(os.Stderr, i18n.Sprintf("Could not send the crash report: %v", err))
+			} else {
+				fmt.Fprintln(os.Stderr, i18n.T("Crash report sent. Thank you!"))
+			}
+		}
+	}
+
+	return crashErr.WithHint(
+		i18n.Sprintf("A crash report was saved to %s", path),
+		"Please include it when contacting support@fixpanic.com",
+	)
+}
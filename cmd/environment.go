@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+)
+
+// environmentAdvice describes a problem specific to the detected environment
+// and how to deal with it
+type environmentAdvice struct {
+	Problem string
+	Hint    string
+}
+
+// adviseEnvironment returns caveats for running the agent in env, or nil when
+// there are none
+func adviseEnvironment(env platform.Environment) *environmentAdvice {
+	switch {
+	case env == platform.EnvironmentWSL1:
+		return &environmentAdvice{
+			Problem: "WSL 1 translates Linux system calls and has limited binfmt support, so the agent binary may fail to execute",
+			Hint:    "Convert the distribution to WSL 2 with 'wsl --set-version <distro> 2' from Windows",
+		}
+	case env == platform.EnvironmentWSL2 && !platform.IsSystemdAvailable():
+		return &environmentAdvice{
+			Problem: "systemd is not enabled in this WSL distribution, so the agent can't run as a service",
+			Hint:    "Enable it with '[boot] systemd=true' in /etc/wsl.conf and run 'wsl --shutdown', or use 'fixpanic agent watchdog'",
+		}
+	case env.IsContainer() && !platform.IsSystemdAvailable():
+		return &environmentAdvice{
+			Problem: "systemd is not running in this container, so no service will be installed",
+			Hint:    "Run 'fixpanic agent watchdog' as the container entrypoint to keep the agent running",
+		}
+	}
+	return nil
+}
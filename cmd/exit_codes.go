@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/spf13/cobra"
+)
+
+// exitCodesCmd is a help topic documenting the CLI's exit codes
+var exitCodesCmd = &cobra.Command{
+	Use:   "exit-codes",
+	Short: "Exit codes returned by the CLI",
+	Long:  exitCodesHelp(),
+}
+
+func init() {
+	rootCmd.AddCommand(exitCodesCmd)
+}
+
+// exitCodesHelp renders the exit code table for 'fixpanic help exit-codes'
+func exitCodesHelp() string {
+	var b strings.Builder
+	b.WriteString("Fixpanic CLI exits with one of the following codes so wrapper scripts can\n")
+	b.WriteString("branch on the kind of failure instead of parsing error messages:\n\n")
+	for _, entry := range clierror.Codes {
+		fmt.Fprintf(&b, "  %3d  %-18s %s\n", entry.Code, entry.Code, entry.Description)
+	}
+	b.WriteString("\nExample:\n\n")
+	b.WriteString("  fixpanic agent install --agent-id=ID --api-key=KEY\n")
+	b.WriteString("  case $? in\n")
+	b.WriteString("    0|6) echo \"agent installed\" ;;\n")
+	b.WriteString("    4)   echo \"network problem, retry later\" ;;\n")
+	b.WriteString("    5)   echo \"re-run with sudo\" ;;\n")
+	b.WriteString("  esac")
+	return b.String()
+}
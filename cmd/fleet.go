@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/fleet"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/service"
+	"github.com/fixpanic/fixpanic-cli/internal/workpool"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fleetHostsFile      string
+	fleetParallel       int
+	fleetRemoteCLI      string
+	fleetSudo           bool
+	fleetConnectTimeout time.Duration
+	fleetSelector       string
+)
+
+// fleetCmd represents the fleet command group
+var fleetCmd = &cobra.Command{
+	Use:   "fleet",
+	Short: "Report on and upgrade the agents of many hosts",
+	Long: `Run the CLI on many hosts over SSH and aggregate what they report.
+
+The hosts are listed in a file given with --hosts: one SSH destination (host,
+user@host or a Host alias of ~/.ssh/config) per line, # starts a comment.
+The system's ssh client connects to them in batch mode, so your keys, agent,
+known_hosts and ~/.ssh/config apply; hosts that would prompt for a password
+or an unknown host key fail instead. The CLI must be installed on every
+host; --sudo runs it with 'sudo -n' there.
+
+A destination can be followed by options for hosts behind bastions or
+otherwise not covered by ~/.ssh/config, and a line starting with * sets
+them for every host:
+
+  * proxy_jump=admin@bastion.example.com sudo=yes
+  web-1.internal port=2222 user=deploy forward_agent=yes
+  db-1.internal sudo=no ssh_option=ServerAliveInterval=30
+
+proxy_jump connects through bastions as ssh -J does, port, user and
+identity_file set the connection, forward_agent (yes or no) forwards your
+SSH agent, sudo (yes or no) overrides --sudo, and ssh_option passes any ssh
+-o option; it can be repeated.
+
+Hosts can be labeled with 'fixpanic fleet label', e.g. env=prod, and fleet
+commands limited to the hosts matching --selector. Without --hosts the
+selector picks from all labeled hosts.`,
+}
+
+// fleetSnapshotCmd represents the fleet snapshot command
+var fleetSnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Print this host's agent state as JSON for fleet reports",
+	Long: `Print the state of this host's agent as JSON: versions, whether it runs and
+since when, the last heartbeat and configuration drift. This is what
+'fixpanic fleet report' runs on every host; the schema only ever gains
+fields.
+
+The last heartbeat is the last time a health check found the agent running
+and the socket server reachable: now, or the latest recorded result of
+'fixpanic agent healthcheck'.`,
+	Args: cobra.NoArgs,
+	RunE: runFleetSnapshot,
+}
+
+func init() {
+	rootCmd.AddCommand(fleetCmd)
+	fleetCmd.AddCommand(fleetSnapshotCmd)
+}
+
+// addFleetFlags adds the flags selecting and reaching the hosts to a fleet
+// command that runs on remote hosts
+func addFleetFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&fleetHostsFile, "hosts", "", "File listing the hosts, one SSH destination per line")
+	cmd.Flags().StringVarP(&fleetSelector, "selector", "l", "", "Only the hosts with these labels, e.g. env=prod,region!=us (see 'fixpanic fleet label')")
+	cmd.Flags().IntVar(&fleetParallel, "parallel", workpool.DefaultLimit, "Number of hosts contacted at once")
+	cmd.Flags().StringVar(&fleetRemoteCLI, "remote-cli", "fixpanic", "The fixpanic command on the hosts")
+	cmd.Flags().BoolVar(&fleetSudo, "sudo", false, "Run the CLI on the hosts with 'sudo -n'")
+	cmd.Flags().DurationVar(&fleetConnectTimeout, "connect-timeout", 10*time.Second, "Timeout for establishing each SSH connection")
+}
+
+// fleetRunner returns the hosts of --hosts and --selector and a runner
+// reaching them
+func fleetRunner() ([]string, *fleet.Runner, error) {
+	if !platform.IsCommandAvailable("ssh") {
+		return nil, nil, clierror.New(clierror.NotInstalled, "ssh is not installed").
+			WithHint("Install the OpenSSH client to reach the hosts")
+	}
+	list, err := fleetHosts()
+	if err != nil {
+		return nil, nil, err
+	}
+	runner := &fleet.Runner{
+		RemoteCLI:      fleetRemoteCLI,
+		Sudo:           fleetSudo,
+		ConnectTimeout: fleetConnectTimeout,
+		OTelEndpoint:   otelEndpoint,
+		Options:        list.Options,
+	}
+	return list.Hosts, runner, nil
+}
+
+// fleetHosts returns the hosts of --hosts matching --selector, or the
+// labeled hosts matching it without --hosts
+func fleetHosts() (*fleet.HostList, error) {
+	if fleetHostsFile == "" && fleetSelector == "" {
+		return nil, clierror.New(clierror.Usage, "--hosts or --selector is required").
+			WithHint("Pass a host list with --hosts, or label hosts with 'fixpanic fleet label' and select them with --selector")
+	}
+	list := &fleet.HostList{}
+	if fleetHostsFile != "" {
+		loaded, err := fleet.LoadHostList(fleetHostsFile)
+		if err != nil {
+			return nil, clierror.Wrap(clierror.Usage, err)
+		}
+		list = loaded
+	}
+	if fleetSelector == "" {
+		return list, nil
+	}
+
+	selector, err := fleet.ParseSelector(fleetSelector)
+	if err != nil {
+		return nil, clierror.Wrap(clierror.Usage, err)
+	}
+	store, err := fleetLabels()
+	if err != nil {
+		return nil, err
+	}
+	if fleetHostsFile == "" {
+		list.Hosts = store.LabeledHosts()
+	}
+	list.Hosts = store.Select(list.Hosts, selector)
+	if len(list.Hosts) == 0 {
+		return nil, clierror.New(clierror.Usage, "no host matches --selector %s", fleetSelector).
+			WithHint("Run 'fixpanic fleet label' to list the labeled hosts")
+	}
+	return list, nil
+}
+
+// fleetLabels loads the host labels
+func fleetLabels() (*fleet.LabelStore, error) {
+	path, err := fleet.DefaultLabelsPath()
+	if err != nil {
+		return nil, err
+	}
+	return fleet.LoadLabels(path)
+}
+
+// writeFleetOutput writes the output of a fleet command with write, to the
+// file at path or to standard output if path is empty or "-"
+func writeFleetOutput(path string, write func(io.Writer) error) error {
+	if path == "" || path == "-" {
+		return write(os.Stdout)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+func runFleetSnapshot(cmd *cobra.Command, args []string) error {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	snapshot := collectFleetSnapshot(cmd.Context(), platformInfo)
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snapshot)
+}
+
+// collectFleetSnapshot gathers the state of this host's agent
+func collectFleetSnapshot(ctx context.Context, platformInfo *platform.PlatformInfo) *fleet.Snapshot {
+	health := checkAgentHealth(ctx, platformInfo)
+	snapshot := &fleet.Snapshot{
+		Schema:       fleet.SnapshotSchema,
+		CLIVersion:   getCurrentVersion(),
+		CollectedAt:  health.Time,
+		Installed:    health.Installed,
+		AgentVersion: health.Version,
+		Running:      health.Running,
+		Drift:        []string{},
+		Problems:     health.Problems,
+	}
+	snapshot.Hostname, _ = os.Hostname()
+	if !snapshot.Installed {
+		return snapshot
+	}
+
+	if snapshot.Running {
+		if platform.IsSystemdAvailable() {
+			if serviceHealth, err := service.NewManager(platformInfo).Health(ctx); err == nil && !serviceHealth.MainStartedAt.IsZero() {
+				started := serviceHealth.MainStartedAt.UTC()
+				snapshot.AgentStartedAt = &started
+			}
+		} else if status := readWatchdogStatus(platformInfo); status != nil && !status.AgentStarted.IsZero() {
+			started := status.AgentStarted.UTC()
+			snapshot.AgentStartedAt = &started
+		}
+	}
+
+	if health.Running && health.Connected {
+		snapshot.LastHeartbeat = &health.Time
+	} else if saved, err := stateStore(platformInfo).Load(); err == nil {
+		for i := len(saved.HealthChecks) - 1; i >= 0; i-- {
+			if check := saved.HealthChecks[i]; check.Running && check.Connected {
+				snapshot.LastHeartbeat = &check.Time
+				break
+			}
+		}
+	}
+
+	agentConfig, err := config.LoadConfig(platformInfo.GetConfigPath())
+	if err != nil {
+		return snapshot
+	}
+	desiredConfig, err := renderDesiredConfig(agentConfig, platformInfo)
+	if err != nil {
+		snapshot.Problems = append(snapshot.Problems, fmt.Sprintf("invalid configuration: %v", err))
+		return snapshot
+	}
+	targets, err := collectDriftTargets(platformInfo, desiredConfig)
+	if err != nil {
+		snapshot.Problems = append(snapshot.Problems, err.Error())
+		return snapshot
+	}
+	for _, target := range targets {
+		if target.Drifted {
+			snapshot.Drift = append(snapshot.Drift, target.Path)
+		}
+	}
+	return snapshot
+}
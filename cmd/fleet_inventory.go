@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/fleet"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/workpool"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fleetInventoryFormat string
+	fleetInventoryOutput string
+)
+
+// fleetInventoryCmd represents the fleet inventory command
+var fleetInventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Collect the inventory of many hosts for a CMDB",
+	Long: `Collect 'fixpanic agent inventory --json' from every host over SSH, in the
+same stable schema, for configuration management database importers.
+
+JSON is a list with one entry per host: the inventory, or the error that kept
+it from being collected. CSV has one row per host with the main fields.`,
+	Example: `  # Collect the inventory of all hosts for the CMDB importer
+  fixpanic fleet inventory --hosts hosts.txt --output inventory.json
+
+  # As CSV
+  fixpanic fleet inventory --hosts hosts.txt --format csv > inventory.csv`,
+	Args: cobra.NoArgs,
+	RunE: runFleetInventory,
+}
+
+func init() {
+	fleetCmd.AddCommand(fleetInventoryCmd)
+
+	// Add flags
+	addFleetFlags(fleetInventoryCmd)
+	fleetInventoryCmd.Flags().StringVar(&fleetInventoryFormat, "format", "json", "Output format: json or csv")
+	fleetInventoryCmd.Flags().StringVarP(&fleetInventoryOutput, "output", "o", "", "File to write the inventory to (default standard output)")
+}
+
+func runFleetInventory(cmd *cobra.Command, args []string) error {
+	if fleetInventoryFormat != "json" && fleetInventoryFormat != "csv" {
+		return clierror.New(clierror.Usage, "unknown format %q: use json or csv", fleetInventoryFormat)
+	}
+	hosts, runner, err := fleetRunner()
+	if err != nil {
+		return err
+	}
+
+	toFile := fleetInventoryOutput != "" && fleetInventoryOutput != "-"
+	if toFile {
+		logger.Info("Collecting the inventory of %d host(s)...", len(hosts))
+	}
+	inventories := workpool.Map(cmd.Context(), hosts, fleetParallel, func(ctx context.Context, host string) fleet.HostInventory {
+		return collectHostInventory(ctx, runner, host)
+	})
+
+	err = writeFleetOutput(fleetInventoryOutput, func(out io.Writer) error {
+		if fleetInventoryFormat == "csv" {
+			return fleet.WriteInventoryCSV(out, inventories)
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(inventories)
+	})
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, inventory := range inventories {
+		if inventory.Inventory == nil {
+			failed++
+		}
+	}
+	if toFile {
+		logger.Success("Wrote the inventory of %d host(s) to %s", len(hosts)-failed, fleetInventoryOutput)
+		if failed > 0 {
+			logger.Warning("%d host(s) could not be reached", failed)
+		}
+	}
+	if failed == len(hosts) {
+		return clierror.New(clierror.Network, "none of the %d host(s) could be reached", len(hosts)).
+			WithHint("Check that 'ssh <host> fixpanic version' works without a prompt")
+	}
+	return nil
+}
+
+// collectHostInventory runs 'fixpanic agent inventory --json' on host
+func collectHostInventory(ctx context.Context, runner *fleet.Runner, host string) fleet.HostInventory {
+	result := fleet.HostInventory{Host: host}
+	output, err := runner.Output(ctx, host, "agent", "inventory", "--json")
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	var inventory fleet.Inventory
+	if err := json.Unmarshal(output, &inventory); err != nil {
+		result.Error = fmt.Sprintf("unexpected inventory (is the CLI on the host older than agent inventory?): %v", err)
+		return result
+	}
+	result.Inventory = &inventory
+	return result
+}
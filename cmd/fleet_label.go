@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/fleet"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// fleetLabelCmd represents the fleet label command
+var fleetLabelCmd = &cobra.Command{
+	Use:   "label [<host> [key=value | key-]...]",
+	Short: "Label hosts to select them in fleet commands",
+	Long: `Set labels such as env=prod or region=eu on a host, so fleet commands can be
+limited to the hosts matching --selector instead of keeping a host list per
+environment. key- removes a label.
+
+The host is the SSH destination as written in host lists. The labels are kept
+in fleet-labels.json in the fixpanic directory of your configuration
+directory. Without labels the host's current labels are printed, and without
+a host every labeled host.
+
+Selectors are comma-separated and every part must match: key=value,
+key!=value, or key alone for hosts that have the label.`,
+	Example: `  # Label a host
+  fixpanic fleet label web-1 env=prod region=eu
+
+  # Remove a label
+  fixpanic fleet label web-1 region-
+
+  # List the labeled hosts
+  fixpanic fleet label
+
+  # Upgrade the production hosts outside the US
+  fixpanic fleet upgrade --selector env=prod,region!=us --sudo`,
+	RunE: runFleetLabel,
+}
+
+func init() {
+	fleetCmd.AddCommand(fleetLabelCmd)
+}
+
+func runFleetLabel(cmd *cobra.Command, args []string) error {
+	store, err := fleetLabels()
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		printFleetLabels(store, store.LabeledHosts())
+		return nil
+	}
+
+	host, changes := args[0], args[1:]
+	if len(changes) == 0 {
+		printFleetLabels(store, []string{host})
+		return nil
+	}
+	if err := store.Apply(host, changes); err != nil {
+		return clierror.Wrap(clierror.Usage, err)
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+	if labels := store.Labels(host); len(labels) > 0 {
+		logger.Success("Labels of %s: %s", host, fleet.FormatLabels(labels))
+	} else {
+		logger.Success("%s has no labels anymore", host)
+	}
+	return nil
+}
+
+// printFleetLabels prints the labels of hosts
+func printFleetLabels(store *fleet.LabelStore, hosts []string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HOST\tLABELS")
+	for _, host := range hosts {
+		fmt.Fprintf(w, "%s\t%s\n", host, fleet.FormatLabels(store.Labels(host)))
+	}
+	w.Flush()
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/diskspace"
+	"github.com/fixpanic/fixpanic-cli/internal/fleet"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/workpool"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fleetLogsSince     string
+	fleetLogsOutput    string
+	fleetLogsMaxSize   int
+	fleetLogsRateLimit int
+)
+
+// fleetLogsCmd represents the fleet logs command
+var fleetLogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Collect the recent agent logs of many hosts",
+	Long: `Collect the agent logs of the last --since from every host over SSH into a
+directory, one <host>.log file per host, for incident evidence.
+
+Hosts are contacted --parallel at a time. Each host's logs are capped at
+--max-size megabytes, keeping the oldest lines of the period, and read at
+most --rate-limit kilobytes per second so the collection doesn't compete
+with production traffic.
+
+Progress is recorded in fleet-logs.json in the directory. Running the same
+command again after an interruption or with failed hosts only collects the
+hosts still missing, for the same period as the first run; use another
+directory to start over. The files may contain sensitive data and are only
+readable by you.
+
+The CLI on the hosts must support 'fixpanic agent logs --since'.`,
+	Example: `  # Collect the last hour of logs of all hosts
+  fixpanic fleet logs --hosts hosts.txt --since 1h --output incident-4711/
+
+  # Collect up to 10 MB per host, 4 hosts at a time, without a rate limit
+  fixpanic fleet logs --hosts hosts.txt --since 30m --output logs/ --max-size 10 --parallel 4 --rate-limit 0`,
+	Args: cobra.NoArgs,
+	RunE: runFleetLogs,
+}
+
+func init() {
+	fleetCmd.AddCommand(fleetLogsCmd)
+
+	// Add flags
+	addFleetFlags(fleetLogsCmd)
+	fleetLogsCmd.Flags().StringVar(&fleetLogsSince, "since", "1h", "Collect the logs newer than this age or time (e.g. 30m, 24h, 7d or 2025-01-01T12:00:00Z)")
+	fleetLogsCmd.Flags().StringVarP(&fleetLogsOutput, "output", "o", "", "Directory to write the logs to")
+	fleetLogsCmd.Flags().IntVar(&fleetLogsMaxSize, "max-size", 100, "Collect at most this many megabytes per host (0 for no limit)")
+	fleetLogsCmd.Flags().IntVar(&fleetLogsRateLimit, "rate-limit", 1024, "Read each host's logs at most this many kilobytes per second (0 for no limit)")
+	fleetLogsCmd.MarkFlagRequired("output")
+}
+
+func runFleetLogs(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	since, err := parseLogSince(fleetLogsSince, time.Now())
+	if err != nil {
+		return clierror.Wrap(clierror.Usage, err)
+	}
+	if fleetLogsMaxSize < 0 || fleetLogsRateLimit < 0 {
+		return clierror.New(clierror.Usage, "--max-size and --rate-limit can't be negative")
+	}
+	hosts, runner, err := fleetRunner()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(fleetLogsOutput, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", fleetLogsOutput, err)
+	}
+	collection, err := fleet.OpenLogCollection(fleetLogsOutput, since, fleetLogsSince)
+	if err != nil {
+		return err
+	}
+	if collection.SinceFlag != fleetLogsSince {
+		return clierror.New(clierror.Usage, "%s holds a collection of the logs since %s (--since %s)", fleetLogsOutput, collection.Since.Local().Format(time.RFC3339), collection.SinceFlag).
+			WithHint(i18n.Sprintf("Re-run with --since %s to resume it, or use another --output directory", collection.SinceFlag))
+	}
+
+	var pending []string
+	for _, host := range hosts {
+		if !collection.Host(host).Done() {
+			pending = append(pending, host)
+		}
+	}
+	if done := len(hosts) - len(pending); done > 0 {
+		logger.Info("Resuming: the logs of %d host(s) were already collected", done)
+	}
+	if len(pending) > 0 {
+		logger.Info("Collecting the logs since %s of %d host(s)...", collection.Since.Local().Format(time.RFC3339), len(pending))
+	}
+	workpool.Map(ctx, pending, fleetParallel, func(ctx context.Context, host string) error {
+		return collectHostLogs(ctx, runner, collection, host)
+	})
+
+	return reportFleetLogs(collection, hosts)
+}
+
+// collectHostLogs runs 'fixpanic agent logs --since' on host and records the
+// outcome in collection. The logs are written next to their final name first
+// so an interrupted collection never leaves a file that looks complete.
+func collectHostLogs(ctx context.Context, runner *fleet.Runner, collection *fleet.LogCollection, host string) error {
+	// Hosts not reached before an interruption stay pending
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	name := fleet.LogFileName(host)
+	path := filepath.Join(fleetLogsOutput, name)
+	partial := path + ".partial"
+	file, err := os.OpenFile(partial, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return collection.Record(host, &fleet.HostLogs{State: fleet.LogsFailed, Error: err.Error(), CollectedAt: time.Now().UTC()})
+	}
+
+	writer := &fleet.LimitedWriter{
+		Ctx:  ctx,
+		W:    file,
+		Max:  int64(fleetLogsMaxSize) * 1024 * 1024,
+		Rate: int64(fleetLogsRateLimit) * 1024,
+	}
+	err = runner.Stream(ctx, host, writer, "agent", "logs", "--since", collection.Since.UTC().Format(time.RFC3339), "--no-pager")
+	// The remote command fails writing once the limit closed its output
+	if writer.Truncated && ctx.Err() == nil {
+		err = nil
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(partial, path)
+	}
+
+	result := &fleet.HostLogs{State: fleet.LogsComplete, File: name, Bytes: writer.Written, CollectedAt: time.Now().UTC()}
+	switch {
+	case err != nil:
+		os.Remove(partial)
+		result = &fleet.HostLogs{State: fleet.LogsFailed, Error: err.Error(), CollectedAt: result.CollectedAt}
+	case writer.Truncated:
+		result.State = fleet.LogsTruncated
+	}
+	return collection.Record(host, result)
+}
+
+// reportFleetLogs prints the outcome for every host and fails if no host's
+// logs could be collected
+func reportFleetLogs(collection *fleet.LogCollection, hosts []string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HOST\tSTATE\tSIZE\tFILE")
+	failed := 0
+	for _, host := range hosts {
+		logs := collection.Host(host)
+		switch {
+		case logs == nil:
+			failed++
+			fmt.Fprintf(w, "%s\tpending\t\t\n", host)
+		case logs.State == fleet.LogsFailed:
+			failed++
+			fmt.Fprintf(w, "%s\t%s\t\t%s\n", host, logs.State, logs.Error)
+		default:
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", host, logs.State, diskspace.FormatBytes(uint64(logs.Bytes)), filepath.Join(fleetLogsOutput, logs.File))
+		}
+	}
+	w.Flush()
+
+	if failed == len(hosts) {
+		return clierror.New(clierror.Network, "the logs of none of the %d host(s) could be collected", len(hosts)).
+			WithHint("Check that 'ssh <host> fixpanic agent logs --since 1h' works without a prompt")
+	}
+	if failed > 0 {
+		logger.Warning("The logs of %d host(s) could not be collected; run the same command again to retry them", failed)
+	}
+	return nil
+}
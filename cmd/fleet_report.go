@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/fleet"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/workpool"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fleetReportFormat string
+	fleetReportOutput string
+)
+
+// fleetReportCmd represents the fleet report command
+var fleetReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize the agents of many hosts as HTML, CSV or JSON",
+	Long: `Collect 'fixpanic fleet snapshot' from every host over SSH and summarize the
+agents: versions and pending upgrades (agents not at the latest release),
+whether they run and for how long, their last heartbeat and configuration
+drift. Hosts that can't be reached are listed with the error.
+
+HTML is a standalone page for attaching to ops reviews, CSV has one row per
+host for spreadsheets and JSON the full snapshots. The report is written to
+standard output unless --output names a file.`,
+	Example: `  # Monthly report as an HTML page
+  fixpanic fleet report --hosts hosts.txt --format html --output fleet-2026-10.html
+
+  # One CSV row per host, running the CLI with sudo on the hosts
+  fixpanic fleet report --hosts hosts.txt --sudo --format csv > fleet.csv`,
+	Args: cobra.NoArgs,
+	RunE: runFleetReport,
+}
+
+func init() {
+	fleetCmd.AddCommand(fleetReportCmd)
+
+	// Add flags
+	addFleetFlags(fleetReportCmd)
+	fleetReportCmd.Flags().StringVar(&fleetReportFormat, "format", "json", "Report format: html, csv or json")
+	fleetReportCmd.Flags().StringVarP(&fleetReportOutput, "output", "o", "", "File to write the report to (default standard output)")
+}
+
+func runFleetReport(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	switch fleetReportFormat {
+	case "html", "csv", "json":
+	default:
+		return clierror.New(clierror.Usage, "unknown format %q: use html, csv or json", fleetReportFormat)
+	}
+	hosts, runner, err := fleetRunner()
+	if err != nil {
+		return err
+	}
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	// Progress only goes to the terminal when the report doesn't
+	toFile := fleetReportOutput != "" && fleetReportOutput != "-"
+	if toFile {
+		logger.Info("Collecting snapshots from %d host(s)...", len(hosts))
+	}
+	reports := workpool.Map(ctx, hosts, fleetParallel, func(ctx context.Context, host string) fleet.HostReport {
+		return collectHostReport(ctx, runner, host)
+	})
+
+	latest, err := connectivity.NewManager(platformInfo).GetLatestAgentVersion(ctx)
+	if err != nil {
+		latest = ""
+		if toFile {
+			logger.Warning("Could not determine the latest agent release, pending upgrades are not reported: %v", err)
+		}
+	}
+	report := fleet.NewReport(reports, latest, time.Now())
+
+	err = writeFleetOutput(fleetReportOutput, func(out io.Writer) error {
+		switch fleetReportFormat {
+		case "html":
+			return report.WriteHTML(out)
+		case "csv":
+			return report.WriteCSV(out)
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	})
+	if err != nil {
+		return err
+	}
+
+	if toFile {
+		summary := report.Summary
+		logger.Success("Wrote the report of %d host(s) to %s", summary.Hosts, fleetReportOutput)
+		if summary.Unreachable > 0 {
+			logger.Warning("%d host(s) could not be reached", summary.Unreachable)
+		}
+	}
+	if report.Summary.Unreachable == report.Summary.Hosts {
+		return clierror.New(clierror.Network, "none of the %d host(s) could be reached", report.Summary.Hosts).
+			WithHint("Check that 'ssh <host> fixpanic version' works without a prompt")
+	}
+	return nil
+}
+
+// collectHostReport runs 'fixpanic fleet snapshot' on host
+func collectHostReport(ctx context.Context, runner *fleet.Runner, host string) fleet.HostReport {
+	report := fleet.HostReport{Host: host}
+	output, err := runner.Output(ctx, host, "fleet", "snapshot")
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	var snapshot fleet.Snapshot
+	if err := json.Unmarshal(output, &snapshot); err != nil {
+		report.Error = fmt.Sprintf("unexpected snapshot (is the CLI on the host older than fleet snapshot?): %v", err)
+		return report
+	}
+	report.Snapshot = &snapshot
+	return report
+}
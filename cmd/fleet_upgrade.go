@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/fleet"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/releases"
+	"github.com/fixpanic/fixpanic-cli/internal/workpool"
+	"github.com/spf13/cobra"
+)
+
+// fleetBakeCheckInterval is how often the canaries are checked while baking
+const fleetBakeCheckInterval = time.Minute
+
+// fleetRollbackTimeout bounds rolling back the canaries, which also runs once
+// the upgrade was interrupted
+const fleetRollbackTimeout = 10 * time.Minute
+
+// Outcomes of a host in a fleet upgrade
+const (
+	fleetUpgraded   = "upgraded"
+	fleetCurrent    = "current"
+	fleetSkipped    = "skipped"
+	fleetFailed     = "failed"
+	fleetRolledBack = "rolled back"
+	fleetNotStarted = "not started"
+)
+
+var (
+	fleetUpgradeCanary  string
+	fleetUpgradeBake    time.Duration
+	fleetUpgradeVersion string
+)
+
+// fleetUpgradeCmd represents the fleet upgrade command
+var fleetUpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade the agents of many hosts, canaries first",
+	Long: `Upgrade the agent of every host over SSH to the same release: --agent-version
+or the latest release at the start.
+
+With --canary only that share of the hosts (e.g. 10%, rounded up) or number
+of hosts is upgraded first, in the order of the host list. The canaries then
+bake for --bake while they are checked every minute: each agent has to run
+the new version and reach the socket server. If a canary fails to upgrade or
+to stay healthy, it is rolled back to its previous version with
+'fixpanic agent upgrade --agent-version' and the other hosts are left alone.
+Otherwise the remaining hosts are upgraded, --parallel at a time.
+
+Hosts that can't be reached, have no agent installed or already run the
+release are skipped. The CLI on the hosts must support
+'fixpanic agent upgrade --agent-version' and usually needs --sudo.`,
+	Example: `  # Upgrade 10% of the hosts, watch them for 30 minutes, then the rest
+  fixpanic fleet upgrade --hosts hosts.txt --sudo --canary 10% --bake 30m
+
+  # Upgrade two canaries to a given release first
+  fixpanic fleet upgrade --hosts hosts.txt --sudo --canary 2 --agent-version v1.5.0
+
+  # Upgrade every host at once
+  fixpanic fleet upgrade --hosts hosts.txt --sudo`,
+	Args: cobra.NoArgs,
+	RunE: runFleetUpgrade,
+}
+
+func init() {
+	fleetCmd.AddCommand(fleetUpgradeCmd)
+
+	// Add flags
+	addFleetFlags(fleetUpgradeCmd)
+	fleetUpgradeCmd.Flags().StringVar(&fleetUpgradeCanary, "canary", "", "Upgrade this share (e.g. 10%) or number of hosts first and only continue if they stay healthy")
+	fleetUpgradeCmd.Flags().DurationVar(&fleetUpgradeBake, "bake", 30*time.Minute, "How long the canaries have to stay healthy before the other hosts are upgraded")
+	fleetUpgradeCmd.Flags().StringVar(&fleetUpgradeVersion, "agent-version", "", "Upgrade to this agent release (e.g. v1.5.0) instead of the latest")
+}
+
+// hostUpgrade is the outcome of upgrading one host
+type hostUpgrade struct {
+	host   string
+	from   string
+	result string
+	err    error
+}
+
+func runFleetUpgrade(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if fleetUpgradeCanary != "" {
+		if _, err := fleet.CanaryCount(fleetUpgradeCanary, 1); err != nil {
+			return clierror.Wrap(clierror.Usage, err)
+		}
+	}
+	if fleetUpgradeBake < 0 {
+		return clierror.New(clierror.Usage, "--bake can't be negative")
+	}
+	hosts, runner, err := fleetRunner()
+	if err != nil {
+		return err
+	}
+	target, err := fleetUpgradeTarget(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Checking the agents of %d host(s)...", len(hosts))
+	reports := workpool.Map(ctx, hosts, fleetParallel, func(ctx context.Context, host string) fleet.HostReport {
+		return collectHostReport(ctx, runner, host)
+	})
+	upgrades := make([]*hostUpgrade, len(reports))
+	var pending []*hostUpgrade
+	for i, report := range reports {
+		upgrade := &hostUpgrade{host: report.Host, result: fleetNotStarted}
+		switch snapshot := report.Snapshot; {
+		case snapshot == nil:
+			upgrade.result, upgrade.err = fleetSkipped, fmt.Errorf("%s", report.Error)
+		case !snapshot.Installed:
+			upgrade.result, upgrade.err = fleetSkipped, fmt.Errorf("the agent is not installed")
+		case fleet.SameVersion(snapshot.AgentVersion, target):
+			upgrade.from, upgrade.result = snapshot.AgentVersion, fleetCurrent
+		default:
+			upgrade.from = snapshot.AgentVersion
+			pending = append(pending, upgrade)
+		}
+		upgrades[i] = upgrade
+	}
+	if len(pending) == 0 {
+		logger.Success("No host needs an upgrade to %s", target)
+		printFleetUpgrades(upgrades, target)
+		return nil
+	}
+
+	rest := pending
+	if fleetUpgradeCanary != "" {
+		count, _ := fleet.CanaryCount(fleetUpgradeCanary, len(pending))
+		canaries := pending[:count]
+		rest = pending[count:]
+
+		logger.Info("Upgrading %d canary host(s) to %s...", len(canaries), target)
+		upgradedAt := time.Now()
+		upgradeFleetHosts(ctx, runner, canaries, target)
+		err := firstFleetFailure(canaries)
+		if err == nil && len(rest) > 0 {
+			err = bakeCanaries(ctx, runner, canaries, target, upgradedAt)
+		}
+		if err != nil {
+			notRolledBack := rollBackCanaries(ctx, runner, canaries)
+			printFleetUpgrades(upgrades, target)
+			failure := clierror.New(clierror.General, "the canary upgrade to %s failed: %w", target, err)
+			if len(notRolledBack) > 0 {
+				return failure.WithHint(i18n.Sprintf("No other host was upgraded, but %s could not be rolled back; run 'fixpanic agent upgrade --agent-version' with their previous version there", strings.Join(notRolledBack, ", ")))
+			}
+			return failure.WithHint("No other host was upgraded; the upgraded canaries were rolled back to their previous version")
+		}
+		if len(rest) > 0 {
+			logger.Success("The canaries are healthy on %s", target)
+		}
+	}
+
+	if len(rest) > 0 {
+		logger.Info("Upgrading %d host(s) to %s...", len(rest), target)
+		upgradeFleetHosts(ctx, runner, rest, target)
+	}
+	printFleetUpgrades(upgrades, target)
+
+	failed := 0
+	for _, upgrade := range pending {
+		if upgrade.result != fleetUpgraded {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return clierror.New(clierror.General, "%d of %d host(s) could not be upgraded to %s", failed, len(pending), target).
+			WithHint("Run the same command again to retry them; upgraded hosts are skipped")
+	}
+	return nil
+}
+
+// fleetUpgradeTarget returns the release of --agent-version, or the latest
+// release, so every host is upgraded to the same one
+func fleetUpgradeTarget(ctx context.Context) (string, error) {
+	if fleetUpgradeVersion != "" {
+		tag, err := releases.ParseTag(fleetUpgradeVersion)
+		if err != nil {
+			return "", clierror.Wrap(clierror.Usage, err)
+		}
+		return tag, nil
+	}
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return "", err
+	}
+	latest, err := connectivity.NewManager(platformInfo).GetLatestAgentVersion(ctx)
+	if err != nil {
+		return "", clierror.WithHint(clierror.Wrap(clierror.Network, fmt.Errorf("failed to determine the latest agent release: %w", err)),
+			"Pass the release to upgrade to with --agent-version")
+	}
+	return latest, nil
+}
+
+// upgradeFleetHosts runs 'fixpanic agent upgrade --agent-version' on the hosts
+// of upgrades, --parallel at a time
+func upgradeFleetHosts(ctx context.Context, runner *fleet.Runner, upgrades []*hostUpgrade, target string) {
+	workpool.Map(ctx, upgrades, fleetParallel, func(ctx context.Context, upgrade *hostUpgrade) error {
+		// Hosts not reached before an interruption stay not started
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, err := runner.Output(ctx, upgrade.host, "agent", "upgrade", "--agent-version", target); err != nil {
+			upgrade.result, upgrade.err = fleetFailed, err
+			logger.Warning("%s: %v", upgrade.host, err)
+			return err
+		}
+		upgrade.result = fleetUpgraded
+		logger.List("%s: upgraded from %s", upgrade.host, upgrade.from)
+		return nil
+	})
+}
+
+// bakeCanaries checks the upgraded canaries every fleetBakeCheckInterval
+// until --bake passed, and returns why the first one became unhealthy
+func bakeCanaries(ctx context.Context, runner *fleet.Runner, canaries []*hostUpgrade, target string, upgradedAt time.Time) error {
+	deadline := time.Now().Add(fleetUpgradeBake)
+	logger.Info("Baking the canaries until %s...", deadline.Format("15:04:05"))
+	for {
+		wait := time.Until(deadline)
+		if wait > fleetBakeCheckInterval {
+			wait = fleetBakeCheckInterval
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		reports := workpool.Map(ctx, canaries, fleetParallel, func(ctx context.Context, canary *hostUpgrade) fleet.HostReport {
+			return collectHostReport(ctx, runner, canary.host)
+		})
+		for i, report := range reports {
+			err := fmt.Errorf("%s", report.Error)
+			if report.Snapshot != nil {
+				err = fleet.CheckUpgraded(report.Snapshot, target, upgradedAt)
+			}
+			if err != nil {
+				canaries[i].err = err
+				return fmt.Errorf("canary %s: %w", report.Host, err)
+			}
+		}
+		if !time.Now().Before(deadline) {
+			return nil
+		}
+	}
+}
+
+// rollBackCanaries returns the upgraded canaries to the version they ran
+// before and returns the hosts that could not be rolled back. It runs even
+// when ctx was cancelled, since that is what triggers it after an
+// interruption.
+func rollBackCanaries(ctx context.Context, runner *fleet.Runner, canaries []*hostUpgrade) []string {
+	var upgraded []*hostUpgrade
+	for _, canary := range canaries {
+		if canary.result == fleetUpgraded {
+			upgraded = append(upgraded, canary)
+		}
+	}
+	if len(upgraded) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fleetRollbackTimeout)
+	defer cancel()
+
+	logger.Warning("Rolling back %d canary host(s)...", len(upgraded))
+	errs := workpool.Map(ctx, upgraded, fleetParallel, func(ctx context.Context, canary *hostUpgrade) error {
+		if _, err := releases.ParseTag(canary.from); err != nil {
+			canary.err = fmt.Errorf("can't roll back to an unknown version")
+			logger.Warning("%s: %v", canary.host, canary.err)
+			return canary.err
+		}
+		if _, err := runner.Output(ctx, canary.host, "agent", "upgrade", "--agent-version", canary.from); err != nil {
+			canary.err = fmt.Errorf("rollback to %s failed: %w", canary.from, err)
+			logger.Warning("%s: %v", canary.host, canary.err)
+			return err
+		}
+		canary.result = fleetRolledBack
+		logger.List("%s: rolled back to %s", canary.host, canary.from)
+		return nil
+	})
+
+	var notRolledBack []string
+	for i, err := range errs {
+		if err != nil {
+			notRolledBack = append(notRolledBack, upgraded[i].host)
+		}
+	}
+	return notRolledBack
+}
+
+// firstFleetFailure returns the error of the first host that failed to
+// upgrade, if any
+func firstFleetFailure(upgrades []*hostUpgrade) error {
+	for _, upgrade := range upgrades {
+		if upgrade.result == fleetFailed {
+			return fmt.Errorf("canary %s: %w", upgrade.host, upgrade.err)
+		}
+	}
+	return nil
+}
+
+// printFleetUpgrades prints the outcome for every host
+func printFleetUpgrades(upgrades []*hostUpgrade, target string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HOST\tFROM\tTO\tRESULT\tERROR")
+	for _, upgrade := range upgrades {
+		errText := ""
+		if upgrade.err != nil {
+			errText = upgrade.err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", upgrade.host, upgrade.from, target, upgrade.result, errText)
+	}
+	w.Flush()
+}
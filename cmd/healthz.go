@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/state"
+	"github.com/fixpanic/fixpanic-cli/internal/watchdog"
+)
+
+// healthEndpointMaxAge is how long a health check answers probes, so a load
+// balancer polling every few seconds doesn't dial the socket server each time
+const healthEndpointMaxAge = 10 * time.Second
+
+// healthEndpointStatus is the response of GET /status.json
+type healthEndpointStatus struct {
+	state.HealthCheck
+	// Watchdog is the state of the watchdog supervising the agent, if any
+	Watchdog *watchdog.Status `json:"watchdog,omitempty"`
+}
+
+// healthEndpoints serves GET /healthz and GET /status.json without a token,
+// for load balancers and local probes. Both answer 503 when the agent is
+// unhealthy.
+type healthEndpoints struct {
+	platformInfo *platform.PlatformInfo
+
+	mu   sync.Mutex
+	last state.HealthCheck
+}
+
+// register adds the endpoints to mux
+func (h *healthEndpoints) register(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", h.handleHealthz)
+	mux.HandleFunc("/status.json", h.handleStatus)
+}
+
+// check returns the latest health check, running a new one when it's older
+// than healthEndpointMaxAge
+func (h *healthEndpoints) check(ctx context.Context) state.HealthCheck {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if time.Since(h.last.Time) > healthEndpointMaxAge {
+		h.last = checkAgentHealth(ctx, h.platformInfo)
+	}
+	return h.last
+}
+
+func (h *healthEndpoints) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if !allowHealthMethod(w, r) {
+		return
+	}
+	result := h.check(r.Context())
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if !result.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "unhealthy: %s\n", strings.Join(result.Problems, "; "))
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+func (h *healthEndpoints) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if !allowHealthMethod(w, r) {
+		return
+	}
+	status := healthEndpointStatus{
+		HealthCheck: h.check(r.Context()),
+		Watchdog:    readWatchdogStatus(h.platformInfo),
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	code := http.StatusOK
+	if !status.Healthy {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, status)
+}
+
+// allowHealthMethod answers requests other than GET and HEAD with 405
+func allowHealthMethod(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	return false
+}
+
+// serveHealthEndpoints serves the health endpoints on addr until ctx is done.
+// A failure to listen is only a warning: the caller keeps doing its job.
+func serveHealthEndpoints(ctx context.Context, platformInfo *platform.PlatformInfo, addr string) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.Warning("Health endpoints disabled: failed to listen on %s: %v", addr, err)
+		return
+	}
+	mux := http.NewServeMux()
+	(&healthEndpoints{platformInfo: platformInfo}).register(mux)
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Warning("Health endpoints failed: %v", err)
+		}
+	}()
+	logger.KeyValue("Health endpoints", fmt.Sprintf("http://%s/healthz, http://%s/status.json", listener.Addr(), listener.Addr()))
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/audit"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historySince string
+	historyJSON  bool
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show when the agent and CLI versions changed",
+	Long: `Show every version change of the agent and the CLI on this host.
+
+Installs, upgrades and failed upgrade attempts are recorded with the time, the
+versions before and after, who made the change and its result, so version
+changes can be correlated with incidents.`,
+	Example: `  # Show all recorded version changes
+  fixpanic history
+
+  # Changes in the last 30 days as JSON
+  fixpanic history --since 30d --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runHistory("")
+	},
+}
+
+// agentHistoryCmd represents the agent history command
+var agentHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show when the agent version changed",
+	Long: `Show every version change of the agent on this host: installs, upgrades
+and failed upgrade attempts, with who made them. 'fixpanic history' also
+includes the CLI's own upgrades.`,
+	Example: `  # Show the agent's version changes of the last week
+  fixpanic agent history --since 7d`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runHistory(state.ComponentAgent)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	agentCmd.AddCommand(agentHistoryCmd)
+
+	// Add flags
+	for _, command := range []*cobra.Command{historyCmd, agentHistoryCmd} {
+		command.Flags().StringVar(&historySince, "since", "", "Only show changes newer than this age (e.g. 30m, 24h, 7d)")
+		command.Flags().BoolVar(&historyJSON, "json", false, "Output changes as JSON")
+	}
+}
+
+// runHistory prints the recorded version changes of component, or of all
+// components if it is empty
+func runHistory(component string) error {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	since, err := audit.ParseSince(historySince, time.Now())
+	if err != nil {
+		return err
+	}
+
+	saved, err := stateStore(platformInfo).Load()
+	if err != nil {
+		return err
+	}
+	changes := []state.VersionChange{}
+	for _, change := range saved.History {
+		if component != "" && change.Component != component {
+			continue
+		}
+		if change.Time.Before(since) {
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	if historyJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(changes)
+	}
+
+	if len(changes) == 0 {
+		logger.Info("No version changes recorded")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tCOMPONENT\tFROM\tTO\tBY\tRESULT")
+	for _, change := range changes {
+		from := change.From
+		if from == "" {
+			from = "-"
+		}
+		result := change.Result
+		if change.Error != "" {
+			result = fmt.Sprintf("%s: %s", change.Result, change.Error)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", change.Time.Local().Format("2006-01-02 15:04:05"), change.Component, from, change.To, change.Initiator, result)
+	}
+	return w.Flush()
+}
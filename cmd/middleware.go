@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/fleet"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+// annotationRequires declares, comma-separated, what a command needs before
+// it runs. The root command's pre-run provides it, in this order:
+//
+//	root          fail unless running as root, offering to run the command
+//	              again with sudo (see offerSudo)
+//	installed     fail unless the agent binary is installed
+//	config        load the agent configuration, failing if it can't be read
+//	lock          hold the installation lock until the command returns, as
+//	              long as the command is mutating (see annotationMutating)
+//	no-telemetry  leave the command out of usage telemetry, for commands run
+//	              by timers rather than people
+//
+// A requirement followed by ":" and a flag condition, as in annotationMutating,
+// only applies when the condition holds: "config:!cli" loads the configuration
+// unless --cli is set. Any requirement resolves the platform first. RunE gets
+// what the middleware resolved from commandPlatform and commandConfig.
+const annotationRequires = "fixpanic.requires"
+
+// Requirements of annotationRequires
+const (
+	requireRoot        = "root"
+	requireInstalled   = "installed"
+	requireConfig      = "config"
+	requireLock        = "lock"
+	requireNoTelemetry = "no-telemetry"
+)
+
+// resolved is what the middleware resolved for the running command
+var resolved struct {
+	platform *platform.PlatformInfo
+	config   *config.AgentConfig
+}
+
+// requires reports whether cmd declares requirement in annotationRequires and
+// its flag condition, if any, holds
+func requires(cmd *cobra.Command, requirement string) bool {
+	for _, declared := range strings.Split(cmd.Annotations[annotationRequires], ",") {
+		name, condition, conditional := strings.Cut(strings.TrimSpace(declared), ":")
+		if name != requirement {
+			continue
+		}
+		return !conditional || flagCondition(cmd, condition)
+	}
+	return false
+}
+
+// unless returns requirement restricted to runs without the boolean flag
+func unless(requirement, flag string) string {
+	return requirement + ":!" + flag
+}
+
+// runMiddleware provides what cmd declares in annotationRequires
+func runMiddleware(cmd *cobra.Command) error {
+	if cmd.Annotations[annotationRequires] == "" {
+		return nil
+	}
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	if requires(cmd, requireRoot) && !platformInfo.IsRoot {
+		if err := offerSudo(cmd, "'%s' requires root", cmd.CommandPath()); err != nil {
+			return err
+		}
+		return clierror.New(clierror.Permission, "'%s' requires root", cmd.CommandPath()).
+			WithHint(i18n.Sprintf("Run it again with sudo: sudo %s", quotedCommandLine()))
+	}
+	if requires(cmd, requireInstalled) {
+		if err := checkInstalled(platformInfo); err != nil {
+			return err
+		}
+	}
+	if requires(cmd, requireConfig) {
+		configPath := platformInfo.GetConfigPath()
+		agentConfig, err := config.LoadConfig(configPath)
+		if err != nil {
+			return clierror.New(clierror.Config, "failed to load configuration: %w", err).
+				WithHint(i18n.Sprintf("Check that %s exists and is valid YAML", configPath), hintReinstallAgent)
+		}
+		resolved.config = agentConfig
+	}
+	if requires(cmd, requireLock) && isMutating(cmd) {
+		return acquireLock(cmd)
+	}
+	return nil
+}
+
+// checkInstalled fails unless the agent binary is installed, for commands
+// that only require it in some modes
+func checkInstalled(platformInfo *platform.PlatformInfo) error {
+	if !connectivity.NewManager(platformInfo).IsFixPanicAgentInstalled() {
+		return clierror.New(clierror.NotInstalled, "FixPanic Agent is not installed").
+			WithHint(hintInstallAgent)
+	}
+	return nil
+}
+
+// commandPlatform returns the platform of the running command, resolved once
+// per process
+func commandPlatform() (*platform.PlatformInfo, error) {
+	if resolved.platform != nil {
+		return resolved.platform, nil
+	}
+	platformInfo, err := platform.GetPlatformInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get platform info: %w", err)
+	}
+	resolved.platform = platformInfo
+	return platformInfo, nil
+}
+
+// commandConfig returns the agent configuration loaded for a command that
+// requires "config"
+func commandConfig() *config.AgentConfig {
+	return resolved.config
+}
+
+// quotedCommandLine returns the command line of this process quoted for a
+// POSIX shell, for hints to paste, with the values of secret flags such as
+// --api-key redacted
+func quotedCommandLine() string {
+	args := redact.Args(os.Args)
+	for i, arg := range args {
+		args[i] = fleet.ShellQuote(arg)
+	}
+	return strings.Join(args, " ")
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRequires(t *testing.T) {
+	newCmd := func(requirements string) *cobra.Command {
+		cmd := &cobra.Command{Use: "set", Annotations: map[string]string{annotationRequires: requirements}}
+		cmd.Flags().Bool("cli", false, "")
+		return cmd
+	}
+
+	tests := []struct {
+		name         string
+		requirements string
+		args         []string
+		requirement  string
+		want         bool
+	}{
+		{"declared", requireInstalled + "," + requireLock, nil, requireLock, true},
+		{"not declared", requireInstalled, nil, requireConfig, false},
+		{"spaces", requireInstalled + ", " + requireConfig, nil, requireConfig, true},
+		{"condition holds", unless(requireConfig, "cli") + "," + requireLock, nil, requireConfig, true},
+		{"condition fails", unless(requireConfig, "cli") + "," + requireLock, []string{"--cli"}, requireConfig, false},
+		{"other requirement unconditional", unless(requireConfig, "cli") + "," + requireLock, []string{"--cli"}, requireLock, true},
+		{"flag must be set", requireInstalled + ":cli", nil, requireInstalled, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newCmd(tt.requirements)
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if got := requires(cmd, tt.requirement); got != tt.want {
+				t.Errorf("requires(%q, %q) with %v = %v, want %v", tt.requirements, tt.requirement, tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuotedCommandLine(t *testing.T) {
+	saved := os.Args
+	t.Cleanup(func() { os.Args = saved })
+	os.Args = []string{"fixpanic", "agent", "install", "--agent-id=agent $(id)", "--api-key", "fp_secret", "--profile", "it's"}
+
+	want := `fixpanic agent install '--agent-id=agent $(id)' --api-key '[REDACTED]' --profile 'it'\''s'`
+	if got := quotedCommandLine(); got != want {
+		t.Errorf("quotedCommandLine() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// networkCmd represents the network command
+var networkCmd = &cobra.Command{
+	Use:   "network",
+	Short: "Diagnose network access to FixPanic",
+	Long: `Diagnose whether this host can reach the FixPanic infrastructure.
+
+Use 'fixpanic agent test-connection' for a quick test of the socket server only.`,
+}
+
+func init() {
+	rootCmd.AddCommand(networkCmd)
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/netprobe"
+	"github.com/spf13/cobra"
+)
+
+// Thresholds a link should meet for comfortable remote-debug sessions
+const (
+	benchMaxLatency    = 250 * time.Millisecond
+	benchMinThroughput = 1_000_000 // bits per second
+	benchMaxReconnect  = 2 * time.Second
+)
+
+var (
+	benchSamples  int
+	benchDuration time.Duration
+)
+
+// networkBenchCmd represents the network bench command
+var networkBenchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure latency and throughput to the socket server",
+	Long: `Measure whether the link to the socket server can support remote-debug
+sessions, before an incident happens.
+
+The benchmark measures:
+  - connect latency percentiles over --samples fresh TCP connections
+  - reconnect time after a dropped connection, including DNS resolution
+  - sustained upload throughput over a test session lasting --duration
+
+Throughput is measured at the TCP level in the upload direction, which is
+the direction debug session output travels. The first second is excluded
+while the kernel send buffer fills.`,
+	Example: `  # Run the benchmark with defaults
+  fixpanic network bench
+
+  # Longer, more precise run
+  fixpanic network bench --samples=50 --duration=30s`,
+	RunE: runNetworkBench,
+}
+
+func init() {
+	networkCmd.AddCommand(networkBenchCmd)
+
+	// Add flags
+	networkBenchCmd.Flags().IntVar(&benchSamples, "samples", 20, "Number of connections used to measure latency")
+	networkBenchCmd.Flags().DurationVar(&benchDuration, "duration", 10*time.Second, "Length of the throughput test")
+}
+
+func runNetworkBench(cmd *cobra.Command, args []string) error {
+	if benchSamples < 1 {
+		return clierror.New(clierror.Usage, "--samples must be at least 1")
+	}
+	if benchDuration < time.Second {
+		return clierror.New(clierror.Usage, "--duration must be at least 1s")
+	}
+
+	logger.Header("FixPanic Network Benchmark")
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	socketServer, err := resolveSocketServer(cmd, platformInfo)
+	if err != nil {
+		return err
+	}
+	logger.KeyValue("Socket server", socketServer)
+	fmt.Println()
+
+	ctx := cmd.Context()
+	var problems []string
+
+	logger.Loading("Measuring connect latency (%d samples)", benchSamples)
+	samples, err := netprobe.Latencies(ctx, socketServer, benchSamples, networkProbeTimeout)
+	if err != nil {
+		logger.LoadingFailed("Latency test failed")
+		return clierror.New(clierror.Network, "failed to connect to %s: %w", socketServer, err).
+			WithHint("Run 'fixpanic network check' to find out what is blocking the connection")
+	}
+	logger.LoadingDone("Latency measured")
+	p50 := netprobe.Percentile(samples, 50)
+	p90 := netprobe.Percentile(samples, 90)
+	p99 := netprobe.Percentile(samples, 99)
+	logger.KeyValue("Latency p50", p50.Round(time.Microsecond).String())
+	logger.KeyValue("Latency p90", p90.Round(time.Microsecond).String())
+	logger.KeyValue("Latency p99", p99.Round(time.Microsecond).String())
+	// Percentile sorted the samples
+	logger.KeyValue("Latency min/max", fmt.Sprintf("%v / %v", samples[0].Round(time.Microsecond), samples[len(samples)-1].Round(time.Microsecond)))
+	if p90 > benchMaxLatency {
+		problems = append(problems, fmt.Sprintf("p90 latency %v exceeds %v", p90.Round(time.Millisecond), benchMaxLatency))
+	}
+
+	logger.Loading("Measuring reconnect time")
+	reconnect, err := netprobe.Reconnect(ctx, socketServer, networkProbeTimeout)
+	if err != nil {
+		logger.LoadingFailed("Reconnect test failed: %v", err)
+		problems = append(problems, "reconnecting after a dropped connection failed")
+	} else {
+		logger.LoadingDone("Reconnect measured")
+		logger.KeyValue("Reconnect time", reconnect.Round(time.Microsecond).String())
+		if reconnect > benchMaxReconnect {
+			problems = append(problems, fmt.Sprintf("reconnect time %v exceeds %v", reconnect.Round(time.Millisecond), benchMaxReconnect))
+		}
+	}
+
+	logger.Loading("Measuring upload throughput (%v)", benchDuration)
+	throughput, err := netprobe.Throughput(ctx, socketServer, benchDuration, networkProbeTimeout)
+	switch {
+	case err != nil:
+		logger.LoadingFailed("Throughput test failed: %v", err)
+		problems = append(problems, "the throughput test failed")
+	case throughput.Duration == 0:
+		logger.LoadingFailed("The socket server closed the test session before throughput could be measured")
+	default:
+		logger.LoadingDone("Throughput measured")
+		logger.KeyValue("Upload throughput", formatBitrate(throughput.BitsPerSecond()))
+		if throughput.ClosedEarly {
+			logger.Warning("The socket server closed the test session after %v; the result may be imprecise", throughput.Duration.Round(time.Millisecond))
+		}
+		if throughput.BitsPerSecond() < benchMinThroughput {
+			problems = append(problems, fmt.Sprintf("upload throughput below %s", formatBitrate(benchMinThroughput)))
+		}
+	}
+
+	logger.Separator()
+	if len(problems) > 0 {
+		logger.Warning("This link may be too slow for remote-debug sessions:")
+		for _, problem := range problems {
+			logger.List("%s", problem)
+		}
+		return nil
+	}
+	logger.Success("This link can support remote-debug sessions")
+	return nil
+}
+
+// formatBitrate renders a rate in bits per second with a decimal unit
+func formatBitrate(bps float64) string {
+	const unit = 1000
+	if bps < unit {
+		return fmt.Sprintf("%.0f bit/s", bps)
+	}
+	div, exp := float64(unit), 0
+	for n := bps / unit; n >= unit && exp < 2; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cbit/s", bps/div, "kMG"[exp])
+}
@@ -0,0 +1,357 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/crash"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/netprobe"
+	"github.com/fixpanic/fixpanic-cli/internal/telemetry"
+	"github.com/fixpanic/fixpanic-cli/internal/workpool"
+	"github.com/spf13/cobra"
+)
+
+// networkProbeTimeout bounds each endpoint probe
+const networkProbeTimeout = 5 * time.Second
+
+// probeParallel is the number of endpoints probed at once
+var probeParallel int
+
+// networkCheckCmd represents the network check command
+var networkCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check outbound access to all FixPanic endpoints",
+	Long: `Verify that this host can reach every endpoint the CLI and the agent use:
+the socket server, the GitHub release API and download hosts, and the FixPanic
+API used for crash reports and telemetry.
+
+HTTPS endpoints are contacted through the proxy selected by HTTPS_PROXY,
+HTTP_PROXY and NO_PROXY, exactly like downloads are. Their certificates are
+checked for signs of a TLS-intercepting middlebox: a chain that doesn't
+verify against the system roots, or an issuer belonging to a known TLS
+inspection product.
+
+Endpoints are probed concurrently, --parallel at a time, so the check takes
+about as long as the slowest endpoint.
+
+When an endpoint is blocked, firewall rules for the firewall tools found on
+this host (ufw, firewalld, iptables) are suggested. The command exits with an
+error if a required endpoint is unreachable.`,
+	Example: `  # Check all endpoints
+  fixpanic network check
+
+  # Check a custom socket server
+  fixpanic network check --socket-server=socket.example.com:9000`,
+	RunE: runNetworkCheck,
+}
+
+func init() {
+	networkCmd.AddCommand(networkCheckCmd)
+
+	// Add flags
+	networkCheckCmd.Flags().IntVar(&probeParallel, "parallel", workpool.DefaultLimit, "Number of endpoints probed at once")
+}
+
+// networkEndpoint is a host the CLI or the agent needs to reach
+type networkEndpoint struct {
+	Name    string
+	Address string
+	// URL is probed over HTTPS for TLS endpoints; raw TCP endpoints leave it empty
+	URL string
+	// Optional endpoints only back opt-in features and don't fail the check
+	Optional bool
+}
+
+// networkCheckResult is the outcome of probing one endpoint
+type networkCheckResult struct {
+	Endpoint networkEndpoint
+	Err      error
+	// Blocked is set when the connection timed out or was refused, which
+	// usually means a firewall is in the way
+	Blocked    bool
+	Family     string
+	DNSLatency time.Duration
+	Latency    time.Duration
+	TLS        *netprobe.TLSResult
+}
+
+// requiredEndpoints lists every endpoint to check, in display order
+func requiredEndpoints(socketServer string) []networkEndpoint {
+	return []networkEndpoint{
+		{Name: "Socket server", Address: socketServer},
+		{Name: "Release API", Address: "api.github.com:443", URL: "https://api.github.com/"},
+		{Name: "Release downloads", Address: "github.com:443", URL: "https://github.com/"},
+		{Name: "Download storage", Address: "objects.githubusercontent.com:443", URL: "https://objects.githubusercontent.com/"},
+		endpointFromURL("Crash reports", crash.DefaultEndpoint, true),
+		endpointFromURL("Telemetry", telemetry.DefaultEndpoint, true),
+	}
+}
+
+// endpointFromURL derives an HTTPS endpoint from a service URL
+func endpointFromURL(name, rawURL string, optional bool) networkEndpoint {
+	endpoint := networkEndpoint{Name: name, URL: rawURL, Optional: optional}
+	if parsed, err := url.Parse(rawURL); err == nil {
+		endpoint.Address, _ = netprobe.NormalizeEndpoint(parsed.Host, "443")
+		endpoint.URL = parsed.Scheme + "://" + parsed.Host + "/"
+	}
+	return endpoint
+}
+
+func runNetworkCheck(cmd *cobra.Command, args []string) error {
+	logger.Header("FixPanic Network Check")
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	socketServer, err := resolveSocketServer(cmd, platformInfo)
+	if err != nil {
+		return err
+	}
+
+	endpoints := requiredEndpoints(socketServer)
+	proxy := ""
+	for _, endpoint := range endpoints {
+		if endpoint.URL != "" {
+			proxy, _ = netprobe.ProxyFor(endpoint.URL)
+			break
+		}
+	}
+	if proxy == "" {
+		proxy = "none (direct connection)"
+	}
+	logger.KeyValue("HTTPS proxy", proxy)
+	fmt.Println()
+
+	ctx := cmd.Context()
+	results := workpool.Map(ctx, endpoints, probeParallel, probeEndpoint)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	var failures, warnings int
+	for _, result := range results {
+		endpoint := result.Endpoint
+		label := fmt.Sprintf("%s (%s)", endpoint.Name, endpoint.Address)
+		switch {
+		case result.Err != nil && endpoint.Optional:
+			warnings++
+			fmt.Printf("⚠️  %s: %s (optional)\n", label, describeNetworkError(result.Err))
+		case result.Err != nil:
+			failures++
+			fmt.Printf("❌ %s: %s\n", label, describeNetworkError(result.Err))
+		case result.TLS != nil && result.TLS.VerifyError != nil:
+			failures++
+			fmt.Printf("❌ %s: certificate not trusted, TLS appears to be intercepted\n", label)
+		case result.TLS != nil && result.TLS.Interceptor != "":
+			warnings++
+			fmt.Printf("⚠️  %s: reachable, but TLS is inspected by %s\n", label, result.TLS.Interceptor)
+		default:
+			via := result.Family
+			if result.TLS != nil && result.TLS.Proxy != "" {
+				via = "proxy"
+			}
+			timing := result.Latency.Round(time.Millisecond).String()
+			if result.DNSLatency > 0 {
+				timing += ", DNS " + result.DNSLatency.Round(time.Millisecond).String()
+			}
+			fmt.Printf("✅ %s: reachable via %s (%s)\n", label, via, timing)
+		}
+
+		if result.TLS != nil {
+			fmt.Printf("     Certificate: %s, issued by %s\n", result.TLS.Subject, result.TLS.Issuer)
+			if result.TLS.VerifyError != nil {
+				fmt.Printf("     %v\n", result.TLS.VerifyError)
+			}
+		}
+	}
+
+	var blocked []networkCheckResult
+	intercepted := false
+	for _, result := range results {
+		if result.Blocked {
+			blocked = append(blocked, result)
+		}
+		if result.TLS != nil && result.TLS.Intercepted() {
+			intercepted = true
+		}
+	}
+
+	if len(blocked) > 0 {
+		fmt.Println()
+		printFirewallSuggestions(ctx, blocked)
+	}
+
+	logger.Separator()
+	if failures > 0 {
+		hints := []string{"Allow outbound TCP to the endpoints above, or route them through your HTTPS proxy"}
+		if intercepted {
+			hints = append(hints, "Exclude the FixPanic and GitHub hosts from TLS inspection, or add the inspection CA to the system trust store")
+		}
+		return clierror.New(clierror.Network, "%d required endpoint(s) unreachable", failures).WithHint(hints...)
+	}
+	if warnings > 0 {
+		logger.Warning("Network check finished with %d warning(s)", warnings)
+	} else {
+		logger.Success("All endpoints are reachable")
+	}
+
+	return nil
+}
+
+// probeEndpoint connects to an endpoint, over HTTPS when it has a URL
+func probeEndpoint(ctx context.Context, endpoint networkEndpoint) networkCheckResult {
+	result := networkCheckResult{Endpoint: endpoint}
+
+	if endpoint.URL != "" {
+		tlsResult, err := netprobe.InspectTLS(ctx, endpoint.URL, networkProbeTimeout)
+		if err != nil {
+			result.Err = err
+			// Through a proxy we can't tell which hop dropped the connection
+			if proxy, _ := netprobe.ProxyFor(endpoint.URL); proxy == "" {
+				result.Blocked = isBlockedError(err)
+			}
+			return result
+		}
+		result.TLS = tlsResult
+		result.DNSLatency = tlsResult.DNSLatency
+		result.Latency = tlsResult.Latency
+		return result
+	}
+
+	dialed, err := netprobe.Dial(ctx, endpoint.Address, networkProbeTimeout)
+	if err != nil {
+		result.Err = err
+		result.Blocked = isBlockedError(err)
+		return result
+	}
+	result.Family = dialed.Family
+	result.DNSLatency = dialed.DNSLatency
+	result.Latency = dialed.Latency
+	return result
+}
+
+// isBlockedError reports whether err looks like a firewall dropping or
+// rejecting the connection rather than a DNS or protocol problem
+func isBlockedError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// describeNetworkError turns a probe error into a short explanation
+func describeNetworkError(err error) string {
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &dnsErr):
+		return i18n.Sprintf("DNS lookup failed for %s", dnsErr.Name)
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection refused"
+	case isBlockedError(err):
+		return "connection timed out (likely dropped by a firewall)"
+	}
+	return err.Error()
+}
+
+// printFirewallSuggestions prints rules that would allow the blocked
+// endpoints for each firewall tool installed on this host
+func printFirewallSuggestions(ctx context.Context, blocked []networkCheckResult) {
+	if runtime.GOOS != "linux" {
+		logger.Info("Allow outbound TCP connections to these endpoints in your firewall:")
+		for _, result := range blocked {
+			logger.List("%s", result.Endpoint.Address)
+		}
+		return
+	}
+
+	var tools []string
+	for _, tool := range []string{"ufw", "firewall-cmd", "iptables"} {
+		if _, err := exec.LookPath(tool); err == nil {
+			tools = append(tools, tool)
+		}
+	}
+	if len(tools) == 0 {
+		tools = []string{"iptables"}
+	}
+
+	logger.Info("Suggested firewall rules to allow the blocked endpoints:")
+	for _, tool := range tools {
+		fmt.Printf("\n  # %s\n", tool)
+		for _, result := range blocked {
+			for _, rule := range firewallRules(ctx, tool, result.Endpoint.Address) {
+				fmt.Printf("  %s\n", rule)
+			}
+		}
+		if tool == "firewall-cmd" {
+			fmt.Println("  sudo firewall-cmd --reload")
+		}
+	}
+	fmt.Println()
+	logger.Info("GitHub's addresses change over time; prefer allowing these hosts by name in an HTTPS proxy")
+}
+
+// firewallRules returns the commands allowing outbound TCP to address with
+// the given tool. Hosts are resolved so IPv4 and IPv6 rules can be emitted;
+// if resolution fails the host name is used, which iptables resolves once
+// when the rule is added.
+func firewallRules(ctx context.Context, tool, address string) []string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil
+	}
+
+	type target struct {
+		addr string
+		v6   bool
+	}
+	var targets []target
+	if v4, v6, err := netprobe.Resolve(ctx, host); err == nil {
+		for _, ip := range v4 {
+			targets = append(targets, target{addr: ip.String()})
+		}
+		for _, ip := range v6 {
+			targets = append(targets, target{addr: ip.String(), v6: true})
+		}
+	}
+	if len(targets) == 0 {
+		targets = []target{{addr: host}}
+	}
+
+	var rules []string
+	for _, t := range targets {
+		switch tool {
+		case "ufw":
+			rules = append(rules, fmt.Sprintf("sudo ufw allow out to %s port %s proto tcp", t.addr, port))
+		case "firewall-cmd":
+			family := "ipv4"
+			if t.v6 {
+				family = "ipv6"
+			}
+			rules = append(rules, fmt.Sprintf("sudo firewall-cmd --permanent --direct --add-rule %s filter OUTPUT 0 -d %s -p tcp --dport %s -j ACCEPT", family, t.addr, port))
+		default:
+			binary := "iptables"
+			if t.v6 {
+				binary = "ip6tables"
+			}
+			rules = append(rules, fmt.Sprintf("sudo %s -I OUTPUT -d %s -p tcp --dport %s -j ACCEPT", binary, t.addr, port))
+		}
+	}
+	return rules
+}
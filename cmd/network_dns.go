@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/netprobe"
+	"github.com/fixpanic/fixpanic-cli/internal/workpool"
+	"github.com/spf13/cobra"
+)
+
+// dnsResolvers are the resolvers compared against the system resolver
+var dnsResolvers []string
+
+// networkDNSCmd represents the network dns command
+var networkDNSCmd = &cobra.Command{
+	Use:   "dns [host...]",
+	Short: "Compare DNS answers of the system and public resolvers",
+	Long: `Resolve the FixPanic and GitHub hosts with the system resolver and with
+public resolvers, and compare the answers.
+
+Each lookup reports its latency and is given up after 5s, so a resolver
+that drops queries shows up as timed out instead of hanging the check. The
+comparison detects:
+  - names the system resolver can't resolve but public DNS can, e.g. a
+    broken or overly strict internal DNS server
+  - split-horizon DNS, where the system resolver returns internal addresses
+    for a name that resolves to public addresses elsewhere
+  - public resolvers that can't be reached because outbound DNS is blocked
+
+Answers that merely differ between public addresses are normal for CDNs and
+geo-routed DNS and are not reported as a problem. The command exits with an
+error if the system resolver fails for any host.`,
+	Example: `  # Compare the system resolver with 1.1.1.1 and 8.8.8.8
+  fixpanic network dns
+
+  # Check specific hosts against a corporate resolver
+  fixpanic network dns socket.fixpanic.com api.github.com --resolver=10.0.0.53`,
+	RunE: runNetworkDNS,
+}
+
+func init() {
+	networkCmd.AddCommand(networkDNSCmd)
+
+	// Add flags
+	networkDNSCmd.Flags().StringSliceVar(&dnsResolvers, "resolver", netprobe.PublicResolvers, "DNS servers to compare with the system resolver")
+	networkDNSCmd.Flags().IntVar(&probeParallel, "parallel", workpool.DefaultLimit, "Number of lookups run at once")
+}
+
+// dnsQuery is a lookup of one host with one resolver
+type dnsQuery struct {
+	Host     string
+	Resolver string
+}
+
+func runNetworkDNS(cmd *cobra.Command, args []string) error {
+	for _, resolver := range dnsResolvers {
+		if _, err := netprobe.NewResolver(resolver); err != nil {
+			return clierror.Wrap(clierror.Usage, err)
+		}
+	}
+
+	logger.Header("FixPanic DNS Check")
+
+	hosts := args
+	if len(hosts) == 0 {
+		platformInfo, err := commandPlatform()
+		if err != nil {
+			return err
+		}
+		socketServer, err := resolveSocketServer(cmd, platformInfo)
+		if err != nil {
+			return err
+		}
+		hosts = dnsHosts(requiredEndpoints(socketServer))
+	}
+
+	resolvers := append([]string{netprobe.SystemResolver}, dnsResolvers...)
+	var queries []dnsQuery
+	for _, host := range hosts {
+		for _, resolver := range resolvers {
+			queries = append(queries, dnsQuery{Host: host, Resolver: resolver})
+		}
+	}
+
+	ctx := cmd.Context()
+	results := workpool.Map(ctx, queries, probeParallel, func(ctx context.Context, query dnsQuery) netprobe.LookupResult {
+		return netprobe.Lookup(ctx, query.Resolver, query.Host, networkProbeTimeout)
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	width := 0
+	for _, resolver := range resolvers {
+		width = max(width, len(resolver))
+	}
+
+	var failures, warnings int
+	// unreachable counts the hosts each public resolver timed out for
+	unreachable := map[string]int{}
+	for i, host := range hosts {
+		answers := results[i*len(resolvers) : (i+1)*len(resolvers)]
+		fmt.Printf("\n%s\n", host)
+		for _, answer := range answers {
+			if answer.Err != nil {
+				fmt.Printf("  ❌ %-*s  %s\n", width, answer.Resolver, netprobe.DescribeDNSError(answer.Err, networkProbeTimeout))
+				if answer.Resolver != netprobe.SystemResolver && isDNSTimeout(answer.Err) {
+					unreachable[answer.Resolver]++
+				}
+				continue
+			}
+			fmt.Printf("  ✅ %-*s  %-9v %s\n", width, answer.Resolver, answer.Latency.Round(time.Microsecond), formatIPs(answer.Addresses))
+		}
+
+		verdict, status := compareDNSAnswers(answers[0], answers[1:])
+		switch status {
+		case doctorFail:
+			failures++
+			fmt.Printf("  ❌ %s\n", verdict)
+		case doctorWarn:
+			warnings++
+			fmt.Printf("  ⚠️  %s\n", verdict)
+		default:
+			fmt.Printf("  💡 %s\n", verdict)
+		}
+	}
+
+	for _, resolver := range dnsResolvers {
+		if unreachable[resolver] == len(hosts) {
+			warnings++
+			fmt.Printf("\n⚠️  %s did not answer any query: outbound DNS (port 53) to it may be blocked, which is fine as long as the system resolver works\n", resolver)
+		}
+	}
+
+	logger.Separator()
+	if failures > 0 {
+		return clierror.New(clierror.Network, "the system resolver failed for %d host(s)", failures).
+			WithHint("Check the nameservers in /etc/resolv.conf (or your network settings) and any DNS overrides for these hosts")
+	}
+	if warnings > 0 {
+		logger.Warning("DNS check finished with %d warning(s)", warnings)
+	} else {
+		logger.Success("DNS answers are consistent")
+	}
+	return nil
+}
+
+// dnsHosts returns the host names of endpoints, without duplicates and IP
+// literals
+func dnsHosts(endpoints []networkEndpoint) []string {
+	var hosts []string
+	seen := map[string]bool{}
+	for _, endpoint := range endpoints {
+		host, _, err := net.SplitHostPort(endpoint.Address)
+		if err != nil || net.ParseIP(host) != nil || seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// compareDNSAnswers judges the system resolver's answer against the public
+// ones and returns a verdict with its status
+func compareDNSAnswers(system netprobe.LookupResult, public []netprobe.LookupResult) (string, doctorStatus) {
+	var resolved []netprobe.LookupResult
+	for _, answer := range public {
+		if answer.Err == nil {
+			resolved = append(resolved, answer)
+		}
+	}
+
+	if system.Err != nil {
+		if len(resolved) > 0 {
+			return fmt.Sprintf("The system resolver can't resolve %s (%s), but %s can", system.Host, netprobe.DescribeDNSError(system.Err, networkProbeTimeout), resolved[0].Resolver), doctorFail
+		}
+		return fmt.Sprintf("No resolver could resolve %s", system.Host), doctorFail
+	}
+	if len(resolved) == 0 {
+		if len(public) == 0 {
+			return "Resolved by the system resolver", doctorOK
+		}
+		return "Only the system resolver knows this name, so it is probably internal", doctorOK
+	}
+
+	for _, answer := range resolved {
+		if netprobe.AllInternal(system.Addresses) && !netprobe.AllInternal(answer.Addresses) {
+			return fmt.Sprintf("Split-horizon DNS: the system resolver returns internal addresses (%s) while %s returns %s; make sure the internal address serves the same endpoint",
+				formatIPs(system.Addresses), answer.Resolver, formatIPs(answer.Addresses)), doctorWarn
+		}
+	}
+	for _, answer := range resolved {
+		if !netprobe.SameAddresses(system.Addresses, answer.Addresses) {
+			return "Answers differ between resolvers, which is normal for CDNs and geo-routed DNS", doctorOK
+		}
+	}
+	return "All resolvers agree", doctorOK
+}
+
+// isDNSTimeout reports whether a lookup failed because the resolver didn't
+// answer in time
+func isDNSTimeout(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &dnsErr) && dnsErr.IsTimeout) ||
+		strings.Contains(err.Error(), "i/o timeout")
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/fixpanic/fixpanic-cli/internal/term"
+)
+
+// pagerCommand returns the pager output is paged through: $PAGER, or less
+// where installed. It returns nil when stdout isn't a terminal or there is
+// no pager to use.
+func pagerCommand() []string {
+	if !term.IsTerminal(os.Stdout) {
+		return nil
+	}
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		if _, err := exec.LookPath("less"); err != nil {
+			return nil
+		}
+		pager = []string{"less"}
+	}
+	if pager[0] == "cat" {
+		return nil
+	}
+	return pager
+}
+
+// withPager calls fn with a writer that pages its output through the user's
+// pager when stdout is a terminal, and with stdout otherwise. Unless $LESS
+// says otherwise, less shows colors and exits right away when the output
+// fits on one screen.
+func withPager(fn func(w io.Writer) error) error {
+	pager := pagerCommand()
+	if pager == nil {
+		return fn(os.Stdout)
+	}
+
+	cmd := exec.Command(pager[0], pager[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fn(os.Stdout)
+	}
+	if err := cmd.Start(); err != nil {
+		return fn(os.Stdout)
+	}
+
+	err = fn(stdin)
+	stdin.Close()
+	cmd.Wait()
+	// Quitting the pager before the end closes the pipe; that's not a failure
+	if errors.Is(err, syscall.EPIPE) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/fsperm"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/spf13/viper"
+)
+
+// Remediation hint for installations that drifted from the permission policy
+const hintReapplyPermissions = "Re-apply the policy with 'fixpanic agent upgrade' or 'fixpanic agent install --force'"
+
+func init() {
+	// Permission policy for the installed trees; also read from the
+	// "permissions" section of the CLI config file
+	agentCmd.PersistentFlags().String("dir-mode", "", "Mode for the agent's lib, config and log directories, e.g. 0750 (default from umask)")
+	agentCmd.PersistentFlags().String("file-mode", "", "Mode for files in those directories, e.g. 0640; executables also get execute bits")
+	agentCmd.PersistentFlags().String("owner", "", "User owning the agent's directories (name or UID)")
+	agentCmd.PersistentFlags().String("group", "", "Group owning the agent's directories (name or GID)")
+	viper.BindPFlag("permissions.dir_mode", agentCmd.PersistentFlags().Lookup("dir-mode"))
+	viper.BindPFlag("permissions.file_mode", agentCmd.PersistentFlags().Lookup("file-mode"))
+	viper.BindPFlag("permissions.owner", agentCmd.PersistentFlags().Lookup("owner"))
+	viper.BindPFlag("permissions.group", agentCmd.PersistentFlags().Lookup("group"))
+}
+
+// permissionPolicy returns the configured permission policy
+func permissionPolicy() (*fsperm.Policy, error) {
+	policy, err := cliSettings().PermissionPolicy()
+	if err != nil {
+		return nil, clierror.Wrap(clierror.Usage, err)
+	}
+	return policy, nil
+}
+
+// managedDirs returns the directory trees the permission policy applies to
+func managedDirs(platformInfo *platform.PlatformInfo) []string {
+	return agentops.ManagedDirs(platformInfo)
+}
+
+// applyPermissions enforces the configured permission policy on the installation
+func applyPermissions(platformInfo *platform.PlatformInfo) error {
+	policy, err := permissionPolicy()
+	if err != nil {
+		return err
+	}
+	return agentops.ApplyPermissions(platformInfo, policy)
+}
+
+// checkPermissions returns the files that violate the configured permission policy
+func checkPermissions(platformInfo *platform.PlatformInfo) ([]fsperm.Violation, error) {
+	policy, err := permissionPolicy()
+	if err != nil {
+		return nil, err
+	}
+	return agentops.CheckPermissions(platformInfo, policy)
+}
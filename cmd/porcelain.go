@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/spf13/cobra"
+)
+
+// porcelainVersion is the --porcelain format written by default. Keys are
+// only ever added to a format; changing what an existing key means gets a
+// new format version, and older ones keep being written on request.
+const porcelainVersion = "v1"
+
+var porcelainFormat string
+
+// addPorcelainFlag adds --porcelain[=<version>] to a command that reports
+// status or information
+func addPorcelainFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&porcelainFormat, "porcelain", "", "Output stable tab-separated key/value lines for scripts (format version, default "+porcelainVersion+")")
+	cmd.Flags().Lookup("porcelain").NoOptDefVal = porcelainVersion
+}
+
+// porcelainEscaper keeps every value on one line
+var porcelainEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// porcelainWriter writes --porcelain output: a "# porcelain v1" line naming
+// the format, then one "key<TAB>value" line per field. Keys are lowercase
+// and dotted, values have backslashes, tabs and newlines escaped as in Go
+// strings, and absent values are empty.
+type porcelainWriter struct {
+	w   io.Writer
+	err error
+}
+
+// newPorcelainWriter starts --porcelain output on w
+func newPorcelainWriter(w io.Writer) (*porcelainWriter, error) {
+	if porcelainFormat != porcelainVersion {
+		return nil, clierror.New(clierror.Usage, "unknown --porcelain format %q: use %s", porcelainFormat, porcelainVersion)
+	}
+	p := &porcelainWriter{w: w}
+	_, p.err = fmt.Fprintf(w, "# porcelain %s\n", porcelainFormat)
+	return p, nil
+}
+
+// field writes a key and its value. Times are written in RFC 3339 (UTC),
+// zero times and nil pointers as empty values.
+func (p *porcelainWriter) field(key string, value interface{}) {
+	if p.err != nil {
+		return
+	}
+	var text string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		text = v
+	case time.Time:
+		if !v.IsZero() {
+			text = v.UTC().Format(time.RFC3339)
+		}
+	case *time.Time:
+		if v != nil && !v.IsZero() {
+			text = v.UTC().Format(time.RFC3339)
+		}
+	default:
+		text = fmt.Sprint(v)
+	}
+	_, p.err = fmt.Fprintf(p.w, "%s\t%s\n", key, porcelainEscaper.Replace(text))
+}
+
+// close returns the first error writing the output
+func (p *porcelainWriter) close() error {
+	return p.err
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fixpanic/fixpanic-cli/internal/term"
+)
+
+// readLine reads a line of user input, giving up when ctx is cancelled so a
+// prompt can be aborted with Ctrl+C
+func readLine(ctx context.Context) (string, error) {
+	lines := make(chan string, 1)
+	go func() {
+		var line string
+		fmt.Scanln(&line)
+		lines <- line
+	}()
+
+	select {
+	case line := <-lines:
+		return line, nil
+	case <-ctx.Done():
+		fmt.Fprintln(os.Stderr)
+		return "", ctx.Err()
+	}
+}
+
+// isInteractive reports whether stdin is a terminal the user can answer prompts on
+func isInteractive() bool {
+	return term.IsTerminal(os.Stdin)
+}
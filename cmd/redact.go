@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/redact"
+	"github.com/spf13/viper"
+)
+
+// newRedactor returns the redactor for diagnostics that leave this host. It
+// uses the built-in patterns, the extra patterns of the "redaction" section
+// of the CLI config file and the API key of the agent configuration, and
+// also hides IP addresses when ips or redaction.ips is set.
+func newRedactor(platformInfo *platform.PlatformInfo, ips bool) (*redact.Redactor, error) {
+	opts := redact.Options{
+		IPs:      ips || viper.GetBool("redaction.ips"),
+		Patterns: viper.GetStringSlice("redaction.patterns"),
+	}
+	if platformInfo != nil {
+		if agentConfig, err := config.LoadConfig(platformInfo.GetConfigPath()); err == nil {
+			opts.Secrets = append(opts.Secrets, agentConfig.App.APIKey)
+		}
+	}
+
+	redactor, err := redact.New(opts)
+	if err != nil {
+		return nil, clierror.WithHint(clierror.Wrap(clierror.Config, err), "Fix the patterns in the redaction section of ~/.fixpanic.yaml")
+	}
+	return redactor, nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fixpanic/fixpanic-cli/internal/httpcache"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/releases"
+)
+
+// showNotesOnly makes 'upgrade' and 'agent upgrade' print the release notes
+// of the pending upgrade without upgrading
+var showNotesOnly bool
+
+// showReleaseNotes prints the notes of every release between the installed
+// version and target, newest first
+func showReleaseNotes(ctx context.Context, repo, installed, target string) error {
+	notes, err := releases.Between(ctx, httpcache.Default(), repo, installed, target)
+	if err != nil {
+		return err
+	}
+	if len(notes) == 0 {
+		logger.Info("No release notes between %s and %s", installed, target)
+		return nil
+	}
+
+	logger.Separator()
+	if len(notes) == 1 {
+		logger.Info("Release notes for %s:", target)
+	} else {
+		logger.Info("Release notes from %s to %s (%d releases):", installed, target, len(notes))
+	}
+	for _, release := range notes {
+		logger.Plain("")
+		title := "## " + release.TagName
+		if !release.PublishedAt.IsZero() {
+			title += " (" + release.PublishedAt.Format("2006-01-02") + ")"
+		}
+		if release.Prerelease {
+			title += " [pre-release]"
+		}
+		logger.Plain("%s", title)
+		if reason, yanked := release.Yanked(); yanked && reason != "" {
+			logger.Warning("Yanked: %s", reason)
+		}
+		body := strings.TrimSpace(strings.ReplaceAll(release.Body, "\r\n", "\n"))
+		if body == "" {
+			logger.Plain("No release notes")
+			continue
+		}
+		logger.Plain("%s", body)
+	}
+	logger.Separator()
+	return nil
+}
@@ -1,18 +1,41 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"runtime/debug"
+	"strings"
+	"time"
 
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/events"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/interrupt"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/summary"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
 	cfgFile string
+	lang    string
+	timeout time.Duration
+	prefix  string
 	version string
 	commit  string
 	date    string
+
+	// Machine-readable progress events, see internal/events
+	eventsFD   int
+	eventsFile string
+
+	// cancelTimeout releases the --timeout context once the command returned
+	cancelTimeout context.CancelFunc = func() {}
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -23,14 +46,81 @@ var rootCmd = &cobra.Command{
 on customer servers. It handles agent installation, configuration, and lifecycle management.
 
 The CLI downloads and manages the connectivity layer binary, sets up systemd services,
-and provides commands for testing and validation.`,
-	Version: "dev",
+and provides commands for testing and validation.
+
+The most common agent commands are also available without the agent
+prefix: status, logs, start, stop and restart, e.g. 'fixpanic status'.
+Scripts should keep using the canonical 'fixpanic agent' form.
+
+Failures exit with a documented code so scripts can branch on them; see
+'fixpanic help exit-codes'.
+
+Output language follows LANG (supported: en, de, ja) and can be overridden
+with --lang.
+
+Global flags can also be set with FIXPANIC_<KEY> environment variables or
+keys of the config file; 'fixpanic config effective' shows the values in
+effect and where they come from.
+
+Ctrl+C or --timeout cancel the running operation cleanly: downloads are
+aborted, child processes stopped, temporary files removed and an interrupted
+install rolled back. Press Ctrl+C twice to exit immediately; steps that
+can't be cut short, like replacing a binary, finish first.`,
+	Version:           "dev",
+	PersistentPreRunE: preRun,
+	// Errors are reported with remediation hints by main
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
-func Execute() error {
-	return rootCmd.Execute()
+func Execute() (err error) {
+	started := time.Now()
+
+	// Report panics as a crash report instead of a raw stack trace
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = handleCrash(recovered, debug.Stack())
+		}
+	}()
+
+	// Ctrl+C and SIGTERM cancel the command's context so it can stop child
+	// processes and remove temporary files; a second signal exits immediately
+	// unless a critical step is running
+	ctx, stop := interrupt.Notify(context.Background())
+	defer stop()
+
+	enableSuggestions(rootCmd)
+	classifyArgErrors(rootCmd)
+	var executed *cobra.Command
+	executed, err = rootCmd.ExecuteContextC(ctx)
+	err = classifyCancellation(ctx, executed, err)
+	steps := printSummary(executed, err)
+	releaseLock()
+	printUpdateNotice(err)
+	cancelTimeout()
+	recordAudit(executed, started, err)
+	recordTelemetry(executed, started, err)
+	completeEvents(executed, started, err, steps)
+	finishTrace(err)
+	return err
+}
+
+// classifyCancellation reports commands aborted by a signal or --timeout with
+// their dedicated exit codes
+func classifyCancellation(signalCtx context.Context, executed *cobra.Command, err error) error {
+	if err == nil {
+		return nil
+	}
+	if signalCtx.Err() != nil {
+		return clierror.New(clierror.Interrupted, "interrupted")
+	}
+	if executed != nil && errors.Is(executed.Context().Err(), context.DeadlineExceeded) {
+		return clierror.New(clierror.Timeout, "timed out after %s: %w", timeout, err).
+			WithHint("Re-run with a longer --timeout")
+	}
+	return err
 }
 
 // SetVersionInfo sets the version information for the CLI
@@ -50,8 +140,128 @@ func init() {
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.fixpanic.yaml)")
-	rootCmd.PersistentFlags().String("socket-server", "socket.fixpanic.com:8080", "Socket server address")
-	viper.BindPFlag("socket_server", rootCmd.PersistentFlags().Lookup("socket-server"))
+	rootCmd.PersistentFlags().String("socket-server", config.DefaultSocketServer, "Socket server address (host:port, [ipv6]:port)")
+	rootCmd.PersistentFlags().StringVar(&lang, "lang", "", "Output language (en, de, ja; default from LANG)")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Abort the command after this long, e.g. 5m (0 waits indefinitely)")
+	rootCmd.PersistentFlags().StringVar(&prefix, "prefix", "", "Keep the agent binary, config and logs below this directory (overrides $"+platform.EnvHome+")")
+	rootCmd.PersistentFlags().IntVar(&eventsFD, "events-fd", 0, "Write line-delimited JSON progress events to this inherited file descriptor (3 or higher)")
+	rootCmd.PersistentFlags().StringVar(&eventsFile, "events-file", "", "Append line-delimited JSON progress events to this file")
+	rootCmd.PersistentFlags().BoolVar(&noSudo, "no-sudo", false, "Fail instead of offering to run commands that need root again with sudo")
+
+	// Report flag parsing failures with the usage exit code
+	rootCmd.SetFlagErrorFunc(flagError)
+}
+
+// preRun applies global settings and policies before any command runs
+func preRun(cmd *cobra.Command, args []string) error {
+	if err := applySettings(cmd); err != nil {
+		return err
+	}
+	if lang != "" {
+		if err := i18n.SetLanguage(lang); err != nil {
+			return clierror.Wrap(clierror.Usage, err)
+		}
+	}
+	if prefix != "" {
+		if err := platform.SetPrefix(prefix); err != nil {
+			return clierror.Wrap(clierror.Usage, err)
+		}
+	}
+	applyCLIConfig()
+	if err := openEvents(cmd); err != nil {
+		return err
+	}
+	if err := startTrace(cmd); err != nil {
+		return err
+	}
+	if timeout > 0 {
+		ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+		cmd.SetContext(ctx)
+		cancelTimeout = cancel
+	}
+	if err := enforceReadOnly(cmd, args); err != nil {
+		return err
+	}
+	if err := runMiddleware(cmd); err != nil {
+		return err
+	}
+	startUpdateNotice(cmd)
+	return nil
+}
+
+// openEvents starts the event stream requested with --events-fd or --events-file
+func openEvents(cmd *cobra.Command) error {
+	var err error
+	switch {
+	case cmd.Flags().Changed("events-fd") && eventsFile != "":
+		return clierror.New(clierror.Usage, "--events-fd and --events-file are mutually exclusive")
+	case cmd.Flags().Changed("events-fd"):
+		err = events.OpenFD(eventsFD)
+	case eventsFile != "":
+		err = events.OpenFile(eventsFile)
+	default:
+		return nil
+	}
+	if err != nil {
+		return clierror.Wrap(clierror.Usage, err)
+	}
+
+	events.Emit(events.Event{Type: events.CommandStarted, Command: cmd.CommandPath()})
+	return nil
+}
+
+// completeEvents ends the event stream with the command's outcome and steps
+func completeEvents(executed *cobra.Command, started time.Time, err error, steps []summary.Step) {
+	if !events.Enabled() {
+		return
+	}
+	command := ""
+	if executed != nil {
+		command = executed.CommandPath()
+	}
+	events.Complete(command, clierror.ExitCode(err), err, time.Since(started), steps)
+}
+
+// isReadOnly reports whether mutating commands are disabled, either through
+// FIXPANIC_READ_ONLY or the cli.read_only key of the CLI config file
+func isReadOnly() bool {
+	return cliSettings().IsReadOnly()
+}
+
+// cliSettings returns the safeguards configured in the CLI config file, the
+// environment and the flags bound to them
+func cliSettings() agentops.Settings {
+	return agentops.Settings{
+		ReadOnly: viper.GetBool("cli.read_only"),
+		DirMode:  viper.GetString("permissions.dir_mode"),
+		FileMode: viper.GetString("permissions.file_mode"),
+		Owner:    viper.GetString("permissions.owner"),
+		Group:    viper.GetString("permissions.group"),
+	}
+}
+
+// annotationEmergency marks mutating commands that still run in read-only
+// mode: read-only mode keeps the installation from being changed, not a
+// compromised agent from being shut down.
+const annotationEmergency = "fixpanic.emergency"
+
+// enforceReadOnly refuses to run mutating commands in read-only mode. Dry
+// runs and plans are allowed since they don't change anything, and so are
+// emergency commands (see annotationEmergency).
+func enforceReadOnly(cmd *cobra.Command, args []string) error {
+	if cmd.Annotations[annotationEmergency] == "true" {
+		return nil
+	}
+	if dryRun, err := cmd.Flags().GetBool("dry-run"); err == nil && dryRun {
+		return nil
+	}
+	if planOnly, err := cmd.Flags().GetBool("plan"); err == nil && planOnly {
+		return nil
+	}
+	if isMutating(cmd) && isReadOnly() {
+		return clierror.New(clierror.ReadOnly, "'%s' modifies the installation and is disabled in read-only mode (cli.read_only / FIXPANIC_READ_ONLY)", cmd.CommandPath())
+	}
+	return nil
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -70,7 +280,11 @@ func initConfig() {
 		viper.SetConfigName(".fixpanic")
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	// read in environment variables that match, e.g. FIXPANIC_SOCKET_SERVER
+	// for socket_server
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
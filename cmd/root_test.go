@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+)
+
+func TestEnforceReadOnlyAllowsEmergencyStop(t *testing.T) {
+	t.Setenv(agentops.EnvReadOnly, "1")
+
+	if err := enforceReadOnly(agentPanicStopCmd, nil); err != nil {
+		t.Errorf("panic-stop refused in read-only mode: %v", err)
+	}
+	if code := clierror.CodeOf(enforceReadOnly(agentStartCmd, nil)); code != clierror.ReadOnly {
+		t.Errorf("agent start exit code = %v, want %v", code, clierror.ReadOnly)
+	}
+}
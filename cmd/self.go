@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// selfCmd represents the self command
+var selfCmd = &cobra.Command{
+	Use:   "self",
+	Short: "Manage the fixpanic CLI itself",
+	Long: `Manage the fixpanic CLI itself rather than the agent.
+
+Use 'fixpanic upgrade' to update the CLI.`,
+}
+
+func init() {
+	rootCmd.AddCommand(selfCmd)
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/state"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Self-healing defaults, used when the self_heal section leaves them unset
+const (
+	defaultSelfHealAfter       = 3
+	defaultSelfHealMaxAttempts = 3
+	defaultSelfHealCooloff     = 15 * time.Minute
+)
+
+// selfHealSettings is the self_heal section of the CLI config file
+type selfHealSettings struct {
+	Enabled bool
+	// After is how many health checks in a row must fail before a restart
+	After int
+	// MaxAttempts is how many restarts are tried before giving up until the
+	// agent is healthy again
+	MaxAttempts int
+	// Cooloff is the minimum time between restarts
+	Cooloff time.Duration
+}
+
+// loadSelfHealSettings reads the self_heal section of the CLI config file
+func loadSelfHealSettings() selfHealSettings {
+	settings := selfHealSettings{
+		Enabled:     viper.GetBool("self_heal.enabled"),
+		After:       viper.GetInt("self_heal.after"),
+		MaxAttempts: viper.GetInt("self_heal.max_attempts"),
+		Cooloff:     viper.GetDuration("self_heal.cooloff"),
+	}
+	if settings.After <= 0 {
+		settings.After = defaultSelfHealAfter
+	}
+	if settings.MaxAttempts <= 0 {
+		settings.MaxAttempts = defaultSelfHealMaxAttempts
+	}
+	if settings.Cooloff <= 0 {
+		settings.Cooloff = defaultSelfHealCooloff
+	}
+	return settings
+}
+
+// selfHeal restarts the agent when self-healing is enabled and enough health
+// checks in a row failed, within the configured attempts and cooloff. Every
+// restart and giving up is recorded in the audit log and posted to the
+// health check webhook.
+func selfHeal(cmd *cobra.Command, platformInfo *platform.PlatformInfo, result state.HealthCheck) {
+	settings := loadSelfHealSettings()
+	if !settings.Enabled {
+		return
+	}
+	ctx := cmd.Context()
+	store := stateStore(platformInfo)
+	saved, err := store.Load()
+	if err != nil {
+		logger.Warning("Self-healing skipped: %v", err)
+		return
+	}
+
+	failing := saved.FailingChecks()
+	if failing < settings.After {
+		logger.Info("Self-healing: %d of %d failed checks before restarting the agent", failing, settings.After)
+		return
+	}
+
+	if saved.SelfHeal.Attempts >= settings.MaxAttempts {
+		if saved.SelfHeal.GaveUp {
+			return
+		}
+		logger.Warning("Self-healing gave up after %d restart(s); the agent needs attention", saved.SelfHeal.Attempts)
+		appendAuditEntry("fixpanic agent healthcheck run", []string{"self-heal:give-up"}, time.Now(),
+			fmt.Errorf("gave up after %d restart attempt(s)", saved.SelfHeal.Attempts))
+		notifyHealthWebhook(ctx, healthEvent{Event: "selfheal.gave_up", Result: result, Attempt: saved.SelfHeal.Attempts})
+		updateSelfHeal(ctx, platformInfo, func(heal *state.SelfHeal) { heal.GaveUp = true })
+		return
+	}
+
+	if wait := settings.Cooloff - time.Since(saved.SelfHeal.LastAttempt); wait > 0 {
+		logger.Info("Self-healing: next restart possible in %s", wait.Round(time.Second))
+		return
+	}
+	if isReadOnly() {
+		logger.Warning("Self-healing skipped: the CLI is in read-only mode")
+		return
+	}
+
+	attempt := saved.SelfHeal.Attempts + 1
+	logger.Info("Self-healing: restarting the agent (attempt %d of %d)", attempt, settings.MaxAttempts)
+	started := time.Now()
+	updateSelfHeal(ctx, platformInfo, func(heal *state.SelfHeal) {
+		heal.Attempts = attempt
+		heal.LastAttempt = started.UTC()
+	})
+	restartErr := withLock(cmd, func() error { return runAgentRestart(cmd, nil) })
+	appendAuditEntry("fixpanic agent healthcheck run",
+		[]string{"self-heal:restart", fmt.Sprintf("attempt=%d/%d", attempt, settings.MaxAttempts)}, started, restartErr)
+
+	event := healthEvent{Event: "selfheal.restarted", Result: result, Attempt: attempt}
+	if restartErr != nil {
+		logger.Warning("Self-healing restart failed: %v", restartErr)
+		event.Event = "selfheal.restart_failed"
+		event.Error = restartErr.Error()
+	}
+	notifyHealthWebhook(ctx, event)
+}
+
+// resetSelfHeal clears the restart attempts once the agent is healthy again
+func resetSelfHeal(ctx context.Context, platformInfo *platform.PlatformInfo) {
+	saved, err := stateStore(platformInfo).Load()
+	if err != nil || saved.SelfHeal.Attempts == 0 {
+		return
+	}
+	updateSelfHeal(ctx, platformInfo, func(heal *state.SelfHeal) { *heal = state.SelfHeal{} })
+}
+
+// updateSelfHeal applies fn to the self-healing state
+func updateSelfHeal(ctx context.Context, platformInfo *platform.PlatformInfo, fn func(*state.SelfHeal)) {
+	err := stateStore(platformInfo).Update(ctx, func(s *state.State) error {
+		fn(&s.SelfHeal)
+		return nil
+	})
+	if err != nil {
+		logger.Warning("Failed to record the self-healing state: %v", err)
+	}
+}
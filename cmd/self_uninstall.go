@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/cleanup"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/fleet"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/service"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	selfUninstallForce     bool
+	selfUninstallDryRun    bool
+	selfUninstallKeepAgent bool
+)
+
+// selfUninstallCmd represents the self uninstall command
+var selfUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the fixpanic CLI and everything it created",
+	Long: `Remove the fixpanic CLI binary together with the files it created outside
+the agent installation:
+
+  - the CLI binary and the backup left by 'fixpanic upgrade'
+  - the CLI config file (~/.fixpanic.yaml), the CLI preferences with the
+    telemetry state (cli.yaml) and the cache directory
+  - the audit log and lock file
+  - shell completion scripts
+  - leftover temporary files from interrupted runs
+
+Uninstall the agent first with 'fixpanic agent uninstall'; without the CLI it
+can no longer be managed.`,
+	Example: `  # Show what would be removed
+  fixpanic self uninstall --dry-run
+
+  # Remove everything without confirmation
+  sudo fixpanic self uninstall --force`,
+	Annotations: map[string]string{annotationMutating: "true"},
+	RunE:        runSelfUninstall,
+}
+
+func init() {
+	selfCmd.AddCommand(selfUninstallCmd)
+
+	// Add flags
+	selfUninstallCmd.Flags().BoolVar(&selfUninstallForce, "force", false, "Uninstall without confirmation")
+	selfUninstallCmd.Flags().BoolVar(&selfUninstallDryRun, "dry-run", false, "List what would be removed without removing anything")
+	selfUninstallCmd.Flags().BoolVar(&selfUninstallKeepAgent, "keep-agent", false, "Uninstall the CLI even though the agent is still installed")
+}
+
+// selfArtifact is a file or directory created by the CLI
+type selfArtifact struct {
+	Kind string
+	Path string
+}
+
+// completionPaths lists where shell completion scripts for the CLI are
+// commonly installed, system-wide and per user
+func completionPaths() []string {
+	paths := []string{
+		"/etc/bash_completion.d/fixpanic",
+		"/usr/share/bash-completion/completions/fixpanic",
+		"/usr/local/share/bash-completion/completions/fixpanic",
+		"/usr/share/zsh/site-functions/_fixpanic",
+		"/usr/local/share/zsh/site-functions/_fixpanic",
+		"/usr/share/fish/vendor_completions.d/fixpanic.fish",
+		"/usr/local/share/fish/vendor_completions.d/fixpanic.fish",
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths,
+			filepath.Join(home, ".local", "share", "bash-completion", "completions", "fixpanic"),
+			filepath.Join(home, ".zsh", "completions", "_fixpanic"),
+			filepath.Join(home, ".config", "fish", "completions", "fixpanic.fish"),
+		)
+	}
+	return paths
+}
+
+// collectSelfArtifacts returns the existing files and directories created by
+// the CLI, with the running binary last
+func collectSelfArtifacts(platformInfo *platform.PlatformInfo, binaryPath string) []selfArtifact {
+	var candidates []selfArtifact
+
+	configFile := viper.ConfigFileUsed()
+	if configFile == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			configFile = filepath.Join(home, ".fixpanic.yaml")
+		}
+	}
+	candidates = append(candidates, selfArtifact{Kind: "CLI config", Path: configFile})
+
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		candidates = append(candidates, selfArtifact{Kind: "Cache", Path: filepath.Join(cacheDir, "fixpanic")})
+	}
+	if store, err := cliConfigStore(); err == nil {
+		candidates = append(candidates,
+			selfArtifact{Kind: "CLI preferences", Path: store.Path},
+			selfArtifact{Kind: "Lock file", Path: store.Path + ".lock"},
+		)
+	}
+	if path, err := fleet.DefaultLabelsPath(); err == nil {
+		candidates = append(candidates, selfArtifact{Kind: "Fleet labels", Path: path})
+	}
+
+	candidates = append(candidates,
+		selfArtifact{Kind: "Audit log", Path: getAuditLogPath(platformInfo)},
+		selfArtifact{Kind: "Lock file", Path: platformInfo.GetLockPath()},
+		selfArtifact{Kind: "State", Path: stateStore(platformInfo).Path},
+		selfArtifact{Kind: "Lock file", Path: stateStore(platformInfo).Path + ".lock"},
+	)
+
+	candidates = append(candidates, selfArtifact{Kind: "Health check", Path: service.HealthCheckCronPath})
+	for _, path := range service.HealthCheckUnitPaths() {
+		candidates = append(candidates, selfArtifact{Kind: "Health check", Path: path})
+	}
+	candidates = append(candidates, selfArtifact{Kind: "Textfile refresh", Path: service.TextfileCronPath})
+	for _, path := range service.TextfileUnitPaths() {
+		candidates = append(candidates, selfArtifact{Kind: "Textfile refresh", Path: path})
+	}
+
+	for _, path := range completionPaths() {
+		candidates = append(candidates, selfArtifact{Kind: "Completion", Path: path})
+	}
+
+	if leftovers, err := cleanup.Find(tempFilePatterns(platformInfo), 0, time.Now()); err == nil {
+		for _, leftover := range leftovers {
+			candidates = append(candidates, selfArtifact{Kind: "Temporary file", Path: leftover.Path})
+		}
+	}
+
+	candidates = append(candidates,
+		selfArtifact{Kind: "Upgrade backup", Path: binaryPath + ".backup"},
+		selfArtifact{Kind: "CLI binary", Path: binaryPath},
+	)
+
+	var existing []selfArtifact
+	for _, candidate := range candidates {
+		if candidate.Path == "" {
+			continue
+		}
+		if _, err := os.Lstat(candidate.Path); err == nil {
+			existing = append(existing, candidate)
+		}
+	}
+	return existing
+}
+
+func runSelfUninstall(cmd *cobra.Command, args []string) error {
+	logger.Header("Uninstalling FixPanic CLI")
+
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	if connectivity.NewManager(platformInfo).IsFixPanicAgentInstalled() && !selfUninstallKeepAgent {
+		return clierror.New(clierror.AlreadyInstalled, "the agent is still installed and can't be managed without the CLI").
+			WithHint("Run 'fixpanic agent uninstall' first, or pass --keep-agent to leave it in place")
+	}
+
+	binaryPath, err := getCurrentBinaryPath()
+	if err != nil {
+		return fmt.Errorf("failed to get current binary path: %w", err)
+	}
+
+	artifacts := collectSelfArtifacts(platformInfo, binaryPath)
+	for _, artifact := range artifacts {
+		logger.KeyValue(artifact.Kind, artifact.Path)
+	}
+
+	if selfUninstallDryRun {
+		logger.Separator()
+		logger.Info("Dry run: %d item(s) would be removed. Run without --dry-run to remove them.", len(artifacts))
+		return nil
+	}
+
+	if !selfUninstallForce {
+		fmt.Fprint(os.Stderr, "\nAre you sure you want to continue? [y/N]: ")
+		response, err := readLine(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if response != "y" && response != "Y" {
+			fmt.Fprintln(os.Stderr, "Uninstallation cancelled.")
+			return nil
+		}
+	}
+
+	// The audit log is among the removed files; don't recreate it afterwards
+	auditDisabled = true
+
+	logger.Separator()
+	var failed int
+	for _, artifact := range artifacts {
+		if err := os.RemoveAll(artifact.Path); err != nil {
+			logger.Warning("Failed to remove %s: %v", artifact.Path, err)
+			failed++
+			continue
+		}
+		logger.List("Removed %s", artifact.Path)
+	}
+
+	// Directories left empty once the agent is gone
+	for _, dir := range []string{platformInfo.ConfigDir, platformInfo.LogDir, platformInfo.StateDir, platformInfo.LibDir} {
+		if err := os.Remove(dir); err == nil {
+			logger.List("Removed empty directory: %s", dir)
+		}
+	}
+
+	logger.Separator()
+	if failed > 0 {
+		return fmt.Errorf("failed to remove %d item(s)", failed)
+	}
+	logger.Success("Removed %d item(s). The FixPanic CLI has been uninstalled.", len(artifacts))
+	return nil
+}
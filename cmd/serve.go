@@ -0,0 +1,489 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/hooks"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/redact"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+var (
+	serveListenAddr     string
+	serveGRPCListenAddr string
+	serveTokenFile      string
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a local REST API for managing the agent",
+	Long: `Start an HTTP server exposing a small REST API, so dashboards and the
+FixPanic web console's remote actions can manage the agent on this host
+without running the CLI themselves.
+
+Endpoints:
+  GET  /v1/status            - installation and process state as JSON
+  POST /v1/start             - start the agent
+  POST /v1/stop              - stop the agent
+  POST /v1/upgrade           - upgrade the agent to the latest version
+  GET  /v1/logs?lines=50     - the last agent log lines as server-sent events,
+                               redacted like 'agent logs --export'; add
+                               follow=true to keep streaming new lines
+  GET  /healthz              - "ok", or 503 when the agent is unhealthy
+  GET  /status.json          - the health check as JSON (503 when unhealthy)
+
+Every /v1/ request must carry the token in an "Authorization: Bearer <token>"
+header; /healthz and /status.json need none, so load balancers can probe
+them. The token is read from --token-file, which is created with a random
+token if it doesn't exist (by default /etc/fixpanic/serve-token, readable by
+its owner only).
+
+Actions take the same lock and run the same hooks as the corresponding
+commands, are recorded in the audit log, and are refused in read-only mode.
+The server listens on localhost by default; it has no TLS, so put a reverse
+proxy in front of it before exposing it to the network.
+
+With --grpc-listen the same status, actions and logs are also served as the
+versioned gRPC service fixpanic.control.v1.Control, with the token in the
+"authorization" metadata. Go programs use the generated client in
+github.com/fixpanic/fixpanic-cli/pkg/control/v1.`,
+	Example: `  # Serve on the default address
+  sudo fixpanic serve
+
+  # Query the status
+  curl -H "Authorization: Bearer $(sudo cat /etc/fixpanic/serve-token)" http://127.0.0.1:7878/v1/status
+
+  # Follow the agent logs
+  curl -N -H "Authorization: Bearer $TOKEN" "http://127.0.0.1:7878/v1/logs?follow=true"
+
+  # Also serve the gRPC control interface
+  sudo fixpanic serve --grpc-listen 127.0.0.1:7880`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	// Add flags
+	serveCmd.Flags().StringVar(&serveListenAddr, "listen", "127.0.0.1:7878", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveGRPCListenAddr, "grpc-listen", "", "Also serve the gRPC control interface on this address (e.g. 127.0.0.1:7880)")
+	serveCmd.Flags().StringVar(&serveTokenFile, "token-file", "", "File holding the API token (default <config dir>/serve-token)")
+}
+
+// serveStatus is the response of GET /v1/status
+type serveStatus struct {
+	Installed  bool   `json:"installed"`
+	Running    bool   `json:"running"`
+	PID        int    `json:"pid,omitempty"`
+	Version    string `json:"version,omitempty"`
+	AgentID    string `json:"agent_id,omitempty"`
+	LogLevel   string `json:"log_level,omitempty"`
+	CLIVersion string `json:"cli_version"`
+}
+
+// serveActionResult is the response of the action endpoints
+type serveActionResult struct {
+	Operation string   `json:"operation"`
+	OK        bool     `json:"ok"`
+	Error     string   `json:"error,omitempty"`
+	ExitCode  int      `json:"exit_code"`
+	Hints     []string `json:"hints,omitempty"`
+}
+
+// apiServer serves the REST API of 'fixpanic serve'
+type apiServer struct {
+	cmd          *cobra.Command
+	platformInfo *platform.PlatformInfo
+	token        string
+
+	// actions serializes the actions, which share the CLI's global state
+	actions sync.Mutex
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return err
+	}
+
+	tokenPath := serveTokenFile
+	if tokenPath == "" {
+		tokenPath = platformInfo.GetServeTokenPath()
+	}
+	token, err := loadServeToken(tokenPath)
+	if err != nil {
+		return clierror.New(clierror.Permission, "failed to set up the API token: %w", err).
+			WithHint("Run with sudo, or pass --token-file with a file you can write")
+	}
+
+	api := &apiServer{cmd: cmd, platformInfo: platformInfo, token: token}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/status", api.authorized(http.MethodGet, api.handleStatus))
+	mux.HandleFunc("/v1/start", api.authorized(http.MethodPost, api.action(hooks.OperationStart, func(ctx context.Context) error {
+		return startAgent(ctx)
+	})))
+	mux.HandleFunc("/v1/stop", api.authorized(http.MethodPost, api.action(hooks.OperationStop, func(ctx context.Context) error {
+		return stopAgent(ctx)
+	})))
+	mux.HandleFunc("/v1/upgrade", api.authorized(http.MethodPost, api.action(hooks.OperationUpgrade, func(ctx context.Context) error {
+		return runAgentUpgrade(cmd, nil)
+	})))
+	mux.HandleFunc("/v1/logs", api.authorized(http.MethodGet, api.handleLogs))
+	(&healthEndpoints{platformInfo: platformInfo}).register(mux)
+
+	listener, err := net.Listen("tcp", serveListenAddr)
+	if err != nil {
+		return clierror.New(clierror.General, "failed to listen on %s: %w", serveListenAddr, err)
+	}
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	var grpcServer *grpc.Server
+	if serveGRPCListenAddr != "" {
+		grpcListener, err := net.Listen("tcp", serveGRPCListenAddr)
+		if err != nil {
+			listener.Close()
+			return clierror.New(clierror.General, "failed to listen on %s: %w", serveGRPCListenAddr, err)
+		}
+		grpcServer = newGRPCServer(api)
+		go func() {
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				logger.Warning("The gRPC control interface failed: %v", err)
+			}
+		}()
+		warnIfExposed(serveGRPCListenAddr)
+		logger.Info("Serving the FixPanic gRPC control interface on %s", grpcListener.Addr())
+	}
+
+	// Stop serving on Ctrl+C or --timeout; streaming log requests end with it
+	go func() {
+		<-cmd.Context().Done()
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	warnIfExposed(serveListenAddr)
+	logger.Info("Serving the FixPanic API on http://%s/v1/", listener.Addr())
+	logger.KeyValue("Token file", tokenPath)
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("API server failed: %w", err)
+	}
+
+	return nil
+}
+
+// warnIfExposed warns that the API served on addr, which has no TLS, can be
+// reached from other hosts unless addr is a loopback address
+func warnIfExposed(addr string) {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			logger.Warning("Listening on %s, which is reachable from other hosts; the API has no TLS", addr)
+		}
+	}
+}
+
+// loadServeToken reads the API token from path, creating the file with a
+// random token first if it doesn't exist
+func loadServeToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return "", fmt.Errorf("%s is empty", path)
+		}
+		return token, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(random)
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", err
+	}
+	logger.Info("Generated a new API token in %s", path)
+	return token, nil
+}
+
+// authorized wraps handler so it only serves requests with the right method
+// and a valid bearer token
+func (s *apiServer) authorized(method string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.validAuthorization(r.Header.Get("Authorization")) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="fixpanic"`)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing or invalid token"})
+			return
+		}
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// validAuthorization reports whether header, an Authorization header or
+// metadata value, carries the API token as a bearer token
+func (s *apiServer) validAuthorization(header string) bool {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(s.token)) == 1
+}
+
+func (s *apiServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.status(r.Context()))
+}
+
+// status returns the installation and process state of the agent
+func (s *apiServer) status(ctx context.Context) serveStatus {
+	status := serveStatus{CLIVersion: getCurrentVersion()}
+
+	connectivityManager := connectivity.NewManager(s.platformInfo)
+	status.Installed = connectivityManager.IsFixPanicAgentInstalled()
+	if status.Installed {
+		if output, err := connectivityManager.GetFixPanicAgentVersion(ctx); err == nil {
+			status.Version = connectivity.ParseAgentVersion(output)
+		}
+	}
+	status.Running, status.PID = detectAgentRunning(ctx, s.platformInfo)
+	if agentConfig, err := config.LoadConfig(s.platformInfo.GetConfigPath()); err == nil {
+		status.AgentID = agentConfig.App.AgentID
+		status.LogLevel = agentConfig.Logging.Level
+	}
+	return status
+}
+
+// action returns a handler running fn as operation with runAction
+func (s *apiServer) action(operation string, fn func(ctx context.Context) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result := serveActionResult{Operation: operation, OK: true}
+		err := s.runAction(operation, r.RemoteAddr, fn)
+		if err == nil {
+			writeJSON(w, http.StatusOK, result)
+			return
+		}
+		result.OK = false
+		result.Error = err.Error()
+		result.ExitCode = clierror.ExitCode(err)
+		result.Hints = clierror.Hints(err)
+		writeJSON(w, actionHTTPStatus(clierror.CodeOf(err)), result)
+	}
+}
+
+// runAction runs fn as operation requested by remote, under the lock and
+// with hooks like the corresponding command, and records it in the audit
+// log. Actions run one at a time and are refused in read-only mode.
+func (s *apiServer) runAction(operation, remote string, fn func(ctx context.Context) error) error {
+	if isReadOnly() {
+		return clierror.New(clierror.ReadOnly, "the API is in read-only mode (cli.read_only / FIXPANIC_READ_ONLY)")
+	}
+
+	s.actions.Lock()
+	defer s.actions.Unlock()
+
+	logger.Info("API: %s requested by %s", operation, remote)
+	started := time.Now()
+	// Bound to the server rather than the request, so a client that goes
+	// away doesn't interrupt an upgrade half way
+	ctx := s.cmd.Context()
+	err := withLock(s.cmd, func() error {
+		return runWithHooks(ctx, operation, func() error { return fn(ctx) })
+	})
+	appendAuditEntry("fixpanic serve", []string{"api:" + operation, "remote=" + remote}, started, err)
+	return err
+}
+
+// actionHTTPStatus maps the exit code of a failed action to an HTTP status
+func actionHTTPStatus(code clierror.Code) int {
+	switch code {
+	case clierror.NotInstalled:
+		return http.StatusNotFound
+	case clierror.Busy:
+		return http.StatusConflict
+	case clierror.ReadOnly, clierror.Permission:
+		return http.StatusForbidden
+	case clierror.Timeout:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// handleLogs streams the last agent log lines as server-sent events, and with
+// follow=true keeps streaming new lines until the client disconnects
+func (s *apiServer) handleLogs(w http.ResponseWriter, r *http.Request) {
+	lines := 50
+	if value := r.URL.Query().Get("lines"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "lines must be a non-negative number"})
+			return
+		}
+		lines = n
+	}
+	follow, _ := strconv.ParseBool(r.URL.Query().Get("follow"))
+
+	// The logs leave the host, so secrets are scrubbed as for an export
+	redactor, err := newRedactor(s.platformInfo, false)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "streaming is not supported"})
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	err = s.streamLogs(r.Context(), redactor, lines, follow, func(line string) {
+		fmt.Fprintf(w, "data: %s\n\n", line)
+		flusher.Flush()
+	})
+	if err != nil && r.Context().Err() == nil {
+		fmt.Fprintf(w, "event: error\ndata: %s\n\n", redactor.String(err.Error()))
+		flusher.Flush()
+	}
+}
+
+// streamLogs passes the last lines of the agent logs, redacted, to send, and
+// with follow new lines until ctx is done
+func (s *apiServer) streamLogs(ctx context.Context, redactor *redact.Redactor, lines int, follow bool, send func(string)) error {
+	redacted := func(line string) {
+		send(redactor.String(strings.TrimRight(line, "\r")))
+	}
+	if platform.IsSystemdAvailable() {
+		return streamJournal(ctx, lines, follow, redacted)
+	}
+	return streamLogFile(ctx, s.platformInfo.GetLogPath(), lines, follow, redacted)
+}
+
+// streamJournal passes the agent's journal lines to send
+func streamJournal(ctx context.Context, lines int, follow bool, send func(string)) error {
+	args := []string{"-u", platform.GetSystemdServiceName(), "-n", strconv.Itoa(lines), "-o", "cat", "--no-pager"}
+	if follow {
+		args = append(args, "-f")
+	}
+	journal := exec.CommandContext(ctx, "journalctl", args...)
+	stdout, err := journal.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := journal.Start(); err != nil {
+		return fmt.Errorf("failed to read the journal: %w", err)
+	}
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		send(scanner.Text())
+	}
+	if err := journal.Wait(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read the journal: %w", err)
+	}
+	return nil
+}
+
+// streamLogFile passes the last lines of the log file at path to send, and
+// with follow polls it for appended lines
+func streamLogFile(ctx context.Context, path string, lines int, follow bool, send func(string)) error {
+	last, err := readLastLines(path, lines)
+	if err != nil && !(follow && errors.Is(err, os.ErrNotExist)) {
+		return err
+	}
+	for _, line := range last {
+		send(line)
+	}
+	if !follow {
+		return nil
+	}
+
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	var partial string
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.Size() < offset {
+			// Rotated or truncated: start over from the beginning
+			offset, partial = 0, ""
+		}
+		if info.Size() == offset {
+			continue
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(io.NewSectionReader(file, offset, info.Size()-offset))
+		file.Close()
+		if err != nil {
+			continue
+		}
+		offset += int64(len(data))
+
+		chunk := partial + string(data)
+		complete := strings.Split(chunk, "\n")
+		partial = complete[len(complete)-1]
+		for _, line := range complete[:len(complete)-1] {
+			send(line)
+		}
+	}
+}
+
+// writeJSON writes value as the JSON response body with status
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(value)
+}
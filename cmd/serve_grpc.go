@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/hooks"
+	controlv1 "github.com/fixpanic/fixpanic-cli/pkg/control/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// controlServer implements the gRPC control interface on top of the same
+// status, actions and log streaming as the REST API
+type controlServer struct {
+	controlv1.UnimplementedControlServer
+	api *apiServer
+}
+
+// newGRPCServer returns a gRPC server for the control interface of api,
+// requiring its token on every call
+func newGRPCServer(api *apiServer) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := api.authorizeCall(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := api.authorizeCall(stream.Context()); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	)
+	controlv1.RegisterControlServer(server, &controlServer{api: api})
+	return server
+}
+
+// authorizeCall fails with Unauthenticated unless the call carries the API
+// token in its authorization metadata
+func (s *apiServer) authorizeCall(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		if s.validAuthorization(value) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid token")
+}
+
+func (c *controlServer) GetStatus(ctx context.Context, req *controlv1.GetStatusRequest) (*controlv1.GetStatusResponse, error) {
+	agentStatus := c.api.status(ctx)
+	return &controlv1.GetStatusResponse{
+		Installed:  agentStatus.Installed,
+		Running:    agentStatus.Running,
+		Pid:        int32(agentStatus.PID),
+		Version:    agentStatus.Version,
+		AgentId:    agentStatus.AgentID,
+		LogLevel:   agentStatus.LogLevel,
+		CliVersion: agentStatus.CLIVersion,
+	}, nil
+}
+
+func (c *controlServer) Start(ctx context.Context, req *controlv1.StartRequest) (*controlv1.StartResponse, error) {
+	if err := c.api.runAction(hooks.OperationStart, remoteAddr(ctx), startAgent); err != nil {
+		return nil, actionStatus(err)
+	}
+	return &controlv1.StartResponse{}, nil
+}
+
+func (c *controlServer) Stop(ctx context.Context, req *controlv1.StopRequest) (*controlv1.StopResponse, error) {
+	if err := c.api.runAction(hooks.OperationStop, remoteAddr(ctx), stopAgent); err != nil {
+		return nil, actionStatus(err)
+	}
+	return &controlv1.StopResponse{}, nil
+}
+
+func (c *controlServer) Upgrade(ctx context.Context, req *controlv1.UpgradeRequest) (*controlv1.UpgradeResponse, error) {
+	err := c.api.runAction(hooks.OperationUpgrade, remoteAddr(ctx), func(ctx context.Context) error {
+		return runAgentUpgrade(c.api.cmd, nil)
+	})
+	if err != nil {
+		return nil, actionStatus(err)
+	}
+	return &controlv1.UpgradeResponse{}, nil
+}
+
+func (c *controlServer) StreamLogs(req *controlv1.StreamLogsRequest, stream controlv1.Control_StreamLogsServer) error {
+	lines := 50
+	if req.Lines != nil {
+		if req.GetLines() < 0 {
+			return status.Error(codes.InvalidArgument, "lines must be a non-negative number")
+		}
+		lines = int(req.GetLines())
+	}
+
+	redactor, err := newRedactor(c.api.platformInfo, false)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	var sendErr error
+	err = c.api.streamLogs(ctx, redactor, lines, req.GetFollow(), func(line string) {
+		if sendErr != nil {
+			return
+		}
+		if sendErr = stream.Send(&controlv1.StreamLogsResponse{Line: line}); sendErr != nil {
+			cancel()
+		}
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil && stream.Context().Err() == nil {
+		return status.Error(codes.Internal, redactor.String(err.Error()))
+	}
+	return nil
+}
+
+// actionStatus returns the gRPC status of a failed action, with the exit
+// code and hints of err attached as an ActionError
+func actionStatus(err error) error {
+	actionErr := status.New(actionGRPCCode(clierror.CodeOf(err)), err.Error())
+	detailed, detailsErr := actionErr.WithDetails(&controlv1.ActionError{
+		ExitCode: int32(clierror.ExitCode(err)),
+		Hints:    clierror.Hints(err),
+	})
+	if detailsErr != nil {
+		return actionErr.Err()
+	}
+	return detailed.Err()
+}
+
+// actionGRPCCode maps the exit code of a failed action to a gRPC status code,
+// like actionHTTPStatus does for the REST API
+func actionGRPCCode(code clierror.Code) codes.Code {
+	switch code {
+	case clierror.NotInstalled:
+		return codes.FailedPrecondition
+	case clierror.Busy:
+		return codes.Aborted
+	case clierror.ReadOnly, clierror.Permission:
+		return codes.PermissionDenied
+	case clierror.Timeout:
+		return codes.DeadlineExceeded
+	}
+	return codes.Internal
+}
+
+// remoteAddr returns the address of the client of a call
+func remoteAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr.String()
+	}
+	return "unknown"
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	controlv1 "github.com/fixpanic/fixpanic-cli/pkg/control/v1"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestControlServer(t *testing.T) {
+	platformInfo, err := platform.GetPlatformInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := &apiServer{cmd: &cobra.Command{}, platformInfo: platformInfo, token: "s3cret-token"}
+	listener := bufconn.Listen(1 << 20)
+	server := newGRPCServer(api)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	dial := func(opts ...grpc.DialOption) controlv1.ControlClient {
+		opts = append(opts,
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+		conn, err := grpc.NewClient("passthrough:///bufconn", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return controlv1.NewControlClient(conn)
+	}
+	ctx := context.Background()
+
+	for _, token := range []string{"", "wrong"} {
+		client := dial(grpc.WithPerRPCCredentials(controlv1.TokenCredentials(token)))
+		if _, err := client.GetStatus(ctx, &controlv1.GetStatusRequest{}); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("GetStatus with token %q: code = %v, want %v", token, status.Code(err), codes.Unauthenticated)
+		}
+	}
+
+	client := dial(grpc.WithPerRPCCredentials(controlv1.TokenCredentials(api.token)))
+	agentStatus, err := client.GetStatus(ctx, &controlv1.GetStatusRequest{})
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if agentStatus.CliVersion != getCurrentVersion() {
+		t.Errorf("cli_version = %q, want %q", agentStatus.CliVersion, getCurrentVersion())
+	}
+
+	t.Setenv(agentops.EnvReadOnly, "1")
+	_, err = client.Stop(ctx, &controlv1.StopRequest{})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Stop in read-only mode: code = %v, want %v", status.Code(err), codes.PermissionDenied)
+	}
+	if actionErr := controlv1.ActionErrorOf(err); actionErr == nil || actionErr.ExitCode != int32(clierror.ReadOnly) {
+		t.Errorf("ActionErrorOf() = %v, want exit code %d", actionErr, clierror.ReadOnly)
+	}
+
+	lines := int32(-1)
+	stream, err := client.StreamLogs(ctx, &controlv1.StreamLogsRequest{Lines: &lines})
+	if err != nil {
+		t.Fatalf("StreamLogs() error = %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("StreamLogs with negative lines: code = %v, want %v", status.Code(err), codes.InvalidArgument)
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// shortcutTargets maps the top-level shortcuts, e.g. 'fixpanic status', to
+// the agent commands they run
+var shortcutTargets = map[*cobra.Command]*cobra.Command{}
+
+func init() {
+	for _, target := range []*cobra.Command{agentStatusCmd, agentLogsCmd, agentStartCmd, agentStopCmd, agentRestartCmd} {
+		rootCmd.AddCommand(newShortcut(target))
+	}
+}
+
+// newShortcut returns a top-level command running the agent command target
+// with the same flags, so the most common operations don't need the agent
+// prefix. Scripts should keep using the canonical 'fixpanic agent' form.
+func newShortcut(target *cobra.Command) *cobra.Command {
+	canonical := "fixpanic agent " + target.Name()
+	shortcut := &cobra.Command{
+		Use:         target.Use,
+		Short:       target.Short + " (shortcut for '" + canonical + "')",
+		Long:        "Shortcut for '" + canonical + "'; scripts should use the canonical form.\n\n" + target.Long,
+		Example:     target.Example,
+		Args:        target.Args,
+		Annotations: target.Annotations,
+		RunE:        target.RunE,
+	}
+
+	// The flags are shared with target, including those 'fixpanic agent'
+	// gives all its subcommands
+	shortcut.Flags().AddFlagSet(target.NonInheritedFlags())
+	shortcut.Flags().AddFlagSet(agentCmd.PersistentFlags())
+
+	shortcutTargets[shortcut] = target
+	return shortcut
+}
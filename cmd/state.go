@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/state"
+)
+
+// stateStore returns the store of what the CLI remembers between runs
+func stateStore(platformInfo *platform.PlatformInfo) *state.Store {
+	return state.NewStore(platformInfo.StateDir)
+}
+
+// recordInstall adds an install record to the state. Failing to record it
+// doesn't fail the installation.
+func recordInstall(ctx context.Context, platformInfo *platform.PlatformInfo, profile string) {
+	if err := agentops.RecordInstall(ctx, platformInfo, getCurrentVersion(), profile); err != nil {
+		logger.Warning("Failed to record the installation: %v", err)
+	}
+}
+
+// recordVersionChange adds a change of component from one version to another
+// to the history; changeErr is the error that made it fail, if any. Failing to
+// record it doesn't fail the change.
+func recordVersionChange(ctx context.Context, component, from, to string, changeErr error) {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return
+	}
+	if err := agentops.RecordVersionChange(ctx, platformInfo, component, from, to, changeErr); err != nil {
+		logger.Warning("Failed to record the version change in the history: %v", err)
+	}
+}
+
+// lastAgentChange returns when the agent binary was last installed or
+// upgraded according to the state, or the zero time if unknown
+func lastAgentChange(platformInfo *platform.PlatformInfo) time.Time {
+	saved, err := stateStore(platformInfo).Load()
+	if err != nil {
+		return time.Time{}
+	}
+	if change := saved.LastVersionChange(state.ComponentAgent); change != nil {
+		return change.Time
+	}
+	if install := saved.LastInstall(); install != nil {
+		return install.Time
+	}
+	return time.Time{}
+}
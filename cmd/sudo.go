@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/sudo"
+	"github.com/spf13/cobra"
+)
+
+// noSudo disables offering to run commands that need root again under sudo
+var noSudo bool
+
+// offerSudo warns why cmd needs root and asks the user to run the command
+// line again with sudo, doing so on confirmation: the process is replaced and
+// offerSudo only returns if sudo couldn't be run. It returns nil right away
+// with --no-sudo, when stdin isn't a terminal to confirm on or when sudo
+// isn't installed, so the caller fails before changing anything.
+func offerSudo(cmd *cobra.Command, format string, args ...interface{}) error {
+	if noSudo || !isInteractive() || !sudo.Available() {
+		return nil
+	}
+
+	logger.Warning(format, args...)
+	fmt.Fprintf(os.Stderr, "%s ", i18n.T("Run it again with sudo? [y/N]:"))
+	response, err := readLine(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if response != "y" && response != "Y" {
+		return nil
+	}
+	return sudo.Reexec(os.Args[1:])
+}
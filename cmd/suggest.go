@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// maxSuggestDistance is how many edits a mistyped command or flag may be
+// away from one to be suggested
+const maxSuggestDistance = 2
+
+// renamedCommands maps commands of earlier CLI versions, without the
+// leading "fixpanic", to their current equivalent
+var renamedCommands = map[string]string{
+	// The agent was called the connectivity layer
+	"connectivity":     "agent",
+	"agent connection": "agent test-connection",
+}
+
+// enableSuggestions makes cmd and the command groups below it fail with
+// suggestions on unknown subcommands instead of printing their help, which
+// they still print when run without one
+func enableSuggestions(cmd *cobra.Command) {
+	for _, sub := range cmd.Commands() {
+		enableSuggestions(sub)
+	}
+	if !cmd.HasSubCommands() {
+		return
+	}
+	cmd.SuggestionsMinimumDistance = maxSuggestDistance
+	if cmd.Runnable() {
+		return
+	}
+	cmd.Args = subcommandArgs
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	}
+}
+
+// subcommandArgs rejects arguments to a command group, which can only be
+// unknown subcommands
+func subcommandArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	return unknownCommand(cmd, args)
+}
+
+// unknownCommand reports that args[0] is no subcommand of cmd, with the
+// current name of commands of earlier CLI versions or the closest
+// subcommands
+func unknownCommand(cmd *cobra.Command, args []string) error {
+	typed := strings.TrimPrefix(cmd.CommandPath()+" "+strings.Join(args, " "), cmd.Root().Name()+" ")
+	for old, current := range renamedCommands {
+		if typed != old && !strings.HasPrefix(typed, old+" ") {
+			continue
+		}
+		replacement := cmd.Root().Name() + " " + current + strings.TrimPrefix(typed, old)
+		return clierror.New(clierror.Usage, "'%s %s' was renamed to '%s %s'", cmd.Root().Name(), old, cmd.Root().Name(), current).
+			WithHint(i18n.Sprintf("Run '%s' instead", replacement))
+	}
+
+	err := clierror.New(clierror.Usage, "unknown command %q for %q", args[0], cmd.CommandPath())
+	for _, suggestion := range cmd.SuggestionsFor(args[0]) {
+		err.WithHint(i18n.Sprintf("Did you mean '%s %s'?", cmd.CommandPath(), suggestion))
+	}
+	return err.WithHint(i18n.Sprintf("Run '%s --help' to see its commands", cmd.CommandPath()))
+}
+
+// suggestFlags returns the flags of cmd closest to the mistyped flag name,
+// closest first
+func suggestFlags(cmd *cobra.Command, name string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden {
+			return
+		}
+		distance := levenshtein(strings.ToLower(name), flag.Name)
+		if distance <= maxSuggestDistance || strings.HasPrefix(flag.Name, name) {
+			candidates = append(candidates, candidate{flag.Name, distance})
+		}
+	})
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	suggestions := make([]string, len(candidates))
+	for i, c := range candidates {
+		suggestions[i] = c.name
+	}
+	return suggestions
+}
+
+// flagError classifies a flag parsing failure of cmd as a usage error,
+// suggesting the closest flags for unknown ones
+func flagError(cmd *cobra.Command, err error) error {
+	classified := clierror.New(clierror.Usage, "%w", err)
+	var name string
+	if _, scanErr := fmt.Sscanf(err.Error(), "unknown flag: --%s", &name); scanErr == nil {
+		for _, suggestion := range suggestFlags(cmd, name) {
+			classified.WithHint(i18n.Sprintf("Did you mean --%s?", suggestion))
+		}
+	}
+	return classified.WithHint(i18n.Sprintf("Run '%s --help' to see its usage", cmd.CommandPath()))
+}
+
+// classifyArgErrors makes the argument validation of cmd and the commands
+// below it fail with the usage exit code, like flag errors
+func classifyArgErrors(cmd *cobra.Command) {
+	for _, sub := range cmd.Commands() {
+		classifyArgErrors(sub)
+	}
+	if cmd.Args == nil {
+		return
+	}
+	validate := cmd.Args
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		err := validate(cmd, args)
+		var classified *clierror.Error
+		if err == nil || errors.As(err, &classified) {
+			return err
+		}
+		return clierror.New(clierror.Usage, "%w", err).
+			WithHint(i18n.Sprintf("Run '%s --help' to see its usage", cmd.CommandPath()))
+	}
+}
+
+// levenshtein returns the number of single-character edits between a and b
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
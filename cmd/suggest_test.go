@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/spf13/cobra"
+)
+
+func TestClassifyArgErrors(t *testing.T) {
+	root := &cobra.Command{Use: "fixpanic"}
+	set := &cobra.Command{Use: "set", Args: cobra.ExactArgs(2), RunE: func(*cobra.Command, []string) error { return nil }}
+	group := &cobra.Command{Use: "group", Args: subcommandArgs}
+	group.AddCommand(&cobra.Command{Use: "child", Run: func(*cobra.Command, []string) {}})
+	root.AddCommand(set, group)
+	classifyArgErrors(root)
+
+	tests := []struct {
+		name string
+		cmd  *cobra.Command
+		args []string
+		want clierror.Code
+	}{
+		{"valid", set, []string{"a", "b"}, clierror.OK},
+		{"too few", set, []string{"a"}, clierror.Usage},
+		{"too many", set, []string{"a", "b", "c"}, clierror.Usage},
+		{"unknown subcommand", group, []string{"chidl"}, clierror.Usage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clierror.CodeOf(tt.cmd.Args(tt.cmd, tt.args)); got != tt.want {
+				t.Errorf("exit code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"status", "status", 0},
+		{"stauts", "status", 2},
+		{"instal", "install", 1},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/summary"
+	"github.com/spf13/cobra"
+)
+
+var noSummary bool
+
+// summaryNoteWidth is how much of a note the summary table shows; the full
+// error of a failed command is reported below it anyway
+const summaryNoteWidth = 60
+
+// summaryCommands are the multi-step commands that end with a summary of
+// their steps
+var summaryCommands = map[*cobra.Command]bool{}
+
+// addSummaryFlag makes cmd end with a summary table of its steps, which
+// --no-summary turns off. The summary is part of the command_completed event
+// either way.
+func addSummaryFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&noSummary, "no-summary", false, "Don't print the summary of the steps at the end")
+	summaryCommands[cmd] = true
+}
+
+// printSummary prints the summary of steps for executed, which ended with
+// err, on standard error and returns the steps for the event stream
+func printSummary(executed *cobra.Command, err error) []summary.Step {
+	steps := summary.Finish(err)
+	if len(steps) == 0 || !summaryCommands[executed] || noSummary {
+		return steps
+	}
+
+	fmt.Fprintln(os.Stderr)
+	w := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STEP\tSTATUS\tDURATION\tARTIFACTS\tNOTE")
+	for _, step := range steps {
+		name, duration := "  "+step.Name, ""
+		if step.Number > 0 {
+			name = fmt.Sprintf("%d. %s", step.Number, step.Name)
+			duration = (time.Duration(step.DurationMS) * time.Millisecond).String()
+		}
+		note := step.Note
+		if runes := []rune(note); len(runes) > summaryNoteWidth {
+			note = string(runes[:summaryNoteWidth-1]) + "…"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, step.Status, duration, strings.Join(step.Artifacts, ", "), note)
+	}
+	w.Flush()
+	if err != nil {
+		fmt.Fprintln(os.Stderr)
+	}
+	return steps
+}
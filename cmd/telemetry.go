@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/cliconfig"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+// telemetryCmd represents the telemetry command
+var telemetryCmd = &cobra.Command{
+	Use:   "telemetry",
+	Short: "Manage anonymous usage telemetry",
+	Long: `Manage anonymous usage telemetry. Telemetry is off until you turn it on.
+
+When enabled, the CLI records for each command only: the command name (never
+its arguments), whether it succeeded (the exit code class), the duration, the
+OS and architecture, and the CLI and Go versions, tagged with a random install
+ID. Events are queued locally and sent in batches over HTTPS.
+
+Setting DO_NOT_TRACK=1 or FIXPANIC_TELEMETRY=0 disables telemetry regardless
+of this setting.`,
+}
+
+// telemetryOnCmd represents the telemetry on command
+var telemetryOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Opt in to anonymous usage telemetry",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setTelemetry(true)
+	},
+}
+
+// telemetryOffCmd represents the telemetry off command
+var telemetryOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Opt out of telemetry and delete queued events",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setTelemetry(false)
+	},
+}
+
+// telemetryStatusCmd represents the telemetry status command
+var telemetryStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether telemetry is enabled",
+	Args:  cobra.NoArgs,
+	RunE:  runTelemetryStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(telemetryCmd)
+	telemetryCmd.AddCommand(telemetryOnCmd)
+	telemetryCmd.AddCommand(telemetryOffCmd)
+	telemetryCmd.AddCommand(telemetryStatusCmd)
+}
+
+// telemetryStore returns the store keeping the telemetry decision in the CLI
+// configuration and the queue in the user's cache directory
+func telemetryStore() (*telemetry.Store, error) {
+	preferences, err := cliConfigStore()
+	if err != nil {
+		return nil, err
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	states := telemetryStates{store: preferences}
+	if err := states.importLegacy(); err != nil {
+		return nil, err
+	}
+	return telemetry.NewStore(states, filepath.Join(cacheDir, "fixpanic")), nil
+}
+
+// telemetryStates keeps the telemetry state in the CLI configuration
+type telemetryStates struct {
+	store *cliconfig.Store
+}
+
+// LoadState returns the telemetry decision of the CLI configuration
+func (t telemetryStates) LoadState() (*telemetry.State, error) {
+	preferences, err := t.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	return &telemetry.State{
+		Enabled:   preferences.TelemetryEnabled(),
+		InstallID: preferences.TelemetryInstallID,
+		UpdatedAt: preferences.TelemetryChanged,
+	}, nil
+}
+
+// SaveState records the telemetry decision in the CLI configuration
+func (t telemetryStates) SaveState(state *telemetry.State) error {
+	return t.store.Update(context.Background(), func(preferences *cliconfig.Config) error {
+		preferences.Telemetry = &state.Enabled
+		preferences.TelemetryInstallID = state.InstallID
+		preferences.TelemetryChanged = state.UpdatedAt
+		return nil
+	})
+}
+
+// importLegacy moves the telemetry.json of earlier versions into the CLI
+// configuration, unless the configuration has a decision of its own
+func (t telemetryStates) importLegacy() error {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+	path := filepath.Join(configDir, "fixpanic", telemetry.LegacyStateFile)
+	legacy, err := telemetry.ReadLegacyState(path)
+	if err != nil || legacy == nil {
+		return err
+	}
+	err = t.store.Update(context.Background(), func(preferences *cliconfig.Config) error {
+		if preferences.Telemetry == nil {
+			preferences.Telemetry = &legacy.Enabled
+			preferences.TelemetryInstallID = legacy.InstallID
+			preferences.TelemetryChanged = legacy.UpdatedAt
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func setTelemetry(enabled bool) error {
+	store, err := telemetryStore()
+	if err != nil {
+		return err
+	}
+	if _, err := store.SetEnabled(enabled); err != nil {
+		return err
+	}
+
+	if !enabled {
+		logger.Success("Telemetry disabled. Queued events were deleted.")
+		return nil
+	}
+
+	logger.Success("Telemetry enabled. Thank you for helping improve FixPanic!")
+	if telemetry.DisabledByEnvironment() {
+		logger.Warning("DO_NOT_TRACK or FIXPANIC_TELEMETRY is set, so nothing will be sent until it is unset")
+	}
+	logger.Info("Run 'fixpanic telemetry status' to see what is collected, or 'fixpanic telemetry off' to opt out.")
+	return nil
+}
+
+func runTelemetryStatus(cmd *cobra.Command, args []string) error {
+	store, err := telemetryStore()
+	if err != nil {
+		return err
+	}
+	state, err := store.LoadState()
+	if err != nil {
+		return err
+	}
+	pending, err := store.Pending()
+	if err != nil {
+		return err
+	}
+
+	status := "disabled"
+	switch {
+	case telemetry.DisabledByEnvironment():
+		status = "disabled by DO_NOT_TRACK / FIXPANIC_TELEMETRY"
+	case state.Enabled:
+		status = "enabled"
+	}
+
+	logger.KeyValue("Telemetry", status)
+	if state.InstallID != "" {
+		logger.KeyValue("Install ID", state.InstallID)
+	}
+	if !state.UpdatedAt.IsZero() {
+		logger.KeyValue("Changed", state.UpdatedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	logger.KeyValue("Queued events", fmt.Sprintf("%d", len(pending)))
+	logger.KeyValue("Endpoint", store.Endpoint)
+	if preferences, err := cliConfigStore(); err == nil {
+		logger.KeyValue("State file", preferences.Path)
+	}
+	return nil
+}
+
+// recordTelemetry queues an anonymous event for the executed command when the
+// user opted in, and sends the queue once a batch is due. Failures are silent:
+// telemetry must never get in the way of the command.
+func recordTelemetry(executed *cobra.Command, started time.Time, runErr error) {
+	if executed == nil || requires(executed, requireNoTelemetry) || telemetry.DisabledByEnvironment() {
+		return
+	}
+	store, err := telemetryStore()
+	if err != nil {
+		return
+	}
+	state, err := store.LoadState()
+	if err != nil || !state.Enabled {
+		return
+	}
+
+	event := telemetry.Event{
+		Time:       started.UTC(),
+		InstallID:  state.InstallID,
+		Command:    executed.CommandPath(),
+		Result:     clierror.CodeOf(runErr).String(),
+		DurationMS: time.Since(started).Milliseconds(),
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		CLIVersion: getCurrentVersion(),
+		GoVersion:  runtime.Version(),
+	}
+	if err := store.Enqueue(event); err != nil {
+		return
+	}
+	store.Flush(context.Background(), time.Now())
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"net/http"
+	"runtime"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/trace"
+	"github.com/spf13/cobra"
+)
+
+// defaultTraceFile is where --trace without a value writes the trace
+const defaultTraceFile = "fixpanic-trace.jsonl"
+
+var (
+	traceFile    string
+	otelEndpoint string
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&traceFile, "trace", "", "Record the timings of steps and HTTP requests to this file (default "+defaultTraceFile+")")
+	rootCmd.PersistentFlags().Lookup("trace").NoOptDefVal = defaultTraceFile
+	rootCmd.PersistentFlags().StringVar(&otelEndpoint, "otel-endpoint", "", "Export the timings to this OpenTelemetry OTLP/HTTP collector, e.g. http://localhost:4318")
+}
+
+// startTrace starts recording the command's timings requested with --trace
+// or --otel-endpoint
+func startTrace(cmd *cobra.Command) error {
+	if traceFile == "" && otelEndpoint == "" {
+		return nil
+	}
+	if traceFile != "" {
+		if err := trace.OpenFile(traceFile); err != nil {
+			return clierror.Wrap(clierror.Usage, err)
+		}
+	}
+	if otelEndpoint != "" {
+		trace.SetEndpoint(otelEndpoint)
+	}
+
+	// Clients without a transport of their own use the default one
+	http.DefaultTransport = trace.Transport(http.DefaultTransport)
+	trace.StartCommand(cmd.CommandPath(), map[string]string{
+		"cli.version": version,
+		"os":          runtime.GOOS,
+		"arch":        runtime.GOARCH,
+	})
+	return nil
+}
+
+// finishTrace writes out the trace of the command, which ended with err. A
+// trace that can't be written is reported without failing the command.
+func finishTrace(err error) {
+	if !trace.Enabled() {
+		return
+	}
+	if traceErr := trace.Finish(err); traceErr != nil {
+		logger.Warning("%v", traceErr)
+		return
+	}
+	if traceFile != "" {
+		logger.Info("Trace written to %s", traceFile)
+	}
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/releases"
+	"github.com/fixpanic/fixpanic-cli/internal/term"
+	"github.com/spf13/cobra"
+)
+
+// envNoUpdateNotifier disables update notices when set to a true value
+const envNoUpdateNotifier = "FIXPANIC_NO_UPDATE_NOTIFIER"
+
+const (
+	// updateCheckInterval is how often the latest release is looked up for
+	// update notices; in between the last answer is reused
+	updateCheckInterval = 24 * time.Hour
+	// updateCheckTimeout bounds the lookup, which the command waits for
+	updateCheckTimeout = 3 * time.Second
+)
+
+// updateNoticeSkipped are the agent commands that don't get an update
+// notice: those installing or listing releases themselves, exec, whose
+// output is the agent's, and the emergency stop, which mustn't wait for
+// anything
+var updateNoticeSkipped = map[string]bool{
+	"install": true, "upgrade": true, "uninstall": true, "versions": true, "exec": true, "panic-stop": true,
+}
+
+// updateCheck is the last lookup of the latest agent release, kept in the
+// user's cache directory
+type updateCheck struct {
+	CheckedAt   time.Time `json:"checked_at"`
+	LatestAgent string    `json:"latest_agent,omitempty"`
+}
+
+// updateNotice is a newer agent release than the installed one
+type updateNotice struct {
+	installed, latest string
+}
+
+// pendingUpdateNotice receives the notice of the running command, or nil if
+// the agent is up to date
+var pendingUpdateNotice chan *updateNotice
+
+// updateNotifierEnabled reports whether update notices are shown. They are
+// on unless $FIXPANIC_NO_UPDATE_NOTIFIER or the update_notifier setting of
+// the CLI configuration turns them off.
+func updateNotifierEnabled() bool {
+	if value := os.Getenv(envNoUpdateNotifier); value != "" {
+		disabled, err := strconv.ParseBool(value)
+		return err == nil && !disabled
+	}
+	return cliConfig().NotifyUpdates()
+}
+
+// startUpdateNotice looks up, in the background, whether a newer agent
+// release than the installed one exists when cmd is an agent command run by
+// a person at a terminal. printUpdateNotice reports it once cmd returned.
+func startUpdateNotice(cmd *cobra.Command) {
+	if !updateNotifierEnabled() || !term.IsTerminal(os.Stderr) || requires(cmd, requireNoTelemetry) {
+		return
+	}
+	subcommand := agentSubcommand(cmd)
+	if subcommand == nil || updateNoticeSkipped[subcommand.Name()] {
+		return
+	}
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return
+	}
+
+	pendingUpdateNotice = make(chan *updateNotice, 1)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(cmd.Context()), updateCheckTimeout)
+	go func() {
+		defer cancel()
+		pendingUpdateNotice <- agentUpdateNotice(ctx, connectivity.NewManager(platformInfo))
+	}()
+}
+
+// printUpdateNotice prints the notice looked up by startUpdateNotice once
+// the lookup finished, unless the command failed with err
+func printUpdateNotice(err error) {
+	if pendingUpdateNotice == nil || err != nil {
+		return
+	}
+	notice := <-pendingUpdateNotice
+	if notice == nil {
+		return
+	}
+	logger.Info("Agent %s is available (installed: %s); run 'fixpanic agent upgrade' to update", notice.latest, notice.installed)
+}
+
+// agentSubcommand returns the subcommand of 'fixpanic agent' that cmd is,
+// belongs to or is a shortcut for, or nil for commands outside 'fixpanic agent'
+func agentSubcommand(cmd *cobra.Command) *cobra.Command {
+	if target, ok := shortcutTargets[cmd]; ok {
+		return target
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Parent() == agentCmd {
+			return c
+		}
+	}
+	return nil
+}
+
+// agentUpdateNotice returns a notice if the latest agent release is not the
+// installed one, looking the release up at most once per
+// updateCheckInterval
+func agentUpdateNotice(ctx context.Context, connectivityManager *connectivity.Manager) *updateNotice {
+	if !connectivityManager.IsFixPanicAgentInstalled() {
+		return nil
+	}
+	output, err := connectivityManager.GetFixPanicAgentVersion(ctx)
+	if err != nil {
+		return nil
+	}
+	installed, err := releases.ParseTag(connectivity.ParseAgentVersion(output))
+	if err != nil {
+		// Development builds aren't compared with releases
+		return nil
+	}
+
+	latest := latestAgentForNotice(ctx, connectivityManager)
+	if latest == "" || normalizeVersion(latest) == normalizeVersion(installed) {
+		return nil
+	}
+	return &updateNotice{installed: installed, latest: latest}
+}
+
+// latestAgentForNotice returns the latest agent release, from the last
+// lookup if it is recent enough. Failed lookups are remembered too so an
+// unreachable GitHub doesn't slow down every command.
+func latestAgentForNotice(ctx context.Context, connectivityManager *connectivity.Manager) string {
+	path := updateCheckPath()
+	var last updateCheck
+	if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &last) == nil {
+		if time.Since(last.CheckedAt) >= 0 && time.Since(last.CheckedAt) < updateCheckInterval {
+			return last.LatestAgent
+		}
+	}
+
+	latest, err := connectivityManager.GetLatestAgentVersion(ctx)
+	if err != nil {
+		latest = ""
+	}
+	if path != "" {
+		data, _ := json.Marshal(updateCheck{CheckedAt: time.Now().UTC(), LatestAgent: latest})
+		if os.MkdirAll(filepath.Dir(path), 0700) == nil {
+			os.WriteFile(path, data, 0600)
+		}
+	}
+	return latest
+}
+
+// updateCheckPath returns where the last update check is kept, or an empty
+// string if there is no cache directory
+func updateCheckPath() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "fixpanic", "update-check.json")
+}
@@ -3,17 +3,33 @@ package cmd
 import (
 	"archive/tar"
 	"compress/gzip"
+	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/fixpanic/fixpanic-cli/internal/cliconfig"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/diskspace"
+	"github.com/fixpanic/fixpanic-cli/internal/events"
+	"github.com/fixpanic/fixpanic-cli/internal/httpcache"
+	"github.com/fixpanic/fixpanic-cli/internal/interrupt"
 	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/releases"
+	"github.com/fixpanic/fixpanic-cli/internal/state"
+	"github.com/fixpanic/fixpanic-cli/internal/summary"
+	"github.com/fixpanic/fixpanic-cli/internal/trace"
 	"github.com/spf13/cobra"
 )
 
@@ -31,20 +47,30 @@ var upgradeCmd = &cobra.Command{
 This command will:
 - Check the current version
 - Fetch the latest release information from GitHub
+- Show the release notes of every version since the installed one
+- Refuse versions that were yanked (pulled because of a problem)
 - Download and install the new version if available
 - Verify the upgrade was successful
 
 The upgrade is performed safely by downloading to a temporary location first,
-then replacing the current binary atomically.`,
+then replacing the current binary atomically. Use --show-notes-only to
+read the release notes without upgrading.
+
+A version is yanked when its release is marked as such or it is on the
+published blocklist; --allow-yanked installs it anyway.`,
 	Example: `  # Check for available updates
   fixpanic upgrade --check
 
+  # Read what changed since the installed version
+  fixpanic upgrade --show-notes-only
+
   # Upgrade to the latest version
   fixpanic upgrade
 
   # Force upgrade even if already on latest version
   fixpanic upgrade --force`,
-	RunE: runUpgrade,
+	Annotations: map[string]string{annotationMutating: "!show-notes-only"},
+	RunE:        runUpgrade,
 }
 
 func init() {
@@ -53,6 +79,9 @@ func init() {
 	// Add flags
 	upgradeCmd.Flags().BoolVar(&forceUpgrade, "force", false, "Force upgrade even if already on latest version")
 	upgradeCmd.Flags().BoolVar(&checkOnly, "check", false, "Only check for updates without upgrading")
+	upgradeCmd.Flags().BoolVar(&allowYanked, "allow-yanked", false, "Upgrade even if the target version was yanked")
+	upgradeCmd.Flags().BoolVar(&showNotesOnly, "show-notes-only", false, "Show the release notes since the installed version without upgrading")
+	addSummaryFlag(upgradeCmd)
 }
 
 // GitHubRelease represents a GitHub release
@@ -65,6 +94,9 @@ type GitHubRelease struct {
 		Name               string `json:"name"`
 		BrowserDownloadURL string `json:"browser_download_url"`
 		Size               int64  `json:"size"`
+		// Digest is "sha256:<hex>" for assets uploaded since GitHub started
+		// recording digests, empty otherwise
+		Digest string `json:"digest"`
 	} `json:"assets"`
 }
 
@@ -75,17 +107,25 @@ func runUpgrade(cmd *cobra.Command, args []string) error {
 	logger.Step(1, "Checking current version")
 	currentVersion := getCurrentVersion()
 	logger.KeyValue("Current version", currentVersion)
+	logger.KeyValue("Update channel", cliConfig().Channel())
 
 	// Fetch latest release info
 	logger.Step(2, "Fetching latest release information")
-	latestRelease, err := getLatestRelease()
+	latestRelease, err := getLatestRelease(cmd.Context())
 	if err != nil {
-		return fmt.Errorf("failed to fetch latest release: %w", err)
+		return clierror.New(clierror.Network, "failed to fetch latest release: %w", err)
 	}
 
 	logger.KeyValue("Latest version", latestRelease.TagName)
 	logger.KeyValue("Release date", formatReleaseDate(latestRelease.PublishedAt))
 
+	if showNotesOnly {
+		if err := showReleaseNotes(cmd.Context(), releases.CLIRepo, currentVersion, latestRelease.TagName); err != nil {
+			return clierror.New(clierror.Network, "failed to fetch release notes: %w", err)
+		}
+		return nil
+	}
+
 	// Compare versions
 	if !forceUpgrade && currentVersion == latestRelease.TagName {
 		logger.Success("You are already on the latest version!")
@@ -101,43 +141,65 @@ func runUpgrade(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if err := checkNotYanked(cmd.Context(), releases.CLIRepo, latestRelease.TagName); err != nil {
+		return err
+	}
+
 	// Show what will be upgraded
 	logger.Separator()
 	if currentVersion == latestRelease.TagName {
 		logger.Info("Forcing upgrade to same version")
 	} else {
 		logger.Info("Upgrading: %s → %s", currentVersion, latestRelease.TagName)
+		if err := showReleaseNotes(cmd.Context(), releases.CLIRepo, currentVersion, latestRelease.TagName); err != nil {
+			logger.Warning("Could not fetch release notes: %v", err)
+		}
 	}
 	logger.Separator()
 
+	// Failed attempts are recorded in the history like successful ones
+	failed := func(err error) error {
+		recordVersionChange(cmd.Context(), state.ComponentCLI, currentVersion, latestRelease.TagName, err)
+		return err
+	}
+
 	// Get current binary path
 	currentBinaryPath, err := getCurrentBinaryPath()
 	if err != nil {
-		return fmt.Errorf("failed to get current binary path: %w", err)
+		return failed(fmt.Errorf("failed to get current binary path: %w", err))
 	}
 
 	logger.KeyValue("Current binary", currentBinaryPath)
 
 	// Download new version
 	logger.Step(3, "Downloading new version")
-	newBinaryPath, err := downloadNewVersion(latestRelease)
+	newBinaryPath, err := downloadNewVersion(cmd.Context(), latestRelease, filepath.Dir(currentBinaryPath))
 	if err != nil {
-		return fmt.Errorf("failed to download new version: %w", err)
+		var insufficient *diskspace.InsufficientError
+		if errors.As(err, &insufficient) {
+			return failed(withDiskSpaceHint(err))
+		}
+		return failed(clierror.New(clierror.Network, "failed to download new version: %w", err))
 	}
 	defer os.RemoveAll(filepath.Dir(newBinaryPath)) // Cleanup temp directory
 
 	// Verify new binary
 	logger.Step(4, "Verifying new binary")
-	if err := verifyNewBinary(newBinaryPath); err != nil {
-		return fmt.Errorf("failed to verify new binary: %w", err)
+	if err := verifyNewBinary(cmd.Context(), newBinaryPath, latestRelease.TagName); err != nil {
+		return failed(fmt.Errorf("failed to verify new binary: %w", err))
 	}
 
 	logger.Success("New binary verified successfully")
 
 	// Replace current binary
 	logger.Step(5, "Installing new version")
-	if err := replaceBinary(currentBinaryPath, newBinaryPath); err != nil {
-		return fmt.Errorf("failed to replace binary: %w", err)
+	err = interrupt.Critical(func() error { return replaceBinary(currentBinaryPath, newBinaryPath) })
+	if err != nil {
+		return failed(fmt.Errorf("failed to replace binary: %w", err))
+	}
+	summary.Touch(currentBinaryPath)
+	if currentVersion != latestRelease.TagName {
+		recordVersionChange(cmd.Context(), state.ComponentCLI, currentVersion, latestRelease.TagName, nil)
 	}
 
 	// Verify upgrade
@@ -153,13 +215,6 @@ func runUpgrade(cmd *cobra.Command, args []string) error {
 	logger.KeyValue("New version", latestRelease.TagName)
 	logger.Separator()
 
-	// Show release notes if available
-	if latestRelease.Body != "" && len(latestRelease.Body) < 500 {
-		logger.Info("Release notes:")
-		logger.Plain(strings.TrimSpace(latestRelease.Body))
-		logger.Separator()
-	}
-
 	logger.Info("Run 'fixpanic --version' to confirm the new version")
 
 	return nil
@@ -190,13 +245,19 @@ func getCurrentBinaryPath() (string, error) {
 }
 
 // getLatestRelease fetches the latest release from GitHub
-func getLatestRelease() (*GitHubRelease, error) {
+func getLatestRelease(ctx context.Context) (*GitHubRelease, error) {
 	client := &http.Client{Timeout: 30 * time.Second}
 
 	url := "https://api.github.com/repos/fixpanic/fixpanic-cli-tool/releases/latest"
+	// The beta channel follows the newest release, pre-releases included,
+	// which GitHub lists first
+	beta := cliConfig().Channel() == cliconfig.ChannelBeta
+	if beta {
+		url = "https://api.github.com/repos/fixpanic/fixpanic-cli-tool/releases?per_page=1"
+	}
 	logger.Loading("Fetching from GitHub API...")
 
-	resp, err := client.Get(url)
+	resp, err := httpcache.Default().Get(ctx, client, url)
 	if err != nil {
 		logger.LoadingFailed("Failed to fetch")
 		return nil, err
@@ -208,18 +269,41 @@ func getLatestRelease() (*GitHubRelease, error) {
 		return nil, fmt.Errorf("GitHub API request failed: %d", resp.StatusCode)
 	}
 
+	// Read the whole body so the response is cached for the next check
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.LoadingFailed("Failed to fetch")
+		return nil, err
+	}
+
 	logger.LoadingDone("Release info fetched")
 
+	if beta {
+		var newest []GitHubRelease
+		if err := json.Unmarshal(body, &newest); err != nil {
+			return nil, fmt.Errorf("failed to parse release info: %w", err)
+		}
+		if len(newest) == 0 {
+			return nil, fmt.Errorf("no releases published")
+		}
+		return &newest[0], nil
+	}
+
 	var release GitHubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+	if err := json.Unmarshal(body, &release); err != nil {
 		return nil, fmt.Errorf("failed to parse release info: %w", err)
 	}
 
 	return &release, nil
 }
 
+// extractionFactor is the room needed per byte of a downloaded archive for
+// the binary extracted from it
+const extractionFactor = 2
+
 // downloadNewVersion downloads the appropriate binary for the current platform
-func downloadNewVersion(release *GitHubRelease) (string, error) {
+// into a temporary directory, which is removed again if anything fails
+func downloadNewVersion(ctx context.Context, release *GitHubRelease, installDir string) (string, error) {
 	// Determine platform-specific binary name
 	assetName := fmt.Sprintf("fixpanic-%s-%s", runtime.GOOS, runtime.GOARCH)
 	if runtime.GOOS != "windows" {
@@ -229,12 +313,13 @@ func downloadNewVersion(release *GitHubRelease) (string, error) {
 	}
 
 	// Find the asset
-	var downloadURL string
+	var downloadURL, checksum string
 	var assetSize int64
 	for _, asset := range release.Assets {
 		if asset.Name == assetName {
 			downloadURL = asset.BrowserDownloadURL
 			assetSize = asset.Size
+			checksum, _ = strings.CutPrefix(asset.Digest, "sha256:")
 			break
 		}
 	}
@@ -246,66 +331,101 @@ func downloadNewVersion(release *GitHubRelease) (string, error) {
 	logger.KeyValue("Asset", assetName)
 	logger.KeyValue("Size", fmt.Sprintf("%.1f MB", float64(assetSize)/(1024*1024)))
 
+	// Fail early rather than with a partial write when the temp or install
+	// directory is full
+	if err := checkUpgradeDiskSpace(assetName, assetSize, installDir); err != nil {
+		return "", err
+	}
+
 	// Create temporary directory
 	tempDir, err := os.MkdirTemp("", "fixpanic-upgrade-*")
 	if err != nil {
 		return "", fmt.Errorf("failed to create temp directory: %w", err)
 	}
 
-	// Download the asset
+	binaryPath, err := downloadAsset(ctx, downloadURL, assetName, checksum, tempDir)
+	if err != nil {
+		os.RemoveAll(tempDir)
+		return "", err
+	}
+
+	logger.Success("Binary ready at: %s", binaryPath)
+	return binaryPath, nil
+}
+
+// checkUpgradeDiskSpace verifies the temp directory can hold the binary
+// downloaded or extracted from the asset, and that the install directory can
+// hold the new binary alongside the backup of the current one
+func checkUpgradeDiskSpace(assetName string, assetSize int64, installDir string) error {
+	if assetSize <= 0 {
+		return nil
+	}
+	required := uint64(assetSize)
+	if strings.HasSuffix(assetName, ".tar.gz") {
+		required *= extractionFactor
+	}
+
+	if err := diskspace.Check(os.TempDir(), required); err != nil {
+		return err
+	}
+	return diskspace.Check(installDir, required)
+}
+
+// downloadAsset downloads a release asset into tempDir and returns the path of
+// the executable it contains. Archives are extracted as they download, so
+// only the binary is written to disk. With a non-empty expectedSHA256 the
+// whole download must have that checksum.
+func downloadAsset(ctx context.Context, downloadURL, assetName, expectedSHA256, tempDir string) (binaryPath string, err error) {
+	span := trace.Start("download "+assetName, trace.KindDownload, nil)
+	defer func() { span.End(err) }()
+
 	client := &http.Client{Timeout: 5 * time.Minute}
 
 	logger.Loading("Downloading %s...", assetName)
 
-	resp, err := client.Get(downloadURL)
+	resp, err := httpcache.Default().Get(ctx, client, downloadURL)
 	if err != nil {
 		logger.LoadingFailed("Download failed")
 		return "", err
 	}
 	defer resp.Body.Close()
+	span.SetAttribute("download.cached", strconv.FormatBool(resp.Header.Get(httpcache.HeaderCache) == "hit"))
 
 	if resp.StatusCode != 200 {
 		logger.LoadingFailed("HTTP %d", resp.StatusCode)
 		return "", fmt.Errorf("download failed: HTTP %d", resp.StatusCode)
 	}
 
-	logger.LoadingDone("Download completed")
-
-	// Save to temp file
-	tempArchivePath := filepath.Join(tempDir, assetName)
-	tempFile, err := os.Create(tempArchivePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %w", err)
-	}
-
-	logger.Progress("Saving to temporary file")
-	if _, err := io.Copy(tempFile, resp.Body); err != nil {
-		tempFile.Close()
-		return "", fmt.Errorf("failed to save download: %w", err)
+	if resp.Header.Get(httpcache.HeaderCache) == "hit" {
+		logger.LoadingDone("Using cached download")
+	} else {
+		logger.LoadingDone("Download started")
 	}
 
-	// Sync to ensure all data is written to disk
-	if err := tempFile.Sync(); err != nil {
-		tempFile.Close()
-		return "", fmt.Errorf("failed to sync file to disk: %w", err)
-	}
+	hash := sha256.New()
+	body := io.TeeReader(events.NewProgressReader(resp.Body, resp.ContentLength, assetName), hash)
 
-	// Close the file before extraction/chmod
-	if err := tempFile.Close(); err != nil {
-		return "", fmt.Errorf("failed to close file: %w", err)
-	}
-
-	// Extract binary if it's a tar.gz
-	var binaryPath string
 	if strings.HasSuffix(assetName, ".tar.gz") {
-		logger.Progress("Extracting binary from archive")
-		binaryPath, err = extractBinaryFromTarGz(tempArchivePath, tempDir)
+		logger.Progress("Extracting binary from the download")
+		binaryPath, err = extractBinaryFromTarGz(body, tempDir)
 		if err != nil {
 			return "", fmt.Errorf("failed to extract binary: %w", err)
 		}
+		// Read the rest of the archive so the checksum covers all of it
+		if _, err := io.Copy(io.Discard, body); err != nil {
+			return "", fmt.Errorf("failed to download %s: %w", assetName, err)
+		}
 	} else {
 		// For Windows .exe, the file is the binary
-		binaryPath = tempArchivePath
+		logger.Progress("Saving to temporary file")
+		binaryPath = filepath.Join(tempDir, assetName)
+		if err := saveFile(binaryPath, body); err != nil {
+			return "", fmt.Errorf("failed to save download: %w", err)
+		}
+	}
+
+	if actual := fmt.Sprintf("%x", hash.Sum(nil)); expectedSHA256 != "" && !strings.EqualFold(actual, expectedSHA256) {
+		return "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", assetName, expectedSHA256, actual)
 	}
 
 	// Make binary executable
@@ -313,19 +433,12 @@ func downloadNewVersion(release *GitHubRelease) (string, error) {
 		return "", fmt.Errorf("failed to make binary executable: %w", err)
 	}
 
-	logger.Success("Binary ready at: %s", binaryPath)
 	return binaryPath, nil
 }
 
-// extractBinaryFromTarGz extracts the binary from a tar.gz archive
-func extractBinaryFromTarGz(archivePath, extractDir string) (string, error) {
-	file, err := os.Open(archivePath)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	gzr, err := gzip.NewReader(file)
+// extractBinaryFromTarGz extracts the binary from a tar.gz archive read from r
+func extractBinaryFromTarGz(r io.Reader, extractDir string) (string, error) {
+	gzr, err := gzip.NewReader(r)
 	if err != nil {
 		return "", err
 	}
@@ -346,28 +459,9 @@ func extractBinaryFromTarGz(archivePath, extractDir string) (string, error) {
 		baseName := filepath.Base(header.Name)
 		if header.Typeflag == tar.TypeReg && (baseName == "fixpanic" || strings.HasPrefix(baseName, "fixpanic-")) {
 			binaryPath := filepath.Join(extractDir, "fixpanic")
-
-			outFile, err := os.Create(binaryPath)
-			if err != nil {
+			if err := saveFile(binaryPath, tr); err != nil {
 				return "", err
 			}
-
-			if _, err := io.Copy(outFile, tr); err != nil {
-				outFile.Close()
-				return "", err
-			}
-
-			// Sync to ensure all data is written to disk
-			if err := outFile.Sync(); err != nil {
-				outFile.Close()
-				return "", err
-			}
-
-			// Close the file
-			if err := outFile.Close(); err != nil {
-				return "", err
-			}
-
 			return binaryPath, nil
 		}
 	}
@@ -375,12 +469,29 @@ func extractBinaryFromTarGz(archivePath, extractDir string) (string, error) {
 	return "", fmt.Errorf("binary not found in archive")
 }
 
-// verifyNewBinary checks that the new binary is valid
-func verifyNewBinary(binaryPath string) error {
-	// Try to run --version on the new binary
-	// Note: We can't actually execute it here because it might be a different architecture
-	// So we just check that it exists and is executable
+// saveFile writes r to path and syncs it to disk
+func saveFile(path string, r io.Reader) error {
+	outFile, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(outFile, r); err != nil {
+		outFile.Close()
+		return err
+	}
+
+	// Sync to ensure all data is written to disk
+	if err := outFile.Sync(); err != nil {
+		outFile.Close()
+		return err
+	}
+
+	return outFile.Close()
+}
 
+// verifyNewBinary checks that the new binary is valid and reports the release version
+func verifyNewBinary(ctx context.Context, binaryPath, expectedVersion string) error {
 	info, err := os.Stat(binaryPath)
 	if err != nil {
 		return fmt.Errorf("binary not found: %w", err)
@@ -395,9 +506,77 @@ func verifyNewBinary(binaryPath string) error {
 	}
 
 	logger.KeyValue("Binary size", fmt.Sprintf("%.1f MB", float64(info.Size())/(1024*1024)))
+
+	// The asset was picked for the running OS and architecture, so it must run
+	// here; executing it catches corrupt and mislabelled artifacts before the
+	// working binary is replaced
+	reported, err := runVersionSandboxed(ctx, binaryPath)
+	if err != nil {
+		return err
+	}
+	logger.KeyValue("Reported version", reported)
+
+	if normalizeVersion(reported) != normalizeVersion(expectedVersion) {
+		return fmt.Errorf("binary reports version %s, expected %s", reported, expectedVersion)
+	}
 	return nil
 }
 
+// versionCheckTimeout bounds how long the downloaded binary may take to print its version
+const versionCheckTimeout = 10 * time.Second
+
+// runVersionSandboxed runs binaryPath --version from an empty scratch
+// directory that also serves as its home, so a broken build can't read or
+// write the user's configuration, and returns the version it reports
+func runVersionSandboxed(ctx context.Context, binaryPath string) (string, error) {
+	sandbox, err := os.MkdirTemp("", "fixpanic-verify-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+	defer os.RemoveAll(sandbox)
+
+	ctx, cancel := context.WithTimeout(ctx, versionCheckTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, binaryPath, "--version")
+	cmd.Dir = sandbox
+	cmd.Env = append(os.Environ(), "HOME="+sandbox, "USERPROFILE="+sandbox, "XDG_CONFIG_HOME="+sandbox)
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("binary did not report its version within %s", versionCheckTimeout)
+	}
+	if err != nil {
+		var execErr *exec.Error
+		if errors.As(err, &execErr) || errors.Is(err, syscall.ENOEXEC) {
+			return "", fmt.Errorf("binary cannot be executed on %s/%s (corrupt or wrong architecture): %w", runtime.GOOS, runtime.GOARCH, err)
+		}
+		return "", fmt.Errorf("binary failed to run: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+
+	reported := parseReportedVersion(string(output))
+	if reported == "" {
+		return "", fmt.Errorf("binary printed no version: %q", strings.TrimSpace(string(output)))
+	}
+	return reported, nil
+}
+
+// parseReportedVersion extracts the version from 'fixpanic --version' output,
+// e.g. "fixpanic version v1.2.3 (commit: abc, built: ...)"
+func parseReportedVersion(output string) string {
+	fields := strings.Fields(output)
+	for i, field := range fields {
+		if field == "version" && i+1 < len(fields) {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
+
+// normalizeVersion strips the optional "v" prefix so tags and build versions compare equal
+func normalizeVersion(version string) string {
+	return strings.TrimPrefix(strings.TrimSpace(version), "v")
+}
+
 // replaceBinary safely replaces the current binary with the new one
 func replaceBinary(currentPath, newPath string) error {
 	// On Unix systems, we can use os.Rename to atomically replace a running binary
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"text/tabwriter"
+
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	versionVerbose bool
+	versionJSON    bool
+)
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show version, build and environment information",
+	Long: `Show the CLI version. With --verbose also print build details, the
+platform, the installed agent version and the paths in use, so a support
+ticket contains everything needed in one paste.
+
+--porcelain prints the verbose details as stable tab-separated key/value
+lines for scripts, git-style: a "# porcelain v1" line naming the format, then
+one "key<TAB>value" line per field, with tabs, newlines and backslashes in
+values escaped. Keys are only ever added to a format version; the pretty
+output may change at any time. Pass --porcelain=<version> to pin a format.`,
+	Example: `  # Print everything for a support ticket
+  fixpanic version --verbose
+
+  # Machine-readable output
+  fixpanic version --verbose --json
+
+  # Stable output for scripts
+  fixpanic version --porcelain`,
+	RunE: runVersion,
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+
+	// Add flags
+	versionCmd.Flags().BoolVarP(&versionVerbose, "verbose", "v", false, "Include build, platform and agent details")
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Output as JSON")
+	addPorcelainFlag(versionCmd)
+}
+
+// versionInfo is everything 'fixpanic version --verbose' reports
+type versionInfo struct {
+	Version       string `json:"version"`
+	Commit        string `json:"commit,omitempty"`
+	BuildDate     string `json:"build_date,omitempty"`
+	GoVersion     string `json:"go_version,omitempty"`
+	Platform      string `json:"platform,omitempty"`
+	Environment   string `json:"environment,omitempty"`
+	AgentVersion  string `json:"agent_version,omitempty"`
+	AgentConfig   string `json:"agent_config,omitempty"`
+	CLIConfig     string `json:"cli_config,omitempty"`
+	UpdateChannel string `json:"update_channel,omitempty"`
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	info := versionInfo{Version: getCurrentVersion()}
+
+	if versionVerbose || porcelainFormat != "" {
+		info.Commit = commit
+		info.BuildDate = date
+		info.GoVersion = runtime.Version()
+		info.Platform = runtime.GOOS + "/" + runtime.GOARCH
+		info.Environment = platform.DetectEnvironment().String()
+		info.CLIConfig = viper.ConfigFileUsed()
+		if info.CLIConfig == "" {
+			info.CLIConfig = "none"
+		}
+		info.UpdateChannel = cliConfig().Channel()
+
+		info.AgentVersion = "not installed"
+		if platformInfo, err := commandPlatform(); err == nil {
+			info.AgentConfig = platformInfo.GetConfigPath()
+			manager := connectivity.NewManager(platformInfo)
+			if manager.IsFixPanicAgentInstalled() {
+				if output, err := manager.GetFixPanicAgentVersion(cmd.Context()); err == nil {
+					info.AgentVersion = connectivity.ParseAgentVersion(output)
+				} else {
+					info.AgentVersion = fmt.Sprintf("unknown (%v)", err)
+				}
+			}
+		}
+	}
+
+	if porcelainFormat != "" {
+		p, err := newPorcelainWriter(os.Stdout)
+		if err != nil {
+			return err
+		}
+		p.field("version", info.Version)
+		p.field("commit", info.Commit)
+		p.field("build_date", info.BuildDate)
+		p.field("go_version", info.GoVersion)
+		p.field("platform", info.Platform)
+		p.field("environment", info.Environment)
+		p.field("agent_version", info.AgentVersion)
+		p.field("agent_config", info.AgentConfig)
+		p.field("cli_config", info.CLIConfig)
+		p.field("update_channel", info.UpdateChannel)
+		return p.close()
+	}
+
+	if versionJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	}
+
+	if !versionVerbose {
+		fmt.Printf("fixpanic version %s\n", info.Version)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Version:\t%s\n", info.Version)
+	fmt.Fprintf(w, "Commit:\t%s\n", info.Commit)
+	fmt.Fprintf(w, "Built:\t%s\n", info.BuildDate)
+	fmt.Fprintf(w, "Go version:\t%s\n", info.GoVersion)
+	fmt.Fprintf(w, "Platform:\t%s\n", info.Platform)
+	fmt.Fprintf(w, "Environment:\t%s\n", info.Environment)
+	fmt.Fprintf(w, "Agent version:\t%s\n", info.AgentVersion)
+	fmt.Fprintf(w, "Agent config:\t%s\n", info.AgentConfig)
+	fmt.Fprintf(w, "CLI config:\t%s\n", info.CLIConfig)
+	fmt.Fprintf(w, "Update channel:\t%s\n", info.UpdateChannel)
+	return w.Flush()
+}
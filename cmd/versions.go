@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/httpcache"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/releases"
+	"github.com/spf13/cobra"
+)
+
+var (
+	versionsPage    int
+	versionsPerPage int
+	versionsJSON    bool
+)
+
+// versionsCmd represents the versions command
+var versionsCmd = &cobra.Command{
+	Use:   "versions",
+	Short: "List available CLI releases",
+	Long: `List the published releases of the FixPanic CLI, newest first, with their
+date, status and the first line of their notes. The installed version is
+marked, as are pre-releases and releases that were yanked (pulled because
+of a problem), so you can choose a version to pin.
+
+Releases are fetched from GitHub one page at a time; use --page to go
+further back.`,
+	Example: `  # Latest CLI releases
+  fixpanic versions
+
+  # The next 50
+  fixpanic versions --page=2 --per-page=50
+
+  # Machine-readable
+  fixpanic versions --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runVersions(cmd, releases.CLIRepo, getCurrentVersion())
+	},
+}
+
+// agentVersionsCmd represents the agent versions command
+var agentVersionsCmd = &cobra.Command{
+	Use:   "versions",
+	Short: "List available agent releases",
+	Long: `List the published releases of the FixPanic agent, newest first, with their
+date, status and the first line of their notes. The installed version is
+marked, as are pre-releases and releases that were yanked (pulled because
+of a problem), so you can choose a version to pin.
+
+Releases are fetched from GitHub one page at a time; use --page to go
+further back.`,
+	Example: `  # Latest agent releases
+  fixpanic agent versions
+
+  # Machine-readable
+  fixpanic agent versions --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runVersions(cmd, releases.AgentRepo, installedAgentVersion(cmd))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionsCmd)
+	agentCmd.AddCommand(agentVersionsCmd)
+
+	// Add flags
+	for _, c := range []*cobra.Command{versionsCmd, agentVersionsCmd} {
+		c.Flags().IntVar(&versionsPage, "page", 1, "Page of releases to show, starting at 1")
+		c.Flags().IntVar(&versionsPerPage, "per-page", releases.DefaultPerPage, "Releases per page (at most 100)")
+		c.Flags().BoolVar(&versionsJSON, "json", false, "Output as JSON")
+	}
+}
+
+// versionEntry is one release as 'versions --json' reports it
+type versionEntry struct {
+	Version     string `json:"version"`
+	Name        string `json:"name,omitempty"`
+	PublishedAt string `json:"published_at"`
+	Prerelease  bool   `json:"prerelease"`
+	Yanked      bool   `json:"yanked"`
+	YankReason  string `json:"yank_reason,omitempty"`
+	Installed   bool   `json:"installed"`
+	Summary     string `json:"summary,omitempty"`
+	URL         string `json:"url"`
+}
+
+func runVersions(cmd *cobra.Command, repo, installed string) error {
+	if versionsPerPage < 1 || versionsPerPage > 100 {
+		return clierror.New(clierror.Usage, "--per-page must be between 1 and 100")
+	}
+	if versionsPage < 1 {
+		return clierror.New(clierror.Usage, "--page must be 1 or higher")
+	}
+
+	page, err := releases.List(cmd.Context(), httpcache.Default(), repo, versionsPage, versionsPerPage)
+	if err != nil {
+		return clierror.New(clierror.Network, "failed to list releases: %w", err)
+	}
+
+	entries := make([]versionEntry, 0, len(page.Releases))
+	for _, release := range page.Releases {
+		reason, yanked := release.Yanked()
+		entries = append(entries, versionEntry{
+			Version:     release.TagName,
+			Name:        release.Name,
+			PublishedAt: release.PublishedAt.Format("2006-01-02"),
+			Prerelease:  release.Prerelease,
+			Yanked:      yanked,
+			YankReason:  reason,
+			Installed:   installed != "" && normalizeVersion(installed) == normalizeVersion(release.TagName),
+			Summary:     release.Summary(60),
+			URL:         release.HTMLURL,
+		})
+	}
+
+	if versionsJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		logger.Info("No releases found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tDATE\tSTATUS\tNOTES")
+	for _, entry := range entries {
+		var status []string
+		if entry.Installed {
+			status = append(status, "installed")
+		}
+		if entry.Prerelease {
+			status = append(status, "pre-release")
+		}
+		if entry.Yanked {
+			status = append(status, "yanked")
+		}
+		notes := entry.Summary
+		if entry.Yanked && entry.YankReason != "" {
+			notes = "yanked: " + entry.YankReason
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Version, entry.PublishedAt, strings.Join(status, ", "), notes)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if page.More {
+		fmt.Fprintf(os.Stderr, "\nMore releases: %s --page=%d\n", cmd.CommandPath(), versionsPage+1)
+	}
+	return nil
+}
+
+// installedAgentVersion returns the version of the installed agent, or an
+// empty string if there is none
+func installedAgentVersion(cmd *cobra.Command) string {
+	platformInfo, err := commandPlatform()
+	if err != nil {
+		return ""
+	}
+	manager := connectivity.NewManager(platformInfo)
+	if !manager.IsFixPanicAgentInstalled() {
+		return ""
+	}
+	output, err := manager.GetFixPanicAgentVersion(cmd.Context())
+	if err != nil {
+		return ""
+	}
+	return connectivity.ParseAgentVersion(output)
+}
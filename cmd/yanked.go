@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+)
+
+// allowYanked lets 'upgrade' and 'agent upgrade' install a version that was
+// yanked
+var allowYanked bool
+
+// checkNotYanked refuses the upgrade to version of repo if that version was
+// yanked, unless --allow-yanked is set
+func checkNotYanked(ctx context.Context, repo, version string) error {
+	return agentops.CheckNotYanked(ctx, repo, version, allowYanked)
+}
@@ -0,0 +1,144 @@
+// Package agentctl talks to the running agent over its local control socket,
+// an HTTP API on a Unix socket that only local users with access to the
+// socket file can reach
+package agentctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+)
+
+// requestTimeout bounds a single request to the agent
+const requestTimeout = 5 * time.Second
+
+var (
+	// ErrNotListening is returned when no agent serves the control socket,
+	// because it isn't running
+	ErrNotListening = errors.New("the agent is not listening on its control socket")
+	// ErrUnsupported is returned by agents too old to serve a request
+	ErrUnsupported = errors.New("the agent does not support this request")
+	// ErrSessionNotFound is returned when killing a session that doesn't exist
+	ErrSessionNotFound = errors.New("no such session")
+)
+
+// Session is a remote-debug session the agent is serving
+type Session struct {
+	ID string `json:"id"`
+	// User is the dashboard user who opened the session
+	User string `json:"user"`
+	// Source is the address the session was opened from
+	Source  string    `json:"source"`
+	Started time.Time `json:"started_at"`
+	// Command is what the session is running right now, if anything
+	Command string `json:"command,omitempty"`
+}
+
+// Client sends requests to the agent's control socket
+type Client struct {
+	socket string
+	http   *http.Client
+}
+
+// NewClient returns a client for the control socket at socket
+func NewClient(socket string) *Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &Client{socket: socket, http: &http.Client{Transport: transport}}
+}
+
+// Sessions returns the remote-debug sessions the agent is serving
+func (c *Client) Sessions(ctx context.Context) ([]Session, error) {
+	var sessions []Session
+	if err := c.do(ctx, http.MethodGet, "/v1/sessions", &sessions); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// KillSession makes the agent terminate the session id and whatever it runs
+func (c *Client) KillSession(ctx context.Context, id string) error {
+	err := c.do(ctx, http.MethodDelete, "/v1/sessions/"+url.PathEscape(id), nil)
+	if errors.Is(err, ErrUnsupported) {
+		// Agents that support sessions answer unknown ones with 404 as well
+		if _, listErr := c.Sessions(ctx); listErr == nil {
+			err = ErrSessionNotFound
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to kill session %s: %w", id, err)
+	}
+	return nil
+}
+
+// Drain makes the agent refuse new sessions while the active ones continue,
+// ahead of stopping it
+func (c *Client) Drain(ctx context.Context) error {
+	if err := c.do(ctx, http.MethodPost, "/v1/drain", nil); err != nil {
+		return fmt.Errorf("failed to drain the agent: %w", err)
+	}
+	return nil
+}
+
+// Resume makes a draining agent accept new sessions again
+func (c *Client) Resume(ctx context.Context) error {
+	if err := c.do(ctx, http.MethodDelete, "/v1/drain", nil); err != nil {
+		return fmt.Errorf("failed to resume the agent: %w", err)
+	}
+	return nil
+}
+
+// Policy returns the command policy the agent has loaded
+func (c *Client) Policy(ctx context.Context) (*config.PolicySection, error) {
+	var policy config.PolicySection
+	if err := c.do(ctx, http.MethodGet, "/v1/policy", &policy); err != nil {
+		return nil, fmt.Errorf("failed to get the loaded policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// do sends a request and decodes the JSON response into result, if not nil
+func (c *Client) do(ctx context.Context, method, path string, result interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	// The host is ignored; requests go to the socket
+	req, err := http.NewRequestWithContext(ctx, method, "http://agent"+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ECONNREFUSED) {
+			return ErrNotListening
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrUnsupported
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return fmt.Errorf("the agent answered HTTP %d", resp.StatusCode)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("invalid response from the agent: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Package agentops implements the steps of installing and upgrading the
+// agent together with their safeguards: read-only mode, yanked releases,
+// binaries owned by distribution packages, the permission policy and the
+// rollback of failed installations. The CLI commands and the pkg/fixpanic
+// library both build on it, so a program embedding the library is held to
+// the same checks as an operator running the CLI.
+package agentops
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/fixpanic/fixpanic-cli/internal/fsperm"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+)
+
+// EnvReadOnly turns read-only mode on when set to a true value, overriding
+// cli.read_only
+const EnvReadOnly = "FIXPANIC_READ_ONLY"
+
+// Settings are the safeguards configured in the CLI config file
+// (~/.fixpanic.yaml) and the matching FIXPANIC_* environment variables
+type Settings struct {
+	// ReadOnly is cli.read_only: mutating operations are refused
+	ReadOnly bool
+	// DirMode, FileMode, Owner and Group are the permission policy of the
+	// installed trees (permissions.*); empty values keep the defaults
+	DirMode  string
+	FileMode string
+	Owner    string
+	Group    string
+}
+
+// IsReadOnly reports whether mutating operations are disabled, either
+// through FIXPANIC_READ_ONLY or cli.read_only
+func (s Settings) IsReadOnly() bool {
+	if value := os.Getenv(EnvReadOnly); value != "" {
+		readOnly, err := strconv.ParseBool(value)
+		return err != nil || readOnly // unparseable values fail closed
+	}
+	return s.ReadOnly
+}
+
+// PermissionPolicy returns the permission policy of the installed trees
+func (s Settings) PermissionPolicy() (*fsperm.Policy, error) {
+	return fsperm.NewPolicy(s.DirMode, s.FileMode, s.Owner, s.Group)
+}
+
+// ManagedDirs returns the directory trees the permission policy applies to
+func ManagedDirs(platformInfo *platform.PlatformInfo) []string {
+	return []string{platformInfo.LibDir, platformInfo.ConfigDir, platformInfo.LogDir}
+}
@@ -0,0 +1,86 @@
+package agentops
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/panicstop"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+)
+
+func TestSettingsIsReadOnly(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured bool
+		env        string
+		want       bool
+	}{
+		{name: "default", want: false},
+		{name: "configured", configured: true, want: true},
+		{name: "environment", env: "true", want: true},
+		{name: "environment overrides config", configured: true, env: "0", want: false},
+		{name: "unparseable fails closed", env: "maybe", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvReadOnly, tt.env)
+			settings := Settings{ReadOnly: tt.configured}
+			if got := settings.IsReadOnly(); got != tt.want {
+				t.Errorf("IsReadOnly() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettingsPermissionPolicy(t *testing.T) {
+	if _, err := (Settings{DirMode: "0750", FileMode: "0640"}).PermissionPolicy(); err != nil {
+		t.Fatalf("PermissionPolicy() error = %v", err)
+	}
+	if _, err := (Settings{DirMode: "rwx"}).PermissionPolicy(); err == nil {
+		t.Fatal("PermissionPolicy() accepted an invalid mode")
+	}
+}
+
+func TestCheckPanicStop(t *testing.T) {
+	platformInfo := &platform.PlatformInfo{ConfigDir: t.TempDir()}
+	if err := CheckPanicStop(platformInfo); err != nil {
+		t.Fatalf("CheckPanicStop() without a marker error = %v", err)
+	}
+
+	stoppedAt := time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC)
+	marker := panicstop.Marker{StoppedAt: stoppedAt, User: "alice", Reason: "INC-1234"}
+	if err := panicstop.Engage(platformInfo.GetPanicStopPath(), marker); err != nil {
+		t.Fatal(err)
+	}
+	err := CheckPanicStop(platformInfo)
+	if !errors.Is(err, ErrPanicStopped) {
+		t.Fatalf("CheckPanicStop() error = %v, want ErrPanicStopped", err)
+	}
+	if want := "by alice: INC-1234"; !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("CheckPanicStop() error = %q, want it to end with %q", err, want)
+	}
+}
+
+func TestDescribePanicStop(t *testing.T) {
+	stoppedAt := time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC)
+	since := " since " + stoppedAt.Local().Format(time.RFC1123)
+	tests := []struct {
+		name   string
+		marker panicstop.Marker
+		want   string
+	}{
+		{"damaged marker", panicstop.Marker{}, ""},
+		{"time only", panicstop.Marker{StoppedAt: stoppedAt}, since},
+		{"user", panicstop.Marker{StoppedAt: stoppedAt, User: "alice"}, since + " by alice"},
+		{"reason", panicstop.Marker{StoppedAt: stoppedAt, Reason: "INC-1234"}, since + ": INC-1234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DescribePanicStop(&tt.marker); got != tt.want {
+				t.Errorf("DescribePanicStop() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
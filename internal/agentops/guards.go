@@ -0,0 +1,102 @@
+package agentops
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/fsperm"
+	"github.com/fixpanic/fixpanic-cli/internal/httpcache"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/releases"
+	"github.com/fixpanic/fixpanic-cli/internal/state"
+)
+
+// CheckNotYanked refuses the upgrade to version of repo if that version was
+// yanked, unless allow is set. If the check itself fails the upgrade goes
+// ahead with a warning, so an unreachable blocklist doesn't block upgrades.
+func CheckNotYanked(ctx context.Context, repo, version string, allow bool) error {
+	reason, yanked, err := releases.CheckYanked(ctx, httpcache.Default(), repo, version)
+	if !yanked {
+		if err != nil {
+			logger.Warning("Could not check whether %s was yanked: %v", version, err)
+		}
+		return nil
+	}
+
+	if reason == "" {
+		reason = i18n.T("no reason given")
+	}
+	if allow {
+		logger.Warning("%s was yanked (%s); installing it anyway because of --allow-yanked", version, reason)
+		return nil
+	}
+	return clierror.New(clierror.General, "%s was yanked: %s", version, reason).
+		WithHint("Wait for a fixed release, or pass --allow-yanked to install this one anyway")
+}
+
+// CheckPackageOwner refuses to replace an agent binary that belongs to a
+// distribution package, unless takeover is set now or was set before for the
+// same package
+func CheckPackageOwner(ctx context.Context, platformInfo *platform.PlatformInfo, takeover bool) error {
+	binaryPath := platformInfo.GetFixPanicAgentBinaryPath()
+	owner := platform.FilePackageOwner(ctx, binaryPath)
+	if owner == nil {
+		return nil
+	}
+	store := state.NewStore(platformInfo.StateDir)
+	if saved, err := store.Load(); err == nil {
+		if previous := saved.PackageTakeover; previous != nil && previous.Manager == owner.Manager && previous.Package == owner.Package {
+			logger.Info("The agent binary belongs to the %s package %s; the CLI took it over on %s", owner.Manager, owner.Package, previous.Time.Local().Format("2006-01-02"))
+			return nil
+		}
+	}
+	if !takeover {
+		return clierror.New(clierror.Config, "%s belongs to the %s package %s; the package manager upgrades it", binaryPath, owner.Manager, owner.Package).
+			WithHint(i18n.Sprintf("Upgrade the package instead: %s", owner.UpgradeCommand()),
+				"Run 'fixpanic agent upgrade --takeover' to have the CLI upgrade the agent from now on")
+	}
+
+	logger.Warning("Taking over the agent binary from the %s package %s", owner.Manager, owner.Package)
+	logger.Info("Hold the package so the package manager doesn't replace the binary again:")
+	logger.Command(owner.HoldCommand())
+	err := store.Update(ctx, func(s *state.State) error {
+		s.PackageTakeover = &state.PackageTakeover{
+			Time:    time.Now().UTC(),
+			Manager: owner.Manager,
+			Package: owner.Package,
+			User:    state.Initiator(),
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Warning("Failed to record the takeover: %v", err)
+	}
+	return nil
+}
+
+// ApplyPermissions enforces policy on the installation
+func ApplyPermissions(platformInfo *platform.PlatformInfo, policy *fsperm.Policy) error {
+	for _, dir := range ManagedDirs(platformInfo) {
+		if err := policy.Apply(dir); err != nil {
+			return fmt.Errorf("failed to apply permissions: %w", err)
+		}
+	}
+	return nil
+}
+
+// CheckPermissions returns the files of the installation that violate policy
+func CheckPermissions(platformInfo *platform.PlatformInfo, policy *fsperm.Policy) ([]fsperm.Violation, error) {
+	var violations []fsperm.Violation
+	for _, dir := range ManagedDirs(platformInfo) {
+		found, err := policy.Check(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check permissions: %w", err)
+		}
+		violations = append(violations, found...)
+	}
+	return violations, nil
+}
@@ -0,0 +1,142 @@
+package agentops
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/plan"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/rollback"
+	"github.com/fixpanic/fixpanic-cli/internal/service"
+	"github.com/fixpanic/fixpanic-cli/internal/state"
+)
+
+// RenderConfig builds the configuration an installation with inputs writes
+func RenderConfig(platformInfo *platform.PlatformInfo, inputs plan.Inputs, apiKey string) (*config.AgentConfig, error) {
+	agentConfig, err := config.InstallConfig(inputs.Profile, inputs.AgentID, apiKey, inputs.SocketServer, platformInfo.GetLogPath(), platformInfo.GetConfigPath())
+	if err != nil {
+		return nil, err
+	}
+	if inputs.TLSCAFile != "" {
+		agentConfig.App.TLSCAFile = inputs.TLSCAFile
+	}
+	if inputs.TLSCertFile != "" {
+		agentConfig.App.TLSCertFile = inputs.TLSCertFile
+		agentConfig.App.TLSKeyFile = inputs.TLSKeyFile
+	}
+	if inputs.Confine {
+		agentConfig.Service.Confine = true
+	}
+	if inputs.Cloud != nil {
+		agentConfig.App.Cloud = inputs.Cloud
+	}
+	return agentConfig, nil
+}
+
+// CreateDirectories creates the directories of the installation, recording
+// the ones it creates in journal
+func CreateDirectories(journal *rollback.Journal, platformInfo *platform.PlatformInfo) error {
+	for _, dir := range ManagedDirs(platformInfo) {
+		journal.TrackDir(dir)
+	}
+	if err := platformInfo.CreateDirectories(); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+	return nil
+}
+
+// TrackService records the agent service as it is before an installation
+// replaces it: rollback restores the previous unit and, if the previous agent
+// was running, starts it again once its files are restored
+func TrackService(ctx context.Context, journal *rollback.Journal, platformInfo *platform.PlatformInfo) error {
+	// Rollback also runs after a failed or cancelled start
+	ctx = context.WithoutCancel(ctx)
+	serviceManager := service.NewManager(platformInfo)
+
+	unitPath := platformInfo.GetServiceFilePath()
+	if _, err := os.Stat(unitPath); err == nil {
+		status, _ := serviceManager.Status(ctx)
+		journal.Undo(func() error {
+			if err := serviceManager.Reload(ctx); err != nil || status != "active" {
+				return err
+			}
+			return serviceManager.Start(ctx)
+		})
+	}
+	return journal.TrackFile(unitPath)
+}
+
+// InstallBinary installs the agent release version, verified against its
+// checksum manifest, or the latest release if version is empty. It
+// returns the SHA-256 of a pinned release's binary.
+func InstallBinary(ctx context.Context, connectivityManager *connectivity.Manager, version string) (string, error) {
+	if version == "" {
+		return "", connectivityManager.EnsureLatestAgent(ctx)
+	}
+	checksum, err := connectivityManager.InstallFixPanicAgentVersion(ctx, version)
+	if err != nil {
+		return "", fmt.Errorf("failed to install agent %s: %w", version, err)
+	}
+	return checksum, nil
+}
+
+// PersistMigration writes the migration of an older config file at
+// configPath to the current version, backing up the original. It returns
+// nil if there is no config file yet.
+func PersistMigration(configPath string) (*config.MigrationResult, error) {
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return config.Migrate(configPath, false)
+}
+
+// RecordInstall adds an install record and the installed agent version to
+// the state
+func RecordInstall(ctx context.Context, platformInfo *platform.PlatformInfo, cliVersion, profile string) error {
+	record := state.InstallRecord{
+		Time:       time.Now().UTC(),
+		CLIVersion: cliVersion,
+		Profile:    profile,
+		User:       state.Initiator(),
+	}
+	if output, err := connectivity.NewManager(platformInfo).GetFixPanicAgentVersion(ctx); err == nil {
+		record.AgentVersion = connectivity.ParseAgentVersion(output)
+	}
+
+	return state.NewStore(platformInfo.StateDir).Update(ctx, func(s *state.State) error {
+		s.Installs = append(s.Installs, record)
+		s.AddVersionChange(state.VersionChange{
+			Time:      record.Time,
+			Component: state.ComponentAgent,
+			To:        record.AgentVersion,
+			Initiator: record.User,
+			Result:    state.ResultSuccess,
+		})
+		return nil
+	})
+}
+
+// RecordVersionChange adds a change of component from one version to another
+// to the history; changeErr is the error that made it fail, if any
+func RecordVersionChange(ctx context.Context, platformInfo *platform.PlatformInfo, component, from, to string, changeErr error) error {
+	change := state.VersionChange{
+		Time:      time.Now().UTC(),
+		Component: component,
+		From:      from,
+		To:        to,
+		Initiator: state.Initiator(),
+		Result:    state.ResultSuccess,
+	}
+	if changeErr != nil {
+		change.Result = state.ResultFailure
+		change.Error = changeErr.Error()
+	}
+	return state.NewStore(platformInfo.StateDir).Update(ctx, func(s *state.State) error {
+		s.AddVersionChange(change)
+		return nil
+	})
+}
@@ -0,0 +1,46 @@
+package agentops
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/panicstop"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+)
+
+// HintPanicReset tells how to lift an emergency stop
+const HintPanicReset = "Run 'fixpanic agent panic-reset' once the incident is resolved"
+
+// ErrPanicStopped is returned by CheckPanicStop while the emergency stop is
+// engaged
+var ErrPanicStopped = errors.New("the emergency stop is engaged")
+
+// CheckPanicStop fails with ErrPanicStopped while the emergency stop is
+// engaged, so the agent isn't installed again or started before
+// 'fixpanic agent panic-reset'
+func CheckPanicStop(platformInfo *platform.PlatformInfo) error {
+	marker, err := panicstop.Read(platformInfo.GetPanicStopPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return clierror.New(clierror.General, "%w%s", ErrPanicStopped, DescribePanicStop(marker)).
+		WithHint(HintPanicReset)
+}
+
+// DescribePanicStop returns when, by whom and why the emergency stop was
+// engaged, to follow "the emergency stop is engaged"
+func DescribePanicStop(marker *panicstop.Marker) string {
+	if marker.StoppedAt.IsZero() {
+		return ""
+	}
+	description := " since " + marker.StoppedAt.Local().Format(time.RFC1123)
+	if marker.User != "" {
+		description += " by " + marker.User
+	}
+	if marker.Reason != "" {
+		description += ": " + marker.Reason
+	}
+	return description
+}
@@ -0,0 +1,126 @@
+// Package artifacts describes bundles of verified agent builds fetched on an
+// internet-connected machine and copied to air-gapped hosts.
+//
+// A bundle is a directory with the agent binaries and a manifest.json that
+// records the release and the SHA-256 checksum of every file.
+package artifacts
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ManifestName is the name of the manifest in a bundle directory
+const ManifestName = "manifest.json"
+
+// FormatVersion is the manifest format written by this CLI
+const FormatVersion = 1
+
+// Manifest lists the artifacts of a bundle
+type Manifest struct {
+	FormatVersion int       `json:"format_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	CLIVersion    string    `json:"cli_version"`
+	// Version is the agent release the artifacts belong to
+	Version   string     `json:"version"`
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+// Artifact is a file in a bundle
+type Artifact struct {
+	Name string `json:"name"`
+	// Platform is the GOOS/GOARCH the artifact was pulled for
+	Platform string `json:"platform"`
+	URL      string `json:"url"`
+	SHA256   string `json:"sha256"`
+	Size     int64  `json:"size"`
+}
+
+// Find returns the artifact called name, or nil
+func (m *Manifest) Find(name string) *Artifact {
+	for i := range m.Artifacts {
+		if m.Artifacts[i].Name == name {
+			return &m.Artifacts[i]
+		}
+	}
+	return nil
+}
+
+// Add records a, replacing an artifact with the same name
+func (m *Manifest) Add(a Artifact) {
+	if existing := m.Find(a.Name); existing != nil {
+		*existing = a
+		return
+	}
+	m.Artifacts = append(m.Artifacts, a)
+}
+
+// Save writes the manifest into the bundle directory dir
+func (m *Manifest) Save(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	path := filepath.Join(dir, ManifestName)
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// Load reads and validates the manifest of the bundle directory dir
+func Load(dir string) (*Manifest, error) {
+	path := filepath.Join(dir, ManifestName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if m.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("manifest %s has format version %d, this CLI supports %d", path, m.FormatVersion, FormatVersion)
+	}
+	for _, a := range m.Artifacts {
+		// Names become paths; refuse anything that could escape the bundle
+		if a.Name == "" || a.Name != filepath.Base(a.Name) || strings.HasPrefix(a.Name, ".") {
+			return nil, fmt.Errorf("manifest %s has an invalid artifact name %q", path, a.Name)
+		}
+		if a.SHA256 == "" {
+			return nil, fmt.Errorf("manifest %s has no checksum for %s", path, a.Name)
+		}
+	}
+	return &m, nil
+}
+
+// Verify checks that the file of a in the bundle directory dir has the
+// recorded size and checksum
+func Verify(dir string, a *Artifact) error {
+	path := filepath.Join(dir, a.Name)
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open artifact: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if size != a.Size {
+		return fmt.Errorf("%s is %d bytes, the manifest records %d", path, size, a.Size)
+	}
+	if actual := fmt.Sprintf("%x", hash.Sum(nil)); !strings.EqualFold(actual, a.SHA256) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", path, a.SHA256, actual)
+	}
+	return nil
+}
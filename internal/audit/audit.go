@@ -0,0 +1,132 @@
+// Package audit records mutating CLI actions to an append-only JSON lines file
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileName is the name of the audit log inside the log directory
+const FileName = "audit.log"
+
+// Result values
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Redacted replaces secret values in recorded arguments
+const Redacted = "[REDACTED]"
+
+// Entry is a single audited action
+type Entry struct {
+	Time       time.Time `json:"time"`
+	User       string    `json:"user"`
+	UID        string    `json:"uid,omitempty"`
+	SudoUser   string    `json:"sudo_user,omitempty"`
+	Hostname   string    `json:"hostname,omitempty"`
+	PID        int       `json:"pid"`
+	Command    string    `json:"command"`
+	Args       []string  `json:"args,omitempty"`
+	Result     string    `json:"result"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	CLIVersion string    `json:"cli_version,omitempty"`
+}
+
+// Append writes an entry to the audit log, creating it if needed. The file is
+// only ever opened in append mode.
+func Append(path string, entry Entry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// Read returns all entries recorded at or after since. Malformed lines are skipped.
+func Read(path string, since time.Time) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if entry.Time.Before(since) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// IsSecretName reports whether a flag or key name likely holds a secret
+func IsSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, marker := range []string{"key", "token", "secret", "password", "passwd", "credential"} {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSince parses a relative age such as "30m", "24h" or "7d" (days are
+// not supported by time.ParseDuration) and returns the corresponding instant
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil || days < 0 {
+			return time.Time{}, fmt.Errorf("invalid duration %q", value)
+		}
+		return now.AddDate(0, 0, -days), nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid duration %q: %w", value, err)
+	}
+
+	return now.Add(-duration), nil
+}
@@ -0,0 +1,88 @@
+// Package cleanup finds and removes temporary files that interrupted CLI runs
+// leave behind, such as fixpanic-upgrade-* directories and partial downloads.
+package cleanup
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultMaxAge is how old a leftover must be before it is considered stale;
+// younger files may belong to an operation that is still running
+const DefaultMaxAge = 24 * time.Hour
+
+// Pattern describes a kind of leftover
+type Pattern struct {
+	// Glob is matched with filepath.Glob
+	Glob string
+	// Dir selects directories instead of regular files
+	Dir bool
+}
+
+// Leftover is a stale temporary file or directory
+type Leftover struct {
+	Path    string
+	Dir     bool
+	Size    int64
+	ModTime time.Time
+}
+
+// Find returns the leftovers matching patterns that were last modified more
+// than maxAge before now
+func Find(patterns []Pattern, maxAge time.Duration, now time.Time) ([]Leftover, error) {
+	cutoff := now.Add(-maxAge)
+
+	var leftovers []Leftover
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern.Glob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern.Glob, err)
+		}
+
+		for _, path := range matches {
+			info, err := os.Lstat(path)
+			if err != nil || info.IsDir() != pattern.Dir || !info.ModTime().Before(cutoff) {
+				continue
+			}
+			if !pattern.Dir && !info.Mode().IsRegular() {
+				continue
+			}
+
+			leftover := Leftover{Path: path, Dir: info.IsDir(), Size: info.Size(), ModTime: info.ModTime()}
+			if leftover.Dir {
+				leftover.Size = dirSize(path)
+			}
+			leftovers = append(leftovers, leftover)
+		}
+	}
+
+	return leftovers, nil
+}
+
+// Remove deletes a leftover
+func Remove(leftover Leftover) error {
+	if leftover.Dir {
+		return os.RemoveAll(leftover.Path)
+	}
+	return os.Remove(leftover.Path)
+}
+
+// dirSize returns the total size of the regular files below dir
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
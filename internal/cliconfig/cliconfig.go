@@ -0,0 +1,354 @@
+// Package cliconfig keeps the preferences of the CLI itself, such as the
+// release channel 'fixpanic upgrade' follows or the default output format,
+// in a YAML file of the user's, apart from the agent configuration
+package cliconfig
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/lock"
+	"golang.org/x/net/http/httpproxy"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the CLI configuration file inside the user's
+// configuration directory
+const FileName = "cli.yaml"
+
+// EnvPath overrides where the CLI configuration file is kept
+const EnvPath = "FIXPANIC_CLI_CONFIG"
+
+// DefaultLockWait is how long Update waits for another process updating the
+// configuration
+const DefaultLockWait = 10 * time.Second
+
+// Release channels 'fixpanic upgrade' follows
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+)
+
+// Color modes
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// Output formats of commands that can write JSON
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// Keys lists the settings of the CLI configuration
+var Keys = []string{"update_channel", "color", "output", "proxy", "update_notifier", "telemetry"}
+
+// Config is the CLI configuration. Unset values mean the default.
+type Config struct {
+	// UpdateChannel is the release channel 'fixpanic upgrade' follows:
+	// stable releases, or beta for pre-releases as well
+	UpdateChannel string `yaml:"update_channel,omitempty"`
+	// Color is auto to follow the environment (NO_COLOR, CLICOLOR), always
+	// or never
+	Color string `yaml:"color,omitempty"`
+	// Output is the format of commands that can write JSON when --json isn't
+	// given: text or json
+	Output string `yaml:"output,omitempty"`
+	// Proxy is the HTTP proxy the CLI's own downloads go through unless
+	// HTTPS_PROXY or HTTP_PROXY are set. It applies to the CLI's HTTP
+	// transports only, never to the processes the CLI starts; the agent's
+	// proxy is configured separately in its service environment.
+	Proxy string `yaml:"proxy,omitempty"`
+	// UpdateNotifier turns notices about newer agent releases on or off
+	UpdateNotifier *bool `yaml:"update_notifier,omitempty"`
+	// Telemetry is the user's decision on anonymous usage telemetry, which
+	// is off until it is turned on
+	Telemetry *bool `yaml:"telemetry,omitempty"`
+	// TelemetryInstallID is the random ID telemetry events are grouped by,
+	// kept while telemetry is on
+	TelemetryInstallID string `yaml:"telemetry_install_id,omitempty"`
+	// TelemetryChanged is when the telemetry decision was made
+	TelemetryChanged time.Time `yaml:"telemetry_changed,omitempty"`
+}
+
+// Channel returns the release channel, stable by default
+func (c *Config) Channel() string {
+	if c.UpdateChannel == "" {
+		return ChannelStable
+	}
+	return c.UpdateChannel
+}
+
+// ColorMode returns the color mode, auto by default
+func (c *Config) ColorMode() string {
+	if c.Color == "" {
+		return ColorAuto
+	}
+	return c.Color
+}
+
+// OutputFormat returns the default output format, text by default
+func (c *Config) OutputFormat() string {
+	if c.Output == "" {
+		return OutputText
+	}
+	return c.Output
+}
+
+// NotifyUpdates reports whether update notices are shown, which they are by
+// default
+func (c *Config) NotifyUpdates() bool {
+	return c.UpdateNotifier == nil || *c.UpdateNotifier
+}
+
+// TelemetryEnabled reports whether the user turned telemetry on
+func (c *Config) TelemetryEnabled() bool {
+	return c.Telemetry != nil && *c.Telemetry
+}
+
+// ProxyFunc returns the proxy selection for the CLI's HTTP transports:
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY when the environment sets a proxy,
+// otherwise the configured proxy for every host NO_PROXY doesn't exclude.
+// The environment itself is left alone, so the proxy doesn't leak into the
+// processes the CLI starts.
+func (c *Config) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	environment := httpproxy.FromEnvironment()
+	if c.Proxy == "" || environment.HTTPProxy != "" || environment.HTTPSProxy != "" {
+		return http.ProxyFromEnvironment
+	}
+	environment.HTTPProxy = c.Proxy
+	environment.HTTPSProxy = c.Proxy
+	proxy := environment.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
+
+// Get returns the effective value of key
+func (c *Config) Get(key string) (string, error) {
+	switch key {
+	case "update_channel":
+		return c.Channel(), nil
+	case "color":
+		return c.ColorMode(), nil
+	case "output":
+		return c.OutputFormat(), nil
+	case "proxy":
+		return c.Proxy, nil
+	case "update_notifier":
+		return strconv.FormatBool(c.NotifyUpdates()), nil
+	case "telemetry":
+		return strconv.FormatBool(c.TelemetryEnabled()), nil
+	}
+	return "", fmt.Errorf("unknown key %q", key)
+}
+
+// IsSet reports whether key has a value of its own rather than the default
+func (c *Config) IsSet(key string) bool {
+	switch key {
+	case "update_channel":
+		return c.UpdateChannel != ""
+	case "color":
+		return c.Color != ""
+	case "output":
+		return c.Output != ""
+	case "proxy":
+		return c.Proxy != ""
+	case "update_notifier":
+		return c.UpdateNotifier != nil
+	case "telemetry":
+		return c.Telemetry != nil
+	}
+	return false
+}
+
+// Set checks value and assigns it to key. An empty value resets key to its
+// default.
+func (c *Config) Set(key, value string) error {
+	switch key {
+	case "update_channel":
+		if err := oneOf(key, value, ChannelStable, ChannelBeta); err != nil {
+			return err
+		}
+		c.UpdateChannel = value
+	case "color":
+		if err := oneOf(key, value, ColorAuto, ColorAlways, ColorNever); err != nil {
+			return err
+		}
+		c.Color = value
+	case "output":
+		if err := oneOf(key, value, OutputText, OutputJSON); err != nil {
+			return err
+		}
+		c.Output = value
+	case "proxy":
+		if value != "" {
+			parsed, err := url.Parse(value)
+			if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https" && parsed.Scheme != "socks5") {
+				return fmt.Errorf("proxy must be a URL like http://proxy.internal:3128, got %q", value)
+			}
+		}
+		c.Proxy = value
+	case "update_notifier":
+		enabled, err := optionalBool(key, value)
+		if err != nil {
+			return err
+		}
+		c.UpdateNotifier = enabled
+	case "telemetry":
+		enabled, err := optionalBool(key, value)
+		if err != nil {
+			return err
+		}
+		c.Telemetry = enabled
+		if enabled == nil || !*enabled {
+			c.TelemetryInstallID = ""
+		}
+	default:
+		return fmt.Errorf("unknown key %q", key)
+	}
+	return nil
+}
+
+// Validate checks the values of a loaded configuration
+func (c *Config) Validate() error {
+	for _, key := range Keys {
+		value, _ := c.Get(key)
+		if err := (&Config{}).Set(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// oneOf checks that value is empty or one of allowed
+func oneOf(key, value string, allowed ...string) error {
+	if value == "" {
+		return nil
+	}
+	for _, candidate := range allowed {
+		if value == candidate {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s must be one of %s, got %q", key, strings.Join(allowed, ", "), value)
+}
+
+// optionalBool parses value as a boolean, or nil if it is empty
+func optionalBool(key, value string) (*bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be true or false, got %q", key, value)
+	}
+	return &parsed, nil
+}
+
+// Store reads and updates the CLI configuration file
+type Store struct {
+	Path string
+	// LockWait is how long Update waits for another process updating the
+	// configuration
+	LockWait time.Duration
+}
+
+// DefaultPath returns $FIXPANIC_CLI_CONFIG, or cli.yaml in the fixpanic
+// directory of the user's configuration directory
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, "fixpanic", FileName), nil
+}
+
+// NewStore returns a store for the configuration file at path
+func NewStore(path string) *Store {
+	return &Store{Path: path, LockWait: DefaultLockWait}
+}
+
+// Load returns the saved configuration, or an empty one if none was saved
+// yet. Updates replace the file atomically, so loading needs no lock.
+func (s *Store) Load() (*Config, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read CLI configuration: %w", err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse CLI configuration %s: %w", s.Path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid CLI configuration %s: %w", s.Path, err)
+	}
+	return &config, nil
+}
+
+// Update applies fn to the saved configuration and saves the result,
+// holding a lock so concurrent updates from other processes are not lost.
+// Nothing is saved if fn returns an error.
+func (s *Store) Update(ctx context.Context, fn func(*Config) error) error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	owner := fmt.Sprintf("PID %d: fixpanic CLI configuration update", os.Getpid())
+	held, err := lock.Acquire(ctx, s.Path+".lock", s.LockWait, owner)
+	if err != nil {
+		return fmt.Errorf("failed to lock CLI configuration: %w", err)
+	}
+	defer held.Release()
+
+	config, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(config); err != nil {
+		return err
+	}
+	return s.save(config)
+}
+
+// save writes config to a temporary file and renames it over the
+// configuration file
+func (s *Store) save(config *Config) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to encode CLI configuration: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), FileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write CLI configuration: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write CLI configuration: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write CLI configuration: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return fmt.Errorf("failed to save CLI configuration: %w", err)
+	}
+	return nil
+}
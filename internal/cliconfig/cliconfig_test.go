@@ -0,0 +1,57 @@
+package cliconfig
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestProxyFuncUsesConfiguredProxy(t *testing.T) {
+	for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy", "REQUEST_METHOD"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("NO_PROXY", "internal.example")
+
+	proxy := (&Config{Proxy: "http://proxy.example:3128"}).ProxyFunc()
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"https://github.com/fixpanic/releases", "http://proxy.example:3128"},
+		{"http://downloads.example/agent", "http://proxy.example:3128"},
+		{"https://mirror.internal.example/agent", ""},
+	}
+	for _, tt := range tests {
+		target, _ := url.Parse(tt.target)
+		got, err := proxy(&http.Request{URL: target})
+		if err != nil {
+			t.Fatalf("proxy(%s) failed: %v", tt.target, err)
+		}
+		gotURL := ""
+		if got != nil {
+			gotURL = got.String()
+		}
+		if gotURL != tt.want {
+			t.Errorf("proxy(%s) = %q, want %q", tt.target, gotURL, tt.want)
+		}
+	}
+}
+
+func TestSetTelemetry(t *testing.T) {
+	config := &Config{TelemetryInstallID: "abc"}
+	if err := config.Set("telemetry", "true"); err != nil {
+		t.Fatal(err)
+	}
+	if !config.TelemetryEnabled() || config.TelemetryInstallID != "abc" {
+		t.Errorf("enabling telemetry: got enabled=%v install ID %q", config.TelemetryEnabled(), config.TelemetryInstallID)
+	}
+	if err := config.Set("telemetry", "false"); err != nil {
+		t.Fatal(err)
+	}
+	if config.TelemetryEnabled() || config.TelemetryInstallID != "" {
+		t.Errorf("disabling telemetry kept enabled=%v install ID %q", config.TelemetryEnabled(), config.TelemetryInstallID)
+	}
+	if err := config.Set("telemetry", "maybe"); err == nil {
+		t.Error("Set accepted telemetry=maybe")
+	}
+}
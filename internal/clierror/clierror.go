@@ -0,0 +1,240 @@
+// Package clierror defines the CLI's exit code scheme so wrapper scripts can
+// branch on the kind of failure instead of parsing error messages.
+//
+// Exit codes:
+//
+//	0  success
+//	1  general error
+//	2  usage error (invalid flags or arguments)
+//	3  configuration error
+//	4  network error
+//	5  permission error
+//	6  agent already installed
+//	7  agent not installed
+//	8  refused by read-only mode
+//	9  another fixpanic operation is in progress
+//	10 timed out (--timeout)
+//	11 release failed verification (checksum manifest missing, unsigned or
+//	   not matching)
+//	130 interrupted (Ctrl+C or SIGTERM)
+package clierror
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+)
+
+// Code is a process exit code
+type Code int
+
+// Exit codes; values are part of the CLI's public contract and must not change
+const (
+	OK               Code = 0
+	General          Code = 1
+	Usage            Code = 2
+	Config           Code = 3
+	Network          Code = 4
+	Permission       Code = 5
+	AlreadyInstalled Code = 6
+	NotInstalled     Code = 7
+	ReadOnly         Code = 8
+	Busy             Code = 9
+	Timeout          Code = 10
+	Integrity        Code = 11
+	Interrupted      Code = 130 // 128 + SIGINT, as shells report it
+)
+
+// String returns a short machine-friendly name for the code
+func (c Code) String() string {
+	switch c {
+	case OK:
+		return "ok"
+	case General:
+		return "general"
+	case Usage:
+		return "usage"
+	case Config:
+		return "config"
+	case Network:
+		return "network"
+	case Permission:
+		return "permission"
+	case AlreadyInstalled:
+		return "already-installed"
+	case NotInstalled:
+		return "not-installed"
+	case ReadOnly:
+		return "read-only"
+	case Busy:
+		return "busy"
+	case Timeout:
+		return "timeout"
+	case Integrity:
+		return "integrity"
+	case Interrupted:
+		return "interrupted"
+	default:
+		return fmt.Sprintf("code-%d", int(c))
+	}
+}
+
+// Codes lists every code with a description, for documentation output
+var Codes = []struct {
+	Code        Code
+	Description string
+}{
+	{OK, "Success"},
+	{General, "General error"},
+	{Usage, "Invalid flags or arguments"},
+	{Config, "Configuration missing or invalid"},
+	{Network, "Network or download failure"},
+	{Permission, "Insufficient permissions (try sudo)"},
+	{AlreadyInstalled, "Agent is already installed"},
+	{NotInstalled, "Agent is not installed"},
+	{ReadOnly, "Command refused by read-only mode"},
+	{Busy, "Another fixpanic operation is in progress"},
+	{Timeout, "Command exceeded --timeout"},
+	{Integrity, "Release failed verification against its signed checksum manifest"},
+	{Interrupted, "Interrupted by Ctrl+C or SIGTERM"},
+}
+
+// Error is an error classified with an exit code and optional remediation
+// hints telling the user what to do about it
+type Error struct {
+	Code  Code
+	Err   error
+	Hints []string
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithHint appends remediation hints to the error
+func (e *Error) WithHint(hints ...string) *Error {
+	e.Hints = append(e.Hints, hints...)
+	return e
+}
+
+// New creates a classified error from a format string, translated to the
+// current output language
+func New(code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Err: fmt.Errorf(i18n.T(format), args...)}
+}
+
+// WithHint attaches remediation hints to err, keeping its exit code.
+// A nil error stays nil.
+func WithHint(err error, hints ...string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: CodeOf(err), Err: err, Hints: hints}
+}
+
+// Wrap classifies an existing error. A nil error stays nil.
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Classify classifies err with code unless a layer of it is classified
+// already, so e.g. a failed verification below a download isn't reported as a
+// network error. A nil error stays nil.
+func Classify(code Code, err error) error {
+	var classified *Error
+	if errors.As(err, &classified) {
+		return err
+	}
+	return Wrap(code, err)
+}
+
+// CodeOf returns the exit code for err. Explicitly classified errors win;
+// otherwise permission and network errors are recognised from the chain.
+func CodeOf(err error) Code {
+	if err == nil {
+		return OK
+	}
+
+	var classified *Error
+	if errors.As(err, &classified) {
+		return classified.Code
+	}
+
+	if errors.Is(err, fs.ErrPermission) {
+		return Permission
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return Network
+	}
+
+	return General
+}
+
+// ExitCode returns the process exit code for err
+func ExitCode(err error) int {
+	return int(CodeOf(err))
+}
+
+// defaultHints apply when no layer of the error chain carries its own hints
+var defaultHints = map[Code][]string{
+	Usage:      {"Run the command with --help to see its usage"},
+	Network:    {"Check your internet connection and any proxy or firewall settings", "Run 'fixpanic agent test-connection' to diagnose connectivity"},
+	Permission: {"Re-run the command with sudo"},
+	ReadOnly:   {"Unset FIXPANIC_READ_ONLY or remove cli.read_only from ~/.fixpanic.yaml if this host should be modified"},
+	Integrity:  {"Don't install the release by other means: a missing or mismatching manifest can mean a tampered download", "Report it to security@fixpanic.com if it persists"},
+}
+
+// Hints returns the remediation hints for err, outermost first. Falls back to
+// generic hints for the error's exit code.
+func Hints(err error) []string {
+	var hints []string
+	seen := make(map[string]bool)
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		classified, ok := e.(*Error)
+		if !ok {
+			continue
+		}
+		for _, hint := range classified.Hints {
+			if !seen[hint] {
+				seen[hint] = true
+				hints = append(hints, hint)
+			}
+		}
+	}
+
+	if len(hints) == 0 {
+		hints = defaultHints[CodeOf(err)]
+	}
+	return hints
+}
+
+// Report writes err and its remediation hints in the format shown to users
+func Report(w io.Writer, err error) {
+	fmt.Fprintln(w, i18n.Sprintf("Error: %v", err))
+
+	hints := Hints(err)
+	if len(hints) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, i18n.T("What you can do:"))
+	for _, hint := range hints {
+		fmt.Fprintf(w, "  • %s\n", i18n.T(hint))
+	}
+}
@@ -0,0 +1,66 @@
+package clierror
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"nil", nil, OK},
+		{"plain", errors.New("boom"), General},
+		{"classified", New(Config, "bad config"), Config},
+		{"wrapped classified", fmt.Errorf("install: %w", New(NotInstalled, "missing")), NotInstalled},
+		{"permission", fmt.Errorf("open: %w", fs.ErrPermission), Permission},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("refused")}, Network},
+		{"classified wins", Wrap(Config, fmt.Errorf("read: %w", fs.ErrPermission)), Config},
+		{"hint keeps code", WithHint(New(Busy, "locked"), "wait"), Busy},
+		{"classify keeps code", Classify(Network, fmt.Errorf("download: %w", New(Integrity, "bad checksum"))), Integrity},
+		{"classify unclassified", Classify(Network, errors.New("HTTP 500")), Network},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeOf(tt.err); got != tt.want {
+				t.Errorf("CodeOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHints(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"none", errors.New("boom"), nil},
+		{"default for code", New(Permission, "denied"), defaultHints[Permission]},
+		{"own hints", New(Network, "down").WithHint("check the proxy"), []string{"check the proxy"}},
+		{
+			name: "outermost first without duplicates",
+			err:  WithHint(New(General, "x").WithHint("inner", "shared"), "outer", "shared"),
+			want: []string{"outer", "shared", "inner"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Hints(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Hints() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapNil(t *testing.T) {
+	if Wrap(Usage, nil) != nil || WithHint(nil, "hint") != nil {
+		t.Error("wrapping nil returned an error")
+	}
+}
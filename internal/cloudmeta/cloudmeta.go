@@ -0,0 +1,267 @@
+// Package cloudmeta identifies the cloud instance the CLI runs on through the
+// instance metadata services of AWS, Google Cloud and Azure. The provider is
+// recognised from the DMI data first, so hosts outside these clouds never
+// wait for a metadata service that isn't there.
+package cloudmeta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/trace"
+)
+
+// Providers
+const (
+	ProviderAWS   = "aws"
+	ProviderGCP   = "gcp"
+	ProviderAzure = "azure"
+)
+
+// Timeout bounds the metadata queries; the services answer within
+// milliseconds on the instance itself
+const Timeout = 2 * time.Second
+
+// Metadata service endpoints
+var (
+	awsBaseURL   = "http://169.254.169.254"
+	gcpBaseURL   = "http://metadata.google.internal"
+	azureBaseURL = "http://169.254.169.254"
+)
+
+// maxTags bounds the tags read from a metadata service
+const maxTags = 50
+
+// Instance identifies a cloud instance
+type Instance struct {
+	Provider   string `json:"provider" yaml:"provider"`
+	InstanceID string `json:"instance_id" yaml:"instance_id"`
+	Region     string `json:"region,omitempty" yaml:"region,omitempty"`
+	Zone       string `json:"zone,omitempty" yaml:"zone,omitempty"`
+	// AccountID is the AWS account, Google Cloud project or Azure
+	// subscription the instance belongs to
+	AccountID string `json:"account_id,omitempty" yaml:"account_id,omitempty"`
+	// Tags are the instance tags the metadata service exposes: EC2 tags if
+	// "instance metadata tags" are enabled, Azure tags, and Google Cloud
+	// network tags, which have no values
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// DetectProvider returns the cloud provider named in the machine's DMI data,
+// or an empty string if it isn't one of the supported clouds
+func DetectProvider() string {
+	var identity []string
+	for _, name := range []string{"sys_vendor", "product_name", "bios_vendor", "chassis_asset_tag"} {
+		if data, err := os.ReadFile("/sys/class/dmi/id/" + name); err == nil {
+			identity = append(identity, strings.TrimSpace(string(data)))
+		}
+	}
+	dmi := strings.Join(identity, " ")
+	switch {
+	case strings.Contains(dmi, "Amazon EC2"):
+		return ProviderAWS
+	case strings.Contains(dmi, "Google"):
+		return ProviderGCP
+	// Azure VMs are Hyper-V machines carrying this asset tag
+	case strings.Contains(dmi, "7783-7084-3265-9085-8269-3286-77"):
+		return ProviderAzure
+	}
+	// Older Xen-based EC2 instances
+	if uuid, err := os.ReadFile("/sys/hypervisor/uuid"); err == nil && strings.HasPrefix(strings.ToLower(string(uuid)), "ec2") {
+		return ProviderAWS
+	}
+	return ""
+}
+
+// Detect returns the instance the CLI runs on, or nil without an error when
+// it doesn't run in a supported cloud
+func Detect(ctx context.Context) (*Instance, error) {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	switch DetectProvider() {
+	case ProviderAWS:
+		return detectAWS(ctx)
+	case ProviderGCP:
+		return detectGCP(ctx)
+	case ProviderAzure:
+		return detectAzure(ctx)
+	}
+	return nil, nil
+}
+
+// client talks to the metadata services directly: a proxy can't reach them.
+// Its requests are traced like those of the default transport.
+var client = &http.Client{Transport: trace.Transport(&http.Transport{Proxy: nil})}
+
+// get requests rawURL with headers and returns the body
+func get(ctx context.Context, method, rawURL string, headers map[string]string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: HTTP %d", rawURL, resp.StatusCode)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// awsToken returns an IMDSv2 session token. Instances that require IMDSv2
+// refuse requests without one.
+func awsToken(ctx context.Context) (string, error) {
+	return get(ctx, http.MethodPut, awsBaseURL+"/latest/api/token", map[string]string{
+		"X-aws-ec2-metadata-token-ttl-seconds": "60",
+	})
+}
+
+func detectAWS(ctx context.Context) (*Instance, error) {
+	token, err := awsToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the EC2 metadata service: %w", err)
+	}
+	document, err := get(ctx, http.MethodGet, awsBaseURL+"/latest/dynamic/instance-identity/document", map[string]string{
+		"X-aws-ec2-metadata-token": token,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the EC2 metadata service: %w", err)
+	}
+
+	var identity struct {
+		InstanceID       string `json:"instanceId"`
+		Region           string `json:"region"`
+		AvailabilityZone string `json:"availabilityZone"`
+		AccountID        string `json:"accountId"`
+	}
+	if err := json.Unmarshal([]byte(document), &identity); err != nil {
+		return nil, fmt.Errorf("unexpected EC2 instance identity document: %w", err)
+	}
+	return &Instance{
+		Provider:   ProviderAWS,
+		InstanceID: identity.InstanceID,
+		Region:     identity.Region,
+		Zone:       identity.AvailabilityZone,
+		AccountID:  identity.AccountID,
+		Tags:       awsTags(ctx, token),
+	}, nil
+}
+
+// awsTags returns the instance tags, or nil if they aren't exposed in the
+// instance metadata, which is off by default
+func awsTags(ctx context.Context, token string) map[string]string {
+	headers := map[string]string{"X-aws-ec2-metadata-token": token}
+	base := awsBaseURL + "/latest/meta-data/tags/instance"
+	keys, err := get(ctx, http.MethodGet, base, headers)
+	if err != nil || keys == "" {
+		return nil
+	}
+	tags := map[string]string{}
+	for _, key := range strings.Split(keys, "\n") {
+		if len(tags) == maxTags {
+			break
+		}
+		if key = strings.TrimSpace(key); key == "" {
+			continue
+		}
+		if value, err := get(ctx, http.MethodGet, base+"/"+url.PathEscape(key), headers); err == nil {
+			tags[key] = value
+		}
+	}
+	return tags
+}
+
+func detectGCP(ctx context.Context) (*Instance, error) {
+	headers := map[string]string{"Metadata-Flavor": "Google"}
+	base := gcpBaseURL + "/computeMetadata/v1/"
+	id, err := get(ctx, http.MethodGet, base+"instance/id", headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the Google Cloud metadata server: %w", err)
+	}
+	instance := &Instance{Provider: ProviderGCP, InstanceID: id}
+
+	// The zone comes as projects/<number>/zones/<zone>; the region is the
+	// zone without its last part
+	if zone, err := get(ctx, http.MethodGet, base+"instance/zone", headers); err == nil {
+		instance.Zone = zone[strings.LastIndex(zone, "/")+1:]
+		if i := strings.LastIndex(instance.Zone, "-"); i > 0 {
+			instance.Region = instance.Zone[:i]
+		}
+	}
+	if project, err := get(ctx, http.MethodGet, base+"project/project-id", headers); err == nil {
+		instance.AccountID = project
+	}
+	// Labels aren't served by the metadata server; custom metadata isn't
+	// read as it commonly holds startup scripts and SSH keys
+	if networkTags, err := get(ctx, http.MethodGet, base+"instance/tags", headers); err == nil {
+		var names []string
+		if json.Unmarshal([]byte(networkTags), &names) == nil && len(names) > 0 {
+			instance.Tags = map[string]string{}
+			for _, name := range names {
+				if len(instance.Tags) == maxTags {
+					break
+				}
+				instance.Tags[name] = ""
+			}
+		}
+	}
+	return instance, nil
+}
+
+func detectAzure(ctx context.Context) (*Instance, error) {
+	document, err := get(ctx, http.MethodGet, azureBaseURL+"/metadata/instance/compute?api-version=2021-02-01", map[string]string{
+		"Metadata": "true",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the Azure instance metadata service: %w", err)
+	}
+
+	var compute struct {
+		VMID           string `json:"vmId"`
+		Location       string `json:"location"`
+		Zone           string `json:"zone"`
+		SubscriptionID string `json:"subscriptionId"`
+		TagsList       []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"tagsList"`
+	}
+	if err := json.Unmarshal([]byte(document), &compute); err != nil {
+		return nil, fmt.Errorf("unexpected Azure instance metadata: %w", err)
+	}
+	instance := &Instance{
+		Provider:   ProviderAzure,
+		InstanceID: compute.VMID,
+		Region:     compute.Location,
+		Zone:       compute.Zone,
+		AccountID:  compute.SubscriptionID,
+	}
+	for _, tag := range compute.TagsList {
+		if instance.Tags == nil {
+			instance.Tags = map[string]string{}
+		}
+		if len(instance.Tags) == maxTags {
+			break
+		}
+		instance.Tags[tag.Name] = tag.Value
+	}
+	return instance, nil
+}
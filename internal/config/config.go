@@ -4,22 +4,55 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/fixpanic/fixpanic-cli/internal/cloudmeta"
+	"github.com/fixpanic/fixpanic-cli/internal/fsperm"
+	"github.com/fixpanic/fixpanic-cli/internal/interrupt"
+	"github.com/fixpanic/fixpanic-cli/internal/netprobe"
 	"gopkg.in/yaml.v3"
 )
 
+// DefaultSocketServer is the socket server the agent connects to by default
+const DefaultSocketServer = "socket.fixpanic.com:9000"
+
+// DefaultSocketPort is used when a socket server address is given without a port
+const DefaultSocketPort = "9000"
+
 // AgentConfig represents the agent configuration
 type AgentConfig struct {
+	ConfigVersion int `yaml:"config_version"`
+	// Profile records the profile the config was generated from, if any
+	Profile    string            `yaml:"profile,omitempty"`
 	App        AppSection        `yaml:"app"`
 	ReqHandler ReqHandlerSection `yaml:"req_handler"`
 	Logging    LoggingSection    `yaml:"logging"`
+	// Service is only read by the CLI when it generates the service unit
+	Service ServiceSection `yaml:"service,omitempty"`
+	// Policy limits what the platform may run through the agent
+	Policy PolicySection `yaml:"policy,omitempty"`
 }
 
 type AppSection struct {
 	AgentID                string `yaml:"agent_id"`
 	APIKey                 string `yaml:"api_key"`
+	SocketServer           string `yaml:"socket_server,omitempty"`
 	TLSEnabled             bool   `yaml:"tls_enabled"`
 	TLSInsecureSkipVerify  bool   `yaml:"tls_insecure_skip_verify"`
+	// TLSCAFile is a PEM bundle of CAs trusted instead of the system roots,
+	// for socket servers behind proxies with a private CA
+	TLSCAFile string `yaml:"tls_ca_file,omitempty"`
+	// TLSCertFile and TLSKeyFile are the client certificate and key the
+	// agent presents for mutual TLS
+	TLSCertFile string `yaml:"tls_cert_file,omitempty"`
+	TLSKeyFile  string `yaml:"tls_key_file,omitempty"`
+	// TLSPinnedSPKI lists the public key pins (sha256/<base64>) the socket
+	// server's chain must match; any one is enough, so the next key can be
+	// pinned before a rotation
+	TLSPinnedSPKI []string `yaml:"tls_pinned_spki,omitempty"`
+	// Cloud is the cloud instance the agent runs on, sent with its
+	// registration so the dashboard can group agents by cloud account
+	Cloud *cloudmeta.Instance `yaml:"cloud,omitempty"`
 }
 
 type ReqHandlerSection struct {
@@ -35,10 +68,26 @@ type LoggingSection struct {
 	File  string `yaml:"file"`
 }
 
+// ServiceSection tunes the generated service: the systemd units the agent is
+// ordered after or depends on, e.g. network-online.target or
+// wg-quick@wg0.service, and the environment of the agent process
+type ServiceSection struct {
+	After           []string          `yaml:"after,omitempty"`
+	Wants           []string          `yaml:"wants,omitempty"`
+	Requires        []string          `yaml:"requires,omitempty"`
+	Environment     map[string]string `yaml:"environment,omitempty"`
+	EnvironmentFile string            `yaml:"environment_file,omitempty"`
+	// Confine restricts the agent with a system call filter and, where
+	// available, an AppArmor profile
+	Confine bool `yaml:"confine,omitempty"`
+}
+
 // DefaultConfig returns a default configuration with TLS enabled
 func DefaultConfig() *AgentConfig {
 	return &AgentConfig{
+		ConfigVersion: CurrentVersion,
 		App: AppSection{
+			SocketServer:          DefaultSocketServer,
 			TLSEnabled:            true,  // Enable TLS by default for security
 			TLSInsecureSkipVerify: false, // Require valid certificates
 		},
@@ -56,19 +105,19 @@ func DefaultConfig() *AgentConfig {
 	}
 }
 
-// LoadConfig loads configuration from file
+// LoadConfig loads configuration from file, upgrading older config versions
+// in memory. The file is left as it is; Migrate writes the upgrade.
 func LoadConfig(path string) (*AgentConfig, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var config AgentConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	result, err := migrateDocument(data)
+	if err != nil {
+		return nil, err
 	}
-
-	return &config, nil
+	return result.Config, nil
 }
 
 // SaveConfig saves configuration to file
@@ -79,16 +128,70 @@ func SaveConfig(config *AgentConfig, path string) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	if config.ConfigVersion == 0 {
+		config.ConfigVersion = CurrentVersion
+	}
+
 	data, err := yaml.Marshal(config)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0600); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+	return writeConfigFile(path, data)
+}
+
+// writeConfigFile replaces the config file at path with data. It is written
+// next to the file and renamed over it, so an interrupted or failed save
+// leaves the previous configuration in place.
+func writeConfigFile(path string, data []byte) error {
+	return interrupt.Critical(func() error {
+		tmpPath, err := writeTemp(path, data)
+		if err != nil {
+			return fmt.Errorf("failed to write config file: %w", err)
+		}
+		if err := os.Rename(tmpPath, path); err != nil {
+			os.Remove(tmpPath)
+			return fmt.Errorf("failed to write config file: %w", err)
+		}
+		return nil
+	})
+}
+
+// writeTemp writes data to a new file next to path, flushed to disk, and
+// returns its name. The file is private (0600) unless path exists, whose
+// mode and ownership it takes over, e.g. as set by the permission policy.
+func writeTemp(path string, data []byte) (string, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	tmpPath := file.Name()
+	fail := func(err error) (string, error) {
+		file.Close()
+		os.Remove(tmpPath)
+		return "", err
 	}
 
-	return nil
+	if info, err := os.Stat(path); err == nil {
+		if err := file.Chmod(info.Mode().Perm()); err != nil {
+			return fail(err)
+		}
+		if uid, gid, ok := fsperm.FileOwner(info); ok {
+			// Only root can give the file away; keeping the writer as owner is fine
+			file.Chown(uid, gid)
+		}
+	}
+	if _, err := file.Write(data); err != nil {
+		return fail(err)
+	}
+	if err := file.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	return tmpPath, nil
 }
 
 // Validate validates the configuration
@@ -99,9 +202,50 @@ func (c *AgentConfig) Validate() error {
 	if c.App.APIKey == "" {
 		return fmt.Errorf("agent API key is required")
 	}
+	if c.App.SocketServer != "" {
+		normalized, err := netprobe.NormalizeEndpoint(c.App.SocketServer, DefaultSocketPort)
+		if err != nil {
+			return fmt.Errorf("invalid socket server: %w", err)
+		}
+		c.App.SocketServer = normalized
+	}
+	if err := c.App.validateTLS(); err != nil {
+		return err
+	}
+	if err := c.Service.Validate(); err != nil {
+		return err
+	}
+	if err := c.Policy.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// Validate checks that every unit and variable fits on its line of the
+// generated unit
+func (s *ServiceSection) Validate() error {
+	lists := []struct {
+		key   string
+		units []string
+	}{{"after", s.After}, {"wants", s.Wants}, {"requires", s.Requires}}
+	for _, list := range lists {
+		for _, unit := range list.units {
+			if unit == "" || strings.ContainsAny(unit, " \t\r\n\\") || !strings.Contains(unit, ".") {
+				return fmt.Errorf("invalid unit %q in service.%s: expected a unit name such as network-online.target", unit, list.key)
+			}
+		}
+	}
+	return s.validateEnvironment()
+}
+
+// GetSocketServer returns the configured socket server, falling back to the default
+func (c *AgentConfig) GetSocketServer() string {
+	if c.App.SocketServer == "" {
+		return DefaultSocketServer
+	}
+	return c.App.SocketServer
+}
+
 // GetConfigPath returns the default config path
 func GetConfigPath() string {
 	return "/etc/fixpanic/agent.yaml"
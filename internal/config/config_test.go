@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	agentConfig := DefaultConfig()
+	agentConfig.App.AgentID = "agent_123"
+	agentConfig.App.APIKey = "fp_test"
+	if err := SaveConfig(agentConfig, path); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("new config mode = %o, want 600", mode)
+	}
+
+	// A mode set by the permission policy survives the rename
+	if err := os.Chmod(path, 0640); err != nil {
+		t.Fatal(err)
+	}
+	agentConfig.Logging.Level = "debug"
+	if err := SaveConfig(agentConfig, path); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0640 {
+		t.Errorf("saved config mode = %v, %v, want 640", info.Mode().Perm(), err)
+	}
+	saved, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if saved.Logging.Level != "debug" || saved.App.AgentID != "agent_123" {
+		t.Errorf("saved config = %+v, want the updated configuration", saved)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("config directory holds %d files, want only the config (no temporary files)", len(entries))
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envNamePattern matches the variable names accepted in service.environment
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateEnvironment checks the variables and environment file of the
+// service section
+func (s *ServiceSection) validateEnvironment() error {
+	for name, value := range s.Environment {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid variable name %q in service.environment", name)
+		}
+		if strings.ContainsAny(value, "\r\n\x00") {
+			return fmt.Errorf("service.environment.%s must be a single line", name)
+		}
+	}
+	if s.EnvironmentFile != "" {
+		if !filepath.IsAbs(s.EnvironmentFile) || strings.ContainsAny(s.EnvironmentFile, "\r\n\x00") {
+			return fmt.Errorf("service.environment_file must be an absolute path, got %q", s.EnvironmentFile)
+		}
+	}
+	return nil
+}
+
+// Environ returns the service environment as sorted NAME=value pairs, for
+// service managers that can't read an environment file themselves. As with
+// systemd, variables from the environment file override service.environment.
+func (s *ServiceSection) Environ() ([]string, error) {
+	merged := make(map[string]string, len(s.Environment))
+	for name, value := range s.Environment {
+		merged[name] = value
+	}
+	if s.EnvironmentFile != "" {
+		fromFile, err := ReadEnvironmentFile(s.EnvironmentFile)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fromFile {
+			merged[name] = value
+		}
+	}
+
+	environ := make([]string, 0, len(merged))
+	for name, value := range merged {
+		environ = append(environ, name+"="+value)
+	}
+	sort.Strings(environ)
+	return environ, nil
+}
+
+// ReadEnvironmentFile parses a systemd-style environment file: NAME=value
+// lines, with blank lines and lines starting with # or ; ignored and values
+// optionally enclosed in single or double quotes
+func ReadEnvironmentFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read environment file: %w", err)
+	}
+	defer file.Close()
+
+	variables := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("%s:%d: expected NAME=value", path, number)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		variables[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read environment file: %w", err)
+	}
+	return variables, nil
+}
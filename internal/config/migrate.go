@@ -0,0 +1,173 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the config_version written by this CLI
+const CurrentVersion = 2
+
+// legacyVersion is assumed for files written before config_version existed
+const legacyVersion = 1
+
+// Migration upgrades a raw config document from one version to the next.
+// Migrations work on the generic document rather than AgentConfig so they can
+// rename or move sections the current struct no longer knows about.
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(doc map[string]interface{}) error
+}
+
+// migrations must be ordered by From and cover every version below CurrentVersion
+var migrations = []Migration{
+	{
+		From:        1,
+		Description: "Record the socket server explicitly (older installs relied on the agent's built-in default)",
+		Apply:       migrateRecordSocketServer,
+	},
+}
+
+// MigrationResult describes how a config document was upgraded
+type MigrationResult struct {
+	FromVersion int
+	ToVersion   int
+	Applied     []string
+	BackupPath  string
+	Config      *AgentConfig
+	// Document is the migrated file, including keys AgentConfig doesn't know
+	// such as those of newer agents, which Migrate writes back
+	Document []byte
+}
+
+// NeedsMigration reports whether any migration was applied
+func (r *MigrationResult) NeedsMigration() bool {
+	return len(r.Applied) > 0
+}
+
+// Migrate upgrades the config file at path to CurrentVersion, backing up the
+// original next to it. With dryRun nothing is written.
+func Migrate(path string, dryRun bool) (*MigrationResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	result, err := migrateDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.NeedsMigration() && !dryRun {
+		if err := persistMigration(path, data, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// migrateDocument parses raw config data and applies pending migrations in memory
+func migrateDocument(data []byte) (*MigrationResult, error) {
+	doc := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+
+	version, err := documentVersion(doc)
+	if err != nil {
+		return nil, err
+	}
+	if version > CurrentVersion {
+		return nil, fmt.Errorf("config version %d is newer than this CLI supports (%d); upgrade the CLI with 'fixpanic upgrade'", version, CurrentVersion)
+	}
+
+	result := &MigrationResult{FromVersion: version, ToVersion: version}
+	for _, migration := range migrations {
+		if migration.From != result.ToVersion {
+			continue
+		}
+		if err := migration.Apply(doc); err != nil {
+			return nil, fmt.Errorf("failed to migrate config from version %d: %w", migration.From, err)
+		}
+		result.ToVersion = migration.From + 1
+		result.Applied = append(result.Applied, migration.Description)
+	}
+	if result.ToVersion != CurrentVersion {
+		return nil, fmt.Errorf("no migration path from config version %d to %d", result.ToVersion, CurrentVersion)
+	}
+	doc["config_version"] = CurrentVersion
+
+	// Round-trip through YAML to decode the generic document into AgentConfig
+	migrated, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode migrated config: %w", err)
+	}
+	var config AgentConfig
+	if err := yaml.Unmarshal(migrated, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse migrated config: %w", err)
+	}
+	result.Config = &config
+	result.Document = migrated
+
+	return result, nil
+}
+
+// documentVersion returns the config_version of a raw document
+func documentVersion(doc map[string]interface{}) (int, error) {
+	raw, ok := doc["config_version"]
+	if !ok || raw == nil {
+		return legacyVersion, nil
+	}
+	version, ok := raw.(int)
+	if !ok || version < legacyVersion {
+		return 0, fmt.Errorf("invalid config_version %v", raw)
+	}
+	return version, nil
+}
+
+// persistMigration backs up the original file and writes the migrated
+// document, so keys AgentConfig doesn't know are kept
+func persistMigration(path string, original []byte, result *MigrationResult) error {
+	backupPath := fmt.Sprintf("%s.v%d.bak", path, result.FromVersion)
+	if err := os.WriteFile(backupPath, original, 0600); err != nil {
+		return fmt.Errorf("failed to back up config file: %w", err)
+	}
+	result.BackupPath = backupPath
+
+	return writeConfigFile(path, result.Document)
+}
+
+// section returns the named mapping of doc, creating it if needed
+func section(doc map[string]interface{}, name string) (map[string]interface{}, error) {
+	raw, ok := doc[name]
+	if !ok || raw == nil {
+		created := map[string]interface{}{}
+		doc[name] = created
+		return created, nil
+	}
+	existing, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("section %q is not a mapping", name)
+	}
+	return existing, nil
+}
+
+// migrateRecordSocketServer (v1 -> v2) writes the default socket server into
+// configs that predate the socket_server setting
+func migrateRecordSocketServer(doc map[string]interface{}) error {
+	app, err := section(doc, "app")
+	if err != nil {
+		return err
+	}
+	if server, ok := app["socket_server"].(string); !ok || server == "" {
+		app["socket_server"] = DefaultSocketServer
+	}
+	return nil
+}
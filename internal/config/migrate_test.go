@@ -0,0 +1,202 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMigrateDocument(t *testing.T) {
+	tests := []struct {
+		name         string
+		doc          string
+		wantFrom     int
+		wantApplied  int
+		wantServer   string
+		wantAgentID  string
+		wantErrorHas string
+	}{
+		{
+			name:        "legacy without socket server",
+			doc:         "app:\n  agent_id: web-1\n  api_key: k\n",
+			wantFrom:    1,
+			wantApplied: 1,
+			wantServer:  DefaultSocketServer,
+			wantAgentID: "web-1",
+		},
+		{
+			name:        "legacy keeps its socket server",
+			doc:         "app:\n  agent_id: web-1\n  socket_server: socket.internal:443\n",
+			wantFrom:    1,
+			wantApplied: 1,
+			wantServer:  "socket.internal:443",
+			wantAgentID: "web-1",
+		},
+		{
+			name:        "empty legacy file",
+			doc:         "",
+			wantFrom:    1,
+			wantApplied: 1,
+			wantServer:  DefaultSocketServer,
+		},
+		{
+			name:        "current",
+			doc:         "config_version: 2\napp:\n  agent_id: web-1\n  socket_server: socket.internal:443\n",
+			wantFrom:    2,
+			wantServer:  "socket.internal:443",
+			wantAgentID: "web-1",
+		},
+		{
+			name:         "newer than supported",
+			doc:          "config_version: 99\n",
+			wantErrorHas: "newer than this CLI supports",
+		},
+		{
+			name:         "invalid version",
+			doc:          "config_version: two\n",
+			wantErrorHas: "invalid config_version",
+		},
+		{
+			name:         "app is no mapping",
+			doc:          "app: web-1\n",
+			wantErrorHas: "not a mapping",
+		},
+		{
+			name:         "not yaml",
+			doc:          "app: [\n",
+			wantErrorHas: "failed to parse",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := migrateDocument([]byte(tt.doc))
+			if tt.wantErrorHas != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrorHas) {
+					t.Fatalf("migrateDocument() error = %v, want one containing %q", err, tt.wantErrorHas)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("migrateDocument() error = %v", err)
+			}
+			if result.FromVersion != tt.wantFrom || result.ToVersion != CurrentVersion || len(result.Applied) != tt.wantApplied {
+				t.Errorf("migrated %d -> %d with %d migration(s), want %d -> %d with %d", result.FromVersion, result.ToVersion, len(result.Applied), tt.wantFrom, CurrentVersion, tt.wantApplied)
+			}
+			if result.Config.ConfigVersion != CurrentVersion {
+				t.Errorf("ConfigVersion = %d, want %d", result.Config.ConfigVersion, CurrentVersion)
+			}
+			if result.Config.App.SocketServer != tt.wantServer || result.Config.App.AgentID != tt.wantAgentID {
+				t.Errorf("App = %+v, want socket server %q and agent ID %q", result.Config.App, tt.wantServer, tt.wantAgentID)
+			}
+		})
+	}
+}
+
+func TestMigrationsCoverEveryVersion(t *testing.T) {
+	for i, migration := range migrations {
+		if migration.From != legacyVersion+i {
+			t.Errorf("migration %d starts at version %d, want %d", i, migration.From, legacyVersion+i)
+		}
+	}
+	if len(migrations) != CurrentVersion-legacyVersion {
+		t.Errorf("%d migration(s) for versions %d to %d", len(migrations), legacyVersion, CurrentVersion)
+	}
+}
+
+const legacyConfig = "app:\n  agent_id: web-1\n  api_key: k\npolicy:\n  deny_commands:\n    - rm\n"
+
+func TestLoadConfigLeavesFileUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.yaml")
+	if err := os.WriteFile(path, []byte(legacyConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.ConfigVersion != CurrentVersion || config.App.SocketServer != DefaultSocketServer {
+		t.Errorf("LoadConfig() didn't migrate in memory: %+v", config)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != legacyConfig {
+		t.Errorf("LoadConfig() rewrote the file:\n%s", data)
+	}
+	if matches, _ := filepath.Glob(path + ".v*.bak"); len(matches) > 0 {
+		t.Errorf("LoadConfig() wrote backups %v", matches)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	for _, dryRun := range []bool{true, false} {
+		path := filepath.Join(t.TempDir(), "agent.yaml")
+		if err := os.WriteFile(path, []byte(legacyConfig), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := Migrate(path, dryRun)
+		if err != nil {
+			t.Fatalf("Migrate(dryRun=%v) error = %v", dryRun, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		backup, backupErr := os.ReadFile(path + ".v1.bak")
+
+		if dryRun {
+			if string(data) != legacyConfig || backupErr == nil || result.BackupPath != "" {
+				t.Errorf("Migrate(dryRun=true) wrote files")
+			}
+			continue
+		}
+		if result.BackupPath != path+".v1.bak" || string(backup) != legacyConfig {
+			t.Errorf("backup at %q = %q, %v", result.BackupPath, backup, backupErr)
+		}
+		migrated, err := LoadConfig(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if migrated.ConfigVersion != CurrentVersion || len(migrated.Policy.DenyCommands) != 1 {
+			t.Errorf("migrated file = %s", data)
+		}
+		again, err := Migrate(path, false)
+		if err != nil || again.NeedsMigration() {
+			t.Errorf("migrating a current file again: %+v, %v", again, err)
+		}
+	}
+}
+
+func TestMigrateKeepsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.yaml")
+	content := legacyConfig + "app_extra: kept\nfeatures:\n  sessions: true\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Migrate(path, false); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	features, _ := doc["features"].(map[string]interface{})
+	if doc["app_extra"] != "kept" || features["sessions"] != true {
+		t.Errorf("Migrate() dropped unknown keys:\n%s", data)
+	}
+	if doc["config_version"] != CurrentVersion {
+		t.Errorf("config_version = %v, want %d", doc["config_version"], CurrentVersion)
+	}
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// PolicySection is the host owner's policy for the commands the platform runs
+// through the agent. The agent refuses commands the policy doesn't allow; an
+// empty policy allows everything.
+type PolicySection struct {
+	// AllowCommands are patterns a command line must match one of, with *
+	// matching any text and ? one character. Empty allows every command
+	// that isn't denied.
+	AllowCommands []string `yaml:"allow_commands,omitempty" json:"allow_commands,omitempty"`
+	// DenyCommands are patterns of command lines refused even if allowed
+	DenyCommands []string `yaml:"deny_commands,omitempty" json:"deny_commands,omitempty"`
+	// BlockedPaths are files and directories commands may not be pointed at
+	BlockedPaths []string `yaml:"blocked_paths,omitempty" json:"blocked_paths,omitempty"`
+	// TimeWindows are when commands are accepted, in the host's time zone,
+	// e.g. "Mon-Fri 09:00-17:00". Empty accepts them at any time.
+	TimeWindows []string `yaml:"time_windows,omitempty" json:"time_windows,omitempty"`
+}
+
+// IsEmpty reports whether the policy allows everything
+func (p *PolicySection) IsEmpty() bool {
+	return len(p.AllowCommands) == 0 && len(p.DenyCommands) == 0 && len(p.BlockedPaths) == 0 && len(p.TimeWindows) == 0
+}
+
+// Validate checks the patterns, paths and time windows of the policy
+func (p *PolicySection) Validate() error {
+	for _, list := range []struct {
+		key      string
+		patterns []string
+	}{{"allow_commands", p.AllowCommands}, {"deny_commands", p.DenyCommands}} {
+		for _, pattern := range list.patterns {
+			if strings.TrimSpace(pattern) == "" || strings.ContainsAny(pattern, "\r\n\x00") {
+				return fmt.Errorf("invalid pattern %q in policy.%s", pattern, list.key)
+			}
+		}
+	}
+	for _, path := range p.BlockedPaths {
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("policy.blocked_paths must be absolute paths, got %q", path)
+		}
+	}
+	for _, spec := range p.TimeWindows {
+		if _, err := ParseTimeWindow(spec); err != nil {
+			return fmt.Errorf("invalid time window in policy.time_windows: %w", err)
+		}
+	}
+	return nil
+}
+
+// Check returns why the policy refuses to run command at now, or nil if it
+// allows it. Arguments are checked against the blocked paths as the agent
+// does: an argument naming a blocked path or anything below it is refused.
+func (p *PolicySection) Check(command string, now time.Time) error {
+	command = strings.TrimSpace(command)
+	for _, pattern := range p.DenyCommands {
+		if matchCommand(pattern, command) {
+			return fmt.Errorf("denied by the pattern %q", pattern)
+		}
+	}
+	if len(p.AllowCommands) > 0 {
+		allowed := false
+		for _, pattern := range p.AllowCommands {
+			if matchCommand(pattern, command) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("matches none of the allowed patterns")
+		}
+	}
+	for _, argument := range strings.Fields(command) {
+		for _, blocked := range p.BlockedPaths {
+			blocked = filepath.Clean(blocked)
+			if argument == blocked || strings.HasPrefix(argument, strings.TrimSuffix(blocked, "/")+"/") {
+				return fmt.Errorf("%s falls under the blocked path %s", argument, blocked)
+			}
+		}
+	}
+	if len(p.TimeWindows) > 0 {
+		for _, spec := range p.TimeWindows {
+			if window, err := ParseTimeWindow(spec); err == nil && window.Contains(now) {
+				return nil
+			}
+		}
+		return fmt.Errorf("outside the allowed time windows (%s)", strings.Join(p.TimeWindows, ", "))
+	}
+	return nil
+}
+
+// matchCommand reports whether command matches pattern as a whole, with *
+// matching any text (including spaces and slashes) and ? one character
+func matchCommand(pattern, command string) bool {
+	var expression strings.Builder
+	expression.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expression.WriteString(".*")
+		case '?':
+			expression.WriteString(".")
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expression.WriteString("$")
+	return regexp.MustCompile(expression.String()).MatchString(command)
+}
+
+// weekdays maps the day names accepted in time windows
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// TimeWindow is a daily period on some days of the week
+type TimeWindow struct {
+	Days [7]bool
+	// Start and End are minutes since midnight; a window ending before it
+	// starts runs past midnight into the next day
+	Start, End int
+}
+
+// ParseTimeWindow parses "[DAYS ]HH:MM-HH:MM", where DAYS is a day
+// ("Sat"), a range ("Mon-Fri") or a comma-separated list of either and
+// defaults to every day
+func ParseTimeWindow(spec string) (TimeWindow, error) {
+	var window TimeWindow
+	fields := strings.Fields(spec)
+	if len(fields) == 0 || len(fields) > 2 {
+		return window, fmt.Errorf("%q: expected e.g. \"Mon-Fri 09:00-17:00\"", spec)
+	}
+
+	if len(fields) == 1 {
+		for day := range window.Days {
+			window.Days[day] = true
+		}
+	} else {
+		for _, item := range strings.Split(fields[0], ",") {
+			first, last, isRange := strings.Cut(strings.ToLower(item), "-")
+			if !isRange {
+				last = first
+			}
+			from, knownFrom := weekdays[first]
+			to, knownTo := weekdays[last]
+			if !knownFrom || !knownTo {
+				return window, fmt.Errorf("%q: unknown day %q, use Mon, Tue, ... Sun", spec, item)
+			}
+			for day := from; ; day = (day + 1) % 7 {
+				window.Days[day] = true
+				if day == to {
+					break
+				}
+			}
+		}
+	}
+
+	start, end, ok := strings.Cut(fields[len(fields)-1], "-")
+	if !ok {
+		return window, fmt.Errorf("%q: expected a time range such as 09:00-17:00", spec)
+	}
+	var err error
+	if window.Start, err = parseClock(start); err != nil {
+		return window, fmt.Errorf("%q: %w", spec, err)
+	}
+	if window.End, err = parseClock(end); err != nil {
+		return window, fmt.Errorf("%q: %w", spec, err)
+	}
+	if window.Start == window.End {
+		return window, fmt.Errorf("%q: the window is empty", spec)
+	}
+	return window, nil
+}
+
+// Contains reports whether t falls within the window, in t's time zone
+func (w TimeWindow) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if w.Start < w.End {
+		return w.Days[t.Weekday()] && minute >= w.Start && minute < w.End
+	}
+	// Past midnight the window belongs to the day it started on
+	if minute >= w.Start {
+		return w.Days[t.Weekday()]
+	}
+	return minute < w.End && w.Days[(t.Weekday()+6)%7]
+}
+
+// parseClock parses HH:MM into minutes since midnight; 24:00 ends a day
+func parseClock(clock string) (int, error) {
+	if clock == "24:00" {
+		return 24 * 60, nil
+	}
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, use HH:MM", clock)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       PolicySection
+		wantErrorHas string
+	}{
+		{name: "empty", policy: PolicySection{}},
+		{
+			name: "valid",
+			policy: PolicySection{
+				AllowCommands: []string{"systemctl status *"},
+				DenyCommands:  []string{"rm -rf *"},
+				BlockedPaths:  []string{"/etc/shadow"},
+				TimeWindows:   []string{"Mon-Fri 09:00-17:00", "22:00-02:00"},
+			},
+		},
+		{name: "blank pattern", policy: PolicySection{AllowCommands: []string{" "}}, wantErrorHas: "policy.allow_commands"},
+		{name: "pattern with newline", policy: PolicySection{DenyCommands: []string{"rm\n-rf"}}, wantErrorHas: "policy.deny_commands"},
+		{name: "relative blocked path", policy: PolicySection{BlockedPaths: []string{"etc/shadow"}}, wantErrorHas: "must be absolute"},
+		{name: "unknown day", policy: PolicySection{TimeWindows: []string{"Mon-Fry 09:00-17:00"}}, wantErrorHas: "unknown day"},
+		{name: "empty window", policy: PolicySection{TimeWindows: []string{"09:00-09:00"}}, wantErrorHas: "the window is empty"},
+		{name: "invalid time", policy: PolicySection{TimeWindows: []string{"9am-5pm"}}, wantErrorHas: "invalid time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.wantErrorHas == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErrorHas) {
+				t.Fatalf("Validate() error = %v, want it to contain %q", err, tt.wantErrorHas)
+			}
+		})
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	// A Wednesday
+	noon := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	night := time.Date(2026, 10, 14, 23, 0, 0, 0, time.UTC)
+
+	policy := PolicySection{
+		AllowCommands: []string{"systemctl * nginx", "cat *"},
+		DenyCommands:  []string{"systemctl stop *"},
+		BlockedPaths:  []string{"/etc/shadow", "/root/"},
+		TimeWindows:   []string{"Mon-Fri 09:00-17:00"},
+	}
+	tests := []struct {
+		name    string
+		command string
+		now     time.Time
+		allowed bool
+	}{
+		{"allowed", "systemctl restart nginx", noon, true},
+		{"denied wins over allowed", "systemctl stop nginx", noon, false},
+		{"not allowed", "reboot", noon, false},
+		{"blocked path", "cat /etc/shadow", noon, false},
+		{"below blocked directory", "cat /root/.ssh/id_ed25519", noon, false},
+		{"similar path", "cat /etc/shadow-", noon, true},
+		{"outside time window", "systemctl restart nginx", night, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.command, tt.now)
+			if (err == nil) != tt.allowed {
+				t.Errorf("Check(%q) error = %v, want allowed = %v", tt.command, err, tt.allowed)
+			}
+		})
+	}
+
+	if err := (&PolicySection{}).Check("rm -rf /", noon); err != nil {
+		t.Errorf("empty policy refused a command: %v", err)
+	}
+}
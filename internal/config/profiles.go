@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultProfile is the profile used when none is selected
+const DefaultProfile = "default"
+
+// Profile is a named set of request handler and logging defaults shipped with
+// the CLI, applied on top of DefaultConfig when the config is generated
+type Profile struct {
+	Name        string
+	Description string
+	Apply       func(config *AgentConfig)
+}
+
+// profiles lists the shipped profiles in the order they are shown to users
+var profiles = []Profile{
+	{
+		Name:        DefaultProfile,
+		Description: "Balanced limits suitable for most servers",
+		Apply:       func(config *AgentConfig) {},
+	},
+	{
+		Name:        "high-throughput",
+		Description: "More concurrent connections and longer tool timeouts for busy hosts",
+		Apply: func(config *AgentConfig) {
+			config.ReqHandler.MaxConcurrentConnections = 50
+			config.ReqHandler.ConnectionTimeout = "120s"
+			config.ReqHandler.DefaultToolTimeout = 600
+			config.Logging.Level = "warn"
+		},
+	},
+	{
+		Name:        "low-memory",
+		Description: "Few concurrent connections and short timeouts for small VMs and containers",
+		Apply: func(config *AgentConfig) {
+			config.ReqHandler.MaxConcurrentConnections = 2
+			config.ReqHandler.ConnectionTimeout = "30s"
+			config.ReqHandler.DefaultToolTimeout = 120
+			config.Logging.Level = "warn"
+		},
+	},
+	{
+		Name:        "debug",
+		Description: "Verbose logging and generous timeouts for troubleshooting",
+		Apply: func(config *AgentConfig) {
+			config.ReqHandler.MaxConcurrentConnections = 5
+			config.ReqHandler.ConnectionTimeout = "300s"
+			config.ReqHandler.DefaultToolTimeout = 900
+			config.Logging.Level = "debug"
+		},
+	},
+}
+
+// Profiles returns the shipped profiles
+func Profiles() []Profile {
+	return profiles
+}
+
+// ProfileNames returns the names of the shipped profiles
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		names = append(names, profile.Name)
+	}
+	return names
+}
+
+// GetProfile returns the named profile; an empty name selects DefaultProfile
+func GetProfile(name string) (Profile, error) {
+	if name == "" {
+		name = DefaultProfile
+	}
+	for _, profile := range profiles {
+		if profile.Name == name {
+			return profile, nil
+		}
+	}
+	return Profile{}, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(ProfileNames(), ", "))
+}
+
+// ProfileConfig returns DefaultConfig with the named profile applied
+func ProfileConfig(name string) (*AgentConfig, error) {
+	profile, err := GetProfile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	config := DefaultConfig()
+	profile.Apply(config)
+	if profile.Name != DefaultProfile {
+		config.Profile = profile.Name
+	}
+	return config, nil
+}
+
+// InstallConfig returns the configuration an installation writes: the named
+// profile with the agent's credentials, logging to logPath. The service and
+// policy sections, the CA bundle, client certificate and pins, and the cloud
+// instance of the configuration at existingPath, if any, are kept, so
+// reinstalling doesn't drop them.
+func InstallConfig(profile, agentID, apiKey, socketServer, logPath, existingPath string) (*AgentConfig, error) {
+	config, err := ProfileConfig(profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create configuration: %w", err)
+	}
+	config.App.AgentID = agentID
+	config.App.APIKey = apiKey
+	config.Logging.File = logPath
+	if socketServer != "" {
+		config.App.SocketServer = socketServer
+	}
+	if existing, err := LoadConfig(existingPath); err == nil {
+		PreserveUserFields(config, existing)
+	}
+	if config.ConfigVersion == 0 {
+		config.ConfigVersion = CurrentVersion
+	}
+	return config, nil
+}
+
+// PreserveUserFields copies what the user configured rather than the profile
+// from existing to config: the service and policy sections, the CA bundle,
+// client certificate and pins, and the cloud instance. Rewriting the
+// configuration from a profile keeps them.
+func PreserveUserFields(config, existing *AgentConfig) {
+	config.Service = existing.Service
+	config.Policy = existing.Policy
+	config.App.TLSCAFile = existing.App.TLSCAFile
+	config.App.TLSCertFile = existing.App.TLSCertFile
+	config.App.TLSKeyFile = existing.App.TLSKeyFile
+	config.App.TLSPinnedSPKI = existing.App.TLSPinnedSPKI
+	config.App.Cloud = existing.App.Cloud
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// BrokenSuffix is appended to a config file replaced by RestoreBackup
+const BrokenSuffix = ".broken"
+
+// ParseConfig parses config data, upgrading older config versions in memory
+func ParseConfig(data []byte) (*AgentConfig, error) {
+	result, err := migrateDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	return result.Config, nil
+}
+
+// BackupPaths returns the backups the CLI keeps of the config file at path,
+// newest first: path.bak from 'agent diff --accept' and path.v<N>.bak from
+// migrations
+func BackupPaths(path string) []string {
+	candidates, _ := filepath.Glob(path + ".v*.bak")
+	candidates = append(candidates, path+".bak")
+
+	type backup struct {
+		path    string
+		modTime int64
+	}
+	var backups []backup
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+			backups = append(backups, backup{candidate, info.ModTime().UnixNano()})
+		}
+	}
+	sort.SliceStable(backups, func(i, j int) bool { return backups[i].modTime > backups[j].modTime })
+
+	paths := make([]string, len(backups))
+	for i, b := range backups {
+		paths[i] = b.path
+	}
+	return paths
+}
+
+// RestoreBackup replaces the config file at path with its newest backup that
+// parses and validates, keeping the replaced file as path.broken, and returns
+// the backup restored. Older config versions are restored as they are and
+// migrated when next loaded.
+func RestoreBackup(path string) (string, error) {
+	for _, backupPath := range BackupPaths(path) {
+		data, err := os.ReadFile(backupPath)
+		if err != nil {
+			continue
+		}
+		config, err := ParseConfig(data)
+		if err != nil || config.Validate() != nil {
+			continue
+		}
+
+		if current, err := os.ReadFile(path); err == nil {
+			if err := os.WriteFile(path+BrokenSuffix, current, 0600); err != nil {
+				return "", fmt.Errorf("failed to keep the broken config file: %w", err)
+			}
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0600); err != nil {
+			return "", fmt.Errorf("failed to restore config file: %w", err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			os.Remove(tmp)
+			return "", fmt.Errorf("failed to restore config file: %w", err)
+		}
+		return backupPath, nil
+	}
+	return "", fmt.Errorf("no valid backup of %s found", path)
+}
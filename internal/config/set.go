@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// readOnlyKeys are managed by the CLI and can't be changed with Set
+var readOnlyKeys = map[string]bool{
+	"config_version": true,
+	"profile":        true,
+}
+
+// Keys returns the dotted keys accepted by Get and Set, e.g.
+// "req_handler.max_concurrent_connections"
+func Keys() []string {
+	var keys []string
+	collectKeys(reflect.TypeOf(AgentConfig{}), "", &keys)
+	return keys
+}
+
+// Get returns the value of a dotted key
+func (c *AgentConfig) Get(key string) (string, error) {
+	field, err := c.field(key)
+	if err != nil {
+		return "", err
+	}
+	switch value := field.Interface().(type) {
+	case []string:
+		return strings.Join(value, ","), nil
+	case map[string]string:
+		pairs := make([]string, 0, len(value))
+		for name, v := range value {
+			pairs = append(pairs, name+"="+v)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ","), nil
+	}
+	return fmt.Sprint(field.Interface()), nil
+}
+
+// Set parses value according to the type of the dotted key and assigns it.
+// Lists are given comma-separated and maps as comma-separated NAME=value
+// pairs; an empty value clears them.
+func (c *AgentConfig) Set(key, value string) error {
+	if readOnlyKeys[key] {
+		return fmt.Errorf("%s is managed by the CLI and can't be set", key)
+	}
+
+	field, err := c.field(key)
+	if err != nil {
+		return err
+	}
+	if !settable(field.Type()) {
+		return fmt.Errorf("%s can't be set from the command line", key)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be an integer, got %q", key, value)
+		}
+		field.SetInt(int64(parsed))
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", key, value)
+		}
+		field.SetBool(parsed)
+	case reflect.Slice:
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+	case reflect.Map:
+		pairs := map[string]string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			name, v, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("%s takes NAME=value pairs, got %q", key, item)
+			}
+			pairs[strings.TrimSpace(name)] = v
+		}
+		if len(pairs) == 0 {
+			pairs = nil
+		}
+		field.Set(reflect.ValueOf(pairs))
+	}
+
+	return nil
+}
+
+// settable reports whether Set can parse a value for a field of type t:
+// strings, integers, booleans, string lists and string maps. Pointers such
+// as app.cloud are written by the CLI alone.
+func settable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Bool:
+		return true
+	case reflect.Slice:
+		return t == reflect.TypeOf([]string(nil))
+	case reflect.Map:
+		return t == reflect.TypeOf(map[string]string(nil))
+	}
+	return false
+}
+
+// field resolves a dotted key to the addressable struct field it names
+func (c *AgentConfig) field(key string) (reflect.Value, error) {
+	value := reflect.ValueOf(c).Elem()
+	for _, name := range strings.Split(key, ".") {
+		if value.Kind() != reflect.Struct {
+			return reflect.Value{}, unknownKeyError(key)
+		}
+		index := fieldIndex(value.Type(), name)
+		if index < 0 {
+			return reflect.Value{}, unknownKeyError(key)
+		}
+		value = value.Field(index)
+	}
+	if value.Kind() == reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%s is a section; use one of its keys", key)
+	}
+	return value, nil
+}
+
+// fieldIndex returns the index of the field with the given YAML name, or -1
+func fieldIndex(t reflect.Type, name string) int {
+	for i := 0; i < t.NumField(); i++ {
+		if yamlName(t.Field(i)) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// collectKeys appends the settable dotted keys of t to keys
+func collectKeys(t reflect.Type, prefix string, keys *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		key := prefix + yamlName(t.Field(i))
+		if t.Field(i).Type.Kind() == reflect.Struct {
+			collectKeys(t.Field(i).Type, key+".", keys)
+			continue
+		}
+		if !readOnlyKeys[key] && settable(t.Field(i).Type) {
+			*keys = append(*keys, key)
+		}
+	}
+}
+
+// yamlName returns the name a struct field is serialized under
+func yamlName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// unknownKeyError reports a key that doesn't exist in the config
+func unknownKeyError(key string) error {
+	return fmt.Errorf("unknown config key %q (run 'fixpanic config set --help' for the list of keys)", key)
+}
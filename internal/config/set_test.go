@@ -0,0 +1,53 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeysCanBeSet(t *testing.T) {
+	config := DefaultConfig()
+	for _, key := range Keys() {
+		value, err := config.Get(key)
+		if err != nil {
+			t.Errorf("Get(%q) error = %v", key, err)
+			continue
+		}
+		if err := config.Set(key, value); err != nil {
+			t.Errorf("Set(%q, %q) error = %v", key, value, err)
+		}
+	}
+}
+
+func TestKeysSkipManagedFields(t *testing.T) {
+	listed := map[string]bool{}
+	for _, key := range Keys() {
+		listed[key] = true
+	}
+	for _, key := range []string{"app.cloud", "config_version", "profile", "app"} {
+		if listed[key] {
+			t.Errorf("Keys() lists %q", key)
+		}
+	}
+}
+
+func TestSetUnsettable(t *testing.T) {
+	config := DefaultConfig()
+	tests := []struct {
+		key     string
+		wantErr string
+	}{
+		{key: "app.cloud", wantErr: "can't be set from the command line"},
+		{key: "profile", wantErr: "managed by the CLI"},
+		{key: "app", wantErr: "is a section"},
+		{key: "app.nope", wantErr: "unknown config key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			err := config.Set(tt.key, "x")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Set(%q) error = %v, want one containing %q", tt.key, err, tt.wantErr)
+			}
+		})
+	}
+}
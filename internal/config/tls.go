@@ -0,0 +1,109 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fixpanic/fixpanic-cli/internal/netprobe"
+)
+
+// TLSMaterial is what the CA bundle and client certificate of a configuration
+// contain
+type TLSMaterial struct {
+	// CACertificates are the certificates of the CA bundle, empty when the
+	// system roots are used
+	CACertificates []*x509.Certificate
+	// ClientCertificate is the leaf of the client certificate chain, nil
+	// without mutual TLS
+	ClientCertificate *x509.Certificate
+	// KeyMode is the permission bits of the client key file
+	KeyMode os.FileMode
+}
+
+// validateTLS checks the paths and pins of the TLS settings of the app
+// section. Whether the certificate and key are both set is left to
+// LoadTLSMaterial, so 'config set' can change them one at a time.
+func (a *AppSection) validateTLS() error {
+	files := []struct{ key, path string }{
+		{"app.tls_ca_file", a.TLSCAFile},
+		{"app.tls_cert_file", a.TLSCertFile},
+		{"app.tls_key_file", a.TLSKeyFile},
+	}
+	for _, file := range files {
+		if file.path != "" && (!filepath.IsAbs(file.path) || strings.ContainsAny(file.path, "\r\n\x00")) {
+			return fmt.Errorf("%s must be an absolute path, got %q", file.key, file.path)
+		}
+	}
+	for _, pin := range a.TLSPinnedSPKI {
+		if err := netprobe.ValidateSPKIPin(pin); err != nil {
+			return fmt.Errorf("app.tls_pinned_spki: %w", err)
+		}
+	}
+	return nil
+}
+
+// LoadTLSMaterial reads and parses the CA bundle and client key pair, so a
+// wrong path or a mismatched key is found before the agent fails its
+// handshake
+func (a *AppSection) LoadTLSMaterial() (*TLSMaterial, error) {
+	material := &TLSMaterial{}
+	if (a.TLSCertFile == "") != (a.TLSKeyFile == "") {
+		return nil, fmt.Errorf("app.tls_cert_file and app.tls_key_file must be set together")
+	}
+
+	if a.TLSCAFile != "" {
+		data, err := os.ReadFile(a.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("invalid certificate in CA bundle %s: %w", a.TLSCAFile, err)
+			}
+			material.CACertificates = append(material.CACertificates, cert)
+		}
+		if len(material.CACertificates) == 0 {
+			return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", a.TLSCAFile)
+		}
+	}
+
+	if a.TLSCertFile != "" {
+		pair, err := tls.LoadX509KeyPair(a.TLSCertFile, a.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate or key: %w", err)
+		}
+		material.ClientCertificate, err = x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate %s: %w", a.TLSCertFile, err)
+		}
+		info, err := os.Stat(a.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client key: %w", err)
+		}
+		material.KeyMode = info.Mode().Perm()
+	}
+
+	return material, nil
+}
+
+// CertPool returns the CA bundle as a pool to verify servers against, or nil
+// for the system roots
+func (m *TLSMaterial) CertPool() *x509.CertPool {
+	if len(m.CACertificates) == 0 {
+		return nil
+	}
+	pool := x509.NewCertPool()
+	for _, cert := range m.CACertificates {
+		pool.AddCert(cert)
+	}
+	return pool
+}
@@ -1,6 +1,7 @@
 package connectivity
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
@@ -8,18 +9,31 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/diskspace"
+	"github.com/fixpanic/fixpanic-cli/internal/events"
+	"github.com/fixpanic/fixpanic-cli/internal/httpcache"
+	"github.com/fixpanic/fixpanic-cli/internal/interrupt"
 	"github.com/fixpanic/fixpanic-cli/internal/logger"
 	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/releases"
+	"github.com/fixpanic/fixpanic-cli/internal/sbom"
+	"github.com/fixpanic/fixpanic-cli/internal/trace"
+	"github.com/fixpanic/fixpanic-cli/internal/workpool"
 )
 
 // Manager handles connectivity layer binary operations
 type Manager struct {
 	platform *platform.PlatformInfo
 	client   *http.Client
+	// cache holds release metadata and downloads; nil disables caching
+	cache *httpcache.Cache
 }
 
 // NewManager creates a new connectivity manager
@@ -27,93 +41,34 @@ func NewManager(platform *platform.PlatformInfo) *Manager {
 	return &Manager{
 		platform: platform,
 		client:   &http.Client{},
+		cache:    httpcache.Default(),
 	}
 }
 
-// Download downloads the connectivity layer binary
-func (m *Manager) Download(version string) error {
-	url, err := platform.GetFixPanicAgentDownloadURL(version)
-	if err != nil {
-		return fmt.Errorf("failed to get download URL: %w", err)
-	}
-	binaryPath := m.platform.GetBinaryPath()
-
-	fmt.Printf("Downloading connectivity layer from %s...\n", url)
-
-	// Create temporary file
-	tmpFile := binaryPath + ".tmp"
-
-	resp, err := m.client.Get(url)
-	if err != nil {
-		return fmt.Errorf("failed to download binary: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download binary: HTTP %d", resp.StatusCode)
-	}
-
-	// Create the file
-	out, err := os.Create(tmpFile)
-	if err != nil {
-		return fmt.Errorf("failed to create temporary file: %w", err)
-	}
-
-	// Write the body to file
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
-		out.Close()
-		os.Remove(tmpFile)
-		return fmt.Errorf("failed to save binary: %w", err)
-	}
-
-	// Sync to ensure all data is written to disk before closing
-	if err := out.Sync(); err != nil {
-		out.Close()
-		os.Remove(tmpFile)
-		return fmt.Errorf("failed to sync file to disk: %w", err)
-	}
-
-	// Close the file before chmod and rename
-	if err := out.Close(); err != nil {
-		os.Remove(tmpFile)
-		return fmt.Errorf("failed to close file: %w", err)
-	}
-
-	// Make the binary executable
-	if err := os.Chmod(tmpFile, 0755); err != nil {
-		os.Remove(tmpFile)
-		return fmt.Errorf("failed to make binary executable: %w", err)
-	}
-
-	// Move to final location
-	if err := os.Rename(tmpFile, binaryPath); err != nil {
-		os.Remove(tmpFile)
-		return fmt.Errorf("failed to move binary to final location: %w", err)
-	}
-
-	fmt.Printf("Connectivity layer downloaded to %s\n", binaryPath)
-	return nil
+// Download downloads the connectivity layer binary, verified like
+// DownloadFixPanicAgent
+func (m *Manager) Download(ctx context.Context, version string) error {
+	return m.DownloadFixPanicAgent(ctx, version)
 }
 
 // IsInstalled checks if the connectivity layer is installed (DEPRECATED)
 // TODO: Remove this function after migration to IsFixPanicAgentInstalled
 func (m *Manager) IsInstalled() bool {
-	fmt.Println("WARNING: IsInstalled() is deprecated, use IsFixPanicAgentInstalled() instead")
+	logger.Deprecated("IsInstalled()", "IsFixPanicAgentInstalled()")
 	return m.IsFixPanicAgentInstalled()
 }
 
 // GetVersion returns the version of the installed connectivity layer (DEPRECATED)
 // TODO: Remove this function after migration to GetFixPanicAgentVersion
-func (m *Manager) GetVersion() (string, error) {
-	fmt.Println("WARNING: GetVersion() is deprecated, use GetFixPanicAgentVersion() instead")
-	return m.GetFixPanicAgentVersion()
+func (m *Manager) GetVersion(ctx context.Context) (string, error) {
+	logger.Deprecated("GetVersion()", "GetFixPanicAgentVersion()")
+	return m.GetFixPanicAgentVersion(ctx)
 }
 
 // Remove removes the connectivity layer binary (DEPRECATED)
 // TODO: Remove this function after migration to RemoveFixPanicAgent
 func (m *Manager) Remove() error {
-	fmt.Println("WARNING: Remove() is deprecated, use RemoveFixPanicAgent() instead")
+	logger.Deprecated("Remove()", "RemoveFixPanicAgent()")
 	return m.RemoveFixPanicAgent()
 }
 
@@ -134,7 +89,7 @@ func (m *Manager) VerifyChecksum(expectedChecksum string) error {
 
 	actualChecksum := fmt.Sprintf("%x", hash.Sum(nil))
 	if actualChecksum != expectedChecksum {
-		return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, actualChecksum)
+		return clierror.Wrap(clierror.Integrity, fmt.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, actualChecksum))
 	}
 
 	return nil
@@ -145,82 +100,410 @@ func (m *Manager) GetBinaryPath() string {
 	return m.platform.GetBinaryPath()
 }
 
-// DownloadFixPanicAgent downloads the FixPanic Agent binary from GitHub Releases
-func (m *Manager) DownloadFixPanicAgent(version string) error {
+// DownloadFixPanicAgent downloads the FixPanic Agent binary of release
+// version, or of the latest release for "latest", from GitHub Releases. Like
+// InstallFixPanicAgentVersion it only installs a binary listed in the
+// release's signed checksum manifest, so the download is trusted through the
+// release signing key rather than the connection.
+func (m *Manager) DownloadFixPanicAgent(ctx context.Context, version string) error {
+	if version == "latest" {
+		latest, err := m.GetLatestAgentVersion(ctx)
+		if err != nil {
+			return err
+		}
+		version = latest
+	}
+	_, err := m.InstallFixPanicAgentVersion(ctx, version)
+	return err
+}
+
+// DownloadFixPanicAgentVerified downloads the agent binary from downloadURL
+// and only installs it if its SHA-256 checksum is expectedSHA256
+func (m *Manager) DownloadFixPanicAgentVerified(ctx context.Context, downloadURL, expectedSHA256 string) error {
+	if expectedSHA256 == "" {
+		return fmt.Errorf("no checksum given for %s", downloadURL)
+	}
+	return m.downloadAgent(ctx, downloadURL, expectedSHA256)
+}
+
+// VerifiedAgentDownload returns the download URL of this platform's agent
+// binary of release version and its checksum from the release's checksum
+// manifest, once the manifest's signature verifies
+func (m *Manager) VerifiedAgentDownload(ctx context.Context, version string) (string, string, error) {
+	assetName, err := platform.GetFixPanicAgentAssetName()
+	if err != nil {
+		return "", "", err
+	}
 	downloadURL, err := platform.GetFixPanicAgentDownloadURL(version)
 	if err != nil {
-		return fmt.Errorf("failed to get download URL: %w", err)
+		return "", "", err
+	}
+	manifest, err := m.ChecksumManifest(ctx, version)
+	if err != nil {
+		return "", "", err
+	}
+	checksum := manifest.Checksum(assetName)
+	if checksum == "" {
+		return "", "", clierror.Wrap(clierror.Integrity, fmt.Errorf("checksum manifest of %s does not list %s", version, assetName))
+	}
+	return downloadURL, checksum, nil
+}
+
+// releaseFile is a file of a pinned agent release fetched by
+// InstallFixPanicAgentVersion
+type releaseFile struct {
+	url string
+	// binary is set for the agent binary, which is written next to the
+	// installed one; the other files are small and kept in memory
+	binary bool
+}
+
+// releaseFileResult is the outcome of fetching a releaseFile
+type releaseFileResult struct {
+	data     []byte
+	tmpFile  string
+	checksum string
+	err      error
+}
+
+// ChecksumManifest returns the checksum manifest of release version once its
+// signature verifies against one of the release signing keys
+func (m *Manager) ChecksumManifest(ctx context.Context, version string) (*releases.ChecksumManifest, error) {
+	return releases.FetchChecksumManifest(ctx, m.cache, version)
+}
+
+// SBOM returns the software bill of materials in format (see sbom.Formats)
+// published with agent release version, once its checksum matches the
+// release's checksum manifest
+func (m *Manager) SBOM(ctx context.Context, version, format string) ([]byte, error) {
+	name, err := sbom.AssetName(format)
+	if err != nil {
+		return nil, err
+	}
+	return releases.FetchVerifiedAsset(ctx, m.cache, version, name)
+}
+
+// InstallFixPanicAgentVersion downloads this platform's agent binary of
+// release version together with the release's checksum manifest and its
+// signature, all at once with their progress shown as one transfer, and
+// installs the binary only if the signed manifest lists its checksum. It
+// returns the checksum of the installed binary. A release without a
+// manifest or signature isn't installed.
+func (m *Manager) InstallFixPanicAgentVersion(ctx context.Context, version string) (string, error) {
+	assetName, err := platform.GetFixPanicAgentAssetName()
+	if err != nil {
+		return "", err
+	}
+	binaryURL, err := platform.GetFixPanicAgentDownloadURL(version)
+	if err != nil {
+		return "", err
+	}
+	if err := releases.RequireSigningKeys(version); err != nil {
+		return "", err
+	}
+	files := []releaseFile{
+		{url: binaryURL, binary: true},
+		{url: releases.DownloadURL(releases.AgentRepo, version, releases.ChecksumManifestName)},
+		{url: releases.DownloadURL(releases.AgentRepo, version, releases.SignatureName)},
+	}
+
+	logger.Loading("Downloading agent %s and its checksums...", version)
+	group := events.NewProgressGroup(filepath.Base(m.platform.GetFixPanicAgentBinaryPath()), func(read, total int64) {
+		if total > 0 {
+			logger.LoadingProgress("Downloading agent %s and its checksums... %s of %s",
+				version, diskspace.FormatBytes(uint64(read)), diskspace.FormatBytes(uint64(total)))
+		} else {
+			logger.LoadingProgress("Downloading agent %s and its checksums... %s", version, diskspace.FormatBytes(uint64(read)))
+		}
+	})
+	results := workpool.Map(ctx, files, len(files), func(ctx context.Context, file releaseFile) releaseFileResult {
+		return m.fetchReleaseFile(ctx, file, group)
+	})
+	binary, manifestResult, signatureResult := results[0], results[1], results[2]
+	discard := func() {
+		if binary.tmpFile != "" {
+			os.Remove(binary.tmpFile)
+		}
+	}
+	for i, result := range results {
+		if result.err != nil {
+			logger.LoadingFailed("Failed to download")
+			discard()
+			if i > 0 {
+				// A release without a manifest or signature fails verification
+				return "", releases.MissingFileError(result.err)
+			}
+			return "", result.err
+		}
+	}
+	logger.LoadingDone("")
+
+	manifest, err := releases.VerifyChecksumManifest(version, manifestResult.data, signatureResult.data)
+	if err != nil {
+		discard()
+		return "", err
+	}
+	expected := manifest.Checksum(assetName)
+	if expected == "" {
+		discard()
+		return "", clierror.Wrap(clierror.Integrity, fmt.Errorf("checksum manifest of %s does not list %s", version, assetName))
+	}
+	if !strings.EqualFold(binary.checksum, expected) {
+		discard()
+		return "", clierror.Wrap(clierror.Integrity, fmt.Errorf("checksum mismatch for %s: the manifest has %s, got %s", binaryURL, expected, binary.checksum))
+	}
+	return binary.checksum, m.placeAgent(ctx, binary.tmpFile)
+}
+
+// fetchReleaseFile downloads file, adding to the combined progress of group
+func (m *Manager) fetchReleaseFile(ctx context.Context, file releaseFile, group *events.ProgressGroup) releaseFileResult {
+	resp, err := m.cache.Get(ctx, m.client, file.url)
+	if err != nil {
+		return releaseFileResult{err: fmt.Errorf("failed to download %s: %w", file.url, err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return releaseFileResult{err: fmt.Errorf("%s is %w", file.url, releases.ErrNotPublished)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return releaseFileResult{err: fmt.Errorf("failed to download %s: HTTP %d", file.url, resp.StatusCode)}
+	}
+	body := group.Reader(resp.Body, resp.ContentLength)
+
+	if !file.binary {
+		data, err := io.ReadAll(io.LimitReader(body, 1<<20))
+		if err != nil {
+			return releaseFileResult{err: fmt.Errorf("failed to download %s: %w", file.url, err)}
+		}
+		return releaseFileResult{data: data}
 	}
 
+	if resp.ContentLength > 0 {
+		if err := diskspace.Check(filepath.Dir(m.platform.GetFixPanicAgentBinaryPath()), uint64(resp.ContentLength)); err != nil {
+			return releaseFileResult{err: err}
+		}
+	}
+	tmpFile, checksum, err := m.writeAgentTemp(body)
+	return releaseFileResult{tmpFile: tmpFile, checksum: checksum, err: err}
+}
+
+// FetchChecksum downloads url without saving it and returns its SHA-256
+// checksum and size
+func (m *Manager) FetchChecksum(ctx context.Context, url string) (string, int64, error) {
+	resp, err := m.cache.Get(ctx, m.client, url)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("failed to download %s: HTTP %d", url, resp.StatusCode)
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, events.NewProgressReader(resp.Body, resp.ContentLength, filepath.Base(url)))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), size, nil
+}
+
+// DownloadArtifact saves url to dest and returns its SHA-256 checksum and
+// size. With a non-empty expectedSHA256 a download with a different checksum
+// is discarded.
+func (m *Manager) DownloadArtifact(ctx context.Context, url, dest, expectedSHA256 string) (checksum string, size int64, err error) {
+	span := trace.Start("download "+filepath.Base(dest), trace.KindDownload, nil)
+	defer func() {
+		span.SetAttribute("download.bytes", strconv.FormatInt(size, 10))
+		span.End(err)
+	}()
+
+	resp, err := m.cache.Get(ctx, m.client, url)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	span.SetAttribute("download.cached", strconv.FormatBool(resp.Header.Get(httpcache.HeaderCache) == "hit"))
+
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("failed to download %s: HTTP %d", url, resp.StatusCode)
+	}
+	if resp.ContentLength > 0 {
+		if err := diskspace.Check(filepath.Dir(dest), uint64(resp.ContentLength)); err != nil {
+			return "", 0, err
+		}
+	}
+
+	tmpFile := dest + ".tmp"
+	out, err := os.OpenFile(tmpFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	hash := sha256.New()
+	size, err = io.Copy(io.MultiWriter(out, hash), events.NewProgressReader(resp.Body, resp.ContentLength, filepath.Base(dest)))
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpFile)
+		return "", 0, fmt.Errorf("failed to save %s: %w", filepath.Base(dest), err)
+	}
+
+	checksum = fmt.Sprintf("%x", hash.Sum(nil))
+	if expectedSHA256 != "" && !strings.EqualFold(checksum, expectedSHA256) {
+		os.Remove(tmpFile)
+		return "", 0, clierror.Wrap(clierror.Integrity, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", url, expectedSHA256, checksum))
+	}
+	if err := os.Rename(tmpFile, dest); err != nil {
+		os.Remove(tmpFile)
+		return "", 0, fmt.Errorf("failed to save %s: %w", filepath.Base(dest), err)
+	}
+	return checksum, size, nil
+}
+
+// downloadAgent downloads the agent binary and moves it into place. With a
+// non-empty expectedSHA256 a binary with a different checksum is discarded.
+func (m *Manager) downloadAgent(ctx context.Context, downloadURL, expectedSHA256 string) (err error) {
 	binaryPath := m.platform.GetFixPanicAgentBinaryPath()
+	span := trace.Start("download "+filepath.Base(binaryPath), trace.KindDownload, nil)
+	defer func() { span.End(err) }()
 
 	logger.Loading("Downloading from %s...", downloadURL)
 
-	// Create temporary file
-	tmpFile := binaryPath + ".tmp"
-
-	resp, err := m.client.Get(downloadURL)
+	resp, err := m.cache.Get(ctx, m.client, downloadURL)
 	if err != nil {
 		logger.LoadingFailed("Failed to download")
 		return fmt.Errorf("failed to download binary: %w", err)
 	}
 	defer resp.Body.Close()
+	span.SetAttribute("download.cached", strconv.FormatBool(resp.Header.Get(httpcache.HeaderCache) == "hit"))
 
 	if resp.StatusCode != http.StatusOK {
 		logger.LoadingFailed("HTTP %d", resp.StatusCode)
 		return fmt.Errorf("failed to download binary: HTTP %d", resp.StatusCode)
 	}
 
-	logger.LoadingDone("Download started")
+	// Fail before writing anything when the binary can't fit next to the
+	// installed one
+	if resp.ContentLength > 0 {
+		if err := diskspace.Check(filepath.Dir(binaryPath), uint64(resp.ContentLength)); err != nil {
+			logger.LoadingFailed("Not enough disk space")
+			return err
+		}
+	}
+
+	if resp.Header.Get(httpcache.HeaderCache) == "hit" {
+		logger.LoadingDone("Using cached download")
+	} else {
+		logger.LoadingDone("Download started")
+	}
+
+	body := events.NewProgressReader(resp.Body, resp.ContentLength, filepath.Base(binaryPath))
+	return m.installAgent(ctx, body, downloadURL, expectedSHA256)
+}
+
+// InstallFixPanicAgentFile installs the agent binary at path, e.g. from an
+// artifact bundle, if its SHA-256 checksum is expectedSHA256
+func (m *Manager) InstallFixPanicAgentFile(ctx context.Context, path, expectedSHA256 string) error {
+	if expectedSHA256 == "" {
+		return fmt.Errorf("no checksum given for %s", path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open agent binary: %w", err)
+	}
+	defer file.Close()
+
+	if info, err := file.Stat(); err == nil {
+		if err := diskspace.Check(filepath.Dir(m.platform.GetFixPanicAgentBinaryPath()), uint64(info.Size())); err != nil {
+			return err
+		}
+	}
+	return m.installAgent(ctx, file, path, expectedSHA256)
+}
+
+// installAgent writes the agent binary read from r, downloaded from or
+// copied from source, and moves it into place. With a non-empty
+// expectedSHA256 a binary with a different checksum is discarded.
+func (m *Manager) installAgent(ctx context.Context, r io.Reader, source, expectedSHA256 string) error {
+	tmpFile, actual, err := m.writeAgentTemp(r)
+	if err != nil {
+		return err
+	}
+	if expectedSHA256 != "" && !strings.EqualFold(actual, expectedSHA256) {
+		os.Remove(tmpFile)
+		return clierror.Wrap(clierror.Integrity, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", source, expectedSHA256, actual))
+	}
+	return m.placeAgent(ctx, tmpFile)
+}
+
+// writeAgentTemp writes the agent binary read from r next to the installed
+// one and returns the temporary file and its SHA-256 checksum
+func (m *Manager) writeAgentTemp(r io.Reader) (string, string, error) {
+	tmpFile := m.platform.GetFixPanicAgentBinaryPath() + ".tmp"
 
-	// Create the file
-	out, err := os.Create(tmpFile)
+	// Create the file executable, leaving the umask to trim the mode; remove
+	// any stale partial download first since an existing file keeps its mode
+	os.Remove(tmpFile)
+	out, err := os.OpenFile(tmpFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
 	if err != nil {
-		return fmt.Errorf("failed to create temporary file: %w", err)
+		return "", "", fmt.Errorf("failed to create temporary file: %w", err)
 	}
 
 	// Write the body to file
-	_, err = io.Copy(out, resp.Body)
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(out, hash), r)
 	if err != nil {
 		out.Close()
 		os.Remove(tmpFile)
-		return fmt.Errorf("failed to save binary: %w", err)
+		return "", "", fmt.Errorf("failed to save binary: %w", err)
 	}
 
 	// Sync to ensure all data is written to disk before closing
 	if err := out.Sync(); err != nil {
 		out.Close()
 		os.Remove(tmpFile)
-		return fmt.Errorf("failed to sync file to disk: %w", err)
+		return "", "", fmt.Errorf("failed to sync file to disk: %w", err)
 	}
 
 	// Close the file before chmod and rename
 	if err := out.Close(); err != nil {
 		os.Remove(tmpFile)
-		return fmt.Errorf("failed to close file: %w", err)
+		return "", "", fmt.Errorf("failed to close file: %w", err)
 	}
+	return tmpFile, fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
 
-	// Make the binary executable
-	if err := os.Chmod(tmpFile, 0755); err != nil {
-		os.Remove(tmpFile)
-		return fmt.Errorf("failed to make binary executable: %w", err)
-	}
+// placeAgent moves the verified agent binary written by writeAgentTemp into
+// place
+func (m *Manager) placeAgent(ctx context.Context, tmpFile string) error {
+	binaryPath := m.platform.GetFixPanicAgentBinaryPath()
 
 	// On macOS, remove quarantine attribute to allow execution
 	if runtime.GOOS == "darwin" {
-		if err := exec.Command("xattr", "-d", "com.apple.quarantine", tmpFile).Run(); err != nil {
+		if err := exec.CommandContext(ctx, "xattr", "-d", "com.apple.quarantine", tmpFile).Run(); err != nil {
 			// Log warning but don't fail - quarantine removal is not critical
 			logger.Warning("Failed to remove quarantine attribute: %v", err)
 		}
 	}
 
-	// Move to final location
-	if err := os.Rename(tmpFile, binaryPath); err != nil {
-		os.Remove(tmpFile)
-		return fmt.Errorf("failed to move binary to final location: %w", err)
+	// Move to final location; a second Ctrl+C waits for the swap
+	err := interrupt.Critical(func() error {
+		if err := os.Rename(tmpFile, binaryPath); err != nil {
+			os.Remove(tmpFile)
+			return fmt.Errorf("failed to move binary to final location: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	logger.Success("FixPanic Agent downloaded to %s", binaryPath)
+	logger.Success("FixPanic Agent installed to %s", binaryPath)
 	return nil
 }
 
@@ -232,7 +515,7 @@ func (m *Manager) IsFixPanicAgentInstalled() bool {
 }
 
 // GetFixPanicAgentVersion returns the version of the installed FixPanic Agent
-func (m *Manager) GetFixPanicAgentVersion() (string, error) {
+func (m *Manager) GetFixPanicAgentVersion(ctx context.Context) (string, error) {
 	binaryPath := m.platform.GetFixPanicAgentBinaryPath()
 
 	if !m.IsFixPanicAgentInstalled() {
@@ -240,7 +523,7 @@ func (m *Manager) GetFixPanicAgentVersion() (string, error) {
 	}
 
 	// Execute with --version flag
-	cmd := exec.Command(binaryPath, "--version")
+	cmd := exec.CommandContext(ctx, binaryPath, "--version")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get version: %w", err)
@@ -250,20 +533,15 @@ func (m *Manager) GetFixPanicAgentVersion() (string, error) {
 }
 
 // UpdateFixPanicAgent updates the FixPanic Agent to the specified version
-func (m *Manager) UpdateFixPanicAgent(version string) error {
-	fmt.Printf("Updating FixPanic Agent to version %s...\n", version)
-
-	// Remove old version
-	if err := m.RemoveFixPanicAgent(); err != nil {
-		return fmt.Errorf("failed to remove old version: %w", err)
-	}
+func (m *Manager) UpdateFixPanicAgent(ctx context.Context, version string) error {
+	fmt.Fprintf(logger.Messages(), "Updating FixPanic Agent to version %s...\n", version)
 
-	// Download new version
-	if err := m.DownloadFixPanicAgent(version); err != nil {
+	// The old version is only replaced once the new one is verified
+	if err := m.DownloadFixPanicAgent(ctx, version); err != nil {
 		return fmt.Errorf("failed to download new version: %w", err)
 	}
 
-	fmt.Printf("FixPanic Agent updated successfully\n")
+	fmt.Fprintf(logger.Messages(), "FixPanic Agent updated successfully\n")
 	return nil
 }
 
@@ -283,80 +561,132 @@ func (m *Manager) RemoveFixPanicAgent() error {
 
 // Update updates the connectivity layer to the specified version (DEPRECATED)
 // TODO: Remove this function after migration to UpdateFixPanicAgent
-func (m *Manager) Update(version string) error {
-	fmt.Println("WARNING: Update() is deprecated, use UpdateFixPanicAgent() instead")
-	return m.UpdateFixPanicAgent(version)
+func (m *Manager) Update(ctx context.Context, version string) error {
+	logger.Deprecated("Update()", "UpdateFixPanicAgent()")
+	return m.UpdateFixPanicAgent(ctx, version)
 }
 
+// agentReleasesURL is the GitHub API endpoint of the agent releases
+const agentReleasesURL = "https://api.github.com/repos/fixpanic/fixpanic-connectivity-layer-release/releases"
+
 // AgentRelease represents a GitHub release for the agent binary
 type AgentRelease struct {
-	TagName     string `json:"tag_name"`
-	Name        string `json:"name"`
-	PublishedAt string `json:"published_at"`
+	TagName     string       `json:"tag_name"`
+	Name        string       `json:"name"`
+	PublishedAt string       `json:"published_at"`
+	Assets      []AgentAsset `json:"assets"`
+}
+
+// AgentAsset is a file attached to an agent release
+type AgentAsset struct {
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	// Digest is "sha256:<hex>" for assets uploaded since GitHub started
+	// recording digests, empty otherwise
+	Digest string `json:"digest"`
+}
+
+// Asset returns the asset called name, or nil
+func (r *AgentRelease) Asset(name string) *AgentAsset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
 }
 
 // GetLatestAgentVersion fetches the latest agent version from GitHub releases
-func (m *Manager) GetLatestAgentVersion() (string, error) {
+func (m *Manager) GetLatestAgentVersion(ctx context.Context) (string, error) {
+	release, err := m.GetAgentRelease(ctx, "latest")
+	if err != nil {
+		return "", err
+	}
+	return release.TagName, nil
+}
+
+// GetAgentRelease fetches the agent release tagged version, or the latest
+// release for "latest"
+func (m *Manager) GetAgentRelease(ctx context.Context, version string) (*AgentRelease, error) {
 	client := &http.Client{Timeout: 10 * time.Second}
 
-	url := "https://api.github.com/repos/fixpanic/fixpanic-connectivity-layer-release/releases/latest"
+	url := agentReleasesURL + "/latest"
+	if version != "latest" {
+		url = agentReleasesURL + "/tags/" + version
+	}
 
-	resp, err := client.Get(url)
+	resp, err := m.cache.Get(ctx, client, url)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch latest release: %w", err)
+		return nil, fmt.Errorf("failed to fetch %s release: %w", version, err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound && version != "latest" {
+		return nil, fmt.Errorf("agent release %s not found", version)
+	}
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("GitHub API request failed: %d", resp.StatusCode)
+		return nil, fmt.Errorf("GitHub API request failed: %d", resp.StatusCode)
 	}
 
+	// Read the whole body so the response is cached for the next check
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s release: %w", version, err)
+	}
 	var release AgentRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return "", fmt.Errorf("failed to parse release info: %w", err)
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("failed to parse release info: %w", err)
 	}
 
-	return release.TagName, nil
+	return &release, nil
 }
 
 // IsAgentUpdateAvailable checks if a newer version of the agent is available
-func (m *Manager) IsAgentUpdateAvailable() (bool, string, error) {
+func (m *Manager) IsAgentUpdateAvailable(ctx context.Context) (bool, string, error) {
 	if !m.IsFixPanicAgentInstalled() {
 		return true, "", nil // Need to install
 	}
 
-	currentVersion, err := m.GetFixPanicAgentVersion()
+	currentVersion, err := m.GetFixPanicAgentVersion(ctx)
 	if err != nil {
 		return true, "", fmt.Errorf("failed to get current version: %w", err)
 	}
 
-	latestVersion, err := m.GetLatestAgentVersion()
+	latestVersion, err := m.GetLatestAgentVersion(ctx)
 	if err != nil {
 		return false, "", fmt.Errorf("failed to get latest version: %w", err)
 	}
 
 	// Parse version strings to compare them
 	// For simplicity, we'll do string comparison since they follow semantic versioning
-	currentClean := strings.TrimSpace(currentVersion)
+	currentClean := ParseAgentVersion(currentVersion)
 	latestClean := strings.TrimSpace(latestVersion)
 
-	// Extract version from output like "fixpanic-connectivity-layer v1.0.0 - ..."
-	if strings.Contains(currentClean, " v") {
-		parts := strings.Split(currentClean, " v")
+	return currentClean != latestClean, latestClean, nil
+}
+
+// ParseAgentVersion extracts the version tag from the agent's --version output,
+// e.g. "fixpanic-connectivity-layer v1.0.0 - ..." becomes "v1.0.0"
+func ParseAgentVersion(output string) string {
+	clean := strings.TrimSpace(output)
+
+	if strings.Contains(clean, " v") {
+		parts := strings.Split(clean, " v")
 		if len(parts) > 1 {
 			versionPart := strings.Split(parts[1], " ")[0]
-			currentClean = "v" + versionPart
+			clean = "v" + versionPart
 		}
 	}
 
-	return currentClean != latestClean, latestClean, nil
+	return clean
 }
 
 // EnsureLatestAgent checks and updates the agent binary if needed
-func (m *Manager) EnsureLatestAgent() error {
+func (m *Manager) EnsureLatestAgent(ctx context.Context) error {
 	logger.Progress("Checking for agent binary updates")
 
-	updateAvailable, latestVersion, err := m.IsAgentUpdateAvailable()
+	updateAvailable, latestVersion, err := m.IsAgentUpdateAvailable(ctx)
 	if err != nil {
 		logger.Warning("Failed to check for updates: %v", err)
 		// Continue with existing binary if update check fails
@@ -372,19 +702,20 @@ func (m *Manager) EnsureLatestAgent() error {
 
 	// Update or install the agent
 	if m.IsFixPanicAgentInstalled() {
-		currentVersion, _ := m.GetFixPanicAgentVersion()
+		currentVersion, _ := m.GetFixPanicAgentVersion(ctx)
 		logger.Info("Agent update available: %s → %s", currentVersion, latestVersion)
 		logger.Progress("Downloading latest agent binary")
 	} else {
 		logger.Progress("Installing agent binary")
 	}
 
-	if err := m.DownloadFixPanicAgent("latest"); err != nil {
+	if err := m.DownloadFixPanicAgent(ctx, latestVersion); err != nil {
 		return fmt.Errorf("failed to download latest agent: %w", err)
 	}
+	logger.Success("Checksum manifest signature verified")
 
 	// Verify the update
-	newVersion, err := m.GetFixPanicAgentVersion()
+	newVersion, err := m.GetFixPanicAgentVersion(ctx)
 	if err != nil {
 		logger.Warning("Failed to verify new version: %v", err)
 	} else {
@@ -0,0 +1,149 @@
+// Package controlplane talks to the FixPanic control-plane API on behalf of an
+// installed agent, authenticated with the agent's credentials
+package controlplane
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint is the base URL of the control-plane API
+const DefaultEndpoint = "https://api.fixpanic.com/v1"
+
+// EnvEndpoint overrides DefaultEndpoint
+const EnvEndpoint = "FIXPANIC_API_URL"
+
+// requestTimeout bounds a single API request
+const requestTimeout = 10 * time.Second
+
+// ErrUnauthorized is returned when the control plane rejects the agent's
+// credentials
+var ErrUnauthorized = errors.New("the control plane rejected the agent ID or API key")
+
+// Command states reported by the control plane
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Command is a command the control plane runs on the host through the agent
+type Command struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	ExitCode int    `json:"exit_code"`
+	Output   string `json:"output"`
+	// Error explains a failed command, e.g. that the agent is offline
+	Error string `json:"error,omitempty"`
+}
+
+// Done reports whether the command has finished, successfully or not
+func (c *Command) Done() bool {
+	return c.Status == StatusCompleted || c.Status == StatusFailed
+}
+
+// Client calls the control-plane API as one agent
+type Client struct {
+	endpoint string
+	agentID  string
+	apiKey   string
+}
+
+// NewClient returns a client authenticated as the agent agentID
+func NewClient(agentID, apiKey string) *Client {
+	endpoint := os.Getenv(EnvEndpoint)
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	return &Client{endpoint: strings.TrimSuffix(endpoint, "/"), agentID: agentID, apiKey: apiKey}
+}
+
+// Endpoint returns the base URL the client calls
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+// RunCommand asks the control plane to run name with args on the host
+// through the agent. The command runs asynchronously; poll it with Command.
+func (c *Client) RunCommand(ctx context.Context, name string, args []string) (*Command, error) {
+	request := struct {
+		Command string   `json:"command"`
+		Args    []string `json:"args"`
+	}{name, args}
+	var command Command
+	if err := c.do(ctx, http.MethodPost, c.agentPath("commands"), request, &command); err != nil {
+		return nil, fmt.Errorf("failed to request the command: %w", err)
+	}
+	return &command, nil
+}
+
+// Command returns the current state of a command requested with RunCommand
+func (c *Client) Command(ctx context.Context, id string) (*Command, error) {
+	var command Command
+	if err := c.do(ctx, http.MethodGet, c.agentPath("commands", id), nil, &command); err != nil {
+		return nil, fmt.Errorf("failed to get the command status: %w", err)
+	}
+	return &command, nil
+}
+
+// agentPath returns the URL of a resource below the client's agent
+func (c *Client) agentPath(elements ...string) string {
+	path := c.endpoint + "/agents/" + url.PathEscape(c.agentID)
+	for _, element := range elements {
+		path += "/" + url.PathEscape(element)
+	}
+	return path
+}
+
+// do sends a request with a JSON body (unless nil) and decodes the JSON
+// response into result
+func (c *Client) do(ctx context.Context, method, target string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, c.endpoint)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", c.endpoint, err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Package crash writes reports for unexpected CLI panics and submits them to
+// the FixPanic API on request.
+package crash
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint receives submitted crash reports
+const DefaultEndpoint = "https://api.fixpanic.com/v1/cli/crash-reports"
+
+// EnvEndpoint overrides DefaultEndpoint
+const EnvEndpoint = "FIXPANIC_CRASH_REPORT_URL"
+
+// submitTimeout bounds how long submitting a report may take
+const submitTimeout = 10 * time.Second
+
+// Report describes a panic
+type Report struct {
+	Time       time.Time `json:"time"`
+	Panic      string    `json:"panic"`
+	Stack      string    `json:"stack"`
+	Command    string    `json:"command"`
+	CLIVersion string    `json:"cli_version"`
+	Commit     string    `json:"commit,omitempty"`
+	GoVersion  string    `json:"go_version"`
+	OS         string    `json:"os"`
+	Arch       string    `json:"arch"`
+}
+
+// String renders the report as plain text
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "FixPanic CLI crash report\n\n")
+	fmt.Fprintf(&b, "Time:        %s\n", r.Time.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Command:     %s\n", r.Command)
+	fmt.Fprintf(&b, "CLI version: %s (commit: %s)\n", r.CLIVersion, r.Commit)
+	fmt.Fprintf(&b, "Go version:  %s\n", r.GoVersion)
+	fmt.Fprintf(&b, "Platform:    %s/%s\n", r.OS, r.Arch)
+	fmt.Fprintf(&b, "Panic:       %s\n\n", r.Panic)
+	b.WriteString(r.Stack)
+	return b.String()
+}
+
+// Write saves the report in dir and returns its path. If dir isn't writable
+// (e.g. the system log directory for a non-root user) the temp directory is
+// used instead.
+func Write(dir string, report *Report) (string, error) {
+	name := fmt.Sprintf("crash-%s.txt", report.Time.Format("20060102-150405"))
+
+	var lastErr error
+	for _, candidate := range []string{dir, os.TempDir()} {
+		if err := os.MkdirAll(candidate, 0755); err != nil {
+			lastErr = err
+			continue
+		}
+		path := filepath.Join(candidate, name)
+		if err := os.WriteFile(path, []byte(report.String()), 0600); err != nil {
+			lastErr = err
+			continue
+		}
+		return path, nil
+	}
+	return "", fmt.Errorf("failed to write crash report: %w", lastErr)
+}
+
+// Submit sends the report to the crash report endpoint
+func Submit(ctx context.Context, report *Report) error {
+	endpoint := os.Getenv(EnvEndpoint)
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode crash report: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, submitTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to submit crash report: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to submit crash report: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
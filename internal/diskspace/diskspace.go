@@ -0,0 +1,77 @@
+// Package diskspace checks free space on the file system holding a path, so
+// downloads can fail early with a clear message instead of a partial write.
+package diskspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// errUnsupported is returned by free on platforms without a free space query
+var errUnsupported = errors.New("free space query not supported on this platform")
+
+// InsufficientError reports that a file system doesn't have room for a download
+type InsufficientError struct {
+	Path     string
+	Required uint64
+	Free     uint64
+}
+
+// Error implements the error interface
+func (e *InsufficientError) Error() string {
+	return fmt.Sprintf("not enough free space in %s: %s required, %s available", e.Path, FormatBytes(e.Required), FormatBytes(e.Free))
+}
+
+// Free returns the bytes available to the current user on the file system
+// holding path. Paths that don't exist yet are resolved to their closest
+// existing parent.
+func Free(path string) (uint64, error) {
+	return free(existingParent(path))
+}
+
+// Check returns an *InsufficientError when the file system holding path has
+// less than required bytes available. It returns nil when the free space can't
+// be determined, so an unsupported platform never blocks a download.
+func Check(path string, required uint64) error {
+	available, err := Free(path)
+	if err != nil {
+		return nil
+	}
+	if available < required {
+		return &InsufficientError{Path: path, Required: required, Free: available}
+	}
+	return nil
+}
+
+// existingParent returns path or its closest ancestor that exists
+func existingParent(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// FormatBytes renders a byte count for humans
+func FormatBytes(bytes uint64) string {
+	switch {
+	case bytes >= 1024*1024*1024:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/(1024*1024*1024))
+	case bytes >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+	case bytes >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/1024)
+	default:
+		return fmt.Sprintf("%d B", bytes)
+	}
+}
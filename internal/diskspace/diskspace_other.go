@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package diskspace
+
+// free is not implemented on this platform
+func free(path string) (uint64, error) {
+	return 0, errUnsupported
+}
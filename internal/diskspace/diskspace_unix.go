@@ -0,0 +1,24 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package diskspace
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// free queries statfs for the blocks available to unprivileged users
+func free(path string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, fmt.Errorf("failed to query free space of %s: %w", path, err)
+	}
+	// Field types differ between platforms; FreeBSD reports Bavail signed and
+	// it goes negative once the root reserve is in use
+	if int64(stat.Bavail) <= 0 {
+		return 0, nil
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
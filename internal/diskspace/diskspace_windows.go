@@ -0,0 +1,23 @@
+//go:build windows
+// +build windows
+
+package diskspace
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// free queries GetDiskFreeSpaceEx, which honours per-user quotas
+func free(path string) (uint64, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var available, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(name, &available, &total, &totalFree); err != nil {
+		return 0, fmt.Errorf("failed to query free space of %s: %w", path, err)
+	}
+	return available, nil
+}
@@ -0,0 +1,167 @@
+// Package events writes machine-readable progress events as line-delimited
+// JSON so GUI wrappers and CI dashboards can follow a command without
+// scraping its human-readable output. Events are only written once a sink
+// has been opened with Open.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/summary"
+)
+
+// Event types
+const (
+	CommandStarted   = "command_started"
+	CommandCompleted = "command_completed"
+	StepStarted      = "step_started"
+	StepCompleted    = "step_completed"
+	Progress         = "progress"
+	Download         = "download"
+	Warning          = "warning"
+)
+
+// Event is a single line of the event stream. Fields that don't apply to an
+// event type are omitted.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Command string    `json:"command,omitempty"`
+	Step    int       `json:"step,omitempty"`
+	Message string    `json:"message,omitempty"`
+	// Download progress; Total is 0 when the server didn't send a length
+	Bytes   int64   `json:"bytes,omitempty"`
+	Total   int64   `json:"total,omitempty"`
+	Percent float64 `json:"percent,omitempty"`
+	// Command completion
+	Success    *bool  `json:"success,omitempty"`
+	ExitCode   int    `json:"exit_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	// Steps summarizes the steps of a completed multi-step command
+	Steps []summary.Step `json:"steps,omitempty"`
+}
+
+var (
+	mu      sync.Mutex
+	sink    io.WriteCloser
+	encoder *json.Encoder
+	// step is the number of the step in progress, 0 if none
+	step        int
+	stepMessage string
+)
+
+// Open starts writing events to w. Closing the stream closes w.
+func Open(w io.WriteCloser) {
+	mu.Lock()
+	defer mu.Unlock()
+	sink = w
+	encoder = json.NewEncoder(w)
+}
+
+// OpenFD writes events to an inherited file descriptor, e.g. --events-fd=3
+// with the wrapper reading the other end of a pipe
+func OpenFD(fd int) error {
+	if fd < 3 {
+		return fmt.Errorf("invalid events file descriptor %d: 0-2 are reserved for stdin, stdout and stderr", fd)
+	}
+	file := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+	if file == nil {
+		return fmt.Errorf("invalid events file descriptor %d", fd)
+	}
+	if _, err := file.Stat(); err != nil {
+		return fmt.Errorf("events file descriptor %d is not open: %w", fd, err)
+	}
+	Open(file)
+	return nil
+}
+
+// OpenFile appends events to path, creating it if needed
+func OpenFile(path string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open events file: %w", err)
+	}
+	Open(file)
+	return nil
+}
+
+// Enabled reports whether an event sink is open
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return encoder != nil
+}
+
+// Emit writes an event. Write errors are ignored: a consumer going away must
+// never fail the command itself.
+func Emit(event Event) {
+	mu.Lock()
+	defer mu.Unlock()
+	emitLocked(event)
+}
+
+func emitLocked(event Event) {
+	if encoder == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	if err := encoder.Encode(event); err != nil {
+		// Stop writing to a broken pipe instead of failing on every event
+		encoder = nil
+	}
+}
+
+// StartStep completes the step in progress, if any, and starts a new one
+func StartStep(number int, message string) {
+	mu.Lock()
+	defer mu.Unlock()
+	completeStepLocked()
+	step, stepMessage = number, message
+	emitLocked(Event{Type: StepStarted, Step: number, Message: message})
+}
+
+func completeStepLocked() {
+	if step == 0 {
+		return
+	}
+	emitLocked(Event{Type: StepCompleted, Step: step, Message: stepMessage})
+	step, stepMessage = 0, ""
+}
+
+// Complete ends the stream with a command_completed event carrying the
+// summary of the command's steps, and closes the sink. The last step is only
+// reported as completed when the command succeeded.
+func Complete(command string, exitCode int, err error, duration time.Duration, steps []summary.Step) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	success := err == nil
+	if success {
+		completeStepLocked()
+	}
+	event := Event{
+		Type:       CommandCompleted,
+		Command:    command,
+		Success:    &success,
+		ExitCode:   exitCode,
+		DurationMS: duration.Milliseconds(),
+		Steps:      steps,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	emitLocked(event)
+
+	if sink != nil {
+		sink.Close()
+	}
+	sink, encoder = nil, nil
+}
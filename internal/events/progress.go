@@ -0,0 +1,147 @@
+package events
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// progressInterval throttles download events for transfers of unknown length
+const progressInterval = 500 * time.Millisecond
+
+// progressReader emits download events while a body is read
+type progressReader struct {
+	r           io.Reader
+	name        string
+	total       int64
+	read        int64
+	emitted     int64
+	lastPercent int
+	lastEmit    time.Time
+}
+
+// NewProgressReader wraps r so reading it emits download events for name.
+// total is the expected size, or <= 0 if unknown. Events are emitted for every
+// whole percent, or every half second when the size is unknown. Without an
+// open sink r is returned unchanged.
+func NewProgressReader(r io.Reader, total int64, name string) io.Reader {
+	if !Enabled() {
+		return r
+	}
+	if total < 0 {
+		total = 0
+	}
+	Emit(Event{Type: Download, Message: name, Total: total})
+	return &progressReader{r: r, name: name, total: total, lastPercent: -1, lastEmit: time.Now()}
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	p.read += int64(n)
+
+	done := err == io.EOF
+	if p.read == p.emitted {
+		return n, err
+	}
+	if p.total > 0 {
+		percent := int(p.read * 100 / p.total)
+		if percent != p.lastPercent || done {
+			p.lastPercent = percent
+			p.emit()
+		}
+	} else if done || time.Since(p.lastEmit) >= progressInterval {
+		p.lastEmit = time.Now()
+		p.emit()
+	}
+
+	return n, err
+}
+
+func (p *progressReader) emit() {
+	p.emitted = p.read
+	event := Event{Type: Download, Message: p.name, Bytes: p.read, Total: p.total}
+	if p.total > 0 {
+		event.Percent = float64(p.read*1000/p.total) / 10
+	}
+	Emit(event)
+}
+
+// ProgressGroup combines the progress of downloads running concurrently into
+// one transfer: download events for name carry the bytes read by all of
+// them, and onProgress, if set, is called with the combined progress. The
+// total is only known once every download has reported its size.
+type ProgressGroup struct {
+	name       string
+	onProgress func(read, total int64)
+
+	mu          sync.Mutex
+	read        int64
+	total       int64
+	unknown     bool
+	lastPercent int
+	lastEmit    time.Time
+}
+
+// NewProgressGroup starts a combined transfer called name
+func NewProgressGroup(name string, onProgress func(read, total int64)) *ProgressGroup {
+	return &ProgressGroup{name: name, onProgress: onProgress, lastPercent: -1, lastEmit: time.Now()}
+}
+
+// Reader wraps r, one of the downloads of the group of expected size total
+// (<= 0 if unknown), so reading it adds to the combined progress
+func (g *ProgressGroup) Reader(r io.Reader, total int64) io.Reader {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if total > 0 {
+		g.total += total
+	} else {
+		g.unknown = true
+	}
+	return &groupReader{r: r, group: g}
+}
+
+// add records n more bytes read and reports the combined progress when it
+// moved by a whole percent, or every half second when the total is unknown
+func (g *ProgressGroup) add(n int64, done bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.read += n
+
+	total := g.total
+	if g.unknown {
+		total = 0
+	}
+	if total > 0 {
+		percent := int(g.read * 100 / total)
+		if percent == g.lastPercent && !done {
+			return
+		}
+		g.lastPercent = percent
+	} else if !done && time.Since(g.lastEmit) < progressInterval {
+		return
+	}
+	g.lastEmit = time.Now()
+
+	event := Event{Type: Download, Message: g.name, Bytes: g.read, Total: total}
+	if total > 0 {
+		event.Percent = float64(g.read*1000/total) / 10
+	}
+	Emit(event)
+	if g.onProgress != nil {
+		g.onProgress(g.read, total)
+	}
+}
+
+// groupReader is one download of a ProgressGroup
+type groupReader struct {
+	r     io.Reader
+	group *ProgressGroup
+}
+
+func (r *groupReader) Read(buf []byte) (int, error) {
+	n, err := r.r.Read(buf)
+	if n > 0 || err == io.EOF {
+		r.group.add(int64(n), err == io.EOF)
+	}
+	return n, err
+}
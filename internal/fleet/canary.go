@@ -0,0 +1,64 @@
+package fleet
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CanaryCount returns how many of hosts a --canary of spec upgrades first:
+// a percentage such as "10%", rounded up so at least one host is a canary,
+// or a number of hosts
+func CanaryCount(spec string, hosts int) (int, error) {
+	spec = strings.TrimSpace(spec)
+	if percent, ok := strings.CutSuffix(spec, "%"); ok {
+		value, err := strconv.ParseFloat(percent, 64)
+		if err != nil || value <= 0 || value > 100 {
+			return 0, fmt.Errorf("invalid canary %q: expected a percentage between 0%% and 100%% such as 10%%", spec)
+		}
+		return int(math.Ceil(value / 100 * float64(hosts))), nil
+	}
+	count, err := strconv.Atoi(spec)
+	if err != nil || count <= 0 {
+		return 0, fmt.Errorf("invalid canary %q: expected a percentage such as 10%% or a number of hosts", spec)
+	}
+	if count > hosts {
+		count = hosts
+	}
+	return count, nil
+}
+
+// CheckUpgraded returns why the agent of snapshot isn't healthy at version
+// after an upgrade at upgradedAt, or nil if it is: installed at version,
+// running, and connected to the socket server since the upgrade
+func CheckUpgraded(snapshot *Snapshot, version string, upgradedAt time.Time) error {
+	switch {
+	case !snapshot.Installed:
+		return fmt.Errorf("the agent is not installed")
+	case !SameVersion(snapshot.AgentVersion, version):
+		return fmt.Errorf("the agent is at %s instead of %s", orUnknown(snapshot.AgentVersion), version)
+	case !snapshot.Running:
+		return fmt.Errorf("the agent is not running")
+	case snapshot.LastHeartbeat == nil || snapshot.LastHeartbeat.Before(upgradedAt):
+		return fmt.Errorf("the agent has not reached the socket server since the upgrade")
+	}
+	if len(snapshot.Problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(snapshot.Problems, "; "))
+	}
+	return nil
+}
+
+// SameVersion reports whether a and b name the same release, with or without
+// the "v" prefix
+func SameVersion(a, b string) bool {
+	return a != "" && strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
+}
+
+func orUnknown(version string) string {
+	if version == "" {
+		return "an unknown version"
+	}
+	return version
+}
@@ -0,0 +1,82 @@
+package fleet
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCanaryCount(t *testing.T) {
+	tests := []struct {
+		spec    string
+		hosts   int
+		want    int
+		wantErr bool
+	}{
+		{"10%", 100, 10, false},
+		{"10%", 5, 1, false},
+		{"25%", 10, 3, false},
+		{"100%", 7, 7, false},
+		{"0.5%", 1000, 5, false},
+		{"3", 10, 3, false},
+		{"30", 10, 10, false},
+		{"0%", 10, 0, true},
+		{"150%", 10, 0, true},
+		{"0", 10, 0, true},
+		{"-2", 10, 0, true},
+		{"ten", 10, 0, true},
+		{"%", 10, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := CanaryCount(tt.spec, tt.hosts)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CanaryCount(%q, %d) error = %v, want error %v", tt.spec, tt.hosts, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("CanaryCount(%q, %d) = %d, want %d", tt.spec, tt.hosts, got, tt.want)
+		}
+	}
+}
+
+func TestCheckUpgraded(t *testing.T) {
+	upgradedAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	before := upgradedAt.Add(-time.Minute)
+	after := upgradedAt.Add(time.Minute)
+	healthy := func() *Snapshot {
+		return &Snapshot{Installed: true, AgentVersion: "v1.5.0", Running: true, LastHeartbeat: &after}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*Snapshot)
+		version string
+		wantErr string
+	}{
+		{name: "healthy", modify: func(*Snapshot) {}, version: "v1.5.0"},
+		{name: "version without v", modify: func(*Snapshot) {}, version: "1.5.0"},
+		{name: "not installed", modify: func(s *Snapshot) { s.Installed = false }, version: "v1.5.0", wantErr: "not installed"},
+		{name: "old version", modify: func(s *Snapshot) { s.AgentVersion = "v1.4.0" }, version: "v1.5.0", wantErr: "at v1.4.0 instead of v1.5.0"},
+		{name: "unknown version", modify: func(s *Snapshot) { s.AgentVersion = "" }, version: "v1.5.0", wantErr: "unknown version"},
+		{name: "not running", modify: func(s *Snapshot) { s.Running = false }, version: "v1.5.0", wantErr: "not running"},
+		{name: "no heartbeat", modify: func(s *Snapshot) { s.LastHeartbeat = nil }, version: "v1.5.0", wantErr: "socket server"},
+		{name: "heartbeat before upgrade", modify: func(s *Snapshot) { s.LastHeartbeat = &before }, version: "v1.5.0", wantErr: "socket server"},
+		{name: "problems", modify: func(s *Snapshot) { s.Problems = []string{"invalid configuration"} }, version: "v1.5.0", wantErr: "invalid configuration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := healthy()
+			tt.modify(snapshot)
+			err := CheckUpgraded(snapshot, tt.version, upgradedAt)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckUpgraded() = %v, want healthy", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckUpgraded() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+// Package fleet runs the CLI on many hosts over SSH and aggregates what they
+// report. It uses the system's ssh client, so the operator's keys, agent,
+// known_hosts and ~/.ssh/config apply unchanged.
+package fleet
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/trace"
+)
+
+// HostList is a parsed host list: the hosts in order and how to reach them
+type HostList struct {
+	Hosts []string
+	// Options of every host, with the defaults of "*" lines applied
+	Options map[string]HostOptions
+}
+
+// HostOptions say how to reach a host, for hosts whose ~/.ssh/config
+// entries don't. Unset fields leave ssh's own configuration in effect.
+type HostOptions struct {
+	// ProxyJump connects through these bastions, as ssh -J
+	ProxyJump string
+	Port      int
+	User      string
+	// IdentityFile is the private key to authenticate with
+	IdentityFile string
+	// ForwardAgent forwards the SSH agent to the host, for CLIs that reach
+	// further hosts from there
+	ForwardAgent *bool
+	// Sudo overrides Runner.Sudo: whether the CLI runs with "sudo -n"
+	Sudo *bool
+	// SSHOptions are extra ssh -o options, e.g. ServerAliveInterval=30
+	SSHOptions []string
+}
+
+// merge returns o with the fields set in override replacing its own; SSH
+// options are appended
+func (o HostOptions) merge(override HostOptions) HostOptions {
+	if override.ProxyJump != "" {
+		o.ProxyJump = override.ProxyJump
+	}
+	if override.Port != 0 {
+		o.Port = override.Port
+	}
+	if override.User != "" {
+		o.User = override.User
+	}
+	if override.IdentityFile != "" {
+		o.IdentityFile = override.IdentityFile
+	}
+	if override.ForwardAgent != nil {
+		o.ForwardAgent = override.ForwardAgent
+	}
+	if override.Sudo != nil {
+		o.Sudo = override.Sudo
+	}
+	o.SSHOptions = append(append([]string(nil), o.SSHOptions...), override.SSHOptions...)
+	return o
+}
+
+// LoadHostList reads a host list: one SSH destination (host, user@host or a
+// Host alias of ~/.ssh/config) per line, optionally followed by key=value
+// options (see parseHostOption). A "*" line sets options for every host.
+// Blank lines and # comments are skipped, as are repeated hosts.
+func LoadHostList(path string) (*HostList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read host list: %w", err)
+	}
+
+	list := &HostList{Options: make(map[string]HostOptions)}
+	var defaults HostOptions
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		content, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(content)
+		if len(fields) == 0 {
+			continue
+		}
+		host := fields[0]
+		// ssh would take a leading "-" as an option
+		if strings.HasPrefix(host, "-") || strings.Contains(host, "=") {
+			return nil, fmt.Errorf("%s:%d: %q is not an SSH destination", path, line, host)
+		}
+		var options HostOptions
+		for _, field := range fields[1:] {
+			if err := parseHostOption(&options, field); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+			}
+		}
+		if host == "*" {
+			defaults = defaults.merge(options)
+			continue
+		}
+		if _, seen := list.Options[host]; seen {
+			continue
+		}
+		list.Hosts = append(list.Hosts, host)
+		list.Options[host] = options
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read host list: %w", err)
+	}
+	if len(list.Hosts) == 0 {
+		return nil, fmt.Errorf("%s lists no hosts", path)
+	}
+	for host, options := range list.Options {
+		list.Options[host] = defaults.merge(options)
+	}
+	return list, nil
+}
+
+// parseHostOption sets the option of a host list line given as key=value:
+//
+//	proxy_jump     bastions to connect through, e.g. admin@bastion:2222
+//	port           SSH port
+//	user           remote user
+//	identity_file  private key
+//	forward_agent  yes or no
+//	sudo           yes or no, overriding --sudo
+//	ssh_option     any ssh -o option as Key=Value, repeatable
+func parseHostOption(options *HostOptions, field string) error {
+	key, value, ok := strings.Cut(field, "=")
+	if !ok || value == "" {
+		return fmt.Errorf("invalid option %q: expected key=value", field)
+	}
+	// Values become ssh arguments, which must not be taken as options
+	if strings.HasPrefix(value, "-") {
+		return fmt.Errorf("invalid %s %q", key, value)
+	}
+	switch key {
+	case "proxy_jump":
+		options.ProxyJump = value
+	case "port":
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port %q", value)
+		}
+		options.Port = port
+	case "user":
+		options.User = value
+	case "identity_file":
+		options.IdentityFile = value
+	case "forward_agent", "sudo":
+		enabled, err := parseYesNo(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+		if key == "sudo" {
+			options.Sudo = &enabled
+		} else {
+			options.ForwardAgent = &enabled
+		}
+	case "ssh_option":
+		if name, _, ok := strings.Cut(value, "="); !ok || name == "" {
+			return fmt.Errorf("invalid ssh_option %q: expected Key=Value", value)
+		}
+		options.SSHOptions = append(options.SSHOptions, value)
+	default:
+		return fmt.Errorf("unknown option %q", key)
+	}
+	return nil
+}
+
+// parseYesNo parses the yes/no values of host options
+func parseYesNo(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "true":
+		return true, nil
+	case "no", "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("%q is not yes or no", value)
+}
+
+// Runner runs the CLI on remote hosts over SSH
+type Runner struct {
+	// RemoteCLI is the fixpanic command on the remote hosts
+	RemoteCLI string
+	// Sudo runs the remote command with "sudo -n", which fails instead of
+	// prompting when the remote user needs a password
+	Sudo bool
+	// ConnectTimeout bounds establishing each SSH connection
+	ConnectTimeout time.Duration
+	// OTelEndpoint is passed on to the remote CLI while tracing
+	OTelEndpoint string
+	// Options say how to reach the hosts, keyed by host
+	Options map[string]HostOptions
+}
+
+// EnvOTelEndpoint sets the OpenTelemetry collector of the CLI
+const EnvOTelEndpoint = "FIXPANIC_OTEL_ENDPOINT"
+
+// Output runs the CLI with args on host and returns its standard output. The
+// error of a failed command carries the last line of its standard error.
+func (r *Runner) Output(ctx context.Context, host string, args ...string) ([]byte, error) {
+	var stdout bytes.Buffer
+	if err := r.Stream(ctx, host, &stdout, args...); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// Stream runs the CLI with args on host and copies its standard output to w
+// as it arrives. An error writing to w ends the run. While tracing, the run
+// is a span of its own that the remote CLI's spans join, exported to the same
+// collector unless the host configures its own.
+func (r *Runner) Stream(ctx context.Context, host string, w io.Writer, args ...string) (err error) {
+	span := trace.Start("ssh "+host, trace.KindHost, map[string]string{
+		"fleet.host":    host,
+		"fleet.command": strings.Join(args, " "),
+	})
+	defer func() { span.End(err) }()
+
+	remote := append([]string{r.RemoteCLI}, args...)
+	if span != nil {
+		env := []string{"env", trace.EnvTraceParent + "=" + span.TraceParent()}
+		if r.OTelEndpoint != "" {
+			env = append(env, EnvOTelEndpoint+"="+r.OTelEndpoint)
+		}
+		remote = append(env, remote...)
+	}
+	options := r.Options[host]
+	sudo := r.Sudo
+	if options.Sudo != nil {
+		sudo = *options.Sudo
+	}
+	if sudo {
+		remote = append([]string{"sudo", "-n"}, remote...)
+	}
+	for i, arg := range remote {
+		remote[i] = ShellQuote(arg)
+	}
+
+	sshArgs := r.sshArgs(options)
+	sshArgs = append(sshArgs, host, "--", strings.Join(remote, " "))
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ssh", sshArgs...)
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if message := lastLine(stderr.String()); message != "" {
+			return fmt.Errorf("%w: %s", err, message)
+		}
+		return err
+	}
+	return nil
+}
+
+// sshArgs returns the ssh options reaching a host with options
+func (r *Runner) sshArgs(options HostOptions) []string {
+	args := []string{"-o", "BatchMode=yes"}
+	if r.ConnectTimeout > 0 {
+		args = append(args, "-o", fmt.Sprintf("ConnectTimeout=%d", int(r.ConnectTimeout.Seconds())))
+	}
+	if options.ProxyJump != "" {
+		args = append(args, "-J", options.ProxyJump)
+	}
+	if options.Port != 0 {
+		args = append(args, "-p", strconv.Itoa(options.Port))
+	}
+	if options.User != "" {
+		args = append(args, "-l", options.User)
+	}
+	if options.IdentityFile != "" {
+		args = append(args, "-i", options.IdentityFile)
+	}
+	if options.ForwardAgent != nil {
+		if *options.ForwardAgent {
+			args = append(args, "-A")
+		} else {
+			args = append(args, "-a")
+		}
+	}
+	for _, option := range options.SSHOptions {
+		args = append(args, "-o", option)
+	}
+	return args
+}
+
+// ShellQuote quotes arg for a POSIX shell, such as the remote shell, leaving
+// arguments the shell wouldn't split or expand as they are
+func ShellQuote(arg string) string {
+	if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=./:,@") == "" {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// lastLine returns the last non-empty line of s
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
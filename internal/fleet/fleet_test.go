@@ -0,0 +1,206 @@
+package fleet
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"status", "status"},
+		{"--since=2025-01-01T12:00:00Z", "--since=2025-01-01T12:00:00Z"},
+		{"user@host:/path,x", "user@host:/path,x"},
+		{"", "''"},
+		{"two words", "'two words'"},
+		{"it's", `'it'\''s'`},
+		{"$(reboot)", "'$(reboot)'"},
+		{"`id`", "'`id`'"},
+		{"a;b", "'a;b'"},
+		{"a\nb", "'a\nb'"},
+		{"*", "'*'"},
+	}
+	for _, tt := range tests {
+		if got := ShellQuote(tt.arg); got != tt.want {
+			t.Errorf("ShellQuote(%q) = %q, want %q", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestShellQuoteRoundTrip(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh to run")
+	}
+	for _, arg := range []string{"plain", "", "two words", "it's", `"double"`, "$HOME", "$(id)", "`id`", "a;b|c&d", "back\\slash", "*?[x]", "new\nline", "'''"} {
+		out, err := exec.Command(sh, "-c", "printf %s "+ShellQuote(arg)).Output()
+		if err != nil {
+			t.Fatalf("sh failed for %q: %v", arg, err)
+		}
+		if string(out) != arg {
+			t.Errorf("the shell read %q as %q", arg, out)
+		}
+	}
+}
+
+func TestLoadHostList(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:    "hosts, comments and duplicates",
+			content: "# production\nweb-1\n\n  deploy@web-2  # primary\nweb-1\n",
+			want:    []string{"web-1", "deploy@web-2"},
+		},
+		{
+			name:    "option injection",
+			content: "web-1\n-oProxyCommand=reboot\n",
+			wantErr: ":2:",
+		},
+		{
+			name:    "option without value",
+			content: "web 1\n",
+			wantErr: `invalid option "1"`,
+		},
+		{
+			name:    "option as host",
+			content: "port=22 web-1\n",
+			wantErr: "is not an SSH destination",
+		},
+		{
+			name:    "defaults only",
+			content: "* proxy_jump=bastion\n",
+			wantErr: "lists no hosts",
+		},
+		{
+			name:    "empty",
+			content: "# nothing\n",
+			wantErr: "lists no hosts",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "hosts.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			list, err := LoadHostList(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadHostList() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadHostList() error = %v", err)
+			}
+			if !reflect.DeepEqual(list.Hosts, tt.want) {
+				t.Errorf("LoadHostList() hosts = %q, want %q", list.Hosts, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadHostListOptions(t *testing.T) {
+	content := `# all hosts go through the bastion
+* proxy_jump=admin@bastion:2222 sudo=yes ssh_option=ServerAliveInterval=30
+web-1
+web-2 port=2200 user=deploy forward_agent=yes sudo=no ssh_option=Compression=yes
+`
+	path := filepath.Join(t.TempDir(), "hosts.txt")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	list, err := LoadHostList(path)
+	if err != nil {
+		t.Fatalf("LoadHostList() error = %v", err)
+	}
+	if want := []string{"web-1", "web-2"}; !reflect.DeepEqual(list.Hosts, want) {
+		t.Fatalf("Hosts = %q, want %q", list.Hosts, want)
+	}
+
+	yes, no := true, false
+	want := map[string]HostOptions{
+		"web-1": {
+			ProxyJump:  "admin@bastion:2222",
+			Sudo:       &yes,
+			SSHOptions: []string{"ServerAliveInterval=30"},
+		},
+		"web-2": {
+			ProxyJump:    "admin@bastion:2222",
+			Port:         2200,
+			User:         "deploy",
+			ForwardAgent: &yes,
+			Sudo:         &no,
+			SSHOptions:   []string{"ServerAliveInterval=30", "Compression=yes"},
+		},
+	}
+	if !reflect.DeepEqual(list.Options, want) {
+		t.Errorf("Options = %+v, want %+v", list.Options, want)
+	}
+}
+
+func TestParseHostOption(t *testing.T) {
+	tests := []struct {
+		field   string
+		wantErr string
+	}{
+		{field: "proxy_jump=bastion"},
+		{field: "port=22"},
+		{field: "port=0", wantErr: "invalid port"},
+		{field: "port=ssh", wantErr: "invalid port"},
+		{field: "user=-oProxyCommand=reboot", wantErr: "invalid user"},
+		{field: "forward_agent=maybe", wantErr: "is not yes or no"},
+		{field: "sudo=yes"},
+		{field: "ssh_option=StrictHostKeyChecking=yes"},
+		{field: "ssh_option=Compression", wantErr: "expected Key=Value"},
+		{field: "proxy_jump=", wantErr: "expected key=value"},
+		{field: "jump=bastion", wantErr: "unknown option"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			var options HostOptions
+			err := parseHostOption(&options, tt.field)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("parseHostOption() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("parseHostOption() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunnerSSHArgs(t *testing.T) {
+	no := false
+	runner := &Runner{ConnectTimeout: 5 * time.Second}
+	got := runner.sshArgs(HostOptions{
+		ProxyJump:    "bastion",
+		Port:         2200,
+		User:         "deploy",
+		IdentityFile: "~/.ssh/fleet",
+		ForwardAgent: &no,
+		SSHOptions:   []string{"ServerAliveInterval=30"},
+	})
+	want := []string{
+		"-o", "BatchMode=yes", "-o", "ConnectTimeout=5",
+		"-J", "bastion", "-p", "2200", "-l", "deploy", "-i", "~/.ssh/fleet", "-a",
+		"-o", "ServerAliveInterval=30",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sshArgs() = %q, want %q", got, want)
+	}
+}
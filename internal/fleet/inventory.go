@@ -0,0 +1,88 @@
+package fleet
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/cloudmeta"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+)
+
+// InventorySchema is the version of the Inventory format. Fields are only
+// ever added; a field changing meaning gets a new name and a new schema.
+const InventorySchema = 1
+
+// Inventory is the host metadata 'fixpanic agent inventory' exports for
+// configuration management databases
+type Inventory struct {
+	Schema      int                `json:"schema"`
+	CollectedAt time.Time          `json:"collected_at"`
+	Hostname    string             `json:"hostname"`
+	OS          platform.OSRelease `json:"os"`
+	Kernel      string             `json:"kernel,omitempty"`
+	Arch        string             `json:"arch"`
+	// Environment is "host", "docker", "wsl2"... as detected by the CLI
+	Environment string `json:"environment"`
+	// Cloud is set on AWS, Google Cloud and Azure instances
+	Cloud      *cloudmeta.Instance `json:"cloud,omitempty"`
+	CLIVersion string              `json:"cli_version"`
+	Agent      InventoryAgent      `json:"agent"`
+}
+
+// InventoryAgent describes the agent installed on the host
+type InventoryAgent struct {
+	Installed bool   `json:"installed"`
+	AgentID   string `json:"agent_id,omitempty"`
+	Version   string `json:"version,omitempty"`
+	// InstalledAt is when the agent was installed, or last upgraded for
+	// agents installed by CLIs that didn't record installs
+	InstalledAt *time.Time `json:"installed_at,omitempty"`
+}
+
+// HostInventory is the inventory of one host of a fleet
+type HostInventory struct {
+	Host      string     `json:"host"`
+	Inventory *Inventory `json:"inventory,omitempty"`
+	// Error is why the inventory couldn't be collected
+	Error string `json:"error,omitempty"`
+}
+
+// inventoryHeader are the columns of the CSV inventory
+var inventoryHeader = []string{"host", "hostname", "os_id", "os_name", "os_version", "kernel", "arch", "environment",
+	"cloud_provider", "cloud_instance_id", "cloud_region", "cloud_account_id",
+	"agent_installed", "agent_id", "agent_version", "agent_installed_at", "cli_version", "error"}
+
+// WriteInventoryCSV writes one row per host, for CMDB importers that take CSV
+func WriteInventoryCSV(w io.Writer, hosts []HostInventory) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(inventoryHeader); err != nil {
+		return err
+	}
+	for _, host := range hosts {
+		row := make([]string, len(inventoryHeader))
+		row[0] = host.Host
+		row[len(row)-1] = host.Error
+		if inv := host.Inventory; inv != nil {
+			copy(row[1:], []string{inv.Hostname, inv.OS.ID, inv.OS.Name, inv.OS.Version, inv.Kernel, inv.Arch, inv.Environment})
+			if cloud := inv.Cloud; cloud != nil {
+				copy(row[8:], []string{cloud.Provider, cloud.InstanceID, cloud.Region, cloud.AccountID})
+			}
+			row[12] = "false"
+			if inv.Agent.Installed {
+				row[12] = "true"
+			}
+			row[13] = inv.Agent.AgentID
+			row[14] = inv.Agent.Version
+			if inv.Agent.InstalledAt != nil {
+				row[15] = inv.Agent.InstalledAt.UTC().Format(time.RFC3339)
+			}
+			row[16] = inv.CLIVersion
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,217 @@
+package fleet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LabelsFileName is the file in the fixpanic directory of the user's
+// configuration directory that holds the host labels
+const LabelsFileName = "fleet-labels.json"
+
+// labelKeyPattern matches label keys and values, e.g. env, region or
+// team.name; values may also be empty
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]*[A-Za-z0-9])?$`)
+
+// LabelStore holds the labels of the hosts fleet commands reach, keyed by
+// SSH destination as listed in host lists
+type LabelStore struct {
+	Hosts map[string]map[string]string `json:"hosts"`
+
+	path string
+}
+
+// DefaultLabelsPath returns the labels file in the user's configuration
+// directory
+func DefaultLabelsPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, "fixpanic", LabelsFileName), nil
+}
+
+// LoadLabels returns the labels saved at path, or none if nothing was saved
+// yet
+func LoadLabels(path string) (*LabelStore, error) {
+	store := &LabelStore{Hosts: map[string]map[string]string{}, path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read host labels: %w", err)
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if store.Hosts == nil {
+		store.Hosts = map[string]map[string]string{}
+	}
+	return store, nil
+}
+
+// Labels returns the labels of host
+func (s *LabelStore) Labels(host string) map[string]string {
+	return s.Hosts[host]
+}
+
+// Apply changes the labels of host: "key=value" sets a label and "key-"
+// removes it. Nothing changes if any change is invalid.
+func (s *LabelStore) Apply(host string, changes []string) error {
+	labels := make(map[string]string, len(s.Hosts[host]))
+	for key, value := range s.Hosts[host] {
+		labels[key] = value
+	}
+	for _, change := range changes {
+		if key, ok := strings.CutSuffix(change, "-"); ok && !strings.Contains(change, "=") {
+			if !labelKeyPattern.MatchString(key) {
+				return fmt.Errorf("invalid label key %q", key)
+			}
+			delete(labels, key)
+			continue
+		}
+		key, value, ok := strings.Cut(change, "=")
+		if !ok {
+			return fmt.Errorf("invalid label %q: expected key=value, or key- to remove it", change)
+		}
+		if !labelKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid label key %q", key)
+		}
+		if value != "" && !labelKeyPattern.MatchString(value) {
+			return fmt.Errorf("invalid value %q of label %s", value, key)
+		}
+		labels[key] = value
+	}
+
+	if len(labels) == 0 {
+		delete(s.Hosts, host)
+	} else {
+		s.Hosts[host] = labels
+	}
+	return nil
+}
+
+// Save writes the labels, replacing the file atomically
+func (s *LabelStore) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode host labels: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(s.path), err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write host labels: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write host labels: %w", err)
+	}
+	return nil
+}
+
+// Select returns the hosts whose labels match selector, in the order of
+// hosts
+func (s *LabelStore) Select(hosts []string, selector Selector) []string {
+	var selected []string
+	for _, host := range hosts {
+		if selector.Matches(s.Hosts[host]) {
+			selected = append(selected, host)
+		}
+	}
+	return selected
+}
+
+// LabeledHosts returns every host with labels, sorted
+func (s *LabelStore) LabeledHosts() []string {
+	hosts := make([]string, 0, len(s.Hosts))
+	for host := range s.Hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// FormatLabels returns labels as sorted key=value pairs separated by commas
+func FormatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// requirement is one condition of a Selector
+type requirement struct {
+	key   string
+	value string
+	// op is "=", "!=" or "" for a key that must be set
+	op string
+}
+
+// Selector filters hosts by their labels. Every requirement must hold.
+type Selector []requirement
+
+// ParseSelector parses comma-separated requirements: key=value, key!=value,
+// or key alone for hosts that have the label at all
+func ParseSelector(spec string) (Selector, error) {
+	var selector Selector
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var req requirement
+		switch {
+		case strings.Contains(part, "!="):
+			key, value, _ := strings.Cut(part, "!=")
+			req = requirement{key: key, value: value, op: "!="}
+		case strings.Contains(part, "="):
+			key, value, _ := strings.Cut(part, "=")
+			req = requirement{key: key, value: value, op: "="}
+		default:
+			req = requirement{key: part}
+		}
+		req.key = strings.TrimSpace(req.key)
+		req.value = strings.TrimSpace(req.value)
+		if !labelKeyPattern.MatchString(req.key) {
+			return nil, fmt.Errorf("invalid selector %q: %q is no label key", spec, req.key)
+		}
+		selector = append(selector, req)
+	}
+	if len(selector) == 0 {
+		return nil, fmt.Errorf("empty selector")
+	}
+	return selector, nil
+}
+
+// Matches reports whether labels satisfy every requirement of s
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, req := range s {
+		value, ok := labels[req.key]
+		switch req.op {
+		case "=":
+			if !ok || value != req.value {
+				return false
+			}
+		case "!=":
+			if ok && value == req.value {
+				return false
+			}
+		default:
+			if !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
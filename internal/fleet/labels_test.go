@@ -0,0 +1,122 @@
+package fleet
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	labels := map[string]string{"env": "prod", "region": "eu", "canary": ""}
+	tests := []struct {
+		spec    string
+		want    bool
+		wantErr bool
+	}{
+		{spec: "env=prod", want: true},
+		{spec: "env=staging", want: false},
+		{spec: "env=prod,region=eu", want: true},
+		{spec: "env=prod, region=us", want: false},
+		{spec: "region!=us", want: true},
+		{spec: "region!=eu", want: false},
+		{spec: "team!=ops", want: true},
+		{spec: "canary", want: true},
+		{spec: "canary=", want: true},
+		{spec: "team", want: false},
+		{spec: "", wantErr: true},
+		{spec: ",", wantErr: true},
+		{spec: "=prod", wantErr: true},
+		{spec: "env prod", wantErr: true},
+	}
+	for _, tt := range tests {
+		selector, err := ParseSelector(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSelector(%q) error = %v, want error %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if err == nil && selector.Matches(labels) != tt.want {
+			t.Errorf("ParseSelector(%q).Matches() = %v, want %v", tt.spec, !tt.want, tt.want)
+		}
+	}
+}
+
+func TestLabelStoreApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial map[string]string
+		changes []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "set", changes: []string{"env=prod", "region=eu"}, want: map[string]string{"env": "prod", "region": "eu"}},
+		{name: "overwrite", initial: map[string]string{"env": "staging"}, changes: []string{"env=prod"}, want: map[string]string{"env": "prod"}},
+		{name: "remove", initial: map[string]string{"env": "prod", "region": "eu"}, changes: []string{"region-"}, want: map[string]string{"env": "prod"}},
+		{name: "remove last", initial: map[string]string{"env": "prod"}, changes: []string{"env-"}, want: nil},
+		{name: "value ending in a dash", changes: []string{"tier=a-"}, wantErr: true},
+		{name: "empty value", changes: []string{"canary="}, want: map[string]string{"canary": ""}},
+		{name: "missing value", initial: map[string]string{"env": "prod"}, changes: []string{"region=eu", "bad"}, want: map[string]string{"env": "prod"}, wantErr: true},
+		{name: "invalid key", changes: []string{"e v=prod"}, wantErr: true},
+		{name: "invalid value", changes: []string{"env=a,b"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &LabelStore{Hosts: map[string]map[string]string{}}
+			if tt.initial != nil {
+				store.Hosts["web-1"] = tt.initial
+			}
+			err := store.Apply("web-1", tt.changes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !reflect.DeepEqual(store.Labels("web-1"), tt.initial) {
+					t.Errorf("a failed Apply() changed the labels to %v", store.Labels("web-1"))
+				}
+				return
+			}
+			if got := store.Labels("web-1"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("labels = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelStoreSaveAndSelect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixpanic", LabelsFileName)
+	store, err := LoadLabels(path)
+	if err != nil {
+		t.Fatalf("LoadLabels() of a missing file error = %v", err)
+	}
+	for host, changes := range map[string][]string{
+		"web-1": {"env=prod", "region=eu"},
+		"web-2": {"env=prod", "region=us"},
+		"db-1":  {"env=staging"},
+	} {
+		if err := store.Apply(host, changes); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadLabels(path)
+	if err != nil {
+		t.Fatalf("LoadLabels() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Hosts, store.Hosts) {
+		t.Errorf("loaded %v, saved %v", loaded.Hosts, store.Hosts)
+	}
+	if got, want := loaded.LabeledHosts(), []string{"db-1", "web-1", "web-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LabeledHosts() = %v, want %v", got, want)
+	}
+
+	selector, err := ParseSelector("env=prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := []string{"web-2", "unlabeled", "db-1", "web-1"}
+	if got, want := loaded.Select(hosts, selector), []string{"web-2", "web-1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Select() = %v, want %v in host list order", got, want)
+	}
+}
@@ -0,0 +1,170 @@
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogManifestName is the file in a log collection's directory that records
+// its progress, so an interrupted collection resumes where it stopped
+const LogManifestName = "fleet-logs.json"
+
+// States of a host in a log collection
+const (
+	LogsComplete  = "complete"
+	LogsTruncated = "truncated"
+	LogsFailed    = "failed"
+)
+
+// LogCollection is the manifest of a fleet log collection
+type LogCollection struct {
+	// Since is the start of the collected period, the same for every host
+	// and every resumption
+	Since time.Time `json:"since"`
+	// SinceFlag is the --since the collection was started with
+	SinceFlag string               `json:"since_flag"`
+	Hosts     map[string]*HostLogs `json:"hosts"`
+
+	mu   sync.Mutex
+	path string
+}
+
+// HostLogs is the outcome of collecting one host's logs
+type HostLogs struct {
+	State string `json:"state"`
+	// File is relative to the collection's directory
+	File        string    `json:"file,omitempty"`
+	Bytes       int64     `json:"bytes"`
+	Error       string    `json:"error,omitempty"`
+	CollectedAt time.Time `json:"collected_at"`
+}
+
+// Done reports whether the host's logs need not be collected again
+func (h *HostLogs) Done() bool {
+	return h != nil && (h.State == LogsComplete || h.State == LogsTruncated)
+}
+
+// OpenLogCollection returns the collection in dir, or a new one of the logs
+// since since if dir holds none yet
+func OpenLogCollection(dir string, since time.Time, sinceFlag string) (*LogCollection, error) {
+	collection := &LogCollection{
+		Since:     since,
+		SinceFlag: sinceFlag,
+		Hosts:     map[string]*HostLogs{},
+		path:      filepath.Join(dir, LogManifestName),
+	}
+	data, err := os.ReadFile(collection.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return collection, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", collection.path, err)
+	}
+	if err := json.Unmarshal(data, collection); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", collection.path, err)
+	}
+	if collection.Hosts == nil {
+		collection.Hosts = map[string]*HostLogs{}
+	}
+	return collection, nil
+}
+
+// Host returns the recorded outcome for host, nil if there is none
+func (c *LogCollection) Host(host string) *HostLogs {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Hosts[host]
+}
+
+// Record saves the outcome for host
+func (c *LogCollection) Record(host string, logs *HostLogs) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Hosts[host] = logs
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", LogManifestName, err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", LogManifestName, err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", LogManifestName, err)
+	}
+	return nil
+}
+
+// LogFileName returns the name of host's log file in a collection
+func LogFileName(host string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune(".-_@", r) {
+			return r
+		}
+		return '_'
+	}, host)
+	return safe + ".log"
+}
+
+// ErrSizeLimit ends a copy through a LimitedWriter that reached its limit
+var ErrSizeLimit = errors.New("size limit reached")
+
+// LimitedWriter passes at most Max bytes on to W, at most Rate bytes per
+// second. Throttling the writes slows down the copy feeding it, and through
+// SSH the remote command, instead of buffering. Zero disables either limit.
+type LimitedWriter struct {
+	Ctx  context.Context
+	W    io.Writer
+	Max  int64
+	Rate int64
+
+	// Written counts the bytes passed on; Truncated is set once Max cut
+	// the output short
+	Written   int64
+	Truncated bool
+	started   time.Time
+}
+
+func (l *LimitedWriter) Write(p []byte) (int, error) {
+	if l.started.IsZero() {
+		l.started = time.Now()
+	}
+	data := p
+	if l.Max > 0 && l.Written+int64(len(data)) > l.Max {
+		data = data[:l.Max-l.Written]
+		l.Truncated = true
+	}
+
+	n, err := l.W.Write(data)
+	l.Written += int64(n)
+	if err != nil {
+		return n, err
+	}
+	if l.Truncated {
+		return n, ErrSizeLimit
+	}
+
+	if l.Rate > 0 {
+		due := l.started.Add(time.Duration(float64(l.Written) / float64(l.Rate) * float64(time.Second)))
+		if wait := time.Until(due); wait > 0 {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-l.Ctx.Done():
+				return n, l.Ctx.Err()
+			}
+		}
+	}
+	return n, nil
+}
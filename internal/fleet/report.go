@@ -0,0 +1,264 @@
+package fleet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SnapshotSchema is the version of the Snapshot format. Fields are only ever
+// added, so a report can read snapshots of older and newer CLIs alike.
+const SnapshotSchema = 1
+
+// Snapshot is what 'fixpanic fleet snapshot' reports about one host
+type Snapshot struct {
+	Schema      int       `json:"schema"`
+	Hostname    string    `json:"hostname"`
+	CLIVersion  string    `json:"cli_version"`
+	CollectedAt time.Time `json:"collected_at"`
+	Installed   bool      `json:"installed"`
+	// AgentVersion is empty when the agent isn't installed or its version
+	// can't be determined
+	AgentVersion string `json:"agent_version,omitempty"`
+	Running      bool   `json:"running"`
+	// AgentStartedAt is when the running agent process started
+	AgentStartedAt *time.Time `json:"agent_started_at,omitempty"`
+	// LastHeartbeat is the last time a health check found the agent running
+	// and the socket server reachable
+	LastHeartbeat *time.Time `json:"last_heartbeat,omitempty"`
+	// Drift lists the generated files (configuration, service unit) edited
+	// since they were generated
+	Drift    []string `json:"drift"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+// Uptime returns how long the agent has been running at the time of the
+// snapshot, or 0 if it isn't running
+func (s *Snapshot) Uptime() time.Duration {
+	if !s.Running || s.AgentStartedAt == nil {
+		return 0
+	}
+	return s.CollectedAt.Sub(*s.AgentStartedAt)
+}
+
+// HostReport is one host of the fleet report
+type HostReport struct {
+	Host     string    `json:"host"`
+	Snapshot *Snapshot `json:"snapshot,omitempty"`
+	// Error is why no snapshot could be collected
+	Error string `json:"error,omitempty"`
+	// UpgradePending is set when the installed agent isn't the latest release
+	UpgradePending bool `json:"upgrade_pending"`
+}
+
+// Summary counts the hosts of a report by state
+type Summary struct {
+	Hosts          int            `json:"hosts"`
+	Unreachable    int            `json:"unreachable"`
+	NotInstalled   int            `json:"not_installed"`
+	NotRunning     int            `json:"not_running"`
+	UpgradePending int            `json:"upgrade_pending"`
+	Drifted        int            `json:"drifted"`
+	Versions       map[string]int `json:"versions"`
+}
+
+// Report summarizes the agents of a fleet
+type Report struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	// LatestAgentVersion is empty when the latest release couldn't be
+	// determined, and then no upgrade is reported as pending
+	LatestAgentVersion string       `json:"latest_agent_version,omitempty"`
+	Summary            Summary      `json:"summary"`
+	Hosts              []HostReport `json:"hosts"`
+}
+
+// NewReport builds the report of hosts, flagging agents that aren't at
+// latestVersion
+func NewReport(hosts []HostReport, latestVersion string, now time.Time) *Report {
+	report := &Report{
+		GeneratedAt:        now.UTC(),
+		LatestAgentVersion: latestVersion,
+		Summary:            Summary{Hosts: len(hosts), Versions: make(map[string]int)},
+		Hosts:              hosts,
+	}
+	for i := range report.Hosts {
+		host := &report.Hosts[i]
+		snapshot := host.Snapshot
+		switch {
+		case snapshot == nil:
+			report.Summary.Unreachable++
+			continue
+		case !snapshot.Installed:
+			report.Summary.NotInstalled++
+			continue
+		case !snapshot.Running:
+			report.Summary.NotRunning++
+		}
+
+		version := snapshot.AgentVersion
+		if version == "" {
+			version = "unknown"
+		}
+		report.Summary.Versions[version]++
+		if latestVersion != "" && snapshot.AgentVersion != "" &&
+			strings.TrimPrefix(snapshot.AgentVersion, "v") != strings.TrimPrefix(latestVersion, "v") {
+			host.UpgradePending = true
+			report.Summary.UpgradePending++
+		}
+		if len(snapshot.Drift) > 0 {
+			report.Summary.Drifted++
+		}
+	}
+	return report
+}
+
+// csvHeader are the columns of the CSV report
+var csvHeader = []string{"host", "hostname", "status", "agent_version", "upgrade_pending", "uptime_seconds", "last_heartbeat", "drift", "cli_version", "error"}
+
+// WriteCSV writes one row per host, for spreadsheets
+func (r *Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, host := range r.Hosts {
+		row := []string{host.Host, "", host.Status(), "", strconv.FormatBool(host.UpgradePending), "", "", "", "", host.Error}
+		if s := host.Snapshot; s != nil {
+			row[1] = s.Hostname
+			row[3] = s.AgentVersion
+			if uptime := s.Uptime(); uptime > 0 {
+				row[5] = strconv.Itoa(int(uptime.Seconds()))
+			}
+			if s.LastHeartbeat != nil {
+				row[6] = s.LastHeartbeat.UTC().Format(time.RFC3339)
+			}
+			row[7] = strings.Join(s.Drift, ";")
+			row[8] = s.CLIVersion
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Status sums up the state of the host's agent in a word
+func (h HostReport) Status() string {
+	switch s := h.Snapshot; {
+	case s == nil:
+		return "unreachable"
+	case !s.Installed:
+		return "not installed"
+	case !s.Running:
+		return "stopped"
+	}
+	return "running"
+}
+
+// WriteHTML writes the report as a standalone HTML page, for attaching to
+// reviews
+func (r *Report) WriteHTML(w io.Writer) error {
+	versions := make([]string, 0, len(r.Summary.Versions))
+	for version := range r.Summary.Versions {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+
+	funcs := template.FuncMap{
+		"uptime": func(s *Snapshot) string {
+			if uptime := s.Uptime(); uptime > 0 {
+				return formatDays(uptime)
+			}
+			return "-"
+		},
+		"time": func(t *time.Time) string {
+			if t == nil {
+				return "never"
+			}
+			return t.UTC().Format("2006-01-02 15:04 UTC")
+		},
+	}
+	tmpl, err := template.New("report").Funcs(funcs).Parse(htmlTemplate)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, struct {
+		*Report
+		VersionNames []string
+	}{r, versions})
+}
+
+// formatDays renders an uptime as days and hours
+func formatDays(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	if days == 0 {
+		return fmt.Sprintf("%dh %dm", hours, int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd %dh", days, hours)
+}
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Fixpanic fleet report {{ .GeneratedAt.Format "2006-01-02 15:04 UTC" }}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+th { background: #f3f3f3; }
+.bad { color: #b00020; font-weight: bold; }
+.warn { color: #a66300; }
+</style>
+</head>
+<body>
+<h1>Fixpanic fleet report</h1>
+<p>Generated {{ .GeneratedAt.Format "2006-01-02 15:04 UTC" }}. Latest agent release: {{ if .LatestAgentVersion }}{{ .LatestAgentVersion }}{{ else }}unknown{{ end }}.</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th>Hosts</th><td>{{ .Summary.Hosts }}</td></tr>
+<tr><th>Unreachable</th><td{{ if .Summary.Unreachable }} class="bad"{{ end }}>{{ .Summary.Unreachable }}</td></tr>
+<tr><th>Agent not installed</th><td>{{ .Summary.NotInstalled }}</td></tr>
+<tr><th>Agent stopped</th><td{{ if .Summary.NotRunning }} class="bad"{{ end }}>{{ .Summary.NotRunning }}</td></tr>
+<tr><th>Upgrade pending</th><td{{ if .Summary.UpgradePending }} class="warn"{{ end }}>{{ .Summary.UpgradePending }}</td></tr>
+<tr><th>Configuration drift</th><td{{ if .Summary.Drifted }} class="warn"{{ end }}>{{ .Summary.Drifted }}</td></tr>
+</table>
+
+<h2>Agent versions</h2>
+<table>
+<tr><th>Version</th><th>Hosts</th></tr>
+{{- range .VersionNames }}
+<tr><td>{{ . }}</td><td>{{ index $.Summary.Versions . }}</td></tr>
+{{- end }}
+</table>
+
+<h2>Hosts</h2>
+<table>
+<tr><th>Host</th><th>Status</th><th>Agent version</th><th>Uptime</th><th>Last heartbeat</th><th>Drift</th><th>CLI version</th></tr>
+{{- range .Hosts }}
+{{- if .Snapshot }}
+<tr>
+<td>{{ .Host }}{{ if ne .Host .Snapshot.Hostname }} ({{ .Snapshot.Hostname }}){{ end }}</td>
+<td{{ if ne .Status "running" }} class="bad"{{ end }}>{{ .Status }}</td>
+<td{{ if .UpgradePending }} class="warn"{{ end }}>{{ .Snapshot.AgentVersion }}{{ if .UpgradePending }} (upgrade pending){{ end }}</td>
+<td>{{ uptime .Snapshot }}</td>
+<td>{{ time .Snapshot.LastHeartbeat }}</td>
+<td{{ if .Snapshot.Drift }} class="warn"{{ end }}>{{ range $i, $name := .Snapshot.Drift }}{{ if $i }}, {{ end }}{{ $name }}{{ else }}none{{ end }}</td>
+<td>{{ .Snapshot.CLIVersion }}</td>
+</tr>
+{{- else }}
+<tr><td>{{ .Host }}</td><td class="bad">unreachable</td><td colspan="5">{{ .Error }}</td></tr>
+{{- end }}
+{{- end }}
+</table>
+</body>
+</html>
+`
@@ -0,0 +1,155 @@
+// Package fsperm applies and verifies the file mode and ownership policy for
+// the directory trees the CLI installs into.
+package fsperm
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Policy describes the modes and ownership files should have. Zero modes and
+// negative IDs leave the respective attribute alone, so files keep the mode
+// derived from the umask they were created with.
+type Policy struct {
+	DirMode  os.FileMode
+	FileMode os.FileMode
+	UID      int
+	GID      int
+}
+
+// Violation is a file that doesn't match the policy
+type Violation struct {
+	Path    string
+	Problem string
+}
+
+// ParseMode parses an octal permission string such as "0750" or "640"
+func ParseMode(value string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid file mode %q: expected octal permissions such as 0750", value)
+	}
+	return os.FileMode(mode), nil
+}
+
+// NewPolicy builds a policy from octal modes and user/group names or IDs.
+// Empty values leave the attribute unmanaged.
+func NewPolicy(dirMode, fileMode, owner, group string) (*Policy, error) {
+	policy := &Policy{UID: -1, GID: -1}
+
+	var err error
+	if dirMode != "" {
+		if policy.DirMode, err = ParseMode(dirMode); err != nil {
+			return nil, err
+		}
+	}
+	if fileMode != "" {
+		if policy.FileMode, err = ParseMode(fileMode); err != nil {
+			return nil, err
+		}
+	}
+	if owner != "" {
+		if policy.UID, err = lookupUser(owner); err != nil {
+			return nil, err
+		}
+	}
+	if group != "" {
+		if policy.GID, err = lookupGroup(group); err != nil {
+			return nil, err
+		}
+	}
+
+	return policy, nil
+}
+
+// IsZero reports whether the policy manages nothing
+func (p *Policy) IsZero() bool {
+	return p.DirMode == 0 && p.FileMode == 0 && p.UID < 0 && p.GID < 0
+}
+
+// wantMode returns the mode the policy requires for a file or directory, or
+// false if its mode is unmanaged. Executables (binaries, hook scripts) get
+// the file mode plus an execute bit wherever it grants read.
+func (p *Policy) wantMode(mode fs.FileMode) (fs.FileMode, bool) {
+	switch {
+	case mode.IsDir():
+		return p.DirMode, p.DirMode != 0
+	case !mode.IsRegular() || p.FileMode == 0:
+		return 0, false
+	case mode.Perm()&0111 != 0:
+		return p.FileMode | (p.FileMode&0444)>>2, true
+	default:
+		return p.FileMode, true
+	}
+}
+
+// Apply sets modes and ownership on root and everything below it. Symlinks
+// are not followed. A missing root is skipped.
+func (p *Policy) Apply(root string) error {
+	if p.IsZero() {
+		return nil
+	}
+	return p.walk(root, func(path string, info fs.FileInfo) error {
+		if p.UID >= 0 || p.GID >= 0 {
+			if err := os.Lchown(path, p.UID, p.GID); err != nil {
+				return fmt.Errorf("failed to change owner of %s: %w", path, err)
+			}
+		}
+		if mode, ok := p.wantMode(info.Mode()); ok && info.Mode().Perm() != mode {
+			if err := os.Chmod(path, mode); err != nil {
+				return fmt.Errorf("failed to change mode of %s: %w", path, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Check returns the files below root that don't match the policy
+func (p *Policy) Check(root string) ([]Violation, error) {
+	var violations []Violation
+	if p.IsZero() {
+		return violations, nil
+	}
+
+	err := p.walk(root, func(path string, info fs.FileInfo) error {
+		if mode, ok := p.wantMode(info.Mode()); ok && info.Mode().Perm() != mode {
+			violations = append(violations, Violation{
+				Path:    path,
+				Problem: fmt.Sprintf("mode is %04o, expected %04o", info.Mode().Perm(), mode),
+			})
+		}
+		if uid, gid, ok := FileOwner(info); ok {
+			if p.UID >= 0 && uid != p.UID {
+				violations = append(violations, Violation{Path: path, Problem: fmt.Sprintf("owner is %d, expected %d", uid, p.UID)})
+			}
+			if p.GID >= 0 && gid != p.GID {
+				violations = append(violations, Violation{Path: path, Problem: fmt.Sprintf("group is %d, expected %d", gid, p.GID)})
+			}
+		}
+		return nil
+	})
+	return violations, err
+}
+
+// walk calls fn for root and every file below it, without following symlinks
+func (p *Policy) walk(root string, fn func(path string, info fs.FileInfo) error) error {
+	if _, err := os.Lstat(root); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		return fn(path, info)
+	})
+}
@@ -0,0 +1,45 @@
+//go:build !windows
+// +build !windows
+
+package fsperm
+
+import (
+	"fmt"
+	"io/fs"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// lookupUser resolves a user name or numeric UID
+func lookupUser(name string) (int, error) {
+	if uid, err := strconv.Atoi(name); err == nil {
+		return uid, nil
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return 0, fmt.Errorf("unknown owner %q: %w", name, err)
+	}
+	return strconv.Atoi(u.Uid)
+}
+
+// lookupGroup resolves a group name or numeric GID
+func lookupGroup(name string) (int, error) {
+	if gid, err := strconv.Atoi(name); err == nil {
+		return gid, nil
+	}
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		return 0, fmt.Errorf("unknown group %q: %w", name, err)
+	}
+	return strconv.Atoi(g.Gid)
+}
+
+// FileOwner returns the UID and GID of a file, if the platform has them
+func FileOwner(info fs.FileInfo) (int, int, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(stat.Uid), int(stat.Gid), true
+}
@@ -0,0 +1,27 @@
+//go:build windows
+// +build windows
+
+package fsperm
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// errOwnershipUnsupported is returned when an owner or group is configured on Windows
+var errOwnershipUnsupported = errors.New("file ownership can't be managed on Windows; use ACLs instead")
+
+// lookupUser is not supported on Windows
+func lookupUser(name string) (int, error) {
+	return 0, errOwnershipUnsupported
+}
+
+// lookupGroup is not supported on Windows
+func lookupGroup(name string) (int, error) {
+	return 0, errOwnershipUnsupported
+}
+
+// FileOwner is not available on Windows
+func FileOwner(info fs.FileInfo) (int, int, bool) {
+	return 0, 0, false
+}
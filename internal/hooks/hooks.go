@@ -0,0 +1,162 @@
+// Package hooks runs user-defined scripts around agent lifecycle operations.
+//
+// Hooks are discovered in two places:
+//   - <ConfigDir>/hooks.d/<phase>-<operation>/ - every executable file in the
+//     directory, run in lexical order (e.g. hooks.d/pre-upgrade/10-snapshot.sh)
+//   - explicitly configured paths passed in by the caller
+//
+// A failing pre-hook aborts the operation; a failing post-hook only warns.
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+)
+
+// Lifecycle operations that support hooks
+const (
+	OperationInstall = "install"
+	OperationUpgrade = "upgrade"
+	OperationStart   = "start"
+	OperationStop    = "stop"
+)
+
+// Hook phases
+const (
+	PhasePre  = "pre"
+	PhasePost = "post"
+)
+
+// DefaultTimeout bounds how long a single hook may run
+const DefaultTimeout = 60 * time.Second
+
+// Runner executes hooks for lifecycle events
+type Runner struct {
+	Dir        string
+	Configured map[string][]string
+	Env        map[string]string
+	Timeout    time.Duration
+	// Output receives what the hooks print. It defaults to the logger's
+	// message writer, stderr, so hooks never mix into the data a command
+	// prints on stdout (e.g. --output json).
+	Output io.Writer
+}
+
+// Event returns the hook event name for a phase and operation, e.g. "pre-upgrade"
+func Event(phase, operation string) string {
+	return phase + "-" + operation
+}
+
+// NewRunner creates a hook runner that looks for hooks under dir
+func NewRunner(dir string) *Runner {
+	return &Runner{
+		Dir:        dir,
+		Configured: make(map[string][]string),
+		Env:        make(map[string]string),
+		Timeout:    DefaultTimeout,
+		Output:     logger.Messages(),
+	}
+}
+
+// Discover returns the hook scripts registered for an event, configured
+// paths first followed by the hooks.d directory entries in lexical order
+func (r *Runner) Discover(event string) ([]string, error) {
+	scripts := append([]string{}, r.Configured[event]...)
+
+	dir := filepath.Join(r.Dir, event)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return scripts, nil
+		}
+		return nil, fmt.Errorf("failed to read hook directory %s: %w", dir, err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+			continue // not executable
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		scripts = append(scripts, filepath.Join(dir, name))
+	}
+
+	return scripts, nil
+}
+
+// Run executes every hook registered for the event. extraEnv is added on
+// top of the runner's environment for this invocation only.
+func (r *Runner) Run(ctx context.Context, phase, operation string, extraEnv map[string]string) error {
+	event := Event(phase, operation)
+	scripts, err := r.Discover(event)
+	if err != nil {
+		return err
+	}
+
+	env := os.Environ()
+	env = append(env,
+		"FIXPANIC_HOOK_EVENT="+event,
+		"FIXPANIC_HOOK_PHASE="+phase,
+		"FIXPANIC_OPERATION="+operation,
+	)
+	for key, value := range r.Env {
+		env = append(env, key+"="+value)
+	}
+	for key, value := range extraEnv {
+		env = append(env, key+"="+value)
+	}
+
+	for _, script := range scripts {
+		if err := r.runScript(ctx, script, env); err != nil {
+			return fmt.Errorf("%s hook %s failed: %w", event, script, err)
+		}
+	}
+
+	return nil
+}
+
+// runScript runs a single hook with a timeout
+func (r *Runner) runScript(ctx context.Context, script string, env []string) error {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, script)
+	cmd.Env = env
+	cmd.Stdout = r.Output
+	cmd.Stderr = r.Output
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timed out after %v", timeout)
+		}
+		return err
+	}
+
+	return nil
+}
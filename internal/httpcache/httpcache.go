@@ -0,0 +1,342 @@
+// Package httpcache keeps release metadata and downloads in a
+// content-addressed cache so repeated installs and update checks don't
+// re-fetch what hasn't changed.
+//
+// Responses are stored once per SHA-256 under blobs/sha256/ and referenced
+// from a small entry per URL. Entries for mutable URLs (release metadata,
+// latest downloads) are revalidated with If-None-Match, which GitHub answers
+// with 304 without counting it against the API rate limit. Downloads of a
+// tagged release are immutable and served without asking the server.
+package httpcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvCache disables the cache when set to a false value
+const EnvCache = "FIXPANIC_CACHE"
+
+// maxAge is how long a blob that isn't used any more is kept
+const maxAge = 30 * 24 * time.Hour
+
+// HeaderCache is set to "hit" on responses served from the cache
+const HeaderCache = "X-Fixpanic-Cache"
+
+// Cache is an on-disk HTTP cache. A nil *Cache is valid and caches nothing.
+type Cache struct {
+	Dir string
+}
+
+// entry describes the cached response for a URL
+type entry struct {
+	URL          string    `json:"url"`
+	SHA256       string    `json:"sha256"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	StoredAt     time.Time `json:"stored_at"`
+}
+
+// New returns a cache in dir
+func New(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+// Default returns the cache in the platform cache directory, or nil if it is
+// disabled by FIXPANIC_CACHE or there is no cache directory
+func Default() *Cache {
+	if value := os.Getenv(EnvCache); value != "" {
+		if enabled, err := strconv.ParseBool(value); err != nil || !enabled {
+			return nil
+		}
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+	return New(filepath.Join(cacheDir, "fixpanic", "http"))
+}
+
+// Immutable reports whether url names content that never changes once
+// published: a release asset downloaded by tag
+func Immutable(url string) bool {
+	return strings.Contains(url, "/releases/download/")
+}
+
+// Get issues a GET request for url, answering it from the cache when the
+// cached copy is still valid. A 200 response read to the end is added to the
+// cache when its body is closed. Caching is best effort: any cache failure
+// falls back to a plain request.
+func (c *Cache) Get(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	cached := c.lookup(url)
+	if cached != nil && Immutable(url) {
+		if resp := c.hit(cached); resp != nil {
+			return resp, nil
+		}
+		cached = nil
+	}
+
+	resp, err := get(ctx, client, url, cached)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		if hit := c.hit(cached); hit != nil {
+			return hit, nil
+		}
+		// The blob became unusable after the lookup; ask for the content
+		if resp, err = get(ctx, client, url, nil); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode == http.StatusOK && c != nil {
+		resp.Body = c.record(url, resp)
+	}
+	return resp, nil
+}
+
+// get issues a GET request for url, conditional on cached if not nil
+func get(ctx context.Context, client *http.Client, url string, cached *entry) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+	return client.Do(req)
+}
+
+func (c *Cache) entryPath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.Dir, "entries", hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *Cache) blobPath(sha string) string {
+	return filepath.Join(c.Dir, "blobs", "sha256", sha)
+}
+
+// lookup returns the entry for url if its blob is present
+func (c *Cache) lookup(url string) *entry {
+	if c == nil {
+		return nil
+	}
+	data, err := os.ReadFile(c.entryPath(url))
+	if err != nil {
+		return nil
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.URL != url {
+		return nil
+	}
+	if _, err := os.Stat(c.blobPath(e.SHA256)); err != nil {
+		return nil
+	}
+	return &e
+}
+
+// hit builds a response from the cached blob, or returns nil if the blob is
+// missing or no longer matches its checksum
+func (c *Cache) hit(e *entry) *http.Response {
+	path := c.blobPath(e.SHA256)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+
+	// Verify before handing out anything: a corrupted binary must never be
+	// installed from the cache
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil || size != e.Size || hex.EncodeToString(hash.Sum(nil)) != e.SHA256 {
+		file.Close()
+		os.Remove(path)
+		return nil
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil
+	}
+
+	// Keep blobs that are still used from being pruned
+	now := time.Now()
+	os.Chtimes(path, now, now)
+
+	header := http.Header{}
+	header.Set(HeaderCache, "hit")
+	if e.ETag != "" {
+		header.Set("ETag", e.ETag)
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          file,
+		ContentLength: e.Size,
+	}
+}
+
+// record wraps the body of resp so reading it also writes a new blob
+func (c *Cache) record(url string, resp *http.Response) io.ReadCloser {
+	tmpDir := filepath.Join(c.Dir, "tmp")
+	if err := os.MkdirAll(tmpDir, 0700); err != nil {
+		return resp.Body
+	}
+	tmp, err := os.CreateTemp(tmpDir, "blob-*")
+	if err != nil {
+		return resp.Body
+	}
+	return &recorder{
+		cache: c,
+		body:  resp.Body,
+		tmp:   tmp,
+		hash:  sha256.New(),
+		entry: entry{
+			URL:          url,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		},
+	}
+}
+
+// recorder copies a response body into the cache while it is read
+type recorder struct {
+	cache *Cache
+	body  io.ReadCloser
+	tmp   *os.File
+	hash  hash.Hash
+	entry entry
+	// failed is set once writing the copy failed; reading continues
+	failed bool
+	eof    bool
+}
+
+func (r *recorder) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if n > 0 && !r.failed {
+		if _, werr := r.tmp.Write(p[:n]); werr != nil {
+			r.failed = true
+		}
+		r.hash.Write(p[:n])
+		r.entry.Size += int64(n)
+	}
+	if err == io.EOF {
+		r.eof = true
+	}
+	return n, err
+}
+
+// Close closes the body and stores the copy if the body was read completely
+func (r *recorder) Close() error {
+	err := r.body.Close()
+	tmpPath := r.tmp.Name()
+	if closeErr := r.tmp.Close(); closeErr != nil {
+		r.failed = true
+	}
+	if !r.eof || r.failed {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	r.entry.SHA256 = hex.EncodeToString(r.hash.Sum(nil))
+	r.entry.StoredAt = time.Now().UTC()
+	if storeErr := r.cache.store(tmpPath, &r.entry); storeErr != nil {
+		os.Remove(tmpPath)
+	}
+	return err
+}
+
+// store moves a completed blob into place and points the entry at it
+func (c *Cache) store(tmpPath string, e *entry) error {
+	blobPath := c.blobPath(e.SHA256)
+	if err := os.MkdirAll(filepath.Dir(blobPath), 0700); err != nil {
+		return err
+	}
+	// The same content may already be cached under another URL
+	if _, err := os.Stat(blobPath); err == nil {
+		os.Remove(tmpPath)
+		now := time.Now()
+		os.Chtimes(blobPath, now, now)
+	} else if err := os.Rename(tmpPath, blobPath); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	entryPath := c.entryPath(e.URL)
+	if err := os.MkdirAll(filepath.Dir(entryPath), 0700); err != nil {
+		return err
+	}
+	tmpEntry := entryPath + ".tmp"
+	if err := os.WriteFile(tmpEntry, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpEntry, entryPath); err != nil {
+		os.Remove(tmpEntry)
+		return err
+	}
+
+	c.prune()
+	return nil
+}
+
+// prune removes blobs that haven't been used for maxAge, along with the
+// entries pointing at them
+func (c *Cache) prune() {
+	blobs, err := os.ReadDir(filepath.Join(c.Dir, "blobs", "sha256"))
+	if err != nil {
+		return
+	}
+	removed := map[string]bool{}
+	for _, blob := range blobs {
+		info, err := blob.Info()
+		if err != nil || time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+		if os.Remove(c.blobPath(blob.Name())) == nil {
+			removed[blob.Name()] = true
+		}
+	}
+	if len(removed) == 0 {
+		return
+	}
+
+	entriesDir := filepath.Join(c.Dir, "entries")
+	entries, err := os.ReadDir(entriesDir)
+	if err != nil {
+		return
+	}
+	for _, item := range entries {
+		path := filepath.Join(entriesDir, item.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var e entry
+		if json.Unmarshal(data, &e) == nil && removed[e.SHA256] {
+			os.Remove(path)
+		}
+	}
+}
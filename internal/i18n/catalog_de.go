@@ -0,0 +1,753 @@
+package i18n
+
+// german holds the German translations
+var german = map[string]string{
+	// Errors
+	"Error: %v":                                      "Fehler: %v",
+	"What you can do:":                               "Was Sie tun können:",
+	"FixPanic Agent is not installed":                "Der FixPanic-Agent ist nicht installiert",
+	"FixPanic Agent not installed":                   "Der FixPanic-Agent ist nicht installiert",
+	"agent is not installed":                         "Der Agent ist nicht installiert",
+	"FixPanic Agent is already installed":            "Der FixPanic-Agent ist bereits installiert",
+	"invalid configuration: %w":                      "ungültige Konfiguration: %w",
+	"failed to load configuration: %w":               "Konfiguration konnte nicht geladen werden: %w",
+	"invalid socket server address: %w":              "ungültige Socket-Server-Adresse: %w",
+	"connection test failed":                         "Verbindungstest fehlgeschlagen",
+	"failed to fetch latest release: %w":             "neueste Version konnte nicht abgerufen werden: %w",
+	"failed to download new version: %w":             "neue Version konnte nicht heruntergeladen werden: %w",
+	"another fixpanic operation is in progress (%s)": "ein anderer fixpanic-Vorgang läuft bereits (%s)",
+	"another fixpanic operation is in progress":      "ein anderer fixpanic-Vorgang läuft bereits",
+	"another watchdog is already running (PID: %d)":  "ein anderer Watchdog läuft bereits (PID: %d)",
+	"'%s' modifies the installation and is disabled in read-only mode (cli.read_only / FIXPANIC_READ_ONLY)": "'%s' verändert die Installation und ist im Nur-Lese-Modus deaktiviert (cli.read_only / FIXPANIC_READ_ONLY)",
+
+	// Remediation hints
+	"Install the agent with 'fixpanic agent install --agent-id=<id> --api-key=<key>'":        "Installieren Sie den Agenten mit 'fixpanic agent install --agent-id=<id> --api-key=<key>'",
+	"Regenerate the configuration with 'fixpanic agent install --force'":                     "Erzeugen Sie die Konfiguration neu mit 'fixpanic agent install --force'",
+	"Wait for the other operation to finish, or retry with --lock-timeout=5m to wait for it": "Warten Sie, bis der andere Vorgang abgeschlossen ist, oder wiederholen Sie den Befehl mit --lock-timeout=5m",
+	"Use --force to reinstall":                                                                                 "Verwenden Sie --force für eine Neuinstallation",
+	"Run 'fixpanic agent upgrade' to update the agent binary":                                                  "Führen Sie 'fixpanic agent upgrade' aus, um das Agent-Binary zu aktualisieren",
+	"Check the values passed to --agent-id, --api-key and --socket-server":                                     "Prüfen Sie die Werte von --agent-id, --api-key und --socket-server",
+	"Check that %s exists and is valid YAML":                                                                   "Prüfen Sie, ob %s existiert und gültiges YAML enthält",
+	"Fix the reported value in %s":                                                                             "Korrigieren Sie den gemeldeten Wert in %s",
+	"Check that your firewall allows outbound TCP connections to %s":                                           "Prüfen Sie, ob Ihre Firewall ausgehende TCP-Verbindungen zu %s erlaubt",
+	"Verify the socket server address with --socket-server":                                                    "Prüfen Sie die Socket-Server-Adresse mit --socket-server",
+	"Run 'fixpanic agent logs' to see why the agent failed to start":                                           "Führen Sie 'fixpanic agent logs' aus, um zu sehen, warum der Agent nicht gestartet ist",
+	"Run 'fixpanic agent doctor' to diagnose common problems":                                                  "Führen Sie 'fixpanic agent doctor' aus, um häufige Probleme zu diagnostizieren",
+	"Run 'fixpanic agent stop' to stop the running watchdog first":                                             "Beenden Sie zuerst den laufenden Watchdog mit 'fixpanic agent stop'",
+	"Run '%s --help' to see its usage":                                                                         "Führen Sie '%s --help' aus, um die Verwendung anzuzeigen",
+	"Run the command with --help to see its usage":                                                             "Führen Sie den Befehl mit --help aus, um die Verwendung anzuzeigen",
+	"Run '%s --help' to see its commands":                                                                      "Führen Sie '%s --help' aus, um die Befehle anzuzeigen",
+	"Did you mean '%s %s'?":                                                                                    "Meinten Sie '%s %s'?",
+	"Did you mean --%s?":                                                                                       "Meinten Sie --%s?",
+	"Run '%s' instead":                                                                                         "Führen Sie stattdessen '%s' aus",
+	"Check your internet connection and any proxy or firewall settings":                                        "Prüfen Sie Ihre Internetverbindung sowie Proxy- und Firewall-Einstellungen",
+	"Run 'fixpanic agent test-connection' to diagnose connectivity":                                            "Führen Sie 'fixpanic agent test-connection' aus, um die Verbindung zu prüfen",
+	"Re-run the command with sudo":                                                                             "Führen Sie den Befehl erneut mit sudo aus",
+	"Unset FIXPANIC_READ_ONLY or remove cli.read_only from ~/.fixpanic.yaml if this host should be modified":   "Entfernen Sie FIXPANIC_READ_ONLY bzw. cli.read_only aus ~/.fixpanic.yaml, wenn dieser Host verändert werden soll",
+	"Don't install the release by other means: a missing or mismatching manifest can mean a tampered download": "Installieren Sie das Release nicht auf anderem Weg: Ein fehlendes oder abweichendes Manifest kann auf einen manipulierten Download hindeuten",
+	"Report it to security@fixpanic.com if it persists":                                                        "Melden Sie es an security@fixpanic.com, wenn es weiterhin auftritt",
+	"This fixpanic binary was built without RELEASE_SIGNING_KEY; use an official release of the CLI":           "Diese fixpanic-Binärdatei wurde ohne RELEASE_SIGNING_KEY gebaut; verwenden Sie ein offizielles Release der CLI",
+
+	// agent install
+	"Installing Fixpanic Agent":                                              "Fixpanic-Agent wird installiert",
+	"Detecting platform and configuration":                                   "Plattform und Konfiguration werden ermittelt",
+	"Running as non-root user. Agent will be installed in user directories.": "Ausführung ohne Root-Rechte. Der Agent wird in Benutzerverzeichnisse installiert.",
+	"Binary location":                                             "Binary-Pfad",
+	"Config location":                                             "Konfigurationspfad",
+	"Creating necessary directories":                              "Benötigte Verzeichnisse werden angelegt",
+	"Checking for existing installation":                          "Bestehende Installation wird gesucht",
+	"Ensuring latest agent binary":                                "Neuestes Agent-Binary wird sichergestellt",
+	"Creating agent configuration":                                "Agent-Konfiguration wird erstellt",
+	"Validating configuration":                                    "Konfiguration wird geprüft",
+	"Configuration saved to: %s":                                  "Konfiguration gespeichert unter: %s",
+	"Setting up system service":                                   "Systemdienst wird eingerichtet",
+	"Removing old service if it exists":                           "Alter Dienst wird entfernt, falls vorhanden",
+	"Failed to remove old service: %v":                            "Alter Dienst konnte nicht entfernt werden: %v",
+	"Installing systemd service":                                  "systemd-Dienst wird installiert",
+	"You can start the agent manually with: fixpanic agent start": "Sie können den Agenten manuell starten mit: fixpanic agent start",
+	"Failed to enable service: %v":                                "Dienst konnte nicht aktiviert werden: %v",
+	"Agent service installed and started successfully":            "Agent-Dienst erfolgreich installiert und gestartet",
+	"Systemd not available. You can start the agent manually with: fixpanic agent start": "systemd ist nicht verfügbar. Sie können den Agenten manuell starten mit: fixpanic agent start",
+	"FixPanic agent installed successfully!":                                             "FixPanic-Agent erfolgreich installiert!",
+	"Agent ID":                                                                           "Agent-ID",
+	"Socket server":                                                                      "Socket-Server",
+	"The agent will start automatically on system boot.":                                 "Der Agent startet automatisch beim Systemstart.",
+	"You can manage the service with:":                                                   "Sie können den Dienst verwalten mit:",
+
+	// agent upgrade
+	"Upgrading FixPanic Agent":                      "FixPanic-Agent wird aktualisiert",
+	"Checking agent installation":                   "Agent-Installation wird geprüft",
+	"Checking current agent version":                "Aktuelle Agent-Version wird geprüft",
+	"Stopping agent for upgrade":                    "Agent wird für die Aktualisierung gestoppt",
+	"Agent is not running, proceeding with upgrade": "Agent läuft nicht, Aktualisierung wird fortgesetzt",
+	"Upgrading agent binary":                        "Agent-Binary wird aktualisiert",
+	"Verifying upgrade":                             "Aktualisierung wird überprüft",
+	"Agent was already on the latest version":       "Der Agent war bereits auf dem neuesten Stand",
+	"Agent upgraded successfully!":                  "Agent erfolgreich aktualisiert!",
+	"Upgraded: %s → %s":                             "Aktualisiert: %s → %s",
+	"Restarting agent":                              "Agent wird neu gestartet",
+	"Agent restarted successfully with new version": "Agent erfolgreich mit neuer Version neu gestartet",
+
+	// agent start
+	"Starting FixPanic Agent":               "FixPanic-Agent wird gestartet",
+	"Checking for existing agent processes": "Laufende Agent-Prozesse werden gesucht",
+	"Agent installation verified":           "Agent-Installation überprüft",
+	"Starting agent service":                "Agent-Dienst wird gestartet",
+	"Checking service status":               "Dienststatus wird geprüft",
+
+	// config migrate
+	"failed to migrate configuration: %w":                           "Konfiguration konnte nicht migriert werden: %w",
+	"Migrating Agent Configuration":                                 "Agent-Konfiguration wird migriert",
+	"Configuration file":                                            "Konfigurationsdatei",
+	"Configuration is already at version %d":                        "Konfiguration ist bereits auf Version %d",
+	"Resulting configuration:":                                      "Resultierende Konfiguration:",
+	"Dry run: nothing was written. Run without --dry-run to apply.": "Testlauf: Es wurde nichts geschrieben. Ohne --dry-run ausführen, um die Änderungen anzuwenden.",
+	"Configuration migrated to version %d":                          "Konfiguration auf Version %d migriert",
+	"Backup":                                                        "Sicherung",
+
+	// agent diff
+	"configuration drift detected": "Abweichung der Konfiguration festgestellt",
+	"Run 'fixpanic agent diff --accept' to re-render the files (edited versions are kept as .bak)": "Mit 'fixpanic agent diff --accept' die Dateien neu erzeugen (bearbeitete Versionen bleiben als .bak erhalten)",
+	"Agent Configuration Drift": "Abweichungen der Agent-Konfiguration",
+	"Configuration":             "Konfiguration",
+	"Service unit":              "Service-Unit",
+	"No drift":                  "Keine Abweichung",
+	"File is missing":           "Datei fehlt",
+	"On-disk files match what this CLI version generates": "Die Dateien entsprechen dem, was diese CLI-Version erzeugt",
+	"Re-rendered %s": "%s neu erzeugt",
+	"Run 'fixpanic agent restart' for the agent to pick up the changes": "'fixpanic agent restart' ausführen, damit der Agent die Änderungen übernimmt",
+
+	// config set and profiles
+	"Run 'fixpanic config profiles' to list the available profiles": "Mit 'fixpanic config profiles' die verfügbaren Profile anzeigen",
+	"Profile":    "Profil",
+	"Updated %s": "%s aktualisiert",
+	"Old value":  "Alter Wert",
+	"New value":  "Neuer Wert",
+	"Balanced limits suitable for most servers":                                  "Ausgewogene Grenzwerte für die meisten Server",
+	"More concurrent connections and longer tool timeouts for busy hosts":        "Mehr gleichzeitige Verbindungen und längere Tool-Timeouts für stark ausgelastete Hosts",
+	"Few concurrent connections and short timeouts for small VMs and containers": "Wenige gleichzeitige Verbindungen und kurze Timeouts für kleine VMs und Container",
+	"Verbose logging and generous timeouts for troubleshooting":                  "Ausführliche Protokollierung und großzügige Timeouts zur Fehlersuche",
+
+	// Cancellation
+	"interrupted":                    "abgebrochen",
+	"timed out after %s: %w":         "Zeitüberschreitung nach %s: %w",
+	"Re-run with a longer --timeout": "Mit einem längeren --timeout erneut ausführen",
+
+	// cleanup-temp
+	"Cleaning Up Temporary Files":    "Temporäre Dateien werden bereinigt",
+	"No stale temporary files found": "Keine veralteten temporären Dateien gefunden",
+	"Failed to remove %s: %v":        "%s konnte nicht entfernt werden: %v",
+	"Dry run: %d item(s) would be removed. Run without --dry-run to remove them.": "Probelauf: %d Element(e) würden entfernt. Ohne --dry-run ausführen, um sie zu entfernen.",
+	"Removed %d item(s), freed %s":           "%d Element(e) entfernt, %s freigegeben",
+	"failed to scan for temporary files: %w": "Suche nach temporären Dateien fehlgeschlagen: %w",
+
+	// Disk space preflight
+	"Not enough disk space": "Nicht genügend Speicherplatz",
+	"Free up space in %s":   "Geben Sie Speicherplatz in %s frei",
+	"Run 'fixpanic cleanup-temp' to remove files left behind by interrupted runs": "Führen Sie 'fixpanic cleanup-temp' aus, um Reste abgebrochener Läufe zu entfernen",
+
+	// upgrade verification
+	"Reported version": "Gemeldete Version",
+
+	// Environment detection
+	"Environment": "Umgebung",
+	"WSL 1 translates Linux system calls and has limited binfmt support, so the agent binary may fail to execute":      "WSL 1 übersetzt Linux-Systemaufrufe und unterstützt binfmt nur eingeschränkt, daher startet die Agent-Binärdatei möglicherweise nicht",
+	"Convert the distribution to WSL 2 with 'wsl --set-version <distro> 2' from Windows":                               "Konvertieren Sie die Distribution unter Windows mit 'wsl --set-version <distro> 2' zu WSL 2",
+	"systemd is not enabled in this WSL distribution, so the agent can't run as a service":                             "systemd ist in dieser WSL-Distribution nicht aktiviert, daher kann der Agent nicht als Dienst laufen",
+	"Enable it with '[boot] systemd=true' in /etc/wsl.conf and run 'wsl --shutdown', or use 'fixpanic agent watchdog'": "Aktivieren Sie es mit '[boot] systemd=true' in /etc/wsl.conf und 'wsl --shutdown', oder verwenden Sie 'fixpanic agent watchdog'",
+	"systemd is not running in this container, so no service will be installed":                                        "systemd läuft in diesem Container nicht, daher wird kein Dienst installiert",
+	"Run 'fixpanic agent watchdog' as the container entrypoint to keep the agent running":                              "Verwenden Sie 'fixpanic agent watchdog' als Entrypoint des Containers, um den Agenten am Laufen zu halten",
+
+	// Permission policy
+	"Applying file permissions":                "Dateiberechtigungen werden angewendet",
+	"%d file(s) violate the permission policy": "%d Datei(en) verletzen die Berechtigungsrichtlinie",
+	"Re-apply the policy with 'fixpanic agent upgrade' or 'fixpanic agent install --force'": "Wenden Sie die Richtlinie mit 'fixpanic agent upgrade' oder 'fixpanic agent install --force' erneut an",
+
+	// self uninstall
+	"Uninstalling FixPanic CLI":                                                       "FixPanic CLI wird deinstalliert",
+	"the agent is still installed and can't be managed without the CLI":               "der Agent ist noch installiert und kann ohne die CLI nicht verwaltet werden",
+	"Run 'fixpanic agent uninstall' first, or pass --keep-agent to leave it in place": "Führen Sie zuerst 'fixpanic agent uninstall' aus oder übergeben Sie --keep-agent, um ihn beizubehalten",
+	"Removed %s":                  "%s entfernt",
+	"Removed empty directory: %s": "Leeres Verzeichnis entfernt: %s",
+	"Removed %d item(s). The FixPanic CLI has been uninstalled.": "%d Element(e) entfernt. Die FixPanic CLI wurde deinstalliert.",
+
+	// Telemetry
+	"Telemetry disabled. Queued events were deleted.":                                                   "Telemetrie deaktiviert. Gesammelte Ereignisse wurden gelöscht.",
+	"Telemetry enabled. Thank you for helping improve FixPanic!":                                        "Telemetrie aktiviert. Danke, dass Sie helfen, FixPanic zu verbessern!",
+	"DO_NOT_TRACK or FIXPANIC_TELEMETRY is set, so nothing will be sent until it is unset":              "DO_NOT_TRACK oder FIXPANIC_TELEMETRY ist gesetzt, daher wird nichts gesendet, bis die Variable entfernt wird",
+	"Run 'fixpanic telemetry status' to see what is collected, or 'fixpanic telemetry off' to opt out.": "Mit 'fixpanic telemetry status' sehen Sie, was erfasst wird; mit 'fixpanic telemetry off' widersprechen Sie.",
+	"Telemetry":     "Telemetrie",
+	"Install ID":    "Installations-ID",
+	"Changed":       "Geändert",
+	"Queued events": "Wartende Ereignisse",
+	"State file":    "Statusdatei",
+
+	// Crash reports
+	"fixpanic crashed unexpectedly: %v":                                "fixpanic ist unerwartet abgestürzt: %v",
+	"fixpanic crashed. Send the crash report (%s) to FixPanic? [y/N]:": "fixpanic ist abgestürzt. Absturzbericht (%s) an FixPanic senden? [y/N]:",
+	"Could not send the crash report: %v":                              "Absturzbericht konnte nicht gesendet werden: %v",
+	"Crash report sent. Thank you!":                                    "Absturzbericht gesendet. Vielen Dank!",
+	"A crash report was saved to %s":                                   "Ein Absturzbericht wurde unter %s gespeichert",
+	"Please include it when contacting support@fixpanic.com":           "Bitte fügen Sie ihn bei, wenn Sie support@fixpanic.com kontaktieren",
+	"Please report this at support@fixpanic.com with the output above": "Bitte melden Sie dies mit der obigen Ausgabe an support@fixpanic.com",
+
+	// agent top
+	"--interval must be at least 100ms":    "--interval muss mindestens 100ms betragen",
+	"the agent is not running":             "der Agent läuft nicht",
+	"Start it with 'fixpanic agent start'": "Starten Sie ihn mit 'fixpanic agent start'",
+	"agent process %d exited":              "Agent-Prozess %d wurde beendet",
+	"Resource usage is unavailable: %v":    "Ressourcennutzung ist nicht verfügbar: %v",
+	"Run as root to see connection counts": "Führen Sie den Befehl als root aus, um die Anzahl der Verbindungen zu sehen",
+
+	// network check
+	"HTTPS proxy":                         "HTTPS-Proxy",
+	"DNS lookup failed for %s":            "DNS-Auflösung für %s fehlgeschlagen",
+	"%d required endpoint(s) unreachable": "%d erforderliche(r) Endpunkt(e) nicht erreichbar",
+	"Allow outbound TCP to the endpoints above, or route them through your HTTPS proxy":                             "Erlauben Sie ausgehendes TCP zu den obigen Endpunkten oder leiten Sie sie über Ihren HTTPS-Proxy",
+	"Exclude the FixPanic and GitHub hosts from TLS inspection, or add the inspection CA to the system trust store": "Nehmen Sie die FixPanic- und GitHub-Hosts von der TLS-Inspektion aus oder fügen Sie die Inspektions-CA dem System-Zertifikatsspeicher hinzu",
+	"Network check finished with %d warning(s)":                                                                     "Netzwerkprüfung mit %d Warnung(en) abgeschlossen",
+	"All endpoints are reachable":                                                                                   "Alle Endpunkte sind erreichbar",
+	"Allow outbound TCP connections to these endpoints in your firewall:":                                           "Erlauben Sie in Ihrer Firewall ausgehende TCP-Verbindungen zu diesen Endpunkten:",
+	"Suggested firewall rules to allow the blocked endpoints:":                                                      "Vorgeschlagene Firewall-Regeln für die blockierten Endpunkte:",
+	"GitHub's addresses change over time; prefer allowing these hosts by name in an HTTPS proxy":                    "Die Adressen von GitHub ändern sich; erlauben Sie diese Hosts besser per Name in einem HTTPS-Proxy",
+
+	// network bench
+	"--samples must be at least 1":                                             "--samples muss mindestens 1 sein",
+	"--duration must be at least 1s":                                           "--duration muss mindestens 1s betragen",
+	"Measuring connect latency (%d samples)":                                   "Verbindungslatenz wird gemessen (%d Messungen)",
+	"Latency test failed":                                                      "Latenztest fehlgeschlagen",
+	"Run 'fixpanic network check' to find out what is blocking the connection": "Führen Sie 'fixpanic network check' aus, um herauszufinden, was die Verbindung blockiert",
+	"Latency measured":                                                         "Latenz gemessen",
+	"Measuring reconnect time":                                                 "Wiederverbindungszeit wird gemessen",
+	"Reconnect test failed: %v":                                                "Wiederverbindungstest fehlgeschlagen: %v",
+	"Reconnect measured":                                                       "Wiederverbindung gemessen",
+	"Reconnect time":                                                           "Wiederverbindungszeit",
+	"Measuring upload throughput (%v)":                                         "Upload-Durchsatz wird gemessen (%v)",
+	"Throughput test failed: %v":                                               "Durchsatztest fehlgeschlagen: %v",
+	"The socket server closed the test session before throughput could be measured": "Der Socket-Server hat die Testsitzung beendet, bevor der Durchsatz gemessen werden konnte",
+	"Throughput measured": "Durchsatz gemessen",
+	"Upload throughput":   "Upload-Durchsatz",
+	"The socket server closed the test session after %v; the result may be imprecise": "Der Socket-Server hat die Testsitzung nach %v beendet; das Ergebnis ist möglicherweise ungenau",
+	"This link may be too slow for remote-debug sessions:":                            "Diese Verbindung ist möglicherweise zu langsam für Remote-Debug-Sitzungen:",
+	"This link can support remote-debug sessions":                                     "Diese Verbindung eignet sich für Remote-Debug-Sitzungen",
+
+	// Progress events
+	"--events-fd and --events-file are mutually exclusive":                             "--events-fd und --events-file schließen sich gegenseitig aus",
+	"invalid events file descriptor %d: 0-2 are reserved for stdin, stdout and stderr": "ungültiger Ereignis-Dateideskriptor %d: 0-2 sind für stdin, stdout und stderr reserviert",
+
+	// Install plans
+	"--plan and --apply are mutually exclusive":                    "--plan und --apply schließen sich gegenseitig aus",
+	"--%s can't be combined with --apply; it is fixed by the plan": "--%s kann nicht mit --apply kombiniert werden; der Wert ist im Plan festgelegt",
+	"Create a new plan with the changed options":                   "Erstellen Sie einen neuen Plan mit den geänderten Optionen",
+	"--api-key is required with --apply":                           "--api-key ist bei --apply erforderlich",
+	"Plan":                                                         "Plan",
+	"Downloading planned agent binary":                             "Geplante Agent-Binärdatei wird heruntergeladen",
+	"Use --force to plan a reinstall":                              "Verwenden Sie --force, um eine Neuinstallation zu planen",
+	"Create a new plan on this host with 'fixpanic agent install --plan' and review it again": "Erstellen Sie auf diesem Host mit 'fixpanic agent install --plan' einen neuen Plan und prüfen Sie ihn erneut",
+	"Run as the same user and with the same --prefix as when the plan was created":            "Führen Sie den Befehl als derselbe Benutzer und mit demselben --prefix aus wie beim Erstellen des Plans",
+	"plan was created for %s, this host is %s":                                                "der Plan wurde für %s erstellt, dieser Host ist %s",
+	"%s would differ from the plan":                                                           "%s würde vom Plan abweichen",
+
+	// artifacts pull and offline install
+	"Pulling FixPanic Agent Artifacts": "FixPanic-Agent-Artefakte werden abgerufen",
+	"Fetching release %s":              "Release %s wird abgerufen",
+	"Failed to fetch release":          "Release konnte nicht abgerufen werden",
+	"Release %s":                       "Release %s",
+	"Downloading %s":                   "%s wird heruntergeladen",
+	"Failed to download %s":            "%s konnte nicht heruntergeladen werden",
+	"%s verified":                      "%s verifiziert",
+	"invalid platform %q: expected GOOS/GOARCH, e.g. linux/amd64": "ungültige Plattform %q: GOOS/GOARCH erwartet, z. B. linux/amd64",
+	"%s already holds a bundle of %s":                             "%s enthält bereits ein Bundle von %s",
+	"Use a separate --dest directory for each release":            "Verwenden Sie für jedes Release ein eigenes --dest-Verzeichnis",
+	"%s is %d bytes, the release lists %d":                        "%s ist %d Bytes groß, das Release nennt %d",
+	"release %s has no agent build for %s":                        "Release %s enthält keinen Agent-Build für %s",
+	"Bundle of %s ready in %s":                                    "Bundle von %s liegt in %s bereit",
+	"Artifacts":                                                   "Artefakte",
+	"Manifest":                                                    "Manifest",
+	"Copy the directory to the target hosts and install with:":    "Kopieren Sie das Verzeichnis auf die Zielhosts und installieren Sie mit:",
+	"Installing agent binary from artifact bundle":                "Agent-Binärdatei wird aus dem Artefakt-Bundle installiert",
+	"bundle %s has a different agent build than the plan":         "Bundle %s enthält einen anderen Agent-Build als der Plan",
+	"Use the bundle the plan was created from":                    "Verwenden Sie das Bundle, aus dem der Plan erstellt wurde",
+	"bundle %s has no %s build":                                   "Bundle %s enthält keinen %s-Build",
+	"FixPanic Agent installed to %s":                              "FixPanic Agent wurde nach %s installiert",
+	"Using cached download":                                       "Zwischengespeicherter Download wird verwendet",
+
+	// concurrent connection tests
+	"connection test failed for %d of %d endpoints: %s":                           "Verbindungstest für %d von %d Endpunkten fehlgeschlagen: %s",
+	"Check that your firewall allows outbound TCP connections to these endpoints": "Prüfen Sie, ob Ihre Firewall ausgehende TCP-Verbindungen zu diesen Endpunkten erlaubt",
+	"invalid endpoint %q: %w":                                                     "ungültiger Endpunkt %q: %w",
+
+	// network dns
+	"DNS check finished with %d warning(s)":     "DNS-Prüfung mit %d Warnung(en) abgeschlossen",
+	"DNS answers are consistent":                "DNS-Antworten sind konsistent",
+	"the system resolver failed for %d host(s)": "der System-Resolver ist für %d Host(s) fehlgeschlagen",
+	"Check the nameservers in /etc/resolv.conf (or your network settings) and any DNS overrides for these hosts": "Prüfen Sie die Nameserver in /etc/resolv.conf (oder Ihren Netzwerkeinstellungen) und DNS-Überschreibungen für diese Hosts",
+
+	// config set service
+	"Run 'fixpanic agent diff --accept' to regenerate the service unit": "Führen Sie 'fixpanic agent diff --accept' aus, um die Service-Unit neu zu erzeugen",
+
+	// agent set-log-level
+	"Setting Agent Log Level":     "Agent-Protokollstufe setzen",
+	"Reverting Agent Log Level":   "Agent-Protokollstufe zurücksetzen",
+	"Old level":                   "Alte Stufe",
+	"New level":                   "Neue Stufe",
+	"Log level":                   "Protokollstufe",
+	"Revert timer":                "Timer für Rücksetzung",
+	"Revert process":              "Prozess für Rücksetzung",
+	"invalid log level %q":        "ungültige Protokollstufe %q",
+	"Use one of: %s":              "Verwenden Sie einen der Werte: %s",
+	"--duration must be positive": "--duration muss positiv sein",
+	"Log level set to %s":         "Protokollstufe auf %s gesetzt",
+	"Log level set to %s until %s, then back to %s":                   "Protokollstufe bis %[2]s auf %[1]s gesetzt, danach wieder %[3]s",
+	"Run 'fixpanic agent set-log-level %s' when you are done":         "Führen Sie nach Abschluss 'fixpanic agent set-log-level %s' aus",
+	"Log level was changed to %s in the meantime; not reverting":      "Protokollstufe wurde inzwischen auf %s geändert; keine Rücksetzung",
+	"Log level reverted to %s":                                        "Protokollstufe auf %s zurückgesetzt",
+	"Agent is not running; the level applies the next time it starts": "Agent läuft nicht; die Stufe gilt beim nächsten Start",
+	"Restarting the agent to apply the log level":                     "Agent wird neu gestartet, um die Protokollstufe anzuwenden",
+	"Failed to remove pending log level revert: %v":                   "Ausstehende Rücksetzung der Protokollstufe konnte nicht entfernt werden: %v",
+	"%s (reverts to %s at %s)":                                        "%s (wird um %[3]s auf %[2]s zurückgesetzt)",
+
+	// agent exec
+	"failed to read the service environment: %w":                           "Service-Umgebung konnte nicht gelesen werden: %w",
+	"Pass --no-config to run the agent without its configuration":          "Verwenden Sie --no-config, um den Agent ohne seine Konfiguration auszuführen",
+	"agent exited with status %d":                                          "Agent wurde mit Status %d beendet",
+	"See the agent's output above; the status is passed through unchanged": "Siehe die Ausgabe des Agents oben; der Status wird unverändert weitergegeben",
+
+	// versions
+	"--per-page must be between 1 and 100": "--per-page muss zwischen 1 und 100 liegen",
+	"--page must be 1 or higher":           "--page muss 1 oder größer sein",
+	"failed to list releases: %w":          "Releases konnten nicht aufgelistet werden: %w",
+	"No releases found":                    "Keine Releases gefunden",
+
+	// release notes
+	"No release notes between %s and %s":         "Keine Versionshinweise zwischen %s und %s",
+	"Release notes for %s:":                      "Versionshinweise für %s:",
+	"Release notes from %s to %s (%d releases):": "Versionshinweise von %s bis %s (%d Releases):",
+	"No release notes":                           "Keine Versionshinweise",
+	"Yanked: %s":                                 "Zurückgezogen: %s",
+	"failed to fetch release notes: %w":          "Versionshinweise konnten nicht abgerufen werden: %w",
+	"Could not fetch release notes: %v":          "Versionshinweise konnten nicht abgerufen werden: %v",
+	"Agent is on the latest version (%s)":        "Der Agent ist auf der neuesten Version (%s)",
+	"release %s not found":                       "Release %s nicht gefunden",
+
+	// yanked releases
+	"Could not check whether %s was yanked: %v": "Konnte nicht prüfen, ob %s zurückgezogen wurde: %v",
+	"no reason given": "kein Grund angegeben",
+	"%s was yanked (%s); installing it anyway because of --allow-yanked": "%s wurde zurückgezogen (%s); wird wegen --allow-yanked trotzdem installiert",
+	"%s was yanked: %s": "%s wurde zurückgezogen: %s",
+	"Wait for a fixed release, or pass --allow-yanked to install this one anyway": "Warten Sie auf ein korrigiertes Release oder verwenden Sie --allow-yanked, um dieses trotzdem zu installieren",
+
+	// serve
+	"Listening on %s, which is reachable from other hosts; the API has no TLS": "Lausche auf %s, das von anderen Hosts erreichbar ist; die API hat kein TLS",
+	"Serving the FixPanic API on http://%s/v1/":                                "FixPanic-API wird unter http://%s/v1/ bereitgestellt",
+	"Token file":                         "Token-Datei",
+	"Generated a new API token in %s":    "Neues API-Token in %s erzeugt",
+	"API: %s requested by %s":            "API: %s angefordert von %s",
+	"failed to set up the API token: %w": "API-Token konnte nicht eingerichtet werden: %w",
+	"Run with sudo, or pass --token-file with a file you can write": "Mit sudo ausführen oder --token-file mit einer beschreibbaren Datei angeben",
+	"failed to listen on %s: %w":                                    "Lauschen auf %s fehlgeschlagen: %w",
+	"Serving the FixPanic gRPC control interface on %s":             "FixPanic-gRPC-Steuerschnittstelle wird unter %s bereitgestellt",
+	"The gRPC control interface failed: %v":                         "Die gRPC-Steuerschnittstelle ist ausgefallen: %v",
+
+	// log disk usage
+	"Agent Log Disk Usage":                      "Speicherbedarf der Agent-Logs",
+	"Could not read the journal disk usage: %v": "Speicherbedarf des Journals konnte nicht gelesen werden: %v",
+	"Journal (all units)":                       "Journal (alle Units)",
+	"The journal is larger than %s; limit it with SystemMaxUse= in /etc/systemd/journald.conf": "Das Journal ist größer als %s; begrenzen Sie es mit SystemMaxUse= in /etc/systemd/journald.conf",
+	"Free in %s": "Frei in %s",
+	"Could not check for dropped messages: %v":                                            "Verworfene Meldungen konnten nicht geprüft werden: %v",
+	"journald dropped %d agent message(s) in the last 24 hours because of its rate limit": "journald hat in den letzten 24 Stunden %d Agent-Meldung(en) wegen seines Ratenlimits verworfen",
+	"Raise the limit with 'sudo systemctl edit %s' and:":                                  "Erhöhen Sie das Limit mit 'sudo systemctl edit %s' und:",
+	"Dropped messages (24h)":                                                              "Verworfene Meldungen (24 h)",
+	"Log files":                                                                           "Logdateien",
+	"none in %s":                                                                          "keine in %s",
+	"Log files total":                                                                     "Logdateien gesamt",
+
+	// Redaction
+	"Exported %d redacted log line(s) to %s":                                    "%d geschwärzte Logzeile(n) nach %s exportiert",
+	"Review the file before sharing it; only known secret formats are redacted": "Prüfen Sie die Datei vor der Weitergabe; nur bekannte Geheimnisformate werden geschwärzt",
+	"--export can't be combined with --follow":                                  "--export kann nicht mit --follow kombiniert werden",
+	"Fix the patterns in the redaction section of ~/.fixpanic.yaml":             "Korrigieren Sie die Muster im Abschnitt redaction von ~/.fixpanic.yaml",
+
+	// Version history
+	"No version changes recorded":                            "Keine Versionsänderungen aufgezeichnet",
+	"Failed to record the installation: %v":                  "Die Installation konnte nicht aufgezeichnet werden: %v",
+	"Failed to record the version change in the history: %v": "Die Versionsänderung konnte nicht im Verlauf aufgezeichnet werden: %v",
+
+	// Health checks
+	"Failed to record the health check: %v":             "Die Integritätsprüfung konnte nicht aufgezeichnet werden: %v",
+	"Failed to notify the webhook: %v":                  "Der Webhook konnte nicht benachrichtigt werden: %v",
+	"agent is unhealthy: %s":                            "Agent ist nicht funktionsfähig: %s",
+	"Run 'fixpanic agent doctor' to diagnose the agent": "Führen Sie 'fixpanic agent doctor' aus, um den Agent zu diagnostizieren",
+	"Agent is healthy":                                  "Agent ist funktionsfähig",
+	"Agent is unhealthy":                                "Agent ist nicht funktionsfähig",
+	"Installed":                                         "Installiert",
+	"Running":                                           "Läuft",
+	"--interval must be at least %s":                    "--interval muss mindestens %s betragen",
+	"Health check scheduled every %s with %s.timer":     "Integritätsprüfung alle %s mit %s.timer geplant",
+	"See the results with 'fixpanic agent healthcheck status' or 'journalctl -u %s'": "Ergebnisse mit 'fixpanic agent healthcheck status' oder 'journalctl -u %s' anzeigen",
+	"Health check scheduled with cron in %s":                                         "Integritätsprüfung mit cron in %s geplant",
+	"cron runs at most once a minute; the interval was rounded up to whole minutes":  "cron läuft höchstens einmal pro Minute; das Intervall wurde auf ganze Minuten aufgerundet",
+	"See the results with 'fixpanic agent healthcheck status'":                       "Ergebnisse mit 'fixpanic agent healthcheck status' anzeigen",
+	"No scheduled health check is installed":                                         "Keine geplante Integritätsprüfung installiert",
+	"Scheduled health check removed":                                                 "Geplante Integritätsprüfung entfernt",
+	"No health checks recorded":                                                      "Keine Integritätsprüfungen aufgezeichnet",
+
+	// Self-healing
+	"Self-healing skipped: %v": "Selbstheilung übersprungen: %v",
+	"Self-healing: %d of %d failed checks before restarting the agent":    "Selbstheilung: %d von %d fehlgeschlagenen Prüfungen bis zum Neustart des Agents",
+	"Self-healing gave up after %d restart(s); the agent needs attention": "Selbstheilung nach %d Neustart(s) aufgegeben; der Agent benötigt Aufmerksamkeit",
+	"Self-healing: next restart possible in %s":                           "Selbstheilung: nächster Neustart möglich in %s",
+	"Self-healing skipped: the CLI is in read-only mode":                  "Selbstheilung übersprungen: die CLI ist im Nur-Lese-Modus",
+	"Self-healing: restarting the agent (attempt %d of %d)":               "Selbstheilung: Agent wird neu gestartet (Versuch %d von %d)",
+	"Self-healing restart failed: %v":                                     "Neustart durch Selbstheilung fehlgeschlagen: %v",
+	"Failed to record the self-healing state: %v":                         "Der Selbstheilungsstatus konnte nicht aufgezeichnet werden: %v",
+
+	// Clock skew
+	"certificate validation would fail: %s":                                                       "Die Zertifikatsprüfung würde fehlschlagen: %s",
+	"the certificate of %s is not valid now":                                                      "Das Zertifikat von %s ist derzeit nicht gültig",
+	"Enable time synchronization with 'sudo timedatectl set-ntp true', or install chrony or ntpd": "Aktivieren Sie die Zeitsynchronisierung mit 'sudo timedatectl set-ntp true' oder installieren Sie chrony oder ntpd",
+	"Run 'fixpanic agent doctor' to check the system clock and the certificate":                   "Führen Sie 'fixpanic agent doctor' aus, um die Systemuhr und das Zertifikat zu prüfen",
+
+	// TLS configuration
+	"--tls-cert-file and --tls-key-file must be given together":                                          "--tls-cert-file und --tls-key-file müssen zusammen angegeben werden",
+	"invalid TLS configuration: %w":                                                                      "Ungültige TLS-Konfiguration: %w",
+	"Check the files passed to --tls-ca-file, --tls-cert-file and --tls-key-file":                        "Prüfen Sie die an --tls-ca-file, --tls-cert-file und --tls-key-file übergebenen Dateien",
+	"Testing Agent Configuration":                                                                        "Agent-Konfiguration wird geprüft",
+	"TLS is disabled; the agent's traffic is not encrypted":                                              "TLS ist deaktiviert; der Datenverkehr des Agenten ist nicht verschlüsselt",
+	"The CA bundle, client certificate and pins are ignored while TLS is disabled":                       "CA-Bundle, Client-Zertifikat und Pins werden ignoriert, solange TLS deaktiviert ist",
+	"CA certificate %q expired on %s":                                                                    "CA-Zertifikat %q ist am %s abgelaufen",
+	"Client certificate expired on %s":                                                                   "Client-Zertifikat ist am %s abgelaufen",
+	"Client certificate is not valid until %s":                                                           "Client-Zertifikat ist erst ab %s gültig",
+	"Client certificate expires in %d day(s)":                                                            "Client-Zertifikat läuft in %d Tag(en) ab",
+	"Client key %s is readable by other users (mode %04o); run 'chmod 600 %s'":                           "Client-Schlüssel %s ist für andere Benutzer lesbar (Modus %04o); führen Sie 'chmod 600 %s' aus",
+	"configuration has %d TLS problem(s)":                                                                "Die Konfiguration hat %d TLS-Problem(e)",
+	"Replace the certificates and run 'fixpanic config test' again":                                      "Ersetzen Sie die Zertifikate und führen Sie 'fixpanic config test' erneut aus",
+	"Configuration is valid":                                                                             "Konfiguration ist gültig",
+	"Fix the paths with 'fixpanic config set app.tls_ca_file|app.tls_cert_file|app.tls_key_file <path>'": "Korrigieren Sie die Pfade mit 'fixpanic config set app.tls_ca_file|app.tls_cert_file|app.tls_key_file <Pfad>'",
+	"Run 'fixpanic config test' to check the TLS settings":                                               "Führen Sie 'fixpanic config test' aus, um die TLS-Einstellungen zu prüfen",
+	"CA certificates":    "CA-Zertifikate",
+	"CA bundle":          "CA-Bundle",
+	"Client certificate": "Client-Zertifikat",
+	"Expires":            "Läuft ab",
+
+	// Certificate pinning
+	"%s presented a certificate matching none of the pinned keys":                          "%s hat ein Zertifikat vorgelegt, das keinem der gepinnten Schlüssel entspricht",
+	"A proxy may be intercepting the connection; the agent will refuse it":                 "Möglicherweise fängt ein Proxy die Verbindung ab; der Agent wird sie ablehnen",
+	"If the server's key was rotated, update app.tls_pinned_spki with the pin shown above": "Wenn der Schlüssel des Servers gewechselt wurde, aktualisieren Sie app.tls_pinned_spki mit dem oben angezeigten Pin",
+	"Pinned keys": "Gepinnte Schlüssel",
+
+	// TLS status
+	"TLS CERTIFICATE VERIFICATION IS DISABLED (%s: true): anyone on the network path can intercept the agent's traffic": "TLS-ZERTIFIKATSPRÜFUNG IST DEAKTIVIERT (%s: true): Jeder auf dem Netzwerkpfad kann den Datenverkehr des Agenten abfangen",
+	"TLS enabled, certificates %s": "TLS aktiviert, Zertifikate %s",
+	"TLS disabled":                 "TLS deaktiviert",
+	"system roots":                 "System-Stammzertifikate",
+	"%s, expires %s (%d days)":     "%s, läuft am %s ab (%d Tage)",
+	"Certificate":                  "Zertifikat",
+	"%s (%d days)":                 "%s (%d Tage)",
+	"Could not inspect the server certificate: %s":              "Das Serverzertifikat konnte nicht geprüft werden: %s",
+	"Certificate chain verifies against the %s CA certificates": "Die Zertifikatskette wird mit den CA-Zertifikaten %s verifiziert",
+	"Certificate chain does not verify: %s":                     "Die Zertifikatskette lässt sich nicht verifizieren: %s",
+	"Certificate matches pinned key %s":                         "Zertifikat entspricht dem gepinnten Schlüssel %s",
+	"Turn verification back on with 'sudo fixpanic config set <key> false'; trust a private CA with app.tls_ca_file instead": "Schalten Sie die Prüfung mit 'sudo fixpanic config set <Schlüssel> false' wieder ein; vertrauen Sie stattdessen einer privaten CA mit app.tls_ca_file",
+	"No TLS problems found": "Keine TLS-Probleme gefunden",
+	"Agent TLS Status":      "TLS-Status des Agenten",
+	"Run 'fixpanic agent tls status' for the full TLS settings": "Führen Sie 'fixpanic agent tls status' für alle TLS-Einstellungen aus",
+
+	// Confinement
+	"The agent is confined by a system call filter and the AppArmor profile %s":           "Der Agent ist durch einen Systemaufruf-Filter und das AppArmor-Profil %s eingeschränkt",
+	"The agent is confined by a system call filter; AppArmor is not enabled on this host": "Der Agent ist durch einen Systemaufruf-Filter eingeschränkt; AppArmor ist auf diesem Host nicht aktiviert",
+	"Run 'fixpanic agent doctor' to check that the confinement isn't blocking the agent":  "Führen Sie 'fixpanic agent doctor' aus, um zu prüfen, dass die Einschränkung den Agenten nicht blockiert",
+	"--confine needs systemd; the agent runs unconfined":                                  "--confine erfordert systemd; der Agent läuft ohne Einschränkung",
+
+	// Pinned agent versions
+	"Downloading agent %s":                                                                        "Agent %s wird heruntergeladen",
+	"Checksum manifest signature verified":                                                        "Signatur des Prüfsummenmanifests verifiziert",
+	"Run 'fixpanic agent versions' to check that the release exists":                              "Führen Sie 'fixpanic agent versions' aus, um zu prüfen, ob das Release existiert",
+	"Releases published before signed checksum manifests can't be installed with --agent-version": "Releases, die vor signierten Prüfsummenmanifesten veröffentlicht wurden, können nicht mit --agent-version installiert werden",
+	"Run 'fixpanic agent versions' to list the agent releases":                                    "Führen Sie 'fixpanic agent versions' aus, um die Agent-Releases aufzulisten",
+
+	// Concurrent downloads
+	"Downloading agent %s and its checksums...":          "Agent %s und seine Prüfsummen werden heruntergeladen...",
+	"Downloading agent %s and its checksums... %s of %s": "Agent %s und seine Prüfsummen werden heruntergeladen... %s von %s",
+	"Downloading agent %s and its checksums... %s":       "Agent %s und seine Prüfsummen werden heruntergeladen... %s",
+	"Failed to download":                                 "Herunterladen fehlgeschlagen",
+
+	// agent logs forward
+	"Forward Agent Logs":                 "Agent-Logs weiterleiten",
+	"Wrote %s":                           "%s geschrieben",
+	"Included it in %s":                  "In %s eingebunden",
+	"The agent logs are forwarded to %s": "Die Agent-Logs werden an %s weitergeleitet",
+	"Fluent Bit uploads with the credentials of its environment or the instance role": "Fluent Bit lädt mit den Zugangsdaten seiner Umgebung oder der Instanzrolle hoch",
+	"The agent logs are not forwarded":                                                "Die Agent-Logs werden nicht weitergeleitet",
+	"The agent logs are no longer forwarded":                                          "Die Agent-Logs werden nicht mehr weitergeleitet",
+	"Restart %s to apply the configuration":                                           "Starten Sie %s neu, um die Konfiguration anzuwenden",
+	"Restarted %s":                                                                    "%s neu gestartet",
+
+	// agent metrics textfile
+	"Textfile refreshed every %s by %s.timer": "Textdatei wird alle %s von %s.timer aktualisiert",
+	"Textfile refreshed with cron in %s":      "Textdatei wird per cron in %s aktualisiert",
+	"No textfile refresh is installed":        "Keine Aktualisierung der Textdatei installiert",
+	"Textfile refresh removed":                "Aktualisierung der Textdatei entfernt",
+
+	// health endpoints
+	"Health endpoints disabled: failed to listen on %s: %v": "Health-Endpunkte deaktiviert: Lauschen auf %s fehlgeschlagen: %v",
+	"Health endpoints failed: %v":                           "Health-Endpunkte fehlgeschlagen: %v",
+
+	// fleet report
+	"Collecting snapshots from %d host(s)...":                                             "Sammle Snapshots von %d Host(s)...",
+	"Could not determine the latest agent release, pending upgrades are not reported: %v": "Neueste Agent-Version konnte nicht ermittelt werden, ausstehende Upgrades werden nicht gemeldet: %v",
+	"Wrote the report of %d host(s) to %s":                                                "Bericht über %d Host(s) nach %s geschrieben",
+	"%d host(s) could not be reached":                                                     "%d Host(s) nicht erreichbar",
+	"none of the %d host(s) could be reached":                                             "keiner der %d Host(s) war erreichbar",
+	"ssh is not installed":                                                                "ssh ist nicht installiert",
+
+	// Inventory
+	"Host Inventory": "Host-Inventar",
+	"Could not identify the cloud instance: %v": "Cloud-Instanz konnte nicht ermittelt werden: %v",
+	"Hostname":               "Hostname",
+	"OS":                     "Betriebssystem",
+	"Kernel":                 "Kernel",
+	"Architecture":           "Architektur",
+	"Cloud":                  "Cloud",
+	"Region":                 "Region",
+	"Account":                "Konto",
+	"CLI version":            "CLI-Version",
+	"Agent is not installed": "Agent ist nicht installiert",
+	"Agent version":          "Agent-Version",
+	"Collecting the inventory of %d host(s)...": "Inventar von %d Host(s) wird erfasst...",
+	"Wrote the inventory of %d host(s) to %s":   "Inventar von %d Host(s) nach %s geschrieben",
+	"unknown format %q: use json or csv":        "Unbekanntes Format %q: json oder csv verwenden",
+
+	// Cloud metadata
+	"Querying the cloud instance metadata service":                                      "Metadatendienst der Cloud-Instanz wird abgefragt",
+	"failed to read the cloud instance metadata: %w":                                    "Metadaten der Cloud-Instanz konnten nicht gelesen werden: %w",
+	"Check that the instance metadata service is enabled and not blocked by a firewall": "Prüfen Sie, ob der Instanz-Metadatendienst aktiviert ist und nicht von einer Firewall blockiert wird",
+	"Install without --cloud-metadata":                                                  "Ohne --cloud-metadata installieren",
+	"--cloud-metadata: this host is not an AWS, Google Cloud or Azure instance":         "--cloud-metadata: Dieser Host ist keine AWS-, Google-Cloud- oder Azure-Instanz",
+
+	// Package-managed agents
+	"The agent binary belongs to the %s package %s; the CLI took it over on %s":             "Die Agent-Binärdatei gehört zum %s-Paket %s; die CLI hat sie am %s übernommen",
+	"%s belongs to the %s package %s; the package manager upgrades it":                      "%s gehört zum %s-Paket %s; der Paketmanager aktualisiert sie",
+	"Upgrade the package instead: %s":                                                       "Aktualisieren Sie stattdessen das Paket: %s",
+	"Run 'fixpanic agent upgrade --takeover' to have the CLI upgrade the agent from now on": "Führen Sie 'fixpanic agent upgrade --takeover' aus, damit die CLI den Agent künftig aktualisiert",
+	"Taking over the agent binary from the %s package %s":                                   "Agent-Binärdatei wird vom %s-Paket %s übernommen",
+	"Hold the package so the package manager doesn't replace the binary again:":             "Halten Sie das Paket zurück, damit der Paketmanager die Binärdatei nicht wieder ersetzt:",
+	"Failed to record the takeover: %v":                                                     "Übernahme konnte nicht gespeichert werden: %v",
+
+	// Install verification
+	"Waiting up to %s for the agent to connect to %s":                                                              "Bis zu %s wird gewartet, bis sich der Agent mit %s verbindet",
+	"Agent is running and connected to the control plane (PID %d)":                                                 "Agent läuft und ist mit der Steuerungsebene verbunden (PID %d)",
+	"the agent is running but socket server %s is unreachable: %w":                                                 "Der Agent läuft, aber der Socket-Server %s ist nicht erreichbar: %w",
+	"Agent is running and the socket server is reachable":                                                          "Agent läuft und der Socket-Server ist erreichbar",
+	"The agent's connections can't be inspected without root; run 'fixpanic agent status' to confirm it connected": "Die Verbindungen des Agents können ohne root nicht geprüft werden; bestätigen Sie die Verbindung mit 'fixpanic agent status'",
+	"Last lines of %s:": "Letzte Zeilen von %s:",
+	"Run 'fixpanic agent logs' to see what the agent reported":                         "Führen Sie 'fixpanic agent logs' aus, um die Meldungen des Agents zu sehen",
+	"Use --no-verify to install without waiting for the agent":                         "Verwenden Sie --no-verify, um ohne Warten auf den Agent zu installieren",
+	"the agent did not start within %s":                                                "Der Agent ist nicht innerhalb von %s gestartet",
+	"the agent did not stay running for %s within %s":                                  "Der Agent lief innerhalb von %[2]s nicht %[1]s lang",
+	"the agent did not connect to %s within %s":                                        "Der Agent hat sich nicht innerhalb von %[2]s mit %[1]s verbunden",
+	"Check the agent ID and API key on the Fixpanic dashboard":                         "Prüfen Sie Agent-ID und API-Schlüssel im Fixpanic-Dashboard",
+	"Agent started in the background (PID %d)":                                         "Agent im Hintergrund gestartet (PID %d)",
+	"Verifying the agent connects":                                                     "Verbindung des Agents wird geprüft",
+	"The agent isn't started in this environment, so its connection isn't verified":    "Der Agent wird in dieser Umgebung nicht gestartet, daher wird seine Verbindung nicht geprüft",
+	"the agent was not started, so the installation can't be verified":                 "Der Agent wurde nicht gestartet, daher kann die Installation nicht geprüft werden",
+	"Run 'fixpanic agent start' and 'fixpanic agent logs' to see why it doesn't start": "Führen Sie 'fixpanic agent start' und 'fixpanic agent logs' aus, um zu sehen, warum er nicht startet",
+
+	// Validate --fix
+	"Applied %d fix(es)":                                      "%d Korrektur(en) angewendet",
+	"validation found %d problem(s)":                          "Die Prüfung hat %d Problem(e) gefunden",
+	"Run 'sudo fixpanic agent validate --fix' to repair them": "Führen Sie 'sudo fixpanic agent validate --fix' aus, um sie zu beheben",
+	"Directory %s is missing":                                 "Verzeichnis %s fehlt",
+	"Created directory %s":                                    "Verzeichnis %s erstellt",
+	"Run 'sudo fixpanic agent validate --fix' to restore the newest valid backup": "Führen Sie 'sudo fixpanic agent validate --fix' aus, um die neueste gültige Sicherung wiederherzustellen",
+	"No valid backup was found: %v":                                               "Keine gültige Sicherung gefunden: %v",
+	"Restored %s from %s (the broken file is kept as %s)":                         "%s aus %s wiederhergestellt (die defekte Datei bleibt als %s erhalten)",
+	"failed to load restored configuration: %w":                                   "Wiederhergestellte Konfiguration konnte nicht geladen werden: %w",
+	"Applied the permission policy to %s (%s)":                                    "Berechtigungsrichtlinie auf %s angewendet (%s)",
+	"Run 'sudo fixpanic agent validate --fix' to apply it":                        "Führen Sie 'sudo fixpanic agent validate --fix' aus, um sie anzuwenden",
+	"%s is readable by other users (mode %04o) and holds the API key":             "%s ist für andere Benutzer lesbar (Modus %04o) und enthält den API-Schlüssel",
+	"Restricted %s to mode 0600 (was %04o)":                                       "%s auf Modus 0600 beschränkt (vorher %04o)",
+	"%s starts '%s' instead of '%s'":                                              "%s startet '%s' statt '%s'",
+	"Re-rendered %s to start '%s'":                                                "%s neu erzeugt, startet nun '%s'",
+	"Run 'fixpanic agent restart' for the service to use the new unit":            "Führen Sie 'fixpanic agent restart' aus, damit der Dienst die neue Unit verwendet",
+
+	// agent migrate
+	"Move an installation made by an older CLI to the current layout":       "Eine von einer älteren CLI erstellte Installation auf das aktuelle Layout umstellen",
+	"Show what would be migrated without changing anything":                 "Anzeigen, was migriert würde, ohne etwas zu ändern",
+	"Migrating Legacy Installation":                                         "Migration einer älteren Installation",
+	"The %s exists at both %s and %s, leaving both":                         "%s existiert sowohl unter %s als auch unter %s, beide bleiben erhalten",
+	"Would move the %s from %s to %s":                                       "Würde %s von %s nach %s verschieben",
+	"Moved the %s from %s to %s":                                            "%s von %s nach %s verschoben",
+	"The installation already uses the current layout":                      "Die Installation verwendet bereits das aktuelle Layout",
+	"Dry run: nothing was changed. Run without --dry-run to apply.":         "Probelauf: nichts wurde geändert. Ohne --dry-run ausführen, um die Änderungen anzuwenden.",
+	"Migrated %d item(s) to the current layout":                             "%d Element(e) auf das aktuelle Layout migriert",
+	"Run 'fixpanic agent restart' so the agent uses the new locations":      "'fixpanic agent restart' ausführen, damit der Agent die neuen Orte verwendet",
+	"%d file(s) exist at both the old and the new location":                 "%d Datei(en) existieren sowohl am alten als auch am neuen Ort",
+	"Remove the copy you don't need and run 'fixpanic agent migrate' again": "Die nicht benötigte Kopie entfernen und 'fixpanic agent migrate' erneut ausführen",
+	"Would migrate the configuration: %s":                                   "Würde die Konfiguration migrieren: %s",
+	"Migrated the configuration: %s":                                        "Konfiguration migriert: %s",
+	"Would set the log file in %s to %s":                                    "Würde die Logdatei in %s auf %s setzen",
+	"Set the log file in %s to %s":                                          "Logdatei in %s auf %s gesetzt",
+	"Would re-render %s to start '%s' instead of '%s'":                      "Würde %s neu erzeugen, um '%s' statt '%s' zu starten",
+	"Re-rendered %s to start '%s' instead of '%s'":                          "%s neu erzeugt, startet nun '%s' statt '%s'",
+
+	// deprecations
+	"%s is deprecated, use %s instead": "%s ist veraltet, stattdessen %s verwenden",
+
+	// porcelain
+	"unknown --porcelain format %q: use %s": "unbekanntes --porcelain-Format %q: %s verwenden",
+
+	// middleware
+	"'%s' requires root":              "'%s' erfordert root",
+	"Run it again with sudo: sudo %s": "Mit sudo erneut ausführen: sudo %s",
+
+	// sudo
+	"Run it again with sudo? [y/N]:":                                                            "Mit sudo erneut ausführen? [y/N]:",
+	"forwarding the agent logs requires root":                                                   "Das Weiterleiten der Agent-Logs erfordert Root-Rechte",
+	"Without root the agent is installed in your user directories and not as a systemd service": "Ohne Root-Rechte wird der Agent in Ihren Benutzerverzeichnissen und nicht als systemd-Dienst installiert",
+
+	// install rollback
+	"Roll back the installation? [Y/n]:": "Installation zurücknehmen? [Y/n]:",
+	"Keeping the partial installation. You can start the agent manually with: fixpanic agent start": "Die unvollständige Installation bleibt erhalten. Sie können den Agent manuell starten mit: fixpanic agent start",
+	"Rolling back the installation": "Installation wird zurückgenommen",
+	"Rolled back the installation":  "Installation zurückgenommen",
+	"Use --keep-partial to keep the installation and find out why with 'fixpanic agent logs'": "Verwenden Sie --keep-partial, um die Installation zu behalten und die Ursache mit 'fixpanic agent logs' zu finden",
+	"Run 'fixpanic agent uninstall' to remove what is left of the installation":               "Führen Sie 'fixpanic agent uninstall' aus, um die Reste der Installation zu entfernen",
+
+	// agent smoke-test
+	"Agent Smoke Test":                         "Agent-Funktionstest",
+	"Checking the agent is running":            "Prüfe, ob der Agent läuft",
+	"Agent is running":                         "Agent läuft",
+	"Requesting 'echo' through %s":             "Fordere 'echo' über %s an",
+	"Waiting up to %s for the agent to run it": "Warte bis zu %s, bis der Agent ihn ausführt",
+	"Verifying the output":                     "Prüfe die Ausgabe",
+	"Commands run through the control plane, socket server and agent (round trip %s)": "Befehle laufen über Control Plane, Socket-Server und Agent (Umlaufzeit %s)",
+	"Run 'fixpanic agent status' to check the agent is connected":                     "Führen Sie 'fixpanic agent status' aus, um zu prüfen, ob der Agent verbunden ist",
+	"Run 'fixpanic agent logs' to see whether the command reached the agent":          "Führen Sie 'fixpanic agent logs' aus, um zu sehen, ob der Befehl den Agent erreicht hat",
+	"Run 'fixpanic agent doctor' to check the agent's confinement isn't blocking it":  "Führen Sie 'fixpanic agent doctor' aus, um zu prüfen, dass die Einschränkung des Agents ihn nicht blockiert",
+	"Run the smoke test again; if it keeps failing contact support@fixpanic.com":      "Führen Sie den Test erneut aus; schlägt er weiterhin fehl, wenden Sie sich an support@fixpanic.com",
+	"Check that %s is reachable from this host":                                       "Prüfen Sie, ob %s von diesem Host erreichbar ist",
+	"Run 'fixpanic network check' to diagnose connectivity":                           "Führen Sie 'fixpanic network check' aus, um die Verbindung zu diagnostizieren",
+
+	// agent sessions
+	"No active sessions":    "Keine aktiven Sitzungen",
+	"Terminated session %s": "Sitzung %s beendet",
+	"Pass the IDs shown by 'fixpanic agent sessions list', or --all":     "Geben Sie die von 'fixpanic agent sessions list' angezeigten IDs oder --all an",
+	"Run 'fixpanic agent sessions list' to see the active sessions":      "Führen Sie 'fixpanic agent sessions list' aus, um die aktiven Sitzungen zu sehen",
+	"Upgrade the agent with 'fixpanic agent upgrade' to manage sessions": "Aktualisieren Sie den Agent mit 'fixpanic agent upgrade', um Sitzungen zu verwalten",
+
+	// agent policy
+	"No policy is set: the platform may run any command":     "Keine Richtlinie gesetzt: die Plattform darf jeden Befehl ausführen",
+	"Can't tell whether the agent has loaded the policy: %v": "Es lässt sich nicht feststellen, ob der Agent die Richtlinie geladen hat: %v",
+	"The running agent enforces this policy":                 "Der laufende Agent setzt diese Richtlinie durch",
+	"The running agent enforces a different policy":          "Der laufende Agent setzt eine andere Richtlinie durch",
+	"Run 'fixpanic agent restart' for the agent to load it":  "Führen Sie 'fixpanic agent restart' aus, damit der Agent sie lädt",
+	"Added %s entry %q":                                    "Eintrag %[2]q zu %[1]s hinzugefügt",
+	"Removed %s entry %q":                                  "Eintrag %[2]q aus %[1]s entfernt",
+	"The policy already has the %s entry %q":               "Die Richtlinie enthält den Eintrag %[2]q in %[1]s bereits",
+	"The policy has no %s entry %q":                        "Die Richtlinie enthält keinen Eintrag %[2]q in %[1]s",
+	"No policy is set":                                     "Keine Richtlinie gesetzt",
+	"Removed the policy; the platform may run any command": "Richtlinie entfernt; die Plattform darf jeden Befehl ausführen",
+	"Run 'fixpanic agent restart' for the agent to load the policy, then 'fixpanic agent policy verify'": "Führen Sie 'fixpanic agent restart' aus, damit der Agent die Richtlinie lädt, und danach 'fixpanic agent policy verify'",
+	"Upgrade the agent with 'fixpanic agent upgrade' to enforce the policy":                              "Aktualisieren Sie den Agent mit 'fixpanic agent upgrade', um die Richtlinie durchzusetzen",
+	"The running agent enforces the configured policy":                                                   "Der laufende Agent setzt die konfigurierte Richtlinie durch",
+	"The policy allows %q": "Die Richtlinie erlaubt %q",
+	"Allowed commands":     "Erlaubte Befehle",
+	"Denied commands":      "Verweigerte Befehle",
+	"Blocked paths":        "Gesperrte Pfade",
+	"Time windows":         "Zeitfenster",
+	"any not denied":       "alle nicht verweigerten",
+	"none":                 "keine",
+	"any time":             "jederzeit",
+
+	// agent panic-stop
+	"Emergency stop engaged: %s":         "Notstopp aktiviert: %s",
+	"Failed to find agent processes: %v": "Agent-Prozesse konnten nicht gefunden werden: %v",
+	"Failed to kill agent process %d":    "Agent-Prozess %d konnte nicht beendet werden",
+	"Killed agent process %d":            "Agent-Prozess %d beendet",
+	"Failed to stop the service: %v":     "Dienst konnte nicht gestoppt werden: %v",
+	"Failed to disable the service: %v":  "Dienst konnte nicht deaktiviert werden: %v",
+	"Failed to enable the service: %v":   "Dienst konnte nicht aktiviert werden: %v",
+	"Kill them by hand, e.g. 'sudo kill -9 <pid>'; the emergency stop keeps them from being restarted": "Beenden Sie sie von Hand, z. B. mit 'sudo kill -9 <pid>'; der Notstopp verhindert einen Neustart",
+	"The agent is stopped; nothing will start it until the emergency stop is lifted":                   "Der Agent ist gestoppt; nichts startet ihn, bis der Notstopp aufgehoben ist",
+	"Run 'fixpanic agent panic-reset' once the incident is resolved":                                   "Führen Sie 'fixpanic agent panic-reset' aus, sobald der Vorfall behoben ist",
+	"The emergency stop is not engaged":                                                                "Der Notstopp ist nicht aktiviert",
+	"The emergency stop is engaged%s":                                                                  "Der Notstopp ist aktiviert%s",
+	"Pass --force to lift it without asking":                                                           "Geben Sie --force an, um ihn ohne Rückfrage aufzuheben",
+	"Allow the platform to reach this host again? [y/N]:":                                              "Der Plattform den Zugriff auf diesen Host wieder erlauben? [y/N]:",
+	"The emergency stop stays engaged":                                                                 "Der Notstopp bleibt aktiviert",
+	"Emergency stop lifted":                                                                            "Notstopp aufgehoben",
+	"The watchdog starts the agent again within a few seconds":                                         "Der Watchdog startet den Agent in wenigen Sekunden wieder",
+	"Run 'fixpanic agent start' to start the agent":                                                    "Führen Sie 'fixpanic agent start' aus, um den Agent zu starten",
+	"engaging the emergency stop requires root":                                                        "Das Aktivieren des Notstopps erfordert Root-Rechte",
+
+	// agent downloads
+	"Fetching the checksums of %s":                                        "Prüfsummen von %s werden abgerufen",
+	"Failed to verify the checksum manifest":                              "Prüfsummenmanifest konnte nicht verifiziert werden",
+	"Releases published before signed checksum manifests can't be pulled": "Releases, die vor signierten Prüfsummenmanifesten veröffentlicht wurden, können nicht abgerufen werden",
+
+	// agent sbom
+	"FixPanic Agent is not installed, showing the CLI only":                                  "FixPanic Agent ist nicht installiert, es wird nur die CLI angezeigt",
+	"Software Bill of Materials":                                                             "Software-Stückliste (SBOM)",
+	"Downloading the SBOM of agent %s...":                                                    "SBOM von Agent %s wird heruntergeladen...",
+	"Could not download the SBOM of agent %s":                                                "SBOM von Agent %s konnte nicht heruntergeladen werden",
+	"Checksum verified against the signed release manifest":                                  "Prüfsumme gegen das signierte Release-Manifest verifiziert",
+	"Try the other format with --format, or upgrade the agent with 'fixpanic agent upgrade'": "Versuchen Sie das andere Format mit --format oder aktualisieren Sie den Agent mit 'fixpanic agent upgrade'",
+	"Install a released agent with 'fixpanic agent upgrade'":                                 "Installieren Sie einen veröffentlichten Agent mit 'fixpanic agent upgrade'",
+	"Pass --component agent or --component cli":                                              "Geben Sie --component agent oder --component cli an",
+	"--raw prints one document":                                                              "--raw gibt ein einzelnes Dokument aus",
+	"unknown component %q: use agent, cli or all":                                            "Unbekannte Komponente %q: verwenden Sie agent, cli oder all",
+	"agent %s publishes no %s SBOM: %w":                                                      "Agent %s veröffentlicht keine %s-SBOM: %w",
+	"the installed agent is not a release build: %w":                                         "Der installierte Agent ist kein Release-Build: %w",
+	"this binary carries no build information":                                               "Diese Binärdatei enthält keine Build-Informationen",
+	"failed to get agent version: %w":                                                        "Agent-Version konnte nicht ermittelt werden: %w",
+	"Component":                                                                              "Komponente",
+	"Format":                                                                                 "Format",
+	"Source":                                                                                 "Quelle",
+	"Provenance":                                                                             "Herkunftsnachweis",
+	"Created":                                                                                "Erstellt",
+	"Created by":                                                                             "Erstellt von",
+	"Packages":                                                                               "Pakete",
+	"embedded in the binary":                                                                 "in die Binärdatei eingebettet",
+	"build information of the Go toolchain":                                                  "Build-Informationen der Go-Toolchain",
+	"signed release manifest":                                                                "signiertes Release-Manifest",
+	"release download, unsigned":                                                             "Release-Download, unsigniert",
+
+	// agent update notices
+	"Agent %s is available (installed: %s); run 'fixpanic agent upgrade' to update": "Agent %s ist verfügbar (installiert: %s); führen Sie 'fixpanic agent upgrade' aus, um zu aktualisieren",
+
+	// fleet logs
+	"Resuming: the logs of %d host(s) were already collected":                                 "Fortsetzung: Die Logs von %d Host(s) wurden bereits gesammelt",
+	"Collecting the logs since %s of %d host(s)...":                                           "Logs seit %s von %d Host(s) werden gesammelt...",
+	"The logs of %d host(s) could not be collected; run the same command again to retry them": "Die Logs von %d Host(s) konnten nicht gesammelt werden; führen Sie denselben Befehl erneut aus, um es noch einmal zu versuchen",
+	"Re-run with --since %s to resume it, or use another --output directory":                  "Mit --since %s erneut ausführen, um sie fortzusetzen, oder ein anderes --output-Verzeichnis verwenden",
+
+	// drain
+	"The agent no longer accepts new sessions":                                                        "Der Agent nimmt keine neuen Sitzungen mehr an",
+	"Waiting for %d active session(s) to end (up to %s)...":                                           "Warten auf das Ende von %d aktiven Sitzung(en) (bis zu %s)...",
+	"All sessions ended":                                                                              "Alle Sitzungen beendet",
+	"The agent can't refuse new sessions; only waiting for the active ones to end":                    "Der Agent kann neue Sitzungen nicht ablehnen; es wird nur auf das Ende der aktiven gewartet",
+	"Run 'fixpanic agent sessions list' to see who is connected":                                      "Führen Sie 'fixpanic agent sessions list' aus, um zu sehen, wer verbunden ist",
+	"Wait longer with --drain-timeout, or end the sessions with 'fixpanic agent sessions kill --all'": "Mit --drain-timeout länger warten oder die Sitzungen mit 'fixpanic agent sessions kill --all' beenden",
+	"Restart the agent with 'fixpanic agent restart' to accept new sessions again":                    "Starten Sie den Agent mit 'fixpanic agent restart' neu, damit er wieder neue Sitzungen annimmt",
+
+	// fleet upgrade
+	"Checking the agents of %d host(s)...": "Die Agents von %d Host(s) werden geprüft...",
+	"No host needs an upgrade to %s":       "Kein Host muss auf %s aktualisiert werden",
+	"Upgrading %d canary host(s) to %s...": "%d Canary-Host(s) werden auf %s aktualisiert...",
+	"Baking the canaries until %s...":      "Die Canaries werden bis %s beobachtet...",
+	"The canaries are healthy on %s":       "Die Canaries laufen fehlerfrei mit %s",
+	"Upgrading %d host(s) to %s...":        "%d Host(s) werden auf %s aktualisiert...",
+	"Rolling back %d canary host(s)...":    "%d Canary-Host(s) werden zurückgesetzt...",
+	"No other host was upgraded; the upgraded canaries were rolled back to their previous version":                                                "Kein weiterer Host wurde aktualisiert; die aktualisierten Canaries wurden auf ihre vorherige Version zurückgesetzt",
+	"No other host was upgraded, but %s could not be rolled back; run 'fixpanic agent upgrade --agent-version' with their previous version there": "Kein weiterer Host wurde aktualisiert, aber %s konnte(n) nicht zurückgesetzt werden; führen Sie dort 'fixpanic agent upgrade --agent-version' mit der vorherigen Version aus",
+	"Run the same command again to retry them; upgraded hosts are skipped":                                                                        "Führen Sie denselben Befehl erneut aus, um es noch einmal zu versuchen; aktualisierte Hosts werden übersprungen",
+	"Pass the release to upgrade to with --agent-version":                                                                                         "Geben Sie das Ziel-Release mit --agent-version an",
+
+	// fleet labels
+	"Labels of %s: %s":         "Labels von %s: %s",
+	"%s has no labels anymore": "%s hat keine Labels mehr",
+	"Pass a host list with --hosts, or label hosts with 'fixpanic fleet label' and select them with --selector": "Übergeben Sie eine Host-Liste mit --hosts, oder versehen Sie Hosts mit 'fixpanic fleet label' mit Labels und wählen Sie sie mit --selector aus",
+	"Run 'fixpanic fleet label' to list the labeled hosts":                                                      "Führen Sie 'fixpanic fleet label' aus, um die Hosts mit Labels aufzulisten",
+}
@@ -0,0 +1,753 @@
+package i18n
+
+// japanese holds the Japanese translations
+var japanese = map[string]string{
+	// Errors
+	"Error: %v":                                      "エラー: %v",
+	"What you can do:":                               "対処方法:",
+	"FixPanic Agent is not installed":                "FixPanic エージェントがインストールされていません",
+	"FixPanic Agent not installed":                   "FixPanic エージェントがインストールされていません",
+	"agent is not installed":                         "エージェントがインストールされていません",
+	"FixPanic Agent is already installed":            "FixPanic エージェントは既にインストールされています",
+	"invalid configuration: %w":                      "設定が無効です: %w",
+	"failed to load configuration: %w":               "設定を読み込めませんでした: %w",
+	"invalid socket server address: %w":              "ソケットサーバーのアドレスが無効です: %w",
+	"connection test failed":                         "接続テストに失敗しました",
+	"failed to fetch latest release: %w":             "最新リリースを取得できませんでした: %w",
+	"failed to download new version: %w":             "新しいバージョンをダウンロードできませんでした: %w",
+	"another fixpanic operation is in progress (%s)": "別の fixpanic の操作が実行中です (%s)",
+	"another fixpanic operation is in progress":      "別の fixpanic の操作が実行中です",
+	"another watchdog is already running (PID: %d)":  "別のウォッチドッグが既に実行中です (PID: %d)",
+	"'%s' modifies the installation and is disabled in read-only mode (cli.read_only / FIXPANIC_READ_ONLY)": "'%s' はインストールを変更するため、読み取り専用モードでは無効です (cli.read_only / FIXPANIC_READ_ONLY)",
+
+	// Remediation hints
+	"Install the agent with 'fixpanic agent install --agent-id=<id> --api-key=<key>'":        "'fixpanic agent install --agent-id=<id> --api-key=<key>' でエージェントをインストールしてください",
+	"Regenerate the configuration with 'fixpanic agent install --force'":                     "'fixpanic agent install --force' で設定を再生成してください",
+	"Wait for the other operation to finish, or retry with --lock-timeout=5m to wait for it": "別の操作が終わるまで待つか、--lock-timeout=5m を付けて再実行してください",
+	"Use --force to reinstall":                                                                                 "再インストールするには --force を指定してください",
+	"Run 'fixpanic agent upgrade' to update the agent binary":                                                  "エージェントのバイナリを更新するには 'fixpanic agent upgrade' を実行してください",
+	"Check the values passed to --agent-id, --api-key and --socket-server":                                     "--agent-id、--api-key、--socket-server の値を確認してください",
+	"Check that %s exists and is valid YAML":                                                                   "%s が存在し、正しい YAML であることを確認してください",
+	"Fix the reported value in %s":                                                                             "%s の該当する値を修正してください",
+	"Check that your firewall allows outbound TCP connections to %s":                                           "ファイアウォールが %s への TCP 送信接続を許可しているか確認してください",
+	"Verify the socket server address with --socket-server":                                                    "--socket-server でソケットサーバーのアドレスを確認してください",
+	"Run 'fixpanic agent logs' to see why the agent failed to start":                                           "'fixpanic agent logs' でエージェントが起動しなかった理由を確認してください",
+	"Run 'fixpanic agent doctor' to diagnose common problems":                                                  "'fixpanic agent doctor' でよくある問題を診断してください",
+	"Run 'fixpanic agent stop' to stop the running watchdog first":                                             "先に 'fixpanic agent stop' で実行中のウォッチドッグを停止してください",
+	"Run '%s --help' to see its usage":                                                                         "使い方は '%s --help' で確認してください",
+	"Run the command with --help to see its usage":                                                             "使い方は --help を付けて実行すると確認できます",
+	"Run '%s --help' to see its commands":                                                                      "コマンドの一覧は '%s --help' で確認してください",
+	"Did you mean '%s %s'?":                                                                                    "'%s %s' のことですか？",
+	"Did you mean --%s?":                                                                                       "--%s のことですか？",
+	"Run '%s' instead":                                                                                         "代わりに '%s' を実行してください",
+	"Check your internet connection and any proxy or firewall settings":                                        "インターネット接続とプロキシ・ファイアウォールの設定を確認してください",
+	"Run 'fixpanic agent test-connection' to diagnose connectivity":                                            "'fixpanic agent test-connection' で接続を診断してください",
+	"Re-run the command with sudo":                                                                             "sudo を付けてコマンドを再実行してください",
+	"Unset FIXPANIC_READ_ONLY or remove cli.read_only from ~/.fixpanic.yaml if this host should be modified":   "このホストを変更する場合は FIXPANIC_READ_ONLY を解除するか、~/.fixpanic.yaml から cli.read_only を削除してください",
+	"Don't install the release by other means: a missing or mismatching manifest can mean a tampered download": "他の方法でリリースをインストールしないでください。マニフェストが欠けている、または一致しない場合はダウンロードが改ざんされている可能性があります",
+	"Report it to security@fixpanic.com if it persists":                                                        "解決しない場合は security@fixpanic.com に報告してください",
+	"This fixpanic binary was built without RELEASE_SIGNING_KEY; use an official release of the CLI":           "この fixpanic バイナリは RELEASE_SIGNING_KEY なしでビルドされています。CLI の公式リリースを使用してください",
+
+	// agent install
+	"Installing Fixpanic Agent":                                              "Fixpanic エージェントをインストールしています",
+	"Detecting platform and configuration":                                   "プラットフォームと設定を検出しています",
+	"Running as non-root user. Agent will be installed in user directories.": "root 以外のユーザーで実行中です。エージェントはユーザーディレクトリにインストールされます。",
+	"Binary location":                                             "バイナリの場所",
+	"Config location":                                             "設定ファイルの場所",
+	"Creating necessary directories":                              "必要なディレクトリを作成しています",
+	"Checking for existing installation":                          "既存のインストールを確認しています",
+	"Ensuring latest agent binary":                                "最新のエージェントバイナリを確認しています",
+	"Creating agent configuration":                                "エージェントの設定を作成しています",
+	"Validating configuration":                                    "設定を検証しています",
+	"Configuration saved to: %s":                                  "設定を保存しました: %s",
+	"Setting up system service":                                   "システムサービスを設定しています",
+	"Removing old service if it exists":                           "古いサービスがあれば削除しています",
+	"Failed to remove old service: %v":                            "古いサービスを削除できませんでした: %v",
+	"Installing systemd service":                                  "systemd サービスをインストールしています",
+	"You can start the agent manually with: fixpanic agent start": "エージェントは次のコマンドで手動起動できます: fixpanic agent start",
+	"Failed to enable service: %v":                                "サービスを有効化できませんでした: %v",
+	"Agent service installed and started successfully":            "エージェントサービスをインストールし、起動しました",
+	"Systemd not available. You can start the agent manually with: fixpanic agent start": "systemd が利用できません。エージェントは次のコマンドで手動起動できます: fixpanic agent start",
+	"FixPanic agent installed successfully!":                                             "FixPanic エージェントのインストールが完了しました!",
+	"Agent ID":                                                                           "エージェント ID",
+	"Socket server":                                                                      "ソケットサーバー",
+	"The agent will start automatically on system boot.":                                 "エージェントはシステム起動時に自動的に起動します。",
+	"You can manage the service with:":                                                   "サービスは次のコマンドで管理できます:",
+
+	// agent upgrade
+	"Upgrading FixPanic Agent":                      "FixPanic エージェントをアップグレードしています",
+	"Checking agent installation":                   "エージェントのインストールを確認しています",
+	"Checking current agent version":                "現在のエージェントのバージョンを確認しています",
+	"Stopping agent for upgrade":                    "アップグレードのためエージェントを停止しています",
+	"Agent is not running, proceeding with upgrade": "エージェントは実行されていません。アップグレードを続行します",
+	"Upgrading agent binary":                        "エージェントのバイナリをアップグレードしています",
+	"Verifying upgrade":                             "アップグレードを検証しています",
+	"Agent was already on the latest version":       "エージェントは既に最新バージョンです",
+	"Agent upgraded successfully!":                  "エージェントのアップグレードが完了しました!",
+	"Upgraded: %s → %s":                             "アップグレード: %s → %s",
+	"Restarting agent":                              "エージェントを再起動しています",
+	"Agent restarted successfully with new version": "新しいバージョンでエージェントを再起動しました",
+
+	// agent start
+	"Starting FixPanic Agent":               "FixPanic エージェントを起動しています",
+	"Checking for existing agent processes": "既存のエージェントプロセスを確認しています",
+	"Agent installation verified":           "エージェントのインストールを確認しました",
+	"Starting agent service":                "エージェントサービスを起動しています",
+	"Checking service status":               "サービスの状態を確認しています",
+
+	// config migrate
+	"failed to migrate configuration: %w":                           "設定を移行できませんでした: %w",
+	"Migrating Agent Configuration":                                 "エージェントの設定を移行しています",
+	"Configuration file":                                            "設定ファイル",
+	"Configuration is already at version %d":                        "設定は既にバージョン %d です",
+	"Resulting configuration:":                                      "移行後の設定:",
+	"Dry run: nothing was written. Run without --dry-run to apply.": "ドライラン: 何も書き込まれていません。適用するには --dry-run を付けずに実行してください。",
+	"Configuration migrated to version %d":                          "設定をバージョン %d に移行しました",
+	"Backup":                                                        "バックアップ",
+
+	// agent diff
+	"configuration drift detected": "設定の差分が見つかりました",
+	"Run 'fixpanic agent diff --accept' to re-render the files (edited versions are kept as .bak)": "'fixpanic agent diff --accept' でファイルを再生成してください (編集済みのファイルは .bak として保存されます)",
+	"Agent Configuration Drift": "エージェント設定の差分",
+	"Configuration":             "設定",
+	"Service unit":              "サービスユニット",
+	"No drift":                  "差分はありません",
+	"File is missing":           "ファイルがありません",
+	"On-disk files match what this CLI version generates": "ファイルはこの CLI バージョンが生成する内容と一致しています",
+	"Re-rendered %s": "%s を再生成しました",
+	"Run 'fixpanic agent restart' for the agent to pick up the changes": "変更を反映するには 'fixpanic agent restart' を実行してください",
+
+	// config set and profiles
+	"Run 'fixpanic config profiles' to list the available profiles": "'fixpanic config profiles' で利用可能なプロファイルを確認してください",
+	"Profile":    "プロファイル",
+	"Updated %s": "%s を更新しました",
+	"Old value":  "変更前の値",
+	"New value":  "変更後の値",
+	"Balanced limits suitable for most servers":                                  "ほとんどのサーバーに適したバランスの取れた上限",
+	"More concurrent connections and longer tool timeouts for busy hosts":        "負荷の高いホスト向けに同時接続数を増やし、ツールのタイムアウトを延長",
+	"Few concurrent connections and short timeouts for small VMs and containers": "小規模な VM やコンテナ向けに同時接続数を抑え、タイムアウトを短縮",
+	"Verbose logging and generous timeouts for troubleshooting":                  "トラブルシューティング向けの詳細なログと長めのタイムアウト",
+
+	// Cancellation
+	"interrupted":                    "中断されました",
+	"timed out after %s: %w":         "%s 後にタイムアウトしました: %w",
+	"Re-run with a longer --timeout": "--timeout を長くして再実行してください",
+
+	// cleanup-temp
+	"Cleaning Up Temporary Files":    "一時ファイルを削除しています",
+	"No stale temporary files found": "古い一時ファイルは見つかりませんでした",
+	"Failed to remove %s: %v":        "%s を削除できませんでした: %v",
+	"Dry run: %d item(s) would be removed. Run without --dry-run to remove them.": "ドライラン: %d 件が削除対象です。削除するには --dry-run を付けずに実行してください。",
+	"Removed %d item(s), freed %s":           "%d 件を削除し、%s を解放しました",
+	"failed to scan for temporary files: %w": "一時ファイルを検索できませんでした: %w",
+
+	// Disk space preflight
+	"Not enough disk space": "ディスクの空き容量が不足しています",
+	"Free up space in %s":   "%s の空き容量を確保してください",
+	"Run 'fixpanic cleanup-temp' to remove files left behind by interrupted runs": "'fixpanic cleanup-temp' で中断された実行の残りファイルを削除してください",
+
+	// upgrade verification
+	"Reported version": "報告されたバージョン",
+
+	// Environment detection
+	"Environment": "環境",
+	"WSL 1 translates Linux system calls and has limited binfmt support, so the agent binary may fail to execute":      "WSL 1 は Linux のシステムコールを変換しており binfmt のサポートも限定的なため、エージェントのバイナリが実行できない場合があります",
+	"Convert the distribution to WSL 2 with 'wsl --set-version <distro> 2' from Windows":                               "Windows 側で 'wsl --set-version <distro> 2' を実行し、ディストリビューションを WSL 2 に変換してください",
+	"systemd is not enabled in this WSL distribution, so the agent can't run as a service":                             "この WSL ディストリビューションでは systemd が有効になっていないため、エージェントをサービスとして実行できません",
+	"Enable it with '[boot] systemd=true' in /etc/wsl.conf and run 'wsl --shutdown', or use 'fixpanic agent watchdog'": "/etc/wsl.conf に '[boot] systemd=true' を追加して 'wsl --shutdown' を実行するか、'fixpanic agent watchdog' を使用してください",
+	"systemd is not running in this container, so no service will be installed":                                        "このコンテナでは systemd が動作していないため、サービスはインストールされません",
+	"Run 'fixpanic agent watchdog' as the container entrypoint to keep the agent running":                              "エージェントを動かし続けるには 'fixpanic agent watchdog' をコンテナのエントリポイントとして実行してください",
+
+	// Permission policy
+	"Applying file permissions":                "ファイルのパーミッションを適用しています",
+	"%d file(s) violate the permission policy": "%d 個のファイルがパーミッションのポリシーに違反しています",
+	"Re-apply the policy with 'fixpanic agent upgrade' or 'fixpanic agent install --force'": "'fixpanic agent upgrade' または 'fixpanic agent install --force' でポリシーを再適用してください",
+
+	// self uninstall
+	"Uninstalling FixPanic CLI":                                                       "FixPanic CLI をアンインストールしています",
+	"the agent is still installed and can't be managed without the CLI":               "エージェントがまだインストールされており、CLI なしでは管理できません",
+	"Run 'fixpanic agent uninstall' first, or pass --keep-agent to leave it in place": "先に 'fixpanic agent uninstall' を実行するか、残す場合は --keep-agent を指定してください",
+	"Removed %s":                  "%s を削除しました",
+	"Removed empty directory: %s": "空のディレクトリを削除しました: %s",
+	"Removed %d item(s). The FixPanic CLI has been uninstalled.": "%d 件を削除しました。FixPanic CLI をアンインストールしました。",
+
+	// Telemetry
+	"Telemetry disabled. Queued events were deleted.":                                                   "テレメトリを無効にしました。送信待ちのイベントは削除されました。",
+	"Telemetry enabled. Thank you for helping improve FixPanic!":                                        "テレメトリを有効にしました。FixPanic の改善にご協力いただきありがとうございます!",
+	"DO_NOT_TRACK or FIXPANIC_TELEMETRY is set, so nothing will be sent until it is unset":              "DO_NOT_TRACK または FIXPANIC_TELEMETRY が設定されているため、解除されるまで何も送信されません",
+	"Run 'fixpanic telemetry status' to see what is collected, or 'fixpanic telemetry off' to opt out.": "収集内容は 'fixpanic telemetry status' で確認でき、'fixpanic telemetry off' でオプトアウトできます。",
+	"Telemetry":     "テレメトリ",
+	"Install ID":    "インストール ID",
+	"Changed":       "変更日時",
+	"Queued events": "送信待ちのイベント",
+	"State file":    "状態ファイル",
+
+	// Crash reports
+	"fixpanic crashed unexpectedly: %v":                                "fixpanic が予期せず異常終了しました: %v",
+	"fixpanic crashed. Send the crash report (%s) to FixPanic? [y/N]:": "fixpanic が異常終了しました。クラッシュレポート (%s) を FixPanic に送信しますか? [y/N]:",
+	"Could not send the crash report: %v":                              "クラッシュレポートを送信できませんでした: %v",
+	"Crash report sent. Thank you!":                                    "クラッシュレポートを送信しました。ありがとうございます!",
+	"A crash report was saved to %s":                                   "クラッシュレポートを %s に保存しました",
+	"Please include it when contacting support@fixpanic.com":           "support@fixpanic.com へのお問い合わせの際に添付してください",
+	"Please report this at support@fixpanic.com with the output above": "上記の出力を添えて support@fixpanic.com にご報告ください",
+
+	// agent top
+	"--interval must be at least 100ms":    "--interval は 100ms 以上である必要があります",
+	"the agent is not running":             "エージェントが実行されていません",
+	"Start it with 'fixpanic agent start'": "'fixpanic agent start' で起動してください",
+	"agent process %d exited":              "エージェントプロセス %d が終了しました",
+	"Resource usage is unavailable: %v":    "リソース使用量を取得できません: %v",
+	"Run as root to see connection counts": "接続数を表示するには root で実行してください",
+
+	// network check
+	"HTTPS proxy":                         "HTTPS プロキシ",
+	"DNS lookup failed for %s":            "%s の DNS 解決に失敗しました",
+	"%d required endpoint(s) unreachable": "%d 件の必須エンドポイントに到達できません",
+	"Allow outbound TCP to the endpoints above, or route them through your HTTPS proxy":                             "上記エンドポイントへの送信 TCP を許可するか、HTTPS プロキシ経由でルーティングしてください",
+	"Exclude the FixPanic and GitHub hosts from TLS inspection, or add the inspection CA to the system trust store": "FixPanic と GitHub のホストを TLS インスペクションの対象外にするか、インスペクション用 CA をシステムの信頼ストアに追加してください",
+	"Network check finished with %d warning(s)":                                                                     "ネットワークチェックは %d 件の警告で終了しました",
+	"All endpoints are reachable":                                                                                   "すべてのエンドポイントに到達できます",
+	"Allow outbound TCP connections to these endpoints in your firewall:":                                           "ファイアウォールでこれらのエンドポイントへの送信 TCP 接続を許可してください:",
+	"Suggested firewall rules to allow the blocked endpoints:":                                                      "ブロックされたエンドポイントを許可するファイアウォールルールの提案:",
+	"GitHub's addresses change over time; prefer allowing these hosts by name in an HTTPS proxy":                    "GitHub のアドレスは変化するため、HTTPS プロキシでホスト名による許可を推奨します",
+
+	// network bench
+	"--samples must be at least 1":                                             "--samples は 1 以上である必要があります",
+	"--duration must be at least 1s":                                           "--duration は 1s 以上である必要があります",
+	"Measuring connect latency (%d samples)":                                   "接続レイテンシを測定中 (%d サンプル)",
+	"Latency test failed":                                                      "レイテンシテストに失敗しました",
+	"Run 'fixpanic network check' to find out what is blocking the connection": "'fixpanic network check' を実行して接続を妨げている原因を確認してください",
+	"Latency measured":                                                         "レイテンシを測定しました",
+	"Measuring reconnect time":                                                 "再接続時間を測定中",
+	"Reconnect test failed: %v":                                                "再接続テストに失敗しました: %v",
+	"Reconnect measured":                                                       "再接続時間を測定しました",
+	"Reconnect time":                                                           "再接続時間",
+	"Measuring upload throughput (%v)":                                         "アップロードスループットを測定中 (%v)",
+	"Throughput test failed: %v":                                               "スループットテストに失敗しました: %v",
+	"The socket server closed the test session before throughput could be measured": "スループットを測定する前にソケットサーバーがテストセッションを閉じました",
+	"Throughput measured": "スループットを測定しました",
+	"Upload throughput":   "アップロードスループット",
+	"The socket server closed the test session after %v; the result may be imprecise": "ソケットサーバーが %v 後にテストセッションを閉じたため、結果が不正確な可能性があります",
+	"This link may be too slow for remote-debug sessions:":                            "この回線はリモートデバッグセッションには遅すぎる可能性があります:",
+	"This link can support remote-debug sessions":                                     "この回線はリモートデバッグセッションに対応できます",
+
+	// Progress events
+	"--events-fd and --events-file are mutually exclusive":                             "--events-fd と --events-file は同時に指定できません",
+	"invalid events file descriptor %d: 0-2 are reserved for stdin, stdout and stderr": "無効なイベントファイルディスクリプタ %d: 0-2 は stdin、stdout、stderr 用に予約されています",
+
+	// Install plans
+	"--plan and --apply are mutually exclusive":                    "--plan と --apply は同時に指定できません",
+	"--%s can't be combined with --apply; it is fixed by the plan": "--%s は --apply と併用できません。値はプランで固定されています",
+	"Create a new plan with the changed options":                   "変更したオプションで新しいプランを作成してください",
+	"--api-key is required with --apply":                           "--apply には --api-key が必要です",
+	"Plan":                                                         "プラン",
+	"Downloading planned agent binary":                             "プランのエージェントバイナリをダウンロード中",
+	"Use --force to plan a reinstall":                              "再インストールを計画するには --force を使用してください",
+	"Create a new plan on this host with 'fixpanic agent install --plan' and review it again": "このホストで 'fixpanic agent install --plan' により新しいプランを作成し、再度確認してください",
+	"Run as the same user and with the same --prefix as when the plan was created":            "プラン作成時と同じユーザーと --prefix で実行してください",
+	"plan was created for %s, this host is %s":                                                "プランは %s 用に作成されましたが、このホストは %s です",
+	"%s would differ from the plan":                                                           "%s がプランと異なります",
+
+	// artifacts pull and offline install
+	"Pulling FixPanic Agent Artifacts": "FixPanic エージェントのアーティファクトを取得しています",
+	"Fetching release %s":              "リリース %s を取得しています",
+	"Failed to fetch release":          "リリースを取得できませんでした",
+	"Release %s":                       "リリース %s",
+	"Downloading %s":                   "%s をダウンロードしています",
+	"Failed to download %s":            "%s をダウンロードできませんでした",
+	"%s verified":                      "%s を検証しました",
+	"invalid platform %q: expected GOOS/GOARCH, e.g. linux/amd64": "無効なプラットフォーム %q: GOOS/GOARCH 形式で指定してください（例: linux/amd64）",
+	"%s already holds a bundle of %s":                             "%s には既に %s のバンドルがあります",
+	"Use a separate --dest directory for each release":            "リリースごとに別の --dest ディレクトリを使用してください",
+	"%s is %d bytes, the release lists %d":                        "%s は %d バイトですが、リリースには %d と記載されています",
+	"release %s has no agent build for %s":                        "リリース %s には %s 用のエージェントビルドがありません",
+	"Bundle of %s ready in %s":                                    "%s のバンドルを %s に用意しました",
+	"Artifacts":                                                   "アーティファクト",
+	"Manifest":                                                    "マニフェスト",
+	"Copy the directory to the target hosts and install with:":    "ディレクトリを対象ホストにコピーし、次のコマンドでインストールしてください:",
+	"Installing agent binary from artifact bundle":                "アーティファクトバンドルからエージェントバイナリをインストールしています",
+	"bundle %s has a different agent build than the plan":         "バンドル %s のエージェントビルドはプランと異なります",
+	"Use the bundle the plan was created from":                    "プランの作成に使用したバンドルを使用してください",
+	"bundle %s has no %s build":                                   "バンドル %s には %s のビルドがありません",
+	"FixPanic Agent installed to %s":                              "FixPanic エージェントを %s にインストールしました",
+	"Using cached download":                                       "キャッシュ済みのダウンロードを使用します",
+
+	// concurrent connection tests
+	"connection test failed for %d of %d endpoints: %s":                           "%d/%d 個のエンドポイントで接続テストに失敗しました: %s",
+	"Check that your firewall allows outbound TCP connections to these endpoints": "ファイアウォールがこれらのエンドポイントへの送信 TCP 接続を許可しているか確認してください",
+	"invalid endpoint %q: %w":                                                     "無効なエンドポイント %q: %w",
+
+	// network dns
+	"DNS check finished with %d warning(s)":     "DNS チェックは %d 件の警告で完了しました",
+	"DNS answers are consistent":                "DNS の応答は一致しています",
+	"the system resolver failed for %d host(s)": "システムリゾルバーが %d 個のホストで失敗しました",
+	"Check the nameservers in /etc/resolv.conf (or your network settings) and any DNS overrides for these hosts": "/etc/resolv.conf（またはネットワーク設定）のネームサーバーと、これらのホストの DNS 上書き設定を確認してください",
+
+	// config set service
+	"Run 'fixpanic agent diff --accept' to regenerate the service unit": "サービスユニットを再生成するには 'fixpanic agent diff --accept' を実行してください",
+
+	// agent set-log-level
+	"Setting Agent Log Level":     "エージェントのログレベルを設定",
+	"Reverting Agent Log Level":   "エージェントのログレベルを元に戻しています",
+	"Old level":                   "変更前のレベル",
+	"New level":                   "新しいレベル",
+	"Log level":                   "ログレベル",
+	"Revert timer":                "復元タイマー",
+	"Revert process":              "復元プロセス",
+	"invalid log level %q":        "無効なログレベル %q",
+	"Use one of: %s":              "次のいずれかを使用してください: %s",
+	"--duration must be positive": "--duration は正の値である必要があります",
+	"Log level set to %s":         "ログレベルを %s に設定しました",
+	"Log level set to %s until %s, then back to %s":                   "ログレベルを %[2]s まで %[1]s に設定しました。その後 %[3]s に戻ります",
+	"Run 'fixpanic agent set-log-level %s' when you are done":         "完了したら 'fixpanic agent set-log-level %s' を実行してください",
+	"Log level was changed to %s in the meantime; not reverting":      "ログレベルはその間に %s に変更されたため、元に戻しません",
+	"Log level reverted to %s":                                        "ログレベルを %s に戻しました",
+	"Agent is not running; the level applies the next time it starts": "エージェントは実行されていません。次回起動時にレベルが適用されます",
+	"Restarting the agent to apply the log level":                     "ログレベルを適用するためにエージェントを再起動しています",
+	"Failed to remove pending log level revert: %v":                   "保留中のログレベル復元を削除できませんでした: %v",
+	"%s (reverts to %s at %s)":                                        "%s (%[3]s に %[2]s に戻ります)",
+
+	// agent exec
+	"failed to read the service environment: %w":                           "サービス環境を読み込めませんでした: %w",
+	"Pass --no-config to run the agent without its configuration":          "設定なしでエージェントを実行するには --no-config を指定してください",
+	"agent exited with status %d":                                          "エージェントはステータス %d で終了しました",
+	"See the agent's output above; the status is passed through unchanged": "上のエージェントの出力を確認してください。ステータスはそのまま返されます",
+
+	// versions
+	"--per-page must be between 1 and 100": "--per-page は 1 から 100 の間である必要があります",
+	"--page must be 1 or higher":           "--page は 1 以上である必要があります",
+	"failed to list releases: %w":          "リリースの一覧を取得できませんでした: %w",
+	"No releases found":                    "リリースが見つかりません",
+
+	// release notes
+	"No release notes between %s and %s":         "%s と %s の間にリリースノートはありません",
+	"Release notes for %s:":                      "%s のリリースノート:",
+	"Release notes from %s to %s (%d releases):": "%s から %s までのリリースノート (%d 件のリリース):",
+	"No release notes":                           "リリースノートはありません",
+	"Yanked: %s":                                 "取り下げ済み: %s",
+	"failed to fetch release notes: %w":          "リリースノートを取得できませんでした: %w",
+	"Could not fetch release notes: %v":          "リリースノートを取得できませんでした: %v",
+	"Agent is on the latest version (%s)":        "エージェントは最新バージョンです (%s)",
+	"release %s not found":                       "リリース %s が見つかりません",
+
+	// yanked releases
+	"Could not check whether %s was yanked: %v": "%s が取り下げられたかどうかを確認できませんでした: %v",
+	"no reason given": "理由は示されていません",
+	"%s was yanked (%s); installing it anyway because of --allow-yanked": "%s は取り下げられています (%s)。--allow-yanked が指定されたためインストールします",
+	"%s was yanked: %s": "%s は取り下げられました: %s",
+	"Wait for a fixed release, or pass --allow-yanked to install this one anyway": "修正版のリリースを待つか、--allow-yanked を指定してこのバージョンをインストールしてください",
+
+	// serve
+	"Listening on %s, which is reachable from other hosts; the API has no TLS": "他のホストから到達可能な %s で待ち受けています。API には TLS がありません",
+	"Serving the FixPanic API on http://%s/v1/":                                "FixPanic API を http://%s/v1/ で提供しています",
+	"Token file":                         "トークンファイル",
+	"Generated a new API token in %s":    "新しい API トークンを %s に生成しました",
+	"API: %s requested by %s":            "API: %[2]s が %[1]s を要求しました",
+	"failed to set up the API token: %w": "API トークンを設定できませんでした: %w",
+	"Run with sudo, or pass --token-file with a file you can write": "sudo で実行するか、書き込み可能なファイルを --token-file で指定してください",
+	"failed to listen on %s: %w":                                    "%s で待ち受けできませんでした: %w",
+	"Serving the FixPanic gRPC control interface on %s":             "FixPanic gRPC コントロールインターフェイスを %s で提供しています",
+	"The gRPC control interface failed: %v":                         "gRPC コントロールインターフェイスが失敗しました: %v",
+
+	// log disk usage
+	"Agent Log Disk Usage":                      "エージェントログのディスク使用量",
+	"Could not read the journal disk usage: %v": "ジャーナルのディスク使用量を読み取れませんでした: %v",
+	"Journal (all units)":                       "ジャーナル (全ユニット)",
+	"The journal is larger than %s; limit it with SystemMaxUse= in /etc/systemd/journald.conf": "ジャーナルが %s を超えています。/etc/systemd/journald.conf の SystemMaxUse= で制限してください",
+	"Free in %s": "%s の空き容量",
+	"Could not check for dropped messages: %v":                                            "破棄されたメッセージを確認できませんでした: %v",
+	"journald dropped %d agent message(s) in the last 24 hours because of its rate limit": "journald はレート制限により過去 24 時間にエージェントのメッセージを %d 件破棄しました",
+	"Raise the limit with 'sudo systemctl edit %s' and:":                                  "'sudo systemctl edit %s' で次の設定を追加して制限を引き上げてください:",
+	"Dropped messages (24h)":                                                              "破棄されたメッセージ (24 時間)",
+	"Log files":                                                                           "ログファイル",
+	"none in %s":                                                                          "%s にはありません",
+	"Log files total":                                                                     "ログファイル合計",
+
+	// Redaction
+	"Exported %d redacted log line(s) to %s":                                    "%d 行の秘匿化済みログを %s にエクスポートしました",
+	"Review the file before sharing it; only known secret formats are redacted": "共有する前にファイルを確認してください。既知の形式の機密情報のみが秘匿化されます",
+	"--export can't be combined with --follow":                                  "--export は --follow と併用できません",
+	"Fix the patterns in the redaction section of ~/.fixpanic.yaml":             "~/.fixpanic.yaml の redaction セクションのパターンを修正してください",
+
+	// Version history
+	"No version changes recorded":                            "記録されたバージョン変更はありません",
+	"Failed to record the installation: %v":                  "インストールを記録できませんでした: %v",
+	"Failed to record the version change in the history: %v": "バージョン変更を履歴に記録できませんでした: %v",
+
+	// Health checks
+	"Failed to record the health check: %v":             "ヘルスチェックを記録できませんでした: %v",
+	"Failed to notify the webhook: %v":                  "Webhook に通知できませんでした: %v",
+	"agent is unhealthy: %s":                            "エージェントが正常ではありません: %s",
+	"Run 'fixpanic agent doctor' to diagnose the agent": "'fixpanic agent doctor' を実行してエージェントを診断してください",
+	"Agent is healthy":                                  "エージェントは正常です",
+	"Agent is unhealthy":                                "エージェントが正常ではありません",
+	"Installed":                                         "インストール済み",
+	"Running":                                           "実行中",
+	"--interval must be at least %s":                    "--interval は %s 以上である必要があります",
+	"Health check scheduled every %s with %s.timer":     "%s ごとのヘルスチェックを %s.timer でスケジュールしました",
+	"See the results with 'fixpanic agent healthcheck status' or 'journalctl -u %s'": "結果は 'fixpanic agent healthcheck status' または 'journalctl -u %s' で確認できます",
+	"Health check scheduled with cron in %s":                                         "%s の cron でヘルスチェックをスケジュールしました",
+	"cron runs at most once a minute; the interval was rounded up to whole minutes":  "cron は最短 1 分間隔で実行されるため、間隔を分単位に切り上げました",
+	"See the results with 'fixpanic agent healthcheck status'":                       "結果は 'fixpanic agent healthcheck status' で確認できます",
+	"No scheduled health check is installed":                                         "スケジュールされたヘルスチェックはインストールされていません",
+	"Scheduled health check removed":                                                 "スケジュールされたヘルスチェックを削除しました",
+	"No health checks recorded":                                                      "記録されたヘルスチェックはありません",
+
+	// Self-healing
+	"Self-healing skipped: %v": "自己修復をスキップしました: %v",
+	"Self-healing: %d of %d failed checks before restarting the agent":    "自己修復: エージェント再起動まで %d / %d 回のチェック失敗",
+	"Self-healing gave up after %d restart(s); the agent needs attention": "自己修復は %d 回の再起動後に断念しました。エージェントの確認が必要です",
+	"Self-healing: next restart possible in %s":                           "自己修復: 次の再起動は %s 後に可能です",
+	"Self-healing skipped: the CLI is in read-only mode":                  "自己修復をスキップしました: CLI は読み取り専用モードです",
+	"Self-healing: restarting the agent (attempt %d of %d)":               "自己修復: エージェントを再起動しています (%d / %d 回目)",
+	"Self-healing restart failed: %v":                                     "自己修復による再起動に失敗しました: %v",
+	"Failed to record the self-healing state: %v":                         "自己修復の状態を記録できませんでした: %v",
+
+	// Clock skew
+	"certificate validation would fail: %s":                                                       "証明書の検証に失敗します: %s",
+	"the certificate of %s is not valid now":                                                      "%s の証明書は現在有効ではありません",
+	"Enable time synchronization with 'sudo timedatectl set-ntp true', or install chrony or ntpd": "'sudo timedatectl set-ntp true' で時刻同期を有効にするか、chrony または ntpd をインストールしてください",
+	"Run 'fixpanic agent doctor' to check the system clock and the certificate":                   "'fixpanic agent doctor' を実行してシステム時計と証明書を確認してください",
+
+	// TLS configuration
+	"--tls-cert-file and --tls-key-file must be given together":                                          "--tls-cert-file と --tls-key-file は同時に指定する必要があります",
+	"invalid TLS configuration: %w":                                                                      "TLS 設定が無効です: %w",
+	"Check the files passed to --tls-ca-file, --tls-cert-file and --tls-key-file":                        "--tls-ca-file、--tls-cert-file、--tls-key-file に渡したファイルを確認してください",
+	"Testing Agent Configuration":                                                                        "エージェント設定をテストしています",
+	"TLS is disabled; the agent's traffic is not encrypted":                                              "TLS が無効です。エージェントの通信は暗号化されていません",
+	"The CA bundle, client certificate and pins are ignored while TLS is disabled":                       "TLS が無効な間は CA バンドル、クライアント証明書、ピンは無視されます",
+	"CA certificate %q expired on %s":                                                                    "CA 証明書 %q は %s に期限切れになりました",
+	"Client certificate expired on %s":                                                                   "クライアント証明書は %s に期限切れになりました",
+	"Client certificate is not valid until %s":                                                           "クライアント証明書は %s まで有効になりません",
+	"Client certificate expires in %d day(s)":                                                            "クライアント証明書はあと %d 日で期限切れになります",
+	"Client key %s is readable by other users (mode %04o); run 'chmod 600 %s'":                           "クライアント鍵 %s は他のユーザーから読み取り可能です (モード %04o)。'chmod 600 %s' を実行してください",
+	"configuration has %d TLS problem(s)":                                                                "設定に %d 件の TLS の問題があります",
+	"Replace the certificates and run 'fixpanic config test' again":                                      "証明書を置き換えて 'fixpanic config test' を再実行してください",
+	"Configuration is valid":                                                                             "設定は有効です",
+	"Fix the paths with 'fixpanic config set app.tls_ca_file|app.tls_cert_file|app.tls_key_file <path>'": "'fixpanic config set app.tls_ca_file|app.tls_cert_file|app.tls_key_file <パス>' でパスを修正してください",
+	"Run 'fixpanic config test' to check the TLS settings":                                               "'fixpanic config test' を実行して TLS 設定を確認してください",
+	"CA certificates":    "CA 証明書",
+	"CA bundle":          "CA バンドル",
+	"Client certificate": "クライアント証明書",
+	"Expires":            "有効期限",
+
+	// Certificate pinning
+	"%s presented a certificate matching none of the pinned keys":                          "%s が提示した証明書はピン留めされたどの鍵とも一致しません",
+	"A proxy may be intercepting the connection; the agent will refuse it":                 "プロキシが接続を傍受している可能性があります。エージェントはこの接続を拒否します",
+	"If the server's key was rotated, update app.tls_pinned_spki with the pin shown above": "サーバーの鍵が更新された場合は、上に表示されたピンで app.tls_pinned_spki を更新してください",
+	"Pinned keys": "ピン留めされた鍵",
+
+	// TLS status
+	"TLS CERTIFICATE VERIFICATION IS DISABLED (%s: true): anyone on the network path can intercept the agent's traffic": "TLS 証明書の検証が無効です (%s: true): ネットワーク経路上の誰でもエージェントの通信を傍受できます",
+	"TLS enabled, certificates %s": "TLS 有効、証明書 %s",
+	"TLS disabled":                 "TLS 無効",
+	"system roots":                 "システムのルート証明書",
+	"%s, expires %s (%d days)":     "%s、有効期限 %s (%d 日)",
+	"Certificate":                  "証明書",
+	"%s (%d days)":                 "%s (%d 日)",
+	"Could not inspect the server certificate: %s":              "サーバー証明書を検査できませんでした: %s",
+	"Certificate chain verifies against the %s CA certificates": "証明書チェーンは %s の CA 証明書で検証されました",
+	"Certificate chain does not verify: %s":                     "証明書チェーンを検証できません: %s",
+	"Certificate matches pinned key %s":                         "証明書はピン留めされた鍵 %s と一致します",
+	"Turn verification back on with 'sudo fixpanic config set <key> false'; trust a private CA with app.tls_ca_file instead": "'sudo fixpanic config set <キー> false' で検証を再度有効にし、代わりに app.tls_ca_file でプライベート CA を信頼してください",
+	"No TLS problems found": "TLS の問題は見つかりませんでした",
+	"Agent TLS Status":      "エージェントの TLS ステータス",
+	"Run 'fixpanic agent tls status' for the full TLS settings": "TLS 設定の詳細は 'fixpanic agent tls status' を実行してください",
+
+	// Confinement
+	"The agent is confined by a system call filter and the AppArmor profile %s":           "エージェントはシステムコールフィルターと AppArmor プロファイル %s で制限されています",
+	"The agent is confined by a system call filter; AppArmor is not enabled on this host": "エージェントはシステムコールフィルターで制限されています。このホストでは AppArmor が有効ではありません",
+	"Run 'fixpanic agent doctor' to check that the confinement isn't blocking the agent":  "'fixpanic agent doctor' を実行して、制限がエージェントを妨げていないことを確認してください",
+	"--confine needs systemd; the agent runs unconfined":                                  "--confine には systemd が必要です。エージェントは制限なしで実行されます",
+
+	// Pinned agent versions
+	"Downloading agent %s":                                                                        "エージェント %s をダウンロードしています",
+	"Checksum manifest signature verified":                                                        "チェックサムマニフェストの署名を検証しました",
+	"Run 'fixpanic agent versions' to check that the release exists":                              "'fixpanic agent versions' を実行してリリースが存在することを確認してください",
+	"Releases published before signed checksum manifests can't be installed with --agent-version": "署名付きチェックサムマニフェスト導入前に公開されたリリースは --agent-version でインストールできません",
+	"Run 'fixpanic agent versions' to list the agent releases":                                    "'fixpanic agent versions' を実行してエージェントのリリースを一覧表示してください",
+
+	// Concurrent downloads
+	"Downloading agent %s and its checksums...":          "エージェント %s とチェックサムをダウンロードしています...",
+	"Downloading agent %s and its checksums... %s of %s": "エージェント %s とチェックサムをダウンロードしています... %s / %s",
+	"Downloading agent %s and its checksums... %s":       "エージェント %s とチェックサムをダウンロードしています... %s",
+	"Failed to download":                                 "ダウンロードに失敗しました",
+
+	// agent logs forward
+	"Forward Agent Logs":                 "エージェントログの転送",
+	"Wrote %s":                           "%s を書き込みました",
+	"Included it in %s":                  "%s に組み込みました",
+	"The agent logs are forwarded to %s": "エージェントログは %s に転送されます",
+	"Fluent Bit uploads with the credentials of its environment or the instance role": "Fluent Bit は環境変数またはインスタンスロールの認証情報でアップロードします",
+	"The agent logs are not forwarded":                                                "エージェントログは転送されていません",
+	"The agent logs are no longer forwarded":                                          "エージェントログの転送を停止しました",
+	"Restart %s to apply the configuration":                                           "設定を適用するには %s を再起動してください",
+	"Restarted %s":                                                                    "%s を再起動しました",
+
+	// agent metrics textfile
+	"Textfile refreshed every %s by %s.timer": "テキストファイルは %[2]s.timer により %[1]s ごとに更新されます",
+	"Textfile refreshed with cron in %s":      "テキストファイルは %s の cron で更新されます",
+	"No textfile refresh is installed":        "テキストファイルの更新はインストールされていません",
+	"Textfile refresh removed":                "テキストファイルの更新を削除しました",
+
+	// health endpoints
+	"Health endpoints disabled: failed to listen on %s: %v": "ヘルスエンドポイントを無効化しました: %s で待ち受けできません: %v",
+	"Health endpoints failed: %v":                           "ヘルスエンドポイントが失敗しました: %v",
+
+	// fleet report
+	"Collecting snapshots from %d host(s)...":                                             "%d 台のホストからスナップショットを収集しています...",
+	"Could not determine the latest agent release, pending upgrades are not reported: %v": "最新のエージェントリリースを特定できないため、保留中のアップグレードは報告されません: %v",
+	"Wrote the report of %d host(s) to %s":                                                "%d 台のホストのレポートを %s に書き込みました",
+	"%d host(s) could not be reached":                                                     "%d 台のホストに接続できませんでした",
+	"none of the %d host(s) could be reached":                                             "%d 台のホストのいずれにも接続できませんでした",
+	"ssh is not installed":                                                                "ssh がインストールされていません",
+
+	// Inventory
+	"Host Inventory": "ホストインベントリ",
+	"Could not identify the cloud instance: %v": "クラウドインスタンスを特定できませんでした: %v",
+	"Hostname":               "ホスト名",
+	"OS":                     "OS",
+	"Kernel":                 "カーネル",
+	"Architecture":           "アーキテクチャ",
+	"Cloud":                  "クラウド",
+	"Region":                 "リージョン",
+	"Account":                "アカウント",
+	"CLI version":            "CLI バージョン",
+	"Agent is not installed": "エージェントはインストールされていません",
+	"Agent version":          "エージェントのバージョン",
+	"Collecting the inventory of %d host(s)...": "%d 台のホストのインベントリを収集しています...",
+	"Wrote the inventory of %d host(s) to %s":   "%d 台のホストのインベントリを %[2]s に書き込みました",
+	"unknown format %q: use json or csv":        "不明な形式 %q です: json または csv を使用してください",
+
+	// Cloud metadata
+	"Querying the cloud instance metadata service":                                      "クラウドインスタンスのメタデータサービスに問い合わせています",
+	"failed to read the cloud instance metadata: %w":                                    "クラウドインスタンスのメタデータを読み取れませんでした: %w",
+	"Check that the instance metadata service is enabled and not blocked by a firewall": "インスタンスメタデータサービスが有効で、ファイアウォールでブロックされていないことを確認してください",
+	"Install without --cloud-metadata":                                                  "--cloud-metadata を付けずにインストールしてください",
+	"--cloud-metadata: this host is not an AWS, Google Cloud or Azure instance":         "--cloud-metadata: このホストは AWS、Google Cloud、Azure のインスタンスではありません",
+
+	// Package-managed agents
+	"The agent binary belongs to the %s package %s; the CLI took it over on %s":             "エージェントのバイナリは %s パッケージ %s に属しています。CLI が %s に管理を引き継ぎました",
+	"%s belongs to the %s package %s; the package manager upgrades it":                      "%s は %s パッケージ %s に属しています。パッケージマネージャーが更新します",
+	"Upgrade the package instead: %s":                                                       "代わりにパッケージを更新してください: %s",
+	"Run 'fixpanic agent upgrade --takeover' to have the CLI upgrade the agent from now on": "今後 CLI がエージェントを更新するには 'fixpanic agent upgrade --takeover' を実行してください",
+	"Taking over the agent binary from the %s package %s":                                   "%s パッケージ %s からエージェントのバイナリの管理を引き継ぎます",
+	"Hold the package so the package manager doesn't replace the binary again:":             "パッケージマネージャーがバイナリを再び置き換えないよう、パッケージを固定してください:",
+	"Failed to record the takeover: %v":                                                     "引き継ぎを記録できませんでした: %v",
+
+	// Install verification
+	"Waiting up to %s for the agent to connect to %s":                                                              "エージェントが %[2]s に接続するまで最大 %[1]s 待機しています",
+	"Agent is running and connected to the control plane (PID %d)":                                                 "エージェントは実行中で、コントロールプレーンに接続しています (PID %d)",
+	"the agent is running but socket server %s is unreachable: %w":                                                 "エージェントは実行中ですが、ソケットサーバー %s に到達できません: %w",
+	"Agent is running and the socket server is reachable":                                                          "エージェントは実行中で、ソケットサーバーに到達できます",
+	"The agent's connections can't be inspected without root; run 'fixpanic agent status' to confirm it connected": "root 権限がないとエージェントの接続を確認できません。'fixpanic agent status' で接続を確認してください",
+	"Last lines of %s:": "%s の最後の行:",
+	"Run 'fixpanic agent logs' to see what the agent reported":                         "'fixpanic agent logs' を実行してエージェントの出力を確認してください",
+	"Use --no-verify to install without waiting for the agent":                         "エージェントを待たずにインストールするには --no-verify を使用してください",
+	"the agent did not start within %s":                                                "エージェントが %s 以内に起動しませんでした",
+	"the agent did not stay running for %s within %s":                                  "エージェントは %[2]s 以内に %[1]s 間実行され続けませんでした",
+	"the agent did not connect to %s within %s":                                        "エージェントは %[2]s 以内に %[1]s に接続しませんでした",
+	"Check the agent ID and API key on the Fixpanic dashboard":                         "Fixpanic ダッシュボードでエージェント ID と API キーを確認してください",
+	"Agent started in the background (PID %d)":                                         "エージェントをバックグラウンドで起動しました (PID %d)",
+	"Verifying the agent connects":                                                     "エージェントの接続を検証しています",
+	"The agent isn't started in this environment, so its connection isn't verified":    "この環境ではエージェントを起動しないため、接続は検証されません",
+	"the agent was not started, so the installation can't be verified":                 "エージェントが起動されなかったため、インストールを検証できません",
+	"Run 'fixpanic agent start' and 'fixpanic agent logs' to see why it doesn't start": "'fixpanic agent start' と 'fixpanic agent logs' を実行して起動しない理由を確認してください",
+
+	// Validate --fix
+	"Applied %d fix(es)":                                      "%d 件の修正を適用しました",
+	"validation found %d problem(s)":                          "検証で %d 件の問題が見つかりました",
+	"Run 'sudo fixpanic agent validate --fix' to repair them": "修復するには 'sudo fixpanic agent validate --fix' を実行してください",
+	"Directory %s is missing":                                 "ディレクトリ %s がありません",
+	"Created directory %s":                                    "ディレクトリ %s を作成しました",
+	"Run 'sudo fixpanic agent validate --fix' to restore the newest valid backup": "最新の有効なバックアップを復元するには 'sudo fixpanic agent validate --fix' を実行してください",
+	"No valid backup was found: %v":                                               "有効なバックアップが見つかりませんでした: %v",
+	"Restored %s from %s (the broken file is kept as %s)":                         "%[1]s を %[2]s から復元しました (壊れたファイルは %[3]s として保持されます)",
+	"failed to load restored configuration: %w":                                   "復元した設定を読み込めませんでした: %w",
+	"Applied the permission policy to %s (%s)":                                    "%s に権限ポリシーを適用しました (%s)",
+	"Run 'sudo fixpanic agent validate --fix' to apply it":                        "適用するには 'sudo fixpanic agent validate --fix' を実行してください",
+	"%s is readable by other users (mode %04o) and holds the API key":             "%s は他のユーザーが読み取れます (モード %04o)。API キーが含まれています",
+	"Restricted %s to mode 0600 (was %04o)":                                       "%s をモード 0600 に制限しました (以前は %04o)",
+	"%s starts '%s' instead of '%s'":                                              "%[1]s は '%[3]s' ではなく '%[2]s' を起動します",
+	"Re-rendered %s to start '%s'":                                                "'%[2]s' を起動するよう %[1]s を再生成しました",
+	"Run 'fixpanic agent restart' for the service to use the new unit":            "サービスが新しいユニットを使うよう 'fixpanic agent restart' を実行してください",
+
+	// agent migrate
+	"Move an installation made by an older CLI to the current layout":       "古いCLIで作成されたインストールを現在のレイアウトに移行します",
+	"Show what would be migrated without changing anything":                 "何も変更せずに移行内容を表示します",
+	"Migrating Legacy Installation":                                         "旧インストールの移行",
+	"The %s exists at both %s and %s, leaving both":                         "%[1]sが%[2]sと%[3]sの両方に存在するため、どちらも残します",
+	"Would move the %s from %s to %s":                                       "%[1]sを%[2]sから%[3]sへ移動します",
+	"Moved the %s from %s to %s":                                            "%[1]sを%[2]sから%[3]sへ移動しました",
+	"The installation already uses the current layout":                      "インストールはすでに現在のレイアウトを使用しています",
+	"Dry run: nothing was changed. Run without --dry-run to apply.":         "ドライラン: 何も変更されていません。適用するには --dry-run なしで実行してください。",
+	"Migrated %d item(s) to the current layout":                             "%d 件を現在のレイアウトに移行しました",
+	"Run 'fixpanic agent restart' so the agent uses the new locations":      "エージェントが新しい場所を使うよう 'fixpanic agent restart' を実行してください",
+	"%d file(s) exist at both the old and the new location":                 "%d 個のファイルが旧場所と新場所の両方に存在します",
+	"Remove the copy you don't need and run 'fixpanic agent migrate' again": "不要なコピーを削除して 'fixpanic agent migrate' を再実行してください",
+	"Would migrate the configuration: %s":                                   "設定を移行します: %s",
+	"Migrated the configuration: %s":                                        "設定を移行しました: %s",
+	"Would set the log file in %s to %s":                                    "%[1]sのログファイルを%[2]sに設定します",
+	"Set the log file in %s to %s":                                          "%[1]sのログファイルを%[2]sに設定しました",
+	"Would re-render %s to start '%s' instead of '%s'":                      "%[1]sを再生成し、'%[3]s'の代わりに'%[2]s'を起動するようにします",
+	"Re-rendered %s to start '%s' instead of '%s'":                          "%[1]sを再生成し、'%[3]s'の代わりに'%[2]s'を起動するようにしました",
+
+	// deprecations
+	"%s is deprecated, use %s instead": "%[1]s は非推奨です。代わりに %[2]s を使用してください",
+
+	// porcelain
+	"unknown --porcelain format %q: use %s": "不明な --porcelain 形式 %[1]q: %[2]s を使用してください",
+
+	// middleware
+	"'%s' requires root":              "'%s' には root 権限が必要です",
+	"Run it again with sudo: sudo %s": "sudo で再実行してください: sudo %s",
+
+	// sudo
+	"Run it again with sudo? [y/N]:":                                                            "sudo で再実行しますか？ [y/N]:",
+	"forwarding the agent logs requires root":                                                   "エージェントログの転送には root 権限が必要です",
+	"Without root the agent is installed in your user directories and not as a systemd service": "root 権限がない場合、エージェントは systemd サービスではなくユーザーディレクトリにインストールされます",
+
+	// install rollback
+	"Roll back the installation? [Y/n]:": "インストールをロールバックしますか？ [Y/n]:",
+	"Keeping the partial installation. You can start the agent manually with: fixpanic agent start": "不完全なインストールを残します。エージェントは次のコマンドで手動で起動できます: fixpanic agent start",
+	"Rolling back the installation": "インストールをロールバックしています",
+	"Rolled back the installation":  "インストールをロールバックしました",
+	"Use --keep-partial to keep the installation and find out why with 'fixpanic agent logs'": "--keep-partial を指定するとインストールが残り、'fixpanic agent logs' で原因を調べられます",
+	"Run 'fixpanic agent uninstall' to remove what is left of the installation":               "'fixpanic agent uninstall' を実行してインストールの残りを削除してください",
+
+	// agent smoke-test
+	"Agent Smoke Test":                         "エージェントのスモークテスト",
+	"Checking the agent is running":            "エージェントが実行中か確認しています",
+	"Agent is running":                         "エージェントは実行中です",
+	"Requesting 'echo' through %s":             "%s 経由で 'echo' を要求しています",
+	"Waiting up to %s for the agent to run it": "エージェントが実行するまで最大 %s 待機しています",
+	"Verifying the output":                     "出力を検証しています",
+	"Commands run through the control plane, socket server and agent (round trip %s)": "コマンドはコントロールプレーン、ソケットサーバー、エージェントを経由して実行されます (往復 %s)",
+	"Run 'fixpanic agent status' to check the agent is connected":                     "'fixpanic agent status' を実行してエージェントが接続されているか確認してください",
+	"Run 'fixpanic agent logs' to see whether the command reached the agent":          "'fixpanic agent logs' を実行してコマンドがエージェントに届いたか確認してください",
+	"Run 'fixpanic agent doctor' to check the agent's confinement isn't blocking it":  "'fixpanic agent doctor' を実行してエージェントの制限がブロックしていないか確認してください",
+	"Run the smoke test again; if it keeps failing contact support@fixpanic.com":      "スモークテストを再実行してください。失敗が続く場合は support@fixpanic.com にお問い合わせください",
+	"Check that %s is reachable from this host":                                       "このホストから %s に到達できるか確認してください",
+	"Run 'fixpanic network check' to diagnose connectivity":                           "'fixpanic network check' を実行して接続を診断してください",
+
+	// agent sessions
+	"No active sessions":    "アクティブなセッションはありません",
+	"Terminated session %s": "セッション %s を終了しました",
+	"Pass the IDs shown by 'fixpanic agent sessions list', or --all":     "'fixpanic agent sessions list' に表示される ID か --all を指定してください",
+	"Run 'fixpanic agent sessions list' to see the active sessions":      "'fixpanic agent sessions list' を実行してアクティブなセッションを確認してください",
+	"Upgrade the agent with 'fixpanic agent upgrade' to manage sessions": "セッションを管理するには 'fixpanic agent upgrade' でエージェントをアップグレードしてください",
+
+	// agent policy
+	"No policy is set: the platform may run any command":     "ポリシーは設定されていません: プラットフォームは任意のコマンドを実行できます",
+	"Can't tell whether the agent has loaded the policy: %v": "エージェントがポリシーを読み込んだか確認できません: %v",
+	"The running agent enforces this policy":                 "実行中のエージェントはこのポリシーを適用しています",
+	"The running agent enforces a different policy":          "実行中のエージェントは別のポリシーを適用しています",
+	"Run 'fixpanic agent restart' for the agent to load it":  "'fixpanic agent restart' を実行してエージェントに読み込ませてください",
+	"Added %s entry %q":                                    "%s エントリ %q を追加しました",
+	"Removed %s entry %q":                                  "%s エントリ %q を削除しました",
+	"The policy already has the %s entry %q":               "ポリシーには %s エントリ %q が既にあります",
+	"The policy has no %s entry %q":                        "ポリシーに %s エントリ %q はありません",
+	"No policy is set":                                     "ポリシーは設定されていません",
+	"Removed the policy; the platform may run any command": "ポリシーを削除しました。プラットフォームは任意のコマンドを実行できます",
+	"Run 'fixpanic agent restart' for the agent to load the policy, then 'fixpanic agent policy verify'": "'fixpanic agent restart' を実行してエージェントにポリシーを読み込ませ、その後 'fixpanic agent policy verify' を実行してください",
+	"Upgrade the agent with 'fixpanic agent upgrade' to enforce the policy":                              "ポリシーを適用するには 'fixpanic agent upgrade' でエージェントを更新してください",
+	"The running agent enforces the configured policy":                                                   "実行中のエージェントは設定されたポリシーを適用しています",
+	"The policy allows %q": "ポリシーは %q を許可しています",
+	"Allowed commands":     "許可されたコマンド",
+	"Denied commands":      "拒否されたコマンド",
+	"Blocked paths":        "ブロックされたパス",
+	"Time windows":         "時間帯",
+	"any not denied":       "拒否されていないすべて",
+	"none":                 "なし",
+	"any time":             "いつでも",
+
+	// agent panic-stop
+	"Emergency stop engaged: %s":         "緊急停止を有効にしました: %s",
+	"Failed to find agent processes: %v": "エージェントのプロセスを検出できませんでした: %v",
+	"Failed to kill agent process %d":    "エージェントのプロセス %d を強制終了できませんでした",
+	"Killed agent process %d":            "エージェントのプロセス %d を強制終了しました",
+	"Failed to stop the service: %v":     "サービスを停止できませんでした: %v",
+	"Failed to disable the service: %v":  "サービスを無効にできませんでした: %v",
+	"Failed to enable the service: %v":   "サービスを有効にできませんでした: %v",
+	"Kill them by hand, e.g. 'sudo kill -9 <pid>'; the emergency stop keeps them from being restarted": "'sudo kill -9 <pid>' などで手動で終了してください。緊急停止により再起動はされません",
+	"The agent is stopped; nothing will start it until the emergency stop is lifted":                   "エージェントは停止しました。緊急停止が解除されるまで起動されません",
+	"Run 'fixpanic agent panic-reset' once the incident is resolved":                                   "インシデントが解決したら 'fixpanic agent panic-reset' を実行してください",
+	"The emergency stop is not engaged":                                                                "緊急停止は有効になっていません",
+	"The emergency stop is engaged%s":                                                                  "緊急停止が有効です%s",
+	"Pass --force to lift it without asking":                                                           "確認なしで解除するには --force を指定してください",
+	"Allow the platform to reach this host again? [y/N]:":                                              "プラットフォームからこのホストへのアクセスを再び許可しますか? [y/N]:",
+	"The emergency stop stays engaged":                                                                 "緊急停止は有効なままです",
+	"Emergency stop lifted":                                                                            "緊急停止を解除しました",
+	"The watchdog starts the agent again within a few seconds":                                         "ウォッチドッグが数秒以内にエージェントを再び起動します",
+	"Run 'fixpanic agent start' to start the agent":                                                    "'fixpanic agent start' を実行してエージェントを起動してください",
+	"engaging the emergency stop requires root":                                                        "緊急停止を有効にするには root 権限が必要です",
+
+	// agent downloads
+	"Fetching the checksums of %s":                                        "%s のチェックサムを取得しています",
+	"Failed to verify the checksum manifest":                              "チェックサムマニフェストを検証できませんでした",
+	"Releases published before signed checksum manifests can't be pulled": "署名付きチェックサムマニフェスト導入前のリリースは取得できません",
+
+	// agent sbom
+	"FixPanic Agent is not installed, showing the CLI only":                                  "FixPanic Agent がインストールされていないため、CLI のみ表示します",
+	"Software Bill of Materials":                                                             "ソフトウェア部品表 (SBOM)",
+	"Downloading the SBOM of agent %s...":                                                    "エージェント %s の SBOM をダウンロードしています...",
+	"Could not download the SBOM of agent %s":                                                "エージェント %s の SBOM をダウンロードできませんでした",
+	"Checksum verified against the signed release manifest":                                  "署名付きリリースマニフェストでチェックサムを検証しました",
+	"Try the other format with --format, or upgrade the agent with 'fixpanic agent upgrade'": "--format でもう一方の形式を試すか、'fixpanic agent upgrade' でエージェントをアップグレードしてください",
+	"Install a released agent with 'fixpanic agent upgrade'":                                 "'fixpanic agent upgrade' でリリース版のエージェントをインストールしてください",
+	"Pass --component agent or --component cli":                                              "--component agent または --component cli を指定してください",
+	"--raw prints one document":                                                              "--raw は 1 つのドキュメントのみ出力します",
+	"unknown component %q: use agent, cli or all":                                            "不明なコンポーネント %q: agent、cli、all のいずれかを指定してください",
+	"agent %s publishes no %s SBOM: %w":                                                      "エージェント %s は %s 形式の SBOM を公開していません: %w",
+	"the installed agent is not a release build: %w":                                         "インストールされているエージェントはリリースビルドではありません: %w",
+	"this binary carries no build information":                                               "このバイナリにはビルド情報が含まれていません",
+	"failed to get agent version: %w":                                                        "エージェントのバージョンを取得できませんでした: %w",
+	"Component":                                                                              "コンポーネント",
+	"Format":                                                                                 "形式",
+	"Source":                                                                                 "取得元",
+	"Provenance":                                                                             "来歴",
+	"Created":                                                                                "作成日時",
+	"Created by":                                                                             "作成者",
+	"Packages":                                                                               "パッケージ数",
+	"embedded in the binary":                                                                 "バイナリに埋め込み",
+	"build information of the Go toolchain":                                                  "Go ツールチェーンのビルド情報",
+	"signed release manifest":                                                                "署名付きリリースマニフェスト",
+	"release download, unsigned":                                                             "リリースのダウンロード（署名なし）",
+
+	// agent update notices
+	"Agent %s is available (installed: %s); run 'fixpanic agent upgrade' to update": "エージェント %s が利用可能です (インストール済み: %s)。'fixpanic agent upgrade' で更新してください",
+
+	// fleet logs
+	"Resuming: the logs of %d host(s) were already collected":                                 "再開します: %d 台のホストのログは収集済みです",
+	"Collecting the logs since %s of %d host(s)...":                                           "%s 以降のログを %d 台のホストから収集しています...",
+	"The logs of %d host(s) could not be collected; run the same command again to retry them": "%d 台のホストのログを収集できませんでした。同じコマンドを再実行すると再試行します",
+	"Re-run with --since %s to resume it, or use another --output directory":                  "--since %s で再実行して再開するか、別の --output ディレクトリを使用してください",
+
+	// drain
+	"The agent no longer accepts new sessions":                                                        "エージェントは新しいセッションを受け付けなくなりました",
+	"Waiting for %d active session(s) to end (up to %s)...":                                           "%d 件のアクティブなセッションの終了を待っています (最大 %s)...",
+	"All sessions ended":                                                                              "すべてのセッションが終了しました",
+	"The agent can't refuse new sessions; only waiting for the active ones to end":                    "エージェントは新しいセッションを拒否できません。アクティブなセッションの終了のみを待ちます",
+	"Run 'fixpanic agent sessions list' to see who is connected":                                      "'fixpanic agent sessions list' で接続中のユーザーを確認してください",
+	"Wait longer with --drain-timeout, or end the sessions with 'fixpanic agent sessions kill --all'": "--drain-timeout で待ち時間を延ばすか、'fixpanic agent sessions kill --all' でセッションを終了してください",
+	"Restart the agent with 'fixpanic agent restart' to accept new sessions again":                    "新しいセッションを再び受け付けるには 'fixpanic agent restart' でエージェントを再起動してください",
+
+	// fleet upgrade
+	"Checking the agents of %d host(s)...": "%d 台のホストのエージェントを確認しています...",
+	"No host needs an upgrade to %s":       "%s へのアップグレードが必要なホストはありません",
+	"Upgrading %d canary host(s) to %s...": "%d 台のカナリアホストを %s にアップグレードしています...",
+	"Baking the canaries until %s...":      "%s までカナリアを監視しています...",
+	"The canaries are healthy on %s":       "カナリアは %s で正常に動作しています",
+	"Upgrading %d host(s) to %s...":        "%d 台のホストを %s にアップグレードしています...",
+	"Rolling back %d canary host(s)...":    "%d 台のカナリアホストをロールバックしています...",
+	"No other host was upgraded; the upgraded canaries were rolled back to their previous version":                                                "他のホストはアップグレードされていません。アップグレードされたカナリアは以前のバージョンにロールバックされました",
+	"No other host was upgraded, but %s could not be rolled back; run 'fixpanic agent upgrade --agent-version' with their previous version there": "他のホストはアップグレードされていませんが、%s はロールバックできませんでした。そのホストで以前のバージョンを指定して 'fixpanic agent upgrade --agent-version' を実行してください",
+	"Run the same command again to retry them; upgraded hosts are skipped":                                                                        "同じコマンドを再実行すると再試行します。アップグレード済みのホストはスキップされます",
+	"Pass the release to upgrade to with --agent-version":                                                                                         "アップグレード先のリリースを --agent-version で指定してください",
+
+	// fleet labels
+	"Labels of %s: %s":         "%s のラベル: %s",
+	"%s has no labels anymore": "%s のラベルはなくなりました",
+	"Pass a host list with --hosts, or label hosts with 'fixpanic fleet label' and select them with --selector": "--hosts でホストリストを指定するか、'fixpanic fleet label' でホストにラベルを付けて --selector で選択してください",
+	"Run 'fixpanic fleet label' to list the labeled hosts":                                                      "'fixpanic fleet label' でラベル付きのホストを一覧表示してください",
+}
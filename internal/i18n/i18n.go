@@ -0,0 +1,101 @@
+// Package i18n translates user-facing CLI messages.
+//
+// Catalogs are keyed by the English format string, so untranslated messages
+// fall back to English and call sites stay readable. The logger translates
+// every format string it prints, which makes adding a translation a matter of
+// adding a catalog entry.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// English is the source language of all messages
+const English = "en"
+
+// catalogs maps a language code to its translations
+var catalogs = map[string]map[string]string{
+	English: {},
+	"de":    german,
+	"ja":    japanese,
+}
+
+var (
+	mu       sync.RWMutex
+	language = Detect()
+)
+
+// Languages returns the supported language codes
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Normalize reduces a locale such as "de_DE.UTF-8" to its language code
+func Normalize(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "_-.@"); i >= 0 {
+		locale = locale[:i]
+	}
+	return locale
+}
+
+// Detect returns the language selected by the POSIX locale variables
+// (LC_ALL, LC_MESSAGES, LANG), falling back to English
+func Detect() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		// The first variable set wins, even if we don't support its language
+		if lang := Normalize(value); catalogs[lang] != nil {
+			return lang
+		}
+		return English
+	}
+	return English
+}
+
+// SetLanguage selects the output language
+func SetLanguage(locale string) error {
+	lang := Normalize(locale)
+	if catalogs[lang] == nil {
+		return fmt.Errorf("unsupported language %q (supported: %s)", locale, strings.Join(Languages(), ", "))
+	}
+
+	mu.Lock()
+	language = lang
+	mu.Unlock()
+	return nil
+}
+
+// Language returns the current output language
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return language
+}
+
+// T returns the translation of an English message or format string
+func T(message string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if translated, ok := catalogs[language][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// Sprintf translates format and formats it with args
+func Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
@@ -0,0 +1,81 @@
+// Package interrupt handles SIGINT and SIGTERM for the CLI. The first signal
+// cancels the command's context; a second one exits, but not while a critical
+// step runs: replacing the agent binary, writing its configuration or service
+// unit, or rolling back. Those steps would leave the host broken if cut off
+// halfway, so the process exits once they finished instead.
+package interrupt
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+)
+
+var (
+	criticalMu sync.Mutex
+	// criticalSections counts the running steps a second signal must not cut
+	// short
+	criticalSections int
+	// exitPending is set when a second signal arrived during a critical
+	// section; the last section to finish exits
+	exitPending bool
+)
+
+// Notify returns a context cancelled by the first SIGINT or SIGTERM, so the
+// command can cancel transfers, stop child processes and remove partial
+// files. A second signal exits immediately, or as soon as the running
+// critical sections finished. The lock needs no release: the system drops it
+// with the process.
+func Notify(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+			return
+		}
+		for range signals {
+			criticalMu.Lock()
+			if criticalSections == 0 {
+				os.Exit(int(clierror.Interrupted))
+			}
+			exitPending = true
+			criticalMu.Unlock()
+			logger.Warning("Finishing the current step before exiting so the installation isn't left broken")
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+// Critical runs fn, a step that would leave the host broken if cut off
+// halfway such as replacing a binary or rolling back. A second signal while
+// it runs exits once it returned. Without Notify, e.g. in the Go library,
+// it just runs fn.
+func Critical(fn func() error) error {
+	criticalMu.Lock()
+	criticalSections++
+	criticalMu.Unlock()
+
+	defer func() {
+		criticalMu.Lock()
+		defer criticalMu.Unlock()
+		criticalSections--
+		if exitPending && criticalSections == 0 {
+			os.Exit(int(clierror.Interrupted))
+		}
+	}()
+	return fn()
+}
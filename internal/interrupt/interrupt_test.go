@@ -0,0 +1,24 @@
+package interrupt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCritical(t *testing.T) {
+	want := errors.New("step failed")
+	err := Critical(func() error {
+		return Critical(func() error {
+			if criticalSections != 2 {
+				t.Errorf("nested critical sections = %d, want 2", criticalSections)
+			}
+			return want
+		})
+	})
+	if !errors.Is(err, want) {
+		t.Errorf("Critical() error = %v, want %v", err, want)
+	}
+	if criticalSections != 0 {
+		t.Errorf("critical sections after return = %d, want 0", criticalSections)
+	}
+}
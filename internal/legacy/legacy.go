@@ -0,0 +1,117 @@
+// Package legacy finds the files of installations laid out by older versions
+// of the CLI and moves them to where the current layout expects them.
+package legacy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/state"
+)
+
+// Move is a file at a legacy location and where it belongs now
+type Move struct {
+	// What names the file, e.g. "agent binary"
+	What string
+	From string
+	To   string
+	// Conflict is set when a file exists at both locations; such moves are
+	// left for the user to resolve
+	Conflict bool
+}
+
+// Find returns the files of the installation described by p that are still at
+// a legacy location:
+//
+//   - the configuration at ~/.fixpanic/agent.yaml, where non-root installs kept
+//     it before the per-user directories existed
+//   - a non-root install in the default directories after XDG base directory
+//     variables were set, which move the binary, configuration, log and state
+//
+// Installs relocated with --prefix or $FIXPANIC_HOME have no legacy layout.
+func Find(p *platform.PlatformInfo, home string) []Move {
+	if p.IsRoot || p.Prefix != "" || home == "" {
+		return nil
+	}
+
+	defaults := platform.UserDefaultDirs(home)
+	candidates := []Move{
+		{What: "configuration", From: filepath.Join(home, ".fixpanic", "agent.yaml"), To: p.GetConfigPath()},
+		{What: "agent binary", From: defaults.GetFixPanicAgentBinaryPath(), To: p.GetFixPanicAgentBinaryPath()},
+		{What: "configuration", From: defaults.GetConfigPath(), To: p.GetConfigPath()},
+		{What: "agent log", From: defaults.GetLogPath(), To: p.GetLogPath()},
+		{What: "CLI state", From: filepath.Join(defaults.StateDir, state.FileName), To: filepath.Join(p.StateDir, state.FileName)},
+	}
+
+	var moves []Move
+	claimed := make(map[string]bool)
+	for _, move := range candidates {
+		if move.From == move.To || !exists(move.From) {
+			continue
+		}
+		move.Conflict = exists(move.To) || claimed[move.To]
+		claimed[move.To] = true
+		moves = append(moves, move)
+	}
+	return moves
+}
+
+// Apply moves the file, copying it where From and To are on different file
+// systems
+func (m Move) Apply() error {
+	if m.Conflict {
+		return fmt.Errorf("%s exists at both %s and %s", m.What, m.From, m.To)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.To), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", m.To, err)
+	}
+	err := os.Rename(m.From, m.To)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return fmt.Errorf("failed to move %s to %s: %w", m.From, m.To, err)
+	}
+	if err := copyFile(m.From, m.To); err != nil {
+		os.Remove(m.To)
+		return fmt.Errorf("failed to copy %s to %s: %w", m.From, m.To, err)
+	}
+	if err := os.Remove(m.From); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", m.From, err)
+	}
+	return nil
+}
+
+// copyFile copies src to dst, keeping its mode
+func copyFile(src, dst string) error {
+	sourceFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	info, err := sourceFile.Stat()
+	if err != nil {
+		return err
+	}
+	destFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(destFile, sourceFile); err != nil {
+		destFile.Close()
+		return err
+	}
+	return destFile.Close()
+}
+
+// exists reports whether a file exists at path
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
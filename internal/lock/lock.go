@@ -0,0 +1,94 @@
+// Package lock provides an advisory inter-process file lock used to serialize
+// CLI operations that modify the agent installation
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrLocked is returned when another process holds the lock
+var ErrLocked = errors.New("lock is held by another process")
+
+// pollInterval is how often a waiting Acquire retries the lock
+const pollInterval = 250 * time.Millisecond
+
+// Lock is a held file lock
+type Lock struct {
+	file *os.File
+}
+
+// Acquire takes the exclusive lock at path, waiting up to wait for another
+// holder to release it. owner is recorded in the lock file so competing
+// processes can report who holds it. Returns ErrLocked if the lock is still
+// held after wait, or ctx's error if ctx is cancelled while waiting.
+func Acquire(ctx context.Context, path string, wait time.Duration, owner string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	// Never truncate on open: the current holder's owner info must survive
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			break
+		}
+		if !time.Now().Before(deadline) {
+			file.Close()
+			return nil, ErrLocked
+		}
+		select {
+		case <-ctx.Done():
+			file.Close()
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+
+	// Best effort: the lock is valid even if the owner can't be recorded
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(owner), 0)
+	}
+
+	return &Lock{file: file}, nil
+}
+
+// Release clears the owner record and releases the lock
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+
+	l.file.Truncate(0)
+	err := unlock(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
+}
+
+// Owner returns the owner recorded by the current holder of the lock at path,
+// or an empty string if unknown
+func Owner(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
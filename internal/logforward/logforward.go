@@ -0,0 +1,303 @@
+// Package logforward sets up the host's log shipper to forward the agent's
+// log file to a central logging system: rsyslog for syslog targets, Fluent
+// Bit for Loki and S3. The CLI only writes the shipper's configuration; the
+// shipper does the forwarding.
+package logforward
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Shippers a target is forwarded with
+const (
+	ShipperRsyslog   = "rsyslog"
+	ShipperFluentBit = "fluent-bit"
+)
+
+// Configuration files written for each shipper
+const (
+	RsyslogPath   = "/etc/rsyslog.d/60-fixpanic-agent.conf"
+	FluentBitPath = "/etc/fluent-bit/fixpanic-agent.conf"
+	// FluentBitMainPath is Fluent Bit's main configuration, which must
+	// include FluentBitPath
+	FluentBitMainPath = "/etc/fluent-bit/fluent-bit.conf"
+)
+
+// Tag identifies the agent's lines at the target
+const Tag = "fixpanic-agent"
+
+// Default ports of the targets
+const (
+	defaultSyslogPort = "514"
+	defaultLokiPort   = "3100"
+)
+
+// Target is where the agent's logs are forwarded to
+type Target struct {
+	// Scheme is "syslog", "syslog+tcp", "loki", "lokis" (Loki over TLS) or "s3"
+	Scheme string
+	Host   string
+	Port   string
+	// Path is the Loki push path or the S3 key prefix
+	Path string
+	// Region is the AWS region of an S3 bucket
+	Region string
+	raw    string
+}
+
+// ParseTarget parses a target URL:
+//
+//	syslog://host[:port]       syslog over UDP (default port 514)
+//	syslog+tcp://host[:port]   syslog over TCP
+//	loki://host[:port]         Loki over HTTP (default port 3100)
+//	lokis://host[:port]        Loki over HTTPS
+//	s3://bucket[/prefix]?region=eu-west-1
+func ParseTarget(raw string) (*Target, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target %q: %w", raw, err)
+	}
+	if u.User != nil {
+		return nil, fmt.Errorf("invalid target %q: credentials don't belong in the target; configure them for the shipper", raw)
+	}
+	target := &Target{Scheme: u.Scheme, Host: u.Hostname(), Port: u.Port(), Path: strings.Trim(u.Path, "/"), raw: raw}
+	if target.Host == "" {
+		return nil, fmt.Errorf("invalid target %q: no host", raw)
+	}
+	// Hosts and paths end up in configuration files; keep them to
+	// characters that need no quoting there
+	if strings.ContainsAny(target.Host+target.Path, " \t\r\n\"'\\`$") {
+		return nil, fmt.Errorf("invalid target %q", raw)
+	}
+
+	switch target.Scheme {
+	case "syslog", "syslog+tcp":
+		if target.Port == "" {
+			target.Port = defaultSyslogPort
+		}
+		if target.Path != "" {
+			return nil, fmt.Errorf("invalid target %q: syslog targets have no path", raw)
+		}
+	case "loki", "lokis":
+		if target.Port == "" {
+			target.Port = defaultLokiPort
+		}
+		if target.Path == "" {
+			target.Path = "loki/api/v1/push"
+		}
+	case "s3":
+		target.Region = u.Query().Get("region")
+		if target.Region == "" || strings.ContainsAny(target.Region, " \t\r\n\"'") {
+			return nil, fmt.Errorf("invalid target %q: S3 targets need ?region=<aws region>", raw)
+		}
+		if target.Port != "" {
+			return nil, fmt.Errorf("invalid target %q: S3 targets have no port", raw)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported target %q: use syslog://, syslog+tcp://, loki://, lokis:// or s3://", raw)
+	}
+	return target, nil
+}
+
+// String returns the target URL
+func (t *Target) String() string {
+	return t.raw
+}
+
+// Shipper returns the log shipper forwarding to the target
+func (t *Target) Shipper() string {
+	if strings.HasPrefix(t.Scheme, "syslog") {
+		return ShipperRsyslog
+	}
+	return ShipperFluentBit
+}
+
+// ConfigPath returns the configuration file written for the target
+func (t *Target) ConfigPath() string {
+	if t.Shipper() == ShipperRsyslog {
+		return RsyslogPath
+	}
+	return FluentBitPath
+}
+
+// Render returns the shipper configuration forwarding the log file at
+// logPath to the target
+func (t *Target) Render(logPath string) (string, error) {
+	tmpl := fluentBitTemplate
+	if t.Shipper() == ShipperRsyslog {
+		tmpl = rsyslogTemplate
+	}
+	data := struct {
+		*Target
+		LogPath    string
+		Tag        string
+		Endpoint   string
+		Protocol   string
+		TLS        string
+		LoadImfile bool
+	}{
+		Target:     t,
+		LogPath:    logPath,
+		Tag:        Tag,
+		Endpoint:   net.JoinHostPort(t.Host, t.Port),
+		Protocol:   "udp",
+		TLS:        "Off",
+		LoadImfile: !rsyslogLoadsImfile(),
+	}
+	if t.Scheme == "syslog+tcp" {
+		data.Protocol = "tcp"
+	}
+	if t.Scheme == "lokis" {
+		data.TLS = "On"
+	}
+
+	parsed, err := template.New("forward").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var result strings.Builder
+	if err := parsed.Execute(&result, data); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+const rsyslogTemplate = `# Forward the Fixpanic agent's log to {{ .Endpoint }}, written by 'fixpanic agent logs forward'
+{{- if .LoadImfile }}
+module(load="imfile")
+{{- end }}
+input(type="imfile" File="{{ .LogPath }}" Tag="{{ .Tag }}:" Severity="info" Facility="daemon" Ruleset="fixpanic-agent")
+
+ruleset(name="fixpanic-agent") {
+  action(type="omfwd" Target="{{ .Host }}" Port="{{ .Port }}" Protocol="{{ .Protocol }}"
+         queue.type="LinkedList" queue.size="10000" action.resumeRetryCount="-1")
+}
+`
+
+const fluentBitTemplate = `# Forward the Fixpanic agent's log to {{ .Target }}, written by 'fixpanic agent logs forward'
+[INPUT]
+    Name              tail
+    Path              {{ .LogPath }}
+    Tag               {{ .Tag }}
+    Skip_Long_Lines   On
+    Refresh_Interval  10
+{{ if eq .Scheme "s3" }}
+[OUTPUT]
+    Name              s3
+    Match             {{ .Tag }}
+    bucket            {{ .Host }}
+    region            {{ .Region }}
+    s3_key_format     /{{ if .Path }}{{ .Path }}/{{ end }}{{ .Tag }}/%Y/%m/%d/%H%M%S-$UUID.gz
+    compression       gzip
+    total_file_size   50M
+    upload_timeout    10m
+{{- else }}
+[OUTPUT]
+    Name              loki
+    Match             {{ .Tag }}
+    Host              {{ .Host }}
+    Port              {{ .Port }}
+    Uri               /{{ .Path }}
+    tls               {{ .TLS }}
+    Labels            job={{ .Tag }}, host=${HOSTNAME}
+{{- end }}
+`
+
+// rsyslogLoadsImfile reports whether the rsyslog configuration already loads
+// the imfile module, which may only be loaded once
+func rsyslogLoadsImfile() bool {
+	files, _ := filepath.Glob("/etc/rsyslog.d/*.conf")
+	files = append(files, "/etc/rsyslog.conf")
+	for _, file := range files {
+		if file == RsyslogPath {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+			if strings.Contains(line, `"imfile"`) || strings.Contains(line, "$ModLoad imfile") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Include is the line of Fluent Bit's main configuration that loads the
+// agent's forwarding configuration
+const Include = "@INCLUDE " + FluentBitPath
+
+// AddInclude adds Include to Fluent Bit's main configuration if it's not
+// there yet, and reports whether it was added
+func AddInclude() (bool, error) {
+	data, err := os.ReadFile(FluentBitMainPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", FluentBitMainPath, err)
+	}
+	if hasInclude(string(data)) {
+		return false, nil
+	}
+	content := strings.TrimRight(string(data), "\n") + "\n\n" + Include + "\n"
+	if err := os.WriteFile(FluentBitMainPath, []byte(content), 0644); err != nil {
+		return false, fmt.Errorf("failed to update %s: %w", FluentBitMainPath, err)
+	}
+	return true, nil
+}
+
+// RemoveInclude removes Include from Fluent Bit's main configuration, which
+// fails to start when an included file is missing
+func RemoveInclude() error {
+	data, err := os.ReadFile(FluentBitMainPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", FluentBitMainPath, err)
+	}
+	if !hasInclude(string(data)) {
+		return nil
+	}
+	var kept []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) != Include {
+			kept = append(kept, line)
+		}
+	}
+	content := strings.TrimRight(strings.Join(kept, "\n"), "\n") + "\n"
+	if err := os.WriteFile(FluentBitMainPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to update %s: %w", FluentBitMainPath, err)
+	}
+	return nil
+}
+
+func hasInclude(config string) bool {
+	for _, line := range strings.Split(config, "\n") {
+		if strings.TrimSpace(line) == Include {
+			return true
+		}
+	}
+	return false
+}
+
+// Configured returns the configuration files of forwarding set up earlier
+func Configured() []string {
+	var paths []string
+	for _, path := range []string{RsyslogPath, FluentBitPath} {
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+)
+
+// EnvNoDeprecationWarnings silences deprecation notices when set to a true value
+const EnvNoDeprecationWarnings = "FIXPANIC_NO_DEPRECATION_WARNINGS"
+
+var (
+	deprecationMu       sync.Mutex
+	deprecationSilenced bool
+	deprecationReported = make(map[string]bool)
+	// deprecationOutput is standard error so notices never end up in JSON
+	// output or the output of scripts
+	deprecationOutput io.Writer = os.Stderr
+)
+
+// SilenceDeprecations suppresses all further deprecation notices
+func SilenceDeprecations() {
+	deprecationMu.Lock()
+	defer deprecationMu.Unlock()
+	deprecationSilenced = true
+}
+
+// Deprecated reports on standard error that name is deprecated in favour of
+// replacement. Each name is reported once per process, and not at all when
+// silenced with SilenceDeprecations or $FIXPANIC_NO_DEPRECATION_WARNINGS.
+func Deprecated(name, replacement string) {
+	deprecationMu.Lock()
+	defer deprecationMu.Unlock()
+	if deprecationSilenced || deprecationReported[name] {
+		return
+	}
+	if silenced, err := strconv.ParseBool(os.Getenv(EnvNoDeprecationWarnings)); err == nil && silenced {
+		return
+	}
+	deprecationReported[name] = true
+
+	message := i18n.Sprintf("%s is deprecated, use %s instead", name, replacement)
+	fmt.Fprintf(deprecationOutput, "%s %s\n", defaultLogger.colorize(Yellow, "[DEPRECATED]"), message)
+}
@@ -2,8 +2,16 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"regexp"
 	"runtime"
+
+	"github.com/fixpanic/fixpanic-cli/internal/events"
+	"github.com/fixpanic/fixpanic-cli/internal/i18n"
+	"github.com/fixpanic/fixpanic-cli/internal/summary"
+	"github.com/fixpanic/fixpanic-cli/internal/term"
+	"github.com/fixpanic/fixpanic-cli/internal/trace"
 )
 
 // ANSI color codes
@@ -19,15 +27,22 @@ const (
 	Bold   = "\033[1m"
 )
 
-// Logger provides consistent, colored output for CLI operations
+// Logger provides consistent, colored output for CLI operations. Data a
+// command reports (key-value pairs, lists, diffs) goes to stdout; messages
+// about the operation itself (headers, progress, info, warnings) go to
+// stderr, so redirecting stdout captures only the data.
 type Logger struct {
 	useColors bool
+	out       io.Writer
+	chrome    io.Writer
 }
 
 // NewLogger creates a new logger instance
 func NewLogger() *Logger {
 	return &Logger{
 		useColors: shouldUseColors(),
+		out:       os.Stdout,
+		chrome:    os.Stderr,
 	}
 }
 
@@ -64,54 +79,62 @@ func (l *Logger) colorize(color, text string) string {
 
 // Info prints an informational message with blue [INFO] prefix
 func (l *Logger) Info(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
+	message := i18n.Sprintf(format, args...)
 	prefix := l.colorize(Blue, "[INFO]")
-	fmt.Printf("%s %s\n", prefix, message)
+	fmt.Fprintf(l.chrome, "%s %s\n", prefix, message)
 }
 
 // Success prints a success message with green [SUCCESS] prefix
 func (l *Logger) Success(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
+	message := i18n.Sprintf(format, args...)
 	prefix := l.colorize(Green, "[SUCCESS]")
-	fmt.Printf("%s %s\n", prefix, message)
+	fmt.Fprintf(l.chrome, "%s %s\n", prefix, message)
 }
 
 // Warning prints a warning message with yellow [WARNING] prefix
 func (l *Logger) Warning(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
+	events.Emit(events.Event{Type: events.Warning, Message: fmt.Sprintf(format, args...)})
+	summary.Warn(fmt.Sprintf(format, args...))
+	message := i18n.Sprintf(format, args...)
 	prefix := l.colorize(Yellow, "[WARNING]")
-	fmt.Printf("%s %s\n", prefix, message)
+	fmt.Fprintf(l.chrome, "%s %s\n", prefix, message)
 }
 
 // Error prints an error message with red [ERROR] prefix
 func (l *Logger) Error(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
+	message := i18n.Sprintf(format, args...)
 	prefix := l.colorize(Red, "[ERROR]")
-	fmt.Printf("%s %s\n", prefix, message)
+	fmt.Fprintf(l.chrome, "%s %s\n", prefix, message)
 }
 
 // Progress prints a progress message with cyan [PROGRESS] prefix
 func (l *Logger) Progress(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
+	events.Emit(events.Event{Type: events.Progress, Message: fmt.Sprintf(format, args...)})
+	message := i18n.Sprintf(format, args...)
 	prefix := l.colorize(Cyan, "[PROGRESS]")
-	fmt.Printf("%s %s\n", prefix, message)
+	fmt.Fprintf(l.chrome, "%s %s\n", prefix, message)
 }
 
-// Step prints a numbered step with purple prefix
+// Step prints a numbered step with purple prefix. Event consumers see the
+// untranslated message so they can match on it.
 func (l *Logger) Step(step int, format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
+	events.StartStep(step, fmt.Sprintf(format, args...))
+	summary.StartStep(step, fmt.Sprintf(format, args...))
+	trace.StartStep(step, fmt.Sprintf(format, args...))
+	message := i18n.Sprintf(format, args...)
 	prefix := l.colorize(Purple, fmt.Sprintf("[STEP %d]", step))
-	fmt.Printf("%s %s\n", prefix, message)
+	fmt.Fprintf(l.chrome, "%s %s\n", prefix, message)
 }
 
 // Plain prints a message without any prefix (but can still be colored)
 func (l *Logger) Plain(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	fmt.Printf("%s\n", message)
+	message := i18n.Sprintf(format, args...)
+	fmt.Fprintf(l.out, "%s\n", message)
 }
 
 // Header prints a section header with separator
 func (l *Logger) Header(title string) {
+	title = i18n.T(title)
 	separator := "=================================="
 	if len(title) > len(separator) {
 		separator = ""
@@ -120,64 +143,134 @@ func (l *Logger) Header(title string) {
 		}
 	}
 
-	fmt.Printf("%s\n", l.colorize(Bold+Blue, title))
-	fmt.Printf("%s\n", l.colorize(Blue, separator))
+	fmt.Fprintf(l.chrome, "%s\n", l.colorize(Bold+Blue, title))
+	fmt.Fprintf(l.chrome, "%s\n", l.colorize(Blue, separator))
 }
 
 // Separator prints a visual separator
 func (l *Logger) Separator() {
-	fmt.Println()
+	fmt.Fprintln(l.chrome)
 }
 
 // KeyValue prints a key-value pair with consistent formatting
 func (l *Logger) KeyValue(key, value string) {
-	keyColored := l.colorize(Bold, key+":")
-	fmt.Printf("   %s %s\n", keyColored, value)
+	keyColored := l.colorize(Bold, i18n.T(key)+":")
+	fmt.Fprintf(l.out, "   %s %s\n", keyColored, value)
 }
 
 // List prints a bulleted list item
 func (l *Logger) List(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
+	message := i18n.Sprintf(format, args...)
 	bullet := l.colorize(Green, "✓")
-	fmt.Printf("   %s %s\n", bullet, message)
+	fmt.Fprintf(l.out, "   %s %s\n", bullet, message)
 }
 
 // Loading prints a loading message (without newline)
 func (l *Logger) Loading(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
+	message := i18n.Sprintf(format, args...)
+	prefix := l.colorize(Cyan, "[LOADING]")
+	fmt.Fprintf(l.chrome, "%s %s", prefix, message)
+}
+
+// LoadingProgress redraws the current loading message with new text, e.g.
+// the progress of a download. Outside a terminal it prints nothing, so logs
+// aren't flooded with intermediate states; complete the message with
+// LoadingDone or LoadingFailed as usual.
+func (l *Logger) LoadingProgress(format string, args ...interface{}) {
+	if !term.IsTerminal(os.Stderr) {
+		return
+	}
+	message := i18n.Sprintf(format, args...)
 	prefix := l.colorize(Cyan, "[LOADING]")
-	fmt.Printf("%s %s", prefix, message)
+	fmt.Fprintf(l.chrome, "\r\033[K%s %s", prefix, message)
 }
 
 // LoadingDone completes a loading message
 func (l *Logger) LoadingDone(format string, args ...interface{}) {
 	if format == "" {
-		fmt.Printf(" %s\n", l.colorize(Green, "✓"))
+		fmt.Fprintf(l.chrome, " %s\n", l.colorize(Green, "✓"))
 	} else {
-		message := fmt.Sprintf(format, args...)
-		fmt.Printf(" %s %s\n", l.colorize(Green, "✓"), message)
+		message := i18n.Sprintf(format, args...)
+		fmt.Fprintf(l.chrome, " %s %s\n", l.colorize(Green, "✓"), message)
 	}
 }
 
 // LoadingFailed completes a loading message with failure
 func (l *Logger) LoadingFailed(format string, args ...interface{}) {
 	if format == "" {
-		fmt.Printf(" %s\n", l.colorize(Red, "✗"))
+		fmt.Fprintf(l.chrome, " %s\n", l.colorize(Red, "✗"))
 	} else {
-		message := fmt.Sprintf(format, args...)
-		fmt.Printf(" %s %s\n", l.colorize(Red, "✗"), message)
+		message := i18n.Sprintf(format, args...)
+		fmt.Fprintf(l.chrome, " %s %s\n", l.colorize(Red, "✗"), message)
 	}
 }
 
+// DiffLine prints a line of a diff: "+" lines green, "-" lines red, others plain
+func (l *Logger) DiffLine(op, text string) {
+	line := op + " " + text
+	switch op {
+	case "+":
+		line = l.colorize(Green, line)
+	case "-":
+		line = l.colorize(Red, line)
+	}
+	fmt.Fprintf(l.out, "   %s\n", line)
+}
+
+// Severity patterns of agent log lines: the level as a word, as in
+// "2025/01/01 12:00:00 ERROR ...", or as a structured field ("level=error",
+// "\"level\":\"error\"")
+var (
+	logErrorPattern = regexp.MustCompile(`\b(?:FATAL|PANIC|CRIT(?:ICAL)?|ERROR|ERR)\b|(?i:level"?[=:]\s*"?(?:fatal|panic|crit(?:ical)?|error|err)\b)`)
+	logWarnPattern  = regexp.MustCompile(`\bWARN(?:ING)?\b|(?i:level"?[=:]\s*"?warn(?:ing)?\b)`)
+	logDebugPattern = regexp.MustCompile(`\b(?:DEBUG|TRACE)\b|(?i:level"?[=:]\s*"?(?:debug|trace)\b)`)
+)
+
+// LogLine returns a line of the agent's log colored by its severity: errors
+// red, warnings yellow and debug output gray. Other lines are unchanged.
+func (l *Logger) LogLine(line string) string {
+	switch {
+	case logErrorPattern.MatchString(line):
+		return l.colorize(Red, line)
+	case logWarnPattern.MatchString(line):
+		return l.colorize(Yellow, line)
+	case logDebugPattern.MatchString(line):
+		return l.colorize(Gray, line)
+	}
+	return line
+}
+
 // Command prints a command that's being executed
 func (l *Logger) Command(cmd string) {
 	cmdColored := l.colorize(Gray, "$ "+cmd)
-	fmt.Printf("   %s\n", cmdColored)
+	fmt.Fprintf(l.chrome, "   %s\n", cmdColored)
 }
 
 // Global logger instance for convenience
 var defaultLogger = NewLogger()
 
+// SetColors overrides whether the default logger colors its output, which
+// otherwise follows the environment
+func SetColors(enabled bool) {
+	defaultLogger.useColors = enabled
+}
+
+// Redirect sends the data and messages of the default logger, uncolored, to
+// w until the returned function restores its previous writers. The library
+// API uses it to keep progress off the standard output of its callers.
+func Redirect(w io.Writer) (restore func()) {
+	previous := *defaultLogger
+	defaultLogger.out, defaultLogger.chrome, defaultLogger.useColors = w, w, false
+	return func() { *defaultLogger = previous }
+}
+
+// Messages returns the writer the default logger prints messages about the
+// operation to, for packages that report progress without the logger's
+// formatting
+func Messages() io.Writer {
+	return defaultLogger.chrome
+}
+
 // Package-level convenience functions
 func Info(format string, args ...interface{})     { defaultLogger.Info(format, args...) }
 func Success(format string, args ...interface{})  { defaultLogger.Success(format, args...) }
@@ -191,6 +284,9 @@ func Separator()                                   { defaultLogger.Separator() }
 func KeyValue(key, value string)                   { defaultLogger.KeyValue(key, value) }
 func List(format string, args ...interface{})     { defaultLogger.List(format, args...) }
 func Loading(format string, args ...interface{})  { defaultLogger.Loading(format, args...) }
+func LoadingProgress(format string, args ...interface{}) { defaultLogger.LoadingProgress(format, args...) }
 func LoadingDone(format string, args ...interface{}) { defaultLogger.LoadingDone(format, args...) }
 func LoadingFailed(format string, args ...interface{}) { defaultLogger.LoadingFailed(format, args...) }
-func Command(cmd string)                           { defaultLogger.Command(cmd) }
\ No newline at end of file
+func Command(cmd string)                           { defaultLogger.Command(cmd) }
+func DiffLine(op, text string)                     { defaultLogger.DiffLine(op, text) }
+func LogLine(line string) string                   { return defaultLogger.LogLine(line) }
\ No newline at end of file
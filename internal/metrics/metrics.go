@@ -0,0 +1,142 @@
+// Package metrics renders agent health samples in the Prometheus text
+// exposition format (version 0.0.4) without pulling in the client library.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the HTTP content type for the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Metric types
+const (
+	Gauge   = "gauge"
+	Counter = "counter"
+)
+
+// Sample is a single metric value with its metadata
+type Sample struct {
+	Name   string
+	Help   string
+	Type   string
+	Labels map[string]string
+	Value  float64
+}
+
+// Bool converts a boolean into a 0/1 gauge value
+func Bool(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Write renders samples in the Prometheus text format. Samples sharing a
+// name are grouped under a single HELP/TYPE header in first-seen order.
+func Write(w io.Writer, samples []Sample) error {
+	var order []string
+	groups := make(map[string][]Sample)
+	for _, sample := range samples {
+		if _, ok := groups[sample.Name]; !ok {
+			order = append(order, sample.Name)
+		}
+		groups[sample.Name] = append(groups[sample.Name], sample)
+	}
+
+	for _, name := range order {
+		group := groups[name]
+		if group[0].Help != "" {
+			if _, err := fmt.Fprintf(w, "# HELP %s %s\n", name, escapeHelp(group[0].Help)); err != nil {
+				return err
+			}
+		}
+		if group[0].Type != "" {
+			if _, err := fmt.Fprintf(w, "# TYPE %s %s\n", name, group[0].Type); err != nil {
+				return err
+			}
+		}
+		for _, sample := range group {
+			if _, err := fmt.Fprintf(w, "%s%s %s\n", name, formatLabels(sample.Labels), formatValue(sample.Value)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteFile renders samples into the file at path, e.g. for node_exporter's
+// textfile collector. The file is replaced atomically so a scrape never reads
+// it half-written.
+func WriteFile(path string, samples []Sample) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := Write(tmp, samples); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// formatLabels renders a label set in a stable (sorted) order
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s=\"%s\"", key, escapeLabel(labels[key]))
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// formatValue renders a float the way Prometheus expects
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeHelp(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
+
+func escapeLabel(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
@@ -0,0 +1,134 @@
+package netprobe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+)
+
+// benchChunk is the size of each write during a throughput test
+const benchChunk = 32 * 1024
+
+// benchWarmup is excluded from throughput results while the kernel send
+// buffer fills, which would otherwise inflate the rate
+const benchWarmup = time.Second
+
+// Latencies measures TCP connect time to endpoint n times
+func Latencies(ctx context.Context, endpoint string, n int, timeout time.Duration) ([]time.Duration, error) {
+	samples := make([]time.Duration, 0, n)
+	for i := 0; i < n; i++ {
+		result, err := Dial(ctx, endpoint, timeout)
+		if err != nil {
+			return samples, err
+		}
+		samples = append(samples, result.Latency)
+	}
+	return samples, nil
+}
+
+// Percentile returns the p-th percentile (0-100) of samples using the
+// nearest-rank method. samples is sorted in place.
+func Percentile(samples []time.Duration, p float64) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	rank := int(p/100*float64(len(samples)) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(samples) {
+		rank = len(samples)
+	}
+	return samples[rank-1]
+}
+
+// Reconnect drops an established connection and measures how long it takes
+// to get a new one, including DNS resolution as an agent reconnect would
+func Reconnect(ctx context.Context, endpoint string, timeout time.Duration) (time.Duration, error) {
+	dialer := &net.Dialer{Timeout: timeout, FallbackDelay: FallbackDelay}
+	conn, err := dialer.DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	conn.Close()
+	conn, err = dialer.DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return 0, fmt.Errorf("reconnect failed: %w", err)
+	}
+	elapsed := time.Since(start)
+	conn.Close()
+
+	return elapsed, nil
+}
+
+// ThroughputResult describes a sustained upload test
+type ThroughputResult struct {
+	Bytes    int64
+	Duration time.Duration
+	// ClosedEarly is set if the server ended the session before the test finished
+	ClosedEarly bool
+}
+
+// BitsPerSecond returns the measured rate
+func (r *ThroughputResult) BitsPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Bytes) * 8 / r.Duration.Seconds()
+}
+
+// Throughput writes data to endpoint for duration and measures the rate the
+// connection sustains once the send buffer is full. Only upload is measured:
+// the server isn't expected to send anything back.
+func Throughput(ctx context.Context, endpoint string, duration, timeout time.Duration) (*ThroughputResult, error) {
+	dialer := &net.Dialer{Timeout: timeout, FallbackDelay: FallbackDelay}
+	conn, err := dialer.DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	buf := make([]byte, benchChunk)
+	result := &ThroughputResult{}
+	start := time.Now()
+	deadline := start.Add(benchWarmup + duration)
+	var measureStart time.Time
+
+	for time.Now().Before(deadline) {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// Short deadlines keep a stalled link from blocking past the test
+		conn.SetWriteDeadline(time.Now().Add(timeout))
+		n, err := conn.Write(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return nil, fmt.Errorf("link stalled: no data accepted for %v", timeout)
+			}
+			result.ClosedEarly = true
+			break
+		}
+		if measureStart.IsZero() {
+			if time.Since(start) < benchWarmup {
+				continue
+			}
+			measureStart = time.Now()
+			continue
+		}
+		result.Bytes += int64(n)
+	}
+
+	if measureStart.IsZero() {
+		return result, nil
+	}
+	result.Duration = time.Since(measureStart)
+	return result, nil
+}
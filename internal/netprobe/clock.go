@@ -0,0 +1,147 @@
+package netprobe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ClockReferenceURL is asked for the time when checking the local clock. It
+// is an endpoint the CLI talks to anyway, so no new host has to be allowed.
+const ClockReferenceURL = "https://api.github.com/"
+
+// Clock offsets above these are reported: TLS certificates are usually
+// issued a few minutes before they become valid, so an offset of minutes
+// already breaks handshakes with freshly renewed certificates.
+const (
+	ClockSkewWarn = time.Minute
+	ClockSkewFail = 5 * time.Minute
+)
+
+// ClockOffset returns how far the local clock is ahead of the clock of the
+// server at rawURL, negative when it is behind. The server time is taken from
+// the HTTP Date header and corrected for the round trip; the header has a
+// resolution of one second, so offsets of a second or two are noise.
+func ClockOffset(ctx context.Context, rawURL string, timeout time.Duration) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy: Proxy,
+			// A wrong clock makes verification fail, which is what is
+			// being diagnosed here
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	end := time.Now()
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, fmt.Errorf("%s did not send a usable Date header", req.URL.Host)
+	}
+	// The header is truncated to the second; assume the middle of it, and
+	// compare with the middle of the round trip
+	serverTime := date.Add(500 * time.Millisecond)
+	localTime := start.Add(end.Sub(start) / 2)
+	return localTime.Sub(serverTime), nil
+}
+
+// DescribeClockOffset renders an offset as "your clock is off by 14 minutes
+// (ahead)"
+func DescribeClockOffset(offset time.Duration) string {
+	direction := "ahead"
+	if offset < 0 {
+		direction = "behind"
+		offset = -offset
+	}
+	return fmt.Sprintf("your clock is off by %s (%s)", describeDuration(offset), direction)
+}
+
+// describeDuration renders d in the largest whole unit that fits
+func describeDuration(d time.Duration) string {
+	plural := func(n int64, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s", unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+	switch {
+	case d >= 48*time.Hour:
+		return plural(int64(d/(24*time.Hour)), "day")
+	case d >= 2*time.Hour:
+		return plural(int64(d/time.Hour), "hour")
+	case d >= time.Minute:
+		return plural(int64(d/time.Minute), "minute")
+	default:
+		return plural(int64(d/time.Second), "second")
+	}
+}
+
+// PeerCertificates completes a TLS handshake with endpoint (host:port) and
+// returns the certificates it presented, leaf first. The chain is not
+// verified, so certificates that are expired or not yet valid can still be
+// inspected.
+func PeerCertificates(ctx context.Context, endpoint string, timeout time.Duration) ([]*x509.Certificate, error) {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: timeout, FallbackDelay: FallbackDelay},
+		Config:    &tls.Config{ServerName: host, InsecureSkipVerify: true},
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := dialer.DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("TLS handshake with %s failed: %w", endpoint, err)
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("%s did not present a certificate", endpoint)
+	}
+	return certs, nil
+}
+
+// CertificateTimeProblems checks that every certificate in chain is valid at
+// now and describes the ones that are not
+func CertificateTimeProblems(chain []*x509.Certificate, now time.Time) []string {
+	var problems []string
+	for _, cert := range chain {
+		name := cert.Subject.CommonName
+		if name == "" {
+			name = cert.Subject.String()
+		}
+		switch {
+		case now.Before(cert.NotBefore):
+			problems = append(problems, fmt.Sprintf("certificate %q is not valid until %s (%s from now)",
+				name, cert.NotBefore.UTC().Format(time.RFC3339), describeDuration(cert.NotBefore.Sub(now))))
+		case now.After(cert.NotAfter):
+			problems = append(problems, fmt.Sprintf("certificate %q expired on %s (%s ago)",
+				name, cert.NotAfter.UTC().Format(time.RFC3339), describeDuration(now.Sub(cert.NotAfter))))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,139 @@
+package netprobe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+)
+
+// SystemResolver names the resolver configured on the host
+const SystemResolver = "system"
+
+// PublicResolvers are compared against the system resolver by default
+var PublicResolvers = []string{"1.1.1.1", "8.8.8.8"}
+
+// LookupResult is the answer of one resolver for one host
+type LookupResult struct {
+	Host      string
+	Resolver  string
+	Addresses []net.IP
+	Latency   time.Duration
+	Err       error
+}
+
+// NewResolver returns a resolver that sends queries to addr ("1.1.1.1",
+// "[2606:4700::1111]:53", ...) instead of the system resolver. An empty addr
+// or SystemResolver returns the system resolver.
+func NewResolver(addr string) (*net.Resolver, error) {
+	if addr == "" || addr == SystemResolver {
+		return net.DefaultResolver, nil
+	}
+	server, err := NormalizeEndpoint(addr, "53")
+	if err != nil {
+		return nil, fmt.Errorf("invalid resolver: %w", err)
+	}
+	if host, _, _ := net.SplitHostPort(server); net.ParseIP(host) == nil {
+		return nil, fmt.Errorf("invalid resolver %q: must be an IP address", addr)
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, server)
+		},
+	}, nil
+}
+
+// Lookup resolves host with the system resolver, or by querying the DNS
+// server at addr directly (see NewResolver), giving up after timeout
+func Lookup(ctx context.Context, addr, host string, timeout time.Duration) LookupResult {
+	result := LookupResult{Host: host, Resolver: addr}
+	if addr == "" {
+		result.Resolver = SystemResolver
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		result.Addresses = []net.IP{ip}
+		return result
+	}
+
+	server := ""
+	if addr != "" && addr != SystemResolver {
+		if _, err := NewResolver(addr); err != nil {
+			result.Err = err
+			return result
+		}
+		server, _ = NormalizeEndpoint(addr, "53")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	if server == "" {
+		var addrs []net.IPAddr
+		addrs, result.Err = net.DefaultResolver.LookupIPAddr(ctx, host)
+		for _, a := range addrs {
+			result.Addresses = append(result.Addresses, a.IP)
+		}
+	} else {
+		result.Addresses, result.Err = queryDNS(ctx, server, host)
+	}
+	result.Latency = time.Since(start)
+	sortIPs(result.Addresses)
+	return result
+}
+
+// DescribeDNSError explains a failed lookup in one short phrase
+func DescribeDNSError(err error, timeout time.Duration) string {
+	var dnsErr *net.DNSError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &dnsErr) && dnsErr.IsTimeout:
+		return fmt.Sprintf("timed out after %v", timeout)
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		return "no such host"
+	case errors.As(err, &dnsErr) && dnsErr.IsTemporary:
+		return "server failure"
+	default:
+		return err.Error()
+	}
+}
+
+// SameAddresses reports whether two answers contain the same addresses
+func SameAddresses(a, b []net.IP) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := map[string]bool{}
+	for _, ip := range a {
+		seen[ip.String()] = true
+	}
+	for _, ip := range b {
+		if !seen[ip.String()] {
+			return false
+		}
+	}
+	return true
+}
+
+// AllInternal reports whether every address is private, loopback or
+// link-local, i.e. only reachable inside the local network
+func AllInternal(ips []net.IP) bool {
+	if len(ips) == 0 {
+		return false
+	}
+	for _, ip := range ips {
+		if !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() {
+			return false
+		}
+	}
+	return true
+}
+
+func sortIPs(ips []net.IP) {
+	sort.Slice(ips, func(i, j int) bool {
+		return ips[i].String() < ips[j].String()
+	})
+}
@@ -0,0 +1,213 @@
+package netprobe
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// DNS record types and response codes used by queryDNS
+const (
+	dnsTypeA         = 1
+	dnsTypeAAAA      = 28
+	dnsClassIN       = 1
+	dnsRcodeOK       = 0
+	dnsRcodeFail     = 2
+	dnsRcodeNXDomain = 3
+	// dnsUDPSize is the largest UDP response accepted without truncation
+	dnsUDPSize = 1232
+)
+
+// queryDNS asks server directly for the A and AAAA records of host. Unlike
+// the Go resolver it never consults the hosts file, so answers really come
+// from server and can be compared with the system resolver's.
+func queryDNS(ctx context.Context, server, host string) ([]net.IP, error) {
+	var ips []net.IP
+	for _, qtype := range []uint16{dnsTypeA, dnsTypeAAAA} {
+		answer, err := exchangeDNS(ctx, "udp", server, host, qtype)
+		if err != nil {
+			return nil, err
+		}
+		ips = append(ips, answer...)
+	}
+	if len(ips) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: host, Server: server, IsNotFound: true}
+	}
+	return ips, nil
+}
+
+// exchangeDNS sends one query over network ("udp" or "tcp") and returns the
+// addresses of type qtype in the answer. Truncated UDP answers are retried
+// over TCP.
+func exchangeDNS(ctx context.Context, network, server, host string, qtype uint16) ([]net.IP, error) {
+	fail := func(err error) error {
+		var netErr net.Error
+		timeout := errors.As(err, &netErr) && netErr.Timeout() || errors.Is(err, context.DeadlineExceeded)
+		return &net.DNSError{Err: err.Error(), Name: host, Server: server, IsTimeout: timeout}
+	}
+
+	query, id, err := buildDNSQuery(host, qtype)
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: host, Server: server}
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, fail(err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	var response []byte
+	if network == "tcp" {
+		framed := binary.BigEndian.AppendUint16(nil, uint16(len(query)))
+		if _, err := conn.Write(append(framed, query...)); err != nil {
+			return nil, fail(err)
+		}
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, fail(err)
+		}
+		response = make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, response); err != nil {
+			return nil, fail(err)
+		}
+	} else {
+		if _, err := conn.Write(query); err != nil {
+			return nil, fail(err)
+		}
+		buf := make([]byte, dnsUDPSize)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return nil, fail(err)
+			}
+			// Ignore stray datagrams that don't answer our query
+			if n >= 2 && binary.BigEndian.Uint16(buf) == id {
+				response = buf[:n]
+				break
+			}
+		}
+	}
+
+	ips, truncated, rcode, err := parseDNSResponse(response, id, qtype)
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: host, Server: server}
+	}
+	if truncated && network == "udp" {
+		return exchangeDNS(ctx, "tcp", server, host, qtype)
+	}
+	switch rcode {
+	case dnsRcodeOK:
+		return ips, nil
+	case dnsRcodeNXDomain:
+		return nil, &net.DNSError{Err: "no such host", Name: host, Server: server, IsNotFound: true}
+	case dnsRcodeFail:
+		return nil, &net.DNSError{Err: "server misbehaving", Name: host, Server: server, IsTemporary: true}
+	default:
+		return nil, &net.DNSError{Err: fmt.Sprintf("server refused the query (rcode %d)", rcode), Name: host, Server: server}
+	}
+}
+
+// buildDNSQuery encodes a recursive query for host and returns it with its ID
+func buildDNSQuery(host string, qtype uint16) ([]byte, uint16, error) {
+	var idBytes [2]byte
+	if _, err := rand.Read(idBytes[:]); err != nil {
+		return nil, 0, err
+	}
+	id := binary.BigEndian.Uint16(idBytes[:])
+
+	// Header: ID, flags with recursion desired, one question
+	msg := binary.BigEndian.AppendUint16(nil, id)
+	msg = binary.BigEndian.AppendUint16(msg, 0x0100)
+	msg = binary.BigEndian.AppendUint16(msg, 1)
+	msg = append(msg, 0, 0, 0, 0, 0, 0)
+
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		if label == "" || len(label) > 63 {
+			return nil, 0, fmt.Errorf("invalid host name %q", host)
+		}
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0)
+	msg = binary.BigEndian.AppendUint16(msg, qtype)
+	msg = binary.BigEndian.AppendUint16(msg, dnsClassIN)
+	return msg, id, nil
+}
+
+// parseDNSResponse extracts the addresses of type qtype from the answer
+// section, along with the truncation flag and response code
+func parseDNSResponse(msg []byte, id, qtype uint16) (ips []net.IP, truncated bool, rcode int, err error) {
+	errMalformed := errors.New("malformed DNS response")
+	if len(msg) < 12 || binary.BigEndian.Uint16(msg) != id {
+		return nil, false, 0, errMalformed
+	}
+	flags := binary.BigEndian.Uint16(msg[2:])
+	if flags&0x8000 == 0 {
+		return nil, false, 0, errMalformed
+	}
+	truncated = flags&0x0200 != 0
+	rcode = int(flags & 0x000f)
+	questions := int(binary.BigEndian.Uint16(msg[4:]))
+	answers := int(binary.BigEndian.Uint16(msg[6:]))
+
+	offset := 12
+	for i := 0; i < questions; i++ {
+		if offset, err = skipDNSName(msg, offset); err != nil {
+			return nil, truncated, rcode, err
+		}
+		offset += 4
+	}
+	for i := 0; i < answers; i++ {
+		if offset, err = skipDNSName(msg, offset); err != nil {
+			return nil, truncated, rcode, err
+		}
+		if offset+10 > len(msg) {
+			return nil, truncated, rcode, errMalformed
+		}
+		recordType := binary.BigEndian.Uint16(msg[offset:])
+		length := int(binary.BigEndian.Uint16(msg[offset+8:]))
+		offset += 10
+		if offset+length > len(msg) {
+			return nil, truncated, rcode, errMalformed
+		}
+		// CNAME records are skipped; the addresses they point to follow
+		if recordType == qtype && (length == net.IPv4len || length == net.IPv6len) {
+			ips = append(ips, net.IP(append([]byte(nil), msg[offset:offset+length]...)))
+		}
+		offset += length
+	}
+	return ips, truncated, rcode, nil
+}
+
+// skipDNSName returns the offset after the (possibly compressed) name at
+// offset
+func skipDNSName(msg []byte, offset int) (int, error) {
+	for {
+		if offset >= len(msg) {
+			return 0, errors.New("malformed DNS response")
+		}
+		length := int(msg[offset])
+		switch {
+		case length == 0:
+			return offset + 1, nil
+		case length&0xc0 == 0xc0:
+			// A compression pointer ends the name
+			return offset + 2, nil
+		default:
+			offset += 1 + length
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Package netprobe provides network reachability checks used by the CLI's
+// connectivity diagnostics. All helpers are IPv6-aware and dial dual-stack
+// endpoints using Happy Eyeballs-style fallback.
+package netprobe
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/workpool"
+)
+
+// FallbackDelay is how long a dual-stack dial waits on the preferred address
+// family before racing the other one (RFC 6555 recommends 150-250ms).
+const FallbackDelay = 250 * time.Millisecond
+
+// Result describes a successful connection attempt
+type Result struct {
+	Endpoint   string
+	RemoteAddr string
+	Family     string
+	// DNSLatency is the time spent resolving the host, Latency the time
+	// spent connecting after that
+	DNSLatency time.Duration
+	Latency    time.Duration
+}
+
+// FamilyResult describes a connection attempt restricted to one address family
+type FamilyResult struct {
+	Family    string
+	Addresses []net.IP
+	Latency   time.Duration
+	Error     error
+}
+
+// NormalizeEndpoint turns user input into a dialable host:port string.
+//
+// It accepts "host:port", "[v6]:port", bare hostnames, bare IPv4 literals and
+// bare (unbracketed) IPv6 literals. Missing ports are filled with defaultPort.
+func NormalizeEndpoint(endpoint, defaultPort string) (string, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return "", fmt.Errorf("endpoint is empty")
+	}
+
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		// No port (or an unbracketed IPv6 literal)
+		host = strings.TrimSuffix(strings.TrimPrefix(endpoint, "["), "]")
+		port = defaultPort
+		if ip := net.ParseIP(host); ip == nil && strings.Contains(host, ":") {
+			return "", fmt.Errorf("invalid endpoint %q: IPv6 literals with a port must be written as [addr]:port", endpoint)
+		}
+	}
+
+	if host == "" {
+		return "", fmt.Errorf("invalid endpoint %q: missing host", endpoint)
+	}
+	if port == "" {
+		return "", fmt.Errorf("invalid endpoint %q: missing port", endpoint)
+	}
+
+	portNum, err := strconv.Atoi(port)
+	if err != nil || portNum < 1 || portNum > 65535 {
+		return "", fmt.Errorf("invalid endpoint %q: port must be between 1 and 65535", endpoint)
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+// FamilyOf returns "IPv4" or "IPv6" for an IP address
+func FamilyOf(ip net.IP) string {
+	if ip.To4() != nil {
+		return "IPv4"
+	}
+	return "IPv6"
+}
+
+// Resolve looks up a host and splits the answers by address family.
+// IP literals are returned as-is without a DNS query.
+func Resolve(ctx context.Context, host string) (v4, v6 []net.IP, err error) {
+	return ResolveVia(ctx, net.DefaultResolver, host)
+}
+
+// ResolveVia is Resolve using the given resolver
+func ResolveVia(ctx context.Context, resolver *net.Resolver, host string) (v4, v6 []net.IP, err error) {
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.To4() != nil {
+			return []net.IP{ip}, nil, nil
+		}
+		return nil, []net.IP{ip}, nil
+	}
+
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			v4 = append(v4, addr.IP)
+		} else {
+			v6 = append(v6, addr.IP)
+		}
+	}
+
+	return v4, v6, nil
+}
+
+// Dial connects to endpoint over TCP, racing IPv6 and IPv4 addresses
+// Happy Eyeballs-style, and reports which address family won.
+func Dial(ctx context.Context, endpoint string, timeout time.Duration) (*Result, error) {
+	return DialVia(ctx, endpoint, timeout, nil)
+}
+
+// DialVia is Dial resolving the host with resolver, or the system resolver
+// if it is nil
+func DialVia(ctx context.Context, endpoint string, timeout time.Duration, resolver *net.Resolver) (*Result, error) {
+	// The first connection attempt starts right after name resolution, which
+	// splits DNS from connect latency without giving up Happy Eyeballs
+	var once sync.Once
+	var resolved time.Time
+	dialer := &net.Dialer{
+		Timeout:       timeout,
+		FallbackDelay: FallbackDelay,
+		Resolver:      resolver,
+		Control: func(network, address string, c syscall.RawConn) error {
+			once.Do(func() { resolved = time.Now() })
+			return nil
+		},
+	}
+
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	end := time.Now()
+
+	result := &Result{
+		Endpoint:   endpoint,
+		RemoteAddr: conn.RemoteAddr().String(),
+		DNSLatency: resolved.Sub(start),
+		Latency:    end.Sub(resolved),
+	}
+	if tcpAddr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		result.Family = FamilyOf(tcpAddr.IP)
+	}
+
+	return result, nil
+}
+
+// ProbeFamilies dials endpoint once per address family so callers can report
+// partial dual-stack breakage (e.g. AAAA records published but IPv6 unroutable).
+// Families without any resolved address are omitted from the result. A nil
+// resolver uses the system resolver.
+func ProbeFamilies(ctx context.Context, endpoint string, timeout time.Duration, resolver *net.Resolver) ([]FamilyResult, error) {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	v4, v6, err := ResolveVia(ctx, resolver, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+
+	type family struct {
+		name    string
+		network string
+		ips     []net.IP
+	}
+	var families []family
+	for _, f := range []family{
+		{"IPv6", "tcp6", v6},
+		{"IPv4", "tcp4", v4},
+	} {
+		if len(f.ips) > 0 {
+			families = append(families, f)
+		}
+	}
+
+	// Dial both families at once so a blackholed one costs a single timeout
+	results := workpool.Map(ctx, families, len(families), func(ctx context.Context, f family) FamilyResult {
+		dialer := &net.Dialer{Timeout: timeout, Resolver: resolver}
+		start := time.Now()
+		conn, err := dialer.DialContext(ctx, f.network, net.JoinHostPort(host, port))
+		result := FamilyResult{Family: f.name, Addresses: f.ips, Error: err}
+		if err == nil {
+			result.Latency = time.Since(start)
+			conn.Close()
+		}
+		return result
+	})
+
+	return results, nil
+}
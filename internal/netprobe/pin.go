@@ -0,0 +1,48 @@
+package netprobe
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// spkiPinPrefix is the hash algorithm prefix of a public key pin
+const spkiPinPrefix = "sha256/"
+
+// SPKIPin returns the pin of cert's public key: the base64 SHA-256 hash of its
+// SubjectPublicKeyInfo, in the "sha256/<base64>" form curl's --pinnedpubkey
+// also uses. The pin survives certificate renewals that keep the key.
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return spkiPinPrefix + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// ValidateSPKIPin checks that pin has the form SPKIPin returns
+func ValidateSPKIPin(pin string) error {
+	encoded, ok := strings.CutPrefix(pin, spkiPinPrefix)
+	if !ok {
+		return fmt.Errorf("invalid pin %q: expected sha256/<base64 hash>", pin)
+	}
+	sum, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sum) != sha256.Size {
+		return fmt.Errorf("invalid pin %q: not a base64 SHA-256 hash", pin)
+	}
+	return nil
+}
+
+// MatchSPKIPins returns the first of pins that matches the public key of a
+// certificate in chain, or an empty string if none does. Pinning an
+// intermediate or root CA matches every certificate it issues.
+func MatchSPKIPins(chain []*x509.Certificate, pins []string) string {
+	for _, cert := range chain {
+		certPin := SPKIPin(cert)
+		for _, pin := range pins {
+			if pin == certPin {
+				return pin
+			}
+		}
+	}
+	return ""
+}
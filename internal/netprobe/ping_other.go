@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package netprobe
+
+import (
+	"errors"
+	"net"
+)
+
+// listenPingSocket is not supported on this platform
+func listenPingSocket(v6 bool) (net.PacketConn, error) {
+	return nil, errors.New("unprivileged ping sockets are not supported on this platform")
+}
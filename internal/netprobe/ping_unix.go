@@ -0,0 +1,29 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package netprobe
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// listenPingSocket opens an ICMP datagram ("ping") socket, which Linux
+// allows unprivileged users in net.ipv4.ping_group_range and macOS allows
+// everyone
+func listenPingSocket(v6 bool) (net.PacketConn, error) {
+	family, proto := syscall.AF_INET, syscall.IPPROTO_ICMP
+	if v6 {
+		family, proto = syscall.AF_INET6, syscall.IPPROTO_ICMPV6
+	}
+	fd, err := syscall.Socket(family, syscall.SOCK_DGRAM, proto)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	syscall.CloseOnExec(fd)
+
+	f := os.NewFile(uintptr(fd), "ping")
+	defer f.Close()
+	return net.FilePacketConn(f)
+}
@@ -0,0 +1,223 @@
+package netprobe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Reachability probe methods, in the order they are tried
+const (
+	MethodICMP = "ICMP echo"
+	MethodTCP  = "TCP"
+	MethodUDP  = "UDP"
+)
+
+// udpProbePort is the traceroute port, which is almost never open, so a probe
+// is answered with ICMP port unreachable
+const udpProbePort = "33434"
+
+// errICMPUnavailable is returned when no ICMP socket can be opened
+var errICMPUnavailable = errors.New("ICMP is not available")
+
+// ReachResult describes whether and how a host answered
+type ReachResult struct {
+	Host string
+	// Method is the probe that got an answer, empty if none did
+	Method  string
+	Detail  string
+	Latency time.Duration
+	// ICMPSkipped explains why ICMP wasn't used, e.g. missing privileges
+	ICMPSkipped error
+	Err         error
+}
+
+// Reach checks whether host answers at all, without needing privileges. It
+// sends an ICMP echo where this process may open an ICMP socket, and
+// otherwise (or when ICMP is filtered) connects to the TCP ports given and
+// finally sends a UDP probe. A refused TCP or UDP probe still proves the
+// host is up, since the refusal comes from the host itself. A nil resolver
+// uses the system resolver.
+func Reach(ctx context.Context, host string, ports []string, timeout time.Duration, resolver *net.Resolver) *ReachResult {
+	result := &ReachResult{Host: host}
+
+	v4, v6, err := ResolveVia(ctx, resolver, host)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	var ip net.IP
+	switch {
+	case len(v4) > 0:
+		ip = v4[0]
+	case len(v6) > 0:
+		ip = v6[0]
+	default:
+		result.Err = fmt.Errorf("%s has no addresses", host)
+		return result
+	}
+
+	var tried []string
+	latency, err := icmpEcho(ctx, ip, timeout)
+	switch {
+	case err == nil:
+		result.Method, result.Detail, result.Latency = MethodICMP, "echo reply", latency
+		return result
+	case errors.Is(err, errICMPUnavailable):
+		result.ICMPSkipped = err
+	default:
+		tried = append(tried, MethodICMP)
+	}
+
+	for _, port := range ports {
+		start := time.Now()
+		dialer := &net.Dialer{Timeout: timeout}
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			conn.Close()
+			result.Method, result.Detail, result.Latency = MethodTCP, fmt.Sprintf("port %s open", port), time.Since(start)
+			return result
+		}
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			result.Method, result.Detail, result.Latency = MethodTCP, fmt.Sprintf("port %s refused by the host", port), time.Since(start)
+			return result
+		}
+		tried = append(tried, "TCP port "+port)
+	}
+
+	start := time.Now()
+	if err := udpProbe(ctx, ip, timeout); err == nil {
+		result.Method, result.Detail, result.Latency = MethodUDP, "port unreachable reply", time.Since(start)
+		return result
+	}
+	tried = append(tried, "UDP")
+
+	result.Err = fmt.Errorf("no answer to %s", strings.Join(tried, ", "))
+	return result
+}
+
+// icmpEcho sends one ICMP echo request to ip and waits for the reply. It
+// uses a raw socket when privileged and an unprivileged ping socket
+// otherwise.
+func icmpEcho(ctx context.Context, ip net.IP, timeout time.Duration) (time.Duration, error) {
+	v6 := ip.To4() == nil
+	conn, raw, err := openICMP(v6)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	id := os.Getpid() & 0xffff
+	request, reply := byte(8), byte(0)
+	if v6 {
+		request, reply = 128, 129
+	}
+	msg := []byte{request, 0, 0, 0, byte(id >> 8), byte(id), 0, 1, 'f', 'i', 'x', 'p', 'a', 'n', 'i', 'c'}
+	if !v6 {
+		// Only ICMPv4 needs a checksum; the kernel fills in ICMPv6 ones
+		sum := icmpChecksum(msg)
+		msg[2], msg[3] = byte(sum>>8), byte(sum)
+	}
+
+	var addr net.Addr = &net.UDPAddr{IP: ip}
+	if raw {
+		addr = &net.IPAddr{IP: ip}
+	}
+	start := time.Now()
+	if _, err := conn.WriteTo(msg, addr); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			return 0, err
+		}
+		// Raw sockets see every ICMP packet; ping sockets are filtered by
+		// the kernel, which also rewrites the ID
+		if n < 8 || buf[0] != reply {
+			continue
+		}
+		if raw && (int(buf[4])<<8|int(buf[5]) != id || !sameIP(from, ip)) {
+			continue
+		}
+		return time.Since(start), nil
+	}
+}
+
+// openICMP opens a raw ICMP socket, or an unprivileged ping socket if that
+// isn't permitted. raw reports which one was opened.
+func openICMP(v6 bool) (conn net.PacketConn, raw bool, err error) {
+	network, address := "ip4:icmp", "0.0.0.0"
+	if v6 {
+		network, address = "ip6:ipv6-icmp", "::"
+	}
+	if conn, err := net.ListenPacket(network, address); err == nil {
+		return conn, true, nil
+	}
+	if conn, err := listenPingSocket(v6); err == nil {
+		return conn, false, nil
+	}
+	return nil, false, fmt.Errorf("%w: it needs root, CAP_NET_RAW or membership in net.ipv4.ping_group_range", errICMPUnavailable)
+}
+
+// udpProbe sends a datagram to a closed port and waits for the host's port
+// unreachable reply, which the kernel reports as a refused read
+func udpProbe(ctx context.Context, ip net.IP, timeout time.Duration) error {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "udp", net.JoinHostPort(ip.String(), udpProbePort))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err := conn.Write([]byte("fixpanic")); err != nil {
+		return err
+	}
+	_, err = conn.Read(make([]byte, 64))
+	if err == nil || errors.Is(err, syscall.ECONNREFUSED) {
+		return nil
+	}
+	return err
+}
+
+// icmpChecksum is the internet checksum of an ICMPv4 message
+func icmpChecksum(msg []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(msg); i += 2 {
+		sum += uint32(msg[i])<<8 | uint32(msg[i+1])
+	}
+	if len(msg)%2 == 1 {
+		sum += uint32(msg[len(msg)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+// sameIP reports whether addr is ip
+func sameIP(addr net.Addr, ip net.IP) bool {
+	switch a := addr.(type) {
+	case *net.IPAddr:
+		return a.IP.Equal(ip)
+	case *net.UDPAddr:
+		return a.IP.Equal(ip)
+	}
+	return false
+}
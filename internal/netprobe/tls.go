@@ -0,0 +1,185 @@
+package netprobe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// interceptors are substrings of certificate issuers used by common TLS
+// inspection products (corporate proxies, antivirus suites, debugging proxies)
+var interceptors = []string{
+	"Zscaler",
+	"Fortinet",
+	"FortiGate",
+	"Palo Alto",
+	"Blue Coat",
+	"Netskope",
+	"Sophos",
+	"Cisco Umbrella",
+	"Forcepoint",
+	"Barracuda",
+	"Check Point",
+	"McAfee",
+	"Kaspersky",
+	"Avast",
+	"ESET",
+	"Bitdefender",
+	"mitmproxy",
+	"Charles Proxy",
+	"Fiddler",
+	"PortSwigger",
+}
+
+// TLSResult describes the certificate an HTTPS endpoint presented to us
+type TLSResult struct {
+	URL string
+	// Proxy is the proxy the request went through, or empty for a direct connection
+	Proxy   string
+	Subject string
+	Issuer  string
+	// VerifyError is set if the chain doesn't verify against the system roots
+	VerifyError error
+	// Interceptor names the TLS inspection product the issuer matched, if any
+	Interceptor string
+	// DNSLatency is the part of Latency spent resolving the host
+	DNSLatency time.Duration
+	Latency    time.Duration
+}
+
+// Intercepted reports whether a middlebox appears to be re-signing TLS traffic
+func (r *TLSResult) Intercepted() bool {
+	return r.Interceptor != "" || r.VerifyError != nil
+}
+
+// Proxy selects the proxy of the probes' requests. It follows the
+// environment (HTTPS_PROXY, HTTP_PROXY, NO_PROXY) unless the CLI sets the
+// proxy of its configuration.
+var Proxy = http.ProxyFromEnvironment
+
+// ProxyFor returns the proxy Proxy selects for rawURL, or an empty string for
+// a direct connection
+func ProxyFor(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	proxy, err := Proxy(&http.Request{URL: parsed})
+	if err != nil || proxy == nil {
+		return "", err
+	}
+	// Never print proxy credentials
+	proxy.User = nil
+	return proxy.String(), nil
+}
+
+// InspectTLS connects to an HTTPS URL the way the CLI's downloads do
+// (honouring proxy settings) and reports who issued the certificate. The chain
+// is verified separately so an untrusted certificate is reported rather than
+// failing the request.
+func InspectTLS(ctx context.Context, rawURL string, timeout time.Duration) (*TLSResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+
+	result := &TLSResult{URL: rawURL}
+	result.Proxy, _ = ProxyFor(rawURL)
+
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy: Proxy,
+			// Verification is done below so interception can be reported
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	var dnsStart time.Time
+	req = req.WithContext(httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:  func(httptrace.DNSDoneInfo) { result.DNSLatency = time.Since(dnsStart) },
+	}))
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	result.Latency = time.Since(start)
+
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("%s did not present a certificate", req.URL.Host)
+	}
+
+	certs := resp.TLS.PeerCertificates
+	leaf := certs[0]
+	result.Subject = leaf.Subject.CommonName
+	result.Issuer = describeIssuer(leaf)
+
+	result.VerifyError = VerifyChain(certs, req.URL.Hostname(), nil)
+
+	// Check the whole chain: some products only show up in the root's name
+	for _, cert := range certs {
+		if name := matchInterceptor(cert.Issuer.String()); name != "" {
+			result.Interceptor = name
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// VerifyChain verifies a chain presented by host, leaf first, against roots,
+// or the system roots if roots is nil
+func VerifyChain(chain []*x509.Certificate, host string, roots *x509.CertPool) error {
+	if len(chain) == 0 {
+		return fmt.Errorf("%s did not present a certificate", host)
+	}
+	opts := x509.VerifyOptions{
+		DNSName:       host,
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range chain[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := chain[0].Verify(opts)
+	return err
+}
+
+// describeIssuer returns a short human-readable issuer name
+func describeIssuer(cert *x509.Certificate) string {
+	issuer := cert.Issuer.CommonName
+	if len(cert.Issuer.Organization) > 0 {
+		if issuer == "" {
+			return cert.Issuer.Organization[0]
+		}
+		return fmt.Sprintf("%s (%s)", issuer, cert.Issuer.Organization[0])
+	}
+	if issuer == "" {
+		return cert.Issuer.String()
+	}
+	return issuer
+}
+
+// matchInterceptor returns the TLS inspection product named in issuer, if any
+func matchInterceptor(issuer string) string {
+	lower := strings.ToLower(issuer)
+	for _, name := range interceptors {
+		if strings.Contains(lower, strings.ToLower(name)) {
+			return name
+		}
+	}
+	return ""
+}
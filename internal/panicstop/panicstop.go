@@ -0,0 +1,78 @@
+// Package panicstop keeps the marker of an emergency stop. While the marker
+// exists the agent must not run: the CLI refuses to start it, the watchdog
+// holds it down and the systemd unit's start condition fails, until the
+// marker is explicitly removed.
+package panicstop
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Marker records who engaged the emergency stop, when and why
+type Marker struct {
+	StoppedAt time.Time `json:"stopped_at"`
+	User      string    `json:"user,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// Engage writes the marker to path. An engaged stop is kept as it is, so the
+// marker keeps naming the first engagement.
+func Engage(path string, marker Marker) error {
+	if Engaged(path) {
+		return nil
+	}
+	data, err := json.MarshalIndent(marker, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write emergency stop marker: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write emergency stop marker: %w", err)
+	}
+	return nil
+}
+
+// Engaged reports whether the emergency stop marker exists at path. A marker
+// that can't be read counts as engaged.
+func Engaged(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
+
+// Read returns the marker at path, or an error satisfying os.ErrNotExist
+// when the emergency stop is not engaged. An unreadable or damaged marker
+// is returned empty alongside the error.
+func Read(path string) (*Marker, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	var marker Marker
+	if err != nil {
+		return &marker, err
+	}
+	if err := json.Unmarshal(data, &marker); err != nil {
+		return &marker, fmt.Errorf("failed to parse emergency stop marker: %w", err)
+	}
+	return &marker, nil
+}
+
+// Release removes the marker at path
+func Release(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove emergency stop marker: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Package plan describes an agent installation as a JSON document that can
+// be reviewed, stored and applied later, enabling approve-then-execute
+// workflows.
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/cloudmeta"
+)
+
+// FormatVersion is the plan format written by this CLI
+const FormatVersion = 1
+
+// RedactedSecret replaces secrets in planned file contents. Secrets are
+// supplied again when the plan is applied so plans can be stored safely.
+const RedactedSecret = "<provided at apply time>"
+
+// Plan is everything an installation will do
+type Plan struct {
+	FormatVersion int       `json:"format_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	CLIVersion    string    `json:"cli_version"`
+	// Platform is the GOOS/GOARCH the plan was created on
+	Platform    string     `json:"platform"`
+	Inputs      Inputs     `json:"inputs"`
+	Directories []string   `json:"directories"`
+	Downloads   []Download `json:"downloads"`
+	Files       []File     `json:"files"`
+	Services    []Service  `json:"services"`
+}
+
+// Inputs are the non-secret install options the plan was created with
+type Inputs struct {
+	AgentID      string `json:"agent_id"`
+	Profile      string `json:"profile"`
+	SocketServer string `json:"socket_server"`
+	Force        bool   `json:"force"`
+	// TLSCAFile, TLSCertFile and TLSKeyFile are paths; the files are read
+	// by the agent and not part of the plan
+	TLSCAFile   string `json:"tls_ca_file,omitempty"`
+	TLSCertFile string `json:"tls_cert_file,omitempty"`
+	TLSKeyFile  string `json:"tls_key_file,omitempty"`
+	// Confine restricts the agent service with a system call filter and an
+	// AppArmor profile
+	Confine bool `json:"confine,omitempty"`
+	// Cloud is the cloud instance detected when the plan was created, so
+	// the reviewed registration metadata is what gets installed
+	Cloud *cloudmeta.Instance `json:"cloud,omitempty"`
+}
+
+// Download is a file fetched during installation. SHA256 pins the exact
+// bytes reviewed; applying the plan fails if the download differs.
+type Download struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	URL         string `json:"url"`
+	SHA256      string `json:"sha256"`
+	Size        int64  `json:"size"`
+	Destination string `json:"destination"`
+}
+
+// File is a file written during installation
+type File struct {
+	Path        string `json:"path"`
+	Mode        string `json:"mode"`
+	Description string `json:"description"`
+	Content     string `json:"content"`
+}
+
+// Service is a system service created during installation
+type Service struct {
+	Name     string `json:"name"`
+	Manager  string `json:"manager"`
+	UnitPath string `json:"unit_path"`
+	Enable   bool   `json:"enable"`
+	Start    bool   `json:"start"`
+}
+
+// Write encodes the plan as indented JSON
+func (p *Plan) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p)
+}
+
+// File returns the planned file at path, or nil
+func (p *Plan) File(path string) *File {
+	for i := range p.Files {
+		if p.Files[i].Path == path {
+			return &p.Files[i]
+		}
+	}
+	return nil
+}
+
+// Load reads and validates a plan file
+func Load(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+
+	var p Plan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse plan %s: %w", path, err)
+	}
+	if p.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("plan %s has format version %d, this CLI supports %d", path, p.FormatVersion, FormatVersion)
+	}
+	if p.Inputs.AgentID == "" {
+		return nil, fmt.Errorf("plan %s has no agent ID", path)
+	}
+	for _, download := range p.Downloads {
+		if download.URL == "" || download.SHA256 == "" {
+			return nil, fmt.Errorf("plan %s has a download without URL or checksum", path)
+		}
+	}
+
+	return &p, nil
+}
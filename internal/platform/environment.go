@@ -0,0 +1,148 @@
+package platform
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Environment describes the kind of system the CLI runs on
+type Environment string
+
+// Known environments
+const (
+	EnvironmentHost      Environment = "host"
+	EnvironmentWSL1      Environment = "wsl1"
+	EnvironmentWSL2      Environment = "wsl2"
+	EnvironmentDocker    Environment = "docker"
+	EnvironmentPodman    Environment = "podman"
+	EnvironmentLXC       Environment = "lxc"
+	EnvironmentContainer Environment = "container"
+)
+
+// String returns a human readable name for the environment
+func (e Environment) String() string {
+	switch e {
+	case EnvironmentWSL1:
+		return "WSL 1"
+	case EnvironmentWSL2:
+		return "WSL 2"
+	case EnvironmentDocker:
+		return "Docker container"
+	case EnvironmentPodman:
+		return "Podman container"
+	case EnvironmentLXC:
+		return "LXC container"
+	case EnvironmentContainer:
+		return "container"
+	default:
+		return "host"
+	}
+}
+
+// IsContainer reports whether the environment is a container
+func (e Environment) IsContainer() bool {
+	switch e {
+	case EnvironmentDocker, EnvironmentPodman, EnvironmentLXC, EnvironmentContainer:
+		return true
+	}
+	return false
+}
+
+// IsWSL reports whether the environment is the Windows Subsystem for Linux
+func (e Environment) IsWSL() bool {
+	return e == EnvironmentWSL1 || e == EnvironmentWSL2
+}
+
+// DetectEnvironment identifies WSL and container environments. Only Linux is
+// inspected; other systems are always reported as a host.
+func DetectEnvironment() Environment {
+	if runtime.GOOS != "linux" {
+		return EnvironmentHost
+	}
+
+	// WSL kernels identify themselves in the release string: WSL 1 reports
+	// e.g. "4.4.0-19041-Microsoft", WSL 2 "5.15.90.1-microsoft-standard-WSL2"
+	if release, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		lower := strings.ToLower(string(release))
+		if strings.Contains(lower, "microsoft") {
+			if strings.Contains(lower, "wsl2") || strings.Contains(lower, "microsoft-standard") {
+				return EnvironmentWSL2
+			}
+			return EnvironmentWSL1
+		}
+	}
+
+	// Marker files dropped by the container runtimes
+	if fileExists("/.dockerenv") {
+		return EnvironmentDocker
+	}
+	if fileExists("/run/.containerenv") {
+		return EnvironmentPodman
+	}
+
+	// LXC and systemd-nspawn set $container for PID 1; it is inherited by
+	// most processes but /proc/1/environ is only readable as root
+	if kind := containerVariable(); kind != "" {
+		if kind == "lxc" || kind == "lxc-libvirt" {
+			return EnvironmentLXC
+		}
+		if kind == "docker" {
+			return EnvironmentDocker
+		}
+		if kind == "podman" {
+			return EnvironmentPodman
+		}
+		return EnvironmentContainer
+	}
+
+	if cgroup, err := os.ReadFile("/proc/1/cgroup"); err == nil {
+		content := string(cgroup)
+		switch {
+		case strings.Contains(content, "/docker"):
+			return EnvironmentDocker
+		case strings.Contains(content, "/lxc"):
+			return EnvironmentLXC
+		case strings.Contains(content, "kubepods"), strings.Contains(content, "containerd"):
+			return EnvironmentContainer
+		}
+	}
+
+	return EnvironmentHost
+}
+
+// containerVariable returns the value of $container for this process or PID 1
+func containerVariable() string {
+	if kind := os.Getenv("container"); kind != "" {
+		return kind
+	}
+	environ, err := os.ReadFile("/proc/1/environ")
+	if err != nil {
+		return ""
+	}
+	for _, entry := range strings.Split(string(environ), "\x00") {
+		if value, ok := strings.CutPrefix(entry, "container="); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// InitSystem returns the name of PID 1 (e.g. "systemd", "init", "tini"), or
+// an empty string if it can't be determined
+func InitSystem() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	comm, err := os.ReadFile("/proc/1/comm")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(comm))
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,62 @@
+package platform
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// OSRelease identifies the operating system distribution
+type OSRelease struct {
+	// ID is the machine-readable distribution name, e.g. "ubuntu"
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// GetOSRelease returns the distribution described by /etc/os-release, or the
+// operating system name where there is no such file
+func GetOSRelease() OSRelease {
+	release := OSRelease{ID: runtime.GOOS, Name: runtime.GOOS}
+	data, err := os.ReadFile("/etc/os-release")
+	if err != nil {
+		data, err = os.ReadFile("/usr/lib/os-release")
+	}
+	if err != nil {
+		return release
+	}
+
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if key, value, ok := strings.Cut(scanner.Text(), "="); ok {
+			fields[key] = strings.Trim(value, `"'`)
+		}
+	}
+	if fields["ID"] != "" {
+		release.ID = fields["ID"]
+	}
+	if name := fields["PRETTY_NAME"]; name != "" {
+		release.Name = name
+	} else if fields["NAME"] != "" {
+		release.Name = fields["NAME"]
+	}
+	release.Version = fields["VERSION_ID"]
+	return release
+}
+
+// KernelRelease returns the release of the running kernel, as uname -r
+// reports it, or an empty string if it can't be determined
+func KernelRelease() string {
+	if release, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		return strings.TrimSpace(string(release))
+	}
+	output, err := exec.Command("uname", "-r").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
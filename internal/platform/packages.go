@@ -0,0 +1,96 @@
+package platform
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Package managers
+const (
+	PackageManagerDpkg = "dpkg"
+	PackageManagerRPM  = "rpm"
+)
+
+// PackageOwner is the distribution package a file belongs to
+type PackageOwner struct {
+	// Manager is "dpkg" or "rpm"
+	Manager string `json:"manager"`
+	Package string `json:"package"`
+}
+
+// UpgradeCommand returns the command upgrading the package
+func (o *PackageOwner) UpgradeCommand() string {
+	if o.Manager == PackageManagerRPM {
+		return "sudo dnf upgrade " + o.Package
+	}
+	return "sudo apt-get install --only-upgrade " + o.Package
+}
+
+// HoldCommand returns the command keeping the package manager from updating
+// the package
+func (o *PackageOwner) HoldCommand() string {
+	if o.Manager == PackageManagerRPM {
+		return "sudo dnf versionlock add " + o.Package
+	}
+	return "sudo apt-mark hold " + o.Package
+}
+
+// FilePackageOwner returns the package that installed the file at path
+// according to dpkg or rpm, or nil if neither knows it. Symbolic links are
+// resolved, as packages may install the file under its target's name.
+func FilePackageOwner(ctx context.Context, path string) *PackageOwner {
+	paths := []string{path}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil && resolved != path {
+		paths = append(paths, resolved)
+	}
+	for _, candidate := range paths {
+		if owner := dpkgOwner(ctx, candidate); owner != nil {
+			return owner
+		}
+		if owner := rpmOwner(ctx, candidate); owner != nil {
+			return owner
+		}
+	}
+	return nil
+}
+
+// dpkgOwner asks dpkg for the package of path. Its output is
+// "package[:arch][, other]: path", preceded by diversion lines for diverted
+// files.
+func dpkgOwner(ctx context.Context, path string) *PackageOwner {
+	if !IsCommandAvailable("dpkg-query") {
+		return nil
+	}
+	output, err := exec.CommandContext(ctx, "dpkg-query", "--search", path).Output()
+	if err != nil {
+		return nil
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		packages, file, ok := strings.Cut(line, ": ")
+		if !ok || strings.HasPrefix(line, "diversion ") || strings.TrimSpace(file) != path {
+			continue
+		}
+		name, _, _ := strings.Cut(packages, ",")
+		name, _, _ = strings.Cut(strings.TrimSpace(name), ":")
+		return &PackageOwner{Manager: PackageManagerDpkg, Package: name}
+	}
+	return nil
+}
+
+// rpmOwner asks rpm for the package of path
+func rpmOwner(ctx context.Context, path string) *PackageOwner {
+	if !IsCommandAvailable("rpm") {
+		return nil
+	}
+	output, err := exec.CommandContext(ctx, "rpm", "--query", "--file", "--queryformat", "%{NAME}\n", path).Output()
+	if err != nil {
+		return nil
+	}
+	name, _, _ := strings.Cut(string(output), "\n")
+	if name = strings.TrimSpace(name); name == "" {
+		return nil
+	}
+	return &PackageOwner{Manager: PackageManagerRPM, Package: name}
+}
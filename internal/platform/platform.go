@@ -5,8 +5,13 @@ import (
 	"os"
 	"os/exec"
 	"os/user"
+	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
 )
 
 // PlatformInfo contains platform-specific information
@@ -17,7 +22,51 @@ type PlatformInfo struct {
 	BinDir    string
 	ConfigDir string
 	LogDir    string
-	IsRoot    bool
+	// StateDir holds what the CLI remembers between runs
+	StateDir string
+	IsRoot   bool
+	// Prefix is the directory everything was relocated to with --prefix or
+	// FIXPANIC_HOME, or empty for the default layout
+	Prefix string
+}
+
+// EnvHome relocates the binary, config and logs below a single directory
+const EnvHome = "FIXPANIC_HOME"
+
+// prefix is set by --prefix and takes precedence over $FIXPANIC_HOME
+var prefix string
+
+// SetPrefix relocates all paths below dir, e.g. /opt/fixpanic
+func SetPrefix(dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid prefix %q: %w", dir, err)
+	}
+	prefix = abs
+	return nil
+}
+
+// installPrefix returns the directory set by --prefix or $FIXPANIC_HOME
+func installPrefix() string {
+	if prefix != "" {
+		return prefix
+	}
+	if home := os.Getenv(EnvHome); home != "" {
+		if abs, err := filepath.Abs(home); err == nil {
+			return abs
+		}
+		return home
+	}
+	return ""
+}
+
+// xdgDir returns $variable/fixpanic when the XDG base directory variable is
+// set to an absolute path, and fallback otherwise
+func xdgDir(variable, fallback string) string {
+	if dir := os.Getenv(variable); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "fixpanic")
+	}
+	return fallback
 }
 
 // GetPlatformInfo returns platform-specific information
@@ -30,19 +79,30 @@ func GetPlatformInfo() (*PlatformInfo, error) {
 	}
 	isRoot := currentUser.Uid == "0"
 
-	var libDir, binDir, configDir, logDir string
+	var libDir, binDir, configDir, logDir, stateDir string
 
-	if isRoot {
+	root := installPrefix()
+	if root != "" {
+		libDir = filepath.Join(root, "lib")
+		binDir = filepath.Join(root, "bin")
+		configDir = filepath.Join(root, "etc")
+		logDir = filepath.Join(root, "log")
+		stateDir = filepath.Join(root, "state")
+	} else if isRoot {
 		libDir = "/usr/local/lib/fixpanic"
 		binDir = "/usr/local/bin"
 		configDir = "/etc/fixpanic"
 		logDir = "/var/log/fixpanic"
+		stateDir = "/var/lib/fixpanic"
 	} else {
-		home := currentUser.HomeDir
-		libDir = fmt.Sprintf("%s/.local/lib/fixpanic", home)
-		binDir = fmt.Sprintf("%s/.local/bin", home)
-		configDir = fmt.Sprintf("%s/.config/fixpanic", home)
-		logDir = fmt.Sprintf("%s/.local/log/fixpanic", home)
+		// XDG variables are only honoured when set so existing installs in
+		// the default locations keep working
+		defaults := UserDefaultDirs(currentUser.HomeDir)
+		libDir = xdgDir("XDG_DATA_HOME", defaults.LibDir)
+		binDir = defaults.BinDir
+		configDir = xdgDir("XDG_CONFIG_HOME", defaults.ConfigDir)
+		logDir = xdgDir("XDG_STATE_HOME", defaults.LogDir)
+		stateDir = xdgDir("XDG_STATE_HOME", defaults.StateDir)
 	}
 
 	return &PlatformInfo{
@@ -52,10 +112,24 @@ func GetPlatformInfo() (*PlatformInfo, error) {
 		BinDir:    binDir,
 		ConfigDir: configDir,
 		LogDir:    logDir,
+		StateDir:  stateDir,
 		IsRoot:    isRoot,
+		Prefix:    root,
 	}, nil
 }
 
+// UserDefaultDirs returns the directories of a non-root installation in home
+// when no XDG base directory variables are set
+func UserDefaultDirs(home string) PlatformInfo {
+	return PlatformInfo{
+		LibDir:    fmt.Sprintf("%s/.local/lib/fixpanic", home),
+		BinDir:    fmt.Sprintf("%s/.local/bin", home),
+		ConfigDir: fmt.Sprintf("%s/.config/fixpanic", home),
+		LogDir:    fmt.Sprintf("%s/.local/log/fixpanic", home),
+		StateDir:  fmt.Sprintf("%s/.local/state/fixpanic", home),
+	}
+}
+
 // GetFixPanicAgentBinaryName returns the correct binary name for FixPanic Agent
 func GetFixPanicAgentBinaryName() string {
 	if runtime.GOOS == "windows" {
@@ -67,7 +141,7 @@ func GetFixPanicAgentBinaryName() string {
 // GetConnectivityBinaryName returns the connectivity binary name for the current platform (DEPRECATED)
 // TODO: Remove this function after migration to GetFixPanicAgentBinaryName
 func GetConnectivityBinaryName() string {
-	fmt.Println("WARNING: GetConnectivityBinaryName is deprecated, use GetFixPanicAgentBinaryName instead")
+	logger.Deprecated("GetConnectivityBinaryName", "GetFixPanicAgentBinaryName")
 	return GetFixPanicAgentBinaryName()
 }
 
@@ -101,8 +175,9 @@ func GetFixPanicAgentPlatformInfo() (os, arch string, err error) {
 		arch = "arm64"
 	case "386", "i386", "i686":
 		arch = "386"
-	case "arm", "armv7":
-		arch = "arm"
+	case "arm":
+		// 32-bit ARM agents are built per ARM version
+		arch = armVariant(machineName())
 	default:
 		return "", "", fmt.Errorf("unsupported architecture: %s", goarch)
 	}
@@ -110,9 +185,110 @@ func GetFixPanicAgentPlatformInfo() (os, arch string, err error) {
 	return os, arch, nil
 }
 
+// Libc variants of Linux agent builds
+const (
+	LibcGlibc = "glibc"
+	LibcMusl  = "musl"
+)
+
+// DetectLibc reports which C library the host uses. Only Linux has libc
+// specific agent builds; other systems return an empty string.
+func DetectLibc() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+
+	// The musl dynamic loader is the most reliable marker (Alpine, Void musl)
+	if matches, _ := filepath.Glob("/lib/ld-musl-*.so.1"); len(matches) > 0 {
+		return LibcMusl
+	}
+
+	// musl's ldd prints its banner to stderr and exits non-zero for --version
+	output, _ := exec.Command("ldd", "--version").CombinedOutput()
+	if strings.Contains(strings.ToLower(string(output)), "musl") {
+		return LibcMusl
+	}
+
+	return LibcGlibc
+}
+
+// machineName returns the hardware name reported by uname -m, or an empty
+// string if it can't be determined
+func machineName() string {
+	output, err := exec.Command("uname", "-m").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// armVariant maps a uname -m machine name to the 32-bit ARM agent build.
+// 64-bit ARM hardware running a 32-bit userland (e.g. Raspberry Pi OS with a
+// 64-bit kernel) reports aarch64 but runs armv7 binaries. Unknown machines
+// get armv6, which runs on every 32-bit ARM Linux host.
+func armVariant(machine string) string {
+	machine = strings.ToLower(machine)
+	switch {
+	case strings.HasPrefix(machine, "armv7"), strings.HasPrefix(machine, "armv8"), machine == "aarch64", machine == "arm64":
+		return "armv7"
+	default:
+		return "armv6"
+	}
+}
+
+// GetFixPanicAgentAssetName returns the release asset name of the agent
+// build for this host, e.g. fixpanic-connectivity-layer-linux-amd64-musl
+func GetFixPanicAgentAssetName() (string, error) {
+	os, arch, err := GetFixPanicAgentPlatformInfo()
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("fixpanic-connectivity-layer-%s-%s", os, arch)
+	if DetectLibc() == LibcMusl {
+		name += "-musl"
+	}
+	return name, nil
+}
+
+// FixPanicAgentAssetNames returns the release asset names of every agent
+// build for a GOOS/GOARCH pair. Linux libc variants and 32-bit ARM versions
+// are all included since they can't be told apart without the target host.
+func FixPanicAgentAssetNames(goos, goarch string) ([]string, error) {
+	switch goos {
+	case "linux", "darwin", "windows":
+	default:
+		return nil, fmt.Errorf("unsupported operating system: %s", goos)
+	}
+
+	var arches []string
+	switch goarch {
+	case "amd64", "x86_64":
+		arches = []string{"amd64"}
+	case "arm64", "aarch64":
+		arches = []string{"arm64"}
+	case "386", "i386", "i686":
+		arches = []string{"386"}
+	case "arm":
+		arches = []string{"armv6", "armv7"}
+	default:
+		return nil, fmt.Errorf("unsupported architecture: %s", goarch)
+	}
+
+	var names []string
+	for _, arch := range arches {
+		name := fmt.Sprintf("fixpanic-connectivity-layer-%s-%s", goos, arch)
+		names = append(names, name)
+		if goos == "linux" {
+			names = append(names, name+"-"+LibcMusl)
+		}
+	}
+	return names, nil
+}
+
 // GetFixPanicAgentDownloadURL returns the correct GitHub Releases URL
 func GetFixPanicAgentDownloadURL(version string) (string, error) {
-	os, arch, err := GetFixPanicAgentPlatformInfo()
+	assetName, err := GetFixPanicAgentAssetName()
 	if err != nil {
 		return "", fmt.Errorf("failed to get platform info: %w", err)
 	}
@@ -121,20 +297,19 @@ func GetFixPanicAgentDownloadURL(version string) (string, error) {
 	baseURL := "https://github.com/fixpanic/fixpanic-connectivity-layer-release/releases"
 
 	if version == "latest" {
-		return fmt.Sprintf("%s/latest/download/fixpanic-connectivity-layer-%s-%s", baseURL, os, arch), nil
+		return fmt.Sprintf("%s/latest/download/%s", baseURL, assetName), nil
 	}
 
-	return fmt.Sprintf("%s/download/%s/fixpanic-connectivity-layer-%s-%s", baseURL, version, os, arch), nil
+	return fmt.Sprintf("%s/download/%s/%s", baseURL, version, assetName), nil
 }
 
 // GetConnectivityDownloadURL returns the download URL for the connectivity binary (DEPRECATED)
 // TODO: Remove this function after migration to GetFixPanicAgentDownloadURL
 func GetConnectivityDownloadURL(version string) string {
-	fmt.Println("WARNING: GetConnectivityDownloadURL is deprecated, use GetFixPanicAgentDownloadURL instead")
+	logger.Deprecated("GetConnectivityDownloadURL", "GetFixPanicAgentDownloadURL")
 	url, err := GetFixPanicAgentDownloadURL(version)
 	if err != nil {
 		// For backward compatibility, return empty string on error
-		fmt.Printf("Error getting download URL: %v\n", err)
 		return ""
 	}
 	return url
@@ -142,16 +317,28 @@ func GetConnectivityDownloadURL(version string) string {
 
 // IsCommandAvailable checks if a command is available in PATH
 func IsCommandAvailable(name string) bool {
-	cmd := exec.Command("which", name)
-	if err := cmd.Run(); err != nil {
-		return false
-	}
-	return true
+	_, err := exec.LookPath(name)
+	return err == nil
 }
 
-// IsSystemdAvailable checks if systemd is available on the system
-func IsSystemdAvailable() bool {
-	return IsCommandAvailable("systemctl")
+// Uptime returns how long the system has been running (Linux only)
+func Uptime() (time.Duration, error) {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read uptime: %w", err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("unexpected /proc/uptime format")
+	}
+
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse uptime: %w", err)
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
 }
 
 // GetSystemdServiceName returns the systemd service name
@@ -159,6 +346,22 @@ func GetSystemdServiceName() string {
 	return "fixpanic-connectivity-layer.service"
 }
 
+// GetLockPath returns the path of the lock serializing CLI operations
+func (p *PlatformInfo) GetLockPath() string {
+	return filepath.Join(p.ConfigDir, "fixpanic.lock")
+}
+
+// GetControlSocketPath returns the path of the Unix socket the running agent
+// serves its local control API on
+func (p *PlatformInfo) GetControlSocketPath() string {
+	return filepath.Join(p.StateDir, "agent.sock")
+}
+
+// GetWindowsServiceName returns the Windows service name
+func GetWindowsServiceName() string {
+	return "fixpanic-connectivity-layer"
+}
+
 // CreateDirectories creates the necessary directories for the agent
 func (p *PlatformInfo) CreateDirectories() error {
 	dirs := []string{
@@ -186,6 +389,36 @@ func (p *PlatformInfo) GetConfigPath() string {
 	return fmt.Sprintf("%s/agent.yaml", p.ConfigDir)
 }
 
+// GetLogPath returns the full path to the agent log file
+func (p *PlatformInfo) GetLogPath() string {
+	return fmt.Sprintf("%s/agent.log", p.LogDir)
+}
+
+// GetWatchdogStatusPath returns the full path to the watchdog status file
+func (p *PlatformInfo) GetWatchdogStatusPath() string {
+	return fmt.Sprintf("%s/watchdog.json", p.LogDir)
+}
+
+// GetLogLevelRevertPath returns the full path to the pending log level revert
+func (p *PlatformInfo) GetLogLevelRevertPath() string {
+	return fmt.Sprintf("%s/log-level-revert.json", p.ConfigDir)
+}
+
+// GetServeTokenPath returns the full path to the token of the local API server
+func (p *PlatformInfo) GetServeTokenPath() string {
+	return fmt.Sprintf("%s/serve-token", p.ConfigDir)
+}
+
+// GetPanicStopPath returns the full path to the emergency stop marker
+func (p *PlatformInfo) GetPanicStopPath() string {
+	return fmt.Sprintf("%s/panic-stop", p.ConfigDir)
+}
+
+// GetHooksDir returns the directory holding lifecycle hook scripts
+func (p *PlatformInfo) GetHooksDir() string {
+	return fmt.Sprintf("%s/hooks.d", p.ConfigDir)
+}
+
 // GetServiceFilePath returns the full path to the systemd service file
 func (p *PlatformInfo) GetServiceFilePath() string {
 	return fmt.Sprintf("/etc/systemd/system/%s", GetSystemdServiceName())
@@ -202,7 +435,9 @@ func NormalizeArch(arch string) string {
 	case "i386", "i686":
 		return "386"
 	case "armv7", "armv7l":
-		return "arm"
+		return "armv7"
+	case "armv6", "armv6l":
+		return "armv6"
 	default:
 		return arch
 	}
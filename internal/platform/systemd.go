@@ -0,0 +1,150 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// systemdProbeTimeout bounds each step of the systemd probe
+const systemdProbeTimeout = 3 * time.Second
+
+// systemdBusSockets are the sockets systemctl talks to: the private socket
+// used as root and the D-Bus system bus used otherwise
+var systemdBusSockets = []string{"/run/systemd/private", "/run/dbus/system_bus_socket"}
+
+// SystemdProbe is the result of checking whether systemd can manage services
+type SystemdProbe struct {
+	// Systemctl is true when the systemctl command is installed
+	Systemctl bool
+	// Booted is true when systemd runs as PID 1
+	Booted bool
+	// Bus is true when systemd's private socket or the system bus accepts connections
+	Bus bool
+	// State is the output of 'systemctl is-system-running' (running, degraded, ...)
+	State string
+}
+
+// Available reports whether services can be managed through systemd
+func (p *SystemdProbe) Available() bool {
+	return p.Systemctl && p.Booted && p.Bus && systemdStateUsable(p.State)
+}
+
+// Reason explains why systemd is unavailable, or returns an empty string
+func (p *SystemdProbe) Reason() string {
+	switch {
+	case !p.Systemctl:
+		return "systemctl is not installed"
+	case !p.Booted:
+		if initSystem := InitSystem(); initSystem != "" {
+			return fmt.Sprintf("PID 1 is %s, not systemd", initSystem)
+		}
+		return "systemd is not running as PID 1"
+	case !p.Bus:
+		return "the systemd bus is not reachable"
+	case !systemdStateUsable(p.State):
+		if p.State == "" {
+			return "'systemctl is-system-running' did not respond"
+		}
+		return fmt.Sprintf("systemd reports state %q", p.State)
+	}
+	return ""
+}
+
+// systemdStateUsable reports whether systemd accepts jobs in state. Degraded
+// only means some unit failed; offline and unknown mean systemd isn't
+// reachable (e.g. inside a chroot).
+func systemdStateUsable(state string) bool {
+	switch state {
+	case "initializing", "starting", "running", "degraded", "maintenance":
+		return true
+	}
+	return false
+}
+
+var (
+	systemdProbeOnce   sync.Once
+	systemdProbeResult *SystemdProbe
+)
+
+// ProbeSystemd checks whether systemd can manage services on this host. The
+// result is computed once per process.
+func ProbeSystemd() *SystemdProbe {
+	systemdProbeOnce.Do(func() {
+		systemdProbeResult = probeSystemd()
+	})
+	return systemdProbeResult
+}
+
+// probeSystemd runs the checks from cheapest to most expensive, stopping at
+// the first failure
+func probeSystemd() *SystemdProbe {
+	probe := &SystemdProbe{}
+	if runtime.GOOS != "linux" {
+		return probe
+	}
+
+	if probe.Systemctl = IsCommandAvailable("systemctl"); !probe.Systemctl {
+		return probe
+	}
+	// Same check as sd_booted(3)
+	if probe.Booted = fileExists("/run/systemd/system") || InitSystem() == "systemd"; !probe.Booted {
+		return probe
+	}
+	if probe.Bus = systemdBusReachable(); !probe.Bus {
+		return probe
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), systemdProbeTimeout)
+	defer cancel()
+	// is-system-running exits non-zero for states other than running but
+	// still prints the state, so the error is ignored
+	output, _ := exec.CommandContext(ctx, "systemctl", "is-system-running").Output()
+	probe.State = strings.TrimSpace(string(output))
+
+	return probe
+}
+
+// systemdBusReachable reports whether any socket systemctl uses accepts connections
+func systemdBusReachable() bool {
+	for _, socket := range systemdBusSockets {
+		conn, err := net.DialTimeout("unix", socket, systemdProbeTimeout)
+		if err == nil {
+			conn.Close()
+			return true
+		}
+	}
+	return false
+}
+
+// IsSystemdAvailable checks if systemd is available on the system. Having
+// systemctl installed isn't enough: containers, chroots and WSL often ship it
+// without a running systemd, so the full probe has to pass.
+func IsSystemdAvailable() bool {
+	return ProbeSystemd().Available()
+}
+
+// TimeSynchronized reports whether systemd-timesyncd (or another NTP client
+// systemd knows about) has synchronized the system clock. ok is false when
+// this cannot be determined, e.g. without systemd.
+func TimeSynchronized(ctx context.Context) (synchronized, ok bool) {
+	if !IsSystemdAvailable() || !IsCommandAvailable("timedatectl") {
+		return false, false
+	}
+	output, err := exec.CommandContext(ctx, "timedatectl", "show", "--property=NTPSynchronized", "--value").Output()
+	if err != nil {
+		return false, false
+	}
+	switch strings.TrimSpace(string(output)) {
+	case "yes":
+		return true, true
+	case "no":
+		return false, true
+	}
+	return false, false
+}
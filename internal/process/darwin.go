@@ -4,9 +4,12 @@
 package process
 
 import (
+	"context"
+	"encoding/xml"
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"syscall"
 )
 
@@ -81,6 +84,20 @@ func (d *DarwinProcessManager) StartProcess(config ProcessConfig) (*ProcessInfo,
 	}, nil
 }
 
+// processExists checks process existence for BaseProcessManager by sending signal 0
+func processExists(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+// newPlatformServiceController has nothing to return; the agent isn't registered with launchd
+func newPlatformServiceController(serviceName string) (ServiceController, bool) {
+	return nil, false
+}
+
 // StopProcess stops a process on macOS
 func (d *DarwinProcessManager) StopProcess(pid int) error {
 	if pid <= 0 {
@@ -117,10 +134,12 @@ func NewDarwinServiceManager(serviceName string) *DarwinServiceManager {
 	}
 }
 
-// InstallService installs the agent as a macOS launchd service
-func (d *DarwinServiceManager) InstallService(binaryPath, configPath string) error {
+// InstallService installs the agent as a macOS launchd service. env holds
+// NAME=value pairs for the agent process; launchd can't read an environment
+// file, so its variables must already be included.
+func (d *DarwinServiceManager) InstallService(ctx context.Context, binaryPath, configPath string, env []string) error {
 	// Generate launchd plist content
-	plistContent := d.generatePlistContent(binaryPath, configPath)
+	plistContent := d.generatePlistContent(binaryPath, configPath, env)
 
 	// Get the launchd plist path
 	plistPath := d.getPlistPath()
@@ -140,17 +159,17 @@ func (d *DarwinServiceManager) InstallService(binaryPath, configPath string) err
 }
 
 // StartService loads and starts the launchd service
-func (d *DarwinServiceManager) StartService() error {
+func (d *DarwinServiceManager) StartService(ctx context.Context) error {
 	plistPath := d.getPlistPath()
 
 	// Load the service
-	cmd := exec.Command("launchctl", "load", plistPath)
+	cmd := exec.CommandContext(ctx, "launchctl", "load", plistPath)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to load launchd service: %w", err)
 	}
 
 	// Start the service
-	cmd = exec.Command("launchctl", "start", d.serviceName)
+	cmd = exec.CommandContext(ctx, "launchctl", "start", d.serviceName)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to start launchd service: %w", err)
 	}
@@ -159,9 +178,9 @@ func (d *DarwinServiceManager) StartService() error {
 }
 
 // StopService stops the launchd service
-func (d *DarwinServiceManager) StopService() error {
+func (d *DarwinServiceManager) StopService(ctx context.Context) error {
 	// Stop the service
-	cmd := exec.Command("launchctl", "stop", d.serviceName)
+	cmd := exec.CommandContext(ctx, "launchctl", "stop", d.serviceName)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to stop launchd service: %w", err)
 	}
@@ -170,11 +189,11 @@ func (d *DarwinServiceManager) StopService() error {
 }
 
 // UnloadService unloads the launchd service
-func (d *DarwinServiceManager) UnloadService() error {
+func (d *DarwinServiceManager) UnloadService(ctx context.Context) error {
 	plistPath := d.getPlistPath()
 
 	// Unload the service
-	cmd := exec.Command("launchctl", "unload", plistPath)
+	cmd := exec.CommandContext(ctx, "launchctl", "unload", plistPath)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to unload launchd service: %w", err)
 	}
@@ -183,9 +202,9 @@ func (d *DarwinServiceManager) UnloadService() error {
 }
 
 // GetServiceStatus gets the status of the launchd service
-func (d *DarwinServiceManager) GetServiceStatus() (string, error) {
+func (d *DarwinServiceManager) GetServiceStatus(ctx context.Context) (string, error) {
 	// Check if service is loaded
-	cmd := exec.Command("launchctl", "list")
+	cmd := exec.CommandContext(ctx, "launchctl", "list")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to list launchd services: %w", err)
@@ -194,7 +213,7 @@ func (d *DarwinServiceManager) GetServiceStatus() (string, error) {
 	outputStr := string(output)
 	if contains(outputStr, d.serviceName) {
 		// Service is loaded, check if it's running
-		cmd = exec.Command("launchctl", "list", d.serviceName)
+		cmd = exec.CommandContext(ctx, "launchctl", "list", d.serviceName)
 		output, err = cmd.Output()
 		if err != nil {
 			return "loaded", nil // Service is loaded but not running
@@ -216,7 +235,17 @@ func (d *DarwinServiceManager) getPlistPath() string {
 }
 
 // generatePlistContent generates the launchd plist content
-func (d *DarwinServiceManager) generatePlistContent(binaryPath, configPath string) string {
+func (d *DarwinServiceManager) generatePlistContent(binaryPath, configPath string, env []string) string {
+	var environment strings.Builder
+	if len(env) > 0 {
+		environment.WriteString("    <key>EnvironmentVariables</key>\n    <dict>\n")
+		for _, pair := range env {
+			name, value, _ := strings.Cut(pair, "=")
+			fmt.Fprintf(&environment, "        <key>%s</key>\n        <string>%s</string>\n", xmlEscape(name), xmlEscape(value))
+		}
+		environment.WriteString("    </dict>\n")
+	}
+
 	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
 <!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
 <plist version="1.0">
@@ -229,7 +258,7 @@ func (d *DarwinServiceManager) generatePlistContent(binaryPath, configPath strin
         <string>--config</string>
         <string>%s</string>
     </array>
-    <key>RunAtLoad</key>
+%s    <key>RunAtLoad</key>
     <true/>
     <key>KeepAlive</key>
     <true/>
@@ -238,7 +267,14 @@ func (d *DarwinServiceManager) generatePlistContent(binaryPath, configPath strin
     <key>StandardErrorPath</key>
     <string>/tmp/fixpanic-agent-error.log</string>
 </dict>
-</plist>`, d.serviceName, binaryPath, configPath)
+</plist>`, d.serviceName, binaryPath, configPath, environment.String())
+}
+
+// xmlEscape escapes s for use as plist text
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
 }
 
 // Darwin-specific helper functions
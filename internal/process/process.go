@@ -1,13 +1,7 @@
 // Package process provides cross-platform process management functionality
 package process
 
-import (
-	"fmt"
-	"os"
-	"os/exec"
-	"runtime"
-	"syscall"
-)
+import "context"
 
 // ProcessConfig contains configuration for starting a process
 type ProcessConfig struct {
@@ -33,6 +27,21 @@ type ProcessManager interface {
 	GetProcessStatus(pid int) *ProcessInfo
 }
 
+// ServiceController controls a service registered with the operating
+// system's native service manager
+type ServiceController interface {
+	StartService(ctx context.Context) error
+	StopService(ctx context.Context) error
+	GetServiceStatus(ctx context.Context) (string, error)
+}
+
+// NewServiceController returns the native service controller for platforms
+// where the agent is managed without systemd (Windows Service Control
+// Manager). ok is false on platforms without one.
+func NewServiceController(serviceName string) (controller ServiceController, ok bool) {
+	return newPlatformServiceController(serviceName)
+}
+
 // NewProcessManager creates a platform-specific process manager
 func NewProcessManager() ProcessManager {
 	// This function will be implemented in platform-specific files
@@ -59,41 +68,8 @@ func (b *BaseProcessManager) IsProcessRunning(pid int) bool {
 		return false
 	}
 
-	// Try to send signal 0 to check if process exists
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return false
-	}
-
-	// On Unix systems, signal 0 can be used to check if process exists
-	if runtime.GOOS != "windows" {
-		err = process.Signal(syscall.Signal(0))
-		return err == nil
-	}
-
-	// On Windows, we need a different approach
-	return b.isWindowsProcessRunning(pid)
-}
-
-// isWindowsProcessRunning checks if a Windows process is running
-func (b *BaseProcessManager) isWindowsProcessRunning(pid int) bool {
-	// Use tasklist command to check if process exists
-	cmd := exec.Command("tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/NH", "/FO", "CSV")
-	output, err := cmd.Output()
-	if err != nil {
-		return false
-	}
-
-	// Check if output contains the PID
-	return len(output) > 0 && !isWindowsProcessNotFound(output)
-}
-
-// isWindowsProcessNotFound checks if tasklist output indicates process not found
-func isWindowsProcessNotFound(output []byte) bool {
-	outputStr := string(output)
-	return contains(outputStr, "No tasks are running") ||
-		contains(outputStr, "INFO: No tasks") ||
-		contains(outputStr, "ERROR:")
+	// Uses native APIs on every platform (signal 0 on Unix, OpenProcess on Windows)
+	return processExists(pid)
 }
 
 // contains checks if a string contains a substring (case-insensitive)
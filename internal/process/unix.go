@@ -4,6 +4,7 @@
 package process
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -39,10 +40,10 @@ func (u *UnixProcessManager) StartProcess(config ProcessConfig) (*ProcessInfo, e
 
 	// Unix-specific process creation attributes
 	if config.Detach {
-		// Create new session and process group for proper detachment
+		// Create a new session, which also puts the process in its own
+		// process group; setpgid on top of it fails with EPERM
 		cmd.SysProcAttr = &syscall.SysProcAttr{
-			Setsid:  true, // Create new session
-			Setpgid: true, // Set process group ID
+			Setsid: true,
 		}
 	}
 
@@ -51,18 +52,30 @@ func (u *UnixProcessManager) StartProcess(config ProcessConfig) (*ProcessInfo, e
 		return nil, fmt.Errorf("failed to start process on Unix: %w", err)
 	}
 
-	// Release the process to allow it to continue running independently
+	// Release the process to allow it to continue running independently;
+	// Release resets Pid, so read it first
+	pid := cmd.Process.Pid
 	if err := cmd.Process.Release(); err != nil {
 		return nil, fmt.Errorf("failed to release process on Unix: %w", err)
 	}
 
 	return &ProcessInfo{
-		PID:     cmd.Process.Pid,
+		PID:     pid,
 		Running: true,
 		Error:   nil,
 	}, nil
 }
 
+// processExists checks process existence for BaseProcessManager
+func processExists(pid int) bool {
+	return CheckUnixProcessExists(pid)
+}
+
+// newPlatformServiceController has nothing to return; systemd is handled by internal/service
+func newPlatformServiceController(serviceName string) (ServiceController, bool) {
+	return nil, false
+}
+
 // StopProcess stops a process on Unix-like systems
 func (u *UnixProcessManager) StopProcess(pid int) error {
 	if pid <= 0 {
@@ -100,7 +113,7 @@ func NewUnixServiceManager(serviceName string) *UnixServiceManager {
 }
 
 // InstallService installs the agent as a systemd service
-func (u *UnixServiceManager) InstallService(binaryPath, configPath string) error {
+func (u *UnixServiceManager) InstallService(ctx context.Context, binaryPath, configPath string) error {
 	// Generate systemd service file content
 	serviceContent := u.generateServiceContent(binaryPath, configPath)
 
@@ -119,7 +132,7 @@ func (u *UnixServiceManager) InstallService(binaryPath, configPath string) error
 	}
 
 	// Reload systemd
-	cmd := exec.Command("systemctl", "daemon-reload")
+	cmd := exec.CommandContext(ctx, "systemctl", "daemon-reload")
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to reload systemd: %w", err)
 	}
@@ -128,8 +141,8 @@ func (u *UnixServiceManager) InstallService(binaryPath, configPath string) error
 }
 
 // StartService starts the systemd service
-func (u *UnixServiceManager) StartService() error {
-	cmd := exec.Command("systemctl", "start", u.serviceName)
+func (u *UnixServiceManager) StartService(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "systemctl", "start", u.serviceName)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to start systemd service: %w", err)
 	}
@@ -138,8 +151,8 @@ func (u *UnixServiceManager) StartService() error {
 }
 
 // StopService stops the systemd service
-func (u *UnixServiceManager) StopService() error {
-	cmd := exec.Command("systemctl", "stop", u.serviceName)
+func (u *UnixServiceManager) StopService(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "systemctl", "stop", u.serviceName)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to stop systemd service: %w", err)
 	}
@@ -148,8 +161,8 @@ func (u *UnixServiceManager) StopService() error {
 }
 
 // EnableService enables the systemd service to start on boot
-func (u *UnixServiceManager) EnableService() error {
-	cmd := exec.Command("systemctl", "enable", u.serviceName)
+func (u *UnixServiceManager) EnableService(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "systemctl", "enable", u.serviceName)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to enable systemd service: %w", err)
 	}
@@ -158,8 +171,8 @@ func (u *UnixServiceManager) EnableService() error {
 }
 
 // DisableService disables the systemd service
-func (u *UnixServiceManager) DisableService() error {
-	cmd := exec.Command("systemctl", "disable", u.serviceName)
+func (u *UnixServiceManager) DisableService(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "systemctl", "disable", u.serviceName)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to disable systemd service: %w", err)
 	}
@@ -168,8 +181,8 @@ func (u *UnixServiceManager) DisableService() error {
 }
 
 // GetServiceStatus gets the status of the systemd service
-func (u *UnixServiceManager) GetServiceStatus() (string, error) {
-	cmd := exec.Command("systemctl", "is-active", u.serviceName)
+func (u *UnixServiceManager) GetServiceStatus(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "systemctl", "is-active", u.serviceName)
 	output, err := cmd.Output()
 	if err != nil {
 		// Service is not active
@@ -185,8 +198,8 @@ func (u *UnixServiceManager) GetServiceStatus() (string, error) {
 }
 
 // IsServiceEnabled checks if the systemd service is enabled
-func (u *UnixServiceManager) IsServiceEnabled() (bool, error) {
-	cmd := exec.Command("systemctl", "is-enabled", u.serviceName)
+func (u *UnixServiceManager) IsServiceEnabled(ctx context.Context) (bool, error) {
+	cmd := exec.CommandContext(ctx, "systemctl", "is-enabled", u.serviceName)
 	output, err := cmd.Output()
 	if err != nil {
 		return false, nil
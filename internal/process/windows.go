@@ -4,9 +4,11 @@
 package process
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"syscall"
 )
 
@@ -51,18 +53,30 @@ func (w *WindowsProcessManager) StartProcess(config ProcessConfig) (*ProcessInfo
 		return nil, fmt.Errorf("failed to start process on Windows: %w", err)
 	}
 
-	// Release the process to allow it to continue running independently
+	// Release the process to allow it to continue running independently;
+	// Release resets Pid, so read it first
+	pid := cmd.Process.Pid
 	if err := cmd.Process.Release(); err != nil {
 		return nil, fmt.Errorf("failed to release process on Windows: %w", err)
 	}
 
 	return &ProcessInfo{
-		PID:     cmd.Process.Pid,
+		PID:     pid,
 		Running: true,
 		Error:   nil,
 	}, nil
 }
 
+// processExists checks process existence for BaseProcessManager
+func processExists(pid int) bool {
+	return IsProcessRunningWindows(pid)
+}
+
+// newPlatformServiceController returns the Service Control Manager controller
+func newPlatformServiceController(serviceName string) (ServiceController, bool) {
+	return NewWindowsServiceManager(serviceName), true
+}
+
 // StopProcess stops a process on Windows
 func (w *WindowsProcessManager) StopProcess(pid int) error {
 	if pid <= 0 {
@@ -102,6 +116,10 @@ func GetProcessExitCode(pid int) (uint32, error) {
 	return exitCode, nil
 }
 
+// processQueryLimitedInformation is PROCESS_QUERY_LIMITED_INFORMATION, which
+// unlike PROCESS_QUERY_INFORMATION can be granted for elevated processes
+const processQueryLimitedInformation = 0x1000
+
 // IsProcessRunningWindows provides a more reliable Windows-specific process check
 func IsProcessRunningWindows(pid int) bool {
 	if pid <= 0 {
@@ -109,9 +127,10 @@ func IsProcessRunningWindows(pid int) bool {
 	}
 
 	// Try to open the process
-	handle, err := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	handle, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
 	if err != nil {
-		return false
+		// Access denied means the process exists but belongs to someone else
+		return err == syscall.ERROR_ACCESS_DENIED
 	}
 	defer syscall.CloseHandle(handle)
 
@@ -138,10 +157,12 @@ func NewWindowsServiceManager(serviceName string) *WindowsServiceManager {
 	}
 }
 
-// InstallService installs the agent as a Windows service
-func (w *WindowsServiceManager) InstallService(binaryPath, configPath string) error {
+// InstallService installs the agent as a Windows service. env holds
+// NAME=value pairs for the agent process; the service control manager can't
+// read an environment file, so its variables must already be included.
+func (w *WindowsServiceManager) InstallService(ctx context.Context, binaryPath, configPath string, env []string) error {
 	// Use sc.exe to create the service
-	cmd := exec.Command("sc.exe", "create", w.serviceName,
+	cmd := exec.CommandContext(ctx, "sc.exe", "create", w.serviceName,
 		fmt.Sprintf("binPath=%s --config %s", binaryPath, configPath),
 		"start=auto",
 		"displayname=FixPanic Agent")
@@ -150,12 +171,23 @@ func (w *WindowsServiceManager) InstallService(binaryPath, configPath string) er
 		return fmt.Errorf("failed to create Windows service: %w", err)
 	}
 
+	// The service control manager passes the Environment value of the
+	// service's registry key to the process
+	if len(env) > 0 {
+		key := `HKLM\SYSTEM\CurrentControlSet\Services\` + w.serviceName
+		cmd := exec.CommandContext(ctx, "reg.exe", "add", key, "/v", "Environment", "/t", "REG_MULTI_SZ",
+			"/d", strings.Join(env, `\0`), "/f")
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to set Windows service environment: %w", err)
+		}
+	}
+
 	return nil
 }
 
 // StartService starts the Windows service
-func (w *WindowsServiceManager) StartService() error {
-	cmd := exec.Command("sc.exe", "start", w.serviceName)
+func (w *WindowsServiceManager) StartService(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "sc.exe", "start", w.serviceName)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to start Windows service: %w", err)
 	}
@@ -163,8 +195,8 @@ func (w *WindowsServiceManager) StartService() error {
 }
 
 // StopService stops the Windows service
-func (w *WindowsServiceManager) StopService() error {
-	cmd := exec.Command("sc.exe", "stop", w.serviceName)
+func (w *WindowsServiceManager) StopService(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "sc.exe", "stop", w.serviceName)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to stop Windows service: %w", err)
 	}
@@ -172,8 +204,8 @@ func (w *WindowsServiceManager) StopService() error {
 }
 
 // DeleteService removes the Windows service
-func (w *WindowsServiceManager) DeleteService() error {
-	cmd := exec.Command("sc.exe", "delete", w.serviceName)
+func (w *WindowsServiceManager) DeleteService(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "sc.exe", "delete", w.serviceName)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to delete Windows service: %w", err)
 	}
@@ -181,8 +213,8 @@ func (w *WindowsServiceManager) DeleteService() error {
 }
 
 // GetServiceStatus gets the status of the Windows service
-func (w *WindowsServiceManager) GetServiceStatus() (string, error) {
-	cmd := exec.Command("sc.exe", "query", w.serviceName)
+func (w *WindowsServiceManager) GetServiceStatus(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "sc.exe", "query", w.serviceName)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to query Windows service: %w", err)
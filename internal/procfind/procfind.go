@@ -0,0 +1,100 @@
+// Package procfind enumerates running processes using native operating system
+// interfaces (procfs on Linux, sysctl on macOS and FreeBSD, the Toolhelp API
+// on Windows) and finds processes by the executable they run.
+package procfind
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Process describes a running process
+type Process struct {
+	PID  int
+	PPID int
+	Name string
+	// Executable is the absolute path of the running binary, or empty if the
+	// operating system doesn't expose it to the current user
+	Executable string
+	Args       []string
+}
+
+// Processes returns all processes visible to the current user
+func Processes() ([]Process, error) {
+	return processes()
+}
+
+// FindByExecutable returns the running processes whose executable is path.
+// Matching is done on the resolved binary path rather than the process name,
+// so unrelated processes mentioning the binary in their arguments (editors,
+// shells, grep) never match. The calling process is never included.
+func FindByExecutable(path string) ([]Process, error) {
+	all, err := processes()
+	if err != nil {
+		return nil, err
+	}
+
+	target := normalizePath(path)
+	self := os.Getpid()
+
+	var matches []Process
+	for _, proc := range all {
+		if proc.PID == self {
+			continue
+		}
+		if matchesPath(proc.executablePath(), target) {
+			matches = append(matches, proc)
+		}
+	}
+
+	return matches, nil
+}
+
+// executablePath returns the binary path of the process, falling back to an
+// absolute argv[0] when the executable isn't readable (e.g. processes of
+// other users on Linux)
+func (p Process) executablePath() string {
+	if p.Executable != "" {
+		return p.Executable
+	}
+	if len(p.Args) > 0 && filepath.IsAbs(p.Args[0]) {
+		return p.Args[0]
+	}
+	return ""
+}
+
+// matchesPath reports whether candidate refers to the normalized target path
+func matchesPath(candidate, target string) bool {
+	if candidate == "" {
+		return false
+	}
+	if samePath(candidate, target) {
+		return true
+	}
+	// Only resolve symlinks for plausible candidates; resolving every process is slow
+	if !samePath(filepath.Base(candidate), filepath.Base(target)) {
+		return false
+	}
+	return samePath(normalizePath(candidate), target)
+}
+
+// normalizePath returns an absolute, cleaned path with symlinks resolved where possible
+func normalizePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return filepath.Clean(path)
+}
+
+// samePath compares paths the way the platform's file system does
+func samePath(a, b string) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
@@ -0,0 +1,79 @@
+//go:build darwin
+// +build darwin
+
+package procfind
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// processes enumerates processes through the kern.proc.all sysctl
+func processes() ([]Process, error) {
+	kprocs, err := unix.SysctlKinfoProcSlice("kern.proc.all")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	procs := make([]Process, 0, len(kprocs))
+	for _, kproc := range kprocs {
+		pid := int(kproc.Proc.P_pid)
+		if pid <= 0 {
+			continue
+		}
+
+		proc := Process{
+			PID:  pid,
+			PPID: int(kproc.Eproc.Ppid),
+			Name: unix.ByteSliceToString(kproc.Proc.P_comm[:]),
+		}
+		// kern.procargs2 is only readable for our own processes unless we're root
+		if exe, args, err := procArgs(pid); err == nil {
+			proc.Executable = exe
+			proc.Args = args
+		}
+		procs = append(procs, proc)
+	}
+
+	return procs, nil
+}
+
+// procArgs reads the executable path and arguments of a process from the
+// kern.procargs2 sysctl. Layout: argc (int32), exec path, NUL padding, argv...
+func procArgs(pid int) (string, []string, error) {
+	buf, err := unix.SysctlRaw("kern.procargs2", pid)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(buf) < 4 {
+		return "", nil, fmt.Errorf("short procargs2 buffer for PID %d", pid)
+	}
+
+	argc := int(binary.NativeEndian.Uint32(buf[:4]))
+	rest := buf[4:]
+
+	end := bytes.IndexByte(rest, 0)
+	if end < 0 {
+		return "", nil, fmt.Errorf("malformed procargs2 buffer for PID %d", pid)
+	}
+	exe := string(rest[:end])
+	rest = bytes.TrimLeft(rest[end:], "\x00")
+
+	args := make([]string, 0, argc)
+	for len(args) < argc && len(rest) > 0 {
+		end = bytes.IndexByte(rest, 0)
+		if end < 0 {
+			end = len(rest)
+		}
+		args = append(args, string(rest[:end]))
+		if end == len(rest) {
+			break
+		}
+		rest = rest[end+1:]
+	}
+
+	return exe, args, nil
+}
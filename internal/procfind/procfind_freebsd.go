@@ -0,0 +1,54 @@
+//go:build freebsd
+// +build freebsd
+
+package procfind
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// kinfo_proc starts with two ints followed by eight kernel pointers, then
+// ki_pid and ki_ppid; each record carries its own size in ki_structsize
+var kinfoPIDOffset = 8 + 8*int(unsafe.Sizeof(uintptr(0)))
+
+// processes enumerates processes through the kern.proc.proc sysctl
+func processes() ([]Process, error) {
+	buf, err := unix.SysctlRaw("kern.proc.proc")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	var procs []Process
+	for len(buf) >= kinfoPIDOffset+8 {
+		size := int(binary.NativeEndian.Uint32(buf[:4]))
+		if size <= 0 || size > len(buf) {
+			break
+		}
+
+		pid := int(int32(binary.NativeEndian.Uint32(buf[kinfoPIDOffset:])))
+		ppid := int(int32(binary.NativeEndian.Uint32(buf[kinfoPIDOffset+4:])))
+		buf = buf[size:]
+		if pid <= 0 {
+			continue
+		}
+
+		proc := Process{PID: pid, PPID: ppid}
+		if path, err := unix.SysctlRaw("kern.proc.pathname", pid); err == nil {
+			proc.Executable = string(bytes.TrimRight(path, "\x00"))
+			proc.Name = filepath.Base(proc.Executable)
+		}
+		if args, err := unix.SysctlRaw("kern.proc.args", pid); err == nil && len(args) > 0 {
+			proc.Args = strings.Split(strings.TrimRight(string(args), "\x00"), "\x00")
+		}
+		procs = append(procs, proc)
+	}
+
+	return procs, nil
+}
@@ -0,0 +1,75 @@
+//go:build linux
+// +build linux
+
+package procfind
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procRoot is the mount point of procfs
+const procRoot = "/proc"
+
+// processes enumerates /proc
+func processes() ([]Process, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", procRoot, err)
+	}
+
+	var procs []Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		// Processes may exit while we walk /proc; skip those that vanished
+		if proc, ok := readProcess(pid); ok {
+			procs = append(procs, proc)
+		}
+	}
+
+	return procs, nil
+}
+
+// readProcess reads a single process from /proc/<pid>. Zombies are skipped
+// since they are no longer running.
+func readProcess(pid int) (Process, bool) {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return Process{}, false
+	}
+
+	// Format: pid (comm) state ppid ...; comm may itself contain spaces and parentheses
+	content := string(stat)
+	open := strings.IndexByte(content, '(')
+	closing := strings.LastIndexByte(content, ')')
+	if open < 0 || closing < open {
+		return Process{}, false
+	}
+	fields := strings.Fields(content[closing+1:])
+	if len(fields) < 2 || fields[0] == "Z" {
+		return Process{}, false
+	}
+
+	proc := Process{PID: pid, Name: content[open+1 : closing]}
+	proc.PPID, _ = strconv.Atoi(fields[1])
+
+	// The link target gets a " (deleted)" suffix once the binary is replaced,
+	// e.g. an old agent still running after an upgrade
+	if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		proc.Executable = strings.TrimSuffix(exe, " (deleted)")
+	}
+
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil && len(cmdline) > 0 {
+		proc.Args = strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+	}
+
+	return proc, true
+}
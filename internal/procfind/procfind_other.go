@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package procfind
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// processes is not implemented on this platform
+func processes() ([]Process, error) {
+	return nil, fmt.Errorf("process discovery is not supported on %s", runtime.GOOS)
+}
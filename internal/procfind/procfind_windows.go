@@ -0,0 +1,62 @@
+//go:build windows
+// +build windows
+
+package procfind
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// processes enumerates processes through a Toolhelp snapshot
+func processes() ([]Process, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot processes: %w", err)
+	}
+	defer windows.CloseHandle(snapshot)
+
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+
+	var procs []Process
+	err = windows.Process32First(snapshot, &entry)
+	for err == nil {
+		pid := int(entry.ProcessID)
+		if pid > 0 {
+			name := windows.UTF16ToString(entry.ExeFile[:])
+			procs = append(procs, Process{
+				PID:        pid,
+				PPID:       int(entry.ParentProcessID),
+				Name:       name,
+				Executable: imagePath(entry.ProcessID),
+			})
+		}
+		err = windows.Process32Next(snapshot, &entry)
+	}
+	if !errors.Is(err, windows.ERROR_NO_MORE_FILES) {
+		return nil, fmt.Errorf("failed to enumerate processes: %w", err)
+	}
+
+	return procs, nil
+}
+
+// imagePath returns the full executable path of a process, or empty if the
+// process can't be opened (e.g. protected system processes)
+func imagePath(pid uint32) string {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseHandle(handle)
+
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(handle, 0, &buf[0], &size); err != nil {
+		return ""
+	}
+	return windows.UTF16ToString(buf[:size])
+}
@@ -0,0 +1,85 @@
+// Package procstat samples per-process resource usage (CPU time, resident
+// memory and established TCP connections) from the operating system.
+package procstat
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/procfind"
+)
+
+// ErrUnsupported is returned where resource sampling isn't implemented
+var ErrUnsupported = errors.New("process statistics are not supported on this platform")
+
+// Stat is a point-in-time resource sample of a single process
+type Stat struct {
+	PID int
+	// CPUTime is the user plus system time consumed since the process started
+	CPUTime time.Duration
+	// RSS is the resident memory in bytes
+	RSS uint64
+	// Connections is the number of established TCP connections, or -1 if
+	// the sockets of the process aren't readable by the current user
+	Connections int
+	Sampled     time.Time
+}
+
+// Read samples the resource usage of a process
+func Read(pid int) (Stat, error) {
+	stat, err := read(pid)
+	if err != nil {
+		return Stat{}, err
+	}
+	stat.PID = pid
+	stat.Sampled = time.Now()
+	return stat, nil
+}
+
+// CPUPercent returns the CPU usage between two samples of the same process,
+// where 100 means one fully used core
+func CPUPercent(prev, cur Stat) float64 {
+	wall := cur.Sampled.Sub(prev.Sampled)
+	if wall <= 0 || cur.CPUTime < prev.CPUTime {
+		return 0
+	}
+	return float64(cur.CPUTime-prev.CPUTime) / float64(wall) * 100
+}
+
+// Node is a process with its depth below the root of a tree
+type Node struct {
+	procfind.Process
+	Depth int
+}
+
+// Tree returns root and all its descendants in depth-first order, children
+// sorted by PID. It returns nil if root isn't among procs.
+func Tree(procs []procfind.Process, root int) []Node {
+	children := make(map[int][]procfind.Process)
+	var rootProc *procfind.Process
+	for i, proc := range procs {
+		if proc.PID == root {
+			rootProc = &procs[i]
+			continue
+		}
+		children[proc.PPID] = append(children[proc.PPID], proc)
+	}
+	if rootProc == nil {
+		return nil
+	}
+
+	var nodes []Node
+	var walk func(proc procfind.Process, depth int)
+	walk = func(proc procfind.Process, depth int) {
+		nodes = append(nodes, Node{Process: proc, Depth: depth})
+		kids := children[proc.PID]
+		sort.Slice(kids, func(i, j int) bool { return kids[i].PID < kids[j].PID })
+		for _, kid := range kids {
+			walk(kid, depth+1)
+		}
+	}
+	walk(*rootProc, 0)
+
+	return nodes
+}
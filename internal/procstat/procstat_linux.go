@@ -0,0 +1,125 @@
+//go:build linux
+// +build linux
+
+package procstat
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// procRoot is the mount point of procfs
+const procRoot = "/proc"
+
+// clockTicks is USER_HZ, the unit of the CPU times in /proc/<pid>/stat. It is
+// 100 on every architecture Linux supports and can't be read without cgo.
+const clockTicks = 100
+
+// tcpEstablished is the state code of an established socket in /proc/net/tcp
+const tcpEstablished = "01"
+
+// read samples a process from /proc/<pid>
+func read(pid int) (Stat, error) {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+
+	cpu, err := readCPUTime(dir)
+	if err != nil {
+		return Stat{}, err
+	}
+	stat := Stat{CPUTime: cpu, Connections: -1}
+
+	if statm, err := os.ReadFile(filepath.Join(dir, "statm")); err == nil {
+		if fields := strings.Fields(string(statm)); len(fields) >= 2 {
+			pages, _ := strconv.ParseUint(fields[1], 10, 64)
+			stat.RSS = pages * uint64(os.Getpagesize())
+		}
+	}
+
+	if inodes, err := socketInodes(dir); err == nil {
+		stat.Connections = countEstablished(dir, inodes)
+	}
+
+	return stat, nil
+}
+
+// readCPUTime returns utime+stime from /proc/<pid>/stat
+func readCPUTime(dir string) (time.Duration, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read process stats: %w", err)
+	}
+
+	// Fields after "(comm)" start at field 3 (state); utime and stime are 14 and 15
+	content := string(data)
+	closing := strings.LastIndexByte(content, ')')
+	if closing < 0 {
+		return 0, fmt.Errorf("malformed %s", filepath.Join(dir, "stat"))
+	}
+	fields := strings.Fields(content[closing+1:])
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("malformed %s", filepath.Join(dir, "stat"))
+	}
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+
+	return time.Duration(utime+stime) * time.Second / clockTicks, nil
+}
+
+// socketInodes returns the inodes of the sockets a process has open. Reading
+// the fd directory of another user's process requires root.
+func socketInodes(dir string) (map[string]bool, error) {
+	fdDir := filepath.Join(dir, "fd")
+	entries, err := os.ReadDir(fdDir)
+	if err != nil {
+		return nil, err
+	}
+
+	inodes := make(map[string]bool)
+	for _, entry := range entries {
+		target, err := os.Readlink(filepath.Join(fdDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		// Link targets look like "socket:[12345]"
+		if strings.HasPrefix(target, "socket:[") && strings.HasSuffix(target, "]") {
+			inodes[target[len("socket:["):len(target)-1]] = true
+		}
+	}
+	return inodes, nil
+}
+
+// countEstablished counts the established TCP sockets among inodes. The
+// per-process net tables are used so processes in another network namespace
+// (e.g. containers) are counted correctly.
+func countEstablished(dir string, inodes map[string]bool) int {
+	if len(inodes) == 0 {
+		return 0
+	}
+
+	count := 0
+	for _, table := range []string{"tcp", "tcp6"} {
+		file, err := os.Open(filepath.Join(dir, "net", table))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Scan() // header
+		for scanner.Scan() {
+			// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 10 {
+				continue
+			}
+			if fields[3] == tcpEstablished && inodes[fields[9]] {
+				count++
+			}
+		}
+		file.Close()
+	}
+	return count
+}
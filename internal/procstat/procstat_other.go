@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package procstat
+
+// read is not implemented on this platform
+func read(pid int) (Stat, error) {
+	return Stat{}, ErrUnsupported
+}
@@ -0,0 +1,252 @@
+package releases
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+	"github.com/fixpanic/fixpanic-cli/internal/httpcache"
+)
+
+// Names of the checksum manifest published with every agent release and of
+// its detached signature
+const (
+	ChecksumManifestName = "SHA256SUMS"
+	SignatureName        = "SHA256SUMS.sig"
+)
+
+// signingKeys are the Ed25519 public keys (base64, comma separated) release
+// manifests are signed with. The release build sets them with
+//
+//	-ldflags "-X github.com/fixpanic/fixpanic-cli/internal/releases.signingKeys=<key>"
+//
+// and fails without them (see 'make check-signing-key').
+var signingKeys string
+
+// SigningKeys are the Ed25519 public keys (base64) release manifests are
+// signed with. A manifest signed by any of them is trusted, so a new key can
+// be shipped before the release pipeline switches to it. A build without
+// them can't verify, and so can't install, any release.
+var SigningKeys = splitKeys(signingKeys)
+
+// splitKeys returns the comma separated keys of list
+func splitKeys(list string) []string {
+	var keys []string
+	for _, key := range strings.Split(list, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// ErrNoSigningKeys is returned by VerifyChecksumManifest by a CLI built
+// without release signing keys
+var ErrNoSigningKeys = errors.New("no release signing key is embedded in this CLI")
+
+// ErrNotPublished is returned for a release asset that doesn't exist
+var ErrNotPublished = errors.New("not published")
+
+// ErrNotListed is returned by FetchVerifiedAsset for an asset the release's
+// checksum manifest doesn't list
+var ErrNotListed = errors.New("not listed in the checksum manifest")
+
+// tagPattern matches release tags: vMAJOR.MINOR.PATCH with an optional
+// pre-release or build suffix
+var tagPattern = regexp.MustCompile(`^v\d+\.\d+\.\d+([-+][0-9A-Za-z.-]+)?$`)
+
+// ParseTag returns the release tag of version, adding the "v" prefix if it
+// is missing, or an error if version isn't a release version like v1.4.0
+func ParseTag(version string) (string, error) {
+	tag := strings.TrimSpace(version)
+	if !strings.HasPrefix(tag, "v") {
+		tag = "v" + tag
+	}
+	if !tagPattern.MatchString(tag) {
+		return "", fmt.Errorf("%q is not a release version like v1.4.0", version)
+	}
+	return tag, nil
+}
+
+// ChecksumManifest maps the asset names of a release to their SHA-256
+// checksums, as published in its SHA256SUMS file
+type ChecksumManifest struct {
+	Version   string
+	Checksums map[string]string
+}
+
+// Checksum returns the checksum of the asset called name, or an empty string
+// if the manifest doesn't list it
+func (m *ChecksumManifest) Checksum(name string) string {
+	return m.Checksums[name]
+}
+
+// DownloadURL returns where the asset name of the release tagged tag of repo
+// is downloaded from
+func DownloadURL(repo, tag, name string) string {
+	return fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", repo, url.PathEscape(tag), name)
+}
+
+// FetchChecksumManifest downloads the checksum manifest of the agent release
+// tagged tag and its signature, and returns the manifest once the signature
+// verifies against one of the SigningKeys. The manifest is trusted through
+// the signature alone, not the connection it was downloaded over. A release
+// without a manifest or signature is refused with the integrity exit code.
+func FetchChecksumManifest(ctx context.Context, cache *httpcache.Cache, tag string) (*ChecksumManifest, error) {
+	if err := RequireSigningKeys(tag); err != nil {
+		return nil, err
+	}
+	data, err := fetchAsset(ctx, cache, DownloadURL(AgentRepo, tag, ChecksumManifestName))
+	if err != nil {
+		return nil, MissingFileError(fmt.Errorf("failed to fetch the checksum manifest of %s: %w", tag, err))
+	}
+	signature, err := fetchAsset(ctx, cache, DownloadURL(AgentRepo, tag, SignatureName))
+	if err != nil {
+		return nil, MissingFileError(fmt.Errorf("failed to fetch the manifest signature of %s: %w", tag, err))
+	}
+
+	return VerifyChecksumManifest(tag, data, signature)
+}
+
+// FetchVerifiedAsset downloads the asset name of the agent release tagged
+// tag and returns it once its checksum matches the one the release's signed
+// checksum manifest lists for it. An asset the manifest doesn't list is
+// refused.
+func FetchVerifiedAsset(ctx context.Context, cache *httpcache.Cache, tag, name string) ([]byte, error) {
+	manifest, err := FetchChecksumManifest(ctx, cache, tag)
+	if err != nil {
+		return nil, err
+	}
+	expected := manifest.Checksum(name)
+	if expected == "" {
+		return nil, clierror.Wrap(clierror.Integrity, fmt.Errorf("%s of %s: %w", name, tag, ErrNotListed))
+	}
+	data, err := fetchAsset(ctx, cache, DownloadURL(AgentRepo, tag, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s of %s: %w", name, tag, err)
+	}
+	sum := sha256.Sum256(data)
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return nil, clierror.Wrap(clierror.Integrity, fmt.Errorf("checksum mismatch for %s of %s: expected %s, got %s", name, tag, expected, actual))
+	}
+	return data, nil
+}
+
+// VerifyChecksumManifest returns the checksum manifest data of the release
+// tagged tag once signature verifies against one of the SigningKeys. Its
+// errors carry the integrity exit code.
+func VerifyChecksumManifest(tag string, data, signature []byte) (*ChecksumManifest, error) {
+	if err := RequireSigningKeys(tag); err != nil {
+		return nil, err
+	}
+	if err := VerifyManifestSignature(data, signature, SigningKeys); err != nil {
+		return nil, clierror.Wrap(clierror.Integrity, fmt.Errorf("checksum manifest of %s: %w", tag, err))
+	}
+	checksums, err := ParseChecksums(data)
+	if err != nil {
+		return nil, clierror.Wrap(clierror.Integrity, fmt.Errorf("checksum manifest of %s: %w", tag, err))
+	}
+	return &ChecksumManifest{Version: tag, Checksums: checksums}, nil
+}
+
+// MissingFileError classifies err, a failed download of a release's checksum
+// manifest or its signature, with the integrity exit code if the release
+// doesn't publish the file: its absence is a failed verification, not a
+// network error
+func MissingFileError(err error) error {
+	if errors.Is(err, ErrNotPublished) {
+		return clierror.Wrap(clierror.Integrity, err)
+	}
+	return err
+}
+
+// RequireSigningKeys fails with the integrity exit code, before anything of
+// the release tagged tag is downloaded, if the CLI was built without
+// SigningKeys
+func RequireSigningKeys(tag string) error {
+	if len(SigningKeys) > 0 {
+		return nil
+	}
+	return clierror.WithHint(clierror.Wrap(clierror.Integrity, fmt.Errorf("checksum manifest of %s: %w", tag, ErrNoSigningKeys)),
+		"This fixpanic binary was built without RELEASE_SIGNING_KEY; use an official release of the CLI")
+}
+
+// VerifyManifestSignature checks that signature (base64) is an Ed25519
+// signature of data by one of keys (base64)
+func VerifyManifestSignature(data, signature []byte, keys []string) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("signature is not a base64 Ed25519 signature")
+	}
+	for _, key := range keys {
+		public, err := base64.StdEncoding.DecodeString(key)
+		if err != nil || len(public) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid signing key %q", key)
+		}
+		if ed25519.Verify(ed25519.PublicKey(public), data, sig) {
+			return nil
+		}
+	}
+	return fmt.Errorf("signature does not match any trusted signing key")
+}
+
+// ParseChecksums parses sha256sum output: one "<hex checksum>  <name>" line
+// per file, with a "*" before binary-mode names
+func ParseChecksums(data []byte) (map[string]string, error) {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		checksum, name, ok := strings.Cut(text, " ")
+		name = strings.TrimPrefix(strings.TrimSpace(name), "*")
+		if decoded, err := hex.DecodeString(checksum); !ok || name == "" || err != nil || len(decoded) != 32 {
+			return nil, fmt.Errorf("line %d is not a SHA-256 checksum line", line)
+		}
+		checksums[name] = strings.ToLower(checksum)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(checksums) == 0 {
+		return nil, fmt.Errorf("no checksums listed")
+	}
+	return checksums, nil
+}
+
+// maxAssetSize is the size up to which fetchAsset reads an asset; SBOMs
+// are the largest assets it fetches
+const maxAssetSize = 16 << 20
+
+// fetchAsset downloads a small release asset through the download cache
+func fetchAsset(ctx context.Context, cache *httpcache.Cache, rawURL string) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := cache.Get(ctx, client, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s is %w", rawURL, ErrNotPublished)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxAssetSize))
+}
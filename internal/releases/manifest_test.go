@@ -0,0 +1,233 @@
+package releases
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{version: "v1.4.0", want: "v1.4.0"},
+		{version: "1.4.0", want: "v1.4.0"},
+		{version: " v1.4.0 ", want: "v1.4.0"},
+		{version: "v1.5.0-rc.1", want: "v1.5.0-rc.1"},
+		{version: "v1.4", wantErr: true},
+		{version: "latest", wantErr: true},
+		{version: "v1.4.0/../../x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := ParseTag(tt.version)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseTag(%q) = %q, want an error", tt.version, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseTag(%q) = %q, %v, want %q", tt.version, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "text and binary mode",
+			data: sum + "  fixpanic-agent-linux-amd64\n" + strings.ToUpper(sum) + " *fixpanic-agent-darwin-arm64\n\n",
+			want: map[string]string{"fixpanic-agent-linux-amd64": sum, "fixpanic-agent-darwin-arm64": sum},
+		},
+		{name: "short checksum", data: "abcd  fixpanic-agent\n", wantErr: true},
+		{name: "missing name", data: sum + "\n", wantErr: true},
+		{name: "empty", data: "\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseChecksums([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseChecksums() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseChecksums() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseChecksums() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyChecksumManifestWithoutKeys(t *testing.T) {
+	withSigningKeys(t)
+
+	data := []byte(strings.Repeat("ab", 32) + "  fixpanic-agent-linux-amd64\n")
+	if _, err := VerifyChecksumManifest("v1.4.0", data, []byte("c2lnbmF0dXJl")); !errors.Is(err, ErrNoSigningKeys) {
+		t.Errorf("VerifyChecksumManifest() error = %v, want ErrNoSigningKeys", err)
+	}
+}
+
+// withSigningKeys sets SigningKeys for the duration of the test
+func withSigningKeys(t *testing.T, keys ...string) {
+	saved := SigningKeys
+	SigningKeys = keys
+	t.Cleanup(func() { SigningKeys = saved })
+}
+
+func TestVerifyChecksumManifest(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withSigningKeys(t, base64.StdEncoding.EncodeToString(public))
+
+	sum := strings.Repeat("ab", 32)
+	data := []byte(sum + "  fixpanic-agent-linux-amd64\n")
+	sign := func(key ed25519.PrivateKey, data []byte) []byte {
+		return []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)) + "\n")
+	}
+
+	manifest, err := VerifyChecksumManifest("v1.4.0", data, sign(private, data))
+	if err != nil {
+		t.Fatalf("VerifyChecksumManifest() error = %v", err)
+	}
+	if manifest.Checksum("fixpanic-agent-linux-amd64") != sum {
+		t.Errorf("VerifyChecksumManifest() = %+v, want a signed manifest listing the binary", manifest)
+	}
+
+	tampered := []byte(strings.Repeat("cd", 32) + "  fixpanic-agent-linux-amd64\n")
+	tests := []struct {
+		name      string
+		data      []byte
+		signature []byte
+	}{
+		{"tampered manifest", tampered, sign(private, data)},
+		{"signed by another key", data, sign(otherPrivate, data)},
+		{"not a signature", data, []byte("c2lnbmF0dXJl")},
+		{"empty signature", data, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if manifest, err := VerifyChecksumManifest("v1.4.0", tt.data, tt.signature); err == nil {
+				t.Errorf("VerifyChecksumManifest() = %+v, want an error", manifest)
+			}
+		})
+	}
+}
+
+func TestSplitKeys(t *testing.T) {
+	if got := splitKeys(""); len(got) != 0 {
+		t.Errorf("splitKeys(\"\") = %q, want no keys", got)
+	}
+	if got, want := splitKeys("a2V5MQ==, a2V5Mg==,"), []string{"a2V5MQ==", "a2V5Mg=="}; !reflect.DeepEqual(got, want) {
+		t.Errorf("splitKeys() = %q, want %q", got, want)
+	}
+}
+
+// releaseServer answers release downloads from files, by asset name, and
+// with 404 for any other asset, in place of GitHub for the duration of the
+// test
+func releaseServer(t *testing.T, files map[string][]byte) {
+	saved := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		data, ok := files[path.Base(req.URL.Path)]
+		if !ok {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(data)), Request: req}, nil
+	})
+	t.Cleanup(func() { http.DefaultTransport = saved })
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestFetchVerifiedAssetFailsClosed(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asset := []byte(`{"spdxVersion": "SPDX-2.3"}`)
+	sum := sha256.Sum256(asset)
+	manifest := []byte(hex.EncodeToString(sum[:]) + "  sbom.spdx.json\n")
+	signature := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, manifest)))
+
+	tests := []struct {
+		name    string
+		keys    []string
+		files   map[string][]byte
+		wantErr error
+	}{
+		{
+			name:    "missing manifest",
+			keys:    []string{base64.StdEncoding.EncodeToString(public)},
+			files:   map[string][]byte{"sbom.spdx.json": asset},
+			wantErr: ErrNotPublished,
+		},
+		{
+			name:    "missing signature",
+			keys:    []string{base64.StdEncoding.EncodeToString(public)},
+			files:   map[string][]byte{"sbom.spdx.json": asset, ChecksumManifestName: manifest},
+			wantErr: ErrNotPublished,
+		},
+		{
+			name:    "no signing key built in",
+			files:   map[string][]byte{"sbom.spdx.json": asset, ChecksumManifestName: manifest, SignatureName: signature},
+			wantErr: ErrNoSigningKeys,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withSigningKeys(t, tt.keys...)
+			releaseServer(t, tt.files)
+
+			data, err := FetchVerifiedAsset(context.Background(), nil, "v1.4.0", "sbom.spdx.json")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FetchVerifiedAsset() = %q, %v, want %v", data, err, tt.wantErr)
+			}
+			if code := clierror.CodeOf(err); code != clierror.Integrity {
+				t.Errorf("exit code = %v, want %v", code, clierror.Integrity)
+			}
+		})
+	}
+
+	withSigningKeys(t, base64.StdEncoding.EncodeToString(public))
+	releaseServer(t, map[string][]byte{"sbom.spdx.json": asset, ChecksumManifestName: manifest, SignatureName: signature})
+	if data, err := FetchVerifiedAsset(context.Background(), nil, "v1.4.0", "sbom.spdx.json"); err != nil || !bytes.Equal(data, asset) {
+		t.Errorf("FetchVerifiedAsset() of a signed release = %q, %v, want the asset", data, err)
+	}
+}
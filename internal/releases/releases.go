@@ -0,0 +1,166 @@
+// Package releases lists the GitHub releases of the CLI and the agent, so
+// operators can pick a version to pin without leaving the terminal.
+package releases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/httpcache"
+)
+
+// Repositories whose releases are listed
+const (
+	CLIRepo   = "fixpanic/fixpanic-cli-tool"
+	AgentRepo = "fixpanic/fixpanic-connectivity-layer-release"
+)
+
+// DefaultPerPage is the number of releases fetched per page
+const DefaultPerPage = 20
+
+// apiBase is the GitHub API endpoint of a repository's releases
+const apiBase = "https://api.github.com/repos/%s/releases"
+
+// Release is one published release
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	PublishedAt time.Time `json:"published_at"`
+	Prerelease  bool      `json:"prerelease"`
+	HTMLURL     string    `json:"html_url"`
+	Body        string    `json:"body"`
+}
+
+// Page is one page of releases, newest first
+type Page struct {
+	Releases []Release
+	// More is set when GitHub has further pages
+	More bool
+}
+
+// List fetches page (starting at 1) of the releases of repo, perPage at a
+// time. Responses are revalidated through the download cache, which a nil
+// cache skips.
+func List(ctx context.Context, cache *httpcache.Cache, repo string, page, perPage int) (*Page, error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = DefaultPerPage
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	url := fmt.Sprintf(apiBase+"?per_page=%d&page=%d", repo, perPage, page)
+	resp, err := cache.Get(ctx, client, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch releases: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("GitHub API request failed: %d", resp.StatusCode)
+	}
+
+	// Read the whole body so the response is cached for the next listing
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch releases: %w", err)
+	}
+	result := &Page{More: strings.Contains(resp.Header.Get("Link"), `rel="next"`)}
+	if err := json.Unmarshal(body, &result.Releases); err != nil {
+		return nil, fmt.Errorf("failed to parse releases: %w", err)
+	}
+
+	return result, nil
+}
+
+// Yanked reports whether the release was pulled, and why. A release is
+// marked as yanked by "[yanked]" in its title or a "Yanked: <reason>" line
+// in its notes (markdown emphasis and quoting are ignored).
+func (r *Release) Yanked() (reason string, yanked bool) {
+	for _, line := range strings.Split(r.Body, "\n") {
+		line = strings.TrimLeft(strings.TrimSpace(line), "> *_")
+		if len(line) < 6 || !strings.EqualFold(line[:6], "yanked") {
+			continue
+		}
+		rest := strings.TrimLeft(line[6:], "*_ ")
+		if rest, ok := strings.CutPrefix(rest, ":"); ok {
+			return strings.TrimSpace(strings.Trim(rest, "*_ ")), true
+		}
+	}
+	if strings.Contains(strings.ToLower(r.Name), "[yanked]") {
+		return "", true
+	}
+	return "", false
+}
+
+// Summary returns the first line of prose in the release notes, shortened
+// to at most width characters
+func (r *Release) Summary(width int) string {
+	for _, line := range strings.Split(r.Body, "\n") {
+		line = strings.TrimSpace(line)
+		// Skip headings, the yank marker and markdown decoration
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "---") {
+			continue
+		}
+		if _, yanked := (&Release{Body: line}).Yanked(); yanked {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimLeft(line, "-*> "))
+		if line == "" {
+			continue
+		}
+		if runes := []rune(line); len(runes) > width {
+			return string(runes[:width-1]) + "…"
+		}
+		return line
+	}
+	return ""
+}
+
+// maxNotesPages bounds how far back Between looks for the installed release
+const maxNotesPages = 5
+
+// Between returns the releases after from up to and including to, newest
+// first, so their notes describe everything an upgrade from from to to
+// brings. Pre-releases are skipped unless to is one. If from isn't found
+// among the recent releases (e.g. a development build), only the release to
+// is returned.
+func Between(ctx context.Context, cache *httpcache.Cache, repo, from, to string) ([]Release, error) {
+	var between []Release
+	for page := 1; page <= maxNotesPages; page++ {
+		result, err := List(ctx, cache, repo, page, 100)
+		if err != nil {
+			return nil, err
+		}
+		for _, release := range result.Releases {
+			switch {
+			case sameVersion(release.TagName, from):
+				return between, nil
+			case sameVersion(release.TagName, to):
+				between = append(between, release)
+			case len(between) > 0 && !release.Prerelease:
+				between = append(between, release)
+			}
+		}
+		if !result.More {
+			break
+		}
+	}
+
+	if len(between) == 0 {
+		return nil, fmt.Errorf("release %s not found", to)
+	}
+	return between[:1], nil
+}
+
+// sameVersion reports whether two tags name the same version, with or
+// without the "v" prefix
+func sameVersion(a, b string) bool {
+	return strings.TrimPrefix(strings.TrimSpace(a), "v") == strings.TrimPrefix(strings.TrimSpace(b), "v")
+}
@@ -0,0 +1,115 @@
+package releases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/httpcache"
+)
+
+// BlocklistURL is where versions that must not be installed are published,
+// for releases that were pulled after the fact or can't be edited
+const BlocklistURL = "https://raw.githubusercontent.com/fixpanic/fixpanic-cli-tool/main/blocklist.json"
+
+// Blocklist lists the blocked versions of each repository, e.g.
+//
+//	{"fixpanic/fixpanic-connectivity-layer-release": [{"version": "v1.2.4", "reason": "corrupts the configuration on arm64"}]}
+type Blocklist map[string][]BlockedVersion
+
+// BlockedVersion is one entry of the blocklist
+type BlockedVersion struct {
+	Version string `json:"version"`
+	Reason  string `json:"reason"`
+}
+
+// FetchBlocklist downloads the published blocklist. A missing blocklist is
+// an empty one.
+func FetchBlocklist(ctx context.Context, cache *httpcache.Cache) (Blocklist, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := cache.Get(ctx, client, BlocklistURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch blocklist: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return Blocklist{}, nil
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("blocklist request failed: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch blocklist: %w", err)
+	}
+	var blocklist Blocklist
+	if err := json.Unmarshal(body, &blocklist); err != nil {
+		return nil, fmt.Errorf("failed to parse blocklist: %w", err)
+	}
+	return blocklist, nil
+}
+
+// Blocked reports whether version of repo is on the blocklist, and why
+func (b Blocklist) Blocked(repo, version string) (reason string, blocked bool) {
+	for _, entry := range b[repo] {
+		if sameVersion(entry.Version, version) {
+			return entry.Reason, true
+		}
+	}
+	return "", false
+}
+
+// Get fetches the release of repo tagged tag
+func Get(ctx context.Context, cache *httpcache.Cache, repo, tag string) (*Release, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := cache.Get(ctx, client, fmt.Sprintf(apiBase, repo)+"/tags/"+url.PathEscape(tag))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release %s: %w", tag, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("release %s not found", tag)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("GitHub API request failed: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release %s: %w", tag, err)
+	}
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("failed to parse release %s: %w", tag, err)
+	}
+	return &release, nil
+}
+
+// CheckYanked reports whether version of repo was pulled, and why, according
+// to the blocklist or the marker in the release itself (see Release.Yanked).
+// An error means one of them could not be checked, so a yank may be missed.
+func CheckYanked(ctx context.Context, cache *httpcache.Cache, repo, version string) (reason string, yanked bool, err error) {
+	blocklist, blocklistErr := FetchBlocklist(ctx, cache)
+	if blocklistErr == nil {
+		if reason, blocked := blocklist.Blocked(repo, version); blocked {
+			return reason, true, nil
+		}
+	}
+
+	release, releaseErr := Get(ctx, cache, repo, version)
+	if releaseErr == nil {
+		if reason, yanked := release.Yanked(); yanked {
+			return reason, true, nil
+		}
+	}
+
+	return "", false, errors.Join(blocklistErr, releaseErr)
+}
@@ -0,0 +1,129 @@
+// Package rollback records the changes a multi-step operation makes so that
+// a failed operation can be undone instead of leaving a half-configured host
+package rollback
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Journal records how to undo changes in the order they were made. The zero
+// value is an empty journal.
+type Journal struct {
+	undo []func() error
+	// backups are the saved copies of tracked files, removed by Commit
+	backups []string
+}
+
+// Undo registers fn to undo a change. Rollback runs it before the undo
+// functions of earlier changes.
+func (j *Journal) Undo(fn func() error) {
+	j.undo = append(j.undo, fn)
+}
+
+// TrackDir records that dir is about to be created. Rollback removes the
+// outermost of its directories that doesn't exist yet, with everything in it.
+func (j *Journal) TrackDir(dir string) {
+	created := ""
+	for path := filepath.Clean(dir); !exists(path); path = filepath.Dir(path) {
+		created = path
+		if filepath.Dir(path) == path {
+			break
+		}
+	}
+	if created == "" {
+		return
+	}
+	j.Undo(func() error {
+		return os.RemoveAll(created)
+	})
+}
+
+// TrackFile records that path is about to be written. Rollback restores its
+// current content and mode, or removes it if it doesn't exist yet.
+func (j *Journal) TrackFile(path string) error {
+	if !exists(path) {
+		j.Undo(func() error {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		})
+		return nil
+	}
+
+	backup, err := saveCopy(path)
+	if err != nil {
+		return fmt.Errorf("failed to save a copy of %s: %w", path, err)
+	}
+	j.backups = append(j.backups, backup)
+	j.Undo(func() error {
+		return os.Rename(backup, path)
+	})
+	return nil
+}
+
+// Rollback undoes the recorded changes, most recent first. It carries on past
+// changes it can't undo and returns their errors.
+func (j *Journal) Rollback() error {
+	var errs []error
+	for i := len(j.undo) - 1; i >= 0; i-- {
+		if err := j.undo[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	j.undo = nil
+	j.Commit()
+	return errors.Join(errs...)
+}
+
+// Commit keeps the changes, removing the saved copies of tracked files. It is
+// a no-op after Rollback.
+func (j *Journal) Commit() {
+	for _, backup := range j.backups {
+		os.Remove(backup)
+	}
+	j.undo, j.backups = nil, nil
+}
+
+// saveCopy copies path to a hidden file next to it, keeping its mode
+func saveCopy(path string) (string, error) {
+	source, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+	info, err := source.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	backup, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".rollback-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(backup, source); err != nil {
+		backup.Close()
+		os.Remove(backup.Name())
+		return "", err
+	}
+	if err := backup.Chmod(info.Mode().Perm()); err != nil {
+		backup.Close()
+		os.Remove(backup.Name())
+		return "", err
+	}
+	if err := backup.Close(); err != nil {
+		os.Remove(backup.Name())
+		return "", err
+	}
+	return backup.Name(), nil
+}
+
+// exists reports whether a file exists at path
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
@@ -0,0 +1,325 @@
+// Package sbom reads the software bills of materials published with agent
+// releases and generates the CLI's own from the module information the Go
+// toolchain embeds in every binary.
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Formats an SBOM is published and generated in
+const (
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+)
+
+// Formats lists the supported formats
+var Formats = []string{FormatSPDX, FormatCycloneDX}
+
+// AssetName returns the name of the release asset holding the SBOM in format
+func AssetName(format string) (string, error) {
+	switch format {
+	case FormatSPDX:
+		return "sbom.spdx.json", nil
+	case FormatCycloneDX:
+		return "sbom.cdx.json", nil
+	}
+	return "", fmt.Errorf("unknown SBOM format %q: use %s", format, strings.Join(Formats, " or "))
+}
+
+// Package is a component listed in an SBOM
+type Package struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	License string `json:"license,omitempty"`
+	// PURL is the package URL, e.g. pkg:golang/gopkg.in/yaml.v3@v3.0.1
+	PURL string `json:"purl,omitempty"`
+}
+
+// Document is what is displayed of an SBOM
+type Document struct {
+	// Format names the specification and its version, e.g. "SPDX-2.3"
+	Format   string    `json:"format"`
+	Name     string    `json:"name,omitempty"`
+	Created  time.Time `json:"created,omitempty"`
+	Creators []string  `json:"creators,omitempty"`
+	Packages []Package `json:"packages"`
+}
+
+// Parse reads an SPDX or CycloneDX document in JSON
+func Parse(data []byte) (*Document, error) {
+	var probe struct {
+		SPDXVersion string `json:"spdxVersion"`
+		BOMFormat   string `json:"bomFormat"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("SBOM is not valid JSON: %w", err)
+	}
+	switch {
+	case probe.SPDXVersion != "":
+		return parseSPDX(data)
+	case probe.BOMFormat == "CycloneDX":
+		return parseCycloneDX(data)
+	}
+	return nil, fmt.Errorf("SBOM is neither an SPDX nor a CycloneDX document")
+}
+
+// spdxDocument is the part of an SPDX 2.x JSON document that is read and
+// generated
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships,omitempty"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string       `json:"name"`
+	SPDXID           string       `json:"SPDXID"`
+	VersionInfo      string       `json:"versionInfo,omitempty"`
+	DownloadLocation string       `json:"downloadLocation"`
+	FilesAnalyzed    bool         `json:"filesAnalyzed"`
+	LicenseConcluded string       `json:"licenseConcluded,omitempty"`
+	LicenseDeclared  string       `json:"licenseDeclared,omitempty"`
+	CopyrightText    string       `json:"copyrightText,omitempty"`
+	ExternalRefs     []spdxExtRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExtRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// noAssertion is SPDX for "not stated"
+const noAssertion = "NOASSERTION"
+
+func parseSPDX(data []byte) (*Document, error) {
+	var spdx spdxDocument
+	if err := json.Unmarshal(data, &spdx); err != nil {
+		return nil, fmt.Errorf("invalid SPDX document: %w", err)
+	}
+	doc := &Document{Format: spdx.SPDXVersion, Name: spdx.Name, Creators: spdx.CreationInfo.Creators}
+	doc.Created, _ = time.Parse(time.RFC3339, spdx.CreationInfo.Created)
+	for _, p := range spdx.Packages {
+		pkg := Package{Name: p.Name, Version: p.VersionInfo, License: p.LicenseDeclared}
+		if pkg.License == "" || pkg.License == noAssertion {
+			pkg.License = p.LicenseConcluded
+		}
+		if pkg.License == noAssertion {
+			pkg.License = ""
+		}
+		for _, ref := range p.ExternalRefs {
+			if ref.ReferenceType == "purl" {
+				pkg.PURL = ref.ReferenceLocator
+			}
+		}
+		doc.Packages = append(doc.Packages, pkg)
+	}
+	return doc, nil
+}
+
+// cycloneDXDocument is the part of a CycloneDX JSON document that is read
+// and generated
+type cycloneDXDocument struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber,omitempty"`
+	Version      int                   `json:"version"`
+	Metadata     cycloneDXMetadata     `json:"metadata"`
+	Components   []cycloneDXComponent  `json:"components,omitempty"`
+	Dependencies []cycloneDXDependency `json:"dependencies,omitempty"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string `json:"timestamp,omitempty"`
+	// Tools is an array of tools up to CycloneDX 1.4 and an object with
+	// components since 1.5
+	Tools     json.RawMessage     `json:"tools,omitempty"`
+	Component *cycloneDXComponent `json:"component,omitempty"`
+}
+
+type cycloneDXComponent struct {
+	Type     string             `json:"type"`
+	BOMRef   string             `json:"bom-ref,omitempty"`
+	Name     string             `json:"name"`
+	Version  string             `json:"version,omitempty"`
+	PURL     string             `json:"purl,omitempty"`
+	Licenses []cycloneDXLicense `json:"licenses,omitempty"`
+}
+
+type cycloneDXLicense struct {
+	License *struct {
+		ID   string `json:"id,omitempty"`
+		Name string `json:"name,omitempty"`
+	} `json:"license,omitempty"`
+	Expression string `json:"expression,omitempty"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+type cycloneDXTool struct {
+	Vendor  string `json:"vendor,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+func parseCycloneDX(data []byte) (*Document, error) {
+	var bom cycloneDXDocument
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return nil, fmt.Errorf("invalid CycloneDX document: %w", err)
+	}
+	doc := &Document{Format: "CycloneDX-" + bom.SpecVersion}
+	doc.Created, _ = time.Parse(time.RFC3339, bom.Metadata.Timestamp)
+	if component := bom.Metadata.Component; component != nil {
+		doc.Name = strings.TrimSpace(component.Name + " " + component.Version)
+	}
+
+	var tools []cycloneDXTool
+	if json.Unmarshal(bom.Metadata.Tools, &tools) != nil {
+		var object struct {
+			Components []cycloneDXTool `json:"components"`
+		}
+		json.Unmarshal(bom.Metadata.Tools, &object)
+		tools = object.Components
+	}
+	for _, tool := range tools {
+		doc.Creators = append(doc.Creators, "Tool: "+strings.TrimSpace(tool.Name+" "+tool.Version))
+	}
+
+	for _, c := range bom.Components {
+		pkg := Package{Name: c.Name, Version: c.Version, PURL: c.PURL}
+		var licenses []string
+		for _, l := range c.Licenses {
+			switch {
+			case l.Expression != "":
+				licenses = append(licenses, l.Expression)
+			case l.License != nil && l.License.ID != "":
+				licenses = append(licenses, l.License.ID)
+			case l.License != nil:
+				licenses = append(licenses, l.License.Name)
+			}
+		}
+		pkg.License = strings.Join(licenses, " OR ")
+		doc.Packages = append(doc.Packages, pkg)
+	}
+	return doc, nil
+}
+
+// Generate returns an SBOM in format of the binary described by info, the
+// module information the Go toolchain embeds (see debug.ReadBuildInfo):
+// the main module at version, the standard library and every module linked
+// in. created is recorded as the creation time.
+func Generate(format, version string, info *debug.BuildInfo, created time.Time) ([]byte, error) {
+	main := Package{Name: info.Main.Path, Version: version, PURL: purl(info.Main.Path, version)}
+	packages := []Package{{Name: "stdlib", Version: info.GoVersion, PURL: purl("stdlib", info.GoVersion)}}
+	for _, dep := range info.Deps {
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		packages = append(packages, Package{Name: dep.Path, Version: dep.Version, PURL: purl(dep.Path, dep.Version)})
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+
+	// Identifiers are derived from the content so the same build always
+	// yields the same document
+	hash := sha256.New()
+	for _, pkg := range append([]Package{main}, packages...) {
+		fmt.Fprintln(hash, pkg.PURL)
+	}
+	digest := hex.EncodeToString(hash.Sum(nil))
+	timestamp := created.UTC().Format(time.RFC3339)
+	tool := "fixpanic-" + version
+
+	var document interface{}
+	switch format {
+	case FormatSPDX:
+		spdx := spdxDocument{
+			SPDXVersion:       "SPDX-2.3",
+			DataLicense:       "CC0-1.0",
+			SPDXID:            "SPDXRef-DOCUMENT",
+			Name:              main.Name + "@" + version,
+			DocumentNamespace: "https://fixpanic.com/spdx/fixpanic-cli/" + version + "-" + digest[:16],
+			CreationInfo:      spdxCreationInfo{Created: timestamp, Creators: []string{"Tool: " + tool}},
+		}
+		for i, pkg := range append([]Package{main}, packages...) {
+			id := fmt.Sprintf("SPDXRef-Package-%d", i)
+			spdx.Packages = append(spdx.Packages, spdxPackage{
+				Name:             pkg.Name,
+				SPDXID:           id,
+				VersionInfo:      pkg.Version,
+				DownloadLocation: noAssertion,
+				LicenseConcluded: noAssertion,
+				LicenseDeclared:  noAssertion,
+				CopyrightText:    noAssertion,
+				ExternalRefs:     []spdxExtRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: pkg.PURL}},
+			})
+			relationship := spdxRelationship{SPDXElementID: "SPDXRef-Package-0", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: id}
+			if i == 0 {
+				relationship = spdxRelationship{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: id}
+			}
+			spdx.Relationships = append(spdx.Relationships, relationship)
+		}
+		document = spdx
+	case FormatCycloneDX:
+		bom := cycloneDXDocument{
+			BOMFormat:    "CycloneDX",
+			SpecVersion:  "1.5",
+			SerialNumber: fmt.Sprintf("urn:uuid:%s-%s-5%s-8%s-%s", digest[:8], digest[8:12], digest[13:16], digest[17:20], digest[20:32]),
+			Version:      1,
+			Metadata: cycloneDXMetadata{
+				Timestamp: timestamp,
+				Component: &cycloneDXComponent{Type: "application", BOMRef: main.PURL, Name: main.Name, Version: version, PURL: main.PURL},
+			},
+		}
+		tools, err := json.Marshal(map[string][]cycloneDXComponent{"components": {{Type: "application", Name: "fixpanic", Version: version}}})
+		if err != nil {
+			return nil, err
+		}
+		bom.Metadata.Tools = tools
+		dependency := cycloneDXDependency{Ref: main.PURL}
+		for _, pkg := range packages {
+			bom.Components = append(bom.Components, cycloneDXComponent{Type: "library", BOMRef: pkg.PURL, Name: pkg.Name, Version: pkg.Version, PURL: pkg.PURL})
+			dependency.DependsOn = append(dependency.DependsOn, pkg.PURL)
+		}
+		bom.Dependencies = []cycloneDXDependency{dependency}
+		document = bom
+	default:
+		_, err := AssetName(format)
+		return nil, err
+	}
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// purl returns the package URL of a Go module
+func purl(path, version string) string {
+	if version == "" || version == "(devel)" {
+		return "pkg:golang/" + path
+	}
+	return "pkg:golang/" + path + "@" + version
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+)
+
+// AppArmorProfileName is the AppArmor profile confining the agent
+const AppArmorProfileName = "fixpanic-agent"
+
+// AppArmorProfilePath is where the agent's AppArmor profile is installed
+const AppArmorProfilePath = "/etc/apparmor.d/fixpanic-agent"
+
+// confinementDirectives restrict the agent service to the system calls and
+// kernel interfaces a network service running diagnostic tools needs.
+// Blocked system calls fail with EPERM instead of killing the agent, so a
+// too strict filter shows up as errors in its log rather than a crash loop.
+var confinementDirectives = []string{
+	"SystemCallArchitectures=native",
+	"SystemCallFilter=@system-service",
+	"SystemCallFilter=~@clock @cpu-emulation @debug @module @mount @obsolete @raw-io @reboot @swap",
+	"SystemCallErrorNumber=EPERM",
+	"ProtectKernelModules=yes",
+	"ProtectKernelTunables=yes",
+	"ProtectKernelLogs=yes",
+	"ProtectClock=yes",
+	"LockPersonality=yes",
+	"RestrictRealtime=yes",
+	"RestrictNamespaces=yes",
+	"RestrictAddressFamilies=AF_UNIX AF_INET AF_INET6 AF_NETLINK",
+}
+
+// AppArmorEnabled reports whether the kernel enforces AppArmor and profiles
+// can be loaded
+func AppArmorEnabled() bool {
+	data, err := os.ReadFile("/sys/module/apparmor/parameters/enabled")
+	if err != nil || strings.TrimSpace(string(data)) != "Y" {
+		return false
+	}
+	return platform.IsCommandAvailable("apparmor_parser")
+}
+
+// AppArmorProfileMode returns the mode ("enforce", "complain") the agent's
+// profile is loaded in, or an empty string if it isn't loaded
+func AppArmorProfileMode() string {
+	file, err := os.Open("/sys/kernel/security/apparmor/profiles")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Lines look like "fixpanic-agent (enforce)"
+		name, mode, ok := strings.Cut(scanner.Text(), " ")
+		if ok && name == AppArmorProfileName {
+			return strings.Trim(mode, "()")
+		}
+	}
+	return ""
+}
+
+// RenderAppArmorProfile returns the AppArmor profile for the agent. The agent
+// may read the host's configuration, logs and process information and run
+// the system's diagnostic tools, which inherit the profile, but never read
+// password hashes or SSH keys.
+func (m *Manager) RenderAppArmorProfile() (string, error) {
+	tmpl := `# AppArmor profile for the Fixpanic agent, installed by 'fixpanic agent install --confine'
+abi <abi/3.0>,
+include <tunables/global>
+
+profile {{ .Name }} {{ .BinaryPath }} flags=(attach_disconnected) {
+  include <abstractions/base>
+  include <abstractions/nameservice>
+  include <abstractions/ssl_certs>
+  include <abstractions/openssl>
+
+  network inet stream,
+  network inet6 stream,
+  network inet dgram,
+  network inet6 dgram,
+  network netlink raw,
+
+  capability dac_read_search,
+  capability net_raw,
+  capability sys_ptrace,
+  capability kill,
+  ptrace read,
+  signal send,
+
+  {{ .BinaryPath }} mr,
+  {{ .ConfigDir }}/ r,
+  {{ .ConfigDir }}/** rk,
+  {{ .LogDir }}/ r,
+  {{ .LogDir }}/** rwk,
+{{- range .Readable }}
+  {{ . }} r,
+{{- end }}
+  /tmp/ r,
+  /tmp/** rwk,
+
+  # Diagnostic tools run on request inherit this profile
+  /{,usr/}{,s}bin/* ix,
+  /usr/local/{,s}bin/* ix,
+  /usr/lib/** mr,
+  /etc/** r,
+  /proc/** r,
+  /sys/** r,
+  /run/** r,
+  /var/log/** r,
+
+  # Credentials on the host stay out of reach
+  deny /etc/shadow* r,
+  deny /etc/gshadow* r,
+  deny /root/.ssh/** rw,
+  deny /home/*/.ssh/** rw,
+}
+`
+
+	// TLS files outside the config directory must stay readable
+	var readable []string
+	if agentConfig, err := config.LoadConfig(m.platform.GetConfigPath()); err == nil {
+		for _, path := range []string{agentConfig.App.TLSCAFile, agentConfig.App.TLSCertFile, agentConfig.App.TLSKeyFile} {
+			if path != "" && !strings.HasPrefix(path, m.platform.ConfigDir+"/") {
+				readable = append(readable, path)
+			}
+		}
+	}
+
+	data := struct {
+		Name       string
+		BinaryPath string
+		ConfigDir  string
+		LogDir     string
+		Readable   []string
+	}{
+		Name:       AppArmorProfileName,
+		BinaryPath: m.platform.GetBinaryPath(),
+		ConfigDir:  filepath.Clean(m.platform.ConfigDir),
+		LogDir:     filepath.Clean(m.platform.LogDir),
+		Readable:   readable,
+	}
+
+	t, err := template.New("apparmor").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var result strings.Builder
+	if err := t.Execute(&result, data); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// installAppArmorProfile writes the agent's profile and loads it in enforce mode
+func (m *Manager) installAppArmorProfile(ctx context.Context) error {
+	profile, err := m.RenderAppArmorProfile()
+	if err != nil {
+		return fmt.Errorf("failed to render AppArmor profile: %w", err)
+	}
+	if err := os.WriteFile(AppArmorProfilePath, []byte(profile), 0644); err != nil {
+		return fmt.Errorf("failed to write AppArmor profile: %w", err)
+	}
+	if output, err := exec.CommandContext(ctx, "apparmor_parser", "--replace", "--write-cache", AppArmorProfilePath).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to load AppArmor profile: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// removeAppArmorProfile unloads and removes the agent's profile, if installed
+func (m *Manager) removeAppArmorProfile(ctx context.Context) error {
+	if _, err := os.Stat(AppArmorProfilePath); err != nil {
+		return nil
+	}
+	if AppArmorEnabled() {
+		exec.CommandContext(ctx, "apparmor_parser", "--remove", AppArmorProfilePath).Run()
+	}
+	if err := os.Remove(AppArmorProfilePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove AppArmor profile: %w", err)
+	}
+	return nil
+}
+
+// ProcessConfinement reports whether the process pid runs under a seccomp
+// filter and the AppArmor label it is confined by, if any
+func ProcessConfinement(pid int) (seccomp bool, label string) {
+	if status, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", pid)); err == nil {
+		for _, line := range strings.Split(string(status), "\n") {
+			if value, ok := strings.CutPrefix(line, "Seccomp:"); ok {
+				// 2 is SECCOMP_MODE_FILTER
+				seccomp = strings.TrimSpace(value) == "2"
+			}
+		}
+	}
+	if current, err := os.ReadFile(fmt.Sprintf("/proc/%d/attr/current", pid)); err == nil {
+		label = strings.TrimSpace(strings.TrimRight(string(current), "\x00"))
+		if label == "unconfined" {
+			label = ""
+		}
+	}
+	return seccomp, label
+}
+
+// ConfinementDenials returns the kernel and agent log lines of the last
+// period showing the confinement blocking the agent: AppArmor denials of its
+// profile, and system calls refused by the filter, which the agent logs as
+// "operation not permitted"
+func (m *Manager) ConfinementDenials(ctx context.Context, period time.Duration) ([]string, error) {
+	if !platform.IsSystemdAvailable() {
+		return nil, fmt.Errorf("systemd is not available on this system")
+	}
+	since := fmt.Sprintf("-%ds", int(period.Seconds()))
+
+	var denials []string
+	kernel, err := exec.CommandContext(ctx, "journalctl", "-k", "-q", "--no-pager", "--since", since).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the kernel log: %w", err)
+	}
+	profile := fmt.Sprintf("profile=%q", AppArmorProfileName)
+	for _, line := range bytes.Split(kernel, []byte("\n")) {
+		if bytes.Contains(line, []byte(`apparmor="DENIED"`)) && bytes.Contains(line, []byte(profile)) {
+			denials = append(denials, string(line))
+		}
+	}
+
+	agent, err := exec.CommandContext(ctx, "journalctl", "-u", platform.GetSystemdServiceName(), "-q", "--no-pager", "--since", since).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service logs: %w", err)
+	}
+	for _, line := range bytes.Split(agent, []byte("\n")) {
+		if bytes.Contains(bytes.ToLower(line), []byte("operation not permitted")) {
+			denials = append(denials, string(line))
+		}
+	}
+	return denials, nil
+}
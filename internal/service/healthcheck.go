@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+)
+
+// HealthCheckUnit is the name of the systemd service and timer running the
+// scheduled health check
+const HealthCheckUnit = "fixpanic-healthcheck"
+
+// HealthCheckCronPath is the cron entry running the scheduled health check on
+// hosts without systemd
+const HealthCheckCronPath = "/etc/cron.d/fixpanic-healthcheck"
+
+// healthCheckJob runs the scheduled health check
+var healthCheckJob = scheduledJob{
+	unit:        HealthCheckUnit,
+	cronPath:    HealthCheckCronPath,
+	description: "Fixpanic agent health check",
+	installedBy: "fixpanic agent healthcheck install",
+}
+
+// HealthCheckUnitPaths returns the unit files of the scheduled health check
+func HealthCheckUnitPaths() []string {
+	return healthCheckJob.unitPaths()
+}
+
+// RenderHealthCheckUnits returns the service and timer running command every
+// interval, starting a minute after boot
+func RenderHealthCheckUnits(command []string, interval time.Duration) (serviceUnit, timerUnit string) {
+	return healthCheckJob.renderUnits(command, interval)
+}
+
+// RenderHealthCheckCron returns the cron entry running command as root every
+// interval, rounded up to whole minutes
+func RenderHealthCheckCron(command []string, interval time.Duration) string {
+	return healthCheckJob.renderCron(command, interval)
+}
+
+// InstallHealthCheck schedules command every interval, with a systemd timer
+// where available and a cron entry otherwise
+func (m *Manager) InstallHealthCheck(ctx context.Context, command []string, interval time.Duration) error {
+	return m.installJob(ctx, healthCheckJob, command, interval)
+}
+
+// UninstallHealthCheck removes the scheduled health check. It returns false
+// if none was installed.
+func (m *Manager) UninstallHealthCheck(ctx context.Context) (bool, error) {
+	return m.uninstallJob(ctx, healthCheckJob)
+}
+
+// scheduledJob is a command the CLI runs on a schedule, with a systemd timer
+// where available and a cron entry otherwise
+type scheduledJob struct {
+	unit        string
+	cronPath    string
+	description string
+	// installedBy is the command that installs the job, noted in the cron entry
+	installedBy string
+}
+
+// unitPaths returns the service and timer unit files of the job
+func (j scheduledJob) unitPaths() []string {
+	return []string{
+		filepath.Join("/etc/systemd/system", j.unit+".service"),
+		filepath.Join("/etc/systemd/system", j.unit+".timer"),
+	}
+}
+
+// renderUnits returns the service and timer running command every interval,
+// starting a minute after boot
+func (j scheduledJob) renderUnits(command []string, interval time.Duration) (serviceUnit, timerUnit string) {
+	serviceUnit = fmt.Sprintf(`[Unit]
+Description=%s
+After=network-online.target
+
+[Service]
+Type=oneshot
+ExecStart=%s
+`, j.description, strings.Join(quoteUnitArgs(command), " "))
+
+	timerUnit = fmt.Sprintf(`[Unit]
+Description=Run the %s every %s
+
+[Timer]
+OnBootSec=1min
+OnUnitActiveSec=%ds
+AccuracySec=10s
+
+[Install]
+WantedBy=timers.target
+`, j.description, interval, int(interval.Seconds()))
+	return serviceUnit, timerUnit
+}
+
+// renderCron returns the cron entry running command as root every interval,
+// rounded up to whole minutes
+func (j scheduledJob) renderCron(command []string, interval time.Duration) string {
+	minutes := int(math.Ceil(interval.Minutes()))
+	schedule := "* * * * *"
+	if minutes > 1 {
+		schedule = fmt.Sprintf("*/%d * * * *", minutes)
+	}
+	return fmt.Sprintf("# %s, installed by '%s'\n%s root %s >/dev/null 2>&1\n",
+		j.description, j.installedBy, schedule, strings.Join(quoteShellArgs(command), " "))
+}
+
+// installJob schedules command every interval
+func (m *Manager) installJob(ctx context.Context, job scheduledJob, command []string, interval time.Duration) error {
+	if !platform.IsSystemdAvailable() {
+		if err := os.WriteFile(job.cronPath, []byte(job.renderCron(command, interval)), 0644); err != nil {
+			return fmt.Errorf("failed to write cron entry: %w", err)
+		}
+		return nil
+	}
+
+	serviceUnit, timerUnit := job.renderUnits(command, interval)
+	paths := job.unitPaths()
+	for i, content := range []string{serviceUnit, timerUnit} {
+		if err := os.WriteFile(paths[i], []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", paths[i], err)
+		}
+	}
+	if err := m.reloadSystemd(ctx); err != nil {
+		return fmt.Errorf("failed to reload systemd: %w", err)
+	}
+	if output, err := exec.CommandContext(ctx, "systemctl", "enable", "--now", job.unit+".timer").CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to enable %s.timer: %w: %s", job.unit, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// uninstallJob removes a scheduled job. It returns false if it wasn't
+// installed.
+func (m *Manager) uninstallJob(ctx context.Context, job scheduledJob) (bool, error) {
+	removed := false
+	if err := os.Remove(job.cronPath); err == nil {
+		removed = true
+	} else if !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to remove cron entry: %w", err)
+	}
+
+	if _, err := os.Stat(job.unitPaths()[1]); err != nil {
+		return removed, nil
+	}
+	if platform.IsSystemdAvailable() {
+		exec.CommandContext(ctx, "systemctl", "disable", "--now", job.unit+".timer").Run()
+	}
+	for _, path := range job.unitPaths() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	if platform.IsSystemdAvailable() {
+		if err := m.reloadSystemd(ctx); err != nil {
+			return true, fmt.Errorf("failed to reload systemd: %w", err)
+		}
+	}
+	return true, nil
+}
+
+// quoteUnitArgs quotes arguments with spaces for an ExecStart= line and
+// escapes the specifiers systemd would expand
+func quoteUnitArgs(args []string) []string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		arg = strings.ReplaceAll(arg, "%", "%%")
+		if strings.ContainsAny(arg, " \t\"'\\") {
+			arg = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+		}
+		quoted[i] = arg
+	}
+	return quoted
+}
+
+// quoteShellArgs single-quotes arguments for a cron command line; cron also
+// treats % specially
+func quoteShellArgs(args []string) []string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = "'" + strings.NewReplacer("'", `'\''`, "%", `\%`).Replace(arg) + "'"
+	}
+	return quoted
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+)
+
+// JournalUsageWarnBytes is the journal size above which it is reported as
+// excessive; it is the largest size journald limits itself to by default
+const JournalUsageWarnBytes = 4 * 1024 * 1024 * 1024
+
+// JournalDir is where journald stores persistent journals
+const JournalDir = "/var/log/journal"
+
+// RateLimitOverride is the drop-in suggested when journald suppresses agent
+// messages, raising the per-unit limits of journald (systemd 240 and later)
+const RateLimitOverride = `[Service]
+LogRateLimitIntervalSec=30s
+LogRateLimitBurst=10000`
+
+// diskUsagePattern matches the size in the output of journalctl --disk-usage,
+// e.g. "Archived and active journals take up 1.2G in the file system."
+var diskUsagePattern = regexp.MustCompile(`take up ([0-9.]+)([BKMGTPE]?)`)
+
+// suppressedPattern matches journald's report of messages it dropped for a
+// unit, e.g. "Suppressed 1234 messages from fixpanic-connectivity-layer.service"
+var suppressedPattern = regexp.MustCompile(`Suppressed ([0-9]+) messages from (\S+)`)
+
+// JournalDiskUsage returns how many bytes the journal files take on disk
+func JournalDiskUsage(ctx context.Context) (uint64, error) {
+	if !platform.IsSystemdAvailable() {
+		return 0, fmt.Errorf("systemd is not available on this system")
+	}
+
+	output, err := exec.CommandContext(ctx, "journalctl", "--disk-usage").Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read journal disk usage: %w", err)
+	}
+	return parseDiskUsage(string(output))
+}
+
+// parseDiskUsage extracts the size from journalctl --disk-usage output
+func parseDiskUsage(output string) (uint64, error) {
+	match := diskUsagePattern.FindStringSubmatch(output)
+	if match == nil {
+		return 0, fmt.Errorf("unexpected journalctl --disk-usage output: %q", strings.TrimSpace(output))
+	}
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected journal size %q: %w", match[1], err)
+	}
+	// journalctl uses binary units with single-letter suffixes; an empty
+	// suffix is found at index 0 like "B"
+	value *= math.Pow(1024, float64(strings.Index("BKMGTPE", match[2])))
+	return uint64(value), nil
+}
+
+// SuppressedMessages returns how many messages of the agent service journald
+// dropped because of its rate limit during the last period
+func (m *Manager) SuppressedMessages(ctx context.Context, period time.Duration) (int, error) {
+	if !platform.IsSystemdAvailable() {
+		return 0, fmt.Errorf("systemd is not available on this system")
+	}
+
+	since := time.Now().Add(-period).Format("2006-01-02 15:04:05")
+	cmd := exec.CommandContext(ctx, "journalctl", "-t", "systemd-journald", "--since", since, "-o", "cat", "--no-pager")
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read journald messages: %w", err)
+	}
+
+	suppressed := 0
+	for _, match := range suppressedPattern.FindAllStringSubmatch(string(output), -1) {
+		// Older journald versions report the cgroup path instead of the unit
+		if !strings.HasSuffix(match[2], platform.GetSystemdServiceName()) {
+			continue
+		}
+		count, _ := strconv.Atoi(match[1])
+		suppressed += count
+	}
+	return suppressed, nil
+}
+
+// WriteServiceLogsSince streams the service logs newer than since to w, only
+// the last lines of them if lines is positive
+func (m *Manager) WriteServiceLogsSince(ctx context.Context, w io.Writer, since time.Time, lines int) error {
+	if !platform.IsSystemdAvailable() {
+		return fmt.Errorf("systemd is not available on this system")
+	}
+
+	args := []string{"-u", platform.GetSystemdServiceName(), "--since", since.Local().Format("2006-01-02 15:04:05"), "--no-pager"}
+	if lines > 0 {
+		args = append(args, "-n", strconv.Itoa(lines))
+	}
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
+	cmd.Stdout = w
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to get service logs: %w", err)
+	}
+	return nil
+}
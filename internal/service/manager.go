@@ -1,14 +1,22 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"os/user"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/interrupt"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
 	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/trace"
 )
 
 // Manager handles systemd service operations
@@ -24,7 +32,10 @@ func NewManager(platform *platform.PlatformInfo) *Manager {
 }
 
 // Install installs the systemd service
-func (m *Manager) Install() error {
+func (m *Manager) Install(ctx context.Context) (err error) {
+	span := trace.Start("service install", trace.KindService, map[string]string{"service.name": platform.GetSystemdServiceName()})
+	defer func() { span.End(err) }()
+
 	if !platform.IsSystemdAvailable() {
 		return fmt.Errorf("systemd is not available on this system")
 	}
@@ -36,30 +47,54 @@ func (m *Manager) Install() error {
 
 	servicePath := m.platform.GetServiceFilePath()
 
-	// Create systemd service file
-	if err := os.WriteFile(servicePath, []byte(serviceContent), 0644); err != nil {
-		return fmt.Errorf("failed to write service file: %w", err)
-	}
+	// A unit referring to a missing profile, or written halfway, keeps the
+	// agent from starting
+	err = interrupt.Critical(func() error {
+		// The unit refers to the AppArmor profile, which must be loaded first
+		if strings.Contains(serviceContent, "AppArmorProfile=") {
+			if err := m.installAppArmorProfile(ctx); err != nil {
+				return err
+			}
+		} else if err := m.removeAppArmorProfile(ctx); err != nil {
+			return err
+		}
 
-	// Reload systemd
-	if err := m.reloadSystemd(); err != nil {
-		return fmt.Errorf("failed to reload systemd: %w", err)
+		// Create systemd service file
+		if err := os.WriteFile(servicePath, []byte(serviceContent), 0644); err != nil {
+			return fmt.Errorf("failed to write service file: %w", err)
+		}
+
+		// Reload systemd
+		if err := m.reloadSystemd(ctx); err != nil {
+			return fmt.Errorf("failed to reload systemd: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("Systemd service installed: %s\n", platform.GetSystemdServiceName())
+	fmt.Fprintf(logger.Messages(), "Systemd service installed: %s\n", platform.GetSystemdServiceName())
 	return nil
 }
 
 // Uninstall removes the systemd service
-func (m *Manager) Uninstall() error {
+func (m *Manager) Uninstall(ctx context.Context) (err error) {
+	span := trace.Start("service uninstall", trace.KindService, map[string]string{"service.name": platform.GetSystemdServiceName()})
+	defer func() { span.End(err) }()
+
 	if !platform.IsSystemdAvailable() {
 		return nil // Nothing to do if systemd is not available
 	}
 
 	// Stop the service first
-	if err := m.Stop(); err != nil {
+	if err := m.Stop(ctx); err != nil {
 		// Continue even if stop fails
-		fmt.Printf("Warning: failed to stop service: %v\n", err)
+		fmt.Fprintf(logger.Messages(), "Warning: failed to stop service: %v\n", err)
+	}
+
+	if err := m.removeAppArmorProfile(ctx); err != nil {
+		return err
 	}
 
 	servicePath := m.platform.GetServiceFilePath()
@@ -73,51 +108,57 @@ func (m *Manager) Uninstall() error {
 	}
 
 	// Reload systemd
-	if err := m.reloadSystemd(); err != nil {
+	if err := m.reloadSystemd(ctx); err != nil {
 		return fmt.Errorf("failed to reload systemd: %w", err)
 	}
 
-	fmt.Printf("Systemd service uninstalled: %s\n", platform.GetSystemdServiceName())
+	fmt.Fprintf(logger.Messages(), "Systemd service uninstalled: %s\n", platform.GetSystemdServiceName())
 	return nil
 }
 
 // Start starts the service
-func (m *Manager) Start() error {
+func (m *Manager) Start(ctx context.Context) (err error) {
+	span := trace.Start("service start", trace.KindService, map[string]string{"service.name": platform.GetSystemdServiceName()})
+	defer func() { span.End(err) }()
+
 	if !platform.IsSystemdAvailable() {
 		return fmt.Errorf("systemd is not available on this system")
 	}
 
-	cmd := exec.Command("systemctl", "start", platform.GetSystemdServiceName())
+	cmd := exec.CommandContext(ctx, "systemctl", "start", platform.GetSystemdServiceName())
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to start service: %w", err)
 	}
 
-	fmt.Printf("Service started: %s\n", platform.GetSystemdServiceName())
+	fmt.Fprintf(logger.Messages(), "Service started: %s\n", platform.GetSystemdServiceName())
 	return nil
 }
 
 // Stop stops the service
-func (m *Manager) Stop() error {
+func (m *Manager) Stop(ctx context.Context) (err error) {
+	span := trace.Start("service stop", trace.KindService, map[string]string{"service.name": platform.GetSystemdServiceName()})
+	defer func() { span.End(err) }()
+
 	if !platform.IsSystemdAvailable() {
 		return fmt.Errorf("systemd is not available on this system")
 	}
 
-	cmd := exec.Command("systemctl", "stop", platform.GetSystemdServiceName())
+	cmd := exec.CommandContext(ctx, "systemctl", "stop", platform.GetSystemdServiceName())
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to stop service: %w", err)
 	}
 
-	fmt.Printf("Service stopped: %s\n", platform.GetSystemdServiceName())
+	fmt.Fprintf(logger.Messages(), "Service stopped: %s\n", platform.GetSystemdServiceName())
 	return nil
 }
 
 // Status returns the service status
-func (m *Manager) Status() (string, error) {
+func (m *Manager) Status(ctx context.Context) (string, error) {
 	if !platform.IsSystemdAvailable() {
 		return "systemd not available", nil
 	}
 
-	cmd := exec.Command("systemctl", "is-active", platform.GetSystemdServiceName())
+	cmd := exec.CommandContext(ctx, "systemctl", "is-active", platform.GetSystemdServiceName())
 	output, err := cmd.Output()
 	if err != nil {
 		// Service is not active
@@ -129,12 +170,12 @@ func (m *Manager) Status() (string, error) {
 }
 
 // IsEnabled checks if the service is enabled
-func (m *Manager) IsEnabled() (bool, error) {
+func (m *Manager) IsEnabled(ctx context.Context) (bool, error) {
 	if !platform.IsSystemdAvailable() {
 		return false, nil
 	}
 
-	cmd := exec.Command("systemctl", "is-enabled", platform.GetSystemdServiceName())
+	cmd := exec.CommandContext(ctx, "systemctl", "is-enabled", platform.GetSystemdServiceName())
 	if err := cmd.Run(); err != nil {
 		return false, nil // Service is not enabled
 	}
@@ -143,35 +184,145 @@ func (m *Manager) IsEnabled() (bool, error) {
 }
 
 // Enable enables the service to start on boot
-func (m *Manager) Enable() error {
+func (m *Manager) Enable(ctx context.Context) (err error) {
+	span := trace.Start("service enable", trace.KindService, map[string]string{"service.name": platform.GetSystemdServiceName()})
+	defer func() { span.End(err) }()
+
 	if !platform.IsSystemdAvailable() {
 		return fmt.Errorf("systemd is not available on this system")
 	}
 
-	cmd := exec.Command("systemctl", "enable", platform.GetSystemdServiceName())
+	cmd := exec.CommandContext(ctx, "systemctl", "enable", platform.GetSystemdServiceName())
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to enable service: %w", err)
 	}
 
-	fmt.Printf("Service enabled for auto-start: %s\n", platform.GetSystemdServiceName())
+	fmt.Fprintf(logger.Messages(), "Service enabled for auto-start: %s\n", platform.GetSystemdServiceName())
 	return nil
 }
 
 // Disable disables the service from starting on boot
-func (m *Manager) Disable() error {
+func (m *Manager) Disable(ctx context.Context) (err error) {
+	span := trace.Start("service disable", trace.KindService, map[string]string{"service.name": platform.GetSystemdServiceName()})
+	defer func() { span.End(err) }()
+
 	if !platform.IsSystemdAvailable() {
 		return fmt.Errorf("systemd is not available on this system")
 	}
 
-	cmd := exec.Command("systemctl", "disable", platform.GetSystemdServiceName())
+	cmd := exec.CommandContext(ctx, "systemctl", "disable", platform.GetSystemdServiceName())
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to disable service: %w", err)
 	}
 
-	fmt.Printf("Service disabled from auto-start: %s\n", platform.GetSystemdServiceName())
+	fmt.Fprintf(logger.Messages(), "Service disabled from auto-start: %s\n", platform.GetSystemdServiceName())
 	return nil
 }
 
+// RestartCount returns how many times systemd has automatically restarted the service
+func (m *Manager) RestartCount(ctx context.Context) (int, error) {
+	if !platform.IsSystemdAvailable() {
+		return 0, fmt.Errorf("systemd is not available on this system")
+	}
+
+	value, err := m.showProperty(ctx, "NRestarts")
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected NRestarts value %q: %w", value, err)
+	}
+
+	return count, nil
+}
+
+// CrashLoopRestartThreshold is the number of automatic restarts after which a
+// recently (re)started service is considered to be crash looping
+const CrashLoopRestartThreshold = 3
+
+// CrashLoopWindow is how recently the main process must have started for
+// accumulated restarts to count as a crash loop
+const CrashLoopWindow = 5 * time.Minute
+
+// Health describes the runtime health of the systemd service
+type Health struct {
+	ActiveState   string
+	SubState      string
+	Result        string
+	Restarts      int
+	MainStartedAt time.Time
+	LastExitCode  int
+}
+
+// IsCrashLooping reports whether systemd keeps restarting the service
+func (h *Health) IsCrashLooping() bool {
+	if h.Restarts < CrashLoopRestartThreshold {
+		return false
+	}
+	if h.SubState == "auto-restart" {
+		return true
+	}
+	return !h.MainStartedAt.IsZero() && time.Since(h.MainStartedAt) < CrashLoopWindow
+}
+
+// Health returns restart and state information for the service
+func (m *Manager) Health(ctx context.Context) (*Health, error) {
+	if !platform.IsSystemdAvailable() {
+		return nil, fmt.Errorf("systemd is not available on this system")
+	}
+
+	cmd := exec.CommandContext(ctx, "systemctl", "show",
+		"-p", "ActiveState", "-p", "SubState", "-p", "Result", "-p", "NRestarts",
+		"-p", "ExecMainStartTimestampMonotonic", "-p", "ExecMainStatus",
+		platform.GetSystemdServiceName())
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service state: %w", err)
+	}
+
+	properties := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			properties[key] = value
+		}
+	}
+
+	health := &Health{
+		ActiveState: properties["ActiveState"],
+		SubState:    properties["SubState"],
+		Result:      properties["Result"],
+	}
+	health.Restarts, _ = strconv.Atoi(properties["NRestarts"])
+	health.LastExitCode, _ = strconv.Atoi(properties["ExecMainStatus"])
+
+	// Monotonic timestamps are microseconds since boot; convert using uptime
+	if usec, err := strconv.ParseInt(properties["ExecMainStartTimestampMonotonic"], 10, 64); err == nil && usec > 0 {
+		if uptime, err := platform.Uptime(); err == nil {
+			health.MainStartedAt = time.Now().Add(-uptime).Add(time.Duration(usec) * time.Microsecond)
+		}
+	}
+
+	return health, nil
+}
+
+// showProperty returns a single unit property as reported by systemctl show
+func (m *Manager) showProperty(ctx context.Context, name string) (string, error) {
+	cmd := exec.CommandContext(ctx, "systemctl", "show", "-p", name, "--value", platform.GetSystemdServiceName())
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read service property %s: %w", name, err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Render returns the unit file content this CLI version would install
+func (m *Manager) Render() (string, error) {
+	return m.generateServiceFile()
+}
+
 // generateServiceFile generates the systemd service file content
 func (m *Manager) generateServiceFile() (string, error) {
 	binaryPath := m.platform.GetBinaryPath()
@@ -179,16 +330,32 @@ func (m *Manager) generateServiceFile() (string, error) {
 
 	tmpl := `[Unit]
 Description=Fixpanic Agent
-After=network.target
+After={{ join .After }}
+{{- if .Wants }}
+Wants={{ join .Wants }}
+{{- end }}
+{{- if .Requires }}
+Requires={{ join .Requires }}
+{{- end }}
+ConditionPathExists=!{{ .StopMarkerPath }}
 
 [Service]
 Type=simple
 User={{ .User }}
+{{- range .Environment }}
+Environment={{ . }}
+{{- end }}
+{{- if .EnvironmentFile }}
+EnvironmentFile={{ .EnvironmentFile }}
+{{- end }}
 ExecStart={{ .BinaryPath }} --config {{ .ConfigPath }}
 Restart=always
 RestartSec=10
 StandardOutput=journal
 StandardError=journal
+{{- range .Confinement }}
+{{ . }}
+{{- end }}
 
 [Install]
 WantedBy=multi-user.target
@@ -204,17 +371,47 @@ WantedBy=multi-user.target
 		user = "root"
 	}
 
+	// Dependencies and environment come from the service section of the agent
+	// config, which doesn't exist yet when the unit is rendered for a fresh
+	// install plan
+	var dependencies config.ServiceSection
+	if agentConfig, err := config.LoadConfig(configPath); err == nil {
+		dependencies = agentConfig.Service
+	}
+	after, wants := unitDependencies(dependencies)
+
 	data := struct {
-		User       string
-		BinaryPath string
-		ConfigPath string
+		User            string
+		BinaryPath      string
+		ConfigPath      string
+		After           []string
+		Wants           []string
+		Requires        []string
+		Environment     []string
+		EnvironmentFile string
+		Confinement     []string
+		StopMarkerPath  string
 	}{
-		User:       user,
-		BinaryPath: binaryPath,
-		ConfigPath: configPath,
+		User:            user,
+		BinaryPath:      binaryPath,
+		ConfigPath:      configPath,
+		After:           after,
+		Wants:           wants,
+		Requires:        dependencies.Requires,
+		Environment:     unitEnvironment(dependencies.Environment),
+		EnvironmentFile: strings.ReplaceAll(dependencies.EnvironmentFile, "%", "%%"),
+		// systemd doesn't start the agent during an emergency stop
+		StopMarkerPath: m.platform.GetPanicStopPath(),
+	}
+	if dependencies.Confine {
+		data.Confinement = confinementDirectives
+		if AppArmorEnabled() {
+			data.Confinement = append(data.Confinement, "AppArmorProfile="+AppArmorProfileName)
+		}
 	}
 
-	t, err := template.New("service").Parse(tmpl)
+	funcs := template.FuncMap{"join": func(units []string) string { return strings.Join(units, " ") }}
+	t, err := template.New("service").Funcs(funcs).Parse(tmpl)
 	if err != nil {
 		return "", err
 	}
@@ -227,9 +424,58 @@ WantedBy=multi-user.target
 	return result.String(), nil
 }
 
+// unitDependencies returns the After= and Wants= units of the service. The
+// agent starts after network.target and every configured unit, since Wants=
+// and Requires= alone don't order anything, and network-online.target is
+// pulled in when the agent waits for it, as systemd expects.
+func unitDependencies(section config.ServiceSection) (after, wants []string) {
+	after = appendUnique([]string{"network.target"}, section.After...)
+	after = appendUnique(after, section.Wants...)
+	after = appendUnique(after, section.Requires...)
+	wants = appendUnique(nil, section.Wants...)
+	for _, unit := range after {
+		if unit == "network-online.target" {
+			wants = appendUnique(wants, unit)
+		}
+	}
+	return after, wants
+}
+
+// unitEnvironment quotes each variable as a sorted Environment= value.
+// systemd doesn't expand variables there, but it does expand % specifiers.
+func unitEnvironment(variables map[string]string) []string {
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%")
+	var environment []string
+	for name, value := range variables {
+		environment = append(environment, `"`+escaper.Replace(name+"="+value)+`"`)
+	}
+	sort.Strings(environment)
+	return environment
+}
+
+// appendUnique appends the units not already in list
+func appendUnique(list []string, units ...string) []string {
+	for _, unit := range units {
+		found := false
+		for _, existing := range list {
+			found = found || existing == unit
+		}
+		if !found {
+			list = append(list, unit)
+		}
+	}
+	return list
+}
+
+// Reload makes systemd pick up unit files changed outside of Install and
+// Uninstall
+func (m *Manager) Reload(ctx context.Context) error {
+	return m.reloadSystemd(ctx)
+}
+
 // reloadSystemd reloads the systemd daemon
-func (m *Manager) reloadSystemd() error {
-	cmd := exec.Command("systemctl", "daemon-reload")
+func (m *Manager) reloadSystemd(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "systemctl", "daemon-reload")
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to reload systemd daemon: %w", err)
 	}
@@ -237,13 +483,13 @@ func (m *Manager) reloadSystemd() error {
 }
 
 // GetServiceLogs returns the service logs
-func (m *Manager) GetServiceLogs(lines int) (string, error) {
+func (m *Manager) GetServiceLogs(ctx context.Context, lines int) (string, error) {
 	if !platform.IsSystemdAvailable() {
 		return "", fmt.Errorf("systemd is not available on this system")
 	}
 
 	args := []string{"journalctl", "-u", platform.GetSystemdServiceName(), "-n", fmt.Sprintf("%d", lines), "--no-pager"}
-	cmd := exec.Command(args[0], args[1:]...)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get service logs: %w", err)
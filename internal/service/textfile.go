@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// TextfileUnit is the name of the systemd service and timer refreshing the
+// node_exporter textfile with the agent's metrics
+const TextfileUnit = "fixpanic-textfile"
+
+// TextfileCronPath is the cron entry refreshing the textfile on hosts without
+// systemd
+const TextfileCronPath = "/etc/cron.d/fixpanic-textfile"
+
+// textfileJob refreshes the node_exporter textfile
+var textfileJob = scheduledJob{
+	unit:        TextfileUnit,
+	cronPath:    TextfileCronPath,
+	description: "Fixpanic agent metrics textfile refresh",
+	installedBy: "fixpanic agent metrics textfile install",
+}
+
+// TextfileUnitPaths returns the unit files of the textfile refresh
+func TextfileUnitPaths() []string {
+	return textfileJob.unitPaths()
+}
+
+// InstallTextfileRefresh runs command every interval to refresh the textfile,
+// with a systemd timer where available and a cron entry otherwise
+func (m *Manager) InstallTextfileRefresh(ctx context.Context, command []string, interval time.Duration) error {
+	return m.installJob(ctx, textfileJob, command, interval)
+}
+
+// UninstallTextfileRefresh removes the textfile refresh. It returns false if
+// none was installed.
+func (m *Manager) UninstallTextfileRefresh(ctx context.Context) (bool, error) {
+	return m.uninstallJob(ctx, textfileJob)
+}
@@ -0,0 +1,286 @@
+// Package state keeps what the CLI remembers between runs, such as install
+// records and the version history, in a single versioned JSON file shared by
+// all commands
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/lock"
+)
+
+// FileName is the name of the state file inside the state directory
+const FileName = "state.json"
+
+// SchemaVersion is the version of the state file written by this CLI
+const SchemaVersion = 4
+
+// MaxHistory is how many version changes are kept; older ones are dropped
+const MaxHistory = 1000
+
+// MaxHealthChecks is how many health check results are kept
+const MaxHealthChecks = 100
+
+// Components whose version changes are recorded
+const (
+	ComponentAgent = "agent"
+	ComponentCLI   = "cli"
+)
+
+// Results of a version change
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// DefaultLockWait is how long Update waits for another process updating the state
+const DefaultLockWait = 10 * time.Second
+
+// ErrNewerSchema is returned for state written by a newer CLI, which this one
+// must not overwrite
+var ErrNewerSchema = errors.New("state was written by a newer version of fixpanic")
+
+// State is everything the CLI remembers between runs
+type State struct {
+	Schema             int                 `json:"schema"`
+	Installs           []InstallRecord     `json:"installs,omitempty"`
+	History            []VersionChange     `json:"history,omitempty"`
+	MaintenanceWindows []MaintenanceWindow `json:"maintenance_windows,omitempty"`
+	HealthChecks       []HealthCheck       `json:"health_checks,omitempty"`
+	SelfHeal           SelfHeal            `json:"self_heal"`
+	// LastUpdateCheck is when a newer CLI release was last looked for
+	LastUpdateCheck time.Time `json:"last_update_check"`
+	// PackageTakeover is set once the CLI took over upgrading an agent
+	// binary installed by a distribution package
+	PackageTakeover *PackageTakeover `json:"package_takeover,omitempty"`
+}
+
+// InstallRecord describes one installation of the agent
+type InstallRecord struct {
+	Time         time.Time `json:"time"`
+	AgentVersion string    `json:"agent_version,omitempty"`
+	CLIVersion   string    `json:"cli_version,omitempty"`
+	Profile      string    `json:"profile,omitempty"`
+	User         string    `json:"user,omitempty"`
+}
+
+// VersionChange records the agent or the CLI changing from one version to another
+type VersionChange struct {
+	Time time.Time `json:"time"`
+	// Component is "agent" or "cli"
+	Component string `json:"component"`
+	From      string `json:"from,omitempty"`
+	To        string `json:"to"`
+	// Initiator is the user or process that made the change
+	Initiator string `json:"initiator,omitempty"`
+	Result    string `json:"result"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthCheck is the result of a scheduled or manual health check
+type HealthCheck struct {
+	Time      time.Time `json:"time"`
+	Healthy   bool      `json:"healthy"`
+	Installed bool      `json:"installed"`
+	Running   bool      `json:"running"`
+	PID       int       `json:"pid,omitempty"`
+	Version   string    `json:"version,omitempty"`
+	// SocketServer is the endpoint probed for connectivity
+	SocketServer string   `json:"socket_server,omitempty"`
+	Connected    bool     `json:"connected"`
+	Problems     []string `json:"problems,omitempty"`
+}
+
+// PackageTakeover records 'fixpanic agent upgrade --takeover' adopting an
+// agent binary that belongs to a distribution package
+type PackageTakeover struct {
+	Time time.Time `json:"time"`
+	// Manager is "dpkg" or "rpm"
+	Manager string `json:"manager"`
+	Package string `json:"package"`
+	User    string `json:"user,omitempty"`
+}
+
+// SelfHeal tracks the restarts made by self-healing since the agent was last
+// found healthy
+type SelfHeal struct {
+	Attempts    int       `json:"attempts"`
+	LastAttempt time.Time `json:"last_attempt"`
+	// GaveUp is set once all attempts were used, so that is reported once
+	GaveUp bool `json:"gave_up,omitempty"`
+}
+
+// MaintenanceWindow is a period in which disruptive operations are expected
+type MaintenanceWindow struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// Active reports whether t falls in the window
+func (w MaintenanceWindow) Active(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// LastInstall returns the most recent install record, or nil if none
+func (s *State) LastInstall() *InstallRecord {
+	if len(s.Installs) == 0 {
+		return nil
+	}
+	return &s.Installs[len(s.Installs)-1]
+}
+
+// AddVersionChange appends change to the history, dropping the oldest
+// changes beyond MaxHistory
+func (s *State) AddVersionChange(change VersionChange) {
+	s.History = append(s.History, change)
+	if len(s.History) > MaxHistory {
+		s.History = s.History[len(s.History)-MaxHistory:]
+	}
+}
+
+// LastVersionChange returns the most recent successful change of component,
+// or nil if none
+func (s *State) LastVersionChange(component string) *VersionChange {
+	for i := len(s.History) - 1; i >= 0; i-- {
+		if s.History[i].Component == component && s.History[i].Result == ResultSuccess {
+			return &s.History[i]
+		}
+	}
+	return nil
+}
+
+// AddHealthCheck appends result, dropping the oldest results beyond
+// MaxHealthChecks
+func (s *State) AddHealthCheck(result HealthCheck) {
+	s.HealthChecks = append(s.HealthChecks, result)
+	if len(s.HealthChecks) > MaxHealthChecks {
+		s.HealthChecks = s.HealthChecks[len(s.HealthChecks)-MaxHealthChecks:]
+	}
+}
+
+// FailingChecks returns how many of the most recent health checks in a row
+// found the agent unhealthy
+func (s *State) FailingChecks() int {
+	count := 0
+	for i := len(s.HealthChecks) - 1; i >= 0 && !s.HealthChecks[i].Healthy; i-- {
+		count++
+	}
+	return count
+}
+
+// Initiator returns who is making a change: the invoking user when run
+// through sudo, the current user otherwise
+func Initiator() string {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		return sudoUser
+	}
+	if currentUser, err := user.Current(); err == nil {
+		return currentUser.Username
+	}
+	return ""
+}
+
+// Store reads and updates the state file
+type Store struct {
+	Path string
+	// LockWait is how long Update waits for another process updating the state
+	LockWait time.Duration
+}
+
+// NewStore returns a store keeping its state in dir
+func NewStore(dir string) *Store {
+	return &Store{Path: filepath.Join(dir, FileName), LockWait: DefaultLockWait}
+}
+
+// Load returns the saved state, or an empty state if none was saved yet.
+// Updates replace the file atomically, so loading needs no lock.
+func (s *Store) Load() (*State, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &State{Schema: SchemaVersion}, nil
+		}
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state %s: %w", s.Path, err)
+	}
+	if err := migrate(&state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// Update applies fn to the saved state and saves the result, holding a lock
+// so concurrent updates from other processes are not lost. Nothing is saved
+// if fn returns an error.
+func (s *Store) Update(ctx context.Context, fn func(*State) error) error {
+	owner := fmt.Sprintf("PID %d: fixpanic state update", os.Getpid())
+	held, err := lock.Acquire(ctx, s.Path+".lock", s.LockWait, owner)
+	if err != nil {
+		return fmt.Errorf("failed to lock state: %w", err)
+	}
+	defer held.Release()
+
+	state, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(state); err != nil {
+		return err
+	}
+	state.Schema = SchemaVersion
+	return s.save(state)
+}
+
+// save writes state to a temporary file and renames it over the state file
+func (s *Store) save(state *State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), FileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return nil
+}
+
+// migrate upgrades state loaded from an older schema in place
+func migrate(state *State) error {
+	if state.Schema > SchemaVersion {
+		return fmt.Errorf("%w (schema %d, this version supports %d)", ErrNewerSchema, state.Schema, SchemaVersion)
+	}
+	// Schema 0 is a file written before the schema field existed; it has
+	// the same layout as schema 1. Schemas 2, 3 and 4 only added health
+	// checks, self-healing and package takeovers.
+	state.Schema = SchemaVersion
+	return nil
+}
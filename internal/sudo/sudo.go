@@ -0,0 +1,69 @@
+// Package sudo runs the CLI again under sudo for commands that need root
+package sudo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// ErrUnavailable is returned when sudo can't be used on this system
+var ErrUnavailable = errors.New("sudo is not available")
+
+// envPrefix marks the variables of the CLI, which are kept under sudo so the
+// command sees the same settings, e.g. $FIXPANIC_HOME
+const envPrefix = "FIXPANIC_"
+
+// Available reports whether the CLI can be run again under sudo
+func Available() bool {
+	if !supported {
+		return false
+	}
+	_, err := exec.LookPath("sudo")
+	return err == nil
+}
+
+// Reexec replaces this process with the CLI run under sudo with args, the
+// command line without the program name. The CLI's $FIXPANIC_* variables are
+// kept; sudo resets the rest of the environment as configured. Reexec only
+// returns on failure.
+func Reexec(args []string) error {
+	if !supported {
+		return ErrUnavailable
+	}
+	sudoPath, err := exec.LookPath("sudo")
+	if err != nil {
+		return ErrUnavailable
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the fixpanic binary: %w", err)
+	}
+
+	argv := []string{"sudo"}
+	if preserved := cliVariables(); len(preserved) > 0 {
+		argv = append(argv, "--preserve-env="+strings.Join(preserved, ","))
+	}
+	argv = append(argv, "--", executable)
+	argv = append(argv, args...)
+	if err := execve(sudoPath, argv, os.Environ()); err != nil {
+		return fmt.Errorf("failed to run sudo: %w", err)
+	}
+	return nil
+}
+
+// cliVariables returns the names of the CLI's variables set in the environment
+func cliVariables() []string {
+	var names []string
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		if strings.HasPrefix(name, envPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
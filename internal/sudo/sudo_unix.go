@@ -0,0 +1,14 @@
+//go:build linux || darwin || freebsd || openbsd || netbsd
+// +build linux darwin freebsd openbsd netbsd
+
+package sudo
+
+import "syscall"
+
+// supported reports whether sudo exists on this platform
+const supported = true
+
+// execve replaces the process image
+func execve(path string, argv, env []string) error {
+	return syscall.Exec(path, argv, env)
+}
@@ -0,0 +1,12 @@
+//go:build windows
+// +build windows
+
+package sudo
+
+// supported reports whether sudo exists on this platform
+const supported = false
+
+// execve is never called on Windows, which has no sudo
+func execve(path string, argv, env []string) error {
+	return ErrUnavailable
+}
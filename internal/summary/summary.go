@@ -0,0 +1,122 @@
+// Package summary records the steps of a multi-step command such as an
+// install, with their outcome, duration and the files they touched, so the
+// command can end with a summary showing at a glance what was done, skipped
+// or failed
+package summary
+
+import (
+	"sync"
+	"time"
+)
+
+// Status is the outcome of a step
+type Status string
+
+// Step outcomes
+const (
+	OK      Status = "ok"
+	Warning Status = "warning"
+	Skipped Status = "skipped"
+	Failed  Status = "failed"
+)
+
+// Step is a step of the command, or an action within one recorded with
+// Record
+type Step struct {
+	// Number is the step's number, 0 for actions within a step
+	Number     int    `json:"step,omitempty"`
+	Name       string `json:"name"`
+	Status     Status `json:"status"`
+	DurationMS int64  `json:"duration_ms"`
+	// Artifacts are the files the step created, changed or removed
+	Artifacts []string `json:"artifacts,omitempty"`
+	// Note explains a warning, skip or failure
+	Note string `json:"note,omitempty"`
+
+	started time.Time
+}
+
+var (
+	mu    sync.Mutex
+	steps []*Step
+	// current is the step in progress, nil if none
+	current *Step
+)
+
+// StartStep ends the step in progress, if any, and starts a new one
+func StartStep(number int, name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	endLocked(nil)
+	current = &Step{Number: number, Name: name, Status: OK, started: time.Now()}
+	steps = append(steps, current)
+}
+
+// Warn marks the step in progress as completed with a warning. The first
+// warning becomes the step's note.
+func Warn(message string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current == nil || current.Status != OK {
+		return
+	}
+	current.Status, current.Note = Warning, message
+}
+
+// Skip marks the step in progress as skipped for reason
+func Skip(reason string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current == nil {
+		return
+	}
+	current.Status, current.Note = Skipped, reason
+}
+
+// Touch records files the step in progress created, changed or removed
+func Touch(paths ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current == nil {
+		return
+	}
+	current.Artifacts = append(current.Artifacts, paths...)
+}
+
+// Record adds an action that finished with status as its own entry, for
+// optional parts of a step like enabling a service. A failed or warning
+// action also marks the step in progress as completed with a warning.
+func Record(name string, status Status, note string, artifacts ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	steps = append(steps, &Step{Name: name, Status: status, Note: note, Artifacts: artifacts})
+	if current != nil && current.Status == OK && (status == Failed || status == Warning) {
+		current.Status, current.Note = Warning, name+": "+string(status)
+	}
+}
+
+// Finish ends the step in progress, as failed if err is not nil, and
+// returns every step recorded
+func Finish(err error) []Step {
+	mu.Lock()
+	defer mu.Unlock()
+	endLocked(err)
+
+	recorded := make([]Step, len(steps))
+	for i, step := range steps {
+		recorded[i] = *step
+	}
+	return recorded
+}
+
+// endLocked ends the step in progress
+func endLocked(err error) {
+	if current == nil {
+		return
+	}
+	current.DurationMS = time.Since(current.started).Milliseconds()
+	if err != nil {
+		current.Status, current.Note = Failed, err.Error()
+	}
+	current = nil
+}
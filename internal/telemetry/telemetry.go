@@ -0,0 +1,304 @@
+// Package telemetry collects opt-in, anonymous CLI usage events. Events are
+// queued in a local JSON lines file and sent in batches over HTTPS; nothing is
+// recorded or sent until the user enables telemetry.
+package telemetry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint receives telemetry batches
+const DefaultEndpoint = "https://telemetry.fixpanic.com/v1/cli-events"
+
+// Environment variables controlling telemetry
+const (
+	// EnvTelemetry disables telemetry when set to a false value, regardless of consent
+	EnvTelemetry = "FIXPANIC_TELEMETRY"
+	// EnvEndpoint overrides DefaultEndpoint
+	EnvEndpoint = "FIXPANIC_TELEMETRY_URL"
+	// EnvDoNotTrack is the cross-tool opt-out convention (https://consoledonottrack.com)
+	EnvDoNotTrack = "DO_NOT_TRACK"
+)
+
+const (
+	// batchSize is how many queued events trigger a flush
+	batchSize = 20
+	// maxBatchAge is how long the oldest queued event may wait for a flush
+	maxBatchAge = 24 * time.Hour
+	// maxQueued caps the queue while the endpoint is unreachable; the oldest
+	// events are dropped first
+	maxQueued = 1000
+	// flushTimeout keeps a flush from noticeably delaying the command
+	flushTimeout = 2 * time.Second
+)
+
+// Event is a single anonymous usage record. It deliberately contains no
+// arguments, paths, hostnames or error messages.
+type Event struct {
+	Time       time.Time `json:"time"`
+	InstallID  string    `json:"install_id"`
+	Command    string    `json:"command"`
+	Result     string    `json:"result"`
+	DurationMS int64     `json:"duration_ms"`
+	OS         string    `json:"os"`
+	Arch       string    `json:"arch"`
+	CLIVersion string    `json:"cli_version"`
+	GoVersion  string    `json:"go_version"`
+}
+
+// State is the user's telemetry decision
+type State struct {
+	Enabled bool `json:"enabled"`
+	// InstallID is a random identifier that lets events from one machine be
+	// grouped without identifying it
+	InstallID string    `json:"install_id,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LegacyStateFile is the file in the user's fixpanic configuration directory
+// earlier versions kept the state in, before it moved to the CLI
+// configuration
+const LegacyStateFile = "telemetry.json"
+
+// StateStore persists the telemetry state, e.g. in the CLI configuration.
+// Without a saved decision LoadState returns a disabled state.
+type StateStore interface {
+	LoadState() (*State, error)
+	SaveState(*State) error
+}
+
+// Store keeps the event queue on disk and the telemetry state in its
+// StateStore
+type Store struct {
+	StateStore StateStore
+	QueuePath  string
+	Endpoint   string
+	Client     *http.Client
+}
+
+// NewStore returns a store keeping its state in states and its queue in
+// cacheDir
+func NewStore(states StateStore, cacheDir string) *Store {
+	endpoint := os.Getenv(EnvEndpoint)
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	return &Store{
+		StateStore: states,
+		QueuePath:  filepath.Join(cacheDir, "telemetry-queue.jsonl"),
+		Endpoint:   endpoint,
+		Client:     &http.Client{Timeout: flushTimeout},
+	}
+}
+
+// DisabledByEnvironment reports whether the environment forbids telemetry
+func DisabledByEnvironment() bool {
+	if value := os.Getenv(EnvDoNotTrack); value != "" && value != "0" {
+		return true
+	}
+	if value := os.Getenv(EnvTelemetry); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		return err != nil || !enabled // unparseable values opt out
+	}
+	return false
+}
+
+// LoadState returns the saved state. Without a saved decision telemetry is off.
+func (s *Store) LoadState() (*State, error) {
+	return s.StateStore.LoadState()
+}
+
+// ReadLegacyState returns the state an earlier version saved at path, or nil
+// if there is none
+func ReadLegacyState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read telemetry state: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse telemetry state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// Enabled reports whether events may be recorded
+func (s *Store) Enabled() bool {
+	if DisabledByEnvironment() {
+		return false
+	}
+	state, err := s.LoadState()
+	return err == nil && state.Enabled
+}
+
+// SetEnabled records the user's decision. Enabling generates an install ID;
+// disabling forgets it and drops queued events.
+func (s *Store) SetEnabled(enabled bool) (*State, error) {
+	state := &State{Enabled: enabled, UpdatedAt: time.Now().UTC()}
+	if enabled {
+		current, err := s.LoadState()
+		if err == nil && current.InstallID != "" {
+			state.InstallID = current.InstallID
+		} else {
+			id, err := newInstallID()
+			if err != nil {
+				return nil, err
+			}
+			state.InstallID = id
+		}
+	} else if err := os.Remove(s.QueuePath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove telemetry queue: %w", err)
+	}
+
+	if err := s.StateStore.SaveState(state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// newInstallID returns a random 128-bit identifier
+func newInstallID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate install ID: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Enqueue appends an event to the local queue
+func (s *Store) Enqueue(event Event) error {
+	if err := os.MkdirAll(filepath.Dir(s.QueuePath), 0755); err != nil {
+		return fmt.Errorf("failed to create telemetry queue directory: %w", err)
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry event: %w", err)
+	}
+
+	file, err := os.OpenFile(s.QueuePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open telemetry queue: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write telemetry queue: %w", err)
+	}
+	return nil
+}
+
+// Pending returns the queued events. Malformed lines are skipped.
+func (s *Store) Pending() ([]Event, error) {
+	file, err := os.Open(s.QueuePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open telemetry queue: %w", err)
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+// ShouldFlush reports whether the queued events are due to be sent
+func ShouldFlush(events []Event, now time.Time) bool {
+	if len(events) == 0 {
+		return false
+	}
+	return len(events) >= batchSize || now.Sub(events[0].Time) >= maxBatchAge
+}
+
+// Flush sends the queued events when a batch is due and empties the queue on
+// success. Undelivered events stay queued, capped at maxQueued.
+func (s *Store) Flush(ctx context.Context, now time.Time) error {
+	events, err := s.Pending()
+	if err != nil || !ShouldFlush(events, now) {
+		return err
+	}
+
+	if err := s.send(ctx, events); err != nil {
+		if len(events) > maxQueued {
+			return s.rewrite(events[len(events)-maxQueued:])
+		}
+		return err
+	}
+	return s.rewrite(nil)
+}
+
+// send posts a batch of events to the endpoint
+func (s *Store) send(ctx context.Context, events []Event) error {
+	body, err := json.Marshal(map[string]interface{}{"events": events})
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry batch: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, flushTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send telemetry: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// rewrite replaces the queue with events
+func (s *Store) rewrite(events []Event) error {
+	if len(events) == 0 {
+		if err := os.Remove(s.QueuePath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear telemetry queue: %w", err)
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode telemetry event: %w", err)
+		}
+		buf.Write(append(data, '\n'))
+	}
+	return os.WriteFile(s.QueuePath, buf.Bytes(), 0600)
+}
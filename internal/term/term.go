@@ -0,0 +1,10 @@
+// Package term reports whether file descriptors are attached to a terminal.
+package term
+
+import "os"
+
+// IsTerminal reports whether f is a terminal. Character devices such as
+// /dev/null are not terminals.
+func IsTerminal(f *os.File) bool {
+	return isTerminal(f.Fd())
+}
@@ -0,0 +1,12 @@
+//go:build darwin || freebsd
+// +build darwin freebsd
+
+package term
+
+import "golang.org/x/sys/unix"
+
+// isTerminal checks for terminal attributes with the TIOCGETA ioctl
+func isTerminal(fd uintptr) bool {
+	_, err := unix.IoctlGetTermios(int(fd), unix.TIOCGETA)
+	return err == nil
+}
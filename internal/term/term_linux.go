@@ -0,0 +1,12 @@
+//go:build linux
+// +build linux
+
+package term
+
+import "golang.org/x/sys/unix"
+
+// isTerminal checks for terminal attributes with the TCGETS ioctl
+func isTerminal(fd uintptr) bool {
+	_, err := unix.IoctlGetTermios(int(fd), unix.TCGETS)
+	return err == nil
+}
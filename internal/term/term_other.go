@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package term
+
+// isTerminal is not implemented on this platform; prompts are skipped
+func isTerminal(fd uintptr) bool {
+	return false
+}
@@ -0,0 +1,124 @@
+// Package textdiff computes line-based differences between two texts, e.g. a
+// file on disk and the content the CLI would generate for it.
+package textdiff
+
+import "strings"
+
+// Kind classifies a line of a diff
+type Kind int
+
+const (
+	// Equal lines are present in both texts
+	Equal Kind = iota
+	// Delete lines are only present in the old text
+	Delete
+	// Insert lines are only present in the new text
+	Insert
+)
+
+// Line is a single line of a diff
+type Line struct {
+	Kind Kind
+	Text string
+}
+
+// Lines returns the edit script turning old into new, line by line. It's based
+// on the longest common subsequence, which is plenty for config-sized files.
+func Lines(old, new string) []Line {
+	a := splitLines(old)
+	b := splitLines(new)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []Line
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, Line{Kind: Equal, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Kind: Delete, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, Line{Kind: Insert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, Line{Kind: Delete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, Line{Kind: Insert, Text: b[j]})
+	}
+
+	return lines
+}
+
+// Changed reports whether the diff contains any insertion or deletion
+func Changed(lines []Line) bool {
+	for _, line := range lines {
+		if line.Kind != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// Hunks groups the changed lines of a diff together with up to context
+// unchanged lines around them, dropping the unchanged lines in between
+func Hunks(lines []Line, context int) [][]Line {
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if line.Kind == Equal {
+			continue
+		}
+		for k := i - context; k <= i+context; k++ {
+			if k >= 0 && k < len(lines) {
+				keep[k] = true
+			}
+		}
+	}
+
+	var hunks [][]Line
+	var current []Line
+	for i, line := range lines {
+		if keep[i] {
+			current = append(current, line)
+			continue
+		}
+		if current != nil {
+			hunks = append(hunks, current)
+			current = nil
+		}
+	}
+	if current != nil {
+		hunks = append(hunks, current)
+	}
+
+	return hunks
+}
+
+// splitLines splits text into lines without their terminating newlines
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
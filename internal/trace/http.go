@@ -0,0 +1,155 @@
+package trace
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Transport wraps base, http.DefaultTransport if nil, so requests made
+// through it are recorded with their DNS, connect, TLS and time to first
+// byte while tracing is enabled. The span of a request ends once its body
+// was read or closed, so it includes the transfer.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Only the host and path: query strings can carry credentials
+	span := Start(req.Method+" "+req.URL.Host, KindHTTP, map[string]string{
+		"http.method": req.Method,
+		"http.url":    req.URL.Scheme + "://" + req.URL.Host + req.URL.Path,
+	})
+	if span == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	phases := &phases{span: span, started: time.Now()}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), phases.clientTrace()))
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		phases.end()
+		span.End(err)
+		return nil, err
+	}
+	span.SetAttribute("http.status_code", strconv.Itoa(resp.StatusCode))
+	resp.Body = &tracedBody{ReadCloser: resp.Body, span: span, phases: phases}
+	return resp, nil
+}
+
+// phases records the phases of a request as child spans of its span
+type phases struct {
+	span    *Span
+	started time.Time
+
+	mu       sync.Mutex
+	dns      *Span
+	connect  *Span
+	tls      *Span
+	gotFirst bool
+}
+
+func (p *phases) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			p.start(&p.dns, "dns "+info.Host)
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			p.done(&p.dns, info.Err)
+		},
+		ConnectStart: func(network, addr string) {
+			p.start(&p.connect, "connect "+addr)
+		},
+		ConnectDone: func(network, addr string, err error) {
+			p.done(&p.connect, err)
+		},
+		TLSHandshakeStart: func() {
+			p.start(&p.tls, "tls")
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			p.done(&p.tls, err)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			p.span.SetAttribute("net.peer.addr", info.Conn.RemoteAddr().String())
+			p.span.SetAttribute("http.reused_connection", strconv.FormatBool(info.Reused))
+		},
+		GotFirstResponseByte: func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if !p.gotFirst {
+				p.gotFirst = true
+				p.span.SetAttribute("http.time_to_first_byte_ms", milliseconds(time.Since(p.started)))
+			}
+		},
+	}
+}
+
+func (p *phases) start(phase **Span, name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	*phase = StartChild(p.span, name, KindPhase, time.Now())
+}
+
+func (p *phases) done(phase **Span, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	(*phase).EndAt(time.Now(), err)
+}
+
+// end ends phases cut short by a failed request
+func (p *phases) end() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, phase := range []*Span{p.dns, p.connect, p.tls} {
+		phase.End(nil)
+	}
+}
+
+// tracedBody ends the request's span at the end of the body
+type tracedBody struct {
+	io.ReadCloser
+	span   *Span
+	phases *phases
+	bytes  int64
+	once   sync.Once
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	if err == io.EOF {
+		b.end(nil)
+	} else if err != nil {
+		b.end(err)
+	}
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	b.end(nil)
+	return b.ReadCloser.Close()
+}
+
+func (b *tracedBody) end(err error) {
+	b.once.Do(func() {
+		b.phases.end()
+		b.span.SetAttribute("http.response_bytes", strconv.FormatInt(b.bytes, 10))
+		b.span.End(err)
+	})
+}
+
+// milliseconds formats d in milliseconds with microsecond precision
+func milliseconds(d time.Duration) string {
+	return strconv.FormatFloat(float64(d.Microseconds())/1000, 'f', 3, 64)
+}
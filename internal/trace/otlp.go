@@ -0,0 +1,141 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServiceName identifies the CLI's spans in the tracing backend
+const ServiceName = "fixpanic-cli"
+
+// exportTimeout keeps an unreachable collector from noticeably delaying the
+// command
+const exportTimeout = 5 * time.Second
+
+// OTLP/HTTP JSON encoding, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              int             `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            otlpStatus      `json:"status"`
+	}
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue string `json:"stringValue"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+)
+
+// OTLP span kinds and status codes
+const (
+	otlpKindInternal = 1
+	otlpKindClient   = 3
+	otlpStatusError  = 2
+)
+
+// export sends spans to the collector at url, appending the traces path
+// unless url already has one
+func export(url string, spans []*Span) error {
+	url = strings.TrimRight(url, "/")
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url += "/v1/traces"
+	}
+
+	converted := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		converted[i] = toOTLP(span)
+	}
+	hostname, _ := os.Hostname()
+	body, err := json.Marshal(otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpAttribute{
+			attribute("service.name", ServiceName),
+			attribute("host.name", hostname),
+		}},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: ServiceName}, Spans: converted}},
+	}}})
+	if err != nil {
+		return fmt.Errorf("failed to encode trace: %w", err)
+	}
+
+	client := &http.Client{Timeout: exportTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to export trace: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to export trace: %s returned HTTP %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+func toOTLP(span *Span) otlpSpan {
+	converted := otlpSpan{
+		TraceID:           span.TraceID,
+		SpanID:            span.SpanID,
+		ParentSpanID:      span.ParentID,
+		Name:              span.Name,
+		Kind:              otlpKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+		Attributes:        []otlpAttribute{attribute("fixpanic.span_kind", span.Kind)},
+	}
+	if span.Kind == KindHTTP {
+		converted.Kind = otlpKindClient
+	}
+	keys := make([]string, 0, len(span.Attributes))
+	for key := range span.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		converted.Attributes = append(converted.Attributes, attribute(key, span.Attributes[key]))
+	}
+	if span.Error != "" {
+		converted.Status = otlpStatus{Code: otlpStatusError, Message: span.Error}
+	}
+	return converted
+}
+
+func attribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: value}}
+}
@@ -0,0 +1,288 @@
+// Package trace records how long a command, its steps and the HTTP requests
+// they make take, down to DNS lookups, connection setup and TLS handshakes,
+// so slow installs can be pinned on the network, a mirror or the host
+// itself. Spans are written as JSON lines to a trace file and can also be
+// exported to an OpenTelemetry collector over OTLP/HTTP. Nothing is
+// recorded until a sink has been opened with OpenFile or SetEndpoint.
+package trace
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Span kinds
+const (
+	KindCommand = "command"
+	KindStep    = "step"
+	KindHTTP    = "http"
+	// KindPhase is part of an HTTP request: DNS, connect or TLS
+	KindPhase    = "phase"
+	KindDownload = "download"
+	KindService  = "service"
+	KindLock     = "lock"
+	KindHook     = "hook"
+	// KindHost is the CLI run on a fleet host over SSH
+	KindHost = "host"
+)
+
+// EnvTraceParent carries the W3C trace context of the span that started the
+// CLI, e.g. a fleet command running it over SSH, so its spans join that
+// trace
+const EnvTraceParent = "TRACEPARENT"
+
+// Span is a timed operation. Spans form a tree below the command's span.
+type Span struct {
+	TraceID    string            `json:"trace_id"`
+	SpanID     string            `json:"span_id"`
+	ParentID   string            `json:"parent_id,omitempty"`
+	Name       string            `json:"name"`
+	Kind       string            `json:"kind"`
+	Start      time.Time         `json:"start"`
+	DurationMS float64           `json:"duration_ms"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Error      string            `json:"error,omitempty"`
+
+	end   time.Time
+	ended bool
+}
+
+var (
+	mu       sync.Mutex
+	file     *os.File
+	encoder  *json.Encoder
+	endpoint string
+	traceID  string
+	// finished are the ended spans waiting for the OTLP export
+	finished []*Span
+	// root is the command's span and step the step in progress, nil if none
+	root *Span
+	step *Span
+)
+
+// OpenFile writes spans to path as JSON lines, replacing an earlier trace
+func OpenFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create trace file: %w", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	file, encoder = f, json.NewEncoder(f)
+	return nil
+}
+
+// SetEndpoint exports spans to the OTLP/HTTP collector at url when the
+// command finishes
+func SetEndpoint(url string) {
+	mu.Lock()
+	defer mu.Unlock()
+	endpoint = url
+}
+
+// Enabled reports whether spans are recorded
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return enabledLocked()
+}
+
+func enabledLocked() bool {
+	return encoder != nil || endpoint != ""
+}
+
+// StartCommand starts the span every other span of the command belongs to
+func StartCommand(name string, attributes map[string]string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabledLocked() {
+		return
+	}
+	parentTraceID, parentID, ok := parseTraceParent(os.Getenv(EnvTraceParent))
+	if traceID == "" {
+		traceID = randomID(16)
+		if ok {
+			traceID = parentTraceID
+		}
+	}
+	root = newSpanLocked(name, KindCommand, nil, attributes)
+	if ok {
+		root.ParentID = parentID
+	}
+}
+
+// StartStep ends the step in progress, if any, and starts a new one
+func StartStep(number int, name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if root == nil {
+		return
+	}
+	endLocked(step, nil)
+	step = newSpanLocked(fmt.Sprintf("%d. %s", number, name), KindStep, root, nil)
+}
+
+// Start starts a span below the step in progress, or the command if no step
+// is. It returns nil, which End accepts, when nothing is recorded.
+func Start(name, kind string, attributes map[string]string) *Span {
+	mu.Lock()
+	defer mu.Unlock()
+	if root == nil {
+		return nil
+	}
+	parent := root
+	if step != nil {
+		parent = step
+	}
+	return newSpanLocked(name, kind, parent, attributes)
+}
+
+// StartChild starts a span below parent, e.g. the phases of an HTTP request
+func StartChild(parent *Span, name, kind string, at time.Time) *Span {
+	if parent == nil {
+		return nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	span := newSpanLocked(name, kind, parent, nil)
+	span.Start = at
+	return span
+}
+
+// TraceParent returns the W3C traceparent header of the span, which
+// EnvTraceParent passes on to a CLI started below it
+func (s *Span) TraceParent() string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("00-%s-%s-01", s.TraceID, s.SpanID)
+}
+
+// parseTraceParent returns the trace and parent span IDs of a W3C
+// traceparent header
+func parseTraceParent(header string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(header, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", "", false
+	}
+	for _, id := range parts[1:3] {
+		if _, err := hex.DecodeString(id); err != nil || strings.Trim(id, "0") == "" {
+			return "", "", false
+		}
+	}
+	return strings.ToLower(parts[1]), strings.ToLower(parts[2]), true
+}
+
+// SetAttribute records key on the span
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if s.Attributes == nil {
+		s.Attributes = map[string]string{}
+	}
+	s.Attributes[key] = value
+}
+
+// End ends the span, as failed if err is not nil. Ending a span twice keeps
+// the first end.
+func (s *Span) End(err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	endLocked(s, err)
+}
+
+// EndAt is End with an explicit end time, for phases reported after the fact
+func (s *Span) EndAt(at time.Time, err error) {
+	if s == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	s.end = at
+	endLocked(s, err)
+}
+
+// Finish ends the step in progress and the command's span, as failed if err
+// is not nil, closes the trace file and exports the spans to the OTLP
+// endpoint, if any
+func Finish(err error) error {
+	mu.Lock()
+	endLocked(step, err)
+	endLocked(root, err)
+	step, root = nil, nil
+
+	var closeErr error
+	if file != nil {
+		closeErr = file.Close()
+	}
+	file, encoder = nil, nil
+	spans, url := finished, endpoint
+	finished, endpoint = nil, ""
+	mu.Unlock()
+
+	if closeErr != nil {
+		return fmt.Errorf("failed to write trace file: %w", closeErr)
+	}
+	if url != "" && len(spans) > 0 {
+		return export(url, spans)
+	}
+	return nil
+}
+
+func newSpanLocked(name, kind string, parent *Span, attributes map[string]string) *Span {
+	span := &Span{
+		TraceID:    traceID,
+		SpanID:     randomID(8),
+		Name:       name,
+		Kind:       kind,
+		Start:      time.Now(),
+		Attributes: attributes,
+	}
+	if parent != nil {
+		span.ParentID = parent.SpanID
+	}
+	return span
+}
+
+func endLocked(span *Span, err error) {
+	if span == nil || span.ended {
+		return
+	}
+	span.ended = true
+	if span.end.IsZero() {
+		span.end = time.Now()
+	}
+	span.DurationMS = float64(span.end.Sub(span.Start).Microseconds()) / 1000
+	if err != nil {
+		span.Error = err.Error()
+	}
+
+	if encoder != nil {
+		if encodeErr := encoder.Encode(span); encodeErr != nil {
+			// Keep the command going with a full disk; the trace is a
+			// diagnostic aid
+			encoder = nil
+		}
+	}
+	if endpoint != "" {
+		finished = append(finished, span)
+	}
+}
+
+// randomID returns n random bytes, hex encoded, as trace and span IDs are
+func randomID(n int) string {
+	id := make([]byte, n)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("%0*x", n*2, time.Now().UnixNano())
+	}
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,100 @@
+package watchdog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile is an io.Writer that appends to a log file and rotates it
+// once it grows beyond MaxSize, keeping at most MaxBackups old files
+// (agent.log.1 is the most recent backup).
+type RotatingFile struct {
+	Path       string
+	MaxSize    int64
+	MaxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewRotatingFile opens (or creates) path for appending
+func NewRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	r := &RotatingFile{
+		Path:       path,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write implements io.Writer
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.MaxSize > 0 && r.size+int64(len(p)) > r.MaxSize && r.size > 0 {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the underlying file
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+
+	if r.MaxBackups > 0 {
+		// Shift agent.log.N-1 -> agent.log.N, dropping the oldest
+		os.Remove(fmt.Sprintf("%s.%d", r.Path, r.MaxBackups))
+		for i := r.MaxBackups - 1; i >= 1; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", r.Path, i), fmt.Sprintf("%s.%d", r.Path, i+1))
+		}
+		if err := os.Rename(r.Path, r.Path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	} else if err := os.Truncate(r.Path, 0); err != nil {
+		return fmt.Errorf("failed to truncate log file: %w", err)
+	}
+
+	return r.open()
+}
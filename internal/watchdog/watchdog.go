@@ -0,0 +1,308 @@
+// Package watchdog implements a lightweight supervisor that keeps the agent
+// running on hosts without an init system (containers, minimal distros).
+package watchdog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/panicstop"
+	"github.com/fixpanic/fixpanic-cli/internal/process"
+)
+
+// Supervisor states reported in the status file
+const (
+	StateStarting = "starting"
+	StateRunning  = "running"
+	StateBackoff  = "backoff"
+	StateStopped  = "stopped"
+	// StateHeld is an agent held down by an emergency stop
+	StateHeld = "held"
+)
+
+// stableRunDuration is how long the agent must stay up for the restart
+// backoff to be reset
+const stableRunDuration = time.Minute
+
+// markerPollInterval is how often the supervisor looks for the emergency
+// stop marker
+const markerPollInterval = 2 * time.Second
+
+// stopGracePeriod is how long the agent gets to exit after being signalled
+const stopGracePeriod = 10 * time.Second
+
+// Config controls how the supervisor runs the agent
+type Config struct {
+	BinaryPath     string
+	Args           []string
+	LogPath        string
+	StatusPath     string
+	MaxLogSize     int64
+	MaxLogBackups  int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// StopMarkerPath is the emergency stop marker: while it exists the agent
+	// is stopped and not restarted (see package panicstop)
+	StopMarkerPath string
+}
+
+// Status is the supervisor state persisted to the status file
+type Status struct {
+	PID           int       `json:"pid"`
+	AgentPID      int       `json:"agent_pid,omitempty"`
+	State         string    `json:"state"`
+	Restarts      int       `json:"restarts"`
+	LastExit      string    `json:"last_exit,omitempty"`
+	LastExitAt    time.Time `json:"last_exit_at,omitempty"`
+	AgentStarted  time.Time `json:"agent_started,omitempty"`
+	NextRestartAt time.Time `json:"next_restart_at,omitempty"`
+	StartedAt     time.Time `json:"started_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// IsAlive reports whether the watchdog process that wrote the status is still running
+func (s *Status) IsAlive() bool {
+	if s.State == StateStopped {
+		return false
+	}
+	return process.NewProcessManager().IsProcessRunning(s.PID)
+}
+
+// IsCrashLooping reports whether the supervised agent keeps exiting shortly after start
+func (s *Status) IsCrashLooping(threshold int, window time.Duration) bool {
+	if s.Restarts < threshold {
+		return false
+	}
+	if s.State == StateBackoff {
+		return true
+	}
+	return !s.AgentStarted.IsZero() && time.Since(s.AgentStarted) < window
+}
+
+// ReadStatus loads a status file written by a supervisor
+func ReadStatus(path string) (*Status, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var status Status
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse watchdog status: %w", err)
+	}
+
+	return &status, nil
+}
+
+// Supervisor runs the agent and restarts it when it exits
+type Supervisor struct {
+	config Config
+	status Status
+	logs   *RotatingFile
+}
+
+// NewSupervisor creates a supervisor for the given configuration
+func NewSupervisor(config Config) *Supervisor {
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = time.Second
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = config.InitialBackoff
+	}
+
+	return &Supervisor{config: config}
+}
+
+// Run supervises the agent until ctx is cancelled
+func (s *Supervisor) Run(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(s.config.LogPath), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	logs, err := NewRotatingFile(s.config.LogPath, s.config.MaxLogSize, s.config.MaxLogBackups)
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+	s.logs = logs
+
+	now := time.Now()
+	s.status = Status{PID: os.Getpid(), State: StateStarting, StartedAt: now}
+	s.writeStatus()
+
+	backoff := s.config.InitialBackoff
+	for {
+		if !s.holdWhileStopped(ctx) {
+			s.status.State = StateStopped
+			s.writeStatus()
+			return nil
+		}
+
+		started := time.Now()
+		exitErr := s.runOnce(ctx)
+
+		if ctx.Err() != nil {
+			s.status.State = StateStopped
+			s.status.AgentPID = 0
+			s.writeStatus()
+			return nil
+		}
+		if s.emergencyStopped() {
+			s.status.AgentPID = 0
+			continue
+		}
+
+		// A long healthy run resets the backoff
+		if time.Since(started) >= stableRunDuration {
+			backoff = s.config.InitialBackoff
+		}
+
+		s.status.Restarts++
+		s.status.LastExit = describeExit(exitErr)
+		s.status.LastExitAt = time.Now()
+		s.status.AgentPID = 0
+		s.status.State = StateBackoff
+		s.status.NextRestartAt = time.Now().Add(backoff)
+		s.writeStatus()
+		s.logf("agent exited (%s), restarting in %v", s.status.LastExit, backoff)
+
+		select {
+		case <-ctx.Done():
+			s.status.State = StateStopped
+			s.writeStatus()
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > s.config.MaxBackoff {
+			backoff = s.config.MaxBackoff
+		}
+	}
+}
+
+// runOnce starts the agent and waits for it to exit or for ctx to be cancelled
+func (s *Supervisor) runOnce(ctx context.Context) error {
+	cmd := exec.Command(s.config.BinaryPath, s.config.Args...)
+	cmd.Stdout = s.logs
+	cmd.Stderr = s.logs
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	s.status.State = StateRunning
+	s.status.AgentPID = cmd.Process.Pid
+	s.status.AgentStarted = time.Now()
+	s.status.NextRestartAt = time.Time{}
+	s.writeStatus()
+	s.logf("agent started (PID %d)", cmd.Process.Pid)
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	ticker := time.NewTicker(markerPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			s.logf("stopping agent (PID %d)", cmd.Process.Pid)
+			stopChild(cmd.Process)
+			select {
+			case err := <-done:
+				return err
+			case <-time.After(stopGracePeriod):
+				cmd.Process.Kill()
+				return <-done
+			}
+		case <-ticker.C:
+			if s.emergencyStopped() {
+				// No grace period: the agent must lose its connections now
+				s.logf("emergency stop engaged, killing agent (PID %d)", cmd.Process.Pid)
+				cmd.Process.Kill()
+				return <-done
+			}
+		}
+	}
+}
+
+// emergencyStopped reports whether the emergency stop marker exists
+func (s *Supervisor) emergencyStopped() bool {
+	return s.config.StopMarkerPath != "" && panicstop.Engaged(s.config.StopMarkerPath)
+}
+
+// holdWhileStopped keeps the agent from starting while the emergency stop is
+// engaged. It returns false if ctx is cancelled meanwhile.
+func (s *Supervisor) holdWhileStopped(ctx context.Context) bool {
+	if !s.emergencyStopped() {
+		return true
+	}
+	s.status.State = StateHeld
+	s.status.NextRestartAt = time.Time{}
+	s.writeStatus()
+	s.logf("emergency stop engaged (%s), not starting the agent", s.config.StopMarkerPath)
+
+	ticker := time.NewTicker(markerPollInterval)
+	defer ticker.Stop()
+	for s.emergencyStopped() {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+	s.logf("emergency stop released, starting the agent")
+	return true
+}
+
+// stopChild asks the agent to shut down gracefully where the platform allows it
+func stopChild(p *os.Process) {
+	if runtime.GOOS == "windows" {
+		p.Kill()
+		return
+	}
+	if err := p.Signal(syscall.SIGTERM); err != nil {
+		p.Kill()
+	}
+}
+
+// describeExit renders a process exit for the status file
+func describeExit(err error) string {
+	if err == nil {
+		return "exit status 0"
+	}
+	return err.Error()
+}
+
+// logf writes a supervisor message into the agent log
+func (s *Supervisor) logf(format string, args ...interface{}) {
+	fmt.Fprintf(s.logs, "%s [watchdog] %s\n", time.Now().Format(time.RFC3339), fmt.Sprintf(format, args...))
+}
+
+// writeStatus atomically persists the current status
+func (s *Supervisor) writeStatus() {
+	if s.config.StatusPath == "" {
+		return
+	}
+
+	s.status.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(s.status, "", "  ")
+	if err != nil {
+		return
+	}
+
+	tmpPath := s.config.StatusPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return
+	}
+	os.Rename(tmpPath, s.config.StatusPath)
+}
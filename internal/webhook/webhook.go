@@ -0,0 +1,43 @@
+// Package webhook posts JSON notifications, such as failed health checks, to
+// a user-configured URL
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// postTimeout bounds a notification so a slow receiver can't stall the caller
+const postTimeout = 10 * time.Second
+
+// Post sends payload as JSON to url
+func Post(ctx context.Context, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, postTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send notification: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Package workpool runs independent probes concurrently with a bound on how
+// many run at once, so checks of many endpoints or hosts take about as long
+// as the slowest one instead of the sum of all of them.
+package workpool
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultLimit is the number of tasks run at once unless configured otherwise
+const DefaultLimit = 8
+
+// Map calls fn for every item with at most limit calls running at once and
+// returns the results in the order of items, whatever order they complete in.
+// A limit below 1 runs one call at a time. Once ctx is done, items that
+// haven't started yet are still passed to fn with the cancelled context so
+// every result slot is filled consistently.
+func Map[T, R any](ctx context.Context, items []T, limit int, fn func(context.Context, T) R) []R {
+	results := make([]R, len(items))
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > len(items) {
+		limit = len(items)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < limit; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = fn(ctx, items[i])
+			}
+		}()
+	}
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
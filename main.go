@@ -1,10 +1,10 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/fixpanic/fixpanic-cli/cmd"
+	"github.com/fixpanic/fixpanic-cli/internal/clierror"
 )
 
 var (
@@ -16,7 +16,7 @@ var (
 func main() {
 	cmd.SetVersionInfo(version, commit, date)
 	if err := cmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		clierror.Report(os.Stderr, err)
+		os.Exit(clierror.ExitCode(err))
 	}
 }
@@ -0,0 +1,36 @@
+package controlv1
+
+import (
+	"context"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// TokenCredentials returns call credentials sending token, the API token of
+// 'fixpanic serve', with every call. They work over plaintext connections
+// too, since the server listens on localhost without TLS by default.
+func TokenCredentials(token string) credentials.PerRPCCredentials {
+	return tokenCredentials(token)
+}
+
+type tokenCredentials string
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// ActionErrorOf returns the ActionError attached to err, the error of a failed
+// Start, Stop or Upgrade call, or nil if there is none
+func ActionErrorOf(err error) *ActionError {
+	for _, detail := range status.Convert(err).Details() {
+		if actionErr, ok := detail.(*ActionError); ok {
+			return actionErr
+		}
+	}
+	return nil
+}
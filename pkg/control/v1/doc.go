@@ -0,0 +1,18 @@
+// Package controlv1 is the generated Go client, and server interface, of
+// version 1 of the gRPC control interface served by 'fixpanic serve
+// --grpc-listen'. Tools use it to manage the agent of a host without
+// running the fixpanic binary or speaking the REST API.
+//
+// The messages and the Control service are generated from
+// proto/fixpanic/control/v1/control.proto with 'make proto'; only this file
+// and credentials.go are written by hand.
+//
+//	conn, err := grpc.NewClient("127.0.0.1:7879",
+//		grpc.WithTransportCredentials(insecure.NewCredentials()),
+//		grpc.WithPerRPCCredentials(controlv1.TokenCredentials(token)))
+//	if err != nil {
+//		return err
+//	}
+//	defer conn.Close()
+//	status, err := controlv1.NewControlClient(conn).GetStatus(ctx, &controlv1.GetStatusRequest{})
+package controlv1
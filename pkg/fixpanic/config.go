@@ -0,0 +1,60 @@
+package fixpanic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+)
+
+// ConfigKeys returns the dotted configuration keys accepted by GetConfig and
+// SetConfig, e.g. "logging.level"
+func ConfigKeys() []string {
+	return config.Keys()
+}
+
+// GetConfig returns the value of a dotted key of the agent configuration, as
+// 'fixpanic config get' prints it
+func (a *Agent) GetConfig(key string) (string, error) {
+	agentConfig, err := a.loadConfig()
+	if err != nil {
+		return "", err
+	}
+	return agentConfig.Get(key)
+}
+
+// SetConfig changes a dotted key of the agent configuration and saves it
+// once the changed configuration validates. The running agent picks the
+// change up when it is next started; changes to service.* keys need the
+// service unit to be regenerated ('fixpanic agent diff --accept'). Like
+// 'fixpanic config set' it takes the CLI's lock but runs no hooks.
+func (a *Agent) SetConfig(ctx context.Context, key, value string) error {
+	return a.withLock(ctx, "config set", func() error {
+		agentConfig, err := a.loadConfig()
+		if err != nil {
+			return err
+		}
+		if err := agentConfig.Set(key, value); err != nil {
+			return err
+		}
+		if err := agentConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		if err := config.SaveConfig(agentConfig, a.platformInfo.GetConfigPath()); err != nil {
+			return fmt.Errorf("failed to save configuration: %w", err)
+		}
+		return nil
+	})
+}
+
+// loadConfig loads the agent configuration of the installation
+func (a *Agent) loadConfig() (*config.AgentConfig, error) {
+	if !a.connectivity.IsFixPanicAgentInstalled() {
+		return nil, ErrNotInstalled
+	}
+	agentConfig, err := config.LoadConfig(a.platformInfo.GetConfigPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return agentConfig, nil
+}
@@ -0,0 +1,209 @@
+// Package fixpanic is the supported Go API of the FixPanic CLI, for programs
+// such as provisioning daemons that manage the agent on the local host
+// without running the fixpanic binary.
+//
+// It covers installing, upgrading and inspecting the agent and managing its
+// configuration. Operations take the same lock as the CLI, so they never run
+// concurrently with a fixpanic command, and run the lifecycle hook scripts
+// in the hooks.d directory. They share the CLI's implementation and its
+// safeguards: read-only mode, the permission policy (both read from
+// ~/.fixpanic.yaml and FIXPANIC_* variables like the CLI does), yanked
+// releases, package-owned binaries, the emergency stop and the rollback of
+// failed installations.
+// The progress the CLI prints, and the output of the hook scripts, go to the
+// Agent's Output, which discards them unless the caller sets a writer.
+//
+// The types in this package are stable; everything else in this module is
+// internal and may change between releases.
+package fixpanic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/hooks"
+	"github.com/fixpanic/fixpanic-cli/internal/lock"
+	"github.com/fixpanic/fixpanic-cli/internal/logger"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/procfind"
+	"github.com/fixpanic/fixpanic-cli/internal/service"
+)
+
+// Errors returned by the operations, to be checked with errors.Is
+var (
+	ErrNotInstalled     = errors.New("fixpanic: agent is not installed")
+	ErrAlreadyInstalled = errors.New("fixpanic: agent is already installed")
+	ErrBusy             = errors.New("fixpanic: another fixpanic operation is in progress")
+	ErrReadOnly         = errors.New("fixpanic: the installation is read-only (cli.read_only / FIXPANIC_READ_ONLY)")
+	// ErrPanicStopped is returned while the emergency stop engaged with
+	// 'fixpanic agent panic-stop' holds the agent down
+	ErrPanicStopped = agentops.ErrPanicStopped
+)
+
+// DefaultLockWait is how long operations wait for a running fixpanic command
+// or operation by default
+const DefaultLockWait = time.Minute
+
+// Agent manages the FixPanic agent installation of this host
+type Agent struct {
+	// LockWait is how long operations wait for another fixpanic command or
+	// operation to finish before failing with ErrBusy
+	LockWait time.Duration
+	// Output receives the progress messages of the operations, the output
+	// of the hook scripts and warnings such as failed post-hooks
+	Output io.Writer
+
+	platformInfo *platform.PlatformInfo
+	connectivity *connectivity.Manager
+	settings     agentops.Settings
+}
+
+// Paths are the locations of the agent installation
+type Paths struct {
+	Binary string
+	Config string
+	LogDir string
+}
+
+// Status describes the agent installation and process
+type Status struct {
+	Installed bool
+	// Version is the installed agent version, e.g. "v1.2.3"
+	Version string
+	Running bool
+	// PID is the process ID of the running agent, or 0
+	PID int
+	// ServiceEnabled is set when the systemd service starts the agent on boot
+	ServiceEnabled bool
+	AgentID        string
+	SocketServer   string
+	LogLevel       string
+}
+
+// New returns an Agent for the installation of this host: the system-wide
+// one when running as root, the per-user one otherwise
+func New() (*Agent, error) {
+	platformInfo, err := platform.GetPlatformInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get platform info: %w", err)
+	}
+	return &Agent{
+		LockWait:     DefaultLockWait,
+		Output:       io.Discard,
+		platformInfo: platformInfo,
+		connectivity: connectivity.NewManager(platformInfo),
+		settings:     loadSettings(),
+	}, nil
+}
+
+// Paths returns the locations of the agent installation
+func (a *Agent) Paths() Paths {
+	return Paths{
+		Binary: a.platformInfo.GetFixPanicAgentBinaryPath(),
+		Config: a.platformInfo.GetConfigPath(),
+		LogDir: a.platformInfo.LogDir,
+	}
+}
+
+// Status reports the state of the agent installation and process
+func (a *Agent) Status(ctx context.Context) (*Status, error) {
+	status := &Status{Installed: a.connectivity.IsFixPanicAgentInstalled()}
+	if !status.Installed {
+		return status, nil
+	}
+
+	if output, err := a.connectivity.GetFixPanicAgentVersion(ctx); err == nil {
+		status.Version = connectivity.ParseAgentVersion(output)
+	}
+
+	if platform.IsSystemdAvailable() {
+		serviceManager := service.NewManager(a.platformInfo)
+		if state, err := serviceManager.Status(ctx); err == nil && state == "active" {
+			status.Running = true
+		}
+		status.ServiceEnabled, _ = serviceManager.IsEnabled(ctx)
+	}
+	procs, err := procfind.FindByExecutable(a.platformInfo.GetFixPanicAgentBinaryPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+	if len(procs) > 0 {
+		status.Running = true
+		status.PID = procs[0].PID
+	}
+
+	if agentConfig, err := config.LoadConfig(a.platformInfo.GetConfigPath()); err == nil {
+		status.AgentID = agentConfig.App.AgentID
+		status.SocketServer = agentConfig.GetSocketServer()
+		status.LogLevel = agentConfig.Logging.Level
+	}
+	return status, nil
+}
+
+// locked runs fn as operation under the CLI's lock, with the lifecycle hooks.
+// It fails with ErrReadOnly in read-only mode. Like the CLI, a failing
+// post-hook only warns and doesn't fail the operation.
+func (a *Agent) locked(ctx context.Context, operation string, fn func() error) error {
+	return a.withLock(ctx, operation, func() error {
+		runner := hooks.NewRunner(a.platformInfo.GetHooksDir())
+		runner.Output = a.output()
+		runner.Env = map[string]string{
+			"FIXPANIC_AGENT_BINARY": a.platformInfo.GetFixPanicAgentBinaryPath(),
+			"FIXPANIC_AGENT_CONFIG": a.platformInfo.GetConfigPath(),
+			"FIXPANIC_LOG_DIR":      a.platformInfo.LogDir,
+		}
+		if err := runner.Run(ctx, hooks.PhasePre, operation, nil); err != nil {
+			return err
+		}
+
+		opErr := fn()
+
+		result := map[string]string{"FIXPANIC_RESULT": "success"}
+		if opErr != nil {
+			result["FIXPANIC_RESULT"] = "failure"
+			result["FIXPANIC_ERROR"] = opErr.Error()
+		}
+		if err := runner.Run(context.WithoutCancel(ctx), hooks.PhasePost, operation, result); err != nil {
+			logger.Warning("%v", err)
+		}
+		return opErr
+	})
+}
+
+// withLock runs fn under the CLI's lock, sending what it prints to Output.
+// It fails with ErrReadOnly in read-only mode.
+func (a *Agent) withLock(ctx context.Context, operation string, fn func() error) error {
+	if a.settings.IsReadOnly() {
+		return ErrReadOnly
+	}
+	owner := fmt.Sprintf("PID %d: fixpanic library (%s)", os.Getpid(), operation)
+	held, err := lock.Acquire(ctx, a.platformInfo.GetLockPath(), a.LockWait, owner)
+	if errors.Is(err, lock.ErrLocked) {
+		if holder := lock.Owner(a.platformInfo.GetLockPath()); holder != "" {
+			return fmt.Errorf("%w (%s)", ErrBusy, holder)
+		}
+		return ErrBusy
+	}
+	if err != nil {
+		return err
+	}
+	defer held.Release()
+
+	defer logger.Redirect(a.output())()
+	return fn()
+}
+
+// output returns Output, or io.Discard if the caller cleared it
+func (a *Agent) output() io.Writer {
+	if a.Output == nil {
+		return io.Discard
+	}
+	return a.Output
+}
@@ -0,0 +1,97 @@
+package fixpanic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/panicstop"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+)
+
+func TestReadOnlyRefusesChanges(t *testing.T) {
+	t.Setenv(agentops.EnvReadOnly, "")
+	agent := &Agent{settings: agentops.Settings{ReadOnly: true}}
+	ctx := context.Background()
+
+	operations := map[string]func() error{
+		"Install": func() error {
+			return agent.Install(ctx, InstallOptions{AgentID: "agent_123", APIKey: "fp_test"})
+		},
+		"Upgrade": func() error {
+			_, err := agent.Upgrade(ctx, UpgradeOptions{})
+			return err
+		},
+		"Start":     func() error { return agent.Start(ctx) },
+		"Stop":      func() error { return agent.Stop(ctx) },
+		"SetConfig": func() error { return agent.SetConfig(ctx, "logging.level", "debug") },
+	}
+	for name, operation := range operations {
+		t.Run(name, func(t *testing.T) {
+			if err := operation(); !errors.Is(err, ErrReadOnly) {
+				t.Errorf("%s() error = %v, want ErrReadOnly", name, err)
+			}
+		})
+	}
+}
+
+func TestPanicStopRefusesStart(t *testing.T) {
+	t.Setenv(agentops.EnvReadOnly, "")
+	dir := t.TempDir()
+	platformInfo := &platform.PlatformInfo{ConfigDir: dir, LibDir: dir, LogDir: dir}
+	if err := os.WriteFile(platformInfo.GetFixPanicAgentBinaryPath(), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := panicstop.Engage(platformInfo.GetPanicStopPath(), panicstop.Marker{StoppedAt: time.Now(), Reason: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	agent := &Agent{platformInfo: platformInfo, connectivity: connectivity.NewManager(platformInfo)}
+	ctx := context.Background()
+
+	operations := map[string]func() error{
+		"Install": func() error {
+			return agent.Install(ctx, InstallOptions{AgentID: "agent_123", APIKey: "fp_test", Force: true})
+		},
+		"Start": func() error { return agent.Start(ctx) },
+	}
+	for name, operation := range operations {
+		t.Run(name, func(t *testing.T) {
+			if err := operation(); !errors.Is(err, ErrPanicStopped) {
+				t.Errorf("%s() error = %v, want ErrPanicStopped", name, err)
+			}
+		})
+	}
+}
+
+func TestFailingPostHookOnlyWarns(t *testing.T) {
+	if runtime.GOOS == "windows" || platform.IsSystemdAvailable() {
+		t.Skip("needs a shell and no systemd service to stop")
+	}
+	t.Setenv(agentops.EnvReadOnly, "")
+	dir := t.TempDir()
+	platformInfo := &platform.PlatformInfo{ConfigDir: dir, LibDir: dir, LogDir: dir}
+	hookDir := filepath.Join(platformInfo.GetHooksDir(), "post-stop")
+	if err := os.MkdirAll(hookDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hookDir, "10-fail.sh"), []byte("#!/bin/sh\necho hook ran\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	var output bytes.Buffer
+	agent := &Agent{platformInfo: platformInfo, connectivity: connectivity.NewManager(platformInfo), Output: &output}
+
+	if err := agent.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v, want nil despite the failing post-hook", err)
+	}
+	if !strings.Contains(output.String(), "hook ran") {
+		t.Errorf("Output = %q, want the hook's output", output.String())
+	}
+}
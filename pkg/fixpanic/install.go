@@ -0,0 +1,365 @@
+package fixpanic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/fixpanic/fixpanic-cli/internal/config"
+	"github.com/fixpanic/fixpanic-cli/internal/connectivity"
+	"github.com/fixpanic/fixpanic-cli/internal/hooks"
+	"github.com/fixpanic/fixpanic-cli/internal/plan"
+	"github.com/fixpanic/fixpanic-cli/internal/platform"
+	"github.com/fixpanic/fixpanic-cli/internal/process"
+	"github.com/fixpanic/fixpanic-cli/internal/procfind"
+	"github.com/fixpanic/fixpanic-cli/internal/releases"
+	"github.com/fixpanic/fixpanic-cli/internal/rollback"
+	"github.com/fixpanic/fixpanic-cli/internal/service"
+	"github.com/fixpanic/fixpanic-cli/internal/state"
+)
+
+// InstallOptions are the settings of a new installation, as passed to
+// 'fixpanic agent install'
+type InstallOptions struct {
+	AgentID string
+	APIKey  string
+	// SocketServer overrides the default socket server (host:port)
+	SocketServer string
+	// Profile is the configuration profile, "default" if empty
+	Profile string
+	// Force reinstalls over an existing installation
+	Force bool
+	// AgentVersion installs this agent release (e.g. "v1.4.0") instead of
+	// the latest, verified against the release's checksum manifest
+	AgentVersion string
+	// TLSCAFile is a PEM bundle of CAs the agent trusts instead of the
+	// system roots; TLSCertFile and TLSKeyFile are its client certificate
+	// and key for mutual TLS. All are absolute paths.
+	TLSCAFile   string
+	TLSCertFile string
+	TLSKeyFile  string
+	// Confine restricts the agent service with a system call filter and,
+	// where AppArmor is enabled, a profile tailored to the agent
+	Confine bool
+	// KeepPartial keeps what a failed installation changed instead of
+	// rolling it back, like 'fixpanic agent install --keep-partial'
+	KeepPartial bool
+}
+
+// UpgradeOptions are the settings of an upgrade, as passed to 'fixpanic
+// agent upgrade'
+type UpgradeOptions struct {
+	// AgentVersion upgrades, or downgrades, to this release (e.g. "v1.4.0")
+	// instead of the latest
+	AgentVersion string
+	// Force installs the release even if it is installed already
+	Force bool
+	// AllowYanked installs a release that was yanked
+	AllowYanked bool
+	// Takeover replaces a binary installed by a distribution package and
+	// manages it from then on
+	Takeover bool
+}
+
+// UpgradeResult reports the versions before and after an upgrade
+type UpgradeResult struct {
+	Previous string
+	Current  string
+}
+
+// Upgraded reports whether the upgrade changed the installed version
+func (r *UpgradeResult) Upgraded() bool {
+	return r.Previous != r.Current
+}
+
+// Install downloads the latest or requested agent, writes its configuration
+// and, where systemd is available, installs, enables and starts its service.
+// Without systemd the agent is left for Start to run. Like the CLI it applies
+// the permission policy, and a failed installation is rolled back unless
+// KeepPartial is set, leaving the host as it was. It fails with
+// ErrPanicStopped while the emergency stop is engaged.
+func (a *Agent) Install(ctx context.Context, opts InstallOptions) error {
+	if opts.AgentID == "" || opts.APIKey == "" {
+		return fmt.Errorf("fixpanic: AgentID and APIKey are required")
+	}
+	profile := opts.Profile
+	if profile == "" {
+		profile = config.DefaultProfile
+	}
+	if _, err := config.GetProfile(profile); err != nil {
+		return fmt.Errorf("fixpanic: %w", err)
+	}
+	version, err := parseVersion(opts.AgentVersion)
+	if err != nil {
+		return err
+	}
+	policy, err := a.settings.PermissionPolicy()
+	if err != nil {
+		return fmt.Errorf("fixpanic: %w", err)
+	}
+
+	return a.locked(ctx, hooks.OperationInstall, func() (err error) {
+		// A reinstall must not bring back an agent stopped in an emergency
+		if err := agentops.CheckPanicStop(a.platformInfo); err != nil {
+			return err
+		}
+		if a.connectivity.IsFixPanicAgentInstalled() && !opts.Force {
+			return ErrAlreadyInstalled
+		}
+
+		var journal rollback.Journal
+		defer journal.Commit()
+		defer func() {
+			if err == nil || opts.KeepPartial {
+				return
+			}
+			if rollbackErr := journal.Rollback(); rollbackErr != nil {
+				err = fmt.Errorf("%w; rolling back the installation failed too: %v", err, rollbackErr)
+			}
+		}()
+		if platform.IsSystemdAvailable() {
+			if err := agentops.TrackService(ctx, &journal, a.platformInfo); err != nil {
+				return err
+			}
+		}
+		if err := agentops.CreateDirectories(&journal, a.platformInfo); err != nil {
+			return err
+		}
+		if err := journal.TrackFile(a.platformInfo.GetFixPanicAgentBinaryPath()); err != nil {
+			return err
+		}
+		if _, err := agentops.InstallBinary(ctx, a.connectivity, version); err != nil {
+			return fmt.Errorf("failed to install agent binary: %w", err)
+		}
+
+		agentConfig, err := agentops.RenderConfig(a.platformInfo, plan.Inputs{
+			AgentID:      opts.AgentID,
+			Profile:      profile,
+			SocketServer: opts.SocketServer,
+			TLSCAFile:    opts.TLSCAFile,
+			TLSCertFile:  opts.TLSCertFile,
+			TLSKeyFile:   opts.TLSKeyFile,
+			Confine:      opts.Confine,
+		}, opts.APIKey)
+		if err != nil {
+			return err
+		}
+		if err := agentConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		if _, err := agentConfig.App.LoadTLSMaterial(); err != nil {
+			return fmt.Errorf("invalid TLS configuration: %w", err)
+		}
+		configPath := a.platformInfo.GetConfigPath()
+		if err := journal.TrackFile(configPath); err != nil {
+			return err
+		}
+		if _, err := agentops.PersistMigration(configPath); err != nil {
+			return fmt.Errorf("failed to migrate configuration: %w", err)
+		}
+		if err := config.SaveConfig(agentConfig, configPath); err != nil {
+			return fmt.Errorf("failed to save configuration: %w", err)
+		}
+		if err := agentops.ApplyPermissions(a.platformInfo, policy); err != nil {
+			return err
+		}
+
+		if platform.IsSystemdAvailable() {
+			serviceManager := service.NewManager(a.platformInfo)
+			journal.Undo(func() error {
+				return serviceManager.Uninstall(context.WithoutCancel(ctx))
+			})
+			// Regenerate the unit so it matches this version of the CLI
+			serviceManager.Uninstall(ctx)
+			if err := serviceManager.Install(ctx); err != nil {
+				return err
+			}
+			if err := serviceManager.Enable(ctx); err != nil {
+				return err
+			}
+			if err := serviceManager.Start(ctx); err != nil {
+				return err
+			}
+		}
+
+		// Like the CLI, a failure to record the install doesn't fail it
+		agentops.RecordInstall(ctx, a.platformInfo, "", profile)
+		return nil
+	})
+}
+
+// Upgrade replaces the agent binary with the latest or requested release,
+// stopping the agent for the replacement and starting it again afterwards.
+// Like 'fixpanic agent upgrade' it refuses yanked releases and binaries
+// owned by a distribution package unless the options allow them. While the
+// emergency stop is engaged the binary is replaced but the agent isn't
+// started again, and Upgrade fails with ErrPanicStopped if it was running.
+func (a *Agent) Upgrade(ctx context.Context, opts UpgradeOptions) (*UpgradeResult, error) {
+	version, err := parseVersion(opts.AgentVersion)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := a.settings.PermissionPolicy()
+	if err != nil {
+		return nil, fmt.Errorf("fixpanic: %w", err)
+	}
+
+	result := &UpgradeResult{}
+	err = a.locked(ctx, hooks.OperationUpgrade, func() error {
+		if !a.connectivity.IsFixPanicAgentInstalled() {
+			return ErrNotInstalled
+		}
+		if err := agentops.CheckPackageOwner(ctx, a.platformInfo, opts.Takeover); err != nil {
+			return err
+		}
+		if _, err := agentops.PersistMigration(a.platformInfo.GetConfigPath()); err != nil {
+			return fmt.Errorf("failed to migrate configuration: %w", err)
+		}
+		result.Previous = a.installedVersion(ctx)
+
+		target := version
+		if target == "" {
+			latest, err := a.connectivity.GetLatestAgentVersion(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get latest version: %w", err)
+			}
+			target = latest
+		}
+		if !opts.Force && strings.TrimPrefix(target, "v") == strings.TrimPrefix(result.Previous, "v") {
+			result.Current = result.Previous
+			return nil
+		}
+		if err := agentops.CheckNotYanked(ctx, releases.AgentRepo, target, opts.AllowYanked); err != nil {
+			return err
+		}
+
+		wasRunning, err := a.stop(ctx)
+		if err != nil {
+			return err
+		}
+		// Install the release that was checked, not whatever is latest now
+		if _, err := agentops.InstallBinary(ctx, a.connectivity, target); err != nil {
+			agentops.RecordVersionChange(ctx, a.platformInfo, state.ComponentAgent, result.Previous, target, err)
+			// Keep the previous version running
+			if wasRunning {
+				a.start(context.WithoutCancel(ctx))
+			}
+			return fmt.Errorf("failed to upgrade agent binary: %w", err)
+		}
+		if err := agentops.ApplyPermissions(a.platformInfo, policy); err != nil {
+			return err
+		}
+		result.Current = a.installedVersion(ctx)
+		if result.Upgraded() {
+			agentops.RecordVersionChange(ctx, a.platformInfo, state.ComponentAgent, result.Previous, result.Current, nil)
+		}
+
+		if wasRunning {
+			return a.start(ctx)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseVersion normalizes a requested agent release, or returns "" for the
+// latest
+func parseVersion(version string) (string, error) {
+	if version == "" {
+		return "", nil
+	}
+	tag, err := releases.ParseTag(version)
+	if err != nil {
+		return "", fmt.Errorf("fixpanic: %w", err)
+	}
+	return tag, nil
+}
+
+// Start starts the agent, through its systemd service where available. It
+// fails with ErrPanicStopped while the emergency stop is engaged.
+func (a *Agent) Start(ctx context.Context) error {
+	return a.locked(ctx, hooks.OperationStart, func() error {
+		if !a.connectivity.IsFixPanicAgentInstalled() {
+			return ErrNotInstalled
+		}
+		return a.start(ctx)
+	})
+}
+
+// Stop stops the agent
+func (a *Agent) Stop(ctx context.Context) error {
+	return a.locked(ctx, hooks.OperationStop, func() error {
+		_, err := a.stop(ctx)
+		return err
+	})
+}
+
+// start starts the agent service, or the agent process without systemd,
+// unless the emergency stop is engaged
+func (a *Agent) start(ctx context.Context) error {
+	if err := agentops.CheckPanicStop(a.platformInfo); err != nil {
+		return err
+	}
+	if platform.IsSystemdAvailable() {
+		return service.NewManager(a.platformInfo).Start(ctx)
+	}
+
+	procs, err := procfind.FindByExecutable(a.platformInfo.GetFixPanicAgentBinaryPath())
+	if err != nil {
+		return fmt.Errorf("failed to list processes: %w", err)
+	}
+	if len(procs) > 0 {
+		return nil
+	}
+	_, err = process.NewProcessManager().StartProcess(process.ProcessConfig{
+		BinaryPath: a.platformInfo.GetFixPanicAgentBinaryPath(),
+		Args:       []string{"--config", a.platformInfo.GetConfigPath()},
+		Detach:     true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start agent: %w", err)
+	}
+	return nil
+}
+
+// stop stops the agent service and any remaining agent processes, and
+// reports whether the agent was running
+func (a *Agent) stop(ctx context.Context) (bool, error) {
+	running := false
+	if platform.IsSystemdAvailable() {
+		serviceManager := service.NewManager(a.platformInfo)
+		if state, err := serviceManager.Status(ctx); err == nil && state == "active" {
+			running = true
+			if err := serviceManager.Stop(ctx); err != nil {
+				return running, err
+			}
+		}
+	}
+
+	procs, err := procfind.FindByExecutable(a.platformInfo.GetFixPanicAgentBinaryPath())
+	if err != nil {
+		return running, fmt.Errorf("failed to list processes: %w", err)
+	}
+	procManager := process.NewProcessManager()
+	for _, proc := range procs {
+		running = true
+		if err := procManager.StopProcess(proc.PID); err != nil {
+			return running, fmt.Errorf("failed to stop process %d: %w", proc.PID, err)
+		}
+	}
+	return running, nil
+}
+
+// installedVersion returns the version of the installed agent, or an empty
+// string if it can't be determined
+func (a *Agent) installedVersion(ctx context.Context) string {
+	output, err := a.connectivity.GetFixPanicAgentVersion(ctx)
+	if err != nil {
+		return ""
+	}
+	return connectivity.ParseAgentVersion(output)
+}
@@ -0,0 +1,33 @@
+package fixpanic
+
+import (
+	"os"
+	"strings"
+
+	"github.com/fixpanic/fixpanic-cli/internal/agentops"
+	"github.com/spf13/viper"
+)
+
+// loadSettings reads the safeguards the CLI reads from ~/.fixpanic.yaml and
+// the FIXPANIC_* environment variables. A missing or unreadable file leaves
+// the defaults.
+func loadSettings() agentops.Settings {
+	v := viper.New()
+	if home, err := os.UserHomeDir(); err == nil {
+		v.AddConfigPath(home)
+	}
+	v.SetConfigType("yaml")
+	v.SetConfigName(".fixpanic")
+	v.SetEnvPrefix("FIXPANIC")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	v.AutomaticEnv()
+	v.ReadInConfig()
+
+	return agentops.Settings{
+		ReadOnly: v.GetBool("cli.read_only"),
+		DirMode:  v.GetString("permissions.dir_mode"),
+		FileMode: v.GetString("permissions.file_mode"),
+		Owner:    v.GetString("permissions.owner"),
+		Group:    v.GetString("permissions.group"),
+	}
+}